@@ -0,0 +1,158 @@
+// Package kds lists, fetches, and decodes Kubernetes secrets the way the kds TUI
+// shows them, for tools that build on kds without shelling out to it. The
+// building blocks are in pkg/kube, and the TUI itself is in pkg/ui.
+package kds
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Format is the kind of content detected in a secret value.
+type Format string
+
+// Formats recognized by Decode, checked in this order.
+const (
+	FormatCertificate         Format = "certificate"           // A PEM bundle of X.509 certificates.
+	FormatPrivateKey          Format = "private-key"           // A PEM-encoded private key.
+	FormatKubeconfig          Format = "kubeconfig"            // A kubeconfig file.
+	FormatServiceAccountToken Format = "service-account-token" // A service account JWT.
+	FormatTOTP                Format = "totp"                  // A TOTP seed or otpauth:// URI.
+	FormatBinary              Format = "binary"                // Anything that is not valid UTF-8.
+	FormatText                Format = "text"                  // Anything else.
+)
+
+// Browser lists, fetches, and decodes secrets.
+type Browser struct {
+	clientset kube.Client
+}
+
+// NewBrowser creates a Browser on top of a Kubernetes client, e.g. a
+// *kubernetes.Clientset.
+func NewBrowser(clientset kube.Client) *Browser {
+	return &Browser{clientset: clientset}
+}
+
+// ListOptions selects the secrets returned by List.
+type ListOptions struct {
+	Namespace     string            // Empty for all namespaces.
+	LabelSelector string            // e.g. "app=web,tier!=db".
+	Type          corev1.SecretType // Empty for secrets of every type.
+}
+
+// Metadata describes a secret without its values.
+type Metadata struct {
+	Name        string
+	Namespace   string
+	Type        corev1.SecretType
+	Created     time.Time
+	Labels      map[string]string
+	Annotations map[string]string
+	Immutable   bool
+	Size        int      // Approximate size of the keys and values, in bytes.
+	Keys        []string // Sorted.
+}
+
+// Secret is a secret with its values decoded.
+type Secret struct {
+	Metadata
+	Values []Value // Sorted by key.
+}
+
+// Value is a decoded secret value. Only the detail that matches its format is set.
+type Value struct {
+	Key    string
+	Data   []byte
+	Format Format
+
+	Certificates []*x509.Certificate
+	PrivateKey   *kube.SSHKeyInfo
+	Kubeconfig   *clientcmdapi.Config
+	Token        *kube.ServiceAccountToken
+	TOTP         *kube.TOTPConfig
+}
+
+// List returns the metadata of the selected secrets, sorted by namespace and name.
+func (b *Browser) List(ctx context.Context, opts ListOptions) ([]Metadata, error) {
+	secrets, err := b.clientset.CoreV1().Secrets(opts.Namespace).List(ctx, metav1.ListOptions{LabelSelector: opts.LabelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	list := make([]Metadata, 0, len(secrets.Items))
+	for i := range secrets.Items {
+		if opts.Type != "" && secrets.Items[i].Type != opts.Type {
+			continue
+		}
+		list = append(list, metadataOf(&secrets.Items[i]))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Namespace != list[j].Namespace {
+			return list[i].Namespace < list[j].Namespace
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+// Get fetches a secret and decodes it.
+func (b *Browser) Get(ctx context.Context, namespace, name string) (*Secret, error) {
+	secret, err := b.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret '%s': %w", kube.SecretRef{Namespace: namespace, Name: name}, err)
+	}
+	return b.Decode(secret), nil
+}
+
+// Decode decodes the values of a secret and detects their formats.
+func (b *Browser) Decode(secret *corev1.Secret) *Secret {
+	decoded := &Secret{Metadata: metadataOf(secret)}
+	for _, key := range decoded.Keys {
+		decoded.Values = append(decoded.Values, decodeValue(key, secret.Data[key]))
+	}
+	return decoded
+}
+
+// metadataOf describes a secret without its values.
+func metadataOf(secret *corev1.Secret) Metadata {
+	return Metadata{
+		Name:        secret.Name,
+		Namespace:   secret.Namespace,
+		Type:        secret.Type,
+		Created:     secret.CreationTimestamp.Time,
+		Labels:      secret.Labels,
+		Annotations: secret.Annotations,
+		Immutable:   secret.Immutable != nil && *secret.Immutable,
+		Size:        kube.SecretSize(secret),
+		Keys:        kube.SortedKeys(secret.Data),
+	}
+}
+
+// decodeValue detects the format of a value with the parsers behind the TUI.
+func decodeValue(key string, data []byte) Value {
+	v := Value{Key: key, Data: data}
+	if certs, err := kube.ParseCertificates(data); err == nil {
+		v.Format, v.Certificates = FormatCertificate, certs
+	} else if info, err := kube.ParseSSHPrivateKey(data); err == nil {
+		v.Format, v.PrivateKey = FormatPrivateKey, &info
+	} else if config, ok := kube.ParseKubeconfig(data); ok {
+		v.Format, v.Kubeconfig = FormatKubeconfig, config
+	} else if token, err := kube.ParseServiceAccountToken(string(data)); err == nil {
+		v.Format, v.Token = FormatServiceAccountToken, token
+	} else if totp, ok := kube.ParseTOTP(key, string(data)); ok {
+		v.Format, v.TOTP = FormatTOTP, &totp
+	} else if !utf8.Valid(data) {
+		v.Format = FormatBinary
+	} else {
+		v.Format = FormatText
+	}
+	return v
+}
@@ -0,0 +1,129 @@
+package kds
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestBrowserList verifies that secrets are listed sorted, filtered by type and
+// label selector.
+func TestBrowserList(t *testing.T) {
+	b := NewBrowser(fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod", Labels: map[string]string{"app": "web"}}, Type: corev1.SecretTypeOpaque, Data: map[string][]byte{"b": []byte("2"), "a": []byte("1")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"}, Type: corev1.SecretTypeBasicAuth},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "dev", Labels: map[string]string{"app": "web"}}, Type: corev1.SecretTypeOpaque},
+	))
+
+	t.Run("should list all namespaces sorted by namespace and name", func(t *testing.T) {
+		list, err := b.List(context.Background(), ListOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		var names []string
+		for _, s := range list {
+			names = append(names, s.Namespace+"/"+s.Name)
+		}
+		if len(names) != 3 || names[0] != "dev/api" || names[1] != "prod/db" || names[2] != "prod/web" {
+			t.Errorf("Expected dev/api, prod/db, prod/web, but got %v", names)
+		}
+		if keys := list[2].Keys; len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+			t.Errorf("Expected sorted keys a, b, but got %v", keys)
+		}
+	})
+
+	t.Run("should filter by namespace, type, and labels", func(t *testing.T) {
+		list, err := b.List(context.Background(), ListOptions{Namespace: "prod", LabelSelector: "app=web", Type: corev1.SecretTypeOpaque})
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if len(list) != 1 || list[0].Name != "web" {
+			t.Errorf("Expected only prod/web, but got %+v", list)
+		}
+	})
+}
+
+// TestBrowserGet verifies that values are decoded with their detected formats.
+func TestBrowserGet(t *testing.T) {
+	claims := `{"iss":"https://kubernetes.default.svc","sub":"system:serviceaccount:ci:builder","exp":4102444800,"kubernetes.io":{"namespace":"ci","serviceaccount":{"name":"builder"}}}`
+	token := "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".sig"
+	b := NewBrowser(fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mixed", Namespace: "default"},
+		Data: map[string][]byte{
+			"ca.crt":      newTestCertificate(t),
+			"config":      []byte("apiVersion: v1\nkind: Config\nclusters:\n- name: prod\n  cluster:\n    server: https://prod:6443\n"),
+			"token":       []byte(token),
+			"otp":         []byte("otpauth://totp/kds?secret=JBSWY3DPEHPK3PXP"),
+			"blob":        {0xff, 0xfe, 0x00},
+			"password":    []byte("hunter2"),
+			"not-a-token": []byte("a.b.c"),
+		},
+	}))
+
+	secret, err := b.Get(context.Background(), "default", "mixed")
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	formats := map[string]Format{}
+	for _, v := range secret.Values {
+		formats[v.Key] = v.Format
+	}
+	expected := map[string]Format{
+		"ca.crt": FormatCertificate, "config": FormatKubeconfig, "token": FormatServiceAccountToken,
+		"otp": FormatTOTP, "blob": FormatBinary, "password": FormatText, "not-a-token": FormatText,
+	}
+	for key, format := range expected {
+		if formats[key] != format {
+			t.Errorf("Expected %s to be detected as %s, but got %s", key, format, formats[key])
+		}
+	}
+	for _, v := range secret.Values {
+		switch v.Key {
+		case "ca.crt":
+			if len(v.Certificates) != 1 || v.Certificates[0].Subject.CommonName != "kds-test" {
+				t.Errorf("Expected the parsed certificate, but got %v", v.Certificates)
+			}
+		case "token":
+			if v.Token == nil || v.Token.ServiceAccount != "builder" {
+				t.Errorf("Expected the claims of the builder token, but got %+v", v.Token)
+			}
+		}
+	}
+
+	t.Run("should report a missing secret", func(t *testing.T) {
+		if _, err := b.Get(context.Background(), "default", "missing"); err == nil {
+			t.Error("Expected an error for a missing secret, but got none")
+		}
+	})
+}
+
+// newTestCertificate creates a self-signed PEM certificate.
+func newTestCertificate(t *testing.T) []byte {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "kds-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pub, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
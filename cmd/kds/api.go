@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/diskmanti/kds"
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/sahilm/fuzzy"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// defaultAPIAddress keeps the REST API on the loopback interface unless asked otherwise.
+const defaultAPIAddress = "127.0.0.1:8080"
+
+// apiTokenEnv holds the bearer token of 'kds api' when no token file is given.
+const apiTokenEnv = "KDS_API_TOKEN"
+
+// newAPICmd creates the 'kds api' command, which serves the secrets of a namespace
+// as JSON over HTTP.
+func newAPICmd(opts *rootOptions) *cobra.Command {
+	var listen, tokenFile string
+
+	cmd := &cobra.Command{
+		Use:   "api",
+		Short: "Serve a local REST API to list, get, and search secrets",
+		Long: `Run a local HTTP server exposing the secrets of the namespace as JSON, with the
+same decoding and cross-referencing as the TUI, for dashboards and scripts:
+
+  GET /api/v1/secrets                 list secrets (?labelSelector=, ?type=)
+  GET /api/v1/secrets/{name}          get a secret, its decoded values, and its users
+  GET /api/v1/search?q=...            fuzzy-search secret names
+
+Every request needs an 'Authorization: Bearer <token>' header. The token is read
+from --auth-token-file or ` + apiTokenEnv + `, or generated and printed at startup.
+Values are masked unless ?reveal=true is passed. The keys matched by redaction
+rules stay masked, and values are never revealed in a protected namespace.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			token, generated, err := loadToken(tokenFile, apiTokenEnv)
+			if err != nil {
+				return err
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			redaction, err := opts.loadRedactionPolicy()
+			if err != nil {
+				return err
+			}
+			protected, err := opts.loadProtectedNamespaces()
+			if err != nil {
+				return err
+			}
+			listener, err := net.Listen("tcp", listen)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", listen, err)
+			}
+			server := &http.Server{
+				Handler:           newAPIHandler(clientset, namespace, token, redaction, protected),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				_ = server.Shutdown(context.Background())
+			}()
+			if generated {
+				cmd.PrintErrf("Generated API token: %s\n", token)
+			}
+			cmd.PrintErrf("Serving the API for namespace '%s' on http://%s (Ctrl+C to stop)\n", namespace, listener.Addr())
+			if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&listen, "listen", defaultAPIAddress, "address to listen on")
+	cmd.Flags().StringVar(&tokenFile, "auth-token-file", "", "file holding the bearer token clients must send (default: $"+apiTokenEnv+", or a generated one)")
+	return cmd
+}
+
+// loadToken reads the bearer token of 'kds api' or 'kds serve' from the file or
+// the environment variable env, or generates a random one, reporting whether it
+// did.
+func loadToken(path, env string) (string, bool, error) {
+	if path != "" {
+		data, err := os.ReadFile(path) //nolint:gosec // The token file is chosen by the user.
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read token: %w", err)
+		}
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return "", false, fmt.Errorf("token file '%s' is empty", path)
+		}
+		return token, false, nil
+	}
+	if token := strings.TrimSpace(os.Getenv(env)); token != "" {
+		return token, false, nil
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", false, fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), true, nil
+}
+
+// apiHandler serves the endpoints of 'kds api'. It only ever reads from the cluster.
+type apiHandler struct {
+	clientset kube.Client
+	browser   *kds.Browser
+	namespace string
+	redaction kube.RedactionPolicy
+	protected kube.ProtectedNamespaces
+}
+
+// newAPIHandler routes the endpoints behind bearer token authentication.
+func newAPIHandler(clientset kube.Client, namespace, token string, redaction kube.RedactionPolicy, protected kube.ProtectedNamespaces) http.Handler {
+	h := &apiHandler{clientset: clientset, browser: kds.NewBrowser(clientset), namespace: namespace, redaction: redaction, protected: protected}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/secrets", h.list)
+	mux.HandleFunc("GET /api/v1/secrets/{name}", h.get)
+	mux.HandleFunc("GET /api/v1/search", h.search)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAPIError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// apiMetadata is the JSON form of kds.Metadata.
+type apiMetadata struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Type        corev1.SecretType `json:"type"`
+	Created     time.Time         `json:"created"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Immutable   bool              `json:"immutable"`
+	Size        int               `json:"size"`
+	Keys        []string          `json:"keys"`
+}
+
+// apiValue is a secret value as returned by the API.
+type apiValue struct {
+	Key      string     `json:"key"`
+	Format   kds.Format `json:"format"`
+	Value    string     `json:"value"`
+	Encoding string     `json:"encoding,omitempty"` // "base64" for revealed binary values.
+	Masked   bool       `json:"masked"`
+	Summary  string     `json:"summary,omitempty"`
+}
+
+// apiSecret is a secret as returned by the API, with the places that reference it.
+type apiSecret struct {
+	apiMetadata
+	Values []apiValue `json:"values"`
+	UsedBy []string   `json:"usedBy"`
+}
+
+// toAPIMetadata converts metadata to its JSON form, without the annotations
+// holding values.
+func toAPIMetadata(m kds.Metadata) apiMetadata {
+	return apiMetadata{
+		Name: m.Name, Namespace: m.Namespace, Type: m.Type, Created: m.Created, Labels: m.Labels,
+		Annotations: kube.WithoutSensitiveAnnotations(m.Annotations), Immutable: m.Immutable, Size: m.Size, Keys: m.Keys,
+	}
+}
+
+// list returns the secrets of the namespace, filtered by label selector and type.
+func (h *apiHandler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	secrets, err := h.browser.List(r.Context(), kds.ListOptions{
+		Namespace:     h.namespace,
+		LabelSelector: query.Get("labelSelector"),
+		Type:          corev1.SecretType(query.Get("type")),
+	})
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, err)
+		return
+	}
+	list := make([]apiMetadata, 0, len(secrets))
+	for _, s := range secrets {
+		list = append(list, toAPIMetadata(s))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// search returns the secrets whose names fuzzy-match the q parameter, best first.
+func (h *apiHandler) search(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeAPIError(w, http.StatusBadRequest, errors.New("missing query parameter 'q'"))
+		return
+	}
+	secrets, err := h.browser.List(r.Context(), kds.ListOptions{Namespace: h.namespace})
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, err)
+		return
+	}
+	names := make([]string, len(secrets))
+	for i, s := range secrets {
+		names[i] = s.Name
+	}
+	matches := fuzzy.Find(query, names)
+	list := make([]apiMetadata, 0, len(matches))
+	for _, match := range matches {
+		list = append(list, toAPIMetadata(secrets[match.Index]))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// get returns one secret with its decoded values, masked unless reveal=true is
+// passed, and the workloads, ingresses, and service accounts that reference it.
+// Revealing is refused in protected namespaces, and the keys matched by redaction
+// rules stay masked, since nobody can confirm them over HTTP.
+func (h *apiHandler) get(w http.ResponseWriter, r *http.Request) {
+	reveal := r.URL.Query().Get("reveal") == "true"
+	if reveal && h.protected.Protects(h.namespace) {
+		writeAPIError(w, http.StatusForbidden, fmt.Errorf("values of protected namespace '%s' cannot be revealed", h.namespace))
+		return
+	}
+	secret, err := h.browser.Get(r.Context(), h.namespace, r.PathValue("name"))
+	if k8serrors.IsNotFound(err) {
+		writeAPIError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, err)
+		return
+	}
+	usages, err := kube.BuildUsageIndex(h.clientset, h.namespace)
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, err)
+		return
+	}
+	result := apiSecret{apiMetadata: toAPIMetadata(secret.Metadata), Values: []apiValue{}, UsedBy: []string{}}
+	for _, v := range secret.Values {
+		value := apiValue{Key: v.Key, Format: v.Format, Value: kube.MaskValue(v.Data), Masked: true, Summary: summarizeValue(v)}
+		if reveal && h.redaction.ModeOf(v.Key) == kube.RedactNone {
+			value.Value, value.Masked = string(v.Data), false
+			if v.Format == kds.FormatBinary {
+				value.Value, value.Encoding = base64.StdEncoding.EncodeToString(v.Data), "base64"
+			}
+		}
+		result.Values = append(result.Values, value)
+	}
+	for _, usage := range usages[secret.Name] {
+		result.UsedBy = append(result.UsedBy, usage.String())
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// writeJSON writes v as the JSON response body.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeAPIError writes an error as a JSON object with an "error" field.
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
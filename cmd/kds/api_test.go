@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestAPIHandler verifies that the API requires the token, masks values unless
+// asked to reveal them, and cross-references the workloads using a secret.
+func TestAPIHandler(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "default", Annotations: map[string]string{
+				"team":                             "payments",
+				corev1.LastAppliedConfigAnnotation: `{"data":{"password":"aHVudGVyMg=="}}`,
+				kube.PreviousValueAnnotationPrefix + "password": "b2xkLWh1bnRlcjI=",
+			}},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{"password": []byte("hunter2"), "blob": {0xff}, "api_token": []byte("t0k3n")},
+		},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api-key", Namespace: "default"}, Type: corev1.SecretTypeOpaque},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}, Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "web", EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "db-credentials"}}}}}},
+		}}}},
+	)
+	redaction, err := kube.CompileRedactionPolicy([]kube.RedactionRuleConfig{{Keys: "^api_token$"}})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	handler := newAPIHandler(clientset, "default", "s3cret", redaction, nil)
+	get := func(t *testing.T, target, token string, v any) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if v != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("Expected a JSON body, but got %q: %v", rec.Body.String(), err)
+			}
+		}
+		return rec.Code
+	}
+
+	t.Run("should require the bearer token", func(t *testing.T) {
+		for _, token := range []string{"", "wrong"} {
+			if code := get(t, "/api/v1/secrets", token, nil); code != http.StatusUnauthorized {
+				t.Errorf("Expected status 401 for token %q, but got %d", token, code)
+			}
+		}
+	})
+
+	t.Run("should list and search secrets", func(t *testing.T) {
+		var list []apiMetadata
+		if code := get(t, "/api/v1/secrets", "s3cret", &list); code != http.StatusOK || len(list) != 2 {
+			t.Errorf("Expected two secrets, but got %d: %+v", code, list)
+		}
+		if get(t, "/api/v1/search?q=dbc", "s3cret", &list); len(list) != 1 || list[0].Name != "db-credentials" {
+			t.Errorf("Expected only db-credentials to match, but got %+v", list)
+		}
+		if code := get(t, "/api/v1/search", "s3cret", nil); code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 without a query, but got %d", code)
+		}
+	})
+
+	t.Run("should mask values unless revealing and list users", func(t *testing.T) {
+		var secret apiSecret
+		get(t, "/api/v1/secrets/db-credentials", "s3cret", &secret)
+		values := map[string]apiValue{}
+		for _, v := range secret.Values {
+			values[v.Key] = v
+		}
+		if v := values["password"]; !v.Masked || v.Value != "******** (7 bytes)" {
+			t.Errorf("Expected the password to be masked, but got %+v", v)
+		}
+		if len(secret.UsedBy) != 1 || secret.UsedBy[0] != "Deployment/web (envFrom)" {
+			t.Errorf("Expected Deployment/web to use the secret, but got %v", secret.UsedBy)
+		}
+		get(t, "/api/v1/secrets/db-credentials?reveal=true", "s3cret", &secret)
+		for _, v := range secret.Values {
+			values[v.Key] = v
+		}
+		if v := values["password"]; v.Masked || v.Value != "hunter2" {
+			t.Errorf("Expected the password to be revealed, but got %+v", v)
+		}
+		if v := values["blob"]; v.Encoding != "base64" || v.Value != "/w==" {
+			t.Errorf("Expected the binary value in base64, but got %+v", v)
+		}
+		if v := values["api_token"]; !v.Masked || v.Value == "t0k3n" {
+			t.Errorf("Expected the redacted token to stay masked, but got %+v", v)
+		}
+	})
+
+	t.Run("should leave out the annotations holding values", func(t *testing.T) {
+		var secret apiSecret
+		get(t, "/api/v1/secrets/db-credentials", "s3cret", &secret)
+		if len(secret.Annotations) != 1 || secret.Annotations["team"] != "payments" {
+			t.Errorf("Expected only the team annotation, but got %v", secret.Annotations)
+		}
+	})
+
+	t.Run("should refuse to reveal values of a protected namespace", func(t *testing.T) {
+		handler := newAPIHandler(clientset, "default", "s3cret", nil, kube.ProtectedNamespaces{"default"})
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/secrets/db-credentials?reveal=true", nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden || strings.Contains(rec.Body.String(), "hunter2") {
+			t.Errorf("Expected status 403, but got %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("should answer 404 for missing secrets", func(t *testing.T) {
+		if code := get(t, "/api/v1/secrets/missing", "s3cret", nil); code != http.StatusNotFound {
+			t.Errorf("Expected status 404, but got %d", code)
+		}
+	})
+}
+
+// TestLoadToken verifies the precedence of the token file, the environment,
+// and a generated token.
+func TestLoadToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(apiTokenEnv, "from-env")
+	if token, generated, err := loadToken(path, apiTokenEnv); err != nil || token != "from-file" || generated {
+		t.Errorf("Expected the token from the file, but got %q, %v, %v", token, generated, err)
+	}
+	if token, generated, err := loadToken("", apiTokenEnv); err != nil || token != "from-env" || generated {
+		t.Errorf("Expected the token from the environment, but got %q, %v, %v", token, generated, err)
+	}
+	t.Setenv(apiTokenEnv, "")
+	if token, generated, err := loadToken("", apiTokenEnv); err != nil || len(token) != 64 || !generated {
+		t.Errorf("Expected a generated token, but got %q, %v, %v", token, generated, err)
+	}
+}
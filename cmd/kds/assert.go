@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newAssertCmd creates the 'kds assert' command.
+func newAssertCmd(opts *rootOptions) *cobra.Command {
+	var exists, keys []string
+
+	cmd := &cobra.Command{
+		Use:   "assert --exists <secret>,... | --keys <secret>:<key>,...",
+		Short: "Check that secrets and keys exist, for deploy pipelines",
+		Long: `Check that secrets, and keys of them, exist in a namespace, so that a deploy
+pipeline can verify its prerequisites before rolling out.
+
+Every expectation is printed with a "+" when it holds and a "-" when it does not,
+and kds exits with a non-zero code if any of them does not. Values are never read
+beyond checking that their keys exist.`,
+		Example: `  # Fail the pipeline if a secret or one of its keys is missing
+  kds assert -n prod --exists db-credentials,app-tls --keys api-token:token,url`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var expectations []kube.SecretExpectation
+			for _, name := range exists {
+				expectations = append(expectations, kube.SecretExpectation{Name: name})
+			}
+			for _, s := range keys {
+				e, err := kube.ParseKeyExpectation(s)
+				if err != nil {
+					return err
+				}
+				expectations = append(expectations, e)
+			}
+			if len(expectations) == 0 {
+				return errors.New("nothing to check: use --exists or --keys")
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			assertions, err := kube.CheckExpectations(clientset, namespace, expectations)
+			if err != nil {
+				return err
+			}
+			failed, err := printAssertions(cmd.OutOrStdout(), assertions)
+			if err != nil {
+				return err
+			}
+			if failed > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d expectations failed in namespace '%s'", failed, len(assertions), namespace)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&exists, "exists", nil, "secrets that must exist, comma-separated")
+	cmd.Flags().StringArrayVar(&keys, "keys", nil, "keys a secret must have, as secret:key1,key2 (repeatable)")
+	return cmd
+}
+
+// printAssertions writes one diff-like line per assertion, and returns how many
+// failed.
+func printAssertions(w io.Writer, assertions []kube.Assertion) (int, error) {
+	var b strings.Builder
+	failed := 0
+	for _, a := range assertions {
+		if a.Passed {
+			fmt.Fprintf(&b, "+ %s\n", a)
+			continue
+		}
+		failed++
+		fmt.Fprintf(&b, "- %s (%s)\n", a, a.Reason)
+	}
+	_, err := io.WriteString(w, b.String())
+	return failed, err
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// b64Options holds the flags of the 'kds b64' command.
+type b64Options struct {
+	decode      bool
+	urlSafe     bool
+	noPadding   bool
+	keepNewline bool
+}
+
+// newB64Cmd creates the 'kds b64' command.
+func newB64Cmd() *cobra.Command {
+	var b64Opts b64Options
+
+	cmd := &cobra.Command{
+		Use:   "b64 [-d] [value...]",
+		Short: "Encode or decode base64 the way secret values need it",
+		Long: `Encode or decode base64 values given as arguments, one per line, or read from
+stdin as a whole.
+
+Encoding never wraps lines, unlike the base64 tool, so the output can be pasted
+into a manifest as is. When stdin is a single line, its trailing newline is left
+out, since it usually comes from echo rather than from the value; use
+--keep-newline to encode it too. Multi-line input, such as a PEM file, is encoded
+byte for byte.
+
+Decoding ignores whitespace and line breaks, does not need the padding, and
+accepts the URL-safe alphabet. PEM input decodes to the DER bytes of its blocks.
+A decoded value that looks base64-encoded again is reported on stderr, as it was
+likely encoded twice.`,
+		Example: `  # Encode a value for the data field of a manifest
+  kds b64 hunter2
+
+  # Encode a certificate, keeping its line breaks
+  kds b64 < tls.crt
+
+  # Decode a value copied from a manifest
+  kds b64 -d aHVudGVyMg
+
+  # Convert a PEM certificate to DER
+  kds b64 -d < tls.crt > tls.der`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := cmd.OutOrStdout()
+			if len(args) > 0 {
+				for _, arg := range args {
+					if err := runB64(cmd, w, []byte(arg), b64Opts, true); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			input, err := io.ReadAll(cmd.InOrStdin())
+			if err != nil {
+				return fmt.Errorf("failed to read stdin: %w", err)
+			}
+			if !b64Opts.decode && !b64Opts.keepNewline && bytes.Count(input, []byte("\n")) == 1 {
+				input = bytes.TrimSuffix(bytes.TrimSuffix(input, []byte("\n")), []byte("\r"))
+			}
+			return runB64(cmd, w, input, b64Opts, false)
+		},
+	}
+	cmd.Flags().BoolVarP(&b64Opts.decode, "decode", "d", false, "decode instead of encoding")
+	cmd.Flags().BoolVar(&b64Opts.urlSafe, "url", false, "encode with the URL-safe alphabet")
+	cmd.Flags().BoolVar(&b64Opts.noPadding, "no-padding", false, "encode without the trailing '=' padding")
+	cmd.Flags().BoolVar(&b64Opts.keepNewline, "keep-newline", false, "encode the trailing newline of single-line stdin")
+	return cmd
+}
+
+// runB64 writes the encoded or decoded input. Encoded values always end with a
+// newline, decoded ones only if newline is set.
+func runB64(cmd *cobra.Command, w io.Writer, input []byte, b64Opts b64Options, newline bool) error {
+	if !b64Opts.decode {
+		_, err := fmt.Fprintln(w, kube.EncodeBase64(input, b64Opts.urlSafe, !b64Opts.noPadding))
+		return err
+	}
+	decoded, err := kube.DecodeBase64(string(input))
+	if err != nil {
+		return err
+	}
+	if kube.LooksBase64Encoded(bytes.TrimSpace(decoded)) {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Warning: the decoded value looks base64-encoded again; it was likely encoded twice")
+	}
+	if newline {
+		decoded = append(decoded, '\n')
+	}
+	_, err = w.Write(decoded)
+	return err
+}
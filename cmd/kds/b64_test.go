@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestB64Cmd verifies that 'kds b64' encodes and decodes arguments and stdin.
+func TestB64Cmd(t *testing.T) {
+	run := func(t *testing.T, stdin string, args ...string) (string, string) {
+		t.Helper()
+		cmd := newB64Cmd()
+		var out, errOut bytes.Buffer
+		cmd.SetIn(strings.NewReader(stdin))
+		cmd.SetOut(&out)
+		cmd.SetErr(&errOut)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		return out.String(), errOut.String()
+	}
+
+	t.Run("should leave out the newline of a single line from stdin", func(t *testing.T) {
+		if out, _ := run(t, "hunter2\n"); out != "aHVudGVyMg==\n" {
+			t.Errorf("Expected %q, but got %q", "aHVudGVyMg==\n", out)
+		}
+	})
+	t.Run("should keep the newline with --keep-newline", func(t *testing.T) {
+		if out, _ := run(t, "hunter2\n", "--keep-newline"); out != "aHVudGVyMgo=\n" {
+			t.Errorf("Expected %q, but got %q", "aHVudGVyMgo=\n", out)
+		}
+	})
+	t.Run("should encode multi-line input unwrapped and byte for byte", func(t *testing.T) {
+		out, _ := run(t, strings.Repeat("line\n", 20))
+		if strings.Count(out, "\n") != 1 || !strings.HasSuffix(out, "Cg==\n") {
+			t.Errorf("Expected a single line ending with the encoded newline, but got %q", out)
+		}
+	})
+	t.Run("should decode each argument on its own line", func(t *testing.T) {
+		if out, _ := run(t, "", "-d", "aHVudGVyMg", "YWRtaW4="); out != "hunter2\nadmin\n" {
+			t.Errorf("Expected %q, but got %q", "hunter2\nadmin\n", out)
+		}
+	})
+	t.Run("should warn about values encoded twice", func(t *testing.T) {
+		out, errOut := run(t, "YUhWdWRHVnlNZz09", "-d")
+		if out != "aHVudGVyMg==" || !strings.Contains(errOut, "encoded twice") {
+			t.Errorf("Expected the inner value and a warning, but got %q and %q", out, errOut)
+		}
+	})
+}
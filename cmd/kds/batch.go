@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// The values of --output that select a machine-readable output format.
+const (
+	outputJSON      = "json"
+	outputChecksums = "checksums"
+)
+
+// validateOutputFormat rejects --output values that kds does not know how to render.
+func validateOutputFormat(format string) error {
+	switch format {
+	case "", outputJSON, outputChecksums:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format '%s' (supported: json, checksums)", format)
+	}
+}
+
+// runBatch fetches every secret listed on stdin and prints them in the requested format.
+// Keys redacted by policy stay masked, and secrets of protected namespaces are only
+// checksummed, as there is no way to confirm revealing them.
+func runBatch(in io.Reader, out io.Writer, clientset kube.Client, namespace, format string, redaction kube.RedactionPolicy, protected kube.ProtectedNamespaces) error {
+	refs, err := kube.ReadSecretRefs(in, namespace)
+	if err != nil {
+		return err
+	}
+	if format != outputChecksums {
+		for _, ref := range refs {
+			if protected.Protects(ref.Namespace) {
+				return fmt.Errorf("namespace '%s' of secret '%s' is protected: batch mode cannot ask for confirmation", ref.Namespace, ref)
+			}
+		}
+	}
+	switch format {
+	case outputJSON:
+		return kube.PrintSecretsJSON(out, clientset, refs, true, redaction, nil)
+	case outputChecksums:
+		return printChecksums(out, clientset, refs)
+	}
+	for _, ref := range refs {
+		if err := viewSecretDataDirectly(clientset, ref.Name, ref.Namespace, redaction, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printChecksums writes one "<sha256>  namespace/name/key" line per key, in the
+// format of sha256sum, so secrets can be compared without revealing their values.
+func printChecksums(w io.Writer, clientset kube.Client, refs []kube.SecretRef) error {
+	for _, ref := range refs {
+		secret, err := kube.GetSecret(clientset, ref)
+		if err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(secret.Data))
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if _, err := fmt.Fprintf(w, "%s  %s/%s\n", kube.Checksum(secret.Data[key]), ref, key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestRunBatch verifies that batch mode emits a JSON array of decoded secrets.
+func TestRunBatch(t *testing.T) {
+	encode := func(s string) []byte { return []byte(base64.StdEncoding.EncodeToString([]byte(s))) }
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"}, Data: map[string][]byte{"user": encode("admin")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "prod"}, Data: map[string][]byte{"token": encode("t0k3n")}},
+	)
+	t.Run("should emit every listed secret as a JSON array", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := runBatch(strings.NewReader("a\nprod/b\n"), &out, clientset, "default", outputJSON, nil, nil); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		var got []kube.SecretData
+		if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+			t.Fatalf("Expected valid JSON, but got: %v", err)
+		}
+		expected := []kube.SecretData{
+			{Namespace: "default", Name: "a", Data: map[string]string{"user": "admin"}},
+			{Namespace: "prod", Name: "b", Data: map[string]string{"token": "t0k3n"}},
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %v, but got %v", expected, got)
+		}
+	})
+	t.Run("should mask the keys redacted by policy", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Data:       map[string][]byte{"user": encode("admin"), "API_TOKEN": encode("t0k3n"), "tls.key": encode("key")},
+		})
+		policy, err := kube.CompileRedactionPolicy([]kube.RedactionRuleConfig{{Keys: "_TOKEN$"}, {Keys: "\\.key$", Mode: kube.RedactConfirm}})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		var out bytes.Buffer
+		if err := runBatch(strings.NewReader("app\n"), &out, clientset, "default", outputJSON, policy, nil); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		var got []kube.SecretData
+		if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+			t.Fatalf("Expected valid JSON, but got: %v", err)
+		}
+		expected := map[string]string{"user": "admin", "API_TOKEN": kube.MaskValue(encode("t0k3n")), "tls.key": kube.MaskValue(encode("key"))}
+		if len(got) != 1 || !reflect.DeepEqual(got[0].Data, expected) {
+			t.Errorf("Expected %v, but got %v", expected, got)
+		}
+	})
+	t.Run("should refuse secrets of protected namespaces", func(t *testing.T) {
+		protected, err := kube.CompileProtectedNamespaces([]string{"prod*"})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		var out bytes.Buffer
+		if err := runBatch(strings.NewReader("a\nprod/b\n"), &out, clientset, "default", "", nil, protected); err == nil || !strings.Contains(err.Error(), "'prod' of secret 'prod/b' is protected") {
+			t.Errorf("Expected prod/b to be refused, but got: %v", err)
+		}
+		if out.Len() != 0 {
+			t.Errorf("Expected nothing to be printed, but got %q", out.String())
+		}
+		if err := runBatch(strings.NewReader("prod/b\n"), &out, clientset, "default", outputChecksums, nil, protected); err != nil {
+			t.Errorf("Expected checksums to be printed, but got: %v", err)
+		}
+	})
+	t.Run("should fail when a listed secret does not exist", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := runBatch(strings.NewReader("missing\n"), &out, clientset, "default", outputJSON, nil, nil); err == nil {
+			t.Fatal("Expected an error for a missing secret, but got nil")
+		}
+	})
+}
+
+// TestPrintChecksums verifies the sha256sum-style checksum output.
+func TestPrintChecksums(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Data:       map[string][]byte{"user": []byte("admin"), "password": []byte("hunter2")},
+	})
+	var out bytes.Buffer
+	if err := printChecksums(&out, clientset, []kube.SecretRef{{Namespace: "default", Name: "db"}}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := kube.Checksum([]byte("hunter2")) + "  default/db/password\n" +
+		kube.Checksum([]byte("admin")) + "  default/db/user\n"
+	if out.String() != expected {
+		t.Errorf("Expected output %q, but got %q", expected, out.String())
+	}
+}
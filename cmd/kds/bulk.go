@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// bulkOptions holds the flags of the commands that can act on every secret
+// matching a label selector instead of a named secret.
+type bulkOptions struct {
+	selector    string
+	allMatching bool
+	yes         bool
+}
+
+// addFlags binds the selector flags to a command.
+func (b *bulkOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&b.selector, "selector", "l", "", "act on the secrets matching this label selector, e.g. app=legacy (requires --all-matching)")
+	cmd.Flags().BoolVar(&b.allMatching, "all-matching", false, "confirm that --selector acts on every matching secret")
+	cmd.Flags().BoolVarP(&b.yes, "yes", "y", false, "do not ask for confirmation")
+}
+
+// validate checks that a selector is acknowledged with --all-matching, and that
+// secret names are given either as arguments or by the selector.
+func (b *bulkOptions) validate(names []string) error {
+	switch {
+	case b.selector == "" && len(names) == 0:
+		return errors.New("a secret name or --selector is required")
+	case b.selector == "":
+		return nil
+	case len(names) > 0:
+		return errors.New("secret names cannot be combined with --selector")
+	case !b.allMatching:
+		return errors.New("--selector acts on every matching secret, so it requires --all-matching")
+	}
+	return nil
+}
+
+// resolve returns the secrets to act on, from the names or the selector. Before a
+// selector acts, the matching secrets are always listed, and the action has to be
+// confirmed unless --yes is set.
+func (b *bulkOptions) resolve(cmd *cobra.Command, clientset kube.Client, namespace string, names []string, verb string) ([]kube.SecretRef, error) {
+	if b.selector == "" {
+		refs := make([]kube.SecretRef, len(names))
+		for i, name := range names {
+			refs[i] = kube.SecretRef{Namespace: namespace, Name: name}
+		}
+		return refs, nil
+	}
+	refs, err := kube.SelectSecrets(clientset, namespace, b.selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("no secrets in namespace '%s' match '%s'", namespace, b.selector)
+	}
+	cmd.PrintErrf("%d secret(s) in namespace '%s' match '%s':\n", len(refs), namespace, b.selector)
+	for _, ref := range refs {
+		cmd.PrintErrf("  - %s\n", ref.Name)
+	}
+	if !b.yes && !askConfirmation(cmd, fmt.Sprintf("%s these %d secret(s)?", verb, len(refs))) {
+		return nil, fmt.Errorf("action on the secrets matching '%s' aborted", b.selector)
+	}
+	return refs, nil
+}
+
+// runOnRefs applies an operation to every secret, going on after failures, and
+// returns the failures joined.
+func runOnRefs(refs []kube.SecretRef, op func(kube.SecretRef) error) error {
+	var errs []error
+	for _, ref := range refs {
+		if err := op(ref); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// newDeleteCmd creates the 'kds delete' command.
+func newDeleteCmd(opts *rootOptions) *cobra.Command {
+	bulk := &bulkOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "delete <secret-name>... | --selector <selector> --all-matching",
+		Short: "Delete secrets, keeping an encrypted copy in the trash",
+		Long: `Delete secrets, keeping an encrypted copy in the trash.
+
+The secrets are either named, or selected by label with --selector, which also
+requires --all-matching. The secrets matching a selector are always listed before
+anything is deleted, and deleting them has to be confirmed unless --yes is set.
+
+Unless the trash is disabled in the config file, an encrypted copy of every
+secret is kept, which 'kds restore-deleted' brings back.`,
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := bulk.validate(args); err != nil {
+				return err
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			refs, err := bulk.resolve(cmd, clientset, namespace, args, "Delete")
+			if err != nil {
+				return err
+			}
+			if bulk.selector == "" && !bulk.yes && !askConfirmation(cmd, fmt.Sprintf("Delete %d secret(s) in namespace '%s'?", len(refs), namespace)) {
+				return errors.New("deletion aborted")
+			}
+			trash, err := opts.openTrash()
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			return runOnRefs(refs, func(ref kube.SecretRef) error {
+				var err error
+				if trash != nil {
+					err = kube.TrashAndDeleteSecret(clientset, trash, ref, now)
+				} else {
+					err = kube.DeleteSecret(clientset, ref)
+				}
+				if err != nil {
+					return fmt.Errorf("failed to delete secret '%s': %w", ref, err)
+				}
+				cmd.PrintErrf("Deleted secret '%s'\n", ref)
+				return nil
+			})
+		},
+	}
+	bulk.addFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestBulkOptions verifies that selectors must be acknowledged, and that the
+// matching secrets are listed before acting on them.
+func TestBulkOptions(t *testing.T) {
+	t.Run("should require --all-matching with a selector", func(t *testing.T) {
+		if err := (&bulkOptions{selector: "app=legacy"}).validate(nil); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+		if err := (&bulkOptions{selector: "app=legacy", allMatching: true}).validate([]string{"db"}); err == nil {
+			t.Error("Expected names and selector to be exclusive, but got no error")
+		}
+		if err := (&bulkOptions{}).validate(nil); err == nil {
+			t.Error("Expected a name or selector to be required, but got no error")
+		}
+	})
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "legacy-db", Namespace: "default", Labels: map[string]string{"app": "legacy"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}},
+	)
+	resolve := func(bulk *bulkOptions, answer string) (string, error) {
+		var stderr bytes.Buffer
+		cmd := &cobra.Command{}
+		cmd.SetIn(strings.NewReader(answer))
+		cmd.SetErr(&stderr)
+		refs, err := bulk.resolve(cmd, clientset, "default", nil, "Delete")
+		if err == nil && (len(refs) != 1 || refs[0].Name != "legacy-db") {
+			t.Errorf("Expected only legacy-db, but got %v", refs)
+		}
+		return stderr.String(), err
+	}
+
+	t.Run("should list the matching secrets and ask", func(t *testing.T) {
+		output, err := resolve(&bulkOptions{selector: "app=legacy", allMatching: true}, "y\n")
+		if err != nil || !strings.Contains(output, "- legacy-db") || !strings.Contains(output, "Delete these 1 secret(s)?") {
+			t.Errorf("Expected a preview and a question, but got %q (%v)", output, err)
+		}
+	})
+	t.Run("should abort without confirmation", func(t *testing.T) {
+		if _, err := resolve(&bulkOptions{selector: "app=legacy", allMatching: true}, ""); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+	t.Run("should still list the secrets with --yes", func(t *testing.T) {
+		output, err := resolve(&bulkOptions{selector: "app=legacy", allMatching: true, yes: true}, "")
+		if err != nil || !strings.Contains(output, "- legacy-db") || strings.Contains(output, "[y/N]") {
+			t.Errorf("Expected a preview without question, but got %q (%v)", output, err)
+		}
+	})
+	t.Run("should fail when nothing matches", func(t *testing.T) {
+		if _, err := resolve(&bulkOptions{selector: "app=gone", allMatching: true, yes: true}, ""); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// cacheConfig configures the cache of the secret lists shown by the TUI.
+type cacheConfig struct {
+	// Enabled caches the secret lists, encrypted, so that the TUI shows them at
+	// once on the next run while it fetches the current ones.
+	Enabled bool `json:"enabled,omitempty"`
+	// Dir holds the encrypted lists (default: kds/lists in the user cache directory).
+	Dir string `json:"dir,omitempty"`
+}
+
+// openListCache opens the cache of the secret lists of the selected context, or
+// returns nil if it is not enabled. The key is kept in the OS keychain, or without
+// one in the user config directory, apart from the cache, e.g.
+// ~/.config/kds/cache.key on Linux.
+func (o *rootOptions) openListCache() (*kube.ListCache, error) {
+	config, err := loadConfig(o.configPath)
+	if err != nil {
+		return nil, err
+	}
+	if !config.Cache.Enabled {
+		return nil, nil
+	}
+	dir := config.Cache.Dir
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the cache directory: %w", err)
+		}
+		dir = filepath.Join(base, "kds", "lists")
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the cache key: %w", err)
+	}
+	contextName, err := o.contextName()
+	if err != nil {
+		return nil, err
+	}
+	return kube.OpenListCache(dir, filepath.Join(configDir, "kds", "cache.key"), contextName)
+}
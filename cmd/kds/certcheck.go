@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/diskmanti/kds/pkg/ui"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// newCheckTLSCmd creates the 'kds check-tls' command.
+func newCheckTLSCmd(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "check-tls [secret-name...]",
+		Short: "Validate the certificates of TLS secrets",
+		Long: `Validate TLS secrets (all kubernetes.io/tls secrets in the namespace if none are given).
+
+kds checks that the private key matches the leaf certificate, that tls.crt chains to
+the CA in ca.crt (or to the system roots if there is none), and that the certificate
+covers every host that Ingresses serve with the secret. Certificates that expire
+soon are reported as warnings. The command fails if any check fails.`,
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			secrets, err := kube.GetSecretsOrAll(clientset, namespace, args)
+			if err != nil {
+				return err
+			}
+			return checkTLSSecrets(cmd.OutOrStdout(), clientset, secrets, len(args) > 0)
+		},
+	}
+}
+
+// checkTLSSecrets validates and reports the TLS secrets among the given secrets.
+// Explicitly named secrets are validated whatever their type.
+func checkTLSSecrets(w io.Writer, clientset kube.Client, secrets []*corev1.Secret, named bool) error {
+	var b strings.Builder
+	checked, failed := 0, 0
+	for _, secret := range secrets {
+		if secret.Type != corev1.SecretTypeTLS && !named {
+			continue
+		}
+		hosts, err := kube.IngressHosts(clientset, secret.Namespace, secret.Name)
+		if err != nil {
+			return err
+		}
+		checks := kube.CheckTLSSecret(secret, hosts, nil, time.Now())
+		fmt.Fprintf(&b, "%s/%s\n", secret.Namespace, secret.Name)
+		for _, check := range checks {
+			fmt.Fprintf(&b, "  %s\n", ui.FormatTLSCheck(check))
+		}
+		checked++
+		if kube.TLSChecksFailed(checks) {
+			failed++
+		}
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d TLS secrets failed validation", failed, checked)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diskmanti/kds/internal/testfixtures"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestCheckTLSSecrets verifies host discovery from Ingresses and the report.
+func TestCheckTLSSecrets(t *testing.T) {
+	now := time.Now()
+	ca := testfixtures.NewCert(t, "ca", now.Add(-time.Hour), now.Add(365*24*time.Hour), nil)
+	leaf := testfixtures.NewCert(t, "leaf", now.Add(-time.Hour), now.Add(90*24*time.Hour), &ca, "app.example.com")
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app-tls"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{"tls.crt": leaf.CertPEM, "tls.key": leaf.KeyPEM, "ca.crt": ca.CertPEM},
+	}
+	opaque := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db"}, Type: corev1.SecretTypeOpaque}
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: networkingv1.IngressSpec{TLS: []networkingv1.IngressTLS{
+			{SecretName: "app-tls", Hosts: []string{"app.example.com", "www.example.com"}},
+			{SecretName: "other", Hosts: []string{"other.example.com"}},
+		}},
+	}
+	clientset := fake.NewSimpleClientset(ingress)
+
+	hosts, err := kube.IngressHosts(clientset, "default", "app-tls")
+	if err != nil || len(hosts) != 2 {
+		t.Fatalf("Expected 2 hosts, but got %v (%v)", hosts, err)
+	}
+
+	var out bytes.Buffer
+	err = checkTLSSecrets(&out, clientset, []*corev1.Secret{secret, opaque}, false)
+	if err == nil || !strings.Contains(err.Error(), "1 of 1 TLS secrets") {
+		t.Errorf("Expected one failed secret, but got %v", err)
+	}
+	if !strings.Contains(out.String(), "✘ covers www.example.com (Ingress/web)") || strings.Contains(out.String(), "default/db") {
+		t.Errorf("Expected the uncovered host of the TLS secret only, but got:\n%s", out.String())
+	}
+}
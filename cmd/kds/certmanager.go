@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/diskmanti/kds/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// newCertificateCmd creates the 'kds certificate' command.
+func newCertificateCmd(opts *rootOptions) *cobra.Command {
+	var renew, yes bool
+
+	cmd := &cobra.Command{
+		Use:   "certificate <secret-name>",
+		Short: "Show the cert-manager Certificate of a TLS secret and trigger its renewal",
+		Long: `Show the cert-manager Certificate that manages a TLS secret: its Ready and Issuing
+conditions, expiry, and scheduled renewal time.
+
+With --renew, a re-issuance is triggered the same way as 'cmctl renew': the
+Certificate gets an Issuing condition, and cert-manager issues a new certificate
+into the secret.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			dyn, err := opts.newDynamicClient()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			secret, err := kube.GetSecret(clientset, kube.SecretRef{Namespace: namespace, Name: args[0]})
+			if err != nil {
+				return err
+			}
+			cert, err := kube.FindCertificate(dyn, secret)
+			if err != nil {
+				return err
+			}
+			if cert == nil {
+				return fmt.Errorf("secret '%s/%s' is not managed by a cert-manager Certificate", namespace, args[0])
+			}
+			cmd.Println(strings.Join(ui.CertificateLines(kube.DescribeCertificate(cert)), "\n"))
+			if !renew || (!yes && !askConfirmation(cmd, "Renew Certificate '"+cert.GetName()+"'?")) {
+				return nil
+			}
+			if err := kube.RenewCertificate(dyn, cert, time.Now()); err != nil {
+				return err
+			}
+			cmd.PrintErrf("Triggered the renewal of Certificate '%s/%s'\n", namespace, cert.GetName())
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&renew, "renew", false, "trigger a re-issuance of the certificate")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "renew without asking for confirmation")
+	return cmd
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// completionFunc is the signature Cobra expects for dynamic argument and flag completion.
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// completeSecretNames completes the positional secret-name argument with the names
+// of the secrets in the resolved namespace.
+func completeSecretNames(opts *rootOptions) completionFunc {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		client, err := opts.newMetadataClient()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		namespace, err := opts.resolveNamespace()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		names, err := kube.ListSecretNames(client, namespace)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeSecretNameAndKey completes the secret-name argument, then the key
+// argument with the keys of that secret.
+func completeSecretNameAndKey(opts *rootOptions) completionFunc {
+	completeNames := completeSecretNames(opts)
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 1 {
+			return completeNames(cmd, args, toComplete)
+		}
+		clientset, err := opts.newClientset()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		namespace, err := opts.resolveNamespace()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		keys, err := kube.ListSecretKeys(clientset, kube.SecretRef{Namespace: namespace, Name: args[0]})
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return filterCompletions(keys, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeServiceAccountNames completes the positional argument with the names of
+// the ServiceAccounts in the resolved namespace.
+func completeServiceAccountNames(opts *rootOptions) completionFunc {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		clientset, err := opts.newClientset()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		namespace, err := opts.resolveNamespace()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		names, err := kube.ListServiceAccountNames(clientset, namespace)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeNamespaces completes the --namespace flag with the namespaces in the cluster.
+func completeNamespaces(opts *rootOptions) completionFunc {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		clientset, err := opts.newClientset()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		names, err := kube.ListNamespaceNames(clientset)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeContexts completes context flags with the contexts defined in the kubeconfig.
+func completeContexts(opts *rootOptions) completionFunc {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		config, err := opts.clientConfig().RawConfig()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		names := make([]string, 0, len(config.Contexts))
+		for name := range config.Contexts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// filterCompletions keeps only the candidates that start with the text typed so far.
+func filterCompletions(candidates []string, toComplete string) []string {
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, toComplete) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
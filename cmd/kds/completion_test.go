@@ -0,0 +1,24 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestFilterCompletions verifies that completion candidates are filtered by prefix.
+func TestFilterCompletions(t *testing.T) {
+	candidates := []string{"app-db", "app-tls", "registry"}
+	t.Run("should keep only candidates with the typed prefix", func(t *testing.T) {
+		got := filterCompletions(candidates, "app-")
+		expected := []string{"app-db", "app-tls"}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %v, but got %v", expected, got)
+		}
+	})
+	t.Run("should return everything for an empty prefix", func(t *testing.T) {
+		got := filterCompletions(candidates, "")
+		if !reflect.DeepEqual(got, candidates) {
+			t.Errorf("Expected %v, but got %v", candidates, got)
+		}
+	})
+}
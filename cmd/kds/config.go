@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/diskmanti/kds/pkg/ui"
+	"sigs.k8s.io/yaml"
+)
+
+// kdsConfig is the content of the kds configuration file.
+type kdsConfig struct {
+	// Decoders render proprietary value formats with external commands.
+	Decoders []kube.DecoderConfig `json:"decoders,omitempty"`
+	// Actions run external commands on the highlighted secret.
+	Actions []ui.ActionConfig `json:"actions,omitempty"`
+	// Stores are password managers and other secret stores that 'kds push' and
+	// 'kds pull' reach through their CLIs.
+	Stores []kube.StoreConfig `json:"stores,omitempty"`
+	// SSHUsers may log in to 'kds serve-ssh'.
+	SSHUsers []sshUserConfig `json:"sshUsers,omitempty"`
+	// ProtectedNamespaces are glob patterns, e.g. prod-*, of the namespaces whose
+	// values are only revealed, copied, or exported after a confirmation.
+	ProtectedNamespaces []string `json:"protectedNamespaces,omitempty"`
+	// Redaction masks the values of keys matching its rules when they are shown.
+	Redaction []kube.RedactionRuleConfig `json:"redaction,omitempty"`
+	// Contexts restrict kds to reads, or block it, in some kubeconfig contexts.
+	Contexts []contextPolicyConfig `json:"contexts,omitempty"`
+	// Trash keeps encrypted copies of the secrets deleted by kds.
+	Trash trashConfig `json:"trash,omitempty"`
+	// Cache keeps encrypted copies of the secret lists between runs of the TUI.
+	Cache cacheConfig `json:"cache,omitempty"`
+	// History records the changes to secrets observed by kds.
+	History historyConfig `json:"history,omitempty"`
+	// SearchHistory remembers the searches typed in the TUI across runs.
+	SearchHistory searchHistoryConfig `json:"searchHistory,omitempty"`
+	// Rotation sets how keys are rotated.
+	Rotation rotationConfig `json:"rotation,omitempty"`
+	// Icons prefixes the secrets of the list with nerd-font icons of their type.
+	Icons bool `json:"icons,omitempty"`
+	// TypeColors colors the secrets of the list by type.
+	TypeColors bool `json:"typeColors,omitempty"`
+	// Watch keeps the list of the TUI up to date, like --watch.
+	Watch bool `json:"watch,omitempty"`
+	// Bell rings the terminal bell when a watched secret changes, like --bell.
+	Bell bool `json:"bell,omitempty"`
+}
+
+// defaultConfigPath returns the configuration file used when --config is not given,
+// e.g. ~/.config/kds/config.yaml on Linux.
+func defaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "kds", "config.yaml"), nil
+}
+
+// loadConfig reads the configuration file at path, or the default one if path is
+// empty. A missing default file is not an error: kds works without configuration.
+func loadConfig(path string) (*kdsConfig, error) {
+	explicit := path != ""
+	if !explicit {
+		var err error
+		if path, err = defaultConfigPath(); err != nil {
+			return &kdsConfig{}, nil
+		}
+	}
+	content, err := os.ReadFile(path) //nolint:gosec // The path is chosen by the user.
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return &kdsConfig{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var config kdsConfig
+	if err := yaml.UnmarshalStrict(content, &config); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return &config, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	"github.com/zalando/go-keyring"
+)
+
+// TestLoadConfig verifies that the config file is read, and optional unless given explicitly.
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	t.Run("should work without a default config file", func(t *testing.T) {
+		config, err := loadConfig("")
+		if err != nil || len(config.Decoders) != 0 {
+			t.Errorf("Expected an empty config, but got %+v (%v)", config, err)
+		}
+	})
+
+	t.Run("should keep previous values in a sibling key by default", func(t *testing.T) {
+		if keep, err := (rotationConfig{}).keepPrevious(); err != nil || keep != kube.KeepPreviousKey {
+			t.Errorf("Expected %s, but got %s (%v)", kube.KeepPreviousKey, keep, err)
+		}
+	})
+
+	t.Run("should fail on a missing explicit config file", func(t *testing.T) {
+		if _, err := loadConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := "decoders:\n- name: acme\n  keys: '\\.acme$'\n  command: [acme-decode, --pretty]\n  timeout: 2s\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	config, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if len(config.Decoders) != 1 || config.Decoders[0].Name != "acme" || config.Decoders[0].Timeout.Seconds() != 2 {
+		t.Errorf("Expected the acme decoder with a 2s timeout, but got %+v", config.Decoders)
+	}
+
+	t.Run("should reject unknown fields", func(t *testing.T) {
+		if err := os.WriteFile(path, []byte("decoder: []\n"), 0o600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		if _, err := loadConfig(path); err == nil {
+			t.Error("Expected an error for a misspelled field, but got none")
+		}
+	})
+}
+
+// TestConfirmProtected verifies that values of protected namespaces are only
+// accessed after a confirmation.
+func TestConfirmProtected(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("protectedNamespaces: [prod-*]\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	opts := &rootOptions{configPath: path}
+	for _, tc := range []struct {
+		name      string
+		namespace string
+		answer    string
+		allowed   bool
+	}{
+		{"should not ask outside protected namespaces", "dev", "", true},
+		{"should allow access once confirmed", "prod-eu", "y\n", true},
+		{"should refuse access without confirmation", "prod-eu", "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.SetIn(strings.NewReader(tc.answer))
+			cmd.SetErr(io.Discard)
+			err := opts.confirmProtected(cmd, "Reveal", kube.SecretRef{Namespace: tc.namespace, Name: "db"})
+			if allowed := err == nil; allowed != tc.allowed {
+				t.Errorf("Expected access allowed: %t, but got error %v", tc.allowed, err)
+			}
+			cmd.SetIn(strings.NewReader(tc.answer))
+			err = opts.confirmProtectedNamespace(cmd, tc.namespace)
+			if allowed := err == nil; allowed != tc.allowed {
+				t.Errorf("Expected the namespace allowed: %t, but got error %v", tc.allowed, err)
+			}
+		})
+	}
+}
+
+// TestOpenTrash verifies the trash section of the configuration file.
+func TestOpenTrash(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	opts := &rootOptions{configPath: path}
+	*opts.flags().Context = "prod"
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+
+	t.Run("should name the key after the trash directory", func(t *testing.T) {
+		write("trash:\n  dir: " + filepath.Join(dir, "trash") + "\n  retention: 72h\n")
+		if trash, err := opts.openTrash(); err != nil || trash == nil {
+			t.Fatalf("Expected a trash, but got %v (%v)", trash, err)
+		}
+		if _, err := keyring.Get("kds", filepath.Join(dir, "trash.key")); err != nil {
+			t.Errorf("Expected the key to be created, but got: %v", err)
+		}
+	})
+	t.Run("should reject invalid retentions", func(t *testing.T) {
+		write("trash:\n  dir: " + filepath.Join(dir, "trash") + "\n  retention: 7d\n")
+		if _, err := opts.openTrash(); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+	t.Run("should be disabled on request", func(t *testing.T) {
+		write("trash:\n  disabled: true\n")
+		if trash, err := opts.openTrash(); trash != nil || err != nil {
+			t.Errorf("Expected no trash, but got %v (%v)", trash, err)
+		}
+	})
+}
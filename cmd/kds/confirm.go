@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// askConfirmation asks a yes/no question on stderr and reads the answer from stdin.
+// Anything but "y" or "yes" (including end of input) counts as "no".
+func askConfirmation(cmd *cobra.Command, question string) bool {
+	cmd.PrintErrf("%s [y/N]: ", question)
+	answer, err := readLine(cmd.InOrStdin())
+	if err != nil && answer == "" {
+		cmd.PrintErrln()
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// askValue asks for a value on stderr and reads it from stdin. If hidden is set and
+// stdin is a terminal, the answer is not echoed. End of input counts as an empty answer.
+func askValue(cmd *cobra.Command, question string, hidden bool) (string, error) {
+	cmd.PrintErrf("%s: ", question)
+	if file, ok := cmd.InOrStdin().(*os.File); ok && hidden && term.IsTerminal(int(file.Fd())) {
+		answer, err := term.ReadPassword(int(file.Fd()))
+		cmd.PrintErrln()
+		return string(answer), err
+	}
+	answer, err := readLine(cmd.InOrStdin())
+	if errors.Is(err, io.EOF) {
+		cmd.PrintErrln()
+		return "", nil
+	}
+	return strings.TrimSpace(answer), err
+}
+
+// readLine reads a single line without buffering past its end, so that several
+// answers can be read from the same input one after another.
+func readLine(r io.Reader) (string, error) {
+	var line strings.Builder
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				return line.String(), nil
+			}
+			line.WriteByte(buf[0])
+		}
+		if errors.Is(err, io.EOF) && line.Len() > 0 {
+			return line.String(), nil
+		}
+		if err != nil {
+			return line.String(), err
+		}
+	}
+}
+
+// loadProtectedNamespaces returns the protected namespaces of the configuration file.
+func (o *rootOptions) loadProtectedNamespaces() (kube.ProtectedNamespaces, error) {
+	config, err := loadConfig(o.configPath)
+	if err != nil {
+		return nil, err
+	}
+	return kube.CompileProtectedNamespaces(config.ProtectedNamespaces)
+}
+
+// loadRedactionPolicy returns the redaction policy of the configuration file.
+func (o *rootOptions) loadRedactionPolicy() (kube.RedactionPolicy, error) {
+	config, err := loadConfig(o.configPath)
+	if err != nil {
+		return nil, err
+	}
+	return kube.CompileRedactionPolicy(config.Redaction)
+}
+
+// confirmProtectedNamespace asks before the values of every secret of a protected
+// namespace are made available, as by 'kds serve --allow-reveal'.
+func (o *rootOptions) confirmProtectedNamespace(cmd *cobra.Command, namespace string) error {
+	protected, err := o.loadProtectedNamespaces()
+	if err != nil {
+		return err
+	}
+	if !protected.Protects(namespace) {
+		return nil
+	}
+	if !askConfirmation(cmd, fmt.Sprintf("Namespace '%s' is protected. Allow revealing the values of its secrets?", namespace)) {
+		return fmt.Errorf("revealing the values of protected namespace '%s' aborted", namespace)
+	}
+	return nil
+}
+
+// confirmProtected asks before the values of a secret in a protected namespace are
+// revealed, copied, or exported, with verb naming what happens to them.
+func (o *rootOptions) confirmProtected(cmd *cobra.Command, verb string, ref kube.SecretRef) error {
+	protected, err := o.loadProtectedNamespaces()
+	if err != nil {
+		return err
+	}
+	if !protected.Protects(ref.Namespace) {
+		return nil
+	}
+	if !askConfirmation(cmd, fmt.Sprintf("Secret '%s' is in a protected namespace. %s its values?", ref, verb)) {
+		return fmt.Errorf("access to secret '%s' of protected namespace '%s' aborted", ref, ref.Namespace)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// Access levels of a context policy, from the least to the most strict.
+const (
+	accessReadOnly = "read-only" // Only reads and dry runs reach the API server.
+	accessBlocked  = "blocked"   // kds refuses to connect at all.
+)
+
+// contextPolicyConfig restricts what kds may do in some kubeconfig contexts.
+type contextPolicyConfig struct {
+	// Contexts are patterns of context names, where * matches any characters
+	// (including the slashes of EKS ARNs), e.g. prod-*.
+	Contexts []string `json:"contexts"`
+	// Access is read-only or blocked.
+	Access string `json:"access"`
+}
+
+// contextAccess returns the strictest access of the policies matching the context,
+// or "" if it is not restricted.
+func contextAccess(policies []contextPolicyConfig, context string) (string, error) {
+	var access string
+	for _, policy := range policies {
+		if policy.Access != accessReadOnly && policy.Access != accessBlocked {
+			return "", fmt.Errorf("context policy for %v has invalid access '%s': use read-only or blocked", policy.Contexts, policy.Access)
+		}
+		for _, pattern := range policy.Contexts {
+			if !matchContext(pattern, context) {
+				continue
+			}
+			if policy.Access == accessBlocked {
+				return accessBlocked, nil
+			}
+			access = policy.Access
+		}
+	}
+	return access, nil
+}
+
+// matchContext reports whether a context name matches a pattern, where * matches
+// any characters.
+func matchContext(pattern, context string) bool {
+	re := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	return regexp.MustCompile(re).MatchString(context)
+}
+
+// contextName returns the name of the selected kubeconfig context.
+func (o *rootOptions) contextName() (string, error) {
+	if context := *o.flags().Context; context != "" {
+		return context, nil
+	}
+	raw, err := o.clientConfig().RawConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	return raw.CurrentContext, nil
+}
+
+// enforceContextPolicy applies the context policies of the configuration file to
+// a REST configuration: blocked contexts are refused, and read-only ones get a
+// transport that refuses every mutation.
+func (o *rootOptions) enforceContextPolicy(restConfig *rest.Config) error {
+	config, err := loadConfig(o.configPath)
+	if err != nil {
+		return err
+	}
+	if len(config.Contexts) == 0 {
+		return nil
+	}
+	name, err := o.contextName()
+	if err != nil {
+		return err
+	}
+	access, err := contextAccess(config.Contexts, name)
+	if err != nil {
+		return err
+	}
+	switch access {
+	case accessBlocked:
+		return fmt.Errorf("context '%s' is blocked by the kds config", name)
+	case accessReadOnly:
+		restConfig.Wrap(func(next http.RoundTripper) http.RoundTripper {
+			return readOnlyTransport{context: name, next: next}
+		})
+	}
+	return nil
+}
+
+// readOnlyTransport refuses every request that could change the cluster.
+type readOnlyTransport struct {
+	context string
+	next    http.RoundTripper
+}
+
+// RoundTrip passes reads and server-side dry runs on, and refuses everything else.
+// Deletions carry their dry-run option in the body, and are always refused.
+func (t readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch {
+	case req.Method == http.MethodGet, req.Method == http.MethodHead, req.Method == http.MethodOptions:
+	case req.URL.Query().Get("dryRun") == "All":
+	default:
+		return nil, fmt.Errorf("context '%s' is read-only: refused %s %s", t.context, req.Method, req.URL.Path)
+	}
+	return t.next.RoundTrip(req)
+}
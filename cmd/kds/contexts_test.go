@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestContextAccess verifies that the strictest policy matching a context wins.
+func TestContextAccess(t *testing.T) {
+	policies := []contextPolicyConfig{
+		{Contexts: []string{"prod-*", "*:cluster/prod"}, Access: accessReadOnly},
+		{Contexts: []string{"prod-legacy"}, Access: accessBlocked},
+	}
+	for name, expected := range map[string]string{
+		"prod-eu":                              accessReadOnly,
+		"arn:aws:eks:eu-west-1:1:cluster/prod": accessReadOnly,
+		"prod-legacy":                          accessBlocked,
+		"dev":                                  "",
+	} {
+		if access, err := contextAccess(policies, name); err != nil || access != expected {
+			t.Errorf("Expected access '%s' for context %s, but got '%s' (%v)", expected, name, access, err)
+		}
+	}
+	t.Run("should reject unknown access levels", func(t *testing.T) {
+		if _, err := contextAccess([]contextPolicyConfig{{Contexts: []string{"*"}, Access: "readonly"}}, "dev"); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+}
+
+// TestReadOnlyContext verifies that mutations never reach the API server of a
+// read-only context, while reads and dry runs do.
+func TestReadOnlyContext(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"Secret","apiVersion":"v1","metadata":{"name":"db","namespace":"prod"}}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	content := "apiVersion: v1\nkind: Config\ncurrent-context: prod\ncontexts:\n- name: prod\n  context: {cluster: prod}\nclusters:\n- name: prod\n  cluster: {server: " + server.URL + "}\n"
+	config := filepath.Join(dir, "config.yaml")
+	for path, data := range map[string]string{kubeconfig: content, config: "contexts:\n- contexts: [prod]\n  access: read-only\n"} {
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	opts := newTestOptions(kubeconfig)
+	opts.configPath = config
+	clientset, err := opts.newClientset()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	secrets := clientset.CoreV1().Secrets("prod")
+	if _, err := secrets.Get(context.TODO(), "db", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected reads to work, but got: %v", err)
+	}
+	if err := secrets.Delete(context.TODO(), "db", metav1.DeleteOptions{}); err == nil || !strings.Contains(err.Error(), "context 'prod' is read-only") {
+		t.Errorf("Expected the delete to be refused, but got: %v", err)
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"}}
+	if _, err := secrets.Update(context.TODO(), secret, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}}); err != nil {
+		t.Errorf("Expected dry runs to work, but got: %v", err)
+	}
+	if strings.Join(requests, ",") != "GET,PUT" {
+		t.Errorf("Expected only the read and the dry run to reach the server, but got %v", requests)
+	}
+
+	t.Run("should refuse blocked contexts", func(t *testing.T) {
+		if err := os.WriteFile(config, []byte("contexts:\n- contexts: [prod]\n  access: blocked\n"), 0o600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		if _, err := opts.newClientset(); err == nil || !strings.Contains(err.Error(), "blocked") {
+			t.Errorf("Expected the context to be blocked, but got: %v", err)
+		}
+	})
+}
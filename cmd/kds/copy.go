@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// newCopyCmd creates the 'kds copy' command, which recreates a secret in another
+// namespace or another cluster.
+func newCopyCmd(opts *rootOptions) *cobra.Command {
+	copyOpts := &kube.CopyOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "copy <secret-name>",
+		Short: "Copy a secret to another namespace or cluster",
+		Long: `Copy a secret to another namespace, or to another cluster with --to-context.
+
+The copy keeps the data, type, labels, and annotations of the source, but not its
+owner references, resource version, or other server-populated metadata. If the
+secret already exists in the target namespace, kds asks before overwriting it.
+
+With --to-context, the target cluster is reached through another context of the
+same kubeconfig, and the secret keeps its namespace unless --to-namespace is given.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCopy(cmd, opts, copyOpts, args[0])
+		},
+	}
+	cmd.Flags().StringVar(&copyOpts.ToNamespace, "to-namespace", "", "namespace to copy the secret into")
+	cmd.Flags().StringVar(&copyOpts.ToContext, "to-context", "", "kubeconfig context of the cluster to copy the secret into")
+	cmd.Flags().BoolVar(&copyOpts.Overwrite, "overwrite", false, "replace the secret in the target namespace if it already exists")
+	cobra.CheckErr(cmd.RegisterFlagCompletionFunc("to-namespace", completeNamespaces(opts)))
+	cobra.CheckErr(cmd.RegisterFlagCompletionFunc("to-context", completeContexts(opts)))
+	return cmd
+}
+
+// runCopy is the action of the 'kds copy' command.
+func runCopy(cmd *cobra.Command, opts *rootOptions, copyOpts *kube.CopyOptions, name string) error {
+	if copyOpts.ToNamespace == "" && copyOpts.ToContext == "" {
+		return errors.New("a target is required (use --to-namespace and/or --to-context)")
+	}
+	clientset, err := opts.newClientset()
+	if err != nil {
+		return err
+	}
+	namespace, err := opts.resolveNamespace()
+	if err != nil {
+		return err
+	}
+	ref := kube.SecretRef{Namespace: namespace, Name: name}
+	toNamespace := copyOpts.ToNamespace
+	if toNamespace == "" {
+		toNamespace = namespace
+	}
+
+	if err := opts.confirmProtected(cmd, "Copy", ref); err != nil {
+		return err
+	}
+
+	var target kube.Client = clientset
+	destination := fmt.Sprintf("namespace '%s'", toNamespace)
+	if copyOpts.ToContext != "" {
+		if target, err = opts.newClientsetForContext(copyOpts.ToContext); err != nil {
+			return err
+		}
+		destination = fmt.Sprintf("namespace '%s' of context '%s'", toNamespace, copyOpts.ToContext)
+	} else if toNamespace == namespace {
+		return fmt.Errorf("secret '%s' cannot be copied onto itself", ref)
+	}
+
+	err = kube.CopySecretTo(clientset, target, ref, toNamespace, copyOpts.Overwrite)
+	if apierrors.IsAlreadyExists(err) {
+		if !askConfirmation(cmd, fmt.Sprintf("Secret '%s' already exists in %s. Overwrite?", ref.Name, destination)) {
+			return fmt.Errorf("secret '%s' already exists in %s (use --overwrite)", ref.Name, destination)
+		}
+		err = kube.CopySecretTo(clientset, target, ref, toNamespace, true)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to copy secret '%s': %w", ref, err)
+	}
+	cmd.PrintErrf("Copied secret '%s' to %s\n", ref, destination)
+	return nil
+}
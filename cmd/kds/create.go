@@ -0,0 +1,17 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// newCreateCmd creates the 'kds create' command, which groups the secret creation subcommands.
+func newCreateCmd(opts *rootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a secret",
+	}
+	cmd.AddCommand(newCreateTLSCmd(opts))
+	cmd.AddCommand(newCreateDockerRegistryCmd(opts))
+	cmd.AddCommand(newCreateGenericCmd(opts))
+	return cmd
+}
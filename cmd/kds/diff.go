@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// shortChecksumLength is how many hex digits of a checksum 'kds diff' prints.
+const shortChecksumLength = 12
+
+// newDiffCmd creates the 'kds diff' command.
+func newDiffCmd(opts *rootOptions) *cobra.Command {
+	var file, vaultPath, store, item, snapshot string
+	var showValues bool
+
+	cmd := &cobra.Command{
+		Use:   "diff <secret-name> --file <file> | --vault <path> | --store <store> | [secret-name] --snapshot <file>",
+		Short: "Compare a secret with a local file, a Vault secret, or a store item, or a namespace with a snapshot",
+		Long: `Compare the keys and values of a secret with a local file: a .env file, or, for
+.yaml, .yml, and .json files, a Secret manifest or a flat map of keys to values.
+
+With --store, it is compared with an item of a secret store, such as vault,
+doppler, infisical, 1password, bitwarden, or a store of the config file, as 'kds
+pull' reads it. The item defaults to <namespace>/<secret-name>: for Vault, it is
+the API path of a secret of a KV engine, as secret/data/app/prod for version 2
+engines, for Doppler, the config, as <project>/<config>, and for Infisical, the
+environment followed by the path of a folder, as prod/app/db. Vault is reached
+like the Vault CLI reaches it, with VAULT_ADDR, VAULT_NAMESPACE, and the token in
+VAULT_TOKEN or written by 'vault login'. --vault <path> is short for --store vault
+--item <path>.
+
+Keys only in the secret are printed with a "-", keys only in the file with a "+",
+and keys whose values differ with a "~" and the SHA-256 checksums of both values,
+or the values themselves with --show-values. kds exits with a non-zero code if
+anything differs.
+
+With --snapshot, the secrets of the namespace, or only the given one, are compared
+with a snapshot saved earlier: the output of 'kubectl get secrets -o yaml' or -o
+json, Secret manifests, or an export of kds. Secrets created since the snapshot are
+printed with a "+", deleted ones with a "-", and changed ones with a "~" followed
+by their changed keys, each as it changed from the snapshot to now.`,
+		Example: `  # Check that what is deployed matches the ticket
+  kds diff app-config --file app-config.env
+
+  # Detect drift from the source of truth in Vault
+  kds diff app-config --vault secret/data/app/prod
+
+  # Or in Doppler
+  kds diff app-config --store doppler --item payments/prd
+
+  # Find out what changed in a namespace since a backup
+  kds diff -n prod --snapshot backup-2024-01.yaml`,
+		Args:              cobra.RangeArgs(0, 1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if sources := countNonEmpty(file, vaultPath, store, snapshot); sources != 1 {
+				return errors.New("exactly one of --file, --vault, --store, and --snapshot is required")
+			}
+			if snapshot != "" {
+				return runSnapshotDiff(cmd, opts, snapshot, args, showValues)
+			}
+			if len(args) == 0 {
+				return errors.New("a secret name is required with --file, --vault, and --store")
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			other, source, err := loadComparedData(opts, file, vaultPath, store, item, ref)
+			if err != nil {
+				return err
+			}
+			if showValues {
+				if err := opts.confirmProtected(cmd, "Reveal", ref); err != nil {
+					return err
+				}
+			}
+			secret, err := kube.GetSecret(clientset, ref)
+			if err != nil {
+				return err
+			}
+			changes := kube.CompareData(secret.Data, other)
+			if err := printComparison(cmd.OutOrStdout(), secret.Data, other, changes, showValues); err != nil {
+				return err
+			}
+			if len(changes) > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("secret '%s' and %s differ: %s", ref, source, kube.SummarizeDataDiff(changes))
+			}
+			cmd.PrintErrf("Secret '%s' and %s match\n", ref, source)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&file, "file", "", "local .env, YAML, or JSON file to compare the secret with")
+	cmd.Flags().StringVar(&vaultPath, "vault", "", "API path of a Vault KV secret to compare the secret with, e.g. secret/data/app/prod (short for --store vault --item <path>)")
+	cmd.Flags().StringVar(&store, "store", "", "secret store to compare the secret with an item of: vault, doppler, infisical, 1password, bitwarden, or a store of the config file")
+	cmd.Flags().StringVar(&item, "item", "", "item of the store to compare the secret with (default <namespace>/<secret-name>)")
+	cmd.Flags().StringVar(&snapshot, "snapshot", "", "snapshot file of the secrets of the namespace to compare them with")
+	cmd.Flags().BoolVar(&showValues, "show-values", false, "print the differing values instead of their checksums")
+	return cmd
+}
+
+// loadComparedData reads the data to compare a secret with from a local file or
+// from an item of a secret store, Vault included, and describes where it comes
+// from.
+func loadComparedData(opts *rootOptions, file, vaultPath, store, item string, ref kube.SecretRef) (map[string][]byte, string, error) {
+	if file != "" {
+		data, err := kube.LoadLocalData(file)
+		return data, file, err
+	}
+	var s kube.SecretStore
+	var err error
+	if vaultPath != "" {
+		// Not through findStore, since the config file may name another store vault.
+		s, err = kube.FindStore(nil, kube.StoreVault)
+		item = vaultPath
+	} else {
+		s, err = findStore(opts, store)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if item == "" {
+		item = ref.String()
+	}
+	data, err := s.Pull(kube.StoreItem{Item: item, Namespace: ref.Namespace, Secret: ref.Name})
+	return data, fmt.Sprintf("item '%s' of %s", item, s.Name), err
+}
+
+// printComparison writes one line per differing key. Differing values are shown
+// as short checksums, or quoted if showValues is set.
+func printComparison(w io.Writer, secret, other map[string][]byte, changes []kube.DataChange, showValues bool) error {
+	format := func(value []byte) string {
+		if showValues {
+			return strconv.Quote(string(value))
+		}
+		return "sha256:" + kube.Checksum(value)[:shortChecksumLength]
+	}
+	var b strings.Builder
+	for _, change := range changes {
+		if change.Kind == kube.KeyChanged {
+			fmt.Fprintf(&b, "%s %s: %s != %s\n", change.Kind, change.Key, format(secret[change.Key]), format(other[change.Key]))
+		} else {
+			fmt.Fprintf(&b, "%s %s\n", change.Kind, change.Key)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// countNonEmpty returns how many of the values are set.
+func countNonEmpty(values ...string) int {
+	count := 0
+	for _, value := range values {
+		if value != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// runSnapshotDiff compares the secrets of the namespace, or only the named one,
+// with a snapshot file, and fails if anything changed.
+func runSnapshotDiff(cmd *cobra.Command, opts *rootOptions, path string, args []string, showValues bool) error {
+	snapshot, err := kube.LoadSnapshotFile(path)
+	if err != nil {
+		return err
+	}
+	clientset, err := opts.newClientset()
+	if err != nil {
+		return err
+	}
+	namespace, err := opts.resolveNamespace()
+	if err != nil {
+		return err
+	}
+	if showValues {
+		ref := kube.SecretRef{Namespace: namespace, Name: "*"}
+		if len(args) == 1 {
+			ref.Name = args[0]
+		}
+		if err := opts.confirmProtected(cmd, "Reveal", ref); err != nil {
+			return err
+		}
+	}
+	list, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+	live := list.Items
+	if len(args) == 1 {
+		snapshot = slices.DeleteFunc(snapshot, func(s *corev1.Secret) bool { return s.Name != args[0] })
+		live = slices.DeleteFunc(live, func(s corev1.Secret) bool { return s.Name != args[0] })
+	}
+	drifts, err := kube.CompareSnapshot(snapshot, live, namespace)
+	if err != nil {
+		return err
+	}
+	if err := printSnapshotDiff(cmd.OutOrStdout(), namespace, snapshot, live, drifts, showValues); err != nil {
+		return err
+	}
+	if len(drifts) > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d secret(s) of namespace '%s' differ from %s", len(drifts), namespace, path)
+	}
+	cmd.PrintErrf("The secrets of namespace '%s' match %s\n", namespace, path)
+	return nil
+}
+
+// printSnapshotDiff writes one line per created, deleted, or changed secret, with
+// the changed keys of changed secrets below it, from the snapshot to now.
+func printSnapshotDiff(w io.Writer, namespace string, snapshot []*corev1.Secret, live []corev1.Secret, drifts []kube.SecretDrift, showValues bool) error {
+	before := make(map[string]map[string][]byte, len(snapshot))
+	for _, secret := range snapshot {
+		if secret.Namespace == namespace || secret.Namespace == "" {
+			before[secret.Name] = secret.Data
+		}
+	}
+	after := make(map[string]map[string][]byte, len(live))
+	for _, secret := range live {
+		after[secret.Name] = secret.Data
+	}
+	var b strings.Builder
+	for _, drift := range drifts {
+		switch drift.Kind {
+		case kube.KeyAdded:
+			fmt.Fprintf(&b, "+ %s (created, %d keys)\n", drift.Name, len(drift.Changes))
+		case kube.KeyRemoved:
+			fmt.Fprintf(&b, "- %s (deleted, %d keys)\n", drift.Name, len(drift.Changes))
+		default:
+			fmt.Fprintf(&b, "~ %s (%s)\n", drift.Name, kube.SummarizeDataDiff(drift.Changes))
+			var keys strings.Builder
+			if err := printComparison(&keys, before[drift.Name], after[drift.Name], drift.Changes, showValues); err != nil {
+				return err
+			}
+			for _, line := range strings.SplitAfter(keys.String(), "\n") {
+				if line != "" {
+					b.WriteString("    " + line)
+				}
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
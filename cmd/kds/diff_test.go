@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestPrintComparison verifies that differing values are hidden unless asked for.
+func TestPrintComparison(t *testing.T) {
+	secret := map[string][]byte{"password": []byte("old"), "legacy": []byte("x")}
+	other := map[string][]byte{"password": []byte("new"), "token": []byte("y")}
+	changes := kube.CompareData(secret, other)
+
+	t.Run("should print checksums of differing values", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := printComparison(&out, secret, other, changes, false); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		expected := "- legacy\n~ password: sha256:" + kube.Checksum([]byte("old"))[:12] + " != sha256:" + kube.Checksum([]byte("new"))[:12] + "\n+ token\n"
+		if out.String() != expected {
+			t.Errorf("Expected %q, but got %q", expected, out.String())
+		}
+	})
+	t.Run("should print the values with --show-values", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := printComparison(&out, secret, other, changes, true); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if expected := "- legacy\n~ password: \"old\" != \"new\"\n+ token\n"; out.String() != expected {
+			t.Errorf("Expected %q, but got %q", expected, out.String())
+		}
+	})
+}
+
+// TestPrintSnapshotDiff verifies that created, deleted, and changed secrets are
+// printed, with the changed keys of changed secrets indented below them.
+func TestPrintSnapshotDiff(t *testing.T) {
+	before := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app"}, Data: map[string][]byte{"password": []byte("old")}}
+	gone := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "gone"}, Data: map[string][]byte{"k": []byte("v")}}
+	snapshot := []*corev1.Secret{&before, &gone}
+	live := []corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "app"}, Data: map[string][]byte{"password": []byte("new")}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "new"}, Data: map[string][]byte{"k": []byte("v")}},
+	}
+	drifts, err := kube.CompareSnapshot(snapshot, live, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should print each differing secret", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := printSnapshotDiff(&out, "prod", snapshot, live, drifts, true); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		expected := "~ app (" + kube.SummarizeDataDiff(drifts[0].Changes) + ")\n    ~ password: \"old\" != \"new\"\n- gone (deleted, 1 keys)\n+ new (created, 1 keys)\n"
+		if out.String() != expected {
+			t.Errorf("Expected %q, but got %q", expected, out.String())
+		}
+	})
+}
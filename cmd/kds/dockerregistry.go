@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newCreateDockerRegistryCmd creates the 'kds create docker-registry' command.
+func newCreateDockerRegistryCmd(opts *rootOptions) *cobra.Command {
+	var creds kube.RegistryCredentials
+	var serviceAccount, dryRun string
+
+	cmd := &cobra.Command{
+		Use:   "docker-registry <name>",
+		Short: "Create a secret for pulling images from a private registry",
+		Long: `Create a kubernetes.io/dockerconfigjson secret for pulling images from a private registry.
+
+Login details not given as flags are asked for interactively; the password is not
+echoed. With --service-account, the secret is also added to the imagePullSecrets of
+that service account, so its pods can use it without changing their specs.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := askRegistryCredentials(cmd, &creds); err != nil {
+				return err
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			secret, err := kube.BuildDockerRegistrySecret(namespace, args[0], creds)
+			if err != nil {
+				return err
+			}
+			if applied, err := runMutation(cmd, clientset, kube.Mutation{After: secret}, dryRun, false); err != nil || !applied {
+				return err
+			}
+			cmd.PrintErrf("Created secret '%s/%s'\n", namespace, args[0])
+			if serviceAccount == "" {
+				return nil
+			}
+			if err := kube.LinkServiceAccount(clientset, secret, serviceAccount); err != nil {
+				return err
+			}
+			cmd.PrintErrf("Added it to the imagePullSecrets of service account '%s'\n", serviceAccount)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&creds.Server, "docker-server", "", "registry server (default "+kube.DefaultRegistryServer+")")
+	cmd.Flags().StringVar(&creds.Username, "docker-username", "", "registry username")
+	cmd.Flags().StringVar(&creds.Password, "docker-password", "", "registry password")
+	cmd.Flags().StringVar(&creds.Email, "docker-email", "", "registry email (optional)")
+	cmd.Flags().StringVar(&serviceAccount, "service-account", "", "add the secret to the imagePullSecrets of this service account")
+	addMutationFlags(cmd, &dryRun)
+	return cmd
+}
+
+// askRegistryCredentials asks for the login details that were not given as flags.
+func askRegistryCredentials(cmd *cobra.Command, creds *kube.RegistryCredentials) error {
+	questions := []struct {
+		flag     string
+		question string
+		value    *string
+		hidden   bool
+	}{
+		{"docker-server", "Registry server [" + kube.DefaultRegistryServer + "]", &creds.Server, false},
+		{"docker-username", "Username", &creds.Username, false},
+		{"docker-password", "Password", &creds.Password, true},
+		{"docker-email", "Email (optional)", &creds.Email, false},
+	}
+	for _, q := range questions {
+		if cmd.Flags().Changed(q.flag) {
+			continue
+		}
+		answer, err := askValue(cmd, q.question, q.hidden)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", q.flag, err)
+		}
+		*q.value = answer
+	}
+	if creds.Server == "" {
+		creds.Server = kube.DefaultRegistryServer
+	}
+	if creds.Username == "" || creds.Password == "" {
+		return errors.New("a username and password are required")
+	}
+	return nil
+}
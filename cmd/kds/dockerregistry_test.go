@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// TestAskRegistryCredentials verifies that missing login details are read from stdin.
+func TestAskRegistryCredentials(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("docker-server", "", "")
+	cmd.Flags().String("docker-username", "", "")
+	cmd.Flags().String("docker-password", "", "")
+	cmd.Flags().String("docker-email", "", "")
+	cmd.SetIn(strings.NewReader("\nbot\nt0ken\n"))
+	cmd.SetErr(&strings.Builder{})
+
+	var creds kube.RegistryCredentials
+	if err := askRegistryCredentials(cmd, &creds); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := kube.RegistryCredentials{Server: kube.DefaultRegistryServer, Username: "bot", Password: "t0ken"}
+	if creds != expected {
+		t.Errorf("Expected %+v, but got %+v", expected, creds)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/diskmanti/kds/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// newDriftCmd creates the 'kds drift' command.
+func newDriftCmd(opts *rootOptions) *cobra.Command {
+	var path string
+
+	cmd := &cobra.Command{
+		Use:   "drift --path <dir>",
+		Short: "Compare the secrets of the cluster with the manifests of a GitOps repository",
+		Long: `Compare the secrets of the cluster with the Secrets declared in the YAML and JSON
+manifests under a directory, such as a checkout of a GitOps repository.
+
+Secrets encrypted with SOPS are decrypted with the sops CLI, which must be
+installed and able to read the keys, as it is for 'sops -d'. SealedSecrets can
+only be decrypted by their controller, so only their keys are compared. Manifests
+are read as they are: kustomize overlays and Helm charts are not rendered, so
+point --path at plain or rendered manifests. Secrets without a namespace are taken
+to be of the namespace of the command.
+
+Each secret that differs is printed with the file declaring it: missing from the
+cluster, or with its differing keys, where "+" is a key only in the cluster, "-" a
+key only in the manifest, and "~" a key whose value differs. Values are never
+printed. kds exits with a non-zero code if any secret drifted.`,
+		Example: `  # Check that production matches the repository
+  kds drift --path ./clusters/prod`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if path == "" {
+				return errors.New("--path is required")
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			manifests, warnings, err := kube.LoadManifestSecrets(path, namespace, kube.DecryptSOPS)
+			if err != nil {
+				return err
+			}
+			for _, warning := range warnings {
+				cmd.PrintErrln("Warning: " + warning)
+			}
+			if len(manifests) == 0 {
+				return fmt.Errorf("no secrets declared in %s", path)
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			drifts, err := kube.CompareManifests(clientset, manifests)
+			if err != nil {
+				return err
+			}
+			if err := printDrifts(cmd.OutOrStdout(), drifts); err != nil {
+				return err
+			}
+			if len(drifts) > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d secrets drifted from %s", len(drifts), len(manifests), path)
+			}
+			cmd.PrintErrf("All %d secrets match %s\n", len(manifests), path)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&path, "path", "", "directory of the manifests to compare the cluster with")
+	return cmd
+}
+
+// printDrifts writes one line per drifted secret, with its differing keys below it.
+func printDrifts(w io.Writer, drifts []kube.ManifestDrift) error {
+	var b strings.Builder
+	for _, drift := range drifts {
+		source := drift.Manifest.Path
+		if drift.Manifest.Sealed {
+			source += ", sealed: keys only"
+		}
+		if drift.Missing {
+			fmt.Fprintf(&b, "- %s (%s): missing from the cluster\n", drift.Manifest.Ref, source)
+			continue
+		}
+		fmt.Fprintf(&b, "~ %s (%s): %s\n", drift.Manifest.Ref, source, kube.SummarizeDataDiff(drift.Changes))
+		b.WriteString(ui.RenderDataDiff(drift.Changes))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
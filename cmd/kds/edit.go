@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/diskmanti/kds/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// newEditCmd creates the 'kds edit' command.
+func newEditCmd(opts *rootOptions) *cobra.Command {
+	var dryRun string
+	var restart bool
+	var generate []string
+
+	cmd := &cobra.Command{
+		Use:   "edit <secret-name>",
+		Short: "Edit the decoded values of a secret in $EDITOR",
+		Long: `Edit the decoded values of a secret in $EDITOR.
+
+The secret's keys and values are opened as YAML in $KUBE_EDITOR or $EDITOR (vi by
+default). Changed, added, and removed keys are saved back to the secret when the
+editor exits. Immutable secrets cannot be edited.
+
+With --generate key=kind[:length], the key is filled in with a random value before
+the editor opens, so new credentials never have to be made up. The kinds are alnum,
+hex, uuid, passphrase (length in words), rsa (length in bits), and ed25519; keypairs
+fill in the private key under the key and the public key under key.pub.
+
+If the secret changed on the server while the editor was open, the edit is merged
+with those changes instead of overwriting them: kds shows how each key changed in
+the edit and on the server, asks which value to keep for the keys changed on both,
+and confirms the merged changes before saving them.
+
+With --dry-run=server, the update is checked by the API server first, and the
+resulting changes are shown for confirmation before they are saved.
+
+After saving, kds offers to restart the Deployments, StatefulSets, and DaemonSets
+that use the secret, so they pick up the new values.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			if err := opts.confirmProtected(cmd, "Edit", ref); err != nil {
+				return err
+			}
+			generated, err := kube.GenerateData(generate)
+			if err != nil {
+				return err
+			}
+			secret, path, err := kube.PrepareEdit(clientset, ref, generated)
+			if err != nil {
+				return fmt.Errorf("cannot edit secret '%s': %w", ref, err)
+			}
+			if _, managed := kube.GitOpsManagerOf(secret); managed {
+				warnGitOpsManaged(cmd, secret)
+				if !askConfirmation(cmd, "Edit it anyway?") {
+					_ = os.Remove(path)
+					cmd.PrintErrf("No changes made to secret '%s'\n", ref)
+					return nil
+				}
+			}
+			editor := ui.EditorCommand(path)
+			editor.Stdin, editor.Stdout, editor.Stderr = cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr()
+			mu, changed, err := kube.ReadEdit(secret, path, editor.Run())
+			if err != nil {
+				return fmt.Errorf("failed to edit secret '%s': %w", ref, err)
+			}
+			if !changed {
+				cmd.PrintErrf("Edit of secret '%s' cancelled, no changes made\n", ref)
+				return nil
+			}
+			merge, err := kube.MergeEdit(clientset, mu)
+			if err != nil {
+				return fmt.Errorf("failed to edit secret '%s': %w", ref, err)
+			}
+			confirm := merge != nil
+			if confirm {
+				mu = resolveEditMerge(cmd, ref, merge)
+			}
+			if applied, err := runMutation(cmd, clientset, mu, dryRun, confirm); err != nil || !applied {
+				return err
+			}
+			cmd.PrintErrf("Edited secret '%s'\n", ref)
+			return offerRestart(cmd, clientset, ref, restart)
+		},
+	}
+	addMutationFlags(cmd, &dryRun)
+	cmd.Flags().BoolVar(&restart, "restart", false, "restart the workloads using the secret without asking")
+	cmd.Flags().StringArrayVar(&generate, "generate", nil, "a key=kind[:length] to fill in with a random value (repeatable)")
+	return cmd
+}
+
+// resolveEditMerge shows how a secret changed on the server while it was edited,
+// asks which value to keep for each key changed on both sides, and returns the
+// merged update.
+func resolveEditMerge(cmd *cobra.Command, ref kube.SecretRef, merge *kube.EditMerge) kube.Mutation {
+	cmd.PrintErrf("Secret '%s' changed on the server while it was being edited:\n%s", ref, merge)
+	for _, key := range merge.Conflicts() {
+		merge.Resolve(key, askConfirmation(cmd, fmt.Sprintf("Key '%s' was changed both in the edit and on the server. Keep your value?", key)))
+	}
+	return merge.Mutation
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newEnvCmd creates the 'kds env' command.
+func newEnvCmd(opts *rootOptions) *cobra.Command {
+	var envOpts kube.EnvOptions
+
+	cmd := &cobra.Command{
+		Use:   "env <secret-name>",
+		Short: "Print the keys of a secret as shell export lines",
+		Long: `Print the keys of a secret as "export NAME='value'" lines, to load them into the
+current shell.
+
+Keys become upper-case variable names, or lower-case ones with --lowercase, with
+characters that are not allowed in names replaced by underscores and --prefix put
+in front. Values are single-quoted, so they are never expanded by the shell. Keys
+masked by the redaction policy are left out.`,
+		Example: `  # Load a secret into the current shell
+  eval "$(kds env app-secret)"
+
+  # Prefix the variables to avoid clashes
+  eval "$(kds env db-credentials --prefix DB_)"`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			vars, err := secretEnvVars(cmd, opts, clientset, ref, envOpts)
+			if err != nil {
+				return err
+			}
+			return printEnvExports(cmd.OutOrStdout(), vars)
+		},
+	}
+	cmd.Flags().StringVar(&envOpts.Prefix, "prefix", "", "prepend this prefix to every variable name")
+	cmd.Flags().BoolVar(&envOpts.Lowercase, "lowercase", false, "use lower-case variable names")
+	return cmd
+}
+
+// secretEnvVars reads the keys of a secret as environment variables, after
+// confirming access to protected namespaces. Keys masked by the redaction policy
+// are left out with a warning, and those that need a confirmation are only kept
+// once it is given.
+func secretEnvVars(cmd *cobra.Command, opts *rootOptions, clientset kube.Client, ref kube.SecretRef, envOpts kube.EnvOptions) ([]kube.EnvVar, error) {
+	if err := opts.confirmProtected(cmd, "Reveal", ref); err != nil {
+		return nil, err
+	}
+	redaction, err := opts.loadRedactionPolicy()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := kube.GetSecret(clientset, ref)
+	if err != nil {
+		return nil, err
+	}
+	data := maps.Clone(secret.Data)
+	omitted := kube.KeysWithMode(redaction, data, kube.RedactMask)
+	if keys := kube.KeysWithMode(redaction, data, kube.RedactConfirm); len(keys) > 0 &&
+		!askConfirmation(cmd, fmt.Sprintf("Reveal %s, redacted by policy?", strings.Join(keys, ", "))) {
+		omitted = append(omitted, keys...)
+	}
+	for _, key := range omitted {
+		delete(data, key)
+	}
+	if len(omitted) > 0 {
+		cmd.PrintErrf("Warning: left out %s, redacted by policy\n", strings.Join(omitted, ", "))
+	}
+	return kube.EnvVars(data, envOpts)
+}
+
+// printEnvExports writes one shell export line per variable.
+func printEnvExports(w io.Writer, vars []kube.EnvVar) error {
+	var b strings.Builder
+	for _, v := range vars {
+		fmt.Fprintf(&b, "export %s=%s\n", v.Name, kube.ShellQuote(string(v.Value)))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newExportCmd creates the 'kds export' command, which writes a secret to local files.
+func newExportCmd(opts *rootOptions) *cobra.Command {
+	exportOpts := &kube.ExportOptions{}
+	bulk := &bulkOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "export <secret-name> | --selector <selector> --all-matching",
+		Short: "Export the keys of a secret to local files",
+		Long: `Export the keys of a secret to local files.
+
+With --dir, every key is written to its own file inside the directory, named after
+the key and containing the raw value, just like the secret looks when mounted as a
+volume. Files are created with 0600 permissions.
+
+With --archive, the same files are packaged into a .tar.gz/.tgz or .zip archive,
+optionally together with a <secret>.metadata.yaml describing the secret, next to
+the directory of the keys.
+
+With --kustomize, a secretGenerator that recreates the secret is added to the
+kustomization.yaml in the directory, along with the env file and files it refers to.
+
+With --dotenv, the keys are written to a .env file, which docker compose can also
+use as an env_file. Keys are named like 'kds env' names them, with --prefix and
+--lowercase, and values are quoted when needed.
+
+With --sops, the secret is written as a Secret manifest encrypted with SOPS, with
+the keys of the creation rule of .sops.yaml matching the file, so it can go
+straight into a GitOps repository. The sops CLI must be installed; the values are
+passed to it on stdin and never written to disk unencrypted.
+
+With --selector and --all-matching, every secret matching the label selector is
+written with --dir into a subdirectory named after it. The matching secrets are
+listed first, and the export has to be confirmed unless --yes is set.
+
+A secret with keys that the redaction rules of the config file mask is not
+exported, and one with keys to confirm only once its export is confirmed.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if exportOpts.Destinations() != 1 {
+				return errors.New("exactly one export destination is required (use --dir, --archive, --kustomize, --dotenv, or --sops)")
+			}
+			if err := bulk.validate(args); err != nil {
+				return err
+			}
+			if bulk.selector != "" && exportOpts.Dir == "" {
+				return errors.New("--selector can only export with --dir")
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			redaction, err := opts.loadRedactionPolicy()
+			if err != nil {
+				return err
+			}
+			if bulk.selector != "" {
+				return exportSelected(cmd, opts, bulk, clientset, namespace, exportOpts.Dir, redaction)
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			if err := opts.confirmProtected(cmd, "Export", ref); err != nil {
+				return err
+			}
+			if err := confirmRedactedExport(cmd, clientset, ref, redaction); err != nil {
+				return err
+			}
+			if exportOpts.Kustomize != "" {
+				if err := kube.WriteKustomizeExport(clientset, ref, exportOpts.Kustomize); err != nil {
+					return err
+				}
+				cmd.PrintErrf("Added a secretGenerator for secret '%s' to %s\n", ref, filepath.Join(exportOpts.Kustomize, kube.KustomizationFile))
+				return nil
+			}
+			if exportOpts.Dotenv != "" {
+				if err := kube.WriteDotenvFile(clientset, ref, exportOpts.Dotenv, exportOpts.Env); err != nil {
+					return err
+				}
+				cmd.PrintErrf("Wrote secret '%s' to %s\n", ref, exportOpts.Dotenv)
+				return nil
+			}
+			if exportOpts.SOPS != "" {
+				if err := kube.WriteSOPSExport(clientset, ref, exportOpts.SOPS, kube.EncryptSOPS); err != nil {
+					return err
+				}
+				cmd.PrintErrf("Wrote secret '%s' encrypted with SOPS to %s\n", ref, exportOpts.SOPS)
+				return nil
+			}
+			if exportOpts.Archive != "" {
+				if err := kube.WriteSecretArchive(clientset, ref, exportOpts.Archive, exportOpts.WithMetadata); err != nil {
+					return err
+				}
+				cmd.PrintErrf("Wrote secret '%s' to %s\n", ref, exportOpts.Archive)
+				return nil
+			}
+			if err := kube.WriteSecretFiles(clientset, ref, exportOpts.Dir); err != nil {
+				return err
+			}
+			cmd.PrintErrf("Wrote the keys of secret '%s' to %s\n", ref, exportOpts.Dir)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&exportOpts.Dir, "dir", "", "directory to write one file per key into")
+	cmd.Flags().StringVar(&exportOpts.Archive, "archive", "", "archive to write the keys into (.tar.gz, .tgz, or .zip)")
+	cmd.Flags().StringVar(&exportOpts.Kustomize, "kustomize", "", "directory to add a kustomize secretGenerator for the secret to")
+	cmd.Flags().StringVar(&exportOpts.Dotenv, "dotenv", "", "dotenv file to write the keys into, e.g. .env")
+	cmd.Flags().StringVar(&exportOpts.SOPS, "sops", "", "file to write the secret into as a manifest encrypted with SOPS, using .sops.yaml")
+	cmd.Flags().StringVar(&exportOpts.Env.Prefix, "prefix", "", "prepend this prefix to every variable name of the dotenv file")
+	cmd.Flags().BoolVar(&exportOpts.Env.Lowercase, "lowercase", false, "use lower-case variable names in the dotenv file")
+	cmd.Flags().BoolVar(&exportOpts.WithMetadata, "with-metadata", false, "include a <secret>.metadata.yaml describing the secret in the archive")
+	bulk.addFlags(cmd)
+	return cmd
+}
+
+// exportSelected writes the keys of every secret matching the selector into a
+// subdirectory of dir named after the secret.
+func exportSelected(cmd *cobra.Command, opts *rootOptions, bulk *bulkOptions, clientset kube.Client, namespace, dir string, redaction kube.RedactionPolicy) error {
+	refs, err := bulk.resolve(cmd, clientset, namespace, nil, "Export")
+	if err != nil {
+		return err
+	}
+	return runOnRefs(refs, func(ref kube.SecretRef) error {
+		if err := opts.confirmProtected(cmd, "Export", ref); err != nil {
+			return err
+		}
+		if err := confirmRedactedExport(cmd, clientset, ref, redaction); err != nil {
+			return err
+		}
+		target := filepath.Join(dir, ref.Name)
+		if err := kube.WriteSecretFiles(clientset, ref, target); err != nil {
+			return err
+		}
+		cmd.PrintErrf("Wrote the keys of secret '%s' to %s\n", ref, target)
+		return nil
+	})
+}
+
+// confirmRedactedExport refuses to export a secret with keys that the redaction
+// policy masks, and asks before exporting one with keys to confirm, since exports
+// write every value in the clear.
+func confirmRedactedExport(cmd *cobra.Command, clientset kube.Client, ref kube.SecretRef, redaction kube.RedactionPolicy) error {
+	if len(redaction) == 0 {
+		return nil
+	}
+	secret, err := kube.GetSecret(clientset, ref)
+	if err != nil {
+		return err
+	}
+	if keys := kube.KeysWithMode(redaction, secret.Data, kube.RedactMask); len(keys) > 0 {
+		return fmt.Errorf("cannot export secret '%s': %s redacted by policy", ref, strings.Join(keys, ", "))
+	}
+	if keys := kube.KeysWithMode(redaction, secret.Data, kube.RedactConfirm); len(keys) > 0 &&
+		!askConfirmation(cmd, fmt.Sprintf("Export %s, redacted by policy?", strings.Join(keys, ", "))) {
+		return fmt.Errorf("export of secret '%s' aborted", ref)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestConfirmRedactedExport verifies that secrets with keys redacted by policy are
+// exported only when the policy allows it.
+func TestConfirmRedactedExport(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}, Data: map[string][]byte{"user": []byte("admin")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}, Data: map[string][]byte{"API_TOKEN": []byte("t0k3n")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "default"}, Data: map[string][]byte{"tls.key": []byte("key")}},
+	)
+	policy, err := kube.CompileRedactionPolicy([]kube.RedactionRuleConfig{{Keys: "_TOKEN$"}, {Keys: "\\.key$", Mode: kube.RedactConfirm}})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	confirm := func(name, answers string) error {
+		cmd := &cobra.Command{}
+		cmd.SetIn(strings.NewReader(answers))
+		cmd.SetErr(io.Discard)
+		return confirmRedactedExport(cmd, clientset, kube.SecretRef{Namespace: "default", Name: name}, policy)
+	}
+
+	if err := confirm("app", ""); err != nil {
+		t.Errorf("Expected a secret without redacted keys to be exported, but got: %v", err)
+	}
+	if err := confirm("api", "y\n"); err == nil {
+		t.Error("Expected a secret with masked keys to be refused, but got no error")
+	}
+	if err := confirm("tls", "n\n"); err == nil {
+		t.Error("Expected the export to be aborted without confirmation, but got no error")
+	}
+	if err := confirm("tls", "y\n"); err != nil {
+		t.Errorf("Expected the export to be confirmed, but got: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultExporterAddress is where 'kds exporter' serves metrics unless told
+// otherwise. It listens on every interface so that Prometheus can scrape it.
+const defaultExporterAddress = ":9484"
+
+// newExporterCmd creates the 'kds exporter' command, which exposes the expiry of
+// the certificates in TLS secrets as Prometheus metrics.
+func newExporterCmd(opts *rootOptions) *cobra.Command {
+	var listen string
+	var interval time.Duration
+	var allNamespaces bool
+
+	cmd := &cobra.Command{
+		Use:   "exporter",
+		Short: "Expose the expiry of TLS certificates as Prometheus metrics",
+		Long: `Scan the kubernetes.io/tls secrets on an interval and serve the expiry of their
+certificates on /metrics, for clusters that do not run cert-manager:
+
+  kds_certificate_expiry_timestamp_seconds{namespace,secret,key,subject}
+  kds_certificate_scan_success
+  kds_certificate_scan_timestamp_seconds
+
+The leaf certificates of tls.crt and of ca.crt are reported. Alert on them with,
+for example: kds_certificate_expiry_timestamp_seconds - time() < 14 * 86400`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace := metav1.NamespaceAll
+			if !allNamespaces {
+				if namespace, err = opts.resolveNamespace(); err != nil {
+					return err
+				}
+			}
+			listener, err := net.Listen("tcp", listen)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", listen, err)
+			}
+			e := &expiryExporter{clientset: clientset, namespace: namespace}
+			mux := http.NewServeMux()
+			mux.Handle("GET /metrics", e)
+			server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			go e.run(ctx, interval, func(err error) { cmd.PrintErrf("Scan failed: %v\n", err) })
+			go func() {
+				<-ctx.Done()
+				_ = server.Shutdown(context.Background())
+			}()
+			cmd.PrintErrf("Serving certificate metrics on http://%s/metrics every %s (Ctrl+C to stop)\n", listener.Addr(), interval)
+			if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&listen, "listen", defaultExporterAddress, "address to serve metrics on")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Minute, "how often to scan the TLS secrets")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "scan the TLS secrets of all namespaces")
+	return cmd
+}
+
+// expiryExporter scans TLS secrets and serves the latest results as metrics.
+type expiryExporter struct {
+	clientset kube.Client
+	namespace string
+
+	mu       sync.Mutex
+	expiries []kube.CertificateExpiry
+	scanned  time.Time // When the last scan finished.
+	err      error     // The error of the last scan, if it failed.
+}
+
+// run scans right away, then on every tick of interval until ctx is done.
+func (e *expiryExporter) run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := e.scan(ctx); err != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan lists the TLS secrets and records their certificate expiries. The
+// expiries of the previous scan are kept if it fails.
+func (e *expiryExporter) scan(ctx context.Context) error {
+	secrets, err := e.clientset.CoreV1().Secrets(e.namespace).List(ctx, metav1.ListOptions{FieldSelector: "type=kubernetes.io/tls"})
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.scanned = time.Now()
+	if err != nil {
+		e.err = fmt.Errorf("failed to list secrets: %w", err)
+		return e.err
+	}
+	e.err, e.expiries = nil, kube.CertificateExpiries(secrets.Items)
+	return nil
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (e *expiryExporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = writeExpiryMetrics(w, e.expiries, e.scanned, e.err == nil && !e.scanned.IsZero())
+}
+
+// writeExpiryMetrics writes the certificate expiries and the outcome of the last scan.
+func writeExpiryMetrics(w io.Writer, expiries []kube.CertificateExpiry, scanned time.Time, success bool) error {
+	var b strings.Builder
+	b.WriteString("# HELP kds_certificate_expiry_timestamp_seconds When the certificate expires, in seconds since the Unix epoch.\n")
+	b.WriteString("# TYPE kds_certificate_expiry_timestamp_seconds gauge\n")
+	for _, e := range expiries {
+		fmt.Fprintf(&b, "kds_certificate_expiry_timestamp_seconds{namespace=%s,secret=%s,key=%s,subject=%s} %d\n",
+			metricLabel(e.Namespace), metricLabel(e.Secret), metricLabel(e.Key), metricLabel(e.Subject), e.NotAfter.Unix())
+	}
+	b.WriteString("# HELP kds_certificate_scan_success Whether the last scan of the TLS secrets succeeded.\n")
+	b.WriteString("# TYPE kds_certificate_scan_success gauge\n")
+	if success {
+		b.WriteString("kds_certificate_scan_success 1\n")
+	} else {
+		b.WriteString("kds_certificate_scan_success 0\n")
+	}
+	if !scanned.IsZero() {
+		b.WriteString("# HELP kds_certificate_scan_timestamp_seconds When the last scan of the TLS secrets finished.\n")
+		b.WriteString("# TYPE kds_certificate_scan_timestamp_seconds gauge\n")
+		fmt.Fprintf(&b, "kds_certificate_scan_timestamp_seconds %d\n", scanned.Unix())
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// metricLabelEscaper escapes label values as the exposition format requires.
+var metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricLabel quotes a label value.
+func metricLabel(value string) string {
+	return `"` + metricLabelEscaper.Replace(value) + `"`
+}
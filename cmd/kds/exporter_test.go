@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diskmanti/kds/internal/testfixtures"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestExpiryExporter verifies that the certificate expiries of TLS secrets are
+// served as metrics, and that a failed scan is reported without losing them.
+func TestExpiryExporter(t *testing.T) {
+	now := time.Now()
+	cert := testfixtures.NewCert(t, `web "prod"`, now.Add(-time.Hour), now.Add(30*24*time.Hour), nil)
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: "prod"}, Type: corev1.SecretTypeTLS, Data: map[string][]byte{corev1.TLSCertKey: cert.CertPEM}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"}, Type: corev1.SecretTypeOpaque},
+	)
+	e := &expiryExporter{clientset: clientset, namespace: "prod"}
+	metrics := func(t *testing.T) string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return rec.Body.String()
+	}
+	expiry := fmt.Sprintf(`kds_certificate_expiry_timestamp_seconds{namespace="prod",secret="web-tls",key="tls.crt",subject="web \"prod\""} %d`, cert.Cert.NotAfter.Unix())
+
+	t.Run("should report the expiry of each certificate", func(t *testing.T) {
+		if err := e.scan(context.Background()); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		body := metrics(t)
+		if !strings.Contains(body, expiry+"\n") || !strings.Contains(body, "kds_certificate_scan_success 1\n") {
+			t.Errorf("Expected the expiry of web-tls and a successful scan, but got:\n%s", body)
+		}
+		if strings.Contains(body, `secret="db"`) {
+			t.Errorf("Expected only TLS secrets, but got:\n%s", body)
+		}
+	})
+
+	t.Run("should keep the last expiries when a scan fails", func(t *testing.T) {
+		clientset.PrependReactor("list", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("forbidden")
+		})
+		if err := e.scan(context.Background()); err == nil {
+			t.Fatal("Expected the scan to fail, but it succeeded")
+		}
+		body := metrics(t)
+		if !strings.Contains(body, expiry+"\n") || !strings.Contains(body, "kds_certificate_scan_success 0\n") {
+			t.Errorf("Expected the previous expiry and a failed scan, but got:\n%s", body)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/diskmanti/kds/pkg/ui"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// newCreateGenericCmd creates the 'kds create generic' command.
+func newCreateGenericCmd(opts *rootOptions) *cobra.Command {
+	var genOpts kube.GenericOptions
+
+	cmd := &cobra.Command{
+		Use:   "generic <name>",
+		Short: "Create a secret from literal values, files, or env files",
+		Long: `Create a secret from literal values, files, or env files, like 'kubectl create secret generic'.
+
+--from-file takes a file, a directory (every regular file in it becomes a key), or
+key=path to choose the key name. --from-env-file reads KEY=VALUE lines. --generate
+sets a key to a random value, given as key=kind[:length] with kind one of alnum,
+hex, uuid, passphrase (length in words), rsa (length in bits), or ed25519; keypairs
+store the private key under the key and the public key under key.pub. With --dry-run,
+the secret is only previewed, with its values masked; with --dry-run=server, it is
+checked by the API server and created after confirmation.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := kube.BuildGenericData(genOpts)
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			secret := kube.NewSecret(namespace, args[0], corev1.SecretType(genOpts.SecretType), data)
+			var clientset kube.Client
+			if genOpts.DryRun != kube.DryRunClient {
+				if clientset, err = opts.newClientset(); err != nil {
+					return err
+				}
+			}
+			if applied, err := runMutation(cmd, clientset, kube.Mutation{After: secret}, genOpts.DryRun, false); err != nil || !applied {
+				return err
+			}
+			cmd.PrintErrf("Created secret '%s/%s' with %d key(s)\n", namespace, args[0], len(data))
+			return nil
+		},
+	}
+	cmd.Flags().StringArrayVar(&genOpts.Literals, "from-literal", nil, "a key=value pair to add (repeatable)")
+	cmd.Flags().StringArrayVar(&genOpts.Files, "from-file", nil, "a file, directory, or key=path to add (repeatable)")
+	cmd.Flags().StringArrayVar(&genOpts.EnvFiles, "from-env-file", nil, "a file of KEY=VALUE lines to add (repeatable)")
+	cmd.Flags().StringArrayVar(&genOpts.Generated, "generate", nil, "a key=kind[:length] to set to a random value (repeatable)")
+	cmd.Flags().StringVar(&genOpts.SecretType, "type", string(corev1.SecretTypeOpaque), "the type of the secret")
+	addMutationFlags(cmd, &genOpts.DryRun)
+	return cmd
+}
+
+// printSecretPreview renders a secret that would be created, with masked values.
+func printSecretPreview(w io.Writer, secret *corev1.Secret) error {
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	ref := kube.SecretRef{Namespace: secret.Namespace, Name: secret.Name}
+	b.WriteString(ui.TitleStyle.Render(fmt.Sprintf("Dry run: secret '%s' (%s) would be created", ref, secret.Type)) + "\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "  %s: %s\n", key, ui.NoteStyle.Render(kube.MaskValue(secret.Data[key])))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestPrintSecretPreview verifies that the dry-run preview masks values.
+func TestPrintSecretPreview(t *testing.T) {
+	secret := kube.NewSecret("default", "app", corev1.SecretTypeOpaque, map[string][]byte{"password": []byte("s3cr3t")})
+	var out strings.Builder
+	if err := printSecretPreview(&out, secret); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if strings.Contains(out.String(), "s3cr3t") || !strings.Contains(out.String(), "(6 bytes)") {
+		t.Errorf("Expected the value to be masked, but got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newGetCmd creates the 'kds get' command.
+func newGetCmd(opts *rootOptions) *cobra.Command {
+	var newline bool
+
+	cmd := &cobra.Command{
+		Use:   "get <secret-name> [key]",
+		Short: "Print a secret, or the decoded value of one of its keys",
+		Long: `Print a secret like 'kds <secret-name>' does, or only the decoded value of one of
+its keys, with nothing around it.
+
+The value is printed as the exact bytes stored in the secret, followed by a newline
+unless --newline=false, so it can replace the usual kubectl and base64 one-liner.
+Keys masked by the redaction policy are never printed, and those that need a
+confirmation ask for it on stderr.`,
+		Example: `  # Instead of: kubectl get secret db -o jsonpath='{.data.password}' | base64 -d
+  kds get db password
+
+  # Write a key to a file without adding a newline
+  kds get app-tls tls.key --newline=false > tls.key`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeSecretNameAndKey(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			if len(args) == 1 {
+				return printSecretDirectly(cmd, opts, clientset, ref)
+			}
+			return printSecretKey(cmd, opts, clientset, ref, args[1], newline)
+		},
+	}
+	cmd.Flags().BoolVar(&newline, "newline", true, "end the value with a newline")
+	return cmd
+}
+
+// printSecretKey writes the decoded value of a key of a secret, once confirmed
+// for protected namespaces and keys redacted by policy.
+func printSecretKey(cmd *cobra.Command, opts *rootOptions, clientset kube.Client, ref kube.SecretRef, key string, newline bool) error {
+	if err := opts.confirmProtected(cmd, "Print", ref); err != nil {
+		return err
+	}
+	redaction, err := opts.loadRedactionPolicy()
+	if err != nil {
+		return err
+	}
+	switch redaction.ModeOf(key) {
+	case kube.RedactMask:
+		return fmt.Errorf("key '%s' of secret '%s' is redacted by policy", key, ref)
+	case kube.RedactConfirm:
+		if !askConfirmation(cmd, fmt.Sprintf("Reveal %s, redacted by policy?", key)) {
+			return fmt.Errorf("access to key '%s' of secret '%s' aborted", key, ref)
+		}
+	}
+	return printSecretValue(cmd.OutOrStdout(), clientset, ref, key, newline)
+}
+
+// printSecretValue writes the decoded value of a key of a secret.
+func printSecretValue(w io.Writer, clientset kube.Client, ref kube.SecretRef, key string, newline bool) error {
+	secret, err := kube.GetSecret(clientset, ref)
+	if err != nil {
+		return err
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return fmt.Errorf("secret '%s' has no key '%s': use one of %s", ref, key, strings.Join(kube.SortedKeys(secret.Data), ", "))
+	}
+	if newline {
+		value = append(value[:len(value):len(value)], '\n')
+	}
+	_, err = w.Write(value)
+	return err
+}
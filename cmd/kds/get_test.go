@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestPrintSecretValue verifies that 'kds get' prints the exact value of a key.
+func TestPrintSecretValue(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("hunter2"), "user": []byte("admin")},
+	})
+	ref := kube.SecretRef{Namespace: "default", Name: "db"}
+
+	t.Run("should print the value with a newline", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := printSecretValue(&out, clientset, ref, "password", true); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if out.String() != "hunter2\n" {
+			t.Errorf("Expected %q, but got %q", "hunter2\n", out.String())
+		}
+	})
+	t.Run("should print the exact bytes without a newline", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := printSecretValue(&out, clientset, ref, "password", false); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if out.String() != "hunter2" {
+			t.Errorf("Expected %q, but got %q", "hunter2", out.String())
+		}
+	})
+	t.Run("should name the existing keys when the key is missing", func(t *testing.T) {
+		err := printSecretValue(&bytes.Buffer{}, clientset, ref, "token", true)
+		if err == nil || err.Error() != "secret 'default/db' has no key 'token': use one of password, user" {
+			t.Errorf("Expected a missing key error, but got %v", err)
+		}
+	})
+}
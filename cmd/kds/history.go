@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// historyConfig configures the local history of the changes to secrets.
+type historyConfig struct {
+	// Enabled records the changes to the secrets of the namespace shown by the TUI.
+	Enabled bool `json:"enabled,omitempty"`
+	// Dir holds the encrypted history (default: kds/history in the user config directory).
+	Dir string `json:"dir,omitempty"`
+	// Limit is how many changes are kept per secret (default: 100).
+	Limit int `json:"limit,omitempty"`
+}
+
+// openHistory opens the history of the selected context, or returns nil if it is
+// not enabled. The key is kept in the OS keychain, or without one next to the
+// history directory, e.g. ~/.config/kds/history.key on Linux.
+func (o *rootOptions) openHistory() (*kube.HistoryStore, error) {
+	config, err := loadConfig(o.configPath)
+	if err != nil {
+		return nil, err
+	}
+	if !config.History.Enabled {
+		return nil, nil
+	}
+	if config.History.Limit < 0 {
+		return nil, fmt.Errorf("invalid history limit %d: use a positive number", config.History.Limit)
+	}
+	limit := kube.DefaultHistoryLimit
+	if config.History.Limit > 0 {
+		limit = config.History.Limit
+	}
+	dir := config.History.Dir
+	if dir == "" {
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the history directory: %w", err)
+		}
+		dir = filepath.Join(base, "kds", "history")
+	}
+	contextName, err := o.contextName()
+	if err != nil {
+		return nil, err
+	}
+	return kube.OpenHistoryStore(dir, filepath.Clean(dir)+".key", contextName, limit)
+}
+
+// newHistoryCmd creates the 'kds history' command.
+func newHistoryCmd(opts *rootOptions) *cobra.Command {
+	var record, allNamespaces bool
+
+	cmd := &cobra.Command{
+		Use:   "history <secret-name> | --record",
+		Short: "Show the recorded changes to a secret, or record them",
+		Long: `Show the changes to a secret recorded by kds, newest first, with the keys each
+change added (+), changed (~), or removed (-).
+
+Kubernetes keeps no revision history of secrets. With history enabled in the config
+file, the TUI watches the secrets of its namespace and records every change it
+observes to an encrypted local store, one file per secret; 'kds history --record'
+does the same without the TUI, until it is interrupted. Only the checksums of the
+values are kept, never the values. Changes made while nothing was watching are
+recorded as observed, when the secret is next seen.`,
+		Example: `  # Record the changes to the secrets of every namespace in the background
+  kds history --record -A &
+
+  # Show what changed in a secret
+  kds history db-credentials`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if record {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := opts.openHistory()
+			if err != nil {
+				return err
+			}
+			if store == nil {
+				return errors.New("the history is not enabled in the kds config")
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			if record {
+				clientset, err := opts.newClientset()
+				if err != nil {
+					return err
+				}
+				if allNamespaces {
+					namespace = ""
+				}
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				cmd.PrintErrln("Recording the changes to secrets, press Ctrl+C to stop")
+				return store.Watch(ctx, clientset, namespace)
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			entries, err := store.Load(ref)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				cmd.PrintErrf("No changes recorded for secret '%s'\n", ref)
+				return nil
+			}
+			return printHistory(cmd.OutOrStdout(), entries)
+		},
+	}
+	cmd.Flags().BoolVar(&record, "record", false, "record the changes to the secrets of the namespace until interrupted")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "with --record, record the changes in every namespace")
+	return cmd
+}
+
+// printHistory writes the entries of a history as an aligned table, newest first.
+func printHistory(w io.Writer, entries []kube.HistoryEntry) error {
+	var b strings.Builder
+	b.WriteString("TIME\tEVENT\tCHANGES\tKEYS\n")
+	for i := len(entries) - 1; i >= 0; i-- {
+		changes := kube.HistoryChanges(entries, i)
+		keys := make([]string, len(changes))
+		for j, change := range changes {
+			keys[j] = string(change.Kind) + change.Key
+		}
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\n", entries[i].Time.Local().Format("2006-01-02 15:04:05"), entries[i].Event, kube.SummarizeDataDiff(changes), strings.Join(keys, " "))
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if _, err := io.WriteString(tw, b.String()); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// TestPrintHistory verifies that the history is printed newest first, key by key.
+func TestPrintHistory(t *testing.T) {
+	created := time.Date(2026, 10, 15, 9, 0, 0, 0, time.Local)
+	entries := []kube.HistoryEntry{
+		{Time: created, Event: kube.HistoryCreated, Checksums: map[string]string{"password": "a", "user": "b"}},
+		{Time: created.Add(time.Hour), Event: kube.HistoryChanged, Checksums: map[string]string{"password": "c", "user": "b", "token": "d"}},
+	}
+	var out bytes.Buffer
+	if err := printHistory(&out, entries); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 entries, but got %q", out.String())
+	}
+	if !strings.Contains(lines[1], "2026-10-15 10:00:00") || !strings.HasSuffix(lines[1], "~password +token") {
+		t.Errorf("Expected the change first, but got %q", lines[1])
+	}
+	if !strings.HasSuffix(lines[2], "+password +user") {
+		t.Errorf("Expected the creation last, but got %q", lines[2])
+	}
+}
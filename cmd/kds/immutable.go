@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newImmutableCmd creates the 'kds immutable' command.
+func newImmutableCmd(opts *rootOptions) *cobra.Command {
+	var unset, yes bool
+
+	cmd := &cobra.Command{
+		Use:   "immutable <secret-name>",
+		Short: "Mark a secret as immutable, or recreate it as mutable",
+		Long: `Mark a secret as immutable, or recreate it as mutable with --unset.
+
+Kubernetes only allows turning the immutable flag on. Turning it off requires deleting
+the secret and creating it again; kds does this for you, keeping its data, type, labels,
+and annotations, after asking for confirmation.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			question := fmt.Sprintf("Mark secret '%s' as immutable? Its data can no longer be edited.", ref)
+			if unset {
+				question = fmt.Sprintf("Delete and recreate secret '%s' as mutable?", ref)
+			}
+			if !yes && !askConfirmation(cmd, question) {
+				return fmt.Errorf("update of secret '%s' aborted", ref)
+			}
+			if err := kube.SetImmutable(clientset, ref, !unset); err != nil {
+				return err
+			}
+			cmd.PrintErrf("Secret '%s' is now %s\n", ref, kube.Mutability(!unset))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&unset, "unset", false, "recreate the secret as mutable")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+	return cmd
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newImportCmd creates the 'kds import' command.
+func newImportCmd(opts *rootOptions) *cobra.Command {
+	var prune, yes, restart bool
+	var dryRun string
+
+	cmd := &cobra.Command{
+		Use:   "import <secret-name> <env-file>",
+		Short: "Merge the keys of a .env file into an existing secret",
+		Long: `Merge the keys of a .env file into an existing secret.
+
+Keys from the file are added or overwrite existing ones; with --prune, keys that are
+not in the file are removed. The added, changed, and removed keys are shown, without
+their values, before asking for confirmation. With --dry-run=server, the changes are
+computed by a server-side dry run. Afterwards, kds offers to restart the workloads
+that use the secret.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			mu, err := kube.PrepareImport(clientset, ref, args[1], prune)
+			if err != nil {
+				return err
+			}
+			if applied, err := runMutation(cmd, clientset, mu, dryRun, !yes); err != nil || !applied {
+				return err
+			}
+			cmd.PrintErrf("Imported %s into secret '%s'\n", args[1], ref)
+			return offerRestart(cmd, clientset, ref, restart)
+		},
+	}
+	cmd.Flags().BoolVar(&prune, "prune", false, "remove keys that are not in the file")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+	addMutationFlags(cmd, &dryRun)
+	cmd.Flags().BoolVar(&restart, "restart", false, "restart the workloads using the secret without asking")
+	return cmd
+}
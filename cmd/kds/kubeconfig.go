@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/diskmanti/kds/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// newKubeconfigCmd creates the 'kds kubeconfig' command.
+func newKubeconfigCmd(opts *rootOptions) *cobra.Command {
+	var key string
+	var write bool
+
+	cmd := &cobra.Command{
+		Use:   "kubeconfig <secret-name>",
+		Short: "Inspect a kubeconfig stored in a secret, or write it to a temporary file",
+		Long: `Show the clusters, contexts, and users of a kubeconfig stored in a secret, with
+credentials masked.
+
+With --write, the kubeconfig is written to a temporary file with 0600 permissions,
+and the line to use it is printed, so that it can be evaluated by the shell:
+
+  eval "$(kds kubeconfig cluster-admin --write)"`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			if write {
+				if err := opts.confirmProtected(cmd, "Write", ref); err != nil {
+					return err
+				}
+			}
+			secret, err := kube.GetSecret(clientset, ref)
+			if err != nil {
+				return err
+			}
+			found, config, err := kube.FindKubeconfig(secret, key)
+			if err != nil {
+				return err
+			}
+			if !write {
+				_, err = io.WriteString(cmd.OutOrStdout(), strings.Join(ui.DescribeKubeconfig(config), "\n")+"\n")
+				return err
+			}
+			path, err := kube.WriteKubeconfig(secret.Data[found])
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "export KUBECONFIG=%s\n", path)
+			return err
+		},
+	}
+	cmd.Flags().StringVarP(&key, "key", "k", "", "key holding the kubeconfig (default: the first one found)")
+	cmd.Flags().BoolVar(&write, "write", false, "write the kubeconfig to a temporary file and print the export line")
+	return cmd
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/yaml"
+)
+
+// newListCmd creates the 'kds list' command, which prints the secrets of a
+// namespace without starting the TUI.
+func newListCmd(opts *rootOptions) *cobra.Command {
+	var plain, allNamespaces bool
+	var output, sortKey, selector, secretType string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the secrets in a namespace",
+		Long: `List the secrets in a namespace, as a table, JSON, or YAML.
+
+--selector and --type keep the secrets matching a label selector and of a type,
+and --sort orders them by name, namespace, type, age, size, or keys (the newest,
+largest, and fullest first). Values are never printed.
+
+With --plain, each secret is printed as an unstyled "namespace/name<TAB>type<TAB>age"
+line, which makes kds easy to compose with fzf, dmenu, or other pickers.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if output != "table" && output != "json" && output != "yaml" {
+				return fmt.Errorf("invalid --output '%s': use table, json, or yaml", output)
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			items, err := listItems(cmd, clientset, opts, allNamespaces)
+			if err != nil {
+				return err
+			}
+			if items, err = kube.FilterItems(items, selector, corev1.SecretType(secretType)); err != nil {
+				return err
+			}
+			if err := kube.SortItemsBy(items, sortKey); err != nil {
+				return err
+			}
+			switch {
+			case plain:
+				return printPlainList(cmd.OutOrStdout(), items, time.Now())
+			case output == "json" || output == "yaml":
+				return printStructuredList(cmd.OutOrStdout(), items, output)
+			}
+			return printTableList(cmd.OutOrStdout(), items, time.Now())
+		},
+	}
+	cmd.Flags().BoolVar(&plain, "plain", false, "print unstyled tab-separated lines for piping into other tools")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "list the secrets of every namespace, skipping those you cannot list")
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "output format: table, json, or yaml")
+	cmd.Flags().StringVar(&sortKey, "sort", "name", "sort by "+strings.Join(kube.ItemSortKeys, ", "))
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "list the secrets matching this label selector, e.g. app=web")
+	cmd.Flags().StringVar(&secretType, "type", "", "list the secrets of this type, e.g. kubernetes.io/tls")
+	return cmd
+}
+
+// listItems lists the secrets of the namespace, or of every namespace, warning on
+// stderr about the namespaces skipped for lack of access.
+func listItems(cmd *cobra.Command, clientset kube.Client, opts *rootOptions, allNamespaces bool) (kube.ItemSource, error) {
+	if allNamespaces {
+		items, skipped, err := kube.ListAllItems(clientset)
+		if len(skipped) > 0 {
+			cmd.PrintErrf("Warning: skipped %d namespace(s) where listing secrets is forbidden: %s\n", len(skipped), strings.Join(skipped, ", "))
+		}
+		return items, err
+	}
+	namespace, err := opts.resolveNamespace()
+	if err != nil {
+		return nil, err
+	}
+	items, err := kube.ListItems(clientset, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets in namespace '%s': %w", namespace, err)
+	}
+	return items, nil
+}
+
+// printPlainList writes one "namespace/name<TAB>type<TAB>age" line per secret.
+func printPlainList(w io.Writer, items kube.ItemSource, now time.Time) error {
+	for _, it := range items {
+		if _, err := fmt.Fprintf(w, "%s/%s\t%s\t%s\n", it.Namespace, it.Name, it.SecretType, age(it.Created, now)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printTableList writes the secrets as an aligned, human-readable table.
+func printTableList(w io.Writer, items kube.ItemSource, now time.Time) error {
+	var b strings.Builder
+	b.WriteString("NAMESPACE\tNAME\tTYPE\tKEYS\tSIZE\tAGE\n")
+	for _, it := range items {
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%d\t%s\t%s\n", it.Namespace, it.Name, it.SecretType, it.Keys, kube.FormatSize(it.Size), age(it.Created, now))
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if _, err := io.WriteString(tw, b.String()); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+// listEntry is a secret in the JSON and YAML output of 'kds list'.
+type listEntry struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Type      corev1.SecretType `json:"type"`
+	Created   time.Time         `json:"created"`
+	Keys      int               `json:"keys"`
+	Size      int               `json:"size"` // Bytes of keys and raw values.
+	Immutable bool              `json:"immutable,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// printStructuredList writes the secrets as a JSON or YAML array.
+func printStructuredList(w io.Writer, items kube.ItemSource, format string) error {
+	entries := make([]listEntry, len(items))
+	for i, it := range items {
+		entries[i] = listEntry{
+			Namespace: it.Namespace,
+			Name:      it.Name,
+			Type:      it.SecretType,
+			Created:   it.Created,
+			Keys:      it.Keys,
+			Size:      it.Size,
+			Immutable: it.Immutable,
+			Labels:    it.Labels,
+		}
+	}
+	if format == "yaml" {
+		content, err := yaml.Marshal(entries)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// age formats the time elapsed since a secret was created the same way kubectl does.
+func age(created, now time.Time) string {
+	if created.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(now.Sub(created))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestPrintPlainList verifies the unstyled, tab-separated list output.
+func TestPrintPlainList(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	items := kube.ItemSource{
+		{Name: "app-tls", Namespace: "web", SecretType: corev1.SecretTypeTLS, Created: now.Add(-72 * time.Hour)},
+		{Name: "legacy", Namespace: "web", SecretType: corev1.SecretTypeOpaque},
+	}
+	var buf bytes.Buffer
+	if err := printPlainList(&buf, items, now); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := "web/app-tls\tkubernetes.io/tls\t3d\nweb/legacy\tOpaque\t<unknown>\n"
+	if buf.String() != expected {
+		t.Errorf("Expected output %q, but got %q", expected, buf.String())
+	}
+}
+
+// TestPrintStructuredList verifies the JSON and YAML list output.
+func TestPrintStructuredList(t *testing.T) {
+	created := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	items := kube.ItemSource{
+		{Name: "app-tls", Namespace: "web", SecretType: corev1.SecretTypeTLS, Created: created, Keys: 2, Size: 1024, Labels: map[string]string{"app": "web"}},
+	}
+
+	t.Run("should print a JSON array", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := printStructuredList(&buf, items, "json"); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		for _, want := range []string{`"name": "app-tls"`, `"type": "kubernetes.io/tls"`, `"keys": 2`, `"app": "web"`} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("Expected the output to contain %s, but got %s", want, buf.String())
+			}
+		}
+		if strings.Contains(buf.String(), "immutable") {
+			t.Errorf("Expected mutable secrets to omit immutable, but got %s", buf.String())
+		}
+	})
+
+	t.Run("should print a YAML list", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := printStructuredList(&buf, items, "yaml"); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if !strings.HasPrefix(buf.String(), "- created: \"2024-01-10T00:00:00Z\"\n") || !strings.Contains(buf.String(), "  size: 1024\n") {
+			t.Errorf("Expected a YAML list, but got %s", buf.String())
+		}
+	})
+}
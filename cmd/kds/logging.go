@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+// newLogger creates the logger of kds. Warnings and client-go messages are always
+// logged; -v adds debug messages such as API calls, cache hits, watch events, and
+// render timings.
+func newLogger(w io.Writer, verbosity int) *slog.Logger {
+	level := slog.LevelInfo
+	if verbosity > 0 {
+		level = slog.LevelDebug
+	}
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// installLogger makes logger the default of kds, and routes the output of
+// client-go (klog) through it, so that its warnings do not corrupt the TUI.
+func installLogger(logger *slog.Logger) {
+	slog.SetDefault(logger)
+	klog.SetSlogLogger(logger)
+}
+
+// setKlogVerbosity passes -v counts above 1 on to klog, for the detailed logs of
+// client-go such as request headers (-vvvvvvv and up, like kubectl -v=7).
+func setKlogVerbosity(verbosity int) error {
+	if verbosity < 2 {
+		return nil
+	}
+	var fs flag.FlagSet
+	klog.InitFlags(&fs)
+	return fs.Set("v", strconv.Itoa(verbosity))
+}
+
+// setupLogging installs a logger writing to the log file if one is given, or to
+// stderr. The log file stays open for the lifetime of the process.
+func (o *rootOptions) setupLogging(stderr io.Writer) error {
+	w := stderr
+	if o.logFile != "" {
+		f, err := os.OpenFile(o.logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec // The log file is chosen by the user.
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		w = f
+	}
+	installLogger(newLogger(w, o.verbosity))
+	return setKlogVerbosity(o.verbosity)
+}
+
+// silenceStderrLogs discards the logs when they would go to stderr, which the
+// TUI owns while it runs. Use --log-file to keep them.
+func (o *rootOptions) silenceStderrLogs() {
+	if o.logFile == "" {
+		installLogger(slog.New(slog.DiscardHandler))
+	}
+}
+
+// loggingTransport logs every request to the API server at debug level.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+// logAPICalls wraps the transport of a REST configuration with a loggingTransport.
+func logAPICalls(restConfig *rest.Config) {
+	restConfig.Wrap(func(next http.RoundTripper) http.RoundTripper {
+		return loggingTransport{next: next}
+	})
+}
+
+// RoundTrip logs the method, URL, status, and duration of the request.
+func (t loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		slog.Debug("API call failed", "method", req.Method, "url", req.URL.String(), "duration", time.Since(start), "error", err)
+		return nil, err
+	}
+	slog.Debug("API call", "method", req.Method, "url", req.URL.String(), "status", resp.StatusCode, "duration", time.Since(start))
+	return resp, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/klog/v2"
+)
+
+// TestLogging verifies the verbosity levels, the logging of API calls, and that
+// klog output is routed to the log.
+func TestLogging(t *testing.T) {
+	previous := slog.Default()
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+		klog.ClearLogger()
+	})
+
+	t.Run("should log debug messages with -v", func(t *testing.T) {
+		for verbosity, expected := range []slog.Level{slog.LevelInfo, slog.LevelDebug, slog.LevelDebug} {
+			logger := newLogger(&bytes.Buffer{}, verbosity)
+			if !logger.Enabled(t.Context(), expected) || logger.Enabled(t.Context(), expected-1) {
+				t.Errorf("Expected -v count %d to log from level %s", verbosity, expected)
+			}
+		}
+	})
+
+	t.Run("should log API calls at debug level", func(t *testing.T) {
+		var buf bytes.Buffer
+		installLogger(newLogger(&buf, 2))
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+		client := &http.Client{Transport: loggingTransport{next: http.DefaultTransport}}
+		resp, err := client.Get(server.URL + "/api/v1/namespaces/default/secrets")
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		_ = resp.Body.Close()
+		if log := buf.String(); !strings.Contains(log, "msg=\"API call\" method=GET") || !strings.Contains(log, "/api/v1/namespaces/default/secrets status=403") {
+			t.Errorf("Expected the API call to be logged, but got %q", log)
+		}
+	})
+
+	t.Run("should route klog output to the log", func(t *testing.T) {
+		var buf bytes.Buffer
+		installLogger(newLogger(&buf, 0))
+		klog.Warning("the client-go warning")
+		klog.Flush()
+		if log := buf.String(); !strings.Contains(log, "the client-go warning") {
+			t.Errorf("Expected the klog warning in the log, but got %q", log)
+		}
+	})
+}
@@ -0,0 +1,482 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/diskmanti/kds/pkg/ui"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// --- Build Information ---
+
+// These variables are placeholders and will be injected by the GoReleaser build process
+// using ldflags. This provides versioning information in the final binary.
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
+)
+
+// --- MAIN & COBRA ---
+// This is the entry point for the application.
+
+func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		// 'kds run' exits with the exit code of its command.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+			os.Exit(exitErr.ExitCode())
+		}
+		os.Exit(1)
+	}
+}
+
+// runRoot is the action of the root command. It prints secrets directly when names
+// are given as an argument or on stdin, and starts the interactive TUI otherwise.
+func runRoot(cmd *cobra.Command, opts *rootOptions, args []string) error {
+	if err := validateOutputFormat(opts.output); err != nil {
+		return err
+	}
+	// A secret URI selects the context and the namespace, and opens the TUI on the
+	// secret, or prints the key it addresses.
+	var uri kube.SecretURI
+	if len(args) > 0 && kube.IsSecretURI(args[0]) {
+		var err error
+		if uri, err = kube.ParseSecretURI(args[0]); err != nil {
+			return err
+		}
+		if err := opts.applySecretURI(uri); err != nil {
+			return err
+		}
+		args = nil
+	}
+	clientset, err := opts.newClientset()
+	if err != nil {
+		return err
+	}
+	namespace, err := opts.resolveNamespace()
+	if err != nil {
+		return err
+	}
+
+	// In batch mode, secret names are read from stdin instead of the arguments.
+	if opts.batch {
+		protected, err := opts.loadProtectedNamespaces()
+		if err != nil {
+			return err
+		}
+		redaction, err := opts.loadRedactionPolicy()
+		if err != nil {
+			return err
+		}
+		return runBatch(cmd.InOrStdin(), cmd.OutOrStdout(), clientset, namespace, opts.output, redaction, protected)
+	}
+
+	if uri.Key != "" {
+		return printSecretKey(cmd, opts, clientset, uri.Ref, uri.Key, true)
+	}
+	// If a secret name is provided as an argument, run in non-interactive mode.
+	if len(args) > 0 {
+		return printSecretDirectly(cmd, opts, clientset, kube.SecretRef{Namespace: namespace, Name: args[0]})
+	}
+
+	// Otherwise, start the interactive TUI.
+	uiOpts := ui.Options{AllNamespaces: opts.allNamespaces, CredentialPlugin: opts.usesCredentialPlugin(), Focus: uri.Ref.Name}
+	uiOpts.PickNamespace = uri.Ref.Name == "" && (opts.pickNamespace || !opts.allNamespaces && !opts.namespaceConfigured())
+	if uiOpts.Context, err = opts.contextName(); err != nil {
+		return err
+	}
+	if uiOpts.SecretKeys, err = kube.CompileSecretKeys(opts.secretKeys); err != nil {
+		return err
+	}
+	if uiOpts.Dynamic, err = opts.newDynamicClient(); err != nil {
+		return err
+	}
+	config, err := loadConfig(opts.configPath)
+	if err != nil {
+		return err
+	}
+	if uiOpts.Decoders, err = kube.CompileDecoders(config.Decoders); err != nil {
+		return err
+	}
+	if uiOpts.Actions, err = ui.CompileActions(config.Actions); err != nil {
+		return err
+	}
+	if uiOpts.Stores, err = kube.CompileStores(config.Stores); err != nil {
+		return err
+	}
+	if uiOpts.ProtectedNamespaces, err = kube.CompileProtectedNamespaces(config.ProtectedNamespaces); err != nil {
+		return err
+	}
+	if uiOpts.Redaction, err = kube.CompileRedactionPolicy(config.Redaction); err != nil {
+		return err
+	}
+	if uiOpts.Trash, err = opts.openTrash(); err != nil {
+		return err
+	}
+	uiOpts.Icons, uiOpts.TypeColors = config.Icons, config.TypeColors
+	uiOpts.Watch, uiOpts.Bell = opts.watch || config.Watch, opts.bell || config.Bell
+	if uiOpts.KeepPrevious, err = config.Rotation.keepPrevious(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	if uiOpts.ListCache, err = opts.openListCache(); err != nil {
+		return err
+	}
+	if uiOpts.History, err = opts.openHistory(); err != nil {
+		return err
+	}
+	if uiOpts.SearchHistory, err = opts.openSearchHistory(); err != nil {
+		return err
+	}
+	opts.silenceStderrLogs()
+	p := tea.NewProgram(ui.NewModel(clientset, namespace, uiOpts), tea.WithAltScreen(), tea.WithMouseAllMotion())
+	if _, err := p.Run(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// rootOptions holds the values of the global command-line flags, shared by the
+// root command, its subcommands, and the shell completion functions. The connection
+// flags are kubectl's (--context, --cluster, --user, --token, ...) so kds behaves
+// the same whether it is run directly or as a kubectl plugin.
+type rootOptions struct {
+	configFlags   *genericclioptions.ConfigFlags
+	output        string
+	batch         bool
+	secretKeys    string
+	configPath    string
+	verbosity     int
+	logFile       string
+	allNamespaces bool
+	pickNamespace bool
+	watch         bool
+	bell          bool
+	pprofAddress  string
+}
+
+// flags returns the connection flags, creating them with kubectl's defaults for
+// options that were not built by newRootCmd. They are not persistent: kds changes
+// the context and the namespace after parsing them, for secret URIs, other
+// contexts, and the users of 'kds serve-ssh', so the client configuration is
+// built anew from them every time.
+func (o *rootOptions) flags() *genericclioptions.ConfigFlags {
+	if o.configFlags == nil {
+		o.configFlags = genericclioptions.NewConfigFlags(false)
+	}
+	return o.configFlags
+}
+
+// clientConfig returns the kubeconfig-backed client configuration with the
+// command-line overrides applied, using the same loading rules as kubectl.
+func (o *rootOptions) clientConfig() clientcmd.ClientConfig {
+	return o.flags().ToRawKubeConfigLoader()
+}
+
+// withConnection returns a copy of the options whose kubeconfig, context, and
+// namespace can be changed without changing those of o, keeping all other
+// connection flags.
+func (o *rootOptions) withConnection() *rootOptions {
+	other := *o
+	f := o.flags()
+	kubeconfig, context, namespace := *f.KubeConfig, *f.Context, *f.Namespace
+	other.configFlags = &genericclioptions.ConfigFlags{
+		CacheDir: f.CacheDir, KubeConfig: &kubeconfig, ClusterName: f.ClusterName, AuthInfoName: f.AuthInfoName,
+		Context: &context, Namespace: &namespace, APIServer: f.APIServer, TLSServerName: f.TLSServerName,
+		Insecure: f.Insecure, CertFile: f.CertFile, KeyFile: f.KeyFile, CAFile: f.CAFile,
+		BearerToken: f.BearerToken, Impersonate: f.Impersonate, ImpersonateUID: f.ImpersonateUID,
+		ImpersonateGroup: f.ImpersonateGroup, Username: f.Username, Password: f.Password,
+		Timeout: f.Timeout, DisableCompression: f.DisableCompression, WrapConfigFn: f.WrapConfigFn,
+	}
+	return &other
+}
+
+// restConfig builds the REST configuration from the resolved client configuration,
+// enforcing the context policies and logging every API call at debug level.
+func (o *rootOptions) restConfig() (*rest.Config, error) {
+	restConfig, err := o.clientConfig().ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kubeconfig: %w", err)
+	}
+	if err := o.enforceContextPolicy(restConfig); err != nil {
+		return nil, err
+	}
+	logAPICalls(restConfig)
+	return restConfig, nil
+}
+
+// usesCredentialPlugin reports whether the credentials come from an exec plugin.
+func (o *rootOptions) usesCredentialPlugin() bool {
+	restConfig, err := o.clientConfig().ClientConfig()
+	return err == nil && restConfig.ExecProvider != nil
+}
+
+// newClientset builds a Kubernetes clientset from the resolved client configuration.
+func (o *rootOptions) newClientset() (*kubernetes.Clientset, error) {
+	restConfig, err := o.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes clientset: %w", err)
+	}
+	return clientset, nil
+}
+
+// newDynamicClient builds a dynamic client for custom resources from the resolved
+// client configuration.
+func (o *rootOptions) newDynamicClient() (dynamic.Interface, error) {
+	restConfig, err := o.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	return client, nil
+}
+
+// newMetadataClient builds a client for the metadata of resources, without their
+// content, from the resolved client configuration.
+func (o *rootOptions) newMetadataClient() (metadata.Interface, error) {
+	restConfig, err := o.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := metadata.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metadata client: %w", err)
+	}
+	return client, nil
+}
+
+// newClientsetForContext builds a clientset for another context of the same
+// kubeconfig, keeping all other connection flags.
+func (o *rootOptions) newClientsetForContext(contextName string) (*kubernetes.Clientset, error) {
+	other := o.withConnection()
+	*other.flags().Context = contextName
+	*other.flags().Namespace = ""
+	return other.newClientset()
+}
+
+// resolveNamespace returns the namespace given with --namespace, falling back to
+// the namespace of the selected kubeconfig context.
+func (o *rootOptions) resolveNamespace() (string, error) {
+	ns, _, err := o.clientConfig().Namespace()
+	if err != nil {
+		return "", fmt.Errorf("failed to get namespace from context: %w", err)
+	}
+	return ns, nil
+}
+
+// namespaceConfigured reports whether the namespace is given with --namespace or
+// by the kubeconfig context, rather than defaulting to default. Without a
+// kubeconfig, the namespace comes from the service account of the pod.
+func (o *rootOptions) namespaceConfigured() bool {
+	if *o.flags().Namespace != "" {
+		return true
+	}
+	raw, err := o.clientConfig().RawConfig()
+	if err != nil || len(raw.Contexts) == 0 {
+		return true
+	}
+	name := raw.CurrentContext
+	if context := *o.flags().Context; context != "" {
+		name = context
+	}
+	current, ok := raw.Contexts[name]
+	return ok && current.Namespace != ""
+}
+
+// isKubectlPlugin reports whether kds was invoked through kubectl's plugin
+// mechanism, which executes binaries named `kubectl-<plugin>` found on the PATH.
+func isKubectlPlugin() bool {
+	return strings.HasPrefix(filepath.Base(os.Args[0]), "kubectl-")
+}
+
+// newRootCmd creates the main command for the kds application, configured using Cobra.
+func newRootCmd() *cobra.Command {
+	opts := &rootOptions{}
+
+	rootCmd := &cobra.Command{
+		Use:   "kds [secret-name | secret://context/namespace/name[#key]]",
+		Short: "A tool with fuzzy-finding to view Kubernetes secrets.",
+		Long: `kds is a CLI tool for browsing, finding, and viewing Kubernetes secrets.
+
+A secret URI, secret://context/namespace/name, opens the TUI on that secret in
+that context, and secret://context/namespace/name#key prints the value of the key,
+so that runbooks and tickets can link to secrets. The context may be left out, as
+in secret:///namespace/name, for the current one.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := opts.setupLogging(cmd.ErrOrStderr()); err != nil {
+				return err
+			}
+			if opts.pprofAddress != "" {
+				return startPprof(opts.pprofAddress)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRoot(cmd, opts, args)
+		},
+	}
+
+	// versionCmd defines the 'kds version' command.
+	versionCmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the version, commit, and build date of kds",
+		Run: func(_ *cobra.Command, _ []string) {
+			fmt.Printf("kds Version: %s\n", version)
+			fmt.Printf("Commit: %s\n", commit)
+			fmt.Printf("Built at: %s\n", date)
+		},
+	}
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(newListCmd(opts))
+	rootCmd.AddCommand(newGetCmd(opts))
+	rootCmd.AddCommand(newEnvCmd(opts))
+	rootCmd.AddCommand(newRunCmd(opts))
+	rootCmd.AddCommand(newB64Cmd())
+	rootCmd.AddCommand(newExportCmd(opts))
+	rootCmd.AddCommand(newSealCmd(opts))
+	rootCmd.AddCommand(newPushCmd(opts))
+	rootCmd.AddCommand(newPullCmd(opts))
+	rootCmd.AddCommand(newReportCmd(opts))
+	rootCmd.AddCommand(newCopyCmd(opts))
+	rootCmd.AddCommand(newSyncCmd(opts))
+	rootCmd.AddCommand(newDiffCmd(opts))
+	rootCmd.AddCommand(newDriftCmd(opts))
+	rootCmd.AddCommand(newRenameCmd(opts))
+	rootCmd.AddCommand(newMergeCmd(opts))
+	rootCmd.AddCommand(newSplitCmd(opts))
+	rootCmd.AddCommand(newDeleteCmd(opts))
+	rootCmd.AddCommand(newRestoreDeletedCmd(opts))
+	rootCmd.AddCommand(newHistoryCmd(opts))
+	rootCmd.AddCommand(newMetadataCmd(opts, "label", kube.FieldLabels))
+	rootCmd.AddCommand(newMetadataCmd(opts, "annotate", kube.FieldAnnotations))
+	rootCmd.AddCommand(newEditCmd(opts))
+	rootCmd.AddCommand(newRotateCmd(opts))
+	rootCmd.AddCommand(newImmutableCmd(opts))
+	rootCmd.AddCommand(newCreateCmd(opts))
+	rootCmd.AddCommand(newImportCmd(opts))
+	rootCmd.AddCommand(newReloaderCmd(opts))
+	rootCmd.AddCommand(newStaleCmd(opts))
+	rootCmd.AddCommand(newTopCmd(opts))
+	rootCmd.AddCommand(newStatsCmd(opts))
+	rootCmd.AddCommand(newAssertCmd(opts))
+	rootCmd.AddCommand(newValidateCmd(opts))
+	rootCmd.AddCommand(newScanCmd(opts))
+	rootCmd.AddCommand(newCheckTLSCmd(opts))
+	rootCmd.AddCommand(newCheckRefsCmd(opts))
+	rootCmd.AddCommand(newUnusedKeysCmd(opts))
+	rootCmd.AddCommand(newCertificateCmd(opts))
+	rootCmd.AddCommand(newKubeconfigCmd(opts))
+	rootCmd.AddCommand(newTokenCmd(opts))
+	rootCmd.AddCommand(newServeCmd(opts))
+	rootCmd.AddCommand(newServeSSHCmd(opts))
+	rootCmd.AddCommand(newAPICmd(opts))
+	rootCmd.AddCommand(newExporterCmd(opts))
+
+	// When run as `kubectl kds`, present usage and completions under that name.
+	if isKubectlPlugin() {
+		rootCmd.Annotations = map[string]string{cobra.CommandDisplayNameAnnotation: "kubectl kds"}
+	}
+
+	// Setup Cobra flags for command-line arguments, using kubectl's standard flag set.
+	opts.flags().AddFlags(rootCmd.PersistentFlags())
+	rootCmd.PersistentFlags().CountVarP(&opts.verbosity, "verbose", "v", "log debug messages such as API calls; repeat for the logs of client-go, like kubectl -v=N")
+	rootCmd.PersistentFlags().StringVar(&opts.logFile, "log-file", "", "write logs to this file instead of stderr, which the TUI hides")
+	rootCmd.PersistentFlags().StringVar(&opts.pprofAddress, "pprof", "", "serve runtime profiles on this address, e.g. localhost:6060, to profile kds")
+	cobra.CheckErr(rootCmd.PersistentFlags().MarkHidden("pprof"))
+	rootCmd.PersistentFlags().StringVar(&opts.configPath, "config", "", "path to the kds config file (default: kds/config.yaml in the user config directory)")
+	rootCmd.Flags().StringVarP(&opts.output, "output", "o", "", "output format for non-interactive mode: json or checksums")
+	rootCmd.Flags().StringVar(&opts.secretKeys, "secret-keys", "", "regular expression for keys whose values get a strength indicator (default: password-like keys)")
+	rootCmd.Flags().BoolVarP(&opts.allNamespaces, "all-namespaces", "A", false, "browse the secrets of every namespace, skipping those you cannot list")
+	rootCmd.Flags().BoolVar(&opts.pickNamespace, "pick-namespace", false, "pick the namespace to browse on startup, which kds also does when neither --namespace nor the context sets one")
+	rootCmd.Flags().BoolVar(&opts.watch, "watch", false, "keep the list up to date with the changes to secrets, and mark the changed ones")
+	rootCmd.Flags().BoolVar(&opts.bell, "bell", false, "ring the terminal bell when a secret changes while watching")
+	rootCmd.Flags().BoolVar(&opts.batch, "batch", false, "read secret names (optionally namespace/name) from stdin, one per line")
+	cobra.CheckErr(rootCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces(opts)))
+
+	return rootCmd
+}
+
+// printSecretDirectly prints a secret in the output format of the root command,
+// after confirming access to protected namespaces.
+func printSecretDirectly(cmd *cobra.Command, opts *rootOptions, clientset kube.Client, ref kube.SecretRef) error {
+	refs := []kube.SecretRef{ref}
+	if opts.output != outputChecksums {
+		if err := opts.confirmProtected(cmd, "Reveal", ref); err != nil {
+			return err
+		}
+	}
+	if opts.output == outputChecksums {
+		return printChecksums(cmd.OutOrStdout(), clientset, refs)
+	}
+	redaction, err := opts.loadRedactionPolicy()
+	if err != nil {
+		return err
+	}
+	confirm := func(keys []string) bool {
+		return askConfirmation(cmd, fmt.Sprintf("Reveal %s, redacted by policy?", strings.Join(keys, ", ")))
+	}
+	if opts.output == outputJSON {
+		return kube.PrintSecretsJSON(cmd.OutOrStdout(), clientset, refs, false, redaction, confirm)
+	}
+	return viewSecretDataDirectly(clientset, ref.Name, ref.Namespace, redaction, confirm)
+}
+
+// viewSecretDataDirectly handles the non-interactive output. It fetches a single
+// secret and prints its data to standard output.
+func viewSecretDataDirectly(clientset kube.Client, secretName, namespace string, redaction kube.RedactionPolicy, confirm func(keys []string) bool) error {
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get secret '%s': %w", secretName, err)
+	}
+	masked := make(map[string]bool)
+	for _, key := range kube.KeysWithMode(redaction, secret.Data, kube.RedactMask) {
+		masked[key] = true
+	}
+	if keys := kube.KeysWithMode(redaction, secret.Data, kube.RedactConfirm); len(keys) > 0 && (confirm == nil || !confirm(keys)) {
+		for _, key := range keys {
+			masked[key] = true
+		}
+	}
+	printSecret(secret, masked)
+	return nil
+}
+
+// printSecret prints the decoded data of a secret in the styled, human-readable
+// format, with the masked keys redacted.
+func printSecret(secret *corev1.Secret, masked map[string]bool) {
+	fmt.Println(ui.TitleStyle.Render(fmt.Sprintf("Data for secret '%s' in namespace '%s'", secret.Name, secret.Namespace)))
+	for key, value := range secret.Data {
+		if masked[key] {
+			fmt.Printf("  %s: %s %s\n", key, kube.MaskValue(value), ui.NoteStyle.Render("(redacted by policy)"))
+		} else if decodedValue, ok := kube.DecodeValue(value); ok {
+			fmt.Printf("  %s: %s\n", key, decodedValue)
+		} else {
+			fmt.Printf("  %s: %s %s\n", key, decodedValue, ui.NoteStyle.Render("(raw value)"))
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// TestMain keeps the keys of the tests in memory rather than in the keychain of
+// the machine.
+func TestMain(m *testing.M) {
+	keyring.MockInit()
+	os.Exit(m.Run())
+}
+
+// TestResolveNamespace verifies resolving the active namespace from a kubeconfig file.
+func TestResolveNamespace(t *testing.T) {
+	expectedNamespace := "my-test-namespace"
+	kubeconfigFile, err := createFakeKubeconfig(expectedNamespace)
+	if err != nil {
+		t.Fatalf("Failed to create fake kubeconfig: %v", err)
+	}
+	defer os.Remove(kubeconfigFile.Name())
+	t.Run("should use the namespace of the current context", func(t *testing.T) {
+		opts := newTestOptions(kubeconfigFile.Name())
+		namespace, err := opts.resolveNamespace()
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if namespace != expectedNamespace {
+			t.Errorf("Expected namespace '%s', but got '%s'", expectedNamespace, namespace)
+		}
+	})
+	t.Run("should prefer the --namespace override", func(t *testing.T) {
+		opts := newTestOptions(kubeconfigFile.Name())
+		*opts.flags().Namespace = "override"
+		namespace, err := opts.resolveNamespace()
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if namespace != "override" {
+			t.Errorf("Expected namespace 'override', but got '%s'", namespace)
+		}
+	})
+}
+
+// TestConfigFlag verifies that every subcommand accepts --config, since most of
+// them read the config file.
+func TestConfigFlag(t *testing.T) {
+	for _, cmd := range newRootCmd().Commands() {
+		t.Run("should be accepted by "+cmd.Name(), func(t *testing.T) {
+			if cmd.Flag("config") == nil {
+				t.Errorf("Expected %s to accept --config", cmd.CommandPath())
+			}
+		})
+	}
+}
+
+// newTestOptions returns options that read the kubeconfig file at path.
+func newTestOptions(kubeconfig string) *rootOptions {
+	opts := &rootOptions{}
+	*opts.flags().KubeConfig = kubeconfig
+	return opts
+}
+
+// createFakeKubeconfig is a helper function to create a temporary kubeconfig file.
+func createFakeKubeconfig(namespace string) (*os.File, error) {
+	config := clientcmdapi.Config{
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{"user": {}},
+		Clusters:  map[string]*clientcmdapi.Cluster{"cluster": {Server: "https://example.com"}},
+		Contexts: map[string]*clientcmdapi.Context{
+			"my-context": {AuthInfo: "user", Cluster: "cluster", Namespace: namespace},
+		},
+		CurrentContext: "my-context",
+	}
+	file, err := os.CreateTemp("", "kubeconfig-")
+	if err != nil {
+		return nil, err
+	}
+	if err := clientcmd.WriteToFile(config, file.Name()); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// TestNamespaceConfigured verifies telling a namespace set by the context or the
+// flags from the default namespace, which the TUI asks to pick instead.
+func TestNamespaceConfigured(t *testing.T) {
+	for namespace, want := range map[string]bool{"my-test-namespace": true, "": false} {
+		kubeconfigFile, err := createFakeKubeconfig(namespace)
+		if err != nil {
+			t.Fatalf("Failed to create fake kubeconfig: %v", err)
+		}
+		defer os.Remove(kubeconfigFile.Name())
+		t.Run("should tell whether the context sets namespace '"+namespace+"'", func(t *testing.T) {
+			opts := newTestOptions(kubeconfigFile.Name())
+			if got := opts.namespaceConfigured(); got != want {
+				t.Errorf("Expected %t, but got %t", want, got)
+			}
+		})
+	}
+	t.Run("should take --namespace as configured", func(t *testing.T) {
+		kubeconfigFile, err := createFakeKubeconfig("")
+		if err != nil {
+			t.Fatalf("Failed to create fake kubeconfig: %v", err)
+		}
+		defer os.Remove(kubeconfigFile.Name())
+		opts := newTestOptions(kubeconfigFile.Name())
+		*opts.flags().Namespace = "override"
+		if !opts.namespaceConfigured() {
+			t.Error("Expected the namespace to be configured")
+		}
+	})
+}
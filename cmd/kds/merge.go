@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// conflictAsk is the --on-conflict value that asks how to resolve each conflict.
+const conflictAsk = "ask"
+
+// newMergeCmd creates the 'kds merge' command.
+func newMergeCmd(opts *rootOptions) *cobra.Command {
+	var into, onConflict, dryRun string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "merge <secret-name>... --into <secret-name>",
+		Short: "Merge several secrets into one",
+		Long: `Merge the keys and labels of several secrets of a namespace, in order, into one,
+to consolidate per-service secrets. The target secret is created, or, if it already
+exists, merged into as the first of the secrets. The merged secrets are kept.
+
+Keys with the same value in several secrets are merged silently. For the others,
+kds asks whether to keep the value merged so far (left), the value of the secret
+being merged (right), or both by renaming the key of the right one. --on-conflict
+picks left or right for every conflict instead. The merged keys are shown, without
+their values, before asking for confirmation.`,
+		Example: `  # Consolidate per-service secrets
+  kds merge api-db api-cache api-queue --into api-secrets`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if into == "" {
+				return errors.New("--into is required")
+			}
+			if onConflict != conflictAsk && onConflict != kube.KeepLeft && onConflict != kube.KeepRight {
+				return fmt.Errorf("invalid --on-conflict '%s': use ask, left, or right", onConflict)
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			mu, err := kube.PrepareMerge(clientset, namespace, args, into, func(c kube.MergeConflict) (kube.MergeResolution, error) {
+				if onConflict != conflictAsk {
+					return kube.MergeResolution{Keep: onConflict}, nil
+				}
+				return askMergeResolution(cmd, c)
+			})
+			if err != nil {
+				return err
+			}
+			if applied, err := runMutation(cmd, clientset, mu, dryRun, !yes); err != nil || !applied {
+				return err
+			}
+			cmd.PrintErrf("Merged %s into secret '%s'\n", strings.Join(args, ", "), mu.Ref())
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&into, "into", "", "secret to merge the secrets into, created if needed")
+	cmd.Flags().StringVar(&onConflict, "on-conflict", conflictAsk, "how to resolve keys with different values: ask, left, or right")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+	addMutationFlags(cmd, &dryRun)
+	return cmd
+}
+
+// askMergeResolution asks how to resolve a conflict until it gets a valid answer,
+// and for a new key until it gets a valid one.
+func askMergeResolution(cmd *cobra.Command, c kube.MergeConflict) (kube.MergeResolution, error) {
+	question := fmt.Sprintf("Key '%s' differs in '%s' and '%s'. Keep [l]eft, keep [r]ight, or re[n]ame the right one", c.Key, c.Left, c.Right)
+	for {
+		answer, err := askValue(cmd, question, false)
+		if err != nil {
+			return kube.MergeResolution{}, err
+		}
+		switch strings.ToLower(answer) {
+		case "l", "left":
+			return kube.MergeResolution{Keep: kube.KeepLeft}, nil
+		case "r", "right":
+			return kube.MergeResolution{Keep: kube.KeepRight}, nil
+		case "n", "rename":
+			return askMergeKey(cmd, c)
+		case "":
+			return kube.MergeResolution{}, fmt.Errorf("merge aborted at the conflict on key '%s'", c.Key)
+		}
+	}
+}
+
+// askMergeKey asks for the key to rename the right value of a conflict to, until
+// it gets a valid one.
+func askMergeKey(cmd *cobra.Command, c kube.MergeConflict) (kube.MergeResolution, error) {
+	for {
+		newKey, err := askValue(cmd, fmt.Sprintf("New key for '%s' of '%s'", c.Key, c.Right), false)
+		if err != nil {
+			return kube.MergeResolution{}, err
+		}
+		if newKey == "" {
+			return kube.MergeResolution{}, fmt.Errorf("merge aborted at the conflict on key '%s'", c.Key)
+		}
+		if err := kube.ValidateKey(newKey); err != nil {
+			cmd.PrintErrln(err)
+			continue
+		}
+		return kube.MergeResolution{Keep: kube.RenameKey, NewKey: newKey}, nil
+	}
+}
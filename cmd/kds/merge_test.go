@@ -0,0 +1,45 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// TestAskMergeResolution verifies that conflicts are resolved from the answers.
+func TestAskMergeResolution(t *testing.T) {
+	conflict := kube.MergeConflict{Key: "url", Left: "api-db", Right: "api-cache"}
+	ask := func(answers string) (kube.MergeResolution, error) {
+		cmd := &cobra.Command{}
+		cmd.SetIn(strings.NewReader(answers))
+		cmd.SetErr(io.Discard)
+		return askMergeResolution(cmd, conflict)
+	}
+
+	t.Run("should ask again after an invalid answer", func(t *testing.T) {
+		resolution, err := ask("x\nr\n")
+		if err != nil || resolution.Keep != kube.KeepRight {
+			t.Errorf("Expected to keep the right value, but got %v, %v", resolution, err)
+		}
+	})
+	t.Run("should ask for the new key", func(t *testing.T) {
+		resolution, err := ask("n\ncache-url\n")
+		if err != nil || resolution != (kube.MergeResolution{Keep: kube.RenameKey, NewKey: "cache-url"}) {
+			t.Errorf("Expected to rename to cache-url, but got %v, %v", resolution, err)
+		}
+	})
+	t.Run("should ask again for an invalid key", func(t *testing.T) {
+		resolution, err := ask("n\ncache/url\ncache-url\n")
+		if err != nil || resolution != (kube.MergeResolution{Keep: kube.RenameKey, NewKey: "cache-url"}) {
+			t.Errorf("Expected to rename to cache-url, but got %v, %v", resolution, err)
+		}
+	})
+	t.Run("should abort without an answer", func(t *testing.T) {
+		if _, err := ask(""); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newMetadataCmd creates the 'kds label' or 'kds annotate' command for the given field.
+func newMetadataCmd(opts *rootOptions, use, field string) *cobra.Command {
+	bulk := &bulkOptions{}
+
+	cmd := &cobra.Command{
+		Use:   use + " <secret-name> key=value... | key-...",
+		Short: fmt.Sprintf("Add or remove %s on a secret", field),
+		Long: fmt.Sprintf(`Add or remove %[1]s on a secret.
+
+Each argument is either key=value, which sets the key, or key-, which removes it.
+The changes are applied with a single JSON patch.
+
+With --selector and --all-matching, the changes are applied to every secret
+matching the label selector instead, and only the changes are passed as arguments.
+The matching secrets are listed first, and the changes have to be confirmed unless
+--yes is set.`, field),
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var names []string
+			if bulk.selector == "" {
+				names, args = args[:1], args[1:]
+			}
+			if err := bulk.validate(names); err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("no %s to change", field)
+			}
+			changes, err := kube.ParseMetadataChanges(field, args)
+			if err != nil {
+				return err
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			refs, err := bulk.resolve(cmd, clientset, namespace, names, "Update "+field+" of")
+			if err != nil {
+				return err
+			}
+			return runOnRefs(refs, func(ref kube.SecretRef) error {
+				if err := kube.PatchMetadata(clientset, ref, field, changes); err != nil {
+					return fmt.Errorf("failed to update %s of secret '%s': %w", field, ref, err)
+				}
+				cmd.PrintErrf("Updated %s of secret '%s'\n", field, ref)
+				return nil
+			})
+		},
+	}
+	bulk.addFlags(cmd)
+	return cmd
+}
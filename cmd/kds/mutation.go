@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/diskmanti/kds/pkg/ui"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// addMutationFlags adds a kubectl-style --dry-run flag to a mutating command, and
+// --force-conflicts. A bare --dry-run means "client".
+func addMutationFlags(cmd *cobra.Command, value *string) {
+	cmd.Flags().StringVar(value, "dry-run", kube.DryRunNone,
+		`"client" to only show the changes, or "server" to check them with a server-side dry run and confirm before applying`)
+	cmd.Flags().Lookup("dry-run").NoOptDefVal = kube.DryRunClient
+	cmd.Flags().Bool("force-conflicts", false, "take over the fields of the secret that other managers applied with other values")
+}
+
+// runMutation applies a mutation from a CLI command, honoring --dry-run. With
+// "client", the changes are only shown. With "server", they are computed by a
+// server-side dry run and applied after confirmation. Without a dry run, the
+// changes are shown and confirmed only if confirm is set. It reports whether the
+// mutation was applied. Changes to fields that other managers applied are refused
+// unless --force-conflicts is set.
+func runMutation(cmd *cobra.Command, clientset kube.Client, mu kube.Mutation, dryRun string, confirm bool) (bool, error) {
+	if err := kube.ValidateDryRun(dryRun); err != nil {
+		return false, err
+	}
+	mu.Force, _ = cmd.Flags().GetBool("force-conflicts")
+	if dryRun == kube.DryRunClient && mu.Before == nil {
+		return false, printSecretPreview(cmd.OutOrStdout(), mu.After)
+	}
+	warnGitOpsManaged(cmd, mu.Before)
+	changes := kube.DiffSecrets(mu.Before, mu.After)
+	if dryRun == kube.DryRunServer {
+		var err error
+		if changes, err = mu.Preview(clientset); err != nil {
+			return false, withConflictHint(err)
+		}
+	}
+	if dryRun != kube.DryRunNone || confirm {
+		cmd.PrintErrf("Changes to secret '%s' (%s):\n%s", mu.Ref(), kube.SummarizeDataDiff(changes), ui.RenderDataDiff(changes))
+	}
+	switch {
+	case dryRun == kube.DryRunClient:
+		return false, nil
+	case len(changes) == 0:
+		cmd.PrintErrf("Secret '%s' is already up to date\n", mu.Ref())
+		return false, nil
+	case (dryRun == kube.DryRunServer || confirm) && !askConfirmation(cmd, "Apply these changes?"):
+		cmd.PrintErrf("No changes made to secret '%s'\n", mu.Ref())
+		return false, nil
+	}
+	if _, err := mu.Apply(clientset, false); err != nil {
+		return false, withConflictHint(err)
+	}
+	return true, nil
+}
+
+// withConflictHint tells how to take over the fields that other managers applied.
+func withConflictHint(err error) error {
+	var conflictErr *kube.ConflictError
+	if errors.As(err, &conflictErr) {
+		return fmt.Errorf("%w: use --force-conflicts to take them over", err)
+	}
+	return err
+}
+
+// warnGitOpsManaged warns on stderr that changes to a secret managed by a GitOps
+// tool will not last.
+func warnGitOpsManaged(cmd *cobra.Command, secret *corev1.Secret) {
+	if secret == nil {
+		return
+	}
+	if manager, managed := kube.GitOpsManagerOf(secret); managed {
+		cmd.PrintErrf("Warning: secret '%s/%s' is %s\n", secret.Namespace, secret.Name, manager.DriftWarning())
+	}
+}
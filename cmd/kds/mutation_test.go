@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newDryRunClientset returns a fake clientset that, like a real API server, does
+// not persist dry-run creates and defaults the type of new secrets.
+func newDryRunClientset(objects ...runtime.Object) *fake.Clientset {
+	clientset := fake.NewSimpleClientset(objects...)
+	clientset.PrependReactor("create", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		create := action.(k8stesting.CreateActionImpl)
+		if len(create.CreateOptions.DryRun) == 0 {
+			return false, nil, nil
+		}
+		secret := create.GetObject().(*corev1.Secret).DeepCopy()
+		if secret.Type == "" {
+			secret.Type = corev1.SecretTypeOpaque
+		}
+		return true, secret, nil
+	})
+	return clientset
+}
+
+// TestRunMutation verifies the --dry-run modes of mutating commands.
+func TestRunMutation(t *testing.T) {
+	secret := kube.NewSecret("default", "app", "", map[string][]byte{"password": []byte("s3cr3t")})
+	run := func(t *testing.T, clientset *fake.Clientset, dryRun, answer string) (bool, string) {
+		t.Helper()
+		cmd := &cobra.Command{}
+		var out, errOut strings.Builder
+		cmd.SetIn(strings.NewReader(answer))
+		cmd.SetOut(&out)
+		cmd.SetErr(&errOut)
+		applied, err := runMutation(cmd, clientset, kube.Mutation{After: secret.DeepCopy()}, dryRun, false)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		return applied, out.String() + errOut.String()
+	}
+	exists := func(clientset *fake.Clientset) bool {
+		_, err := clientset.CoreV1().Secrets("default").Get(context.TODO(), "app", metav1.GetOptions{})
+		return err == nil
+	}
+
+	t.Run("should only preview with a client dry run", func(t *testing.T) {
+		clientset := newDryRunClientset()
+		applied, output := run(t, clientset, kube.DryRunClient, "")
+		if applied || exists(clientset) {
+			t.Error("Expected nothing to be created, but the secret exists")
+		}
+		if !strings.Contains(output, "password") || strings.Contains(output, "s3cr3t") {
+			t.Errorf("Expected a masked preview, but got:\n%s", output)
+		}
+	})
+
+	t.Run("should show the server's changes and apply them after confirmation", func(t *testing.T) {
+		clientset := newDryRunClientset()
+		applied, output := run(t, clientset, kube.DryRunServer, "y\n")
+		if !applied || !exists(clientset) {
+			t.Error("Expected the secret to be created after confirmation, but it was not")
+		}
+		if !strings.Contains(output, "+ data.password") || !strings.Contains(output, "+ type") {
+			t.Errorf("Expected the diff to include the data and the defaulted type, but got:\n%s", output)
+		}
+	})
+
+	t.Run("should not apply a server dry run that is not confirmed", func(t *testing.T) {
+		clientset := newDryRunClientset()
+		if applied, _ := run(t, clientset, kube.DryRunServer, "n\n"); applied || exists(clientset) {
+			t.Error("Expected nothing to be created, but the secret exists")
+		}
+	})
+
+	t.Run("should reject an unknown dry-run mode", func(t *testing.T) {
+		cmd := &cobra.Command{}
+		if _, err := runMutation(cmd, newDryRunClientset(), kube.Mutation{After: secret}, "maybe", false); err == nil {
+			t.Error("Expected an error, but got nil")
+		}
+	})
+}
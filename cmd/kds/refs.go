@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newCheckRefsCmd creates the 'kds check-refs' command.
+func newCheckRefsCmd(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "check-refs",
+		Short: "Find workloads that reference missing secrets or keys",
+		Long: `Find the workloads and pods of a namespace that reference secrets, or keys of
+secrets, that do not exist, through env secretKeyRefs, envFrom, or volumes. Their
+pods cannot start, and wait in CreateContainerConfigError or ContainerCreating.
+
+References marked optional are ignored. The command fails if any reference is
+broken.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			usages, err := kube.BuildUsageIndex(clientset, namespace)
+			if err != nil {
+				return err
+			}
+			secrets, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to list secrets in namespace '%s': %w", namespace, err)
+			}
+			broken := kube.FindBrokenReferences(usages, secrets.Items)
+			if err := printBrokenReferences(cmd.OutOrStdout(), broken); err != nil {
+				return err
+			}
+			if len(broken) > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d broken secret references in namespace '%s'", len(broken), namespace)
+			}
+			cmd.PrintErrf("Every secret reference in namespace '%s' resolves\n", namespace)
+			return nil
+		},
+	}
+}
+
+// printBrokenReferences writes one line per broken reference.
+func printBrokenReferences(w io.Writer, broken []kube.BrokenReference) error {
+	var b strings.Builder
+	for _, ref := range broken {
+		fmt.Fprintf(&b, "- %s\n", ref)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newReloaderCmd creates the 'kds reloader' command.
+func newReloaderCmd(opts *rootOptions) *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "reloader <secret-name>",
+		Short: "Make Reloader restart the workloads using a secret when it changes",
+		Long: `Make Stakater Reloader restart the workloads using a secret when it changes.
+
+The secret is added to the ` + kube.ReloaderSecretAnnotation + ` annotation of every
+Deployment, StatefulSet, and DaemonSet that uses it and is not already restarted
+automatically by Reloader or Wave.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			workloads, err := kube.FindConsumers(clientset, ref)
+			if err != nil {
+				return fmt.Errorf("failed to find the workloads using secret '%s': %w", ref, err)
+			}
+			manual := kube.ManualRestarts(workloads)
+			if len(manual) == 0 {
+				cmd.PrintErrf("No workload using secret '%s' needs a Reloader annotation\n", ref)
+				return nil
+			}
+			cmd.PrintErrf("Workloads using secret '%s' without automatic restarts:\n", ref)
+			for _, workload := range manual {
+				cmd.PrintErrf("  - %s\n", workload)
+			}
+			if !yes && !askConfirmation(cmd, "Add the Reloader annotation to them?") {
+				return errors.New("annotation of workloads aborted")
+			}
+			if err := kube.AddReloaderAnnotations(clientset, ref, manual); err != nil {
+				return err
+			}
+			cmd.PrintErrf("Annotated %d workload(s)\n", len(manual))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+	return cmd
+}
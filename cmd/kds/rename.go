@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newRenameCmd creates the 'kds rename' command.
+func newRenameCmd(opts *rootOptions) *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "rename <secret-name> <new-name>",
+		Short: "Rename a secret, keeping its data, labels, and annotations",
+		Long: `Rename a secret, keeping its data, type, labels, and annotations.
+
+Kubernetes has no native rename, so kds creates a copy under the new name and then
+deletes the original. Before doing so, it lists the workloads, ingresses, and service
+accounts that still reference the old name and asks for confirmation.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			idx, err := kube.BuildUsageIndex(clientset, namespace)
+			if err != nil {
+				return fmt.Errorf("failed to check the usages of secret '%s': %w", ref, err)
+			}
+			if usages := idx[ref.Name]; len(usages) > 0 {
+				cmd.PrintErrf("Secret '%s' is still referenced by:\n", ref)
+				for _, usage := range usages {
+					cmd.PrintErrf("  - %s\n", usage)
+				}
+				cmd.PrintErrln("These references will break until they are updated to the new name.")
+			}
+			if !yes && !askConfirmation(cmd, fmt.Sprintf("Rename secret '%s' to '%s'?", ref, args[1])) {
+				return fmt.Errorf("rename of secret '%s' aborted", ref)
+			}
+			if err := kube.RenameSecret(clientset, ref, args[1]); err != nil {
+				return err
+			}
+			cmd.PrintErrf("Renamed secret '%s' to '%s'\n", ref, args[1])
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+	return cmd
+}
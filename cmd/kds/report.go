@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newReportCmd creates the 'kds report' command, which renders secrets as a
+// document that never contains plaintext values.
+func newReportCmd(opts *rootOptions) *cobra.Command {
+	var format, values string
+
+	cmd := &cobra.Command{
+		Use:   "report [secret-name...]",
+		Short: "Render secrets as a Markdown or HTML report with masked values",
+		Long: `Render one or more secrets (all secrets in the namespace if none are given) as a
+Markdown or HTML report. Values are never printed: they are either masked or
+replaced by their SHA-256 checksum, which makes the report safe to attach to
+documentation and change reviews.`,
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != kube.ReportMarkdown && format != kube.ReportHTML {
+				return fmt.Errorf("unknown report format '%s' (supported: markdown, html)", format)
+			}
+			if values != kube.ValuesMasked && values != kube.ValuesHashed {
+				return fmt.Errorf("unknown value rendering '%s' (supported: masked, hashed)", values)
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			secrets, err := kube.GetSecretsOrAll(clientset, namespace, args)
+			if err != nil {
+				return err
+			}
+			sections := kube.BuildReport(secrets, values == kube.ValuesHashed)
+			if format == kube.ReportHTML {
+				return kube.WriteHTMLReport(cmd.OutOrStdout(), namespace, sections)
+			}
+			_, err = io.WriteString(cmd.OutOrStdout(), kube.MarkdownReport(namespace, sections))
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", kube.ReportMarkdown, "report format: markdown or html")
+	cmd.Flags().StringVar(&values, "values", kube.ValuesMasked, "how to render values: masked or hashed")
+	return cmd
+}
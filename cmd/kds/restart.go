@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// offerRestart lists the workloads consuming a changed secret and restarts them
+// if restart is set or the user agrees.
+func offerRestart(cmd *cobra.Command, clientset kube.Client, ref kube.SecretRef, restart bool) error {
+	workloads, err := kube.FindConsumers(clientset, ref)
+	if err != nil {
+		return fmt.Errorf("failed to find the workloads using secret '%s': %w", ref, err)
+	}
+	if note := kube.AutoRestartNote(workloads); note != "" {
+		cmd.PrintErrf("Secret '%s' %s\n", ref, note)
+	}
+	workloads = kube.ManualRestarts(workloads)
+	if len(workloads) == 0 {
+		return nil
+	}
+	cmd.PrintErrf("Secret '%s' is also used by:\n", ref)
+	for _, workload := range workloads {
+		cmd.PrintErrf("  - %s\n", workload)
+	}
+	if !restart && !askConfirmation(cmd, "Restart them so they pick up the change?") {
+		return nil
+	}
+	if err := kube.RestartWorkloads(clientset, ref.Namespace, workloads); err != nil {
+		return err
+	}
+	cmd.PrintErrf("Restarted %d workload(s)\n", len(workloads))
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// rotationConfig configures how keys are rotated by 'kds rotate' and the TUI.
+type rotationConfig struct {
+	// KeepPrevious is where the previous value of a rotated key is kept: key,
+	// annotation, or none (default: key).
+	KeepPrevious string `json:"keepPrevious,omitempty"`
+}
+
+// keepPrevious returns where to keep previous values, by default in a sibling key,
+// which is as secret as the value itself, unlike an annotation.
+func (c rotationConfig) keepPrevious() (string, error) {
+	if c.KeepPrevious == "" {
+		return kube.KeepPreviousKey, nil
+	}
+	return c.KeepPrevious, kube.ValidateKeepPrevious(c.KeepPrevious)
+}
+
+// newRotateCmd creates the 'kds rotate' command.
+func newRotateCmd(opts *rootOptions) *cobra.Command {
+	var generator, keepPrevious, dryRun string
+	var restart bool
+
+	cmd := &cobra.Command{
+		Use:   "rotate <secret-name> <key>",
+		Short: "Rotate a key of a secret to a generated value",
+		Long: `Rotate a key of a secret: set it to a generated value, keep the previous value,
+apply the change after showing it, and offer to restart the workloads that use the
+secret.
+
+The new value is generated by --generate, given as kind[:length] like the
+--generate flag of 'kds create generic', or by default shaped like the current
+value: a keypair of the same kind, or a UUID, hex, passphrase, or alphanumeric
+value of the same length. Keypairs rotate the public key under key.pub too.
+
+When the key was rotated is recorded in the rotated.kds.diskmanti.io/<key>
+annotation. The previous value is kept in the sibling key <key>.previous, so that
+clients can still be switched over, or base64-encoded in the
+previous.kds.diskmanti.io/<key> annotation with --keep-previous=annotation, where
+anyone allowed to read the metadata of the secret can read it; none keeps
+nothing. The default is taken from the rotation.keepPrevious setting of the
+config file. The new value is never printed: read it with 'kds get'.`,
+		Example: `  # Rotate a password to a new value of the same shape
+  kds rotate db-credentials password
+
+  # Rotate a signing key, without keeping the old one
+  kds rotate jwt signing-key --generate ed25519 --keep-previous none`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeSecretNameAndKey(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var rotOpts kube.RotationOptions
+			if generator != "" {
+				g, err := kube.ParseGenerator(generator)
+				if err != nil {
+					return err
+				}
+				rotOpts.Generator = g
+			}
+			config, err := loadConfig(opts.configPath)
+			if err != nil {
+				return err
+			}
+			if rotOpts.KeepPrevious, err = config.Rotation.keepPrevious(); err != nil {
+				return fmt.Errorf("invalid config: %w", err)
+			}
+			if keepPrevious != "" {
+				rotOpts.KeepPrevious = keepPrevious
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			mu, err := kube.PrepareRotation(clientset, ref, args[1], rotOpts, time.Now())
+			if err != nil {
+				return fmt.Errorf("cannot rotate key '%s' of secret '%s': %w", args[1], ref, err)
+			}
+			if applied, err := runMutation(cmd, clientset, mu, dryRun, true); err != nil || !applied {
+				return err
+			}
+			cmd.PrintErrf("Rotated key '%s' of secret '%s'\n", args[1], ref)
+			return offerRestart(cmd, clientset, ref, restart)
+		},
+	}
+	cmd.Flags().StringVar(&generator, "generate", "", "the kind[:length] of the new value (default: shaped like the current value)")
+	cmd.Flags().StringVar(&keepPrevious, "keep-previous", "", "where to keep the previous value: key, annotation, or none (default from the config file, or key)")
+	cmd.Flags().BoolVar(&restart, "restart", false, "restart the workloads using the secret without asking")
+	addMutationFlags(cmd, &dryRun)
+	return cmd
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newRunCmd creates the 'kds run' command.
+func newRunCmd(opts *rootOptions) *cobra.Command {
+	var envOpts kube.EnvOptions
+	var asFiles bool
+
+	cmd := &cobra.Command{
+		Use:   "run <secret-name>... -- <command> [args...]",
+		Short: "Run a command with the keys of secrets in its environment",
+		Long: `Run a command with the keys of one or more secrets in its environment, for local
+debugging without writing them anywhere.
+
+Keys are named like 'kds env' names them, with --prefix and --lowercase. When
+several secrets set the same variable, the last one wins. With --files, every key
+is written to a file of a private temporary directory instead, and its variable
+holds the path of the file, for programs that read certificates or keys from
+files. The directory is removed when the command exits.
+
+Interrupts are passed to the command, and kds exits with its exit code.`,
+		Example: `  # Start a server with the credentials of the cluster
+  kds run app-secret db-credentials -- ./server
+
+  # Pass a TLS key pair as files: TLS_CRT and TLS_KEY hold their paths
+  kds run app-tls --files -- sh -c 'openssl x509 -in "$TLS_CRT" -noout -subject'`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			dash := cmd.ArgsLenAtDash()
+			if dash < 1 || dash == len(args) {
+				return errors.New("expected secret names, then -- and a command")
+			}
+			return nil
+		},
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, command := args[:cmd.ArgsLenAtDash()], args[cmd.ArgsLenAtDash():]
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			// Files can hold binary values, such as keystores, which the environment cannot.
+			envOpts.AllowBinary = asFiles
+			var vars []kube.EnvVar
+			for _, name := range names {
+				secretVars, err := secretEnvVars(cmd, opts, clientset, kube.SecretRef{Namespace: namespace, Name: name}, envOpts)
+				if err != nil {
+					return err
+				}
+				vars = append(vars, secretVars...)
+			}
+			dir := ""
+			if asFiles {
+				if dir, err = os.MkdirTemp("", "kds-run-*"); err != nil {
+					return fmt.Errorf("failed to create a directory for the files: %w", err)
+				}
+				defer os.RemoveAll(dir)
+			}
+			env, err := buildRunEnv(os.Environ(), vars, dir)
+			if err != nil {
+				return err
+			}
+			err = runCommand(command, env)
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				// The command already reported what went wrong.
+				cmd.SilenceErrors, cmd.SilenceUsage = true, true
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&envOpts.Prefix, "prefix", "", "prepend this prefix to every variable name")
+	cmd.Flags().BoolVar(&envOpts.Lowercase, "lowercase", false, "use lower-case variable names")
+	cmd.Flags().BoolVar(&asFiles, "files", false, "write the keys to temporary files and pass their paths instead")
+	return cmd
+}
+
+// buildRunEnv adds the variables to the environment base. With a directory, each
+// value is written to a file in it, and the variable holds the path of the file.
+func buildRunEnv(base []string, vars []kube.EnvVar, dir string) ([]string, error) {
+	env := append([]string(nil), base...)
+	for _, v := range vars {
+		value := string(v.Value)
+		if dir != "" {
+			value = filepath.Join(dir, v.Name)
+			if err := os.WriteFile(value, v.Value, 0o600); err != nil {
+				return nil, fmt.Errorf("failed to write key '%s': %w", v.Key, err)
+			}
+		}
+		// exec.Cmd keeps the last value of duplicate variables.
+		env = append(env, v.Name+"="+value)
+	}
+	return env, nil
+}
+
+// runCommand runs a command attached to the terminal and waits for it, passing it
+// the interrupts that kds receives meanwhile.
+func runCommand(command, env []string) error {
+	process := exec.Command(command[0], command[1:]...)
+	process.Env = env
+	process.Stdin, process.Stdout, process.Stderr = os.Stdin, os.Stdout, os.Stderr
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	if err := process.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", command[0], err)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = process.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return process.Wait()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// TestBuildRunEnv verifies the environment 'kds run' passes to its command.
+func TestBuildRunEnv(t *testing.T) {
+	vars := []kube.EnvVar{{Name: "TLS_KEY", Key: "tls.key", Value: []byte("-----BEGIN KEY-----")}}
+
+	t.Run("should append the values to the environment", func(t *testing.T) {
+		env, err := buildRunEnv([]string{"HOME=/root"}, vars, "")
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		expected := []string{"HOME=/root", "TLS_KEY=-----BEGIN KEY-----"}
+		if !reflect.DeepEqual(env, expected) {
+			t.Errorf("Expected %v, but got %v", expected, env)
+		}
+	})
+
+	t.Run("should pass the paths of files holding the values", func(t *testing.T) {
+		dir := t.TempDir()
+		env, err := buildRunEnv(nil, vars, dir)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		path := filepath.Join(dir, "TLS_KEY")
+		if !reflect.DeepEqual(env, []string{"TLS_KEY=" + path}) {
+			t.Errorf("Expected the variable to hold %s, but got %v", path, env)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil || string(content) != "-----BEGIN KEY-----" {
+			t.Errorf("Expected the file to hold the value, but got %q, %v", content, err)
+		}
+	})
+
+	t.Run("should pass binary values as files", func(t *testing.T) {
+		keystore := []byte{0xfe, 0xed, 0x00, 0x02}
+		binary, err := kube.EnvVars(map[string][]byte{"keystore.p12": keystore}, kube.EnvOptions{AllowBinary: true})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		dir := t.TempDir()
+		if _, err := buildRunEnv(nil, binary, dir); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		content, err := os.ReadFile(filepath.Join(dir, "KEYSTORE_P12"))
+		if err != nil || !bytes.Equal(content, keystore) {
+			t.Errorf("Expected the file to hold the keystore, but got %q, %v", content, err)
+		}
+	})
+}
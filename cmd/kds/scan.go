@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Output formats of the 'kds scan' command.
+const (
+	scanOutputTable = "table"
+	scanOutputJSON  = "json"
+)
+
+// newScanCmd creates the 'kds scan' command.
+func newScanCmd(opts *rootOptions) *cobra.Command {
+	scanOpts := &kube.ScanOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "scan [secret-name...]",
+		Short: "Scan secrets for weak, default, and leaked credentials",
+		Long: `Scan secrets (all secrets in the namespace if none are given) for weak and default
+passwords, passwords equal to the username, short passwords, test API keys, and
+credentials in well-known formats such as AWS, GitHub, Slack, Stripe, and Google keys.
+
+Findings are redacted: matched values are masked, keeping at most a short prefix of
+long values to identify the credential. Extend the built-in list of weak passwords
+with --weak-values, a file with one value per line.
+
+With --rules, additional rules are read from a gitleaks-style TOML file, e.g. to
+detect private keys or internal token formats. Each [[rules]] entry has an id, a
+description, and a regex; keywords, secretGroup, entropy, path (matched against the
+key), allowlists, and a severity are optional. Rules replace built-in rules with the
+same id.`,
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if scanOpts.Output != scanOutputTable && scanOpts.Output != scanOutputJSON {
+				return fmt.Errorf("invalid output format '%s': must be table or json", scanOpts.Output)
+			}
+			s, err := kube.NewScanner(scanOpts.WeakValuesFile, scanOpts.RulesFile, scanOpts.MinLength)
+			if err != nil {
+				return err
+			}
+			secrets, err := scanTargets(opts, scanOpts.AllNamespaces, args)
+			if err != nil {
+				return err
+			}
+			return printScanFindings(cmd.OutOrStdout(), s.Scan(secrets), scanOpts.Output)
+		},
+	}
+	cmd.Flags().StringVar(&scanOpts.WeakValuesFile, "weak-values", "", "file with additional weak passwords, one per line")
+	cmd.Flags().StringVar(&scanOpts.RulesFile, "rules", "", "gitleaks-style TOML file with additional rules")
+	cmd.Flags().IntVar(&scanOpts.MinLength, "min-length", 12, "flag passwords shorter than this (0 to disable)")
+	cmd.Flags().StringVarP(&scanOpts.Output, "output", "o", scanOutputTable, "output format: table or json")
+	cmd.Flags().BoolVarP(&scanOpts.AllNamespaces, "all-namespaces", "A", false, "scan the secrets of all namespaces")
+	return cmd
+}
+
+// scanTargets fetches the secrets to scan: the named ones, every secret in the
+// namespace, or every secret in the cluster.
+func scanTargets(opts *rootOptions, allNamespaces bool, names []string) ([]*corev1.Secret, error) {
+	if allNamespaces && len(names) > 0 {
+		return nil, errors.New("secret names cannot be combined with --all-namespaces")
+	}
+	clientset, err := opts.newClientset()
+	if err != nil {
+		return nil, err
+	}
+	if !allNamespaces {
+		namespace, err := opts.resolveNamespace()
+		if err != nil {
+			return nil, err
+		}
+		return kube.GetSecretsOrAll(clientset, namespace, names)
+	}
+	list, err := clientset.CoreV1().Secrets(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	secrets := make([]*corev1.Secret, len(list.Items))
+	for i := range list.Items {
+		secrets[i] = &list.Items[i]
+	}
+	return secrets, nil
+}
+
+// printScanFindings writes the findings as a table or JSON.
+func printScanFindings(w io.Writer, findings []kube.ScanFinding, format string) error {
+	if format == scanOutputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if findings == nil {
+			findings = []kube.ScanFinding{}
+		}
+		return enc.Encode(findings)
+	}
+	var b strings.Builder
+	b.WriteString("SEVERITY\tNAMESPACE\tNAME\tKEY\tRULE\tMATCH\n")
+	for _, f := range findings {
+		key := f.Key
+		if f.Line > 0 {
+			key = fmt.Sprintf("%s:%d", f.Key, f.Line)
+		}
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\t%s\t%s\n", f.Severity, f.Namespace, f.Name, key, f.Rule, f.Match)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if _, err := io.WriteString(tw, b.String()); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"os"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newSealCmd creates the 'kds seal' command.
+func newSealCmd(opts *rootOptions) *cobra.Command {
+	var controllerNamespace, controllerName, certPath, scope, out string
+
+	cmd := &cobra.Command{
+		Use:   "seal <secret-name>",
+		Short: "Turn a secret into a SealedSecret manifest",
+		Long: `Turn a secret into a SealedSecret manifest, which can be committed to a GitOps
+repository and is decrypted back into the secret by the Sealed Secrets controller.
+
+The values are encrypted with the public certificate of the controller, fetched
+through the API server like kubeseal does, or read from --cert for offline use.
+kubeseal does not need to be installed. Only the controller can decrypt them, and
+only within --scope: "strict" (the default) for the same name and namespace,
+"namespace-wide" for any name in the namespace, or "cluster-wide" for anywhere.
+
+The manifest keeps the type, labels, and annotations of the secret. It is written
+to --out, or to stdout.`,
+		Example: `  # Seal a secret into the repository
+  kds seal db-credentials --out clusters/prod/db-credentials.sealed.yaml
+
+  # Use a controller installed elsewhere, or its certificate
+  kds seal db-credentials --controller-namespace sealed-secrets --controller-name sealed-secrets
+  kds seal db-credentials --cert pub-cert.pem`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := kube.ValidateSealScope(scope); err != nil {
+				return err
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			secret, err := kube.GetSecret(clientset, ref)
+			if err != nil {
+				return err
+			}
+			key, err := sealingKey(clientset, certPath, controllerNamespace, controllerName)
+			if err != nil {
+				return err
+			}
+			manifest, err := kube.SealSecret(secret, key, scope)
+			if err != nil {
+				return fmt.Errorf("failed to seal secret '%s': %w", ref, err)
+			}
+			if out == "" {
+				_, err := cmd.OutOrStdout().Write(manifest)
+				return err
+			}
+			if err := os.WriteFile(out, manifest, 0o644); err != nil { //nolint:gosec // Only the controller can decrypt the values.
+				return fmt.Errorf("failed to write %s: %w", out, err)
+			}
+			cmd.PrintErrf("Sealed secret '%s' into %s\n", ref, out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&controllerNamespace, "controller-namespace", kube.DefaultSealedSecretsNamespace, "namespace of the Sealed Secrets controller")
+	cmd.Flags().StringVar(&controllerName, "controller-name", kube.DefaultSealedSecretsController, "name of the Sealed Secrets controller service")
+	cmd.Flags().StringVar(&certPath, "cert", "", "PEM file of the controller certificate, instead of fetching it")
+	cmd.Flags().StringVar(&scope, "scope", kube.SealStrict, "where the secret can be unsealed: strict, namespace-wide, or cluster-wide")
+	cmd.Flags().StringVarP(&out, "out", "o", "", "file to write the SealedSecret manifest to (default stdout)")
+	return cmd
+}
+
+// sealingKey reads the public key of the Sealed Secrets controller from a
+// certificate file, or fetches it from the controller if the path is empty.
+func sealingKey(clientset kube.Client, certPath, namespace, controller string) (*rsa.PublicKey, error) {
+	if certPath == "" {
+		return kube.FetchSealingKey(clientset, namespace, controller)
+	}
+	content, err := os.ReadFile(certPath) //nolint:gosec // The path is chosen by the user.
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	return kube.ParseSealingKey(content)
+}
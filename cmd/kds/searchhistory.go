@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// searchHistoryConfig configures the searches the TUI remembers across runs.
+type searchHistoryConfig struct {
+	// Disabled forgets the searches when the TUI quits.
+	Disabled bool `json:"disabled,omitempty"`
+	// Dir holds the encrypted searches (default: kds/searches in the user cache directory).
+	Dir string `json:"dir,omitempty"`
+	// Limit is how many searches are remembered per namespace (default: 50).
+	Limit int `json:"limit,omitempty"`
+}
+
+// openSearchHistory opens the searches of the selected context, or returns nil if
+// they are not remembered across runs. They are encrypted with the key of the list
+// cache.
+func (o *rootOptions) openSearchHistory() (*kube.SearchHistory, error) {
+	config, err := loadConfig(o.configPath)
+	if err != nil {
+		return nil, err
+	}
+	if config.SearchHistory.Disabled {
+		return nil, nil
+	}
+	if config.SearchHistory.Limit < 0 {
+		return nil, fmt.Errorf("invalid search history limit %d: use a positive number", config.SearchHistory.Limit)
+	}
+	limit := kube.DefaultSearchLimit
+	if config.SearchHistory.Limit > 0 {
+		limit = config.SearchHistory.Limit
+	}
+	dir := config.SearchHistory.Dir
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the search history directory: %w", err)
+		}
+		dir = filepath.Join(base, "kds", "searches")
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the search history key: %w", err)
+	}
+	contextName, err := o.contextName()
+	if err != nil {
+		return nil, err
+	}
+	return kube.OpenSearchHistory(dir, filepath.Join(configDir, "kds", "cache.key"), contextName, limit)
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/diskmanti/kds"
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/sahilm/fuzzy"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// defaultServeAddress keeps the web UI on the loopback interface unless asked otherwise.
+const defaultServeAddress = "127.0.0.1:8484"
+
+// serveTokenEnv holds the token of 'kds serve' when no token file is given.
+const serveTokenEnv = "KDS_SERVE_TOKEN"
+
+// serveTokenCookie keeps the token in the browser once the URL printed at startup
+// is opened.
+const serveTokenCookie = "kds_token"
+
+// newServeCmd creates the 'kds serve' command, which presents the secrets of a
+// namespace in the browser, read-only.
+func newServeCmd(opts *rootOptions) *cobra.Command {
+	var listen, tokenFile string
+	var allowReveal bool
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Browse secrets read-only in a web browser",
+		Long: `Run a local web server that lists, searches, and shows the secrets of the
+namespace, like the TUI does. It never changes anything in the cluster, and
+values are masked unless --allow-reveal is set. Allowing it in a protected
+namespace asks for confirmation first, and the keys matched by redaction rules
+stay masked.
+
+The server listens on the loopback interface by default, and only answers the
+browsers that opened the URL printed at startup, which holds a token: anyone who
+has it reads secrets with your credentials. The token is read from
+--auth-token-file or ` + serveTokenEnv + `, or generated. Scripts may send it in
+an 'Authorization: Bearer <token>' header instead.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			token, _, err := loadToken(tokenFile, serveTokenEnv)
+			if err != nil {
+				return err
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			var redaction kube.RedactionPolicy
+			if allowReveal {
+				if err := opts.confirmProtectedNamespace(cmd, namespace); err != nil {
+					return err
+				}
+				if redaction, err = opts.loadRedactionPolicy(); err != nil {
+					return err
+				}
+			}
+			listener, err := net.Listen("tcp", listen)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", listen, err)
+			}
+			server := &http.Server{
+				Handler:           newServeHandler(kds.NewBrowser(clientset), namespace, token, allowReveal, redaction),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				_ = server.Shutdown(context.Background())
+			}()
+			cmd.PrintErrf("Serving secrets of namespace '%s' on http://%s/?token=%s (Ctrl+C to stop)\n", namespace, listener.Addr(), token)
+			if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&listen, "listen", defaultServeAddress, "address to listen on")
+	cmd.Flags().StringVar(&tokenFile, "auth-token-file", "", "file holding the token browsers must open the server with (default: $"+serveTokenEnv+", or a generated one)")
+	cmd.Flags().BoolVar(&allowReveal, "allow-reveal", false, "allow revealing values in the browser")
+	return cmd
+}
+
+// serveHandler serves the pages of 'kds serve'. It only ever reads secrets.
+type serveHandler struct {
+	browser     *kds.Browser
+	namespace   string
+	allowReveal bool
+	redaction   kube.RedactionPolicy
+}
+
+// newServeHandler routes the list and detail pages behind token authentication.
+// Any method but GET is refused.
+func newServeHandler(browser *kds.Browser, namespace, token string, allowReveal bool, redaction kube.RedactionPolicy) http.Handler {
+	h := &serveHandler{browser: browser, namespace: namespace, allowReveal: allowReveal, redaction: redaction}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", h.list)
+	mux.HandleFunc("GET /secrets/{name}", h.detail)
+	return requireServeToken(token, mux)
+}
+
+// requireServeToken lets through the requests that carry the token: in the token
+// parameter of the URL printed at startup, which is then kept in a cookie and
+// removed from the URL, in that cookie, or in a bearer header. Pages of other
+// sites, including those rebinding their DNS name to the loopback interface, have
+// neither.
+func requireServeToken(token string, next http.Handler) http.Handler {
+	valid := func(given string) bool {
+		return given != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if query := r.URL.Query(); valid(query.Get("token")) {
+			http.SetCookie(w, &http.Cookie{Name: serveTokenCookie, Value: token, Path: "/", HttpOnly: true, SameSite: http.SameSiteStrictMode})
+			query.Del("token")
+			target := *r.URL
+			target.RawQuery = query.Encode()
+			http.Redirect(w, r, target.RequestURI(), http.StatusSeeOther)
+			return
+		}
+		if cookie, err := r.Cookie(serveTokenCookie); err == nil && valid(cookie.Value) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && valid(given) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		http.Error(w, "Open the URL printed by 'kds serve', which holds the token.", http.StatusUnauthorized)
+	})
+}
+
+// servedValue is a secret value as shown on the detail page.
+type servedValue struct {
+	Key     string
+	Format  kds.Format
+	Value   string
+	Summary string
+}
+
+// list renders the secrets of the namespace, fuzzy-filtered by the q parameter.
+func (h *serveHandler) list(w http.ResponseWriter, r *http.Request) {
+	secrets, err := h.browser.List(r.Context(), kds.ListOptions{Namespace: h.namespace})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query != "" {
+		names := make([]string, len(secrets))
+		for i, s := range secrets {
+			names[i] = s.Name
+		}
+		matches := fuzzy.Find(query, names)
+		filtered := make([]kds.Metadata, 0, len(matches))
+		for _, match := range matches {
+			filtered = append(filtered, secrets[match.Index])
+		}
+		secrets = filtered
+	}
+	renderPage(w, serveListTemplate, struct {
+		Namespace string
+		Query     string
+		Secrets   []kds.Metadata
+	}{h.namespace, query, secrets})
+}
+
+// detail renders one secret, masking its values unless revealing is allowed and
+// asked for. The keys matched by redaction rules stay masked, since nobody can
+// confirm them in the browser.
+func (h *serveHandler) detail(w http.ResponseWriter, r *http.Request) {
+	secret, err := h.browser.Get(r.Context(), h.namespace, r.PathValue("name"))
+	if k8serrors.IsNotFound(err) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	reveal := h.allowReveal && r.URL.Query().Get("reveal") == "true"
+	values := make([]servedValue, 0, len(secret.Values))
+	for _, v := range secret.Values {
+		value := kube.MaskValue(v.Data)
+		if reveal && v.Format != kds.FormatBinary && h.redaction.ModeOf(v.Key) == kube.RedactNone {
+			value = string(v.Data)
+		}
+		values = append(values, servedValue{Key: v.Key, Format: v.Format, Value: value, Summary: summarizeValue(v)})
+	}
+	renderPage(w, serveDetailTemplate, struct {
+		Secret      *kds.Secret
+		Values      []servedValue
+		Annotations map[string]string
+		AllowReveal bool
+		Revealed    bool
+	}{secret, values, kube.WithoutSensitiveAnnotations(secret.Annotations), h.allowReveal, reveal})
+}
+
+// summarizeValue describes a value of a detected format without revealing it.
+func summarizeValue(v kds.Value) string {
+	switch v.Format {
+	case kds.FormatCertificate:
+		leaf := v.Certificates[0]
+		return fmt.Sprintf("%s, expires %s", leaf.Subject, leaf.NotAfter.UTC().Format(time.RFC3339))
+	case kds.FormatPrivateKey:
+		return v.PrivateKey.String()
+	case kds.FormatKubeconfig:
+		return fmt.Sprintf("kubeconfig with %d cluster(s), current context %q", len(v.Kubeconfig.Clusters), v.Kubeconfig.CurrentContext)
+	case kds.FormatServiceAccountToken:
+		summary := "service account " + v.Token.Namespace + "/" + v.Token.ServiceAccount
+		if v.Token.ExpiresAt.IsZero() {
+			return summary + ", never expires"
+		}
+		return summary + ", expires " + v.Token.ExpiresAt.UTC().Format(time.RFC3339)
+	case kds.FormatTOTP:
+		return "TOTP seed"
+	}
+	return ""
+}
+
+// renderPage executes a page template, reporting failures as server errors.
+func renderPage(w http.ResponseWriter, tmpl *template.Template, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// servePageStyle is shared by the pages of 'kds serve'.
+const servePageStyle = `<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; vertical-align: top; }
+code, pre { font-family: monospace; margin: 0; white-space: pre-wrap; }
+.note { color: #888; }
+</style>`
+
+// serveListTemplate lists the secrets of the namespace with a search box.
+var serveListTemplate = template.Must(template.New("list").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Secrets in {{.Namespace}}</title>
+` + servePageStyle + `
+</head>
+<body>
+<h1>Secrets in namespace <code>{{.Namespace}}</code></h1>
+<form><input name="q" value="{{.Query}}" placeholder="Search for a secret..." autofocus></form>
+<table>
+<tr><th>Name</th><th>Type</th><th>Keys</th><th>Created</th></tr>
+{{range .Secrets}}<tr><td><a href="/secrets/{{.Name}}"><code>{{.Name}}</code></a>{{if .Immutable}} <span class="note">(immutable)</span>{{end}}</td><td><code>{{.Type}}</code></td><td>{{len .Keys}}</td><td>{{.Created.UTC.Format "2006-01-02 15:04"}}</td></tr>
+{{else}}<tr><td colspan="4" class="note">No secrets found.</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// serveDetailTemplate shows the metadata and values of one secret.
+var serveDetailTemplate = template.Must(template.New("detail").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Secret.Namespace}}/{{.Secret.Name}}</title>
+` + servePageStyle + `
+</head>
+<body>
+<p><a href="/">&larr; All secrets</a></p>
+<h1><code>{{.Secret.Namespace}}/{{.Secret.Name}}</code></h1>
+<p>Type: <code>{{.Secret.Type}}</code> · Created: {{.Secret.Created.UTC.Format "2006-01-02 15:04:05"}}{{if .Secret.Immutable}} · immutable{{end}}</p>
+{{if .AllowReveal}}<p>{{if .Revealed}}<a href="?">Mask values</a>{{else}}<a href="?reveal=true">Reveal values</a>{{end}}</p>{{end}}
+<table>
+<tr><th>Key</th><th>Value</th><th>Format</th></tr>
+{{range .Values}}<tr><td><code>{{.Key}}</code></td><td><pre>{{.Value}}</pre>{{if .Summary}}<div class="note">{{.Summary}}</div>{{end}}</td><td>{{.Format}}</td></tr>
+{{end}}</table>
+{{with .Secret.Labels}}<h2>Labels</h2>
+<table>{{range $k, $v := .}}<tr><td><code>{{$k}}</code></td><td><code>{{$v}}</code></td></tr>{{end}}</table>{{end}}
+{{with .Annotations}}<h2>Annotations</h2>
+<table>{{range $k, $v := .}}<tr><td><code>{{$k}}</code></td><td><code>{{$v}}</code></td></tr>{{end}}</table>{{end}}
+</body>
+</html>
+`))
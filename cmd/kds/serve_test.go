@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestServeHandler verifies that the web UI lists and searches secrets, masks values
+// unless revealing is allowed, and refuses anything but reads.
+func TestServeHandler(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "default", Annotations: map[string]string{
+				"team":                             "payments",
+				corev1.LastAppliedConfigAnnotation: `{"data":{"password":"aHVudGVyMg=="}}`,
+				kube.PreviousValueAnnotationPrefix + "password": "b2xkLWh1bnRlcjI=",
+			}},
+			Data: map[string][]byte{"password": []byte("hunter2")},
+		},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api-key", Namespace: "default"}},
+	)
+	get := func(t *testing.T, handler http.Handler, method, target string) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, nil)
+		req.AddCookie(&http.Cookie{Name: serveTokenCookie, Value: "t0k3n"})
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("should only answer with the token", func(t *testing.T) {
+		handler := newServeHandler(kds.NewBrowser(clientset), "default", "t0k3n", false, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusUnauthorized || strings.Contains(rec.Body.String(), "db-credentials") {
+			t.Errorf("Expected status 401 without the token, but got %d", rec.Code)
+		}
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?token=wrong", nil))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 with a wrong token, but got %d", rec.Code)
+		}
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?q=db&token=t0k3n", nil))
+		cookies := rec.Result().Cookies()
+		if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/?q=db" || len(cookies) != 1 || cookies[0].Value != "t0k3n" || !cookies[0].HttpOnly {
+			t.Errorf("Expected the token to be kept in a cookie and removed from the URL, but got %d to %s with %v", rec.Code, rec.Header().Get("Location"), cookies)
+		}
+	})
+
+	t.Run("should hide the annotations holding values", func(t *testing.T) {
+		handler := newServeHandler(kds.NewBrowser(clientset), "default", "t0k3n", true, nil)
+		body := get(t, handler, http.MethodGet, "/secrets/db-credentials").Body.String()
+		if !strings.Contains(body, "payments") || strings.Contains(body, "aHVudGVyMg==") || strings.Contains(body, "b2xkLWh1bnRlcjI=") {
+			t.Errorf("Expected only the team annotation, but got:\n%s", body)
+		}
+	})
+
+	t.Run("should list and search secrets", func(t *testing.T) {
+		handler := newServeHandler(kds.NewBrowser(clientset), "default", "t0k3n", false, nil)
+		if body := get(t, handler, http.MethodGet, "/").Body.String(); !strings.Contains(body, "db-credentials") || !strings.Contains(body, "api-key") {
+			t.Errorf("Expected both secrets to be listed, but got:\n%s", body)
+		}
+		body := get(t, handler, http.MethodGet, "/?q=dbc").Body.String()
+		if !strings.Contains(body, "db-credentials") || strings.Contains(body, "api-key") {
+			t.Errorf("Expected only db-credentials to match, but got:\n%s", body)
+		}
+	})
+
+	t.Run("should mask values unless revealing is allowed", func(t *testing.T) {
+		masked := newServeHandler(kds.NewBrowser(clientset), "default", "t0k3n", false, nil)
+		if body := get(t, masked, http.MethodGet, "/secrets/db-credentials?reveal=true").Body.String(); strings.Contains(body, "hunter2") || !strings.Contains(body, "******** (7 bytes)") {
+			t.Errorf("Expected the value to stay masked, but got:\n%s", body)
+		}
+		revealing := newServeHandler(kds.NewBrowser(clientset), "default", "t0k3n", true, nil)
+		if body := get(t, revealing, http.MethodGet, "/secrets/db-credentials").Body.String(); strings.Contains(body, "hunter2") {
+			t.Errorf("Expected the value to be masked by default, but got:\n%s", body)
+		}
+		if body := get(t, revealing, http.MethodGet, "/secrets/db-credentials?reveal=true").Body.String(); !strings.Contains(body, "hunter2") {
+			t.Errorf("Expected the value to be revealed, but got:\n%s", body)
+		}
+		redaction, err := kube.CompileRedactionPolicy([]kube.RedactionRuleConfig{{Keys: "^password$", Mode: kube.RedactConfirm}})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		redacted := newServeHandler(kds.NewBrowser(clientset), "default", "t0k3n", true, redaction)
+		if body := get(t, redacted, http.MethodGet, "/secrets/db-credentials?reveal=true").Body.String(); strings.Contains(body, "hunter2") {
+			t.Errorf("Expected the redacted value to stay masked, but got:\n%s", body)
+		}
+	})
+
+	t.Run("should answer 404 for missing secrets and 405 for writes", func(t *testing.T) {
+		handler := newServeHandler(kds.NewBrowser(clientset), "default", "t0k3n", true, nil)
+		if code := get(t, handler, http.MethodGet, "/secrets/missing").Code; code != http.StatusNotFound {
+			t.Errorf("Expected status 404, but got %d", code)
+		}
+		if code := get(t, handler, http.MethodPost, "/secrets/db-credentials").Code; code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, but got %d", code)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTopCmd creates the 'kds top' command.
+func newTopCmd(opts *rootOptions) *cobra.Command {
+	var allNamespaces bool
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "Rank the biggest secrets per namespace",
+		Long: `Rank the biggest secrets per namespace by the size of their keys and values.
+
+Secrets larger than 1 MiB are rejected by the API server, and big secrets slow down
+etcd and every client that lists them. Secrets above 80% of the limit are flagged.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace := metav1.NamespaceAll
+			if !allNamespaces {
+				if namespace, err = opts.resolveNamespace(); err != nil {
+					return err
+				}
+			}
+			secrets, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to list secrets: %w", err)
+			}
+			return printTopSecrets(cmd.OutOrStdout(), kube.RankBySize(secrets.Items, limit))
+		},
+	}
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "rank the secrets of every namespace")
+	cmd.Flags().IntVar(&limit, "limit", 10, "number of secrets to show per namespace (0 for all)")
+	return cmd
+}
+
+// printTopSecrets writes the ranked secrets as a table.
+func printTopSecrets(w io.Writer, secrets []*corev1.Secret) error {
+	var b strings.Builder
+	b.WriteString("NAMESPACE\tNAME\tKEYS\tSIZE\tLIMIT\n")
+	for _, secret := range secrets {
+		size := kube.SecretSize(secret)
+		fmt.Fprintf(&b, "%s\t%s\t%d\t%s\t%d%%\t%s\n", secret.Namespace, secret.Name, len(secret.Data), kube.FormatSize(size), size*100/kube.SecretSizeLimit, kube.SizeWarning(size))
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if _, err := io.WriteString(tw, b.String()); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestRankBySize verifies ranking by size and the per-namespace limit.
+func TestRankBySize(t *testing.T) {
+	sized := func(namespace, name string, size int) corev1.Secret {
+		return corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Data:       map[string][]byte{"k": make([]byte, size)},
+		}
+	}
+	secrets := []corev1.Secret{
+		sized("b", "small", 10),
+		sized("a", "medium", 100),
+		sized("a", "big", 1000),
+		sized("a", "tiny", 1),
+		sized("b", "large", 900),
+	}
+
+	t.Run("should keep the biggest secrets of each namespace", func(t *testing.T) {
+		var names []string
+		for _, secret := range kube.RankBySize(secrets, 2) {
+			names = append(names, secret.Namespace+"/"+secret.Name)
+		}
+		expected := "a/big a/medium b/large b/small"
+		if got := strings.Join(names, " "); got != expected {
+			t.Errorf("Expected '%s', but got '%s'", expected, got)
+		}
+	})
+
+	t.Run("should keep every secret without a limit", func(t *testing.T) {
+		if got := len(kube.RankBySize(secrets, 0)); got != len(secrets) {
+			t.Errorf("Expected %d secrets, but got %d", len(secrets), got)
+		}
+	})
+
+	t.Run("should flag secrets close to the limit", func(t *testing.T) {
+		huge := sized("a", "huge", kube.SecretSizeLimit-100)
+		var out bytes.Buffer
+		if err := printTopSecrets(&out, []*corev1.Secret{&huge}); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if !strings.Contains(out.String(), "⚠") || !strings.Contains(out.String(), "99%") {
+			t.Errorf("Expected a size warning, but got:\n%s", out.String())
+		}
+	})
+}
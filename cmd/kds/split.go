@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newSplitCmd creates the 'kds split' command.
+func newSplitCmd(opts *rootOptions) *cobra.Command {
+	var into, dryRun string
+	var keys []string
+	var patchWorkloads, yes bool
+
+	cmd := &cobra.Command{
+		Use:   "split <secret-name> --into <secret-name>",
+		Short: "Move some keys of a secret into a new secret",
+		Long: `Move some keys of a secret into a new Opaque secret, to untangle monolithic shared
+secrets. Without --keys, kds lists the keys and asks which to move.
+
+The workloads that read moved keys need to point at the new secret: env references
+to moved keys are repointed, and envFrom and projected volumes of the whole secret
+get a source for the new secret next to them. kds prints the kubectl commands that
+patch them, or applies the patches with --patch-workloads, which rolls them out.
+References it cannot repoint, such as volumes mounting the whole secret, are listed
+for a manual change.
+
+The new secret is created, and the workloads patched, before the keys are removed
+from the original secret.`,
+		Example: `  # Pick the keys to move interactively
+  kds split app-secrets --into payments-secrets
+
+  # Move two keys and patch the workloads
+  kds split app-secrets --into payments-secrets --keys stripe-key,stripe-webhook --patch-workloads`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if into == "" {
+				return errors.New("--into is required")
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			if len(keys) == 0 {
+				secret, err := kube.GetSecret(clientset, ref)
+				if err != nil {
+					return err
+				}
+				if keys, err = askKeysToMove(cmd, kube.SortedKeys(secret.Data)); err != nil {
+					return err
+				}
+			}
+			create, update, err := kube.PrepareSplit(clientset, ref, keys, into)
+			if err != nil {
+				return err
+			}
+			patches, manual, err := kube.SplitPatches(clientset, ref, keys, into)
+			if err != nil {
+				return err
+			}
+			if applied, err := runMutation(cmd, clientset, create, dryRun, !yes); err != nil || !applied {
+				return err
+			}
+			if patchWorkloads {
+				if err := kube.ApplyWorkloadPatches(clientset, namespace, patches); err != nil {
+					return err
+				}
+				for _, p := range patches {
+					cmd.PrintErrf("Patched %s to read secret '%s'\n", p.Workload, into)
+				}
+			} else if err := printWorkloadPatches(cmd.OutOrStdout(), namespace, patches); err != nil {
+				return err
+			}
+			for _, note := range manual {
+				cmd.PrintErrf("Warning: change by hand: %s\n", note)
+			}
+			if _, err := runMutation(cmd, clientset, update, kube.DryRunNone, false); err != nil {
+				return err
+			}
+			cmd.PrintErrf("Moved %s from secret '%s' to secret '%s'\n", strings.Join(keys, ", "), ref, into)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&into, "into", "", "name of the new secret")
+	cmd.Flags().StringSliceVar(&keys, "keys", nil, "keys to move, comma-separated (default: ask)")
+	cmd.Flags().BoolVar(&patchWorkloads, "patch-workloads", false, "patch the workloads instead of printing the kubectl commands that do")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+	addMutationFlags(cmd, &dryRun)
+	return cmd
+}
+
+// askKeysToMove lists the keys of a secret and asks which to move, by number or
+// by name.
+func askKeysToMove(cmd *cobra.Command, keys []string) ([]string, error) {
+	for i, key := range keys {
+		cmd.PrintErrf("  %d) %s\n", i+1, key)
+	}
+	answer, err := askValue(cmd, "Keys to move (numbers or names, comma-separated)", false)
+	if err != nil {
+		return nil, err
+	}
+	var selected []string
+	for _, field := range strings.Split(answer, ",") {
+		field = strings.TrimSpace(field)
+		if n, err := strconv.Atoi(field); err == nil && n >= 1 && n <= len(keys) {
+			field = keys[n-1]
+		}
+		if field != "" {
+			selected = append(selected, field)
+		}
+	}
+	return selected, nil
+}
+
+// printWorkloadPatches writes one kubectl command per patch.
+func printWorkloadPatches(w io.Writer, namespace string, patches []kube.WorkloadPatch) error {
+	var b strings.Builder
+	for _, p := range patches {
+		fmt.Fprintf(&b, "kubectl patch %s -n %s --type strategic -p %s\n", strings.ToLower(p.Workload.String()), namespace, kube.ShellQuote(string(p.Patch)))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
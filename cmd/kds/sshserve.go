@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/activeterm"
+	bm "github.com/charmbracelet/wish/bubbletea"
+	"github.com/charmbracelet/wish/logging"
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/diskmanti/kds/pkg/ui"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// defaultSSHAddress is where 'kds serve-ssh' listens unless told otherwise.
+const defaultSSHAddress = ":2222"
+
+// sshUserConfig maps a user of 'kds serve-ssh' to the Kubernetes identity their
+// TUI runs as.
+type sshUserConfig struct {
+	Name string `json:"name"`
+	// AuthorizedKeys are the public keys the user logs in with, one per entry in
+	// the authorized_keys format.
+	AuthorizedKeys []string `json:"authorizedKeys"`
+	// Kubeconfig and Context select the credentials; they default to those kds serve-ssh runs with.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	Context    string `json:"context,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	// Impersonate and ImpersonateGroups make every API request on behalf of
+	// another identity, so RBAC applies per user even with shared credentials.
+	Impersonate       string   `json:"impersonate,omitempty"`
+	ImpersonateGroups []string `json:"impersonateGroups,omitempty"`
+}
+
+// sshUser is a compiled sshUserConfig.
+type sshUser struct {
+	config sshUserConfig
+	keys   []ssh.PublicKey
+}
+
+// compileSSHUsers parses the authorized keys of the configured users, keyed by name.
+func compileSSHUsers(configs []sshUserConfig) (map[string]*sshUser, error) {
+	users := make(map[string]*sshUser, len(configs))
+	for _, c := range configs {
+		if c.Name == "" || len(c.AuthorizedKeys) == 0 {
+			return nil, fmt.Errorf("ssh user '%s' needs a name and at least one authorized key", c.Name)
+		}
+		if _, ok := users[c.Name]; ok {
+			return nil, fmt.Errorf("ssh user '%s' is configured twice", c.Name)
+		}
+		user := &sshUser{config: c}
+		for _, line := range c.AuthorizedKeys {
+			key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+			if err != nil {
+				return nil, fmt.Errorf("ssh user '%s' has an invalid authorized key: %w", c.Name, err)
+			}
+			user.keys = append(user.keys, key)
+		}
+		users[c.Name] = user
+	}
+	return users, nil
+}
+
+// authorized reports whether key is one of the user's authorized keys.
+func (u *sshUser) authorized(key ssh.PublicKey) bool {
+	for _, k := range u.keys {
+		if ssh.KeysEqual(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientConfig returns the client configuration of the user: the server's, with
+// the user's kubeconfig, context, and namespace applied.
+func (u *sshUser) clientConfig(opts *rootOptions) clientcmd.ClientConfig {
+	return u.options(opts).clientConfig()
+}
+
+// options returns the connection options of the server, with the kubeconfig,
+// context, and namespace of the user.
+func (u *sshUser) options(opts *rootOptions) *rootOptions {
+	other := opts.withConnection()
+	flags := other.flags()
+	if u.config.Kubeconfig != "" {
+		*flags.KubeConfig = u.config.Kubeconfig
+	}
+	if u.config.Context != "" {
+		*flags.Context = u.config.Context
+		*flags.Namespace = ""
+	}
+	if u.config.Namespace != "" {
+		*flags.Namespace = u.config.Namespace
+	}
+	return other
+}
+
+// restConfig builds the REST configuration of the user, impersonating the
+// configured identity if any.
+func (u *sshUser) restConfig(opts *rootOptions) (*rest.Config, error) {
+	restConfig, err := u.clientConfig(opts).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kubeconfig for ssh user '%s': %w", u.config.Name, err)
+	}
+	if err := u.options(opts).enforceContextPolicy(restConfig); err != nil {
+		return nil, fmt.Errorf("ssh user '%s': %w", u.config.Name, err)
+	}
+	logAPICalls(restConfig)
+	if u.config.Impersonate != "" || len(u.config.ImpersonateGroups) > 0 {
+		restConfig.Impersonate = rest.ImpersonationConfig{UserName: u.config.Impersonate, Groups: u.config.ImpersonateGroups}
+	}
+	return restConfig, nil
+}
+
+// newModel creates the TUI of a session, connected as the user.
+func (u *sshUser) newModel(opts *rootOptions, uiOpts ui.Options) (tea.Model, error) {
+	restConfig, err := u.restConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	namespace, _, err := u.clientConfig(opts).Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace for ssh user '%s': %w", u.config.Name, err)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes clientset: %w", err)
+	}
+	if uiOpts.Dynamic, err = dynamic.NewForConfig(restConfig); err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	if uiOpts.Trash, err = u.options(opts).openTrash(); err != nil {
+		return nil, err
+	}
+	return ui.NewModel(clientset, namespace, uiOpts), nil
+}
+
+// newServeSSHCmd creates the 'kds serve-ssh' command, which hosts the TUI on an
+// SSH port for the users of the config file.
+func newServeSSHCmd(opts *rootOptions) *cobra.Command {
+	var listen, hostKey string
+
+	cmd := &cobra.Command{
+		Use:   "serve-ssh",
+		Short: "Host the TUI on an SSH port, as a shared secrets bastion",
+		Long: `Run an SSH server that opens the TUI for every user who logs in. Users are
+declared under sshUsers in the config file, each with their authorized keys and
+the Kubernetes identity their session runs as: a kubeconfig, a context, a
+namespace, and an identity to impersonate.
+
+  sshUsers:
+    - name: alice
+      authorizedKeys: ["ssh-ed25519 AAAAC3Nza... alice@laptop"]
+      impersonate: alice@example.com
+      impersonateGroups: [developers]
+
+Users then connect with 'ssh -p 2222 alice@bastion'. Custom actions, the editor,
+and the actions that read or write files or the clipboard are disabled, since
+they would run on the server.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			config, err := loadConfig(opts.configPath)
+			if err != nil {
+				return err
+			}
+			users, err := compileSSHUsers(config.SSHUsers)
+			if err != nil {
+				return err
+			}
+			if len(users) == 0 {
+				return errors.New("no ssh users configured: declare them under sshUsers in the config file")
+			}
+			uiOpts := ui.Options{Remote: true}
+			if uiOpts.SecretKeys, err = kube.CompileSecretKeys(opts.secretKeys); err != nil {
+				return err
+			}
+			if uiOpts.Decoders, err = kube.CompileDecoders(config.Decoders); err != nil {
+				return err
+			}
+			if uiOpts.ProtectedNamespaces, err = kube.CompileProtectedNamespaces(config.ProtectedNamespaces); err != nil {
+				return err
+			}
+			if uiOpts.Redaction, err = kube.CompileRedactionPolicy(config.Redaction); err != nil {
+				return err
+			}
+			uiOpts.Icons, uiOpts.TypeColors = config.Icons, config.TypeColors
+			if uiOpts.KeepPrevious, err = config.Rotation.keepPrevious(); err != nil {
+				return fmt.Errorf("invalid config: %w", err)
+			}
+			if hostKey == "" {
+				if hostKey, err = defaultHostKeyPath(); err != nil {
+					return err
+				}
+			}
+
+			server, err := wish.NewServer(
+				wish.WithAddress(listen),
+				wish.WithHostKeyPath(hostKey),
+				wish.WithPublicKeyAuth(func(ctx ssh.Context, key ssh.PublicKey) bool {
+					user, ok := users[ctx.User()]
+					return ok && user.authorized(key)
+				}),
+				wish.WithMiddleware(
+					bm.Middleware(func(sess ssh.Session) (tea.Model, []tea.ProgramOption) {
+						m, err := users[sess.User()].newModel(opts, uiOpts)
+						if err != nil {
+							wish.Fatalln(sess, err)
+							return nil, nil
+						}
+						return m, []tea.ProgramOption{tea.WithAltScreen()}
+					}),
+					activeterm.Middleware(),
+					logging.Middleware(),
+				),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create ssh server: %w", err)
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				_ = server.Shutdown(context.Background())
+			}()
+			cmd.PrintErrf("Serving the TUI to %d user(s) on %s (Ctrl+C to stop)\n", len(users), listen)
+			if err := server.ListenAndServe(); !errors.Is(err, ssh.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&listen, "listen", defaultSSHAddress, "address to listen on")
+	cmd.Flags().StringVar(&hostKey, "host-key", "", "path to the SSH host key, created if missing (default: kds/ssh_host_ed25519 in the user config directory)")
+	return cmd
+}
+
+// defaultHostKeyPath returns where the host key of 'kds serve-ssh' is kept.
+func defaultHostKeyPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "kds", "ssh_host_ed25519"), nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// TestSSHUsers verifies that users log in with their own keys only and that
+// their sessions run as the configured identity.
+func TestSSHUsers(t *testing.T) {
+	alice, bob := newTestAuthorizedKey(t), newTestAuthorizedKey(t)
+
+	t.Run("should authorize users with their own keys only", func(t *testing.T) {
+		users, err := compileSSHUsers([]sshUserConfig{
+			{Name: "alice", AuthorizedKeys: []string{alice}},
+			{Name: "bob", AuthorizedKeys: []string{bob}},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		aliceKey, _, _, _, _ := ssh.ParseAuthorizedKey([]byte(alice))
+		if !users["alice"].authorized(aliceKey) {
+			t.Error("Expected alice to be authorized with their key")
+		}
+		if users["bob"].authorized(aliceKey) {
+			t.Error("Expected bob not to be authorized with alice's key")
+		}
+	})
+
+	t.Run("should reject invalid users", func(t *testing.T) {
+		for _, configs := range [][]sshUserConfig{
+			{{Name: "alice"}},
+			{{Name: "alice", AuthorizedKeys: []string{"not a key"}}},
+			{{Name: "alice", AuthorizedKeys: []string{alice}}, {Name: "alice", AuthorizedKeys: []string{bob}}},
+		} {
+			if _, err := compileSSHUsers(configs); err == nil {
+				t.Errorf("Expected an error for %+v, but got none", configs)
+			}
+		}
+	})
+
+	t.Run("should apply the user's context, namespace, and impersonation", func(t *testing.T) {
+		kubeconfig := filepath.Join(t.TempDir(), "config")
+		if err := os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster: {server: "https://dev:6443"}
+- name: prod
+  cluster: {server: "https://prod:6443"}
+contexts:
+- name: dev
+  context: {cluster: dev, user: admin}
+- name: prod
+  context: {cluster: prod, user: admin, namespace: web}
+users:
+- name: admin
+  user: {token: secret}
+`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		user := &sshUser{config: sshUserConfig{Name: "alice", Kubeconfig: kubeconfig, Context: "prod", Impersonate: "alice@example.com", ImpersonateGroups: []string{"developers"}}}
+		restConfig, err := user.restConfig(&rootOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if restConfig.Host != "https://prod:6443" {
+			t.Errorf("Expected the prod cluster, but got %s", restConfig.Host)
+		}
+		if restConfig.Impersonate.UserName != "alice@example.com" || len(restConfig.Impersonate.Groups) != 1 {
+			t.Errorf("Expected to impersonate alice@example.com in developers, but got %+v", restConfig.Impersonate)
+		}
+		if ns, _, _ := user.clientConfig(&rootOptions{}).Namespace(); ns != "web" {
+			t.Errorf("Expected namespace web from the context, but got %s", ns)
+		}
+		user.config.Namespace = "api"
+		if ns, _, _ := user.clientConfig(&rootOptions{}).Namespace(); ns != "api" {
+			t.Errorf("Expected namespace api from the user, but got %s", ns)
+		}
+	})
+}
+
+// newTestAuthorizedKey generates an ed25519 public key in the authorized_keys format.
+func newTestAuthorizedKey(t *testing.T) string {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := gossh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(gossh.MarshalAuthorizedKey(key))
+}
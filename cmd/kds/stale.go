@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Output formats of the 'kds stale' command.
+const (
+	staleOutputTable = "table"
+	staleOutputJSON  = "json"
+	staleOutputCSV   = "csv"
+)
+
+// newStaleCmd creates the 'kds stale' command.
+func newStaleCmd(opts *rootOptions) *cobra.Command {
+	staleOpts := &kube.StaleOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "stale",
+		Short: "List secrets that are overdue for rotation",
+		Long: `List secrets that are overdue for rotation.
+
+A secret is overdue when it has not changed for longer than --older-than, or longer
+than the period in its ` + kube.RotationPeriodAnnotation + ` annotation (e.g. "30d").
+The last change is the most recent update recorded in the secret's managed fields,
+or its creation time. Service account tokens and Helm release secrets are skipped.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			threshold, err := kube.ParseAge(staleOpts.OlderThan)
+			if err != nil {
+				return fmt.Errorf("invalid --older-than: %w", err)
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace := metav1.NamespaceAll
+			if !staleOpts.AllNamespaces {
+				if namespace, err = opts.resolveNamespace(); err != nil {
+					return err
+				}
+			}
+			secrets, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to list secrets: %w", err)
+			}
+			stale, err := kube.FindStaleSecrets(secrets.Items, threshold, time.Now())
+			if err != nil {
+				return err
+			}
+			if err := kube.SortStaleSecrets(stale, staleOpts.SortBy); err != nil {
+				return err
+			}
+			return printStaleSecrets(cmd.OutOrStdout(), stale, staleOpts.Output)
+		},
+	}
+	cmd.Flags().StringVar(&staleOpts.OlderThan, "older-than", "90d", "report secrets unchanged for longer than this (e.g. 90d, 720h)")
+	cmd.Flags().StringVar(&staleOpts.SortBy, "sort", "age", "sort by age, name, or namespace")
+	cmd.Flags().StringVarP(&staleOpts.Output, "output", "o", staleOutputTable, "output format: table, json, or csv")
+	cmd.Flags().BoolVarP(&staleOpts.AllNamespaces, "all-namespaces", "A", false, "check the secrets of all namespaces")
+	return cmd
+}
+
+// printStaleSecrets writes the report as a table, JSON, or CSV.
+func printStaleSecrets(w io.Writer, stale []kube.StaleSecret, format string) error {
+	switch format {
+	case staleOutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if stale == nil {
+			stale = []kube.StaleSecret{}
+		}
+		return enc.Encode(stale)
+	case staleOutputCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"namespace", "name", "type", "lastChanged", "ageDays", "rotationPeriod", "reason"}); err != nil {
+			return err
+		}
+		for _, s := range stale {
+			row := []string{s.Namespace, s.Name, string(s.Type), s.LastChanged.Format(time.RFC3339), strconv.Itoa(s.AgeDays), s.RotationPeriod, s.Reason}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case staleOutputTable:
+		var b strings.Builder
+		b.WriteString("NAMESPACE\tNAME\tTYPE\tLAST CHANGED\tREASON\n")
+		for _, s := range stale {
+			fmt.Fprintf(&b, "%s\t%s\t%s\t%s\t%s\n", s.Namespace, s.Name, s.Type, s.LastChanged.Format(time.DateOnly), s.Reason)
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		if _, err := io.WriteString(tw, b.String()); err != nil {
+			return err
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("invalid output format '%s': must be table, json, or csv", format)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestFindStaleSecrets verifies the selection and reporting of secrets due for rotation.
+func TestFindStaleSecrets(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) metav1.Time { return metav1.NewTime(now.AddDate(0, 0, -days)) }
+	recentUpdate := daysAgo(5)
+	secrets := []corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "old", CreationTimestamp: daysAgo(200)}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "fresh", CreationTimestamp: daysAgo(10)}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:              "updated",
+			CreationTimestamp: daysAgo(300),
+			ManagedFields:     []metav1.ManagedFieldsEntry{{Time: &recentUpdate}},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:              "rotated-monthly",
+			CreationTimestamp: daysAgo(40),
+			Annotations:       map[string]string{kube.RotationPeriodAnnotation: "30d"},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "token", CreationTimestamp: daysAgo(500)}, Type: corev1.SecretTypeServiceAccountToken},
+	}
+
+	stale, err := kube.FindStaleSecrets(secrets, 90*24*time.Hour, now)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := kube.SortStaleSecrets(stale, "age"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(stale) != 2 || stale[0].Name != "old" || stale[1].Name != "rotated-monthly" {
+		t.Fatalf("Expected 'old' and 'rotated-monthly', but got %+v", stale)
+	}
+	if stale[1].Reason != "rotation period of 30d exceeded" {
+		t.Errorf("Expected the rotation period to be the reason, but got '%s'", stale[1].Reason)
+	}
+
+	t.Run("should write CSV with a header", func(t *testing.T) {
+		var out strings.Builder
+		if err := printStaleSecrets(&out, stale, staleOutputCSV); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != 3 || !strings.HasPrefix(lines[1], ",old,,2023-11-14T00:00:00Z,200,") {
+			t.Errorf("Expected a header and 2 rows, but got:\n%s", out.String())
+		}
+	})
+
+	t.Run("should write a JSON array", func(t *testing.T) {
+		var out strings.Builder
+		if err := printStaleSecrets(&out, stale, staleOutputJSON); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		var decoded []kube.StaleSecret
+		if err := json.Unmarshal([]byte(out.String()), &decoded); err != nil || len(decoded) != 2 {
+			t.Errorf("Expected 2 entries, but got %v (%v)", decoded, err)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxOrphansShown is how many orphans 'kds stats' names.
+const maxOrphansShown = 5
+
+// newStatsCmd creates the 'kds stats' command.
+func newStatsCmd(opts *rootOptions) *cobra.Command {
+	var expiringWithin, output string
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Summarize the secrets of a namespace",
+		Long: `Summarize the secrets of a namespace on one screen: their number by type, their
+total size, the oldest and newest secrets, the TLS certificates that expire within
+--expiring-within, and the orphans.
+
+Orphans are secrets that no workload, ingress, or service account references, and
+that no other object owns. Service account tokens and Helm release secrets are never
+orphans. If the workloads cannot be listed, orphans are not counted.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			window, err := kube.ParseAge(expiringWithin)
+			if err != nil {
+				return fmt.Errorf("invalid --expiring-within: %w", err)
+			}
+			if output != "text" && output != "json" {
+				return fmt.Errorf("invalid --output '%s': use text or json", output)
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			secrets, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to list secrets in namespace '%s': %w", namespace, err)
+			}
+			usages, err := kube.BuildUsageIndex(clientset, namespace)
+			if err != nil {
+				cmd.PrintErrf("Warning: orphans are not counted: %v\n", err)
+				usages = nil
+			}
+			now := time.Now()
+			stats := kube.BuildNamespaceStats(namespace, secrets.Items, usages, window, now)
+			if output == "json" {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(stats)
+			}
+			return printStats(cmd.OutOrStdout(), stats, expiringWithin, now)
+		},
+	}
+	cmd.Flags().StringVar(&expiringWithin, "expiring-within", "30d", "count the TLS certificates expiring within this period (e.g. 30d, 72h)")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "output format: text or json")
+	return cmd
+}
+
+// printStats writes the summary of a namespace as aligned lines.
+func printStats(w io.Writer, stats kube.NamespaceStats, window string, now time.Time) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Namespace:\t%s\n", stats.Namespace)
+	fmt.Fprintf(&b, "Secrets:\t%d (%s)\n", stats.Secrets, kube.FormatSize(stats.TotalSize))
+	types := make([]corev1.SecretType, 0, len(stats.ByType))
+	for secretType := range stats.ByType {
+		types = append(types, secretType)
+	}
+	slices.SortFunc(types, func(a, b corev1.SecretType) int {
+		if stats.ByType[a] != stats.ByType[b] {
+			return stats.ByType[b] - stats.ByType[a]
+		}
+		return strings.Compare(string(a), string(b))
+	})
+	for _, secretType := range types {
+		fmt.Fprintf(&b, "  %s\t%d\n", secretType, stats.ByType[secretType])
+	}
+	if stats.Oldest != nil {
+		fmt.Fprintf(&b, "Oldest:\t%s (%s)\n", stats.Oldest.Name, age(stats.Oldest.Created, now))
+		fmt.Fprintf(&b, "Newest:\t%s (%s)\n", stats.Newest.Name, age(stats.Newest.Created, now))
+	}
+	fmt.Fprintf(&b, "Certificates:\t%d expiring within %s (%d expired)\n", stats.ExpiringCertificates, window, stats.ExpiredCertificates)
+	switch {
+	case stats.Orphans == nil:
+		b.WriteString("Orphans:\tunknown\n")
+	case len(stats.Orphans) > maxOrphansShown:
+		fmt.Fprintf(&b, "Orphans:\t%d (%s, and %d more)\n", len(stats.Orphans), strings.Join(stats.Orphans[:maxOrphansShown], ", "), len(stats.Orphans)-maxOrphansShown)
+	case len(stats.Orphans) > 0:
+		fmt.Fprintf(&b, "Orphans:\t%d (%s)\n", len(stats.Orphans), strings.Join(stats.Orphans, ", "))
+	default:
+		b.WriteString("Orphans:\t0\n")
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if _, err := io.WriteString(tw, b.String()); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// newPushCmd creates the 'kds push' command.
+func newPushCmd(opts *rootOptions) *cobra.Command {
+	var store, item string
+
+	cmd := &cobra.Command{
+		Use:   "push <secret-name> --to <store>",
+		Short: "Push the keys of a secret to an item of a password manager",
+		Long: `Push the keys of a secret to an item of a password manager or another secret
+store, through its CLI.
+
+With --to 1password, the keys are set as concealed fields of a 1Password item, named
+after the namespace and the secret unless --item is given, with the op CLI, which
+must be installed and signed in. With --to bitwarden, they are set as hidden custom
+fields of a Bitwarden or Vaultwarden item with the bw CLI, whose vault must be
+unlocked with the session in BW_SESSION. An existing item keeps its other fields;
+a missing one is created as a secure note. Other stores are declared in the stores
+section of the config file, with the commands kds runs to push and pull items.
+
+Values are passed to the CLI on stdin, never as arguments.`,
+		Example: `  # Keep a copy of a secret in 1Password
+  kds push db-credentials --to 1password
+
+  # Or in Bitwarden, after 'export BW_SESSION=$(bw unlock --raw)'
+  kds push db-credentials --to bitwarden
+
+  # Push to a store of the config file, under another item
+  kds push db-credentials --to team-vault --item payments/db`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := findStore(opts, store)
+			if err != nil {
+				return err
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			if err := opts.confirmProtected(cmd, "Push", ref); err != nil {
+				return err
+			}
+			secret, err := kube.GetSecret(clientset, ref)
+			if err != nil {
+				return err
+			}
+			if item == "" {
+				item = ref.String()
+			}
+			if err := s.Push(kube.StoreItem{Item: item, Namespace: ref.Namespace, Secret: ref.Name}, secret.Data); err != nil {
+				return err
+			}
+			cmd.PrintErrf("Pushed secret '%s' to item '%s' of %s\n", ref, item, s.Name)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&store, "to", "", "store to push to: 1password, bitwarden, or a store of the config file")
+	cmd.Flags().StringVar(&item, "item", "", "item to push to (default <namespace>/<secret-name>)")
+	return cmd
+}
+
+// newPullCmd creates the 'kds pull' command.
+func newPullCmd(opts *rootOptions) *cobra.Command {
+	var store, secretName, dryRun string
+	var prune, yes bool
+
+	cmd := &cobra.Command{
+		Use:   "pull <item> --from <store>",
+		Short: "Create or update a secret from an item of a password manager",
+		Long: `Create or update a secret from an item of a password manager or another secret
+store, through its CLI.
+
+With --from 1password, the labeled fields of a 1Password item become the keys of
+the secret, with the op CLI, which must be installed and signed in. The notes and
+empty fields of the item are left out. With --from bitwarden, the custom fields of
+a Bitwarden or Vaultwarden item do, with the bw CLI, whose vault must be unlocked
+with the session in BW_SESSION. With --from doppler, the secrets of a Doppler
+config, given as <project>/<config>, do, and with --from infisical, those of an
+Infisical environment, given with the path of a folder as prod/app/db; both are
+read with their CLIs, logged in or given a token, and cannot be pushed to. With
+--from vault, the values of a secret of a Vault KV engine do, given by its API
+path as secret/data/app/prod, and read with VAULT_ADDR and the token of the Vault
+CLI; it cannot be pushed to either. Other stores are declared in the stores
+section of the config file.
+
+The secret is named after the last part of the item unless --secret is given. A
+missing secret is created as Opaque. Otherwise, the keys of the item are added or
+overwrite existing ones, and with --prune, the keys that are not in the item are
+removed; the changes are shown, without values, before asking for confirmation.`,
+		Example: `  # Create db-credentials from a 1Password item
+  kds pull prod/db-credentials --from 1password
+
+  # Or from a Bitwarden item
+  kds pull prod/db-credentials --from bitwarden
+
+  # Create app-config from the prd config of the payments project in Doppler
+  kds pull payments/prd --from doppler --secret app-config
+
+  # Mirror an item into an existing secret
+  kds pull "Payments DB" --from 1password --secret db-credentials --prune`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := findStore(opts, store)
+			if err != nil {
+				return err
+			}
+			if secretName == "" {
+				secretName = path.Base(args[0])
+				if errs := validation.IsDNS1123Subdomain(secretName); len(errs) > 0 {
+					return fmt.Errorf("item '%s' is not a valid secret name: use --secret", args[0])
+				}
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: secretName}
+			data, err := s.Pull(kube.StoreItem{Item: args[0], Namespace: ref.Namespace, Secret: ref.Name})
+			if err != nil {
+				return err
+			}
+			mu, err := kube.PreparePull(clientset, ref, data, prune)
+			if err != nil {
+				return err
+			}
+			if applied, err := runMutation(cmd, clientset, mu, dryRun, !yes && mu.Before != nil); err != nil || !applied {
+				return err
+			}
+			cmd.PrintErrf("Pulled item '%s' of %s into secret '%s'\n", args[0], s.Name, ref)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&store, "from", "", "store to pull from: 1password, bitwarden, doppler, infisical, vault, or a store of the config file")
+	cmd.Flags().StringVar(&secretName, "secret", "", "secret to create or update (default the last part of the item)")
+	cmd.Flags().BoolVar(&prune, "prune", false, "remove keys that are not in the item")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+	addMutationFlags(cmd, &dryRun)
+	return cmd
+}
+
+// findStore returns a store of the config file, or of a kind with its defaults.
+func findStore(opts *rootOptions, name string) (kube.SecretStore, error) {
+	if name == "" {
+		return kube.SecretStore{}, errors.New("a store is required: use 1password, bitwarden, doppler, infisical, vault, or a store of the config file")
+	}
+	config, err := loadConfig(opts.configPath)
+	if err != nil {
+		return kube.SecretStore{}, err
+	}
+	stores, err := kube.CompileStores(config.Stores)
+	if err != nil {
+		return kube.SecretStore{}, fmt.Errorf("invalid config: %w", err)
+	}
+	return kube.FindStore(stores, name)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// newSyncCmd creates the 'kds sync' command, which replicates a secret into other namespaces.
+func newSyncCmd(opts *rootOptions) *cobra.Command {
+	var targets []string
+	var watchSource bool
+
+	cmd := &cobra.Command{
+		Use:   "sync <secret-name>",
+		Short: "Replicate a secret into other namespaces and keep the replicas up to date",
+		Long: fmt.Sprintf(`Replicate a secret into other namespaces.
+
+Replicas are marked with the %s annotation. Secrets that
+already exist in a target namespace without that annotation are never overwritten.
+With --watch, kds keeps running and updates the replicas whenever the source changes,
+which is handy for wildcard TLS certificates needed in many namespaces.`, kube.SyncedFromAnnotation),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(targets) == 0 {
+				return errors.New("at least one target namespace is required (use --to)")
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			ref := kube.SecretRef{Namespace: namespace, Name: args[0]}
+			if err := opts.confirmProtected(cmd, "Copy", ref); err != nil {
+				return err
+			}
+			if !watchSource {
+				source, err := kube.GetSecret(clientset, ref)
+				if err != nil {
+					return err
+				}
+				return syncToAll(cmd, clientset, source, targets)
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			return watchAndSync(ctx, cmd, clientset, ref, targets)
+		},
+	}
+	cmd.Flags().StringSliceVar(&targets, "to", nil, "comma-separated namespaces to replicate the secret into")
+	cmd.Flags().BoolVar(&watchSource, "watch", false, "keep running and update the replicas whenever the source changes")
+	cobra.CheckErr(cmd.RegisterFlagCompletionFunc("to", completeNamespaces(opts)))
+	return cmd
+}
+
+// syncToAll replicates source into every target namespace, reporting progress on stderr.
+func syncToAll(cmd *cobra.Command, clientset kube.Client, source *corev1.Secret, targets []string) error {
+	var errs []error
+	for _, target := range targets {
+		target = strings.TrimSpace(target)
+		if target == source.Namespace {
+			continue
+		}
+		if err := kube.SyncSecret(clientset, source, target); err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync to namespace '%s': %w", target, err))
+			continue
+		}
+		cmd.PrintErrf("Synced '%s/%s' to namespace '%s'\n", source.Namespace, source.Name, target)
+	}
+	return errors.Join(errs...)
+}
+
+// watchAndSync watches the source secret and re-syncs the replicas on every change
+// until the context is cancelled. Broken watches are re-established automatically.
+func watchAndSync(ctx context.Context, cmd *cobra.Command, clientset kube.Client, ref kube.SecretRef, targets []string) error {
+	listOptions := metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", ref.Name).String()}
+	for {
+		watcher, err := clientset.CoreV1().Secrets(ref.Namespace).Watch(ctx, listOptions)
+		if err != nil {
+			cmd.PrintErrf("Failed to watch secret '%s', retrying: %v\n", ref, err)
+		} else {
+			consumeSyncEvents(cmd, clientset, watcher, targets)
+			watcher.Stop()
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(kube.WatchRetryDelay):
+		}
+	}
+}
+
+// consumeSyncEvents handles watch events for the source secret until the watch ends.
+func consumeSyncEvents(cmd *cobra.Command, clientset kube.Client, watcher watch.Interface, targets []string) {
+	for event := range watcher.ResultChan() {
+		secret, ok := event.Object.(*corev1.Secret)
+		if !ok {
+			slog.Debug("ignored watch event", "type", event.Type)
+			continue
+		}
+		slog.Debug("watch event", "type", event.Type, "namespace", secret.Namespace, "secret", secret.Name, "resourceVersion", secret.ResourceVersion)
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			if err := syncToAll(cmd, clientset, secret, targets); err != nil {
+				cmd.PrintErrln(err)
+			}
+		case watch.Deleted:
+			cmd.PrintErrf("Source secret '%s/%s' was deleted; existing replicas are kept\n", secret.Namespace, secret.Name)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newCreateTLSCmd creates the 'kds create tls' command.
+func newCreateTLSCmd(opts *rootOptions) *cobra.Command {
+	var certPath, keyPath, dryRun string
+
+	cmd := &cobra.Command{
+		Use:   "tls <name> --cert <file> --key <file>",
+		Short: "Create a TLS secret from a certificate and private key",
+		Long: `Create a kubernetes.io/tls secret from PEM-encoded certificate and private key files.
+
+Before creating the secret, kds checks that the private key matches the certificate,
+that the chain is ordered leaf first with each certificate signed by the next, and
+that every certificate is currently valid.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			secret, warnings, err := kube.BuildTLSSecret(namespace, args[0], certPath, keyPath, time.Now())
+			for _, warning := range warnings {
+				cmd.PrintErrln("Warning:", warning)
+			}
+			if err != nil {
+				return err
+			}
+			if applied, err := runMutation(cmd, clientset, kube.Mutation{After: secret}, dryRun, false); err != nil || !applied {
+				return err
+			}
+			cmd.PrintErrf("Created secret '%s/%s'\n", namespace, args[0])
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&certPath, "cert", "", "path to the PEM-encoded certificate (chain)")
+	cmd.Flags().StringVar(&keyPath, "key", "", "path to the PEM-encoded private key")
+	addMutationFlags(cmd, &dryRun)
+	cobra.CheckErr(cmd.MarkFlagRequired("cert"))
+	cobra.CheckErr(cmd.MarkFlagRequired("key"))
+	return cmd
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newTokenCmd creates the 'kds token' command.
+func newTokenCmd(opts *rootOptions) *cobra.Command {
+	var audiences []string
+	var lifetime time.Duration
+	var copyToClipboard bool
+
+	cmd := &cobra.Command{
+		Use:   "token <serviceaccount>",
+		Short: "Request a short-lived token for a ServiceAccount",
+		Long: `Request a short-lived token for a ServiceAccount from the TokenRequest API,
+instead of reading a long-lived token from a service-account-token secret.
+
+The token is printed on stdout, or copied to the clipboard with --copy. The API
+server may shorten the requested duration; the actual expiry is printed on stderr.`,
+		Example: `  # Call the API as the ServiceAccount for the next 10 minutes
+  kubectl --token "$(kds token deployer --duration 10m)" get pods
+
+  # Mint a token for another audience and copy it
+  kds token vault-auth --audience vault --copy`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeServiceAccountNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if lifetime < kube.MinTokenDuration {
+				return fmt.Errorf("duration must be at least %s", kube.MinTokenDuration)
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			status, err := kube.RequestToken(clientset, namespace, args[0], audiences, lifetime)
+			if err != nil {
+				return err
+			}
+			if copyToClipboard {
+				if err := clipboard.WriteAll(status.Token); err != nil {
+					return fmt.Errorf("failed to copy the token: %w", err)
+				}
+				cmd.PrintErrf("Copied a token for ServiceAccount '%s/%s' to the clipboard\n", namespace, args[0])
+			} else if _, err := io.WriteString(cmd.OutOrStdout(), status.Token+"\n"); err != nil {
+				return err
+			}
+			cmd.PrintErrf("The token expires at %s\n", status.ExpirationTimestamp.UTC().Format(time.RFC3339))
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&audiences, "audience", nil, "intended audiences of the token (default: the API server's audiences)")
+	cmd.Flags().DurationVar(&lifetime, "duration", time.Hour, "requested lifetime of the token")
+	cmd.Flags().BoolVar(&copyToClipboard, "copy", false, "copy the token to the clipboard instead of printing it")
+	return cmd
+}
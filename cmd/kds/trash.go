@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// trashConfig configures where deleted secrets are kept, and for how long.
+type trashConfig struct {
+	// Dir holds the encrypted secrets (default: kds/trash in the user config directory).
+	Dir string `json:"dir,omitempty"`
+	// Retention is how long deleted secrets can be restored, e.g. 72h (default: 168h).
+	Retention string `json:"retention,omitempty"`
+	// Disabled deletes secrets without keeping a copy.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// openTrash opens the trash of the configuration file for the selected context, or
+// returns nil if it is disabled.
+// The key is kept in the OS keychain, or without one next to the trash directory,
+// e.g. ~/.config/kds/trash.key on Linux.
+func (o *rootOptions) openTrash() (*kube.Trash, error) {
+	config, err := loadConfig(o.configPath)
+	if err != nil {
+		return nil, err
+	}
+	if config.Trash.Disabled {
+		return nil, nil
+	}
+	retention := kube.DefaultTrashRetention
+	if config.Trash.Retention != "" {
+		if retention, err = time.ParseDuration(config.Trash.Retention); err != nil || retention <= 0 {
+			return nil, fmt.Errorf("invalid trash retention '%s': use a positive duration such as 72h", config.Trash.Retention)
+		}
+	}
+	dir := config.Trash.Dir
+	if dir == "" {
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the trash directory: %w", err)
+		}
+		dir = filepath.Join(base, "kds", "trash")
+	}
+	contextName, err := o.contextName()
+	if err != nil {
+		return nil, err
+	}
+	return kube.OpenTrash(dir, filepath.Clean(dir)+".key", contextName, retention)
+}
+
+// newRestoreDeletedCmd creates the 'kds restore-deleted' command.
+func newRestoreDeletedCmd(opts *rootOptions) *cobra.Command {
+	var dryRun string
+
+	cmd := &cobra.Command{
+		Use:   "restore-deleted [secret-name]",
+		Short: "Restore a secret deleted by kds, or list the deleted secrets",
+		Long: `Restore a secret deleted by kds, or list the deleted secrets.
+
+Before deleting a secret, the TUI and 'kds delete' write an encrypted copy of it
+to a local trash directory. The copies are kept for the retention period of the
+'trash' section of the config file (7 days by default), and can be restored with
+this command as long as no secret of the same name exists. Only the secrets
+deleted in the current context are restored. Without a secret name, the deleted
+secrets of the current context that can still be restored are listed.
+
+With --dry-run, the secret is only shown, or checked by the API server with
+--dry-run=server, and stays in the trash.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			trash, err := opts.openTrash()
+			if err != nil {
+				return err
+			}
+			if trash == nil {
+				return fmt.Errorf("the trash is disabled in the kds config")
+			}
+			now := time.Now()
+			if len(args) == 0 {
+				entries, err := trash.List(now)
+				if err != nil {
+					return err
+				}
+				return printTrash(cmd.OutOrStdout(), entries, now)
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			entry, err := trash.Find(kube.SecretRef{Namespace: namespace, Name: args[0]}, now)
+			if err != nil {
+				return err
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			mu, err := trash.RestoreMutation(entry)
+			if err != nil {
+				return err
+			}
+			if applied, err := runMutation(cmd, clientset, mu, dryRun, false); err != nil || !applied {
+				return err
+			}
+			if err := trash.Remove(entry); err != nil {
+				return err
+			}
+			cmd.PrintErrf("Restored secret '%s', deleted %s ago\n", entry.Ref, age(entry.DeletedAt, now))
+			return nil
+		},
+	}
+	addMutationFlags(cmd, &dryRun)
+	return cmd
+}
+
+// printTrash writes the deleted secrets as an aligned table.
+func printTrash(w io.Writer, entries []kube.TrashEntry, now time.Time) error {
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(w, "No deleted secrets to restore.")
+		return err
+	}
+	var b strings.Builder
+	b.WriteString("CONTEXT\tNAMESPACE\tNAME\tDELETED\n")
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s ago\n", entry.Context, entry.Ref.Namespace, entry.Ref.Name, age(entry.DeletedAt, now))
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if _, err := io.WriteString(tw, b.String()); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
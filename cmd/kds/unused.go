@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// newUnusedKeysCmd creates the 'kds unused-keys' command.
+func newUnusedKeysCmd(opts *rootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "unused-keys [secret-name...]",
+		Short: "Find the keys of secrets that nothing consumes",
+		Long: `Find the keys of secrets (all secrets in the namespace if none are given) that no
+workload, pod, or ingress of the namespace consumes, to slim down bloated shared
+secrets.
+
+A key is consumed when an env secretKeyRef or a volume item names it, or when a
+workload reads the whole secret with envFrom or a volume without items. Ingresses
+consume tls.crt and tls.key. Secrets that nothing references at all are orphans,
+which 'kds stats' counts, and are not listed.`,
+		ValidArgsFunction: completeSecretNames(opts),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			secrets, err := kube.GetSecretsOrAll(clientset, namespace, args)
+			if err != nil {
+				return err
+			}
+			usages, err := kube.BuildUsageIndex(clientset, namespace)
+			if err != nil {
+				return err
+			}
+			return printUnusedKeys(cmd.OutOrStdout(), secrets, usages)
+		},
+	}
+}
+
+// printUnusedKeys writes the secrets with unused keys as an aligned table.
+func printUnusedKeys(w io.Writer, secrets []*corev1.Secret, usages kube.UsageIndex) error {
+	var b strings.Builder
+	for _, secret := range secrets {
+		if unused := kube.UnusedKeys(secret, usages[secret.Name]); len(unused) > 0 {
+			fmt.Fprintf(&b, "%s\t%d of %d keys unused\t%s\n", secret.Name, len(unused), len(secret.Data), strings.Join(unused, ", "))
+		}
+	}
+	if b.Len() == 0 {
+		_, err := io.WriteString(w, "No unused keys\n")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if _, err := io.WriteString(tw, "SECRET\tUNUSED\tKEYS\n"+b.String()); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// applySecretURI selects the context and the namespace of a secret URI, unless
+// flags select others.
+func (o *rootOptions) applySecretURI(uri kube.SecretURI) error {
+	if o.allNamespaces {
+		return fmt.Errorf("--all-namespaces cannot be used with a secret URI")
+	}
+	context, namespace := o.flags().Context, o.flags().Namespace
+	if uri.Context != "" {
+		if *context != "" && *context != uri.Context {
+			return fmt.Errorf("the secret URI is of context '%s', but --context is '%s'", uri.Context, *context)
+		}
+		*context = uri.Context
+	}
+	if *namespace != "" && *namespace != uri.Ref.Namespace {
+		return fmt.Errorf("the secret URI is of namespace '%s', but --namespace is '%s'", uri.Ref.Namespace, *namespace)
+	}
+	*namespace = uri.Ref.Namespace
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// TestApplySecretURI verifies that a secret URI selects its context and its
+// namespace, unless flags select others.
+func TestApplySecretURI(t *testing.T) {
+	uri := kube.SecretURI{Context: "prod", Ref: kube.SecretRef{Namespace: "payments", Name: "db"}}
+
+	t.Run("should select the context and the namespace", func(t *testing.T) {
+		opts := &rootOptions{}
+		if err := opts.applySecretURI(uri); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if *opts.flags().Context != "prod" || *opts.flags().Namespace != "payments" {
+			t.Errorf("Expected context prod and namespace payments, but got %s and %s", *opts.flags().Context, *opts.flags().Namespace)
+		}
+	})
+	t.Run("should keep the current context when the URI has none", func(t *testing.T) {
+		opts := &rootOptions{}
+		if err := opts.applySecretURI(kube.SecretURI{Ref: uri.Ref}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if *opts.flags().Context != "" {
+			t.Errorf("Expected no context override, but got %s", *opts.flags().Context)
+		}
+	})
+	t.Run("should refuse a conflicting --context", func(t *testing.T) {
+		opts := &rootOptions{}
+		*opts.flags().Context = "staging"
+		if err := opts.applySecretURI(uri); err == nil || !strings.Contains(err.Error(), "--context is 'staging'") {
+			t.Errorf("Expected a conflict error, but got %v", err)
+		}
+	})
+	t.Run("should accept a matching --namespace", func(t *testing.T) {
+		opts := &rootOptions{}
+		*opts.flags().Namespace = "payments"
+		if err := opts.applySecretURI(uri); err != nil {
+			t.Errorf("Expected no error, but got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/spf13/cobra"
+)
+
+// newValidateCmd creates the 'kds validate' command.
+func newValidateCmd(opts *rootOptions) *cobra.Command {
+	var specPath string
+
+	cmd := &cobra.Command{
+		Use:   "validate --spec <file>",
+		Short: "Check the secrets of a namespace against a spec file",
+		Long: `Check the secrets of a namespace against a YAML spec listing the secrets that
+must exist, their type, their keys, and constraints on the values of the keys: a
+regular expression to match, a minimum length, or a format (json or pem).
+
+The outcome is printed like 'kds assert' prints it, and kds exits with a non-zero
+code if any check fails. Values are never printed.`,
+		Example: `  # secrets.schema.yaml:
+  #   secrets:
+  #     - name: db-credentials
+  #       keys:
+  #         - name: password
+  #           minLength: 16
+  #         - name: port
+  #           pattern: ^[0-9]+$
+  #     - name: app-tls
+  #       type: kubernetes.io/tls
+  #       keys:
+  #         - name: tls.crt
+  #           format: pem
+  kds validate -n prod --spec secrets.schema.yaml`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if specPath == "" {
+				return errors.New("--spec is required")
+			}
+			spec, err := kube.LoadSecretSpec(specPath)
+			if err != nil {
+				return err
+			}
+			clientset, err := opts.newClientset()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			assertions, err := spec.Validate(clientset, namespace)
+			if err != nil {
+				return err
+			}
+			failed, err := printAssertions(cmd.OutOrStdout(), assertions)
+			if err != nil {
+				return err
+			}
+			if failed > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d checks failed in namespace '%s'", failed, len(assertions), namespace)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&specPath, "spec", "", "YAML spec of the secrets the namespace must have")
+	return cmd
+}
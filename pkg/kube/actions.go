@@ -0,0 +1,32 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultExportPath is the file suggested when exporting secrets from the TUI.
+const DefaultExportPath = "kds-export.json"
+
+// --- SECRET OPERATIONS ---
+
+// DeleteSecret deletes a single secret.
+func DeleteSecret(clientset Client, ref SecretRef) error {
+	return clientset.CoreV1().Secrets(ref.Namespace).Delete(context.TODO(), ref.Name, metav1.DeleteOptions{})
+}
+
+// ExportSecrets writes the decoded data of the given secrets to a JSON file that
+// only the current user can read.
+func ExportSecrets(clientset Client, refs []SecretRef, path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := PrintSecretsJSON(file, clientset, refs, true, nil, nil); err != nil {
+		return errors.Join(err, file.Close())
+	}
+	return file.Close()
+}
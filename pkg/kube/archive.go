@@ -0,0 +1,135 @@
+package kube
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// archiveEntry is a single file to be stored in an export archive.
+type archiveEntry struct {
+	name    string
+	content []byte
+}
+
+// secretMetadata is the content of the optional <secret>.metadata.yaml file in an
+// export archive.
+type secretMetadata struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Type        corev1.SecretType `json:"type"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// archiveEntries lists the files of an export archive: one per key, in a directory
+// named after the secret, plus <secret>.metadata.yaml next to the directory if
+// requested, where it cannot collide with a key.
+func archiveEntries(secret *corev1.Secret, withMetadata bool) ([]archiveEntry, error) {
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		if err := checkFileName(key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]archiveEntry, 0, len(keys)+1)
+	for _, key := range keys {
+		entries = append(entries, archiveEntry{name: path.Join(secret.Name, key), content: secret.Data[key]})
+	}
+	if withMetadata {
+		metadata, err := yaml.Marshal(secretMetadata{
+			Name:        secret.Name,
+			Namespace:   secret.Namespace,
+			Type:        secret.Type,
+			Labels:      secret.Labels,
+			Annotations: WithoutSensitiveAnnotations(secret.Annotations),
+		})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, archiveEntry{name: secret.Name + ".metadata.yaml", content: metadata})
+	}
+	return entries, nil
+}
+
+// WriteSecretArchive packages the keys of a secret into a tarball or zip archive,
+// picking the format from the file extension.
+func WriteSecretArchive(clientset Client, ref SecretRef, archivePath string, withMetadata bool) error {
+	var write func(io.Writer, []archiveEntry) error
+	switch {
+	case strings.HasSuffix(archivePath, ".tar.gz"), strings.HasSuffix(archivePath, ".tgz"):
+		write = writeTarGz
+	case strings.HasSuffix(archivePath, ".zip"):
+		write = writeZip
+	default:
+		return fmt.Errorf("unsupported archive format for '%s' (use .tar.gz, .tgz, or .zip)", archivePath)
+	}
+
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return err
+	}
+	entries, err := archiveEntries(secret, withMetadata)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(archivePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive '%s': %w", archivePath, err)
+	}
+	if err := write(file, entries); err != nil {
+		return errors.Join(fmt.Errorf("failed to write archive '%s': %w", archivePath, err), file.Close())
+	}
+	return file.Close()
+}
+
+// writeTarGz writes the entries as a gzip-compressed tarball.
+func writeTarGz(w io.Writer, entries []archiveEntry) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0o600, Size: int64(len(entry.content)), ModTime: now}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(entry.content); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// writeZip writes the entries as a zip archive.
+func writeZip(w io.Writer, entries []archiveEntry) error {
+	zw := zip.NewWriter(w)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate, Modified: time.Now()}
+		header.SetMode(0o600)
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(entry.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
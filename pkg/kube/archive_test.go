@@ -0,0 +1,68 @@
+package kube
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestWriteSecretArchive verifies that a tarball contains every key and the metadata,
+// even with a key named like the metadata file, and that the metadata leaves out
+// the annotations that may hold values.
+func TestWriteSecretArchive(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-tls", Namespace: "default", Annotations: map[string]string{
+			lastAppliedAnnotation:                     `{"data":{"tls.key":"a2V5"}}`,
+			PreviousValueAnnotationPrefix + "tls.key": "old",
+		}},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key"), "metadata.yaml": []byte("owner: web")},
+	})
+	archivePath := filepath.Join(t.TempDir(), "out.tgz")
+	if err := WriteSecretArchive(clientset, SecretRef{Namespace: "default", Name: "app-tls"}, archivePath, true); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		t.Fatalf("Expected archive to exist, but got: %v", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Expected a gzip stream, but got: %v", err)
+	}
+	contents := make(map[string]string)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Expected a valid tarball, but got: %v", err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		contents[header.Name] = string(content)
+	}
+	expected := map[string]string{
+		"app-tls/tls.crt":       "cert",
+		"app-tls/tls.key":       "key",
+		"app-tls/metadata.yaml": "owner: web",
+		"app-tls.metadata.yaml": "name: app-tls\nnamespace: default\ntype: kubernetes.io/tls\n",
+	}
+	if !reflect.DeepEqual(contents, expected) {
+		t.Errorf("Expected archive contents %v, but got %v", expected, contents)
+	}
+}
@@ -0,0 +1,89 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretExpectation is a secret that must exist, with the keys it must have.
+type SecretExpectation struct {
+	Name string
+	Keys []string
+}
+
+// Assertion is the outcome of checking a secret, or a key of it if Key is set.
+type Assertion struct {
+	Secret string `json:"secret"`
+	Key    string `json:"key,omitempty"`
+	Passed bool   `json:"passed"`
+	// Reason tells why the assertion failed.
+	Reason string `json:"reason,omitempty"`
+}
+
+// String returns the secret, or its key, as "secret/key".
+func (a Assertion) String() string {
+	if a.Key == "" {
+		return a.Secret
+	}
+	return a.Secret + "/" + a.Key
+}
+
+// ParseKeyExpectation parses a "secret:key1,key2" expectation.
+func ParseKeyExpectation(s string) (SecretExpectation, error) {
+	name, keys, found := strings.Cut(s, ":")
+	if !found || name == "" || keys == "" {
+		return SecretExpectation{}, fmt.Errorf("invalid key expectation '%s': use secret:key1,key2", s)
+	}
+	return SecretExpectation{Name: name, Keys: strings.Split(keys, ",")}, nil
+}
+
+// MergeExpectations merges the expectations on the same secret, keeping the order
+// in which secrets and keys first appear.
+func MergeExpectations(expectations []SecretExpectation) []SecretExpectation {
+	var merged []SecretExpectation
+	index := make(map[string]int)
+	for _, e := range expectations {
+		i, ok := index[e.Name]
+		if !ok {
+			i = len(merged)
+			index[e.Name] = i
+			merged = append(merged, SecretExpectation{Name: e.Name})
+		}
+		for _, key := range e.Keys {
+			if !slices.Contains(merged[i].Keys, key) {
+				merged[i].Keys = append(merged[i].Keys, key)
+			}
+		}
+	}
+	return merged
+}
+
+// CheckExpectations checks that the expected secrets and keys exist in a
+// namespace. The keys of a missing secret are not checked.
+func CheckExpectations(clientset Client, namespace string, expectations []SecretExpectation) ([]Assertion, error) {
+	var assertions []Assertion
+	for _, e := range MergeExpectations(expectations) {
+		secret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), e.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			assertions = append(assertions, Assertion{Secret: e.Name, Reason: "secret missing"})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get secret '%s': %w", SecretRef{Namespace: namespace, Name: e.Name}, err)
+		}
+		assertions = append(assertions, Assertion{Secret: e.Name, Passed: true})
+		for _, key := range e.Keys {
+			if _, ok := secret.Data[key]; ok {
+				assertions = append(assertions, Assertion{Secret: e.Name, Key: key, Passed: true})
+			} else {
+				assertions = append(assertions, Assertion{Secret: e.Name, Key: key, Reason: "key missing"})
+			}
+		}
+	}
+	return assertions, nil
+}
@@ -0,0 +1,48 @@
+package kube
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestCheckExpectations verifies that missing secrets and keys fail their assertions.
+func TestCheckExpectations(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-token", Namespace: "prod"},
+		Data:       map[string][]byte{"token": []byte("t0k3n")},
+	})
+
+	t.Run("should parse key expectations", func(t *testing.T) {
+		e, err := ParseKeyExpectation("api-token:token,url")
+		if err != nil || !reflect.DeepEqual(e, SecretExpectation{Name: "api-token", Keys: []string{"token", "url"}}) {
+			t.Errorf("Expected api-token with keys token and url, but got %v, %v", e, err)
+		}
+		if _, err := ParseKeyExpectation("api-token"); err == nil {
+			t.Error("Expected an error without keys, but got none")
+		}
+	})
+
+	t.Run("should report missing secrets and keys", func(t *testing.T) {
+		assertions, err := CheckExpectations(clientset, "prod", []SecretExpectation{
+			{Name: "api-token"},
+			{Name: "db"},
+			{Name: "api-token", Keys: []string{"token", "url"}},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		expected := []Assertion{
+			{Secret: "api-token", Passed: true},
+			{Secret: "api-token", Key: "token", Passed: true},
+			{Secret: "api-token", Key: "url", Reason: "key missing"},
+			{Secret: "db", Reason: "secret missing"},
+		}
+		if !reflect.DeepEqual(assertions, expected) {
+			t.Errorf("Expected %v, but got %v", expected, assertions)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package kube
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// EncodeBase64 encodes a value as a single line of base64, never wrapped, with the
+// URL-safe alphabet if urlSafe, and without the trailing '=' padding unless padded.
+func EncodeBase64(value []byte, urlSafe, padded bool) string {
+	encoding := base64.StdEncoding
+	if urlSafe {
+		encoding = base64.URLEncoding
+	}
+	if !padded {
+		encoding = encoding.WithPadding(base64.NoPadding)
+	}
+	return encoding.EncodeToString(value)
+}
+
+// DecodeBase64 decodes base64 the lenient way: whitespace and line breaks are
+// ignored, so wrapped output of the base64 tool and YAML block scalars decode, the
+// padding is optional, and the standard and URL-safe alphabets are both accepted.
+// PEM input decodes to the DER bytes of its blocks, in order.
+func DecodeBase64(s string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(s), "-----BEGIN ") {
+		return decodePEMBlocks([]byte(s))
+	}
+	compact := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r):
+			return -1
+		case r == '-':
+			return '+'
+		case r == '_':
+			return '/'
+		}
+		return r
+	}, s)
+	decoded, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(compact, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	return decoded, nil
+}
+
+// decodePEMBlocks returns the concatenated bytes of the PEM blocks of content.
+func decodePEMBlocks(content []byte) ([]byte, error) {
+	var der []byte
+	for {
+		block, rest := pem.Decode(content)
+		if block == nil {
+			break
+		}
+		der = append(der, block.Bytes...)
+		content = rest
+	}
+	if der == nil || len(bytes.TrimSpace(content)) > 0 {
+		return nil, errors.New("invalid PEM: expected only BEGIN/END blocks")
+	}
+	return der, nil
+}
+
+// LooksBase64Encoded reports whether a value is itself padded base64 of printable
+// text, which usually means it was encoded twice, as happens when an encoded value
+// is put in stringData or encoded again by hand.
+func LooksBase64Encoded(value []byte) bool {
+	if len(value) < 8 || len(value)%4 != 0 {
+		return false
+	}
+	decoded, err := base64.StdEncoding.Strict().DecodeString(string(value))
+	if err != nil || !utf8.Valid(decoded) {
+		return false
+	}
+	return !strings.ContainsFunc(string(decoded), func(r rune) bool { return !unicode.IsPrint(r) && !unicode.IsSpace(r) })
+}
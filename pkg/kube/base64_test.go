@@ -0,0 +1,68 @@
+package kube
+
+import (
+	"encoding/pem"
+	"testing"
+)
+
+// TestDecodeBase64 verifies that base64 is decoded despite the usual quirks.
+func TestDecodeBase64(t *testing.T) {
+	tests := []struct {
+		name, input, expected string
+	}{
+		{"should decode padded base64", "aHVudGVyMg==", "hunter2"},
+		{"should decode base64 without padding", "aHVudGVyMg", "hunter2"},
+		{"should decode the URL-safe alphabet", "-_8", "\xfb\xff"},
+		{"should ignore line breaks and indentation", "  aHVu\n  dGVy\r\n  Mg==\n", "hunter2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeBase64(tt.input)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if string(decoded) != tt.expected {
+				t.Errorf("Expected %q, but got %q", tt.expected, decoded)
+			}
+		})
+	}
+	t.Run("should decode PEM to DER", func(t *testing.T) {
+		der := []byte{0x30, 0x03, 0x02, 0x01, 0x01}
+		decoded, err := DecodeBase64(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if string(decoded) != string(der) {
+			t.Errorf("Expected %x, but got %x", der, decoded)
+		}
+	})
+	t.Run("should reject invalid base64", func(t *testing.T) {
+		if _, err := DecodeBase64("not base64!"); err == nil {
+			t.Error("Expected an error, but got nil")
+		}
+	})
+}
+
+// TestEncodeBase64 verifies the alphabets and padding of encoded values.
+func TestEncodeBase64(t *testing.T) {
+	value := []byte("\xfb\xffa")
+	if encoded := EncodeBase64(value, false, true); encoded != "+/9h" {
+		t.Errorf("Expected %q, but got %q", "+/9h", encoded)
+	}
+	if encoded := EncodeBase64([]byte("a"), true, false); encoded != "YQ" {
+		t.Errorf("Expected %q, but got %q", "YQ", encoded)
+	}
+	if encoded := EncodeBase64(value, true, true); encoded != "-_9h" {
+		t.Errorf("Expected %q, but got %q", "-_9h", encoded)
+	}
+}
+
+// TestLooksBase64Encoded verifies the detection of values encoded twice.
+func TestLooksBase64Encoded(t *testing.T) {
+	if !LooksBase64Encoded([]byte("aHVudGVyMg==")) {
+		t.Error("Expected an encoded value to be detected")
+	}
+	if LooksBase64Encoded([]byte("password")) {
+		t.Error("Expected a plain value not to be detected")
+	}
+}
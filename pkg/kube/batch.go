@@ -0,0 +1,112 @@
+package kube
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretRef identifies a secret by namespace and name.
+type SecretRef struct {
+	Namespace string
+	Name      string
+}
+
+// String returns the reference in the familiar "namespace/name" form.
+func (r SecretRef) String() string { return r.Namespace + "/" + r.Name }
+
+// SecretData is the decoded, serializable form of a secret used by the JSON output.
+type SecretData struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Data      map[string]string `json:"data"`
+}
+
+// parseSecretRef parses a "name" or "namespace/name" reference, using the default
+// namespace when none is given.
+func parseSecretRef(ref, defaultNamespace string) SecretRef {
+	if ns, name, found := strings.Cut(ref, "/"); found {
+		return SecretRef{Namespace: ns, Name: name}
+	}
+	return SecretRef{Namespace: defaultNamespace, Name: ref}
+}
+
+// ReadSecretRefs reads one secret reference per line, skipping blank lines and # comments.
+func ReadSecretRefs(r io.Reader, defaultNamespace string) ([]SecretRef, error) {
+	var refs []SecretRef
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		refs = append(refs, parseSecretRef(line, defaultNamespace))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read secret names: %w", err)
+	}
+	return refs, nil
+}
+
+// GetSecret fetches a single secret by reference.
+func GetSecret(clientset Client, ref SecretRef) (*corev1.Secret, error) {
+	secret, err := clientset.CoreV1().Secrets(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret '%s': %w", ref, err)
+	}
+	return secret, nil
+}
+
+// getSecretData fetches a secret and decodes its data for serialization. Keys
+// always masked by the redaction policy are masked, and so are the keys to be
+// confirmed unless confirm, which may be nil, reveals them.
+func getSecretData(clientset Client, ref SecretRef, redaction RedactionPolicy, confirm func(keys []string) bool) (SecretData, error) {
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return SecretData{}, err
+	}
+	masked := make(map[string]bool)
+	for _, key := range KeysWithMode(redaction, secret.Data, RedactMask) {
+		masked[key] = true
+	}
+	if keys := KeysWithMode(redaction, secret.Data, RedactConfirm); len(keys) > 0 && (confirm == nil || !confirm(keys)) {
+		for _, key := range keys {
+			masked[key] = true
+		}
+	}
+	data := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		if masked[key] {
+			data[key] = MaskValue(value)
+		} else {
+			data[key], _ = DecodeValue(value)
+		}
+	}
+	return SecretData{Namespace: secret.Namespace, Name: secret.Name, Data: data}, nil
+}
+
+// PrintSecretsJSON fetches the referenced secrets and writes them as indented JSON,
+// with the keys redacted by policy masked as getSecretData does. A single secret is
+// written as an object unless asArray is set.
+func PrintSecretsJSON(w io.Writer, clientset Client, refs []SecretRef, asArray bool, redaction RedactionPolicy, confirm func(keys []string) bool) error {
+	secrets := make([]SecretData, 0, len(refs))
+	for _, ref := range refs {
+		data, err := getSecretData(clientset, ref, redaction, confirm)
+		if err != nil {
+			return err
+		}
+		secrets = append(secrets, data)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if !asArray && len(secrets) == 1 {
+		return enc.Encode(secrets[0])
+	}
+	return enc.Encode(secrets)
+}
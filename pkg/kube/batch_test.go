@@ -0,0 +1,24 @@
+package kube
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestReadSecretRefs verifies parsing of secret names read from stdin.
+func TestReadSecretRefs(t *testing.T) {
+	input := "app-db\n\n# a comment\nprod/app-tls\n  spaced  \n"
+	refs, err := ReadSecretRefs(strings.NewReader(input), "default")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := []SecretRef{
+		{Namespace: "default", Name: "app-db"},
+		{Namespace: "prod", Name: "app-tls"},
+		{Namespace: "default", Name: "spaced"},
+	}
+	if !reflect.DeepEqual(refs, expected) {
+		t.Errorf("Expected refs %v, but got %v", expected, refs)
+	}
+}
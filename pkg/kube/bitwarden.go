@@ -0,0 +1,193 @@
+package kube
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Types of Bitwarden items and custom fields.
+const (
+	bitwardenSecureNote  = 2
+	bitwardenHiddenField = 1
+	bitwardenLinkedField = 3
+)
+
+// bitwardenItem holds the attributes of a Bitwarden item, as printed by 'bw list
+// items' and read by 'bw create item' and 'bw edit item'.
+type bitwardenItem struct {
+	ID       string           `json:"id,omitempty"`
+	Type     int              `json:"type"`
+	Name     string           `json:"name"`
+	FolderID *string          `json:"folderId"`
+	Fields   []bitwardenField `json:"fields"`
+	// Other holds the attributes kds does not change, which 'bw edit item' needs
+	// back as they were.
+	Other map[string]json.RawMessage `json:"-"`
+}
+
+// bitwardenField is a custom field of a Bitwarden item.
+type bitwardenField struct {
+	Name     string          `json:"name"`
+	Value    *string         `json:"value"`
+	Type     int             `json:"type"`
+	LinkedID json.RawMessage `json:"linkedId,omitempty"`
+}
+
+// UnmarshalJSON keeps the attributes of an item that kds does not know.
+func (i *bitwardenItem) UnmarshalJSON(content []byte) error {
+	type plain bitwardenItem
+	if err := json.Unmarshal(content, (*plain)(i)); err != nil {
+		return err
+	}
+	var err error
+	i.Other, err = otherAttributes(content, "id", "type", "name", "folderId", "fields")
+	return err
+}
+
+// MarshalJSON writes back the attributes of an item that kds does not know.
+func (i bitwardenItem) MarshalJSON() ([]byte, error) {
+	type plain bitwardenItem
+	content, err := json.Marshal(plain(i))
+	if err != nil {
+		return nil, err
+	}
+	return withOtherAttributes(content, i.Other)
+}
+
+// runBitwarden runs a bw command, without prompting for the master password: the
+// vault must be unlocked, with the session in BW_SESSION.
+func (s SecretStore) runBitwarden(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	out, err := s.run(ctx, append([]string{"bw", "--nointeraction"}, args...), stdin, nil)
+	if err != nil && (strings.Contains(err.Error(), "Vault is locked") || strings.Contains(err.Error(), "not logged in")) {
+		return nil, fmt.Errorf("%w (log in with 'bw login', then run 'export BW_SESSION=$(bw unlock --raw)')", err)
+	}
+	return out, err
+}
+
+// bitwardenFolder returns the ID of the folder of the store, its vault, or nil if
+// it has none.
+func (s SecretStore) bitwardenFolder(ctx context.Context) (*string, error) {
+	if s.vault == "" {
+		return nil, nil
+	}
+	out, err := s.runBitwarden(ctx, nil, "list", "folders", "--search", s.vault)
+	if err != nil {
+		return nil, err
+	}
+	var folders []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(out, &folders); err != nil {
+		return nil, fmt.Errorf("failed to parse the output of bw: %w", err)
+	}
+	for _, folder := range folders {
+		if folder.Name == s.vault {
+			return &folder.ID, nil
+		}
+	}
+	return nil, fmt.Errorf("no folder '%s' in Bitwarden", s.vault)
+}
+
+// getBitwardenItem returns the item with a name, in the folder of the store if it
+// has one, or nil if there is no such item, and the ID of the folder for the item
+// to be created in. The local copy of the vault is synced first, so that items
+// changed elsewhere are neither missed nor overwritten.
+func (s SecretStore) getBitwardenItem(ctx context.Context, name string) (*bitwardenItem, *string, error) {
+	if _, err := s.runBitwarden(ctx, nil, "sync"); err != nil {
+		return nil, nil, err
+	}
+	folder, err := s.bitwardenFolder(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	args := []string{"list", "items", "--search", name}
+	if folder != nil {
+		args = append(args, "--folderid", *folder)
+	}
+	out, err := s.runBitwarden(ctx, nil, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	var items []bitwardenItem
+	if err := json.Unmarshal(out, &items); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse the output of bw: %w", err)
+	}
+	var found *bitwardenItem
+	for i := range items {
+		if items[i].Name != name {
+			continue
+		}
+		if found != nil {
+			return nil, nil, fmt.Errorf("several items are named '%s' in Bitwarden", name)
+		}
+		found = &items[i]
+	}
+	return found, folder, nil
+}
+
+// pullBitwarden reads the custom fields of a Bitwarden item, by name. Linked and
+// empty fields are left out.
+func (s SecretStore) pullBitwarden(ctx context.Context, item StoreItem) (map[string][]byte, error) {
+	found, _, err := s.getBitwardenItem(ctx, item.Item)
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no item '%s' in Bitwarden", item.Item)
+	}
+	data := make(map[string][]byte, len(found.Fields))
+	for _, field := range found.Fields {
+		if field.Name == "" || field.Value == nil || *field.Value == "" || field.Type == bitwardenLinkedField {
+			continue
+		}
+		if _, duplicate := data[field.Name]; duplicate {
+			return nil, fmt.Errorf("item '%s' has several fields named '%s'", item.Item, field.Name)
+		}
+		data[field.Name] = []byte(*field.Value)
+	}
+	return data, nil
+}
+
+// pushBitwarden sets the keys of a secret as hidden custom fields of a Bitwarden
+// item, by name, with 'bw edit item', or creates the item as a secure note with
+// 'bw create item'. The other fields of an existing item are kept. The item is
+// passed on stdin, encoded as bw expects.
+func (s SecretStore) pushBitwarden(ctx context.Context, item StoreItem, data map[string][]byte) error {
+	existing, folder, err := s.getBitwardenItem(ctx, item.Item)
+	if err != nil {
+		return err
+	}
+	var args []string
+	if existing != nil {
+		args = []string{"edit", "item", existing.ID}
+	} else {
+		existing = &bitwardenItem{
+			Type:     bitwardenSecureNote,
+			Name:     item.Item,
+			FolderID: folder,
+			Other:    map[string]json.RawMessage{"secureNote": json.RawMessage(`{"type":0}`)},
+		}
+		args = []string{"create", "item"}
+	}
+	for _, key := range SortedKeys(data) {
+		i := 0
+		for i < len(existing.Fields) && existing.Fields[i].Name != key {
+			i++
+		}
+		if i == len(existing.Fields) {
+			existing.Fields = append(existing.Fields, bitwardenField{Name: key, Type: bitwardenHiddenField})
+		}
+		value := string(data[key])
+		existing.Fields[i].Value = &value
+	}
+	content, err := json.Marshal(existing)
+	if err != nil {
+		return err
+	}
+	_, err = s.runBitwarden(ctx, []byte(base64.StdEncoding.EncodeToString(content)), args...)
+	return err
+}
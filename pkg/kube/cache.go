@@ -0,0 +1,86 @@
+package kube
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// listCacheSuffix ends the name of every file of the list cache.
+const listCacheSuffix = ".list.enc"
+
+// ListCache keeps encrypted copies of the secret lists of a kubeconfig context on
+// the local disk, so that they can be shown before the API server answers. Only
+// what the list shows is cached, such as names, types, and sizes, never values.
+type ListCache struct {
+	dir     string
+	context string
+	key     []byte
+}
+
+// cachedList is the content of a file of the list cache.
+type cachedList struct {
+	SavedAt time.Time  `json:"savedAt"`
+	Items   ItemSource `json:"items"`
+}
+
+// OpenListCache opens the cache of the lists of a context in dir, creating it if
+// needed. Its files are encrypted with the key of keyPath, as loadSealKey keeps
+// it. Keep the key file apart from the cache, so that a copy of the cache alone
+// reveals nothing.
+func OpenListCache(dir, keyPath, context string) (*ListCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	key, err := loadSealKey(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache key: %w", err)
+	}
+	return &ListCache{dir: dir, context: context, key: key}, nil
+}
+
+// Load returns the cached list of a namespace, or of every namespace if it is
+// empty, and when it was saved. It returns no items if nothing is cached.
+func (c *ListCache) Load(namespace string) (ItemSource, time.Time, error) {
+	content, err := os.ReadFile(c.path(namespace))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read cache: %w", err)
+	}
+	plaintext, err := unseal(c.key, content)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to decrypt cache: %w", err)
+	}
+	var list cachedList
+	if err := json.Unmarshal(plaintext, &list); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to parse cache: %w", err)
+	}
+	return list.Items, list.SavedAt, nil
+}
+
+// Store replaces the cached list of a namespace, or of every namespace if it is
+// empty.
+func (c *ListCache) Store(namespace string, items ItemSource, now time.Time) error {
+	plaintext, err := json.Marshal(cachedList{SavedAt: now, Items: items})
+	if err != nil {
+		return fmt.Errorf("failed to serialize the secret list: %w", err)
+	}
+	sealed, err := seal(c.key, plaintext)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(c.dir, c.path(namespace), sealed); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return nil
+}
+
+// path returns the file of the list of a namespace.
+func (c *ListCache) path(namespace string) string {
+	return hashedPath(c.dir, listCacheSuffix, c.context, namespace)
+}
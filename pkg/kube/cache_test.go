@@ -0,0 +1,69 @@
+package kube
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestListCache verifies that secret lists are cached encrypted, per context and
+// namespace.
+func TestListCache(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	items := ItemSource{{Name: "app-db", Namespace: "default", SecretType: corev1.SecretTypeOpaque, Keys: 2, Size: 42, Created: now.Add(-time.Hour)}}
+	cache, err := OpenListCache(filepath.Join(dir, "lists"), filepath.Join(dir, "cache.key"), "prod")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cache.Store("default", items, now); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	t.Run("should load the stored list", func(t *testing.T) {
+		loaded, savedAt, err := cache.Load("default")
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if len(loaded) != 1 || loaded[0].Name != "app-db" || loaded[0].Keys != 2 || !savedAt.Equal(now) {
+			t.Errorf("Expected the stored list, but got %v saved at %v", loaded, savedAt)
+		}
+	})
+
+	t.Run("should encrypt the list", func(t *testing.T) {
+		content, err := os.ReadFile(cache.path("default"))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if bytes.Contains(content, []byte("app-db")) {
+			t.Error("Expected the cached list to be encrypted")
+		}
+	})
+
+	t.Run("should keep contexts and namespaces apart", func(t *testing.T) {
+		other, err := OpenListCache(filepath.Join(dir, "lists"), filepath.Join(dir, "cache.key"), "staging")
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if loaded, _, err := other.Load("default"); err != nil || loaded != nil {
+			t.Errorf("Expected nothing cached for another context, but got %v, %v", loaded, err)
+		}
+		if loaded, _, err := cache.Load("kube-system"); err != nil || loaded != nil {
+			t.Errorf("Expected nothing cached for another namespace, but got %v, %v", loaded, err)
+		}
+	})
+
+	t.Run("should not decrypt with another key", func(t *testing.T) {
+		other, err := OpenListCache(filepath.Join(dir, "lists"), filepath.Join(dir, "other.key"), "prod")
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if _, _, err := other.Load("default"); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package kube
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// caCertKey is the conventional key of the CA bundle in TLS secrets.
+const caCertKey = "ca.crt"
+
+// TLSCheck is the outcome of one validation of a TLS secret.
+type TLSCheck struct {
+	Name    string
+	Problem string // Empty if the check passed.
+	Warning bool   // The problem does not break TLS (yet).
+}
+
+// IngressHost is a hostname that an Ingress serves with a TLS secret.
+type IngressHost struct {
+	host    string
+	ingress string
+}
+
+// CheckTLSSecret validates the certificate and key of a TLS secret. The chain is
+// verified against ca.crt if present, or else against roots (the system roots if nil).
+func CheckTLSSecret(secret *corev1.Secret, hosts []IngressHost, roots *x509.CertPool, now time.Time) []TLSCheck {
+	chain, err := ParseCertificates(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return []TLSCheck{{Name: "certificate", Problem: corev1.TLSCertKey + ": " + err.Error()}}
+	}
+	leaf := chain[0]
+	checks := []TLSCheck{{Name: "private key matches the certificate"}}
+	if _, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]); err != nil {
+		checks[0].Problem = err.Error()
+	}
+	checks = append(checks, checkChain(secret, chain, roots, now))
+	if remaining := leaf.NotAfter.Sub(now); remaining > 0 && remaining < expiryWarningPeriod {
+		checks = append(checks, TLSCheck{Name: "expiry", Problem: "expires on " + leaf.NotAfter.Format(time.RFC3339), Warning: true})
+	}
+	for _, h := range hosts {
+		check := TLSCheck{Name: fmt.Sprintf("covers %s (Ingress/%s)", h.host, h.ingress)}
+		if err := leaf.VerifyHostname(h.host); err != nil {
+			check.Problem = "not in the certificate's SANs"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkChain verifies that the leaf certificate chains to ca.crt or to the roots,
+// using the rest of tls.crt as intermediates.
+func checkChain(secret *corev1.Secret, chain []*x509.Certificate, roots *x509.CertPool, now time.Time) TLSCheck {
+	check := TLSCheck{Name: "chains to the system roots"}
+	if ca, ok := secret.Data[caCertKey]; ok {
+		check.Name = "chains to " + caCertKey
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(ca) {
+			check.Problem = "no certificate found in " + caCertKey
+			return check
+		}
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		check.Problem = err.Error()
+	}
+	return check
+}
+
+// TLSChecksFailed reports whether any check failed, ignoring warnings.
+func TLSChecksFailed(checks []TLSCheck) bool {
+	for _, check := range checks {
+		if check.Problem != "" && !check.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// IngressHosts lists the hosts that Ingresses in the namespace serve with the secret.
+func IngressHosts(clientset Client, namespace, secretName string) ([]IngressHost, error) {
+	ingresses, err := clientset.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	var hosts []IngressHost
+	for _, ing := range ingresses.Items {
+		for _, tls := range ing.Spec.TLS {
+			if tls.SecretName != secretName {
+				continue
+			}
+			for _, host := range tls.Hosts {
+				hosts = append(hosts, IngressHost{host: host, ingress: ing.Name})
+			}
+		}
+	}
+	return hosts, nil
+}
@@ -0,0 +1,80 @@
+package kube
+
+import (
+	"crypto/x509"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diskmanti/kds/internal/testfixtures"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestCheckTLSSecret verifies the key, chain, expiry, and host checks.
+func TestCheckTLSSecret(t *testing.T) {
+	now := time.Now()
+	ca := testfixtures.NewCert(t, "ca", now.Add(-time.Hour), now.Add(365*24*time.Hour), nil)
+	leaf := testfixtures.NewCert(t, "leaf", now.Add(-time.Hour), now.Add(90*24*time.Hour), &ca, "app.example.com")
+	tlsSecret := func(data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app-tls"}, Type: corev1.SecretTypeTLS, Data: data}
+	}
+	problems := func(checks []TLSCheck) string {
+		var found []string
+		for _, check := range checks {
+			if check.Problem != "" {
+				found = append(found, check.Name)
+			}
+		}
+		return strings.Join(found, ", ")
+	}
+
+	t.Run("should pass a valid secret with its CA", func(t *testing.T) {
+		secret := tlsSecret(map[string][]byte{"tls.crt": leaf.CertPEM, "tls.key": leaf.KeyPEM, "ca.crt": ca.CertPEM})
+		checks := CheckTLSSecret(secret, []IngressHost{{host: "app.example.com", ingress: "app"}}, nil, now)
+		if got := problems(checks); got != "" || len(checks) != 3 {
+			t.Errorf("Expected 3 passing checks, but got %v", checks)
+		}
+		if checks[1].Name != "chains to ca.crt" {
+			t.Errorf("Expected the chain to be verified against ca.crt, but got '%s'", checks[1].Name)
+		}
+	})
+
+	t.Run("should verify against the given roots without ca.crt", func(t *testing.T) {
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.Cert)
+		secret := tlsSecret(map[string][]byte{"tls.crt": leaf.CertPEM, "tls.key": leaf.KeyPEM})
+		if got := problems(CheckTLSSecret(secret, nil, roots, now)); got != "" {
+			t.Errorf("Expected no problems, but got '%s'", got)
+		}
+	})
+
+	t.Run("should report a wrong key, CA, and uncovered hosts", func(t *testing.T) {
+		other := testfixtures.NewCert(t, "other-ca", now.Add(-time.Hour), now.Add(365*24*time.Hour), nil)
+		secret := tlsSecret(map[string][]byte{"tls.crt": leaf.CertPEM, "tls.key": other.KeyPEM, "ca.crt": other.CertPEM})
+		checks := CheckTLSSecret(secret, []IngressHost{{host: "api.example.com", ingress: "api"}}, nil, now)
+		expected := "private key matches the certificate, chains to ca.crt, covers api.example.com (Ingress/api)"
+		if got := problems(checks); got != expected {
+			t.Errorf("Expected '%s', but got '%s'", expected, got)
+		}
+		if !TLSChecksFailed(checks) {
+			t.Error("Expected the checks to fail")
+		}
+	})
+
+	t.Run("should warn about a certificate expiring soon", func(t *testing.T) {
+		soon := testfixtures.NewCert(t, "soon", now.Add(-time.Hour), now.Add(7*24*time.Hour), &ca)
+		secret := tlsSecret(map[string][]byte{"tls.crt": soon.CertPEM, "tls.key": soon.KeyPEM, "ca.crt": ca.CertPEM})
+		checks := CheckTLSSecret(secret, nil, nil, now)
+		if TLSChecksFailed(checks) || !checks[len(checks)-1].Warning {
+			t.Errorf("Expected only an expiry warning, but got %v", checks)
+		}
+	})
+
+	t.Run("should report a missing certificate", func(t *testing.T) {
+		checks := CheckTLSSecret(tlsSecret(map[string][]byte{}), nil, nil, now)
+		if len(checks) != 1 || !TLSChecksFailed(checks) {
+			t.Errorf("Expected a single failed check, but got %v", checks)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// CertificateNameAnnotation is set by cert-manager on the secrets it issues.
+const CertificateNameAnnotation = "cert-manager.io/certificate-name"
+
+// CertificateGVR identifies cert-manager Certificates for the dynamic client.
+var CertificateGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+// certificateCondition is a condition from the status of a Certificate.
+type certificateCondition struct {
+	Status  string
+	Reason  string
+	Message string
+}
+
+// CertificateInfo summarizes the cert-manager Certificate that manages a secret.
+type CertificateInfo struct {
+	Name        string
+	Ready       *certificateCondition
+	Issuing     *certificateCondition
+	NotAfter    string
+	RenewalTime string
+}
+
+// FindCertificate returns the Certificate that manages a secret, found by the
+// annotation cert-manager puts on issued secrets or by its spec.SecretName. It
+// returns nil if there is none, or if cert-manager is not installed.
+func FindCertificate(dyn dynamic.Interface, secret *corev1.Secret) (*unstructured.Unstructured, error) {
+	certificates := dyn.Resource(CertificateGVR).Namespace(secret.Namespace)
+	if name := secret.Annotations[CertificateNameAnnotation]; name != "" {
+		cert, err := certificates.Get(context.TODO(), name, metav1.GetOptions{})
+		if err == nil || !apierrors.IsNotFound(err) {
+			return cert, err
+		}
+	}
+	list, err := certificates.List(context.TODO(), metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list certificates: %w", err)
+	}
+	for i := range list.Items {
+		if nestedString(list.Items[i].Object, "spec", "secretName") == secret.Name {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// DescribeCertificate extracts the status of a Certificate.
+func DescribeCertificate(cert *unstructured.Unstructured) *CertificateInfo {
+	info := &CertificateInfo{
+		Name:        cert.GetName(),
+		NotAfter:    nestedString(cert.Object, "status", "notAfter"),
+		RenewalTime: nestedString(cert.Object, "status", "renewalTime"),
+	}
+	for _, c := range nestedSlice(cert.Object, "status", "conditions") {
+		condition, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		parsed := &certificateCondition{
+			Status:  nestedString(condition, "status"),
+			Reason:  nestedString(condition, "reason"),
+			Message: nestedString(condition, "message"),
+		}
+		switch condition["type"] {
+		case "Ready":
+			info.Ready = parsed
+		case "Issuing":
+			info.Issuing = parsed
+		}
+	}
+	return info
+}
+
+// nestedString returns a string field of an unstructured object, or "" if it is
+// missing or not a string.
+func nestedString(obj map[string]any, fields ...string) string {
+	value, found, err := unstructured.NestedString(obj, fields...)
+	if err != nil || !found {
+		return ""
+	}
+	return value
+}
+
+// nestedSlice returns a slice field of an unstructured object, or nil if it is
+// missing or not a slice.
+func nestedSlice(obj map[string]any, fields ...string) []any {
+	value, found, err := unstructured.NestedSlice(obj, fields...)
+	if err != nil || !found {
+		return nil
+	}
+	return value
+}
+
+// RenewCertificate triggers a re-issuance like 'cmctl renew' does: by adding an
+// Issuing condition to the status of the Certificate.
+func RenewCertificate(dyn dynamic.Interface, cert *unstructured.Unstructured, now time.Time) error {
+	if info := DescribeCertificate(cert); info.Issuing != nil && info.Issuing.Status == "True" {
+		return fmt.Errorf("certificate '%s' is already being issued", cert.GetName())
+	}
+	cert = cert.DeepCopy()
+	conditions := nestedSlice(cert.Object, "status", "conditions")
+	kept := make([]any, 0, len(conditions)+1)
+	for _, c := range conditions {
+		if condition, ok := c.(map[string]any); !ok || condition["type"] != "Issuing" {
+			kept = append(kept, c)
+		}
+	}
+	kept = append(kept, map[string]any{
+		"type":               "Issuing",
+		"status":             "True",
+		"reason":             "ManuallyTriggered",
+		"message":            "Certificate re-issuance manually triggered",
+		"lastTransitionTime": now.UTC().Format(time.RFC3339),
+		"observedGeneration": cert.GetGeneration(),
+	})
+	if err := unstructured.SetNestedSlice(cert.Object, kept, "status", "conditions"); err != nil {
+		return err
+	}
+	_, err := dyn.Resource(CertificateGVR).Namespace(cert.GetNamespace()).UpdateStatus(context.TODO(), cert, metav1.UpdateOptions{FieldManager: FieldManager})
+	if err != nil {
+		return fmt.Errorf("failed to renew certificate '%s': %w", cert.GetName(), err)
+	}
+	return nil
+}
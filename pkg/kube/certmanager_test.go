@@ -0,0 +1,42 @@
+package kube
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diskmanti/kds/internal/testfixtures"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestRenewCertificate verifies that renewal adds an Issuing condition.
+func TestRenewCertificate(t *testing.T) {
+	stale := map[string]any{"type": "Issuing", "status": "False", "reason": "Done"}
+	cert := testfixtures.NewCertificate("web", "web-tls", map[string]any{"type": "Ready", "status": "True"}, stale)
+	dyn := testfixtures.NewCertificateClient(cert)
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := RenewCertificate(dyn, cert, now); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	updated, err := dyn.Resource(CertificateGVR).Namespace("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	info := DescribeCertificate(updated)
+	if info.Issuing == nil || info.Issuing.Status != "True" || info.Issuing.Reason != "ManuallyTriggered" {
+		t.Errorf("Expected a manually triggered Issuing condition, but got %+v", info.Issuing)
+	}
+	conditions, _, _ := unstructured.NestedSlice(updated.Object, "status", "conditions")
+	if len(conditions) != 2 {
+		t.Errorf("Expected the old Issuing condition to be replaced, but got %v", conditions)
+	}
+
+	t.Run("should refuse to renew a Certificate that is being issued", func(t *testing.T) {
+		if err := RenewCertificate(dyn, updated, now); err == nil || !strings.Contains(err.Error(), "already being issued") {
+			t.Errorf("Expected an error, but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package kube
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// LoadLocalData reads the keys and values of a local file to compare a secret
+// with: a .env file, or, for .yaml, .yml, and .json files, either a Secret
+// manifest or a flat map of keys to values.
+func LoadLocalData(path string) (map[string][]byte, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // The path is chosen by the user.
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		data, err := parseYAMLData(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return data, nil
+	}
+	entries, err := parseEnvFile(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	data := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		data[entry.key] = []byte(entry.value)
+	}
+	return data, nil
+}
+
+// parseYAMLData parses a Secret manifest, merging its stringData into its data as
+// the API server does, or a flat map of keys to string values.
+func parseYAMLData(content []byte) (map[string][]byte, error) {
+	var header struct {
+		Kind string `json:"kind"`
+	}
+	if err := yaml.Unmarshal(content, &header); err != nil {
+		return nil, err
+	}
+	if header.Kind == "Secret" {
+		var secret corev1.Secret
+		if err := yaml.Unmarshal(content, &secret); err != nil {
+			return nil, err
+		}
+		data := make(map[string][]byte, len(secret.Data)+len(secret.StringData))
+		for key, value := range secret.Data {
+			data[key] = value
+		}
+		for key, value := range secret.StringData {
+			data[key] = []byte(value)
+		}
+		return data, nil
+	}
+	var values map[string]string
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("expected a Secret or a map of keys to string values: %w", err)
+	}
+	data := make(map[string][]byte, len(values))
+	for key, value := range values {
+		data[key] = []byte(value)
+	}
+	return data, nil
+}
+
+// CompareData compares the data of a secret with other data, sorted by key: keys
+// only in the secret are removed, keys only in the other data are added.
+func CompareData(secret, other map[string][]byte) []DataChange {
+	return diffData(secret, other)
+}
+
+// SecretDrift is a secret of a namespace that was created, deleted, or whose data
+// changed since a snapshot. Kind is KeyAdded for created secrets, KeyRemoved for
+// deleted ones, and KeyChanged for changed ones, with the changes of their keys.
+type SecretDrift struct {
+	Name    string
+	Kind    ChangeKind
+	Changes []DataChange
+}
+
+// LoadSnapshotFile reads the secrets saved in a snapshot file: the YAML or JSON
+// output of 'kubectl get secrets', as a List or as Secret documents, or the JSON
+// export of kds.
+func LoadSnapshotFile(path string) ([]*corev1.Secret, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // The path is chosen by the user.
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", path, err)
+	}
+	secrets, err := parseSnapshot(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return secrets, nil
+}
+
+// parseSnapshot parses the secrets of a snapshot file.
+func parseSnapshot(content []byte) ([]*corev1.Secret, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("[")) {
+		var exported []SecretData
+		if err := json.Unmarshal(content, &exported); err != nil {
+			return nil, fmt.Errorf("expected an export of kds: %w", err)
+		}
+		secrets := make([]*corev1.Secret, len(exported))
+		for i, e := range exported {
+			data := make(map[string][]byte, len(e.Data))
+			for key, value := range e.Data {
+				data[key] = []byte(value)
+			}
+			secrets[i] = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: e.Namespace, Name: e.Name}, Data: data}
+		}
+		return secrets, nil
+	}
+	var secrets []*corev1.Secret
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+	for {
+		var doc struct {
+			Kind  string           `json:"kind"`
+			Items []*corev1.Secret `json:"items"`
+			corev1.Secret
+		}
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch doc.Kind {
+		case "":
+			continue
+		case "Secret":
+			secrets = append(secrets, &doc.Secret)
+		case "List", "SecretList":
+			secrets = append(secrets, doc.Items...)
+		default:
+			return nil, fmt.Errorf("expected Secrets or a List of them, but got a %s", doc.Kind)
+		}
+	}
+	for _, secret := range secrets {
+		for key, value := range secret.StringData {
+			if secret.Data == nil {
+				secret.Data = make(map[string][]byte, len(secret.StringData))
+			}
+			secret.Data[key] = []byte(value)
+		}
+	}
+	return secrets, nil
+}
+
+// CompareSnapshot compares the secrets of a namespace with those a snapshot saved
+// of it, sorted by name. Secrets of the snapshot without a namespace are taken to
+// be of the namespace.
+func CompareSnapshot(snapshot []*corev1.Secret, live []corev1.Secret, namespace string) ([]SecretDrift, error) {
+	before := make(map[string]map[string][]byte)
+	namespaces := make(map[string]bool)
+	for _, secret := range snapshot {
+		namespaces[secret.Namespace] = true
+		if secret.Namespace == namespace || secret.Namespace == "" {
+			before[secret.Name] = secret.Data
+		}
+	}
+	if len(before) == 0 && len(snapshot) > 0 {
+		return nil, fmt.Errorf("the snapshot has no secrets of namespace '%s', only of %s", namespace, strings.Join(slices.Sorted(maps.Keys(namespaces)), ", "))
+	}
+	var drifts []SecretDrift
+	for i := range live {
+		secret := &live[i]
+		data, existed := before[secret.Name]
+		delete(before, secret.Name)
+		switch changes := diffData(data, secret.Data); {
+		case !existed:
+			drifts = append(drifts, SecretDrift{Name: secret.Name, Kind: KeyAdded, Changes: changes})
+		case len(changes) > 0:
+			drifts = append(drifts, SecretDrift{Name: secret.Name, Kind: KeyChanged, Changes: changes})
+		}
+	}
+	for name, data := range before {
+		drifts = append(drifts, SecretDrift{Name: name, Kind: KeyRemoved, Changes: diffData(data, nil)})
+	}
+	slices.SortFunc(drifts, func(a, b SecretDrift) int { return strings.Compare(a.Name, b.Name) })
+	return drifts, nil
+}
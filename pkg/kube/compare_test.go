@@ -0,0 +1,117 @@
+package kube
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestLoadLocalData verifies that .env files, Secret manifests, and flat YAML maps
+// are read as secret data.
+func TestLoadLocalData(t *testing.T) {
+	expected := map[string][]byte{"user": []byte("admin"), "password": []byte("hunter2")}
+	for name, content := range map[string]string{
+		"app.env":     "user=admin\nexport password='hunter2'\n",
+		"secret.yaml": "apiVersion: v1\nkind: Secret\nmetadata:\n  name: app\ndata:\n  user: YWRtaW4=\nstringData:\n  password: hunter2\n",
+		"flat.yml":    "user: admin\npassword: hunter2\n",
+	} {
+		t.Run("should read "+name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			data, err := LoadLocalData(path)
+			if err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+			if !reflect.DeepEqual(data, expected) {
+				t.Errorf("Expected %v, but got %v", expected, data)
+			}
+		})
+	}
+}
+
+// TestLoadSnapshotFile verifies that lists, multi-document manifests, and exports
+// of kds are read as snapshots.
+func TestLoadSnapshotFile(t *testing.T) {
+	for name, content := range map[string]string{
+		"list.yaml":   "apiVersion: v1\nkind: List\nitems:\n- apiVersion: v1\n  kind: Secret\n  metadata:\n    name: app\n    namespace: prod\n  data:\n    user: YWRtaW4=\n- apiVersion: v1\n  kind: Secret\n  metadata:\n    name: db\n    namespace: prod\n  stringData:\n    user: admin\n",
+		"multi.yaml":  "apiVersion: v1\nkind: Secret\nmetadata:\n  name: app\n  namespace: prod\ndata:\n  user: YWRtaW4=\n---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: db\n  namespace: prod\nstringData:\n  user: admin\n",
+		"export.json": `[{"namespace":"prod","name":"app","data":{"user":"admin"}},{"namespace":"prod","name":"db","data":{"user":"admin"}}]`,
+	} {
+		t.Run("should read "+name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			secrets, err := LoadSnapshotFile(path)
+			if err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+			if len(secrets) != 2 {
+				t.Fatalf("Expected 2 secrets, but got %d", len(secrets))
+			}
+			for _, secret := range secrets {
+				if secret.Namespace != "prod" || string(secret.Data["user"]) != "admin" {
+					t.Errorf("Expected secret '%s' of prod with user admin, but got %s/%s with %v", secret.Name, secret.Namespace, secret.Name, secret.Data)
+				}
+			}
+		})
+	}
+
+	t.Run("should reject other kinds", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cm.yaml")
+		if err := os.WriteFile(path, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSnapshotFile(path); err == nil {
+			t.Error("Expected an error for a ConfigMap, but got none")
+		}
+	})
+}
+
+// TestCompareSnapshot verifies that created, deleted, and changed secrets are
+// reported, and unchanged ones are not.
+func TestCompareSnapshot(t *testing.T) {
+	secret := func(namespace, name string, data map[string]string) corev1.Secret {
+		s := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Data: map[string][]byte{}}
+		for k, v := range data {
+			s.Data[k] = []byte(v)
+		}
+		return s
+	}
+	same := secret("prod", "same", map[string]string{"k": "v"})
+	changed := secret("prod", "changed", map[string]string{"password": "old", "legacy": "x"})
+	deleted := secret("", "deleted", map[string]string{"k": "v"})
+	snapshot := []*corev1.Secret{&same, &changed, &deleted}
+	live := []corev1.Secret{
+		secret("prod", "same", map[string]string{"k": "v"}),
+		secret("prod", "changed", map[string]string{"password": "new", "token": "y"}),
+		secret("prod", "created", map[string]string{"k": "v"}),
+	}
+
+	t.Run("should report created, deleted, and changed secrets", func(t *testing.T) {
+		drifts, err := CompareSnapshot(snapshot, live, "prod")
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		expected := []SecretDrift{
+			{Name: "changed", Kind: KeyChanged, Changes: []DataChange{{Key: "legacy", Kind: KeyRemoved}, {Key: "password", Kind: KeyChanged}, {Key: "token", Kind: KeyAdded}}},
+			{Name: "created", Kind: KeyAdded, Changes: []DataChange{{Key: "k", Kind: KeyAdded}}},
+			{Name: "deleted", Kind: KeyRemoved, Changes: []DataChange{{Key: "k", Kind: KeyRemoved}}},
+		}
+		if !reflect.DeepEqual(drifts, expected) {
+			t.Errorf("Expected %v, but got %v", expected, drifts)
+		}
+	})
+	t.Run("should reject a snapshot of another namespace", func(t *testing.T) {
+		other := secret("staging", "app", nil)
+		if _, err := CompareSnapshot([]*corev1.Secret{&other}, live, "prod"); err == nil {
+			t.Error("Expected an error for a snapshot of staging, but got none")
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package kube
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/metadata"
+)
+
+// completionTimeout bounds how long a shell completion request may spend querying
+// the cluster, so an unreachable API server never hangs the user's shell.
+const completionTimeout = 2 * time.Second
+
+// secretsGVR is the resource of secrets, for the metadata client.
+var secretsGVR = corev1.SchemeGroupVersion.WithResource("secrets")
+
+// ListSecretNames returns the names of all secrets in a namespace, giving up after
+// completionTimeout. Only the metadata of the secrets is listed, never their values.
+func ListSecretNames(client metadata.Interface, namespace string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	secrets, err := client.Resource(secretsGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(secrets.Items))
+	for i, secret := range secrets.Items {
+		names[i] = secret.Name
+	}
+	return names, nil
+}
+
+// ListSecretKeys returns the sorted keys of a secret, giving up after completionTimeout.
+func ListSecretKeys(clientset Client, ref SecretRef) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	secret, err := clientset.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return SortedKeys(secret.Data), nil
+}
+
+// ListServiceAccountNames returns the names of all ServiceAccounts in a namespace,
+// giving up after completionTimeout.
+func ListServiceAccountNames(clientset Client, namespace string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	accounts, err := clientset.CoreV1().ServiceAccounts(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(accounts.Items))
+	for i, account := range accounts.Items {
+		names[i] = account.Name
+	}
+	return names, nil
+}
+
+// ListNamespaceNames returns the names of all namespaces, giving up after completionTimeout.
+func ListNamespaceNames(clientset Client) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(namespaces.Items))
+	for i, ns := range namespaces.Items {
+		names[i] = ns.Name
+	}
+	return names, nil
+}
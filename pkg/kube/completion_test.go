@@ -0,0 +1,51 @@
+package kube
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+)
+
+// TestListSecretNames verifies that secret names are listed for shell completion
+// from the metadata of the secrets.
+func TestListSecretNames(t *testing.T) {
+	scheme := metadatafake.NewTestScheme()
+	if err := metav1.AddMetaToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	secret := func(namespace, name string) *metav1.PartialObjectMetadata {
+		return &metav1.PartialObjectMetadata{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		}
+	}
+	client := metadatafake.NewSimpleMetadataClient(scheme, secret("default", "app-db"), secret("default", "app-tls"), secret("kube-system", "other"))
+	names, err := ListSecretNames(client, "default")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := []string{"app-db", "app-tls"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected names %v, but got %v", expected, names)
+	}
+}
+
+// TestListSecretKeys verifies that the keys of a secret are listed for shell completion.
+func TestListSecretKeys(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-db", Namespace: "default"},
+		Data:       map[string][]byte{"username": nil, "password": nil},
+	})
+	keys, err := ListSecretKeys(clientset, SecretRef{Namespace: "default", Name: "app-db"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := []string{"password", "username"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, but got %v", expected, keys)
+	}
+}
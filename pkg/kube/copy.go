@@ -0,0 +1,82 @@
+package kube
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// lastAppliedAnnotation is set by `kubectl apply` and describes the source object,
+// so it must not be carried over to copies.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// CopyOptions holds the flags of the 'kds copy' command.
+type CopyOptions struct {
+	ToNamespace string
+	ToContext   string
+	Overwrite   bool
+}
+
+// CopySecret recreates a secret in another namespace of the same cluster.
+func CopySecret(clientset Client, ref SecretRef, toNamespace string, overwrite bool) error {
+	return CopySecretTo(clientset, clientset, ref, toNamespace, overwrite)
+}
+
+// CopySecretTo reads a secret through the source client and recreates it through the
+// target client, which may point at another cluster. If the secret already exists in
+// the target, it is replaced when overwrite is set, and an AlreadyExists error is
+// returned otherwise. A new secret that would exceed the secret quota of the target
+// namespace is refused before it is sent.
+func CopySecretTo(source, target Client, ref SecretRef, toNamespace string, overwrite bool) error {
+	secret, err := GetSecret(source, ref)
+	if err != nil {
+		return err
+	}
+	clone := cloneSecret(secret, toNamespace)
+	secrets := target.CoreV1().Secrets(toNamespace)
+	if _, err := secrets.Get(context.TODO(), clone.Name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		if err := CheckSecretQuota(target, toNamespace, 1); err != nil {
+			return err
+		}
+	}
+	_, err = secrets.Create(context.TODO(), clone, metav1.CreateOptions{FieldManager: FieldManager})
+	if !apierrors.IsAlreadyExists(err) || !overwrite {
+		return err
+	}
+	existing, err := secrets.Get(context.TODO(), clone.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	clone.ResourceVersion = existing.ResourceVersion
+	_, err = secrets.Update(context.TODO(), clone, metav1.UpdateOptions{FieldManager: FieldManager})
+	return err
+}
+
+// cloneSecret returns a copy of a secret suitable for creating in the given namespace.
+// Server-populated and cluster-specific metadata is dropped so the copy is a fresh,
+// unowned object.
+func cloneSecret(secret *corev1.Secret, namespace string) *corev1.Secret {
+	var annotations map[string]string
+	for key, value := range secret.Annotations {
+		if key == lastAppliedAnnotation {
+			continue
+		}
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[key] = value
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        secret.Name,
+			Namespace:   namespace,
+			Labels:      secret.Labels,
+			Annotations: annotations,
+		},
+		Type:      secret.Type,
+		Data:      secret.Data,
+		Immutable: secret.Immutable,
+	}
+}
@@ -0,0 +1,86 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestCopySecret verifies that copies are created without server-populated metadata.
+func TestCopySecret(t *testing.T) {
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "app-tls",
+			Namespace:       "default",
+			Labels:          map[string]string{"app": "web"},
+			ResourceVersion: "42",
+			UID:             "1234",
+			OwnerReferences: []metav1.OwnerReference{{Name: "owner"}},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{"tls.crt": []byte("cert")},
+	}
+	clientset := fake.NewSimpleClientset(source)
+	if err := CopySecret(clientset, SecretRef{Namespace: "default", Name: "app-tls"}, "staging", false); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	copied, err := clientset.CoreV1().Secrets("staging").Get(context.TODO(), "app-tls", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the copy to exist, but got: %v", err)
+	}
+	if len(copied.OwnerReferences) != 0 || copied.UID != "" {
+		t.Errorf("Expected owner references and UID to be stripped, but got %v and %q", copied.OwnerReferences, copied.UID)
+	}
+	if copied.Labels["app"] != "web" || string(copied.Data["tls.crt"]) != "cert" || copied.Type != corev1.SecretTypeTLS {
+		t.Errorf("Expected labels, data, and type to be preserved, but got %+v", copied)
+	}
+}
+
+// TestCopySecretOverwrite verifies that existing secrets are only replaced when asked to.
+func TestCopySecretOverwrite(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}, Data: map[string][]byte{"v": []byte("new")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "staging"}, Data: map[string][]byte{"v": []byte("old")}},
+	)
+	ref := SecretRef{Namespace: "default", Name: "db"}
+	t.Run("should report a conflict without overwrite", func(t *testing.T) {
+		if err := CopySecret(clientset, ref, "staging", false); !apierrors.IsAlreadyExists(err) {
+			t.Fatalf("Expected an AlreadyExists error, but got: %v", err)
+		}
+	})
+	t.Run("should replace the existing secret with overwrite", func(t *testing.T) {
+		if err := CopySecret(clientset, ref, "staging", true); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		copied, err := clientset.CoreV1().Secrets("staging").Get(context.TODO(), "db", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if string(copied.Data["v"]) != "new" {
+			t.Errorf("Expected the value to be overwritten with 'new', but got %q", copied.Data["v"])
+		}
+	})
+}
+
+// TestCopySecretTo verifies copying a secret between two clusters.
+func TestCopySecretTo(t *testing.T) {
+	source := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "ci", ResourceVersion: "7"},
+		Data:       map[string][]byte{".dockerconfigjson": []byte("{}")},
+	})
+	target := fake.NewSimpleClientset()
+	if err := CopySecretTo(source, target, SecretRef{Namespace: "ci", Name: "registry"}, "ci", false); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	copied, err := target.CoreV1().Secrets("ci").Get(context.TODO(), "registry", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the secret to exist in the target cluster, but got: %v", err)
+	}
+	if string(copied.Data[".dockerconfigjson"]) != "{}" {
+		t.Errorf("Expected the data to be copied, but got %v", copied.Data)
+	}
+}
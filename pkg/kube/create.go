@@ -0,0 +1,21 @@
+package kube
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NewSecret builds a secret with the given type and data.
+func NewSecret(namespace, name string, secretType corev1.SecretType, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Type:       secretType,
+		Data:       data,
+	}
+}
+
+// CreateSecret creates a secret, explaining the failure if it already exists.
+func CreateSecret(clientset Client, secret *corev1.Secret) error {
+	_, err := Mutation{After: secret}.Apply(clientset, false)
+	return err
+}
@@ -0,0 +1,104 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultDecoderTimeout bounds a decoder command that sets no timeout of its own.
+const defaultDecoderTimeout = 5 * time.Second
+
+// DecoderConfig declares an external command that renders values in the data pane,
+// for formats kds does not know.
+type DecoderConfig struct {
+	Name string `json:"name"`
+	// Keys is a regular expression for the keys the decoder applies to.
+	Keys string `json:"keys,omitempty"`
+	// Types lists the secret types the decoder applies to.
+	Types []string `json:"types,omitempty"`
+	// Command receives the value on stdin and prints the rendering on stdout.
+	Command []string         `json:"command"`
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// ValueDecoder is a compiled DecoderConfig.
+type ValueDecoder struct {
+	Name    string
+	keys    *regexp.Regexp // Nil to match every key.
+	types   []corev1.SecretType
+	command []string
+	timeout time.Duration
+}
+
+// CompileDecoders validates the decoders of the configuration.
+func CompileDecoders(configs []DecoderConfig) ([]ValueDecoder, error) {
+	decoders := make([]ValueDecoder, 0, len(configs))
+	for _, c := range configs {
+		if c.Name == "" || len(c.Command) == 0 {
+			return nil, fmt.Errorf("decoder '%s' needs a name and a command", c.Name)
+		}
+		if c.Keys == "" && len(c.Types) == 0 {
+			return nil, fmt.Errorf("decoder '%s' needs keys or types to apply to", c.Name)
+		}
+		d := ValueDecoder{Name: c.Name, command: c.Command, timeout: defaultDecoderTimeout}
+		if c.Keys != "" {
+			keys, err := regexp.Compile(c.Keys)
+			if err != nil {
+				return nil, fmt.Errorf("decoder '%s' has invalid keys: %w", c.Name, err)
+			}
+			d.keys = keys
+		}
+		for _, t := range c.Types {
+			d.types = append(d.types, corev1.SecretType(t))
+		}
+		if c.Timeout != nil {
+			d.timeout = c.Timeout.Duration
+		}
+		decoders = append(decoders, d)
+	}
+	return decoders, nil
+}
+
+// Matches reports whether the decoder applies to a key of a secret. When both keys
+// and types are set, both must match.
+func (d ValueDecoder) Matches(secret *corev1.Secret, key string) bool {
+	if d.keys != nil && !d.keys.MatchString(key) {
+		return false
+	}
+	return len(d.types) == 0 || slices.Contains(d.types, secret.Type)
+}
+
+// Decode runs the decoder command with the value on stdin. The command also gets the
+// location of the value in KDS_NAMESPACE, KDS_SECRET, KDS_KEY, and KDS_TYPE.
+func (d ValueDecoder) Decode(secret *corev1.Secret, key string, value []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, d.command[0], d.command[1:]...) //nolint:gosec // Decoders are declared by the user.
+	cmd.Stdin = bytes.NewReader(value)
+	cmd.Env = append(os.Environ(),
+		"KDS_NAMESPACE="+secret.Namespace,
+		"KDS_SECRET="+secret.Name,
+		"KDS_KEY="+key,
+		"KDS_TYPE="+string(secret.Type),
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
@@ -0,0 +1,20 @@
+package kube
+
+import (
+	"testing"
+)
+
+// TestCompileDecoders verifies that incomplete decoders are rejected.
+func TestCompileDecoders(t *testing.T) {
+	invalid := []DecoderConfig{
+		{Keys: "blob", Command: []string{"cat"}},
+		{Name: "acme", Keys: "blob"},
+		{Name: "acme", Command: []string{"cat"}},
+		{Name: "acme", Keys: "(", Command: []string{"cat"}},
+	}
+	for _, c := range invalid {
+		if _, err := CompileDecoders([]DecoderConfig{c}); err == nil {
+			t.Errorf("Expected an error for %+v, but got none", c)
+		}
+	}
+}
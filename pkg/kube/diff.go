@@ -0,0 +1,92 @@
+package kube
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ChangeKind is the kind of change made to a single key of a secret.
+type ChangeKind string
+
+const (
+	KeyAdded   ChangeKind = "+"
+	KeyChanged ChangeKind = "~"
+	KeyRemoved ChangeKind = "-"
+)
+
+// DataChange is a change to a single key of a secret's data.
+type DataChange struct {
+	Key  string
+	Kind ChangeKind
+}
+
+// diffData compares the data of a secret before and after a change, sorted by key.
+func diffData(before, after map[string][]byte) []DataChange {
+	var changes []DataChange
+	for key, value := range after {
+		old, exists := before[key]
+		switch {
+		case !exists:
+			changes = append(changes, DataChange{Key: key, Kind: KeyAdded})
+		case !bytes.Equal(old, value):
+			changes = append(changes, DataChange{Key: key, Kind: KeyChanged})
+		}
+	}
+	for key := range before {
+		if _, exists := after[key]; !exists {
+			changes = append(changes, DataChange{Key: key, Kind: KeyRemoved})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// SummarizeDataDiff describes changes on a single line, e.g. "+2 ~1 -0".
+func SummarizeDataDiff(changes []DataChange) string {
+	counts := map[ChangeKind]int{}
+	for _, change := range changes {
+		counts[change.Kind]++
+	}
+	return fmt.Sprintf("+%d ~%d -%d", counts[KeyAdded], counts[KeyChanged], counts[KeyRemoved])
+}
+
+// DiffSecrets compares two versions of a secret field by field: data keys, labels,
+// annotations, type, and the immutable flag. A nil before stands for a new secret.
+func DiffSecrets(before, after *corev1.Secret) []DataChange {
+	if before == nil {
+		before = &corev1.Secret{}
+	}
+	changes := prefixChanges("data.", diffData(before.Data, after.Data))
+	changes = append(changes, prefixChanges("metadata.labels.", diffData(stringsToBytes(before.Labels), stringsToBytes(after.Labels)))...)
+	changes = append(changes, prefixChanges("metadata.annotations.", diffData(stringsToBytes(before.Annotations), stringsToBytes(after.Annotations)))...)
+	switch {
+	case before.Type == "" && after.Type != "":
+		changes = append(changes, DataChange{Key: "type", Kind: KeyAdded})
+	case before.Type != after.Type:
+		changes = append(changes, DataChange{Key: "type", Kind: KeyChanged})
+	}
+	if isImmutable(before) != isImmutable(after) {
+		changes = append(changes, DataChange{Key: "immutable", Kind: KeyChanged})
+	}
+	return changes
+}
+
+// prefixChanges prefixes the keys of changes with the path of their field.
+func prefixChanges(prefix string, changes []DataChange) []DataChange {
+	for i := range changes {
+		changes[i].Key = prefix + changes[i].Key
+	}
+	return changes
+}
+
+// stringsToBytes converts label or annotation values so they can be compared with diffData.
+func stringsToBytes(values map[string]string) map[string][]byte {
+	converted := make(map[string][]byte, len(values))
+	for key, value := range values {
+		converted[key] = []byte(value)
+	}
+	return converted
+}
@@ -0,0 +1,50 @@
+package kube
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDiffData verifies the detection of added, changed, and removed keys.
+func TestDiffData(t *testing.T) {
+	before := map[string][]byte{"host": []byte("db"), "user": []byte("admin"), "old": []byte("x")}
+	after := map[string][]byte{"host": []byte("db"), "user": []byte("root"), "port": []byte("5432")}
+
+	changes := diffData(before, after)
+	expected := []DataChange{{"old", KeyRemoved}, {"port", KeyAdded}, {"user", KeyChanged}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v, but got %v", expected, changes)
+	}
+	if summary := SummarizeDataDiff(changes); summary != "+1 ~1 -1" {
+		t.Errorf("Expected summary '+1 ~1 -1', but got '%s'", summary)
+	}
+}
+
+// TestDiffSecrets verifies the field-level comparison of two versions of a secret.
+func TestDiffSecrets(t *testing.T) {
+	immutable := true
+	before := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "a"}},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{"key": []byte("v1")},
+	}
+	after := before.DeepCopy()
+	after.Labels["team"] = "b"
+	after.Annotations = map[string]string{"owner": "alice"}
+	after.Data["key"] = []byte("v2")
+	after.Immutable = &immutable
+
+	changes := DiffSecrets(before, after)
+	expected := []DataChange{
+		{"data.key", KeyChanged},
+		{"metadata.labels.team", KeyChanged},
+		{"metadata.annotations.owner", KeyAdded},
+		{"immutable", KeyChanged},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v, but got %v", expected, changes)
+	}
+}
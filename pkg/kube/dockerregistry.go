@@ -0,0 +1,103 @@
+package kube
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultRegistryServer is the Docker Hub registry, used when no server is given.
+const DefaultRegistryServer = "https://index.docker.io/v1/"
+
+// RegistryCredentials are the login details stored in a docker-registry secret.
+type RegistryCredentials struct {
+	Server   string
+	Username string
+	Password string
+	Email    string
+}
+
+// dockerConfigEntry is a single registry login in a .dockerconfigjson file.
+type dockerConfigEntry struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Email    string `json:"email,omitempty"`
+	Auth     string `json:"auth"`
+}
+
+// dockerConfigJSON is the content of a .dockerconfigjson file.
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+// buildDockerConfigJSON encodes registry credentials the way `kubectl create
+// secret docker-registry` does.
+func buildDockerConfigJSON(creds RegistryCredentials) ([]byte, error) {
+	auth := base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))
+	return json.Marshal(dockerConfigJSON{Auths: map[string]dockerConfigEntry{
+		creds.Server: {Username: creds.Username, Password: creds.Password, Email: creds.Email, Auth: auth},
+	}})
+}
+
+// BuildDockerRegistrySecret builds a kubernetes.io/dockerconfigjson secret.
+func BuildDockerRegistrySecret(namespace, name string, creds RegistryCredentials) (*corev1.Secret, error) {
+	config, err := buildDockerConfigJSON(creds)
+	if err != nil {
+		return nil, err
+	}
+	return NewSecret(namespace, name, corev1.SecretTypeDockerConfigJson, map[string][]byte{corev1.DockerConfigJsonKey: config}), nil
+}
+
+// createDockerRegistrySecret creates a kubernetes.io/dockerconfigjson secret and,
+// if serviceAccount is set, adds it to that service account's imagePullSecrets.
+func createDockerRegistrySecret(clientset Client, namespace, name string, creds RegistryCredentials, serviceAccount string) error {
+	secret, err := BuildDockerRegistrySecret(namespace, name, creds)
+	if err != nil {
+		return err
+	}
+	if err := CreateSecret(clientset, secret); err != nil {
+		return err
+	}
+	if serviceAccount == "" {
+		return nil
+	}
+	return LinkServiceAccount(clientset, secret, serviceAccount)
+}
+
+// LinkServiceAccount adds a newly created secret to a service account's imagePullSecrets.
+func LinkServiceAccount(clientset Client, secret *corev1.Secret, serviceAccount string) error {
+	if err := addImagePullSecret(clientset, secret.Namespace, serviceAccount, secret.Name); err != nil {
+		return fmt.Errorf("created secret '%s/%s' but failed to update service account '%s': %w", secret.Namespace, secret.Name, serviceAccount, err)
+	}
+	return nil
+}
+
+// addImagePullSecret appends a secret to a service account's imagePullSecrets with
+// a JSON patch, unless it is already listed.
+func addImagePullSecret(clientset Client, namespace, serviceAccount, secretName string) error {
+	accounts := clientset.CoreV1().ServiceAccounts(namespace)
+	sa, err := accounts.Get(context.TODO(), serviceAccount, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	ref := corev1.LocalObjectReference{Name: secretName}
+	if slices.Contains(sa.ImagePullSecrets, ref) {
+		return nil
+	}
+	op := jsonPatchOp{Op: "add", Path: "/imagePullSecrets/-", Value: ref}
+	if sa.ImagePullSecrets == nil {
+		op = jsonPatchOp{Op: "add", Path: "/imagePullSecrets", Value: []corev1.LocalObjectReference{ref}}
+	}
+	patch, err := json.Marshal([]jsonPatchOp{op})
+	if err != nil {
+		return err
+	}
+	_, err = accounts.Patch(context.TODO(), serviceAccount, types.JSONPatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	return err
+}
@@ -0,0 +1,52 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestBuildDockerConfigJSON verifies the encoding of registry credentials.
+func TestBuildDockerConfigJSON(t *testing.T) {
+	data, err := buildDockerConfigJSON(RegistryCredentials{Server: "ghcr.io", Username: "bot", Password: "t0ken"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := `{"auths":{"ghcr.io":{"username":"bot","password":"t0ken","auth":"Ym90OnQwa2Vu"}}}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, but got %s", expected, data)
+	}
+}
+
+// TestCreateDockerRegistrySecret verifies the created secret and the service account patch.
+func TestCreateDockerRegistrySecret(t *testing.T) {
+	creds := RegistryCredentials{Server: "ghcr.io", Username: "bot", Password: "t0ken"}
+	for _, existing := range [][]corev1.LocalObjectReference{nil, {{Name: "other"}}} {
+		clientset := fake.NewSimpleClientset(&corev1.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Name: "default", Namespace: "default"},
+			ImagePullSecrets: existing,
+		})
+		t.Run("should add the secret to a service account with "+strings.Repeat("one ", len(existing))+"pull secret(s)", func(t *testing.T) {
+			if err := createDockerRegistrySecret(clientset, "default", "ghcr", creds, "default"); err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			secret, err := clientset.CoreV1().Secrets("default").Get(context.TODO(), "ghcr", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected the secret to be created, but got: %v", err)
+			}
+			var config dockerConfigJSON
+			if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil || config.Auths["ghcr.io"].Username != "bot" {
+				t.Errorf("Expected a .dockerconfigjson for ghcr.io, but got %s (%v)", secret.Data[corev1.DockerConfigJsonKey], err)
+			}
+			sa, _ := clientset.CoreV1().ServiceAccounts("default").Get(context.TODO(), "default", metav1.GetOptions{})
+			if len(sa.ImagePullSecrets) != len(existing)+1 || sa.ImagePullSecrets[len(existing)].Name != "ghcr" {
+				t.Errorf("Expected 'ghcr' to be appended to the imagePullSecrets, but got %v", sa.ImagePullSecrets)
+			}
+		})
+	}
+}
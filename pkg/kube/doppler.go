@@ -0,0 +1,36 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// dopplerMetaKeys are added by 'doppler secrets download' to every config.
+var dopplerMetaKeys = []string{"DOPPLER_CONFIG", "DOPPLER_ENVIRONMENT", "DOPPLER_PROJECT"}
+
+// pullDoppler reads the secrets of a Doppler config with 'doppler secrets
+// download', authenticated by 'doppler login' or DOPPLER_TOKEN. The item is the
+// config, as project/config, or as config of the project of the store, its vault.
+func (s SecretStore) pullDoppler(ctx context.Context, item StoreItem) (map[string][]byte, error) {
+	project, config := s.vault, item.Item
+	if i := strings.LastIndex(item.Item, "/"); i >= 0 {
+		project, config = item.Item[:i], item.Item[i+1:]
+	}
+	if project == "" || config == "" {
+		return nil, fmt.Errorf("item '%s' is not a Doppler config: use <project>/<config>, or set the vault of the store to the project", item.Item)
+	}
+	out, err := s.run(ctx, []string{"doppler", "secrets", "download", "--no-file", "--format", "json", "--project", project, "--config", config}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]string
+	if err := json.Unmarshal(out, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse the output of doppler: %w", err)
+	}
+	for _, key := range dopplerMetaKeys {
+		delete(values, key)
+	}
+	return byteData(values), nil
+}
@@ -0,0 +1,44 @@
+package kube
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// envEntry is a single KEY=VALUE assignment from a dotenv file.
+type envEntry struct {
+	key   string
+	value string
+}
+
+// parseEnvFile parses a dotenv file. Blank lines and '#' comments are skipped, an
+// optional "export " prefix is allowed, and values may be wrapped in single or
+// double quotes, which are removed. Entries are returned in file order.
+func parseEnvFile(data []byte) ([]envEntry, error) {
+	var entries []envEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNo)
+		}
+		entries = append(entries, envEntry{key: key, value: unquote(strings.TrimSpace(value))})
+	}
+	return entries, scanner.Err()
+}
+
+// unquote removes a pair of matching single or double quotes around a value.
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,26 @@
+package kube
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseEnvFile verifies parsing of dotenv files.
+func TestParseEnvFile(t *testing.T) {
+	t.Run("should parse assignments, skipping comments and removing quotes", func(t *testing.T) {
+		content := "# database\nDB_HOST=db.local\n\nexport DB_USER = 'admin'\nDB_PASS=\"p=ss word\"\nEMPTY=\n"
+		entries, err := parseEnvFile([]byte(content))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		expected := []envEntry{{"DB_HOST", "db.local"}, {"DB_USER", "admin"}, {"DB_PASS", "p=ss word"}, {"EMPTY", ""}}
+		if !reflect.DeepEqual(entries, expected) {
+			t.Errorf("Expected %v, but got %v", expected, entries)
+		}
+	})
+	t.Run("should reject a line without an assignment", func(t *testing.T) {
+		if _, err := parseEnvFile([]byte("A=1\nNOT_AN_ASSIGNMENT\n")); err == nil {
+			t.Error("Expected an error, but got nil")
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package kube
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ManifestSecret is a secret declared in the manifests of a GitOps repository, as
+// a Secret, possibly encrypted with SOPS, or as a SealedSecret.
+type ManifestSecret struct {
+	Ref  SecretRef
+	Path string // The file declaring it.
+	// Data holds the values of the secret. For SealedSecrets, whose values can only
+	// be decrypted by their controller, it holds the keys with empty values.
+	Data   map[string][]byte
+	Sealed bool
+}
+
+// ManifestDrift is a secret of the cluster that differs from its manifest. The
+// changes go from the manifest to the cluster, so KeyAdded is a key only in the
+// cluster. The values of sealed secrets are not compared, only their keys.
+type ManifestDrift struct {
+	Manifest ManifestSecret
+	Missing  bool // The secret is not in the cluster.
+	Changes  []DataChange
+}
+
+// LoadManifestSecrets reads the secrets declared in the YAML and JSON files under
+// dir: Secrets, decrypted with decrypt if SOPS encrypted them, and SealedSecrets.
+// Secrets without a namespace are taken to be of namespace. Files that are not
+// Kubernetes manifests, such as templates, are skipped with a warning, as are
+// secrets declared twice.
+func LoadManifestSecrets(dir, namespace string, decrypt Decrypter) ([]ManifestSecret, []string, error) {
+	var secrets []ManifestSecret
+	var warnings []string
+	declared := make(map[SecretRef]string)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && path != dir && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if ext := filepath.Ext(path); entry.IsDir() || ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			return nil
+		}
+		found, err := loadManifestFile(path, namespace, decrypt)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("skipped %s: %v", path, err))
+			return nil
+		}
+		for _, secret := range found {
+			if first, seen := declared[secret.Ref]; seen {
+				warnings = append(warnings, fmt.Sprintf("secret '%s' is declared again in %s, keeping %s", secret.Ref, path, first))
+				continue
+			}
+			declared[secret.Ref] = path
+			secrets = append(secrets, secret)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read manifests: %w", err)
+	}
+	return secrets, warnings, nil
+}
+
+// manifestDocument holds the fields of a Secret or a SealedSecret manifest.
+type manifestDocument struct {
+	Kind       string            `json:"kind"`
+	Metadata   metav1.ObjectMeta `json:"metadata"`
+	Data       map[string][]byte `json:"data"`
+	StringData map[string]string `json:"stringData"`
+	Spec       struct {
+		EncryptedData map[string]string `json:"encryptedData"`
+	} `json:"spec"`
+}
+
+// loadManifestFile reads the secrets declared in a file, decrypting it first if
+// SOPS encrypted it.
+func loadManifestFile(path, namespace string, decrypt Decrypter) ([]ManifestSecret, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // The path is under the directory chosen by the user.
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := sopsEncrypted(content)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		if content, err = decrypt(path); err != nil {
+			return nil, err
+		}
+	}
+	docs, err := decodeDocuments[manifestDocument](content)
+	if err != nil {
+		return nil, err
+	}
+	var secrets []ManifestSecret
+	for _, doc := range docs {
+		secret := ManifestSecret{Ref: SecretRef{Namespace: doc.Metadata.Namespace, Name: doc.Metadata.Name}, Path: path}
+		if secret.Ref.Namespace == "" {
+			secret.Ref.Namespace = namespace
+		}
+		switch doc.Kind {
+		case "Secret":
+			secret.Data = make(map[string][]byte, len(doc.Data)+len(doc.StringData))
+			for key, value := range doc.Data {
+				secret.Data[key] = value
+			}
+			for key, value := range doc.StringData {
+				secret.Data[key] = []byte(value)
+			}
+		case "SealedSecret":
+			secret.Sealed = true
+			secret.Data = make(map[string][]byte, len(doc.Spec.EncryptedData))
+			for key := range doc.Spec.EncryptedData {
+				secret.Data[key] = nil
+			}
+		default:
+			continue
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, nil
+}
+
+// sopsEncrypted reports whether SOPS encrypted a Secret or a SealedSecret of a
+// file, whose values then cannot be decoded before the file is decrypted.
+// The top-level sops key holds the metadata SOPS adds to the files it encrypts.
+func sopsEncrypted(content []byte) (bool, error) {
+	docs, err := decodeDocuments[struct {
+		Kind string `json:"kind"`
+		SOPS any    `json:"sops"`
+	}](content)
+	if err != nil {
+		return false, err
+	}
+	for _, doc := range docs {
+		if doc.SOPS != nil && (doc.Kind == "Secret" || doc.Kind == "SealedSecret") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// decodeDocuments decodes the documents of a YAML or JSON file.
+func decodeDocuments[T any](content []byte) ([]T, error) {
+	var docs []T
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+	for {
+		var doc T
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// CompareManifests compares the secrets of the cluster with their manifests, and
+// returns those that differ: missing from the cluster, or with different keys or
+// values. Only the keys of sealed secrets are compared.
+func CompareManifests(clientset Client, manifests []ManifestSecret) ([]ManifestDrift, error) {
+	var drifts []ManifestDrift
+	for _, manifest := range manifests {
+		live, err := GetSecret(clientset, manifest.Ref)
+		if apierrors.IsNotFound(err) {
+			drifts = append(drifts, ManifestDrift{Manifest: manifest, Missing: true})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		data := live.Data
+		if manifest.Sealed {
+			data = make(map[string][]byte, len(live.Data))
+			for key := range live.Data {
+				data[key] = nil
+			}
+		}
+		if changes := diffData(manifest.Data, data); len(changes) > 0 {
+			drifts = append(drifts, ManifestDrift{Manifest: manifest, Changes: changes})
+		}
+	}
+	return drifts, nil
+}
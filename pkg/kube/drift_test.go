@@ -0,0 +1,97 @@
+package kube
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestLoadManifestSecrets verifies that plain, SOPS-encrypted, and sealed secrets
+// are read from a directory of manifests, and other files are left out.
+func TestLoadManifestSecrets(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"apps/db.yaml":          "apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\ndata:\n  password: aHVudGVyMg==\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+		"apps/api.sops.yaml":    "apiVersion: v1\nkind: Secret\nmetadata:\n  name: api\n  namespace: prod\ndata:\n  token: ENC[AES256_GCM,data:xyz,type:str]\nsops:\n  version: 3.8.1\n",
+		"apps/tls.sealed.yaml":  "apiVersion: bitnami.com/v1alpha1\nkind: SealedSecret\nmetadata:\n  name: tls\nspec:\n  encryptedData:\n    tls.crt: AgBy8hC...\n    tls.key: AgCx9...\n",
+		"charts/templates.yaml": "apiVersion: v1\nkind: Secret\ndata: {{ .Values.data }}\n",
+		".git/config.yaml":      "kind: Secret\nmetadata:\n  name: ignored\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	decrypt := func(path string) ([]byte, error) {
+		if filepath.Base(path) != "api.sops.yaml" {
+			return nil, errors.New("unexpected decryption of " + path)
+		}
+		return []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: api\n  namespace: prod\nstringData:\n  token: s3cr3t\n"), nil
+	}
+
+	secrets, warnings, err := LoadManifestSecrets(dir, "default", decrypt)
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	t.Run("should read plain, SOPS-encrypted, and sealed secrets", func(t *testing.T) {
+		got := make(map[SecretRef]map[string][]byte)
+		for _, secret := range secrets {
+			got[secret.Ref] = secret.Data
+		}
+		expected := map[SecretRef]map[string][]byte{
+			{Namespace: "prod", Name: "api"}:    {"token": []byte("s3cr3t")},
+			{Namespace: "default", Name: "db"}:  {"password": []byte("hunter2")},
+			{Namespace: "default", Name: "tls"}: {"tls.crt": nil, "tls.key": nil},
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %v, but got %v", expected, got)
+		}
+	})
+	t.Run("should warn about files that are not manifests", func(t *testing.T) {
+		if len(warnings) != 1 {
+			t.Errorf("Expected a warning about the template, but got %v", warnings)
+		}
+	})
+}
+
+// TestCompareManifests verifies that missing secrets and differing keys are
+// reported, and only the keys of sealed secrets are compared.
+func TestCompareManifests(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "db"}, Data: map[string][]byte{"password": []byte("changed"), "user": []byte("admin")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "tls"}, Data: map[string][]byte{"tls.crt": []byte("a"), "tls.key": []byte("b")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "same"}, Data: map[string][]byte{"k": []byte("v")}},
+	)
+	manifests := []ManifestSecret{
+		{Ref: SecretRef{Namespace: "prod", Name: "db"}, Data: map[string][]byte{"password": []byte("hunter2"), "user": []byte("admin")}},
+		{Ref: SecretRef{Namespace: "prod", Name: "tls"}, Data: map[string][]byte{"tls.crt": nil, "tls.key": nil}, Sealed: true},
+		{Ref: SecretRef{Namespace: "prod", Name: "same"}, Data: map[string][]byte{"k": []byte("v")}},
+		{Ref: SecretRef{Namespace: "prod", Name: "api"}, Data: map[string][]byte{"token": []byte("x")}},
+	}
+
+	drifts, err := CompareManifests(clientset, manifests)
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	t.Run("should report a changed key and a missing secret", func(t *testing.T) {
+		if len(drifts) != 2 {
+			t.Fatalf("Expected 2 drifted secrets, but got %v", drifts)
+		}
+		if expected := []DataChange{{Key: "password", Kind: KeyChanged}}; !reflect.DeepEqual(drifts[0].Changes, expected) {
+			t.Errorf("Expected changes %v, but got %v", expected, drifts[0].Changes)
+		}
+		if drifts[1].Manifest.Ref.Name != "api" || !drifts[1].Missing {
+			t.Errorf("Expected api to be missing, but got %v", drifts[1])
+		}
+	})
+}
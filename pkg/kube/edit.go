@@ -0,0 +1,104 @@
+package kube
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// PrepareEdit fetches a secret and writes its decoded values to a temporary YAML
+// file, refusing secrets whose data cannot be edited. Generated values are filled
+// in over the current ones, to be reviewed in the editor.
+func PrepareEdit(clientset Client, ref SecretRef, generated map[string][]byte) (*corev1.Secret, string, error) {
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return nil, "", err
+	}
+	if isImmutable(secret) {
+		return nil, "", ErrImmutable
+	}
+	values := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		if !utf8.Valid(value) {
+			return nil, "", fmt.Errorf("key '%s' holds binary data, which cannot be edited as text", key)
+		}
+		values[key] = string(value)
+	}
+	for key, value := range generated {
+		values[key] = string(value)
+	}
+	content, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, "", err
+	}
+	header := fmt.Sprintf("# Editing secret '%s'. Remove a key to delete it; save an empty file to cancel.\n", ref)
+	if len(generated) > 0 {
+		header += fmt.Sprintf("# Generated values are filled in for: %s.\n", strings.Join(SortedKeys(generated), ", "))
+	}
+
+	file, err := os.CreateTemp("", "kds-edit-*.yaml")
+	if err != nil {
+		return nil, "", err
+	}
+	_, err = file.WriteString(header + string(content))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, "", errors.Join(err, os.Remove(file.Name()))
+	}
+	return secret, file.Name(), nil
+}
+
+// ReadEdit reads back and removes the edited file. It returns the update of the
+// secret, or false if the values are unchanged or the file was emptied.
+func ReadEdit(secret *corev1.Secret, path string, editorErr error) (Mutation, bool, error) {
+	content, err := os.ReadFile(path)
+	if removeErr := os.Remove(path); err == nil {
+		err = removeErr
+	}
+	if editorErr != nil {
+		return Mutation{}, false, fmt.Errorf("editor failed: %w", editorErr)
+	}
+	if err != nil {
+		return Mutation{}, false, err
+	}
+	if len(bytes.TrimSpace(stripComments(content))) == 0 {
+		return Mutation{}, false, nil
+	}
+	var values map[string]string
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return Mutation{}, false, fmt.Errorf("invalid YAML: %w", err)
+	}
+	data := make(map[string][]byte, len(values))
+	for key, value := range values {
+		if err := ValidateKey(key); err != nil {
+			return Mutation{}, false, err
+		}
+		data[key] = []byte(value)
+	}
+	if maps.EqualFunc(data, secret.Data, bytes.Equal) {
+		return Mutation{}, false, nil
+	}
+	edited := secret.DeepCopy()
+	edited.Data = data
+	return Mutation{Before: secret, After: edited}, true, nil
+}
+
+// stripComments removes full-line '#' comments from YAML content.
+func stripComments(content []byte) []byte {
+	var out bytes.Buffer
+	for line := range bytes.Lines(content) {
+		if !bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			out.Write(line)
+		}
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,88 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestEditSecret verifies preparing and applying an edit of a secret's values.
+func TestEditSecret(t *testing.T) {
+	ref := SecretRef{Namespace: "default", Name: "app-db"}
+	newClientset := func(immutable bool) *fake.Clientset {
+		return fake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-db", Namespace: "default"},
+			Data:       map[string][]byte{"password": []byte("s3cr3t"), "user": []byte("admin")},
+			Immutable:  &immutable,
+		})
+	}
+	edit := func(t *testing.T, clientset *fake.Clientset, content string) bool {
+		t.Helper()
+		secret, path, err := PrepareEdit(clientset, ref, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write edit file: %v", err)
+		}
+		mu, changed, err := ReadEdit(secret, path, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if changed {
+			if _, err := mu.Apply(clientset, false); err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+		}
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected the edit file to be removed, but got: %v", err)
+		}
+		return changed
+	}
+
+	t.Run("should save changed, added, and removed keys", func(t *testing.T) {
+		clientset := newClientset(false)
+		if !edit(t, clientset, "password: n3w\nhost: db\n") {
+			t.Fatal("Expected the secret to be changed, but it was not")
+		}
+		secret, _ := clientset.CoreV1().Secrets("default").Get(context.TODO(), "app-db", metav1.GetOptions{})
+		if len(secret.Data) != 2 || string(secret.Data["password"]) != "n3w" || string(secret.Data["host"]) != "db" {
+			t.Errorf("Expected {password: n3w, host: db}, but got %v", secret.Data)
+		}
+	})
+
+	t.Run("should cancel when the file is unchanged or empty", func(t *testing.T) {
+		clientset := newClientset(false)
+		if edit(t, clientset, "# comment only\n") {
+			t.Error("Expected an empty file to cancel the edit, but the secret was changed")
+		}
+		if edit(t, clientset, "user: admin\npassword: s3cr3t\n") {
+			t.Error("Expected unchanged values to cancel the edit, but the secret was changed")
+		}
+	})
+
+	t.Run("should fill in generated values", func(t *testing.T) {
+		secret, path, err := PrepareEdit(newClientset(false), ref, map[string][]byte{"password": []byte("g3n3rated")})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		mu, changed, err := ReadEdit(secret, path, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if !changed || string(mu.After.Data["password"]) != "g3n3rated" || string(mu.After.Data["user"]) != "admin" {
+			t.Errorf("Expected the generated password to be saved as is, but got %q", mu.After.Data)
+		}
+	})
+
+	t.Run("should refuse to edit an immutable secret", func(t *testing.T) {
+		if _, _, err := PrepareEdit(newClientset(true), ref, nil); !errors.Is(err, ErrImmutable) {
+			t.Errorf("Expected errImmutable, but got: %v", err)
+		}
+	})
+}
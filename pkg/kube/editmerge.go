@@ -0,0 +1,131 @@
+package kube
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// MergedKey is a key of a secret changed in an edit, on the server while the edit
+// was open, or both. Changes are from the secret the edit started from.
+type MergedKey struct {
+	Key      string
+	Mine     ChangeKind // Empty if the edit left the key unchanged.
+	Theirs   ChangeKind // Empty if the server has the key unchanged.
+	Conflict bool       // Both changed the key, to different values.
+}
+
+// EditMerge is an edit rebased on the changes made to the secret on the server
+// while it was being edited. Keys changed on one side only are merged; conflicting
+// keys keep the edited value until resolved.
+type EditMerge struct {
+	Keys     []MergedKey
+	Mutation Mutation // From the secret on the server to the merged values.
+	mine     map[string][]byte
+	theirs   map[string][]byte
+}
+
+// MergeEdit checks whether a secret changed on the server while it was edited,
+// and if so returns the three-way merge of the secret the edit started from (the
+// base), the edited values (mine), and the values on the server (theirs). It
+// returns nil if the secret is unchanged.
+func MergeEdit(clientset Client, mu Mutation) (*EditMerge, error) {
+	current, err := GetSecret(clientset, mu.Ref())
+	if err != nil {
+		return nil, err
+	}
+	if current.ResourceVersion == mu.Before.ResourceVersion {
+		return nil, nil
+	}
+	if isImmutable(current) {
+		return nil, ErrImmutable
+	}
+	base, mine, theirs := mu.Before.Data, mu.After.Data, current.Data
+	merge := &EditMerge{mine: mine, theirs: theirs}
+	merged := current.DeepCopy()
+	merged.Data = make(map[string][]byte, len(theirs))
+	keys := make(map[string]bool)
+	for _, data := range []map[string][]byte{base, mine, theirs} {
+		for key := range data {
+			keys[key] = true
+		}
+	}
+	for _, key := range SortedKeys(keys) {
+		k := MergedKey{Key: key, Mine: changeOf(base, mine, key), Theirs: changeOf(base, theirs, key)}
+		value, found := mine[key]
+		if k.Mine == "" {
+			value, found = theirs[key]
+		}
+		if k.Mine != "" && k.Theirs != "" {
+			theirValue, theirFound := theirs[key]
+			k.Conflict = found != theirFound || !bytes.Equal(value, theirValue)
+		}
+		if found {
+			merged.Data[key] = value
+		}
+		if k.Mine != "" || k.Theirs != "" {
+			merge.Keys = append(merge.Keys, k)
+		}
+	}
+	merge.Mutation = Mutation{Before: current, After: merged, Force: mu.Force}
+	return merge, nil
+}
+
+// changeOf returns how a key changed from before to after, or "" if it did not.
+func changeOf(before, after map[string][]byte, key string) ChangeKind {
+	old, existed := before[key]
+	value, exists := after[key]
+	switch {
+	case !existed && exists:
+		return KeyAdded
+	case existed && !exists:
+		return KeyRemoved
+	case existed && !bytes.Equal(old, value):
+		return KeyChanged
+	}
+	return ""
+}
+
+// Conflicts returns the keys changed both in the edit and on the server.
+func (m *EditMerge) Conflicts() []string {
+	var keys []string
+	for _, k := range m.Keys {
+		if k.Conflict {
+			keys = append(keys, k.Key)
+		}
+	}
+	return keys
+}
+
+// Resolve settles a conflicting key with the edited value, or with the value on
+// the server.
+func (m *EditMerge) Resolve(key string, keepMine bool) {
+	source := m.theirs
+	if keepMine {
+		source = m.mine
+	}
+	if value, found := source[key]; found {
+		m.Mutation.After.Data[key] = value
+	} else {
+		delete(m.Mutation.After.Data, key)
+	}
+}
+
+// String renders the merged keys as a three-way diff, without values: how each
+// key changed in the edit and on the server.
+func (m *EditMerge) String() string {
+	var b strings.Builder
+	width := len("key")
+	for _, k := range m.Keys {
+		width = max(width, len(k.Key))
+	}
+	fmt.Fprintf(&b, "  %-*s  mine  theirs\n", width, "key")
+	for _, k := range m.Keys {
+		line := fmt.Sprintf("  %-*s  %-4s  %-6s", width, k.Key, k.Mine, k.Theirs)
+		if k.Conflict {
+			line += "  conflict"
+		}
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package kube
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestMergeEdit verifies that an edit is merged with the changes made on the
+// server while it was open, and that keys changed on both sides are conflicts.
+func TestMergeEdit(t *testing.T) {
+	secret := func(version string, data map[string]string) *corev1.Secret {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", ResourceVersion: version}, Data: map[string][]byte{}}
+		for key, value := range data {
+			s.Data[key] = []byte(value)
+		}
+		return s
+	}
+	base := secret("1", map[string]string{"user": "admin", "password": "old", "host": "db", "port": "5432"})
+	mine := secret("1", map[string]string{"user": "root", "password": "mine", "host": "db", "port": "5432"})
+	theirs := secret("2", map[string]string{"user": "admin", "password": "theirs", "port": "5433", "tls": "true"})
+	mu := Mutation{Before: base, After: mine}
+
+	t.Run("should return nothing when the secret is unchanged", func(t *testing.T) {
+		merge, err := MergeEdit(fake.NewSimpleClientset(base), mu)
+		if err != nil || merge != nil {
+			t.Errorf("Expected no merge, but got %v (error: %v)", merge, err)
+		}
+	})
+
+	merge, err := MergeEdit(fake.NewSimpleClientset(theirs), mu)
+	if err != nil || merge == nil {
+		t.Fatalf("Expected a merge, but got error: %v", err)
+	}
+	t.Run("should merge the keys changed on one side", func(t *testing.T) {
+		got := merge.Mutation.After.Data
+		want := map[string]string{"user": "root", "password": "mine", "port": "5433", "tls": "true"}
+		if len(got) != len(want) {
+			t.Errorf("Expected %v, but got %v", want, got)
+		}
+		for key, value := range want {
+			if string(got[key]) != value {
+				t.Errorf("Expected %s to be %q, but got %q", key, value, got[key])
+			}
+		}
+		if merge.Mutation.Before.ResourceVersion != "2" {
+			t.Errorf("Expected the merge to apply to the secret on the server, but got version %s", merge.Mutation.Before.ResourceVersion)
+		}
+	})
+	t.Run("should report the conflicts and the three-way diff", func(t *testing.T) {
+		if got := strings.Join(merge.Conflicts(), ","); got != "password" {
+			t.Errorf("Expected password to conflict, but got %q", got)
+		}
+		diff := merge.String()
+		for _, want := range []string{"password  ~     ~       conflict\n", "host            -\n", "user      ~\n"} {
+			if !strings.Contains(diff, want) {
+				t.Errorf("Expected the diff to contain %q, but got:\n%s", want, diff)
+			}
+		}
+	})
+	t.Run("should resolve conflicts with either value", func(t *testing.T) {
+		merge.Resolve("password", false)
+		if got := string(merge.Mutation.After.Data["password"]); got != "theirs" {
+			t.Errorf("Expected their password, but got %q", got)
+		}
+		merge.Resolve("password", true)
+		if got := string(merge.Mutation.After.Data["password"]); got != "mine" {
+			t.Errorf("Expected my password, but got %q", got)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package kube
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// EnvOptions controls how the keys of a secret are named as environment variables.
+type EnvOptions struct {
+	// Prefix is prepended to every name, e.g. APP_.
+	Prefix string
+	// Lowercase keeps names in lower case instead of upper case.
+	Lowercase bool
+	// AllowBinary accepts values with NUL bytes, for variables that hold the path
+	// of a file with the value rather than the value itself.
+	AllowBinary bool
+}
+
+// EnvVar is a key of a secret exposed as an environment variable.
+type EnvVar struct {
+	Name  string
+	Key   string
+	Value []byte
+}
+
+// EnvName turns a key into an environment variable name: characters other than
+// letters, digits, and underscores become underscores, and a name that would start
+// with a digit gets a leading underscore.
+func EnvName(key string, opts EnvOptions) string {
+	name := []byte(opts.Prefix + key)
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			name[i] = '_'
+		}
+	}
+	s := string(name)
+	if s != "" && s[0] >= '0' && s[0] <= '9' {
+		s = "_" + s
+	}
+	if opts.Lowercase {
+		return strings.ToLower(s)
+	}
+	return strings.ToUpper(s)
+}
+
+// EnvVars returns the keys of a secret as environment variables, sorted by name.
+// It fails if two keys map to the same name, or, unless opts.AllowBinary is set,
+// if a value contains a NUL byte, which the environment cannot hold.
+func EnvVars(data map[string][]byte, opts EnvOptions) ([]EnvVar, error) {
+	vars := make([]EnvVar, 0, len(data))
+	keysByName := make(map[string]string, len(data))
+	for _, key := range SortedKeys(data) {
+		name := EnvName(key, opts)
+		if other, ok := keysByName[name]; ok {
+			return nil, fmt.Errorf("keys '%s' and '%s' both map to variable %s", other, key, name)
+		}
+		if !opts.AllowBinary && bytes.IndexByte(data[key], 0) >= 0 {
+			return nil, fmt.Errorf("key '%s' has a binary value, which cannot be put in the environment", key)
+		}
+		keysByName[name] = key
+		vars = append(vars, EnvVar{Name: name, Key: key, Value: data[key]})
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars, nil
+}
+
+// ShellQuote quotes a value for POSIX shells, wrapping it in single quotes.
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// FormatDotenv formats variables as a dotenv file, which docker compose also reads
+// as an env_file. Values are left bare when they can be, single-quoted when they
+// contain spaces or special characters, and double-quoted with escapes when they
+// contain single quotes or line breaks, with $ doubled since docker compose
+// interpolates variables within double quotes.
+func FormatDotenv(vars []EnvVar) []byte {
+	var b bytes.Buffer
+	for _, v := range vars {
+		b.WriteString(v.Name + "=" + dotenvValue(string(v.Value)) + "\n")
+	}
+	return b.Bytes()
+}
+
+// dotenvValue quotes a value of a dotenv file as little as possible.
+func dotenvValue(value string) string {
+	switch {
+	case !strings.ContainsAny(value, " \t\r\n#'\"\\$`"):
+		return value
+	case !strings.ContainsAny(value, "'\r\n"):
+		return "'" + value + "'"
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", "$$")
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// WriteDotenvFile writes the keys of a secret to a dotenv file, created with 0600
+// permissions.
+func WriteDotenvFile(clientset Client, ref SecretRef, file string, opts EnvOptions) error {
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return err
+	}
+	vars, err := EnvVars(secret.Data, opts)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, FormatDotenv(vars), 0o600); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", file, err)
+	}
+	return nil
+}
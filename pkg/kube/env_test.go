@@ -0,0 +1,60 @@
+package kube
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestEnvVars verifies how the keys of a secret are named as environment variables.
+func TestEnvVars(t *testing.T) {
+	t.Run("should name variables after the keys", func(t *testing.T) {
+		for key, want := range map[string]string{"password": "APP_PASSWORD", "tls.crt": "APP_TLS_CRT", "db-url": "APP_DB_URL"} {
+			if got := EnvName(key, EnvOptions{Prefix: "app_"}); got != want {
+				t.Errorf("Expected %s for key %s, but got %s", want, key, got)
+			}
+		}
+		if got := EnvName("1st-key", EnvOptions{Lowercase: true}); got != "_1st_key" {
+			t.Errorf("Expected _1st_key, but got %s", got)
+		}
+	})
+
+	t.Run("should sort variables by name", func(t *testing.T) {
+		vars, err := EnvVars(map[string][]byte{"user": []byte("admin"), "api.key": []byte("k")}, EnvOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		want := []EnvVar{{Name: "API_KEY", Key: "api.key", Value: []byte("k")}, {Name: "USER", Key: "user", Value: []byte("admin")}}
+		if !reflect.DeepEqual(vars, want) {
+			t.Errorf("Expected %v, but got %v", want, vars)
+		}
+	})
+
+	t.Run("should reject clashing names and binary values", func(t *testing.T) {
+		if _, err := EnvVars(map[string][]byte{"db-url": nil, "db.url": nil}, EnvOptions{}); err == nil {
+			t.Error("Expected an error for clashing names, but got none")
+		}
+		if _, err := EnvVars(map[string][]byte{"blob": {0x00, 0x01}}, EnvOptions{}); err == nil {
+			t.Error("Expected an error for a binary value, but got none")
+		}
+	})
+
+	t.Run("should single-quote values for the shell", func(t *testing.T) {
+		if got := ShellQuote("it's $HOME"); got != `'it'\''s $HOME'` {
+			t.Errorf("Expected the value to be quoted, but got %s", got)
+		}
+	})
+}
+
+// TestFormatDotenv verifies that values are quoted only as much as needed.
+func TestFormatDotenv(t *testing.T) {
+	vars := []EnvVar{
+		{Name: "USER", Value: []byte("admin")},
+		{Name: "GREETING", Value: []byte("hello $USER")},
+		{Name: "PEM", Value: []byte("-----BEGIN-----\nit's \"quoted\"\n")},
+		{Name: "PASSWORD", Value: []byte("pa$word's")},
+	}
+	expected := "USER=admin\nGREETING='hello $USER'\nPEM=\"-----BEGIN-----\\nit's \\\"quoted\\\"\\n\"\nPASSWORD=\"pa$$word's\"\n"
+	if got := string(FormatDotenv(vars)); got != expected {
+		t.Errorf("Expected %q, but got %q", expected, got)
+	}
+}
@@ -0,0 +1,50 @@
+package kube
+
+import (
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// CertificateExpiry is when the leaf certificate under a key of a TLS secret expires.
+type CertificateExpiry struct {
+	Namespace string
+	Secret    string
+	Key       string // tls.crt or ca.crt.
+	Subject   string // The common name of the certificate.
+	NotAfter  time.Time
+}
+
+// CertificateExpiries collects the expiry of the leaf certificates of tls.crt, and
+// of ca.crt if present, in the TLS secrets among secrets, sorted by namespace,
+// secret, and key. Values that do not parse are skipped.
+func CertificateExpiries(secrets []corev1.Secret) []CertificateExpiry {
+	var expiries []CertificateExpiry
+	for i := range secrets {
+		secret := &secrets[i]
+		if secret.Type != corev1.SecretTypeTLS {
+			continue
+		}
+		for _, key := range []string{caCertKey, corev1.TLSCertKey} {
+			chain, err := ParseCertificates(secret.Data[key])
+			if err != nil {
+				continue
+			}
+			expiries = append(expiries, CertificateExpiry{
+				Namespace: secret.Namespace,
+				Secret:    secret.Name,
+				Key:       key,
+				Subject:   chain[0].Subject.CommonName,
+				NotAfter:  chain[0].NotAfter,
+			})
+		}
+	}
+	sort.SliceStable(expiries, func(i, j int) bool {
+		if expiries[i].Namespace != expiries[j].Namespace {
+			return expiries[i].Namespace < expiries[j].Namespace
+		}
+		return expiries[i].Secret < expiries[j].Secret
+	})
+	return expiries
+}
@@ -0,0 +1,37 @@
+package kube
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diskmanti/kds/internal/testfixtures"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestCertificateExpiries verifies that the leaf certificates of tls.crt and ca.crt
+// are collected from TLS secrets only.
+func TestCertificateExpiries(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	ca := testfixtures.NewCert(t, "ca", now.Add(-time.Hour), now.Add(365*24*time.Hour), nil)
+	leaf := testfixtures.NewCert(t, "web.example.com", now.Add(-time.Hour), now.Add(30*24*time.Hour), &ca)
+	secrets := []corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: "prod"}, Type: corev1.SecretTypeTLS, Data: map[string][]byte{
+			corev1.TLSCertKey: append(append([]byte{}, leaf.CertPEM...), ca.CertPEM...),
+			caCertKey:         ca.CertPEM,
+		}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "broken-tls", Namespace: "prod"}, Type: corev1.SecretTypeTLS, Data: map[string][]byte{corev1.TLSCertKey: []byte("garbage")}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "bundle", Namespace: "dev"}, Type: corev1.SecretTypeOpaque, Data: map[string][]byte{corev1.TLSCertKey: leaf.CertPEM}},
+	}
+
+	expiries := CertificateExpiries(secrets)
+	if len(expiries) != 2 {
+		t.Fatalf("Expected 2 expiries, but got %+v", expiries)
+	}
+	if e := expiries[0]; e.Key != caCertKey || e.Subject != "ca" || !e.NotAfter.Equal(ca.Cert.NotAfter) {
+		t.Errorf("Expected the expiry of ca.crt, but got %+v", e)
+	}
+	if e := expiries[1]; e.Key != corev1.TLSCertKey || e.Subject != "web.example.com" || !e.NotAfter.Equal(leaf.Cert.NotAfter) {
+		t.Errorf("Expected the expiry of the leaf certificate, but got %+v", e)
+	}
+}
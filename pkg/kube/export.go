@@ -0,0 +1,67 @@
+package kube
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ExportOptions holds the flags of the 'kds export' command.
+type ExportOptions struct {
+	Dir          string
+	Archive      string
+	WithMetadata bool
+	Kustomize    string
+	Dotenv       string
+	SOPS         string
+	// Env names the variables of the dotenv file.
+	Env EnvOptions
+}
+
+// Destinations counts the export destinations that were given.
+func (o *ExportOptions) Destinations() int {
+	count := 0
+	for _, destination := range []string{o.Dir, o.Archive, o.Kustomize, o.Dotenv, o.SOPS} {
+		if destination != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// checkFileName rejects secret keys that cannot safely be used as a file name.
+// Keys are validated by the API server, but are never trusted as paths.
+func checkFileName(key string) error {
+	if key != filepath.Base(key) || key == "." || key == ".." {
+		return fmt.Errorf("refusing to write key '%s': not a plain file name", key)
+	}
+	return nil
+}
+
+// WriteSecretFiles writes every key of a secret as an individual file in dir,
+// using the key as the file name and the raw value as its content. Every key is
+// checked before anything is written, so that a bad key leaves no partial export.
+func WriteSecretFiles(clientset Client, ref SecretRef, dir string) error {
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return err
+	}
+	keys := SortedKeys(secret.Data)
+	for _, key := range keys {
+		if err := checkFileName(key); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create directory '%s': %w", dir, err)
+	}
+	for _, key := range keys {
+		value := secret.Data[key]
+		// Through a temporary file, created readable by the current user only, as
+		// os.WriteFile would keep the permissions of an existing file.
+		if err := writeFileAtomic(dir, filepath.Join(dir, key), value); err != nil {
+			return fmt.Errorf("failed to write key '%s': %w", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package kube
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestWriteSecretFiles verifies that every key is written as its own 0600 file.
+func TestWriteSecretFiles(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-tls", Namespace: "default"},
+		Data:       map[string][]byte{"tls.crt": []byte("cert-bytes"), "tls.key": []byte("key-bytes")},
+	})
+	dir := filepath.Join(t.TempDir(), "out")
+	// A file left by an earlier export with looser permissions is tightened.
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tls.key"), []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSecretFiles(clientset, SecretRef{Namespace: "default", Name: "app-tls"}, dir); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for key, expected := range map[string]string{"tls.crt": "cert-bytes", "tls.key": "key-bytes"} {
+		path := filepath.Join(dir, key)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Expected file '%s' to exist, but got: %v", path, err)
+		}
+		if string(content) != expected {
+			t.Errorf("Expected '%s' to contain %q, but got %q", key, expected, content)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("Expected mode 0600 for '%s', but got %v", key, info.Mode().Perm())
+		}
+	}
+}
+
+// TestWriteSecretFilesInvalidKey verifies that an invalid key fails the export
+// before any file is written.
+func TestWriteSecretFilesInvalidKey(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Data:       map[string][]byte{"a": []byte("1"), "..": []byte("2"), "z": []byte("3")},
+	})
+	dir := t.TempDir()
+	if err := WriteSecretFiles(clientset, SecretRef{Namespace: "default", Name: "app"}, dir); err == nil {
+		t.Fatal("Expected an error for an invalid key, but got none")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no file to be written, but got %d", len(entries))
+	}
+}
@@ -0,0 +1,51 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// FetchErrorKind classifies why a secret could not be fetched.
+type FetchErrorKind int
+
+// Kinds of fetch errors, from the most to the least actionable.
+const (
+	FetchErrorOther      FetchErrorKind = iota // Anything else, e.g. a malformed request.
+	FetchErrorPermission                       // RBAC forbids the request, or the credentials were rejected.
+	FetchErrorNotFound                         // The secret no longer exists.
+	FetchErrorTransient                        // The API server was unreachable, overloaded, or timed out.
+)
+
+// String names the kind of error, e.g. "permission denied".
+func (k FetchErrorKind) String() string {
+	switch k {
+	case FetchErrorPermission:
+		return "permission denied"
+	case FetchErrorNotFound:
+		return "not found"
+	case FetchErrorTransient:
+		return "transient error"
+	}
+	return "error"
+}
+
+// ClassifyFetchError tells whether err is a permission, not-found, or transient
+// error, which decides whether retrying can help.
+func ClassifyFetchError(err error) FetchErrorKind {
+	var netErr net.Error
+	switch {
+	case k8serrors.IsForbidden(err), k8serrors.IsUnauthorized(err):
+		return FetchErrorPermission
+	case k8serrors.IsNotFound(err):
+		return FetchErrorNotFound
+	case k8serrors.IsTimeout(err), k8serrors.IsServerTimeout(err), k8serrors.IsTooManyRequests(err),
+		k8serrors.IsServiceUnavailable(err), k8serrors.IsInternalError(err), k8serrors.IsUnexpectedServerError(err),
+		errors.Is(err, context.DeadlineExceeded), errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &netErr):
+		return FetchErrorTransient
+	}
+	return FetchErrorOther
+}
@@ -0,0 +1,35 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TestClassifyFetchError verifies that API and network errors are classified.
+func TestClassifyFetchError(t *testing.T) {
+	secrets := schema.GroupResource{Resource: "secrets"}
+	tests := []struct {
+		err      error
+		expected FetchErrorKind
+	}{
+		{k8serrors.NewForbidden(secrets, "db", errors.New("no RBAC policy matched")), FetchErrorPermission},
+		{k8serrors.NewUnauthorized("token expired"), FetchErrorPermission},
+		{k8serrors.NewNotFound(secrets, "db"), FetchErrorNotFound},
+		{k8serrors.NewServiceUnavailable("etcd is down"), FetchErrorTransient},
+		{k8serrors.NewTooManyRequests("slow down", 1), FetchErrorTransient},
+		{fmt.Errorf("failed to get secret: %w", context.DeadlineExceeded), FetchErrorTransient},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, FetchErrorTransient},
+		{k8serrors.NewBadRequest("invalid name"), FetchErrorOther},
+	}
+	for _, tt := range tests {
+		if kind := ClassifyFetchError(tt.err); kind != tt.expected {
+			t.Errorf("Expected %q to be classified as %s, but got %s", tt.err, tt.expected, kind)
+		}
+	}
+}
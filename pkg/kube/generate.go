@@ -0,0 +1,225 @@
+package kube
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	_ "embed"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"maps"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Kinds of generated values.
+const (
+	GenerateAlphanumeric = "alnum"
+	GenerateHex          = "hex"
+	GenerateUUID         = "uuid"
+	GeneratePassphrase   = "passphrase"
+	GenerateRSA          = "rsa"     // An RSA keypair.
+	GenerateEd25519      = "ed25519" // An ed25519 keypair.
+)
+
+// GeneratorKinds lists the kinds of generated values, for help texts.
+var GeneratorKinds = []string{GenerateAlphanumeric, GenerateHex, GenerateUUID, GeneratePassphrase, GenerateRSA, GenerateEd25519}
+
+// PublicKeySuffix is appended to the key of a generated keypair to name the key
+// holding its public half.
+const PublicKeySuffix = ".pub"
+
+// generatorLengths are the default, minimum, and maximum lengths of the kinds that
+// have one: characters for alnum and hex, words for passphrases, and bits for RSA.
+var generatorLengths = map[string][3]int{
+	GenerateAlphanumeric: {32, 8, 1024},
+	GenerateHex:          {64, 8, 1024},
+	GeneratePassphrase:   {7, 3, 64},
+	GenerateRSA:          {4096, 2048, 8192},
+}
+
+// passphraseWords are the words passphrases are made of.
+//
+//go:embed wordlist.txt
+var passphraseWords string
+
+// Generator describes a random value to generate. A zero Length uses the default
+// length of the kind.
+type Generator struct {
+	Kind   string
+	Length int
+}
+
+// ParseGenerator parses a generator given as kind or kind:length, such as alnum:48
+// or rsa:2048.
+func ParseGenerator(spec string) (Generator, error) {
+	kind, length, hasLength := strings.Cut(strings.TrimSpace(spec), ":")
+	g := Generator{Kind: strings.ToLower(kind)}
+	limits, hasLimits := generatorLengths[g.Kind]
+	switch {
+	case !hasLimits && g.Kind != GenerateUUID && g.Kind != GenerateEd25519:
+		return Generator{}, fmt.Errorf("unknown generator '%s': use one of %s", kind, strings.Join(GeneratorKinds, ", "))
+	case !hasLength:
+		return g, nil
+	case !hasLimits:
+		return Generator{}, fmt.Errorf("generator '%s' takes no length", g.Kind)
+	}
+	n, err := strconv.Atoi(length)
+	if err != nil || n < limits[1] || n > limits[2] {
+		return Generator{}, fmt.Errorf("invalid length '%s' of generator '%s': expected %d to %d", length, g.Kind, limits[1], limits[2])
+	}
+	g.Length = n
+	return g, nil
+}
+
+// ParseGeneratedKey parses a key and its generator given as key=kind[:length].
+func ParseGeneratedKey(s string) (string, Generator, error) {
+	key, spec, found := strings.Cut(s, "=")
+	if !found || key == "" {
+		return "", Generator{}, fmt.Errorf("invalid generated key '%s': expected key=kind[:length]", s)
+	}
+	g, err := ParseGenerator(spec)
+	return key, g, err
+}
+
+// Generate returns the generated data for a key: the value itself, or for
+// keypairs, the PKCS#8 private key under key and the PKIX public key under key
+// with PublicKeySuffix, both PEM-encoded.
+func (g Generator) Generate(key string) (map[string][]byte, error) {
+	length := g.Length
+	if length == 0 {
+		length = generatorLengths[g.Kind][0]
+	}
+	var value []byte
+	var err error
+	switch g.Kind {
+	case GenerateAlphanumeric:
+		value, err = randomFrom("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", length)
+	case GenerateHex:
+		value, err = randomFrom("0123456789abcdef", length)
+	case GenerateUUID:
+		value, err = randomUUID()
+	case GeneratePassphrase:
+		value, err = randomPassphrase(length)
+	case GenerateRSA:
+		var private *rsa.PrivateKey
+		if private, err = rsa.GenerateKey(rand.Reader, length); err == nil {
+			return keypairData(key, private, &private.PublicKey)
+		}
+	case GenerateEd25519:
+		var public ed25519.PublicKey
+		var private ed25519.PrivateKey
+		if public, private, err = ed25519.GenerateKey(rand.Reader); err == nil {
+			return keypairData(key, private, public)
+		}
+	default:
+		return nil, fmt.Errorf("unknown generator '%s'", g.Kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate a value for key '%s': %w", key, err)
+	}
+	return map[string][]byte{key: value}, nil
+}
+
+// GenerateData generates the values of keys given as key=kind[:length]. A key
+// given more than once is an error.
+func GenerateData(specs []string) (map[string][]byte, error) {
+	data := make(map[string][]byte)
+	for _, spec := range specs {
+		key, g, err := ParseGeneratedKey(spec)
+		if err != nil {
+			return nil, err
+		}
+		generated, err := g.Generate(key)
+		if err != nil {
+			return nil, err
+		}
+		for k := range generated {
+			if _, exists := data[k]; exists {
+				return nil, fmt.Errorf("key '%s' is given more than once", k)
+			}
+		}
+		maps.Copy(data, generated)
+	}
+	return data, nil
+}
+
+// PrepareGenerate returns the update that sets keys of a secret to generated
+// values, given as key=kind[:length].
+func PrepareGenerate(clientset Client, ref SecretRef, specs []string) (Mutation, error) {
+	generated, err := GenerateData(specs)
+	if err != nil {
+		return Mutation{}, err
+	}
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return Mutation{}, err
+	}
+	if isImmutable(secret) {
+		return Mutation{}, ErrImmutable
+	}
+	updated := secret.DeepCopy()
+	if updated.Data == nil {
+		updated.Data = make(map[string][]byte, len(generated))
+	}
+	maps.Copy(updated.Data, generated)
+	return Mutation{Before: secret, After: updated}, nil
+}
+
+// randomFrom returns n characters drawn uniformly from alphabet.
+func randomFrom(alphabet string, n int) ([]byte, error) {
+	value := make([]byte, n)
+	for i := range value {
+		index, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return nil, err
+		}
+		value[i] = alphabet[index.Int64()]
+	}
+	return value, nil
+}
+
+// randomUUID returns a random version 4 UUID.
+func randomUUID() ([]byte, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b)
+	return []byte(h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]), nil
+}
+
+// randomPassphrase returns n words of the word list, separated by dashes.
+func randomPassphrase(n int) ([]byte, error) {
+	words := strings.Fields(passphraseWords)
+	chosen := make([]string, n)
+	for i := range chosen {
+		index, err := rand.Int(rand.Reader, big.NewInt(int64(len(words))))
+		if err != nil {
+			return nil, err
+		}
+		chosen[i] = words[index.Int64()]
+	}
+	return []byte(strings.Join(chosen, "-")), nil
+}
+
+// keypairData encodes the halves of a keypair as PEM under key and its public key.
+func keypairData(key string, private, public any) (map[string][]byte, error) {
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the private key of key '%s': %w", key, err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the public key of key '%s': %w", key, err)
+	}
+	return map[string][]byte{
+		key:                   pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		key + PublicKeySuffix: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}),
+	}, nil
+}
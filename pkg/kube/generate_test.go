@@ -0,0 +1,120 @@
+package kube
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"regexp"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestParseGenerator verifies the parsing of generators and their lengths.
+func TestParseGenerator(t *testing.T) {
+	valid := map[string]Generator{
+		"alnum":          {Kind: GenerateAlphanumeric},
+		"HEX:16":         {Kind: GenerateHex, Length: 16},
+		"rsa:2048":       {Kind: GenerateRSA, Length: 2048},
+		"uuid":           {Kind: GenerateUUID},
+		"ed25519":        {Kind: GenerateEd25519},
+		" passphrase:5 ": {Kind: GeneratePassphrase, Length: 5},
+	}
+	for spec, expected := range valid {
+		t.Run("should parse "+strings.TrimSpace(spec), func(t *testing.T) {
+			g, err := ParseGenerator(spec)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if g != expected {
+				t.Errorf("Expected %+v, but got %+v", expected, g)
+			}
+		})
+	}
+	for _, spec := range []string{"random", "alnum:4", "alnum:x", "rsa:1024", "uuid:36"} {
+		t.Run("should reject "+spec, func(t *testing.T) {
+			if _, err := ParseGenerator(spec); err == nil {
+				t.Error("Expected an error, but got nil")
+			}
+		})
+	}
+}
+
+// TestGenerate verifies the shape of the generated values.
+func TestGenerate(t *testing.T) {
+	generate := func(t *testing.T, g Generator) map[string][]byte {
+		t.Helper()
+		data, err := g.Generate("value")
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		return data
+	}
+	patterns := map[Generator]string{
+		{Kind: GenerateAlphanumeric}:          `^[A-Za-z0-9]{32}$`,
+		{Kind: GenerateHex, Length: 10}:       `^[0-9a-f]{10}$`,
+		{Kind: GenerateUUID}:                  `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		{Kind: GeneratePassphrase, Length: 4}: `^[a-z]+(-[a-z]+){3}$`,
+	}
+	for g, pattern := range patterns {
+		t.Run("should generate a "+g.Kind+" value", func(t *testing.T) {
+			data := generate(t, g)
+			if len(data) != 1 || !regexp.MustCompile(pattern).Match(data["value"]) {
+				t.Errorf("Expected a value matching %s, but got %q", pattern, data)
+			}
+		})
+	}
+	t.Run("should generate different values", func(t *testing.T) {
+		g := Generator{Kind: GenerateAlphanumeric}
+		if string(generate(t, g)["value"]) == string(generate(t, g)["value"]) {
+			t.Error("Expected two generated values to differ")
+		}
+	})
+	for _, g := range []Generator{{Kind: GenerateEd25519}, {Kind: GenerateRSA, Length: 2048}} {
+		t.Run("should generate a "+g.Kind+" keypair", func(t *testing.T) {
+			data := generate(t, g)
+			private, _ := pem.Decode(data["value"])
+			public, _ := pem.Decode(data["value"+PublicKeySuffix])
+			if private == nil || public == nil {
+				t.Fatalf("Expected PEM private and public keys, but got %q", data)
+			}
+			if _, err := x509.ParsePKCS8PrivateKey(private.Bytes); err != nil {
+				t.Errorf("Expected a PKCS#8 private key, but got: %v", err)
+			}
+			if _, err := x509.ParsePKIXPublicKey(public.Bytes); err != nil {
+				t.Errorf("Expected a PKIX public key, but got: %v", err)
+			}
+		})
+	}
+}
+
+// TestPrepareGenerate verifies setting keys of a secret to generated values.
+func TestPrepareGenerate(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Data:       map[string][]byte{"user": []byte("admin"), "password": []byte("changeme")},
+	})
+	ref := SecretRef{Namespace: "default", Name: "db"}
+
+	t.Run("should replace and add generated keys", func(t *testing.T) {
+		mu, err := PrepareGenerate(clientset, ref, []string{"password=hex:16", "token=uuid"})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if _, err := mu.Apply(clientset, false); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		secret, _ := clientset.CoreV1().Secrets("default").Get(context.TODO(), "db", metav1.GetOptions{})
+		if string(secret.Data["user"]) != "admin" || len(secret.Data["password"]) != 16 || len(secret.Data["token"]) != 36 {
+			t.Errorf("Expected user kept and password and token generated, but got %q", secret.Data)
+		}
+	})
+	t.Run("should reject a key given twice", func(t *testing.T) {
+		if _, err := PrepareGenerate(clientset, ref, []string{"ssh=ed25519", "ssh.pub=hex"}); err == nil {
+			t.Error("Expected an error for a duplicate key, but got nil")
+		}
+	})
+}
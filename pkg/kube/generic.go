@@ -0,0 +1,138 @@
+package kube
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// GenericOptions holds the sources of a generic secret's data.
+type GenericOptions struct {
+	Literals   []string
+	Files      []string
+	EnvFiles   []string
+	Generated  []string // Keys with generated values, as key=kind[:length].
+	SecretType string
+	DryRun     string
+}
+
+// ValidateKey rejects names the API server does not accept as the key of a secret.
+func ValidateKey(key string) error {
+	if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+		return fmt.Errorf("invalid key '%s': %s", key, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// BuildGenericData collects the data of a generic secret from all sources. A key
+// given more than once is an error.
+func BuildGenericData(genOpts GenericOptions) (map[string][]byte, error) {
+	data := make(map[string][]byte)
+	add := func(key string, value []byte) error {
+		if err := ValidateKey(key); err != nil {
+			return err
+		}
+		if _, exists := data[key]; exists {
+			return fmt.Errorf("key '%s' is given more than once", key)
+		}
+		data[key] = value
+		return nil
+	}
+	for _, literal := range genOpts.Literals {
+		key, value, found := strings.Cut(literal, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid literal '%s': expected key=value", literal)
+		}
+		if err := add(key, []byte(value)); err != nil {
+			return nil, err
+		}
+	}
+	for _, source := range genOpts.Files {
+		if err := addFromFile(source, add); err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range genOpts.EnvFiles {
+		if err := addFromEnvFile(path, add); err != nil {
+			return nil, err
+		}
+	}
+	generated, err := GenerateData(genOpts.Generated)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range SortedKeys(generated) {
+		if err := add(key, generated[key]); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) == 0 {
+		return nil, errors.New("no data given; use --from-literal, --from-file, --from-env-file, or --generate")
+	}
+	return data, nil
+}
+
+// addFromFile adds a --from-file source: key=path, a single file named after its
+// base name, or every regular file of a directory.
+func addFromFile(source string, add func(string, []byte) error) error {
+	key, path, found := strings.Cut(source, "=")
+	if !found {
+		key, path = "", source
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		if key == "" {
+			key = filepath.Base(path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return add(key, content)
+	}
+	if found {
+		return fmt.Errorf("cannot give a key name for directory '%s'", path)
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := add(entry.Name(), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addFromEnvFile adds every entry of a --from-env-file source.
+func addFromEnvFile(path string, add func(string, []byte) error) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	entries, err := parseEnvFile(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, entry := range entries {
+		if err := add(entry.key, []byte(entry.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
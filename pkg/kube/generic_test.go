@@ -0,0 +1,68 @@
+package kube
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestBuildGenericData verifies collecting secret data from literals, files, and env files.
+func TestBuildGenericData(t *testing.T) {
+	dir := t.TempDir()
+	certs := filepath.Join(dir, "certs")
+	if err := os.Mkdir(certs, 0o700); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	files := map[string]string{
+		filepath.Join(dir, "config.json"): "{}",
+		filepath.Join(certs, "ca.crt"):    "CA",
+		filepath.Join(dir, "app.env"):     "API_KEY=abc\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	t.Run("should combine all sources", func(t *testing.T) {
+		data, err := BuildGenericData(GenericOptions{
+			Literals: []string{"user=admin"},
+			Files:    []string{filepath.Join(dir, "config.json"), "settings=" + filepath.Join(dir, "config.json"), certs},
+			EnvFiles: []string{filepath.Join(dir, "app.env")},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		expected := map[string]string{"user": "admin", "config.json": "{}", "settings": "{}", "ca.crt": "CA", "API_KEY": "abc"}
+		if len(data) != len(expected) {
+			t.Errorf("Expected %d keys, but got %d", len(expected), len(data))
+		}
+		for key, value := range expected {
+			if string(data[key]) != value {
+				t.Errorf("Expected %s=%s, but got %s", key, value, data[key])
+			}
+		}
+	})
+
+	t.Run("should add generated keys", func(t *testing.T) {
+		data, err := BuildGenericData(GenericOptions{Literals: []string{"user=admin"}, Generated: []string{"password=alnum:20"}})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if string(data["user"]) != "admin" || len(data["password"]) != 20 {
+			t.Errorf("Expected user=admin and a 20-character password, but got %q", data)
+		}
+	})
+
+	t.Run("should reject duplicate keys", func(t *testing.T) {
+		if _, err := BuildGenericData(GenericOptions{Literals: []string{"a=1", "a=2"}}); err == nil {
+			t.Error("Expected an error for a duplicate key, but got nil")
+		}
+	})
+
+	t.Run("should reject an empty secret", func(t *testing.T) {
+		if _, err := BuildGenericData(GenericOptions{}); err == nil {
+			t.Error("Expected an error when no data is given, but got nil")
+		}
+	})
+}
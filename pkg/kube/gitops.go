@@ -0,0 +1,222 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Labels and annotations Argo CD puts on the resources of an Application.
+const (
+	// ArgoCDInstanceLabel names the Application, when Argo CD tracks resources with
+	// this label instead of the default app.kubernetes.io/instance, which Helm sets
+	// too and so tells nothing on its own.
+	ArgoCDInstanceLabel = "argocd.argoproj.io/instance"
+	// ArgoCDTrackingAnnotation holds <application>:<group>/<kind>:<namespace>/<name>,
+	// where the application is <namespace>_<name> outside the Argo CD namespace.
+	ArgoCDTrackingAnnotation = "argocd.argoproj.io/tracking-id"
+)
+
+// Labels Flux puts on the resources of a Kustomization or a HelmRelease.
+const (
+	FluxKustomizeNameLabel      = "kustomize.toolkit.fluxcd.io/name"
+	FluxKustomizeNamespaceLabel = "kustomize.toolkit.fluxcd.io/namespace"
+	FluxHelmNameLabel           = "helm.toolkit.fluxcd.io/name"
+	FluxHelmNamespaceLabel      = "helm.toolkit.fluxcd.io/namespace"
+)
+
+// GitOps tools that manage secrets.
+const (
+	GitOpsArgoCD = "Argo CD"
+	GitOpsFlux   = "Flux"
+)
+
+// defaultArgoCDNamespace is where Argo CD Applications are looked up when the
+// tracking label does not tell their namespace.
+const defaultArgoCDNamespace = "argocd"
+
+// Resources of the GitOps tools, for the dynamic client.
+var (
+	argoCDApplicationGVR = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+	fluxKustomizationGVR = schema.GroupVersionResource{Group: "kustomize.toolkit.fluxcd.io", Version: "v1", Resource: "kustomizations"}
+	fluxHelmReleaseGVR   = schema.GroupVersionResource{Group: "helm.toolkit.fluxcd.io", Version: "v2", Resource: "helmreleases"}
+	fluxSourceGVRs       = map[string]schema.GroupVersionResource{
+		"GitRepository":  {Group: "source.toolkit.fluxcd.io", Version: "v1", Resource: "gitrepositories"},
+		"HelmRepository": {Group: "source.toolkit.fluxcd.io", Version: "v1", Resource: "helmrepositories"},
+		"OCIRepository":  {Group: "source.toolkit.fluxcd.io", Version: "v1beta2", Resource: "ocirepositories"},
+		"Bucket":         {Group: "source.toolkit.fluxcd.io", Version: "v1", Resource: "buckets"},
+	}
+)
+
+// GitOpsManager is the object of a GitOps tool that manages a secret, such as an
+// Argo CD Application, as told by the labels and annotations the tool puts on the
+// resources it applies. Manual changes to the secret do not last: the tool brings
+// it back to the state of its source.
+type GitOpsManager struct {
+	Tool      string
+	Kind      string
+	Namespace string // Empty if the labels do not tell.
+	Name      string
+}
+
+// String describes the manager, e.g. Argo CD Application argocd/payments.
+func (g GitOpsManager) String() string {
+	name := g.Name
+	if g.Namespace != "" {
+		name = g.Namespace + "/" + name
+	}
+	return fmt.Sprintf("%s %s %s", g.Tool, g.Kind, name)
+}
+
+// DriftWarning tells what happens to manual changes of a secret managed by g.
+func (g GitOpsManager) DriftWarning() string {
+	return fmt.Sprintf("managed by %s: %s", g, g.DriftConsequence())
+}
+
+// DriftConsequence tells, in the words of the tool, that manual changes will not last.
+func (g GitOpsManager) DriftConsequence() string {
+	if g.Tool == GitOpsFlux {
+		return "manual changes will be reconciled away"
+	}
+	return "manual changes will be reverted on the next sync"
+}
+
+// GitOpsManagerOf returns the GitOps object managing a secret, if any. A tracking
+// annotation only counts if it names the secret itself, as it is left behind on
+// copies that the tool does not manage.
+func GitOpsManagerOf(secret *corev1.Secret) (GitOpsManager, bool) {
+	if tracking := secret.Annotations[ArgoCDTrackingAnnotation]; tracking != "" {
+		app, resource, _ := strings.Cut(tracking, ":")
+		groupKind, objectRef, _ := strings.Cut(resource, ":")
+		namespace, name, _ := strings.Cut(objectRef, "/")
+		if app != "" && strings.TrimPrefix(groupKind, "/") == "Secret" && namespace == secret.Namespace && name == secret.Name {
+			manager := GitOpsManager{Tool: GitOpsArgoCD, Kind: "Application", Name: app}
+			if appNamespace, appName, found := strings.Cut(app, "_"); found {
+				manager.Namespace, manager.Name = appNamespace, appName
+			}
+			return manager, true
+		}
+	}
+	if app := secret.Labels[ArgoCDInstanceLabel]; app != "" {
+		return GitOpsManager{Tool: GitOpsArgoCD, Kind: "Application", Name: app}, true
+	}
+	if name := secret.Labels[FluxHelmNameLabel]; name != "" {
+		return GitOpsManager{Tool: GitOpsFlux, Kind: "HelmRelease", Namespace: secret.Labels[FluxHelmNamespaceLabel], Name: name}, true
+	}
+	if name := secret.Labels[FluxKustomizeNameLabel]; name != "" {
+		return GitOpsManager{Tool: GitOpsFlux, Kind: "Kustomization", Namespace: secret.Labels[FluxKustomizeNamespaceLabel], Name: name}, true
+	}
+	return GitOpsManager{}, false
+}
+
+// FindGitOpsSource returns the repository the manager of a secret applies it from,
+// e.g. https://github.com/acme/fleet@main, or "" if the tool is not installed or
+// the objects cannot be found.
+func FindGitOpsSource(dyn dynamic.Interface, g GitOpsManager) (string, error) {
+	source, err := findGitOpsSource(dyn, g)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find the source of %s: %w", g, err)
+	}
+	return source, nil
+}
+
+// findGitOpsSource reads the source from the objects of the tool.
+func findGitOpsSource(dyn dynamic.Interface, g GitOpsManager) (string, error) {
+	switch {
+	case g.Tool == GitOpsArgoCD:
+		namespace := g.Namespace
+		if namespace == "" {
+			namespace = defaultArgoCDNamespace
+		}
+		app, err := dyn.Resource(argoCDApplicationGVR).Namespace(namespace).Get(context.TODO(), g.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		source := nestedMap(app.Object, "spec", "source")
+		if sources := nestedSlice(app.Object, "spec", "sources"); source == nil && len(sources) > 0 {
+			source, _ = sources[0].(map[string]any)
+		}
+		return withRevision(nestedString(source, "repoURL"), nestedString(source, "path"), nestedString(source, "targetRevision")), nil
+	case g.Kind == "Kustomization":
+		kustomization, err := dyn.Resource(fluxKustomizationGVR).Namespace(g.Namespace).Get(context.TODO(), g.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		ref := nestedMap(kustomization.Object, "spec", "sourceRef")
+		url, err := fluxSourceURL(dyn, g.Namespace, ref)
+		return withRevision(url, nestedString(kustomization.Object, "spec", "path"), ""), err
+	default:
+		release, err := dyn.Resource(fluxHelmReleaseGVR).Namespace(g.Namespace).Get(context.TODO(), g.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		ref := nestedMap(release.Object, "spec", "chart", "spec", "sourceRef")
+		chart := nestedString(release.Object, "spec", "chart", "spec", "chart")
+		if ref == nil {
+			ref = nestedMap(release.Object, "spec", "chartRef")
+		}
+		url, err := fluxSourceURL(dyn, g.Namespace, ref)
+		return withRevision(url, chart, ""), err
+	}
+}
+
+// fluxSourceURL returns the URL of the Flux source a sourceRef points to, with its
+// branch, tag, or semver range.
+func fluxSourceURL(dyn dynamic.Interface, namespace string, ref map[string]any) (string, error) {
+	gvr, ok := fluxSourceGVRs[nestedString(ref, "kind")]
+	if !ok {
+		return "", nil
+	}
+	if ns := nestedString(ref, "namespace"); ns != "" {
+		namespace = ns
+	}
+	source, err := dyn.Resource(gvr).Namespace(namespace).Get(context.TODO(), nestedString(ref, "name"), metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	url := nestedString(source.Object, "spec", "url")
+	if url == "" {
+		url = strings.TrimSuffix(nestedString(source.Object, "spec", "endpoint"), "/") + "/" + nestedString(source.Object, "spec", "bucketName")
+	}
+	for _, field := range []string{"branch", "tag", "semver"} {
+		if revision := nestedString(source.Object, "spec", "ref", field); revision != "" {
+			return url + "@" + revision, nil
+		}
+	}
+	return url, nil
+}
+
+// withRevision appends the path and the revision of a source to its URL, as in
+// https://github.com/acme/fleet@main (apps/prod).
+func withRevision(url, path, revision string) string {
+	if url == "" {
+		return ""
+	}
+	if revision != "" {
+		url += "@" + revision
+	}
+	if path = strings.Trim(path, "./"); path != "" {
+		url += " (" + path + ")"
+	}
+	return url
+}
+
+// nestedMap returns a map field of an unstructured object, or nil if it is missing
+// or not a map.
+func nestedMap(obj map[string]any, fields ...string) map[string]any {
+	value, found, err := unstructured.NestedMap(obj, fields...)
+	if err != nil || !found {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,82 @@
+package kube
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+// TestGitOpsManagerOf verifies that the Argo CD Application managing a secret is
+// found from its tracking label or annotation.
+func TestGitOpsManagerOf(t *testing.T) {
+	secret := func(labels, annotations map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "db", Labels: labels, Annotations: annotations}}
+	}
+	for _, tc := range []struct {
+		name     string
+		secret   *corev1.Secret
+		expected string
+	}{
+		{"should read the instance label", secret(map[string]string{ArgoCDInstanceLabel: "payments"}, nil), "Argo CD Application payments"},
+		{"should read the tracking annotation", secret(nil, map[string]string{ArgoCDTrackingAnnotation: "payments:/Secret:prod/db"}), "Argo CD Application payments"},
+		{"should read the namespace of the application", secret(nil, map[string]string{ArgoCDTrackingAnnotation: "team-a_payments:/Secret:prod/db"}), "Argo CD Application team-a/payments"},
+		{"should ignore a tracking annotation of another secret", secret(nil, map[string]string{ArgoCDTrackingAnnotation: "payments:/Secret:prod/db-copy"}), ""},
+		{"should ignore the instance label of Helm", secret(map[string]string{"app.kubernetes.io/instance": "payments"}, nil), ""},
+		{"should read the labels of a Flux Kustomization", secret(map[string]string{FluxKustomizeNameLabel: "apps", FluxKustomizeNamespaceLabel: "flux-system"}, nil), "Flux Kustomization flux-system/apps"},
+		{"should read the labels of a Flux HelmRelease", secret(map[string]string{FluxHelmNameLabel: "redis", FluxHelmNamespaceLabel: "prod"}, nil), "Flux HelmRelease prod/redis"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			manager, managed := GitOpsManagerOf(tc.secret)
+			if got := manager.String(); managed != (tc.expected != "") || managed && got != tc.expected {
+				t.Errorf("Expected manager %q, but got %q (managed: %v)", tc.expected, got, managed)
+			}
+		})
+	}
+}
+
+// TestFindGitOpsSource verifies that the repositories of Argo CD Applications and
+// Flux Kustomizations are found.
+func TestFindGitOpsSource(t *testing.T) {
+	object := func(apiVersion, kind, namespace, name string, spec map[string]any) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   map[string]any{"namespace": namespace, "name": name},
+			"spec":       spec,
+		}}
+	}
+	dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		object("argoproj.io/v1alpha1", "Application", "argocd", "payments", map[string]any{
+			"source": map[string]any{"repoURL": "https://github.com/acme/fleet", "path": "apps/payments", "targetRevision": "main"},
+		}),
+		object("kustomize.toolkit.fluxcd.io/v1", "Kustomization", "flux-system", "apps", map[string]any{
+			"path":      "./apps/prod",
+			"sourceRef": map[string]any{"kind": "GitRepository", "name": "fleet"},
+		}),
+		object("source.toolkit.fluxcd.io/v1", "GitRepository", "flux-system", "fleet", map[string]any{
+			"url": "https://github.com/acme/fleet", "ref": map[string]any{"branch": "main"},
+		}),
+	)
+	for _, tc := range []struct {
+		manager  GitOpsManager
+		expected string
+	}{
+		{GitOpsManager{Tool: GitOpsArgoCD, Kind: "Application", Name: "payments"}, "https://github.com/acme/fleet@main (apps/payments)"},
+		{GitOpsManager{Tool: GitOpsFlux, Kind: "Kustomization", Namespace: "flux-system", Name: "apps"}, "https://github.com/acme/fleet@main (apps/prod)"},
+		{GitOpsManager{Tool: GitOpsFlux, Kind: "HelmRelease", Namespace: "prod", Name: "missing"}, ""},
+	} {
+		t.Run("should find the source of "+tc.manager.String(), func(t *testing.T) {
+			source, err := FindGitOpsSource(dyn, tc.manager)
+			if err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+			if source != tc.expected {
+				t.Errorf("Expected source %q, but got %q", tc.expected, source)
+			}
+		})
+	}
+}
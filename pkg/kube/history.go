@@ -0,0 +1,155 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Events of a HistoryEntry, and of the secrets seen by WatchSecrets.
+const (
+	HistoryCreated  = "created"
+	HistoryChanged  = "changed"
+	HistoryDeleted  = "deleted"
+	HistoryObserved = "observed" // Seen for the first time, or changed while nothing was watching.
+)
+
+// DefaultHistoryLimit is how many entries are kept per secret by default.
+const DefaultHistoryLimit = 100
+
+// historySuffix ends the name of every file of the history.
+const historySuffix = ".history.enc"
+
+// HistoryEntry is a change of a secret observed by kds. Values are never kept,
+// only the SHA-256 checksum of each key, so that key-level changes can be told.
+type HistoryEntry struct {
+	Time            time.Time         `json:"time"`
+	Event           string            `json:"event"`
+	ResourceVersion string            `json:"resourceVersion,omitempty"`
+	Checksums       map[string]string `json:"checksums,omitempty"`
+}
+
+// HistoryStore keeps encrypted records of the changes to the secrets of a
+// kubeconfig context on the local disk, one file per secret, since Kubernetes
+// keeps no revision history of secrets.
+type HistoryStore struct {
+	dir     string
+	context string
+	key     []byte
+	limit   int
+}
+
+// OpenHistoryStore opens the history of the secrets of a context in dir, creating
+// it if needed, keeping the last limit entries of each secret. Its files are
+// encrypted with the key of keyPath, as loadSealKey keeps it.
+func OpenHistoryStore(dir, keyPath, context string, limit int) (*HistoryStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	key, err := loadSealKey(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history key: %w", err)
+	}
+	return &HistoryStore{dir: dir, context: context, key: key, limit: limit}, nil
+}
+
+// Load returns the recorded history of a secret, oldest first.
+func (h *HistoryStore) Load(ref SecretRef) ([]HistoryEntry, error) {
+	content, err := os.ReadFile(h.path(ref))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	plaintext, err := unseal(h.key, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt history: %w", err)
+	}
+	var entries []HistoryEntry
+	if err := json.Unmarshal(plaintext, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse history: %w", err)
+	}
+	return entries, nil
+}
+
+// Record adds an event of a secret to its history, unless its data is the same as
+// in the last entry. It reports whether an entry was added.
+func (h *HistoryStore) Record(secret *corev1.Secret, event string, now time.Time) (bool, error) {
+	ref := SecretRef{Namespace: secret.Namespace, Name: secret.Name}
+	entries, err := h.Load(ref)
+	if err != nil {
+		return false, err
+	}
+	entry := HistoryEntry{Time: now.UTC(), Event: event, ResourceVersion: secret.ResourceVersion}
+	if event != HistoryDeleted {
+		entry.Checksums = make(map[string]string, len(secret.Data))
+		for key, value := range secret.Data {
+			entry.Checksums[key] = Checksum(value)
+		}
+	}
+	if n := len(entries); n > 0 {
+		last := entries[n-1]
+		if (last.Event == HistoryDeleted) == (event == HistoryDeleted) && maps.Equal(last.Checksums, entry.Checksums) {
+			return false, nil
+		}
+	} else if event == HistoryDeleted {
+		return false, nil
+	}
+	entries = append(entries, entry)
+	if h.limit > 0 && len(entries) > h.limit {
+		entries = entries[len(entries)-h.limit:]
+	}
+	plaintext, err := json.Marshal(entries)
+	if err != nil {
+		return false, fmt.Errorf("failed to serialize history: %w", err)
+	}
+	sealed, err := seal(h.key, plaintext)
+	if err != nil {
+		return false, err
+	}
+	if err := writeFileAtomic(h.dir, h.path(ref), sealed); err != nil {
+		return false, fmt.Errorf("failed to write history: %w", err)
+	}
+	return true, nil
+}
+
+// Watch records the changes to the secrets of a namespace, or of every namespace
+// if it is empty, until ctx is done, as WatchSecrets sees them.
+func (h *HistoryStore) Watch(ctx context.Context, clientset Client, namespace string) error {
+	return WatchSecrets(ctx, clientset, namespace, func(secret *corev1.Secret, event string) error {
+		_, err := h.Record(secret, event, time.Now())
+		return err
+	})
+}
+
+// HistoryChanges returns the key-level changes of the entry i of a history, from
+// the entry before it. The first entry adds all of its keys.
+func HistoryChanges(entries []HistoryEntry, i int) []DataChange {
+	var before map[string][]byte
+	if i > 0 {
+		before = checksumData(entries[i-1].Checksums)
+	}
+	return diffData(before, checksumData(entries[i].Checksums))
+}
+
+// checksumData converts checksums to data, so that diffData can compare them.
+func checksumData(checksums map[string]string) map[string][]byte {
+	data := make(map[string][]byte, len(checksums))
+	for key, sum := range checksums {
+		data[key] = []byte(sum)
+	}
+	return data
+}
+
+// path returns the file of the history of a secret.
+func (h *HistoryStore) path(ref SecretRef) string {
+	return hashedPath(h.dir, historySuffix, h.context, ref.Namespace, ref.Name)
+}
@@ -0,0 +1,126 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestHistoryStore verifies that changes are recorded encrypted, once each, and
+// compared key by key.
+func TestHistoryStore(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	store, err := OpenHistoryStore(filepath.Join(dir, "history"), filepath.Join(dir, "history.key"), "prod", 3)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("hunter2"), "user": []byte("admin")},
+	}
+	ref := SecretRef{Namespace: "default", Name: "db"}
+	record := func(t *testing.T, secret *corev1.Secret, event string, expected bool) {
+		t.Helper()
+		added, err := store.Record(secret, event, now)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if added != expected {
+			t.Errorf("Expected the entry to be added: %v, but got %v", expected, added)
+		}
+	}
+
+	t.Run("should record changes of the data only", func(t *testing.T) {
+		record(t, secret, HistoryObserved, true)
+		record(t, secret, HistoryChanged, false)
+		changed := secret.DeepCopy()
+		changed.Data["password"], changed.Data["token"] = []byte("n3w"), []byte("abc")
+		delete(changed.Data, "user")
+		record(t, changed, HistoryChanged, true)
+		entries, err := store.Load(ref)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("Expected 2 entries, but got %d", len(entries))
+		}
+		changes := HistoryChanges(entries, 1)
+		expected := []DataChange{{Key: "password", Kind: KeyChanged}, {Key: "token", Kind: KeyAdded}, {Key: "user", Kind: KeyRemoved}}
+		if len(changes) != len(expected) {
+			t.Fatalf("Expected %v, but got %v", expected, changes)
+		}
+		for i := range expected {
+			if changes[i] != expected[i] {
+				t.Errorf("Expected %v, but got %v", expected[i], changes[i])
+			}
+		}
+	})
+	t.Run("should record a deletion once and keep the last entries", func(t *testing.T) {
+		record(t, secret, HistoryDeleted, true)
+		record(t, secret, HistoryDeleted, false)
+		record(t, secret, HistoryCreated, true)
+		entries, _ := store.Load(ref)
+		if len(entries) != 3 || entries[0].Event != HistoryChanged || entries[1].Event != HistoryDeleted || len(HistoryChanges(entries, 1)) != 2 {
+			t.Errorf("Expected the last 3 entries, ending with a deletion and a creation, but got %v", entries)
+		}
+	})
+	t.Run("should encrypt the history", func(t *testing.T) {
+		content, err := os.ReadFile(store.path(ref))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if bytes.Contains(content, []byte("password")) {
+			t.Error("Expected the history to be encrypted, but it contains a key name")
+		}
+	})
+}
+
+// TestHistoryStoreWatch verifies that the changes seen by the watch are recorded.
+func TestHistoryStoreWatch(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenHistoryStore(filepath.Join(dir, "history"), filepath.Join(dir, "history.key"), "prod", 0)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("hunter2")},
+	}
+	clientset := fake.NewSimpleClientset(secret)
+	watcher := watch.NewFake()
+	clientset.PrependWatchReactor("secrets", k8stesting.DefaultWatchReactor(watcher, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- store.Watch(ctx, clientset, "default") }()
+	changed := secret.DeepCopy()
+	changed.Data["password"] = []byte("n3w")
+	watcher.Modify(changed)
+	watcher.Delete(changed)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	entries, err := store.Load(SecretRef{Namespace: "default", Name: "db"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	events := make([]string, len(entries))
+	for i, entry := range entries {
+		events[i] = entry.Event
+	}
+	if len(events) != 3 || events[0] != HistoryObserved || events[1] != HistoryChanged || events[2] != HistoryDeleted {
+		t.Errorf("Expected observed, changed, and deleted, but got %v", events)
+	}
+}
@@ -0,0 +1,56 @@
+package kube
+
+import (
+	"context"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// immutableMarker is appended to the names of immutable secrets in the list.
+const immutableMarker = " 🔒"
+
+// ErrImmutable is returned when trying to change the data of an immutable secret.
+var ErrImmutable = errors.New("the secret is immutable, so its data cannot be changed; recreate it as mutable first with 'kds immutable <secret-name> --unset'")
+
+// isImmutable reports whether a secret's data is locked by `immutable: true`.
+func isImmutable(secret *corev1.Secret) bool {
+	return secret.Immutable != nil && *secret.Immutable
+}
+
+// Mutability describes the immutable flag in words.
+func Mutability(immutable bool) string {
+	if immutable {
+		return "immutable"
+	}
+	return "mutable"
+}
+
+// SetImmutable sets the immutable flag of a secret. Turning the flag on is an
+// in-place update; turning it off recreates the secret.
+func SetImmutable(clientset Client, ref SecretRef, immutable bool) error {
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return err
+	}
+	if isImmutable(secret) == immutable {
+		return nil
+	}
+	if immutable {
+		secret.Immutable = &immutable
+		_, err := clientset.CoreV1().Secrets(ref.Namespace).Update(context.TODO(), secret, metav1.UpdateOptions{FieldManager: FieldManager})
+		return err
+	}
+	return recreateSecret(clientset, secret, immutable)
+}
+
+// recreateSecret deletes a secret and creates it again with the given immutable
+// flag, keeping its data, metadata, and owner references. The secret is only
+// deleted if it did not change since it was read, and the original is restored if
+// the new secret cannot be created.
+func recreateSecret(clientset Client, secret *corev1.Secret, immutable bool) error {
+	recreated := recreatedSecret(secret)
+	recreated.Immutable = &immutable
+	return replaceSecret(clientset, secret, recreated)
+}
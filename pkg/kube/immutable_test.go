@@ -0,0 +1,68 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestSetImmutable verifies toggling the immutable flag of a secret.
+func TestSetImmutable(t *testing.T) {
+	ref := SecretRef{Namespace: "default", Name: "app-db"}
+	newSecret := func(immutable bool) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "app-db", Namespace: "default", UID: "5678", ResourceVersion: "42",
+				Labels:          map[string]string{"team": "payments"},
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "app", UID: "1234"}},
+			},
+			Type:      corev1.SecretTypeOpaque,
+			Data:      map[string][]byte{"password": []byte("s3cr3t")},
+			Immutable: &immutable,
+		}
+	}
+
+	t.Run("should mark a mutable secret as immutable", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newSecret(false))
+		if err := SetImmutable(clientset, ref, true); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		secret, _ := clientset.CoreV1().Secrets("default").Get(context.TODO(), "app-db", metav1.GetOptions{})
+		if !isImmutable(secret) {
+			t.Error("Expected the secret to be immutable, but it is not")
+		}
+	})
+
+	t.Run("should recreate an immutable secret as mutable, keeping data and metadata", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(newSecret(true))
+		var preconditions *metav1.Preconditions
+		clientset.PrependReactor("delete", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			preconditions = action.(k8stesting.DeleteAction).GetDeleteOptions().Preconditions
+			return false, nil, nil
+		})
+		if err := SetImmutable(clientset, ref, false); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		secret, err := clientset.CoreV1().Secrets("default").Get(context.TODO(), "app-db", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected the recreated secret, but got: %v", err)
+		}
+		if isImmutable(secret) {
+			t.Error("Expected the secret to be mutable, but it is immutable")
+		}
+		if string(secret.Data["password"]) != "s3cr3t" || secret.Labels["team"] != "payments" {
+			t.Errorf("Expected data and labels to be kept, but got %v and %v", secret.Data, secret.Labels)
+		}
+		if len(secret.OwnerReferences) != 1 || secret.OwnerReferences[0].UID != "1234" {
+			t.Errorf("Expected the owner references to be kept, but got %v", secret.OwnerReferences)
+		}
+		if preconditions == nil || *preconditions.UID != "5678" || *preconditions.ResourceVersion != "42" {
+			t.Errorf("Expected the deletion to be conditioned on the secret read, but got %+v", preconditions)
+		}
+	})
+}
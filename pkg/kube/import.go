@@ -0,0 +1,37 @@
+package kube
+
+import (
+	"fmt"
+	"maps"
+	"os"
+)
+
+// PrepareImport reads a .env file and returns the update that merges the file's
+// keys into the secret's data.
+func PrepareImport(clientset Client, ref SecretRef, path string, prune bool) (Mutation, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Mutation{}, err
+	}
+	entries, err := parseEnvFile(content)
+	if err != nil {
+		return Mutation{}, fmt.Errorf("%s: %w", path, err)
+	}
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return Mutation{}, err
+	}
+	if isImmutable(secret) {
+		return Mutation{}, ErrImmutable
+	}
+	data := make(map[string][]byte, len(secret.Data)+len(entries))
+	if !prune {
+		maps.Copy(data, secret.Data)
+	}
+	for _, entry := range entries {
+		data[entry.key] = []byte(entry.value)
+	}
+	merged := secret.DeepCopy()
+	merged.Data = data
+	return Mutation{Before: secret, After: merged}, nil
+}
@@ -0,0 +1,55 @@
+package kube
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestImportEnvFile verifies merging a .env file into a secret, with and without pruning.
+func TestImportEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("USER=root\nPORT=5432\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	ref := SecretRef{Namespace: "default", Name: "app"}
+	newClientset := func() *fake.Clientset {
+		return fake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Data:       map[string][]byte{"USER": []byte("admin"), "HOST": []byte("db")},
+		})
+	}
+
+	t.Run("should merge keys and keep the others", func(t *testing.T) {
+		clientset := newClientset()
+		mu, err := PrepareImport(clientset, ref, path, false)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if summary := SummarizeDataDiff(DiffSecrets(mu.Before, mu.After)); summary != "+1 ~1 -0" {
+			t.Errorf("Expected changes '+1 ~1 -0', but got '%s'", summary)
+		}
+		if _, err := mu.Apply(clientset, false); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		updated, _ := clientset.CoreV1().Secrets("default").Get(context.TODO(), "app", metav1.GetOptions{})
+		if len(updated.Data) != 3 || string(updated.Data["USER"]) != "root" || string(updated.Data["HOST"]) != "db" {
+			t.Errorf("Expected merged data, but got %v", updated.Data)
+		}
+	})
+
+	t.Run("should remove keys not in the file when pruning", func(t *testing.T) {
+		mu, err := PrepareImport(newClientset(), ref, path, true)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if summary := SummarizeDataDiff(DiffSecrets(mu.Before, mu.After)); summary != "+1 ~1 -1" {
+			t.Errorf("Expected changes '+1 ~1 -1', but got '%s'", summary)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// pullInfisical reads the secrets of a folder of an Infisical environment with
+// 'infisical export', authenticated by 'infisical login' or INFISICAL_TOKEN. The
+// item is the environment, followed by the path of the folder if it is not the
+// root, as in prod/app/db. The project is the vault of the store, or the one of
+// the .infisical.json file of the working directory.
+func (s SecretStore) pullInfisical(ctx context.Context, item StoreItem) (map[string][]byte, error) {
+	env, folder, _ := strings.Cut(item.Item, "/")
+	if env == "" {
+		return nil, fmt.Errorf("item '%s' is not an Infisical environment: use <environment>[/<path>]", item.Item)
+	}
+	args := []string{"infisical", "export", "--format", "json", "--env", env, "--path", "/" + folder}
+	if s.vault != "" {
+		args = append(args, "--projectId", s.vault)
+	}
+	out, err := s.run(ctx, args, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var secrets []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(out, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse the output of infisical: %w", err)
+	}
+	data := make(map[string][]byte, len(secrets))
+	for _, secret := range secrets {
+		data[secret.Key] = []byte(secret.Value)
+	}
+	return data, nil
+}
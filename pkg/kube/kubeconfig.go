@@ -0,0 +1,74 @@
+package kube
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ParseKubeconfig recognizes a kubeconfig stored in a secret value.
+func ParseKubeconfig(value []byte) (*clientcmdapi.Config, bool) {
+	text := string(value)
+	if !strings.Contains(text, "clusters:") && !strings.Contains(text, "kind: Config") {
+		return nil, false
+	}
+	config, err := clientcmd.Load(value)
+	if err != nil || (len(config.Clusters) == 0 && len(config.Contexts) == 0 && len(config.AuthInfos) == 0) {
+		return nil, false
+	}
+	return config, true
+}
+
+// SortedKeys returns the keys of a map in order.
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FindKubeconfig returns the key of a secret that holds a kubeconfig: the given key,
+// or else the first one that parses as a kubeconfig.
+func FindKubeconfig(secret *corev1.Secret, key string) (string, *clientcmdapi.Config, error) {
+	if key != "" {
+		value, ok := secret.Data[key]
+		if !ok {
+			return "", nil, fmt.Errorf("key '%s' not found in secret '%s'", key, secret.Name)
+		}
+		config, ok := ParseKubeconfig(value)
+		if !ok {
+			return "", nil, fmt.Errorf("key '%s' of secret '%s' does not hold a kubeconfig", key, secret.Name)
+		}
+		return key, config, nil
+	}
+	for _, key := range SortedKeys(secret.Data) {
+		if config, ok := ParseKubeconfig(secret.Data[key]); ok {
+			return key, config, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no kubeconfig found in secret '%s'", secret.Name)
+}
+
+// WriteKubeconfig writes a kubeconfig to a private temporary file and returns its path.
+func WriteKubeconfig(value []byte) (string, error) {
+	file, err := os.CreateTemp("", "kds-kubeconfig-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	_, err = file.Write(value)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", errors.Join(err, os.Remove(file.Name()))
+	}
+	return file.Name(), nil
+}
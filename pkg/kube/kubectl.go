@@ -0,0 +1,35 @@
+package kube
+
+import (
+	"regexp"
+	"strings"
+)
+
+// shellSafe matches the words that need no quoting in POSIX shells.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:@=+,-]+$`)
+
+// KubectlCommand returns the kubectl command that prints the decoded value of a
+// key of a secret, or every key of it as key=value lines when key is empty, for
+// those who do not use kds. The context is left out when it is empty.
+func KubectlCommand(context string, ref SecretRef, key string) string {
+	args := []string{"kubectl", "get", "secret", shellWord(ref.Name), "-n", shellWord(ref.Namespace)}
+	if context != "" {
+		args = append(args, "--context", shellWord(context))
+	}
+	if key == "" {
+		args = append(args, "-o", ShellQuote(`go-template={{range $k, $v := .data}}{{$k}}={{$v | base64decode}}{{"\n"}}{{end}}`))
+		return strings.Join(args, " ")
+	}
+	// Dots separate the fields of a JSONPath, so those of keys are escaped.
+	path := "{.data." + strings.ReplaceAll(key, ".", `\.`) + "}"
+	args = append(args, "-o", ShellQuote("jsonpath="+path), "|", "base64", "-d")
+	return strings.Join(args, " ")
+}
+
+// shellWord quotes a word for POSIX shells, only if it needs to be.
+func shellWord(word string) string {
+	if shellSafe.MatchString(word) {
+		return word
+	}
+	return ShellQuote(word)
+}
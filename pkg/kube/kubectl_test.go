@@ -0,0 +1,28 @@
+package kube
+
+import "testing"
+
+// TestKubectlCommand verifies the kubectl commands printing a key of a secret, or
+// all of them, with the keys and the context escaped.
+func TestKubectlCommand(t *testing.T) {
+	ref := SecretRef{Namespace: "payments", Name: "db"}
+	tests := []struct {
+		name    string
+		context string
+		key     string
+		want    string
+	}{
+		{"should print a key", "", "password", `kubectl get secret db -n payments -o 'jsonpath={.data.password}' | base64 -d`},
+		{"should escape the dots of a key", "", "tls.crt", `kubectl get secret db -n payments -o 'jsonpath={.data.tls\.crt}' | base64 -d`},
+		{"should name the context", "arn:aws:eks:eu-west-1:123456789012:cluster/prod", "password", `kubectl get secret db -n payments --context arn:aws:eks:eu-west-1:123456789012:cluster/prod -o 'jsonpath={.data.password}' | base64 -d`},
+		{"should quote a context with spaces", "my cluster", "password", `kubectl get secret db -n payments --context 'my cluster' -o 'jsonpath={.data.password}' | base64 -d`},
+		{"should print every key", "", "", `kubectl get secret db -n payments -o 'go-template={{range $k, $v := .data}}{{$k}}={{$v | base64decode}}{{"\n"}}{{end}}'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KubectlCommand(tt.context, ref, tt.key); got != tt.want {
+				t.Errorf("Expected %s, but got %s", tt.want, got)
+			}
+		})
+	}
+}
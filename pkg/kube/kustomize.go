@@ -0,0 +1,162 @@
+package kube
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// KustomizationFile is the name of the file that kustomize reads from a directory.
+const KustomizationFile = "kustomization.yaml"
+
+// secretGenerator is a secretGenerator entry of a kustomization.yaml.
+type secretGenerator struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	Type      corev1.SecretType `json:"type,omitempty"`
+	Envs      []string          `json:"envs,omitempty"`
+	Files     []string          `json:"files,omitempty"`
+	Options   generatorOptions  `json:"options"`
+}
+
+// generatorOptions are the options of a secretGenerator entry.
+type generatorOptions struct {
+	DisableNameSuffixHash bool              `json:"disableNameSuffixHash"`
+	Labels                map[string]string `json:"labels,omitempty"`
+	Annotations           map[string]string `json:"annotations,omitempty"`
+}
+
+// WriteKustomizeExport reconstructs a secretGenerator for a live secret in dir.
+// Single-line text values go into <name>.env, all others into one file per key
+// under <name>/. The generator is added to dir/kustomization.yaml, replacing any
+// generator with the same name and keeping the rest of the file.
+func WriteKustomizeExport(clientset Client, ref SecretRef, dir string) error {
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return err
+	}
+	generator, files, err := buildSecretGenerator(secret)
+	if err != nil {
+		return err
+	}
+	for name, content := range files {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0o600); err != nil {
+			return fmt.Errorf("failed to write '%s': %w", target, err)
+		}
+	}
+	return addSecretGenerator(filepath.Join(dir, KustomizationFile), generator)
+}
+
+// buildSecretGenerator builds the secretGenerator entry for a secret and the
+// files it refers to, keyed by their path relative to the kustomization.
+func buildSecretGenerator(secret *corev1.Secret) (secretGenerator, map[string][]byte, error) {
+	generator := secretGenerator{
+		Name:      secret.Name,
+		Namespace: secret.Namespace,
+		// Keep the live name, which workloads refer to, instead of adding a hash suffix.
+		Options: generatorOptions{DisableNameSuffixHash: true, Labels: secret.Labels},
+	}
+	if secret.Type != corev1.SecretTypeOpaque {
+		generator.Type = secret.Type
+	}
+	// The generator is meant to be committed, so the annotations of rotations, which
+	// may hold previous values, are left out as in sealed and sops exports.
+	for key, value := range withoutRotationAnnotations(secret.Annotations) {
+		if key == lastAppliedAnnotation {
+			continue
+		}
+		if generator.Options.Annotations == nil {
+			generator.Options.Annotations = make(map[string]string)
+		}
+		generator.Options.Annotations[key] = value
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		if err := checkFileName(key); err != nil {
+			return generator, nil, err
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	files := make(map[string][]byte)
+	var env strings.Builder
+	for _, key := range keys {
+		value := secret.Data[key]
+		if fitsEnvFile(value) {
+			env.WriteString(key + "=" + string(value) + "\n")
+			continue
+		}
+		name := path.Join(secret.Name, key)
+		files[name] = value
+		generator.Files = append(generator.Files, key+"="+name)
+	}
+	if env.Len() > 0 {
+		envName := secret.Name + ".env"
+		files[envName] = []byte(env.String())
+		generator.Envs = []string{envName}
+	}
+	return generator, files, nil
+}
+
+// fitsEnvFile reports whether a value survives a round trip through an env file
+// unchanged: single-line text without surrounding whitespace or quotes.
+func fitsEnvFile(value []byte) bool {
+	s := string(value)
+	return utf8.Valid(value) && !strings.ContainsAny(s, "\r\n") && strings.TrimSpace(s) == s &&
+		!strings.HasPrefix(s, `"`) && !strings.HasPrefix(s, "'")
+}
+
+// addSecretGenerator adds a generator to a kustomization file, creating the file
+// if needed and replacing any generator with the same name.
+func addSecretGenerator(file string, generator secretGenerator) error {
+	kustomization := map[string]any{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+	}
+	content, err := os.ReadFile(file)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(content, &kustomization); err != nil {
+			return fmt.Errorf("failed to parse '%s': %w", file, err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	// Other generators are kept as they are, including fields kds does not know about.
+	var existing []any
+	if list, ok := kustomization["secretGenerator"].([]any); ok {
+		existing = list
+	}
+	generators := make([]any, 0, len(existing)+1)
+	for _, g := range existing {
+		if m, ok := g.(map[string]any); ok && m["name"] == generator.Name {
+			if ns, _ := m["namespace"].(string); ns == generator.Namespace {
+				continue
+			}
+		}
+		generators = append(generators, g)
+	}
+	kustomization["secretGenerator"] = append(generators, generator)
+
+	content, err = yaml.Marshal(kustomization)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, content, 0o600)
+}
@@ -0,0 +1,104 @@
+package kube
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+)
+
+// TestWriteKustomizeExport verifies the generated secretGenerator and its files.
+func TestWriteKustomizeExport(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", Labels: map[string]string{"team": "payments"},
+			Annotations: map[string]string{
+				"owner":                                "payments",
+				lastAppliedAnnotation:                  `{"data":{"USER":"YWRtaW4="}}`,
+				PreviousValueAnnotationPrefix + "USER": "root",
+				RotatedAtAnnotationPrefix + "USER":     "2024-01-01T00:00:00Z",
+			}},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"USER":    []byte("admin"),
+			"ca.crt":  []byte("-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"),
+			"PADDING": []byte(" spaced "),
+		},
+	})
+	dir := t.TempDir()
+	existing := "resources:\n- deployment.yaml\nsecretGenerator:\n- name: other\n  literals:\n  - a=b\n- name: app\n  namespace: default\n  envs:\n  - stale.env\n"
+	if err := os.WriteFile(filepath.Join(dir, KustomizationFile), []byte(existing), 0o600); err != nil {
+		t.Fatalf("Failed to write kustomization: %v", err)
+	}
+
+	if err := WriteKustomizeExport(clientset, SecretRef{Namespace: "default", Name: "app"}, dir); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	env, err := os.ReadFile(filepath.Join(dir, "app.env"))
+	if err != nil || string(env) != "USER=admin\n" {
+		t.Errorf("Expected app.env to contain 'USER=admin', but got %q (%v)", env, err)
+	}
+	for _, key := range []string{"ca.crt", "PADDING"} {
+		if _, err := os.Stat(filepath.Join(dir, "app", key)); err != nil {
+			t.Errorf("Expected key '%s' to be written as a file, but got: %v", key, err)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, KustomizationFile))
+	if err != nil {
+		t.Fatalf("Failed to read kustomization: %v", err)
+	}
+	var kustomization struct {
+		Resources       []string          `json:"resources"`
+		SecretGenerator []secretGenerator `json:"secretGenerator"`
+	}
+	if err := yaml.Unmarshal(content, &kustomization); err != nil {
+		t.Fatalf("Failed to parse kustomization: %v", err)
+	}
+	if len(kustomization.Resources) != 1 || len(kustomization.SecretGenerator) != 2 {
+		t.Fatalf("Expected the resources and other generator to be kept, but got:\n%s", content)
+	}
+	if !strings.Contains(string(content), "a=b") {
+		t.Errorf("Expected the literals of the other generator to be kept, but got:\n%s", content)
+	}
+	generator := kustomization.SecretGenerator[1]
+	if generator.Name != "app" || len(generator.Envs) != 1 || len(generator.Files) != 2 || !generator.Options.DisableNameSuffixHash {
+		t.Errorf("Expected a generator for 'app' with 1 env file and 2 files, but got %+v", generator)
+	}
+	if generator.Options.Labels["team"] != "payments" {
+		t.Errorf("Expected the labels to be kept, but got %v", generator.Options.Labels)
+	}
+	if len(generator.Options.Annotations) != 1 || generator.Options.Annotations["owner"] != "payments" {
+		t.Errorf("Expected only the owner annotation to be kept, but got %v", generator.Options.Annotations)
+	}
+}
+
+// TestAddSecretGenerator verifies that a generator without a namespace replaces
+// the one with the same name instead of being added again.
+func TestAddSecretGenerator(t *testing.T) {
+	file := filepath.Join(t.TempDir(), KustomizationFile)
+	generator := secretGenerator{Name: "app", Envs: []string{"app.env"}}
+	for range 2 {
+		if err := addSecretGenerator(file, generator); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Failed to read kustomization: %v", err)
+	}
+	var kustomization struct {
+		SecretGenerator []secretGenerator `json:"secretGenerator"`
+	}
+	if err := yaml.Unmarshal(content, &kustomization); err != nil {
+		t.Fatalf("Failed to parse kustomization: %v", err)
+	}
+	if len(kustomization.SecretGenerator) != 1 {
+		t.Errorf("Expected a single generator, but got:\n%s", content)
+	}
+}
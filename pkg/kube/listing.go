@@ -0,0 +1,57 @@
+package kube
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ItemSortKeys are the keys that SortItemsBy sorts secrets by.
+var ItemSortKeys = []string{"name", "namespace", "type", "age", "size", "keys"}
+
+// FilterItems keeps the secrets matching a label selector such as app=web, and of
+// a type, if they are not empty.
+func FilterItems(items ItemSource, selector string, secretType corev1.SecretType) (ItemSource, error) {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector '%s': %w", selector, err)
+	}
+	var kept ItemSource
+	for _, it := range items {
+		if parsed.Matches(labels.Set(it.Labels)) && (secretType == "" || it.SecretType == secretType) {
+			kept = append(kept, it)
+		}
+	}
+	return kept, nil
+}
+
+// SortItemsBy sorts secrets by one of ItemSortKeys, then by namespace and name.
+// Ages, sizes, and key counts sort the newest, largest, and fullest first, so
+// that the head of the list is what stands out.
+func SortItemsBy(items ItemSource, key string) error {
+	var compare func(a, b Item) int
+	switch key {
+	case "name":
+		compare = func(a, b Item) int { return strings.Compare(a.Name, b.Name) }
+	case "namespace":
+		compare = func(a, b Item) int { return strings.Compare(a.Namespace, b.Namespace) }
+	case "type":
+		compare = func(a, b Item) int { return strings.Compare(string(a.SecretType), string(b.SecretType)) }
+	case "age":
+		compare = func(a, b Item) int { return b.Created.Compare(a.Created) }
+	case "size":
+		compare = func(a, b Item) int { return cmp.Compare(b.Size, a.Size) }
+	case "keys":
+		compare = func(a, b Item) int { return cmp.Compare(b.Keys, a.Keys) }
+	default:
+		return fmt.Errorf("invalid sort key '%s': use one of %s", key, strings.Join(ItemSortKeys, ", "))
+	}
+	slices.SortStableFunc(items, func(a, b Item) int {
+		return cmp.Or(compare(a, b), strings.Compare(a.Namespace, b.Namespace), strings.Compare(a.Name, b.Name))
+	})
+	return nil
+}
@@ -0,0 +1,55 @@
+package kube
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestFilterAndSortItems verifies that secrets are filtered by label selector and
+// type, and sorted by the requested key.
+func TestFilterAndSortItems(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	items := ItemSource{
+		{Name: "web-tls", Namespace: "prod", SecretType: corev1.SecretTypeTLS, Size: 4096, Keys: 2, Created: now.Add(-48 * time.Hour), Labels: map[string]string{"app": "web"}},
+		{Name: "api", Namespace: "prod", SecretType: corev1.SecretTypeOpaque, Size: 128, Keys: 5, Created: now, Labels: map[string]string{"app": "web"}},
+		{Name: "db", Namespace: "dev", SecretType: corev1.SecretTypeOpaque, Size: 64, Keys: 1, Created: now.Add(-time.Hour)},
+	}
+	names := func(items ItemSource) []string {
+		out := make([]string, len(items))
+		for i, it := range items {
+			out[i] = it.Name
+		}
+		return out
+	}
+
+	t.Run("should filter by selector and type", func(t *testing.T) {
+		kept, err := FilterItems(items, "app=web", corev1.SecretTypeOpaque)
+		if err != nil || len(kept) != 1 || kept[0].Name != "api" {
+			t.Errorf("Expected only api, but got %v, %v", names(kept), err)
+		}
+		if _, err := FilterItems(items, "app in (web", ""); err == nil {
+			t.Error("Expected an error for an invalid selector, but got none")
+		}
+	})
+
+	for key, want := range map[string]string{"name": "api db web-tls", "age": "api db web-tls", "size": "web-tls api db", "keys": "api web-tls db", "namespace": "db api web-tls"} {
+		t.Run("should sort by "+key, func(t *testing.T) {
+			sorted := append(ItemSource(nil), items...)
+			if err := SortItemsBy(sorted, key); err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+			if got := names(sorted); strings.Join(got, " ") != want {
+				t.Errorf("Expected %s, but got %v", want, got)
+			}
+		})
+	}
+
+	t.Run("should reject unknown sort keys", func(t *testing.T) {
+		if err := SortItemsBy(items, "color"); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+}
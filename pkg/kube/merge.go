@@ -0,0 +1,118 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Resolutions of a MergeConflict.
+const (
+	KeepLeft  = "left"  // Keep the value merged so far.
+	KeepRight = "right" // Keep the value of the secret being merged.
+	RenameKey = "rename"
+)
+
+// MergeConflict is a key that two secrets being merged both have, with different
+// values.
+type MergeConflict struct {
+	Key   string
+	Left  string // The secret the value merged so far comes from.
+	Right string // The secret being merged.
+}
+
+// MergeResolution tells how to resolve a MergeConflict. With RenameKey, the value
+// of the right secret is kept under NewKey.
+type MergeResolution struct {
+	Keep   string
+	NewKey string
+}
+
+// PrepareMerge returns the mutation that merges the data and labels of secrets of
+// a namespace, in order, into the secret into. If it already exists, it is merged
+// into as the leftmost secret. Keys with the same value in several secrets are
+// merged silently, and resolve is called for those with different values.
+func PrepareMerge(clientset Client, namespace string, names []string, into string, resolve func(MergeConflict) (MergeResolution, error)) (Mutation, error) {
+	var before *corev1.Secret
+	merged := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: into, Namespace: namespace}, Data: map[string][]byte{}}
+	origins := make(map[string]string)
+	existing, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), into, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		if isImmutable(existing) {
+			return Mutation{}, ErrImmutable
+		}
+		before, merged = existing, existing.DeepCopy()
+		for key := range merged.Data {
+			origins[key] = into
+		}
+	case !apierrors.IsNotFound(err):
+		return Mutation{}, fmt.Errorf("failed to get secret '%s': %w", SecretRef{Namespace: namespace, Name: into}, err)
+	}
+	for _, name := range names {
+		if name == into {
+			continue
+		}
+		secret, err := GetSecret(clientset, SecretRef{Namespace: namespace, Name: name})
+		if err != nil {
+			return Mutation{}, err
+		}
+		if merged.Type == "" {
+			merged.Type = secret.Type
+		} else if secret.Type != merged.Type {
+			return Mutation{}, fmt.Errorf("secret '%s' has type %s, but the merged secret has type %s", name, secret.Type, merged.Type)
+		}
+		for _, key := range SortedKeys(secret.Data) {
+			value := secret.Data[key]
+			if old, ok := merged.Data[key]; ok && !bytes.Equal(old, value) {
+				resolved, err := resolveConflict(merged.Data, MergeConflict{Key: key, Left: origins[key], Right: name}, resolve)
+				if err != nil {
+					return Mutation{}, err
+				}
+				if resolved == "" {
+					continue
+				}
+				key = resolved
+			}
+			merged.Data[key] = value
+			origins[key] = name
+		}
+		for key, value := range secret.Labels {
+			if _, ok := merged.Labels[key]; !ok {
+				if merged.Labels == nil {
+					merged.Labels = make(map[string]string)
+				}
+				merged.Labels[key] = value
+			}
+		}
+	}
+	return Mutation{Before: before, After: merged}, nil
+}
+
+// resolveConflict returns the key under which to store the value of the right
+// secret, or an empty key to drop it.
+func resolveConflict(data map[string][]byte, conflict MergeConflict, resolve func(MergeConflict) (MergeResolution, error)) (string, error) {
+	resolution, err := resolve(conflict)
+	if err != nil {
+		return "", err
+	}
+	switch resolution.Keep {
+	case KeepLeft:
+		return "", nil
+	case KeepRight:
+		return conflict.Key, nil
+	case RenameKey:
+		if _, ok := data[resolution.NewKey]; ok || resolution.NewKey == "" {
+			return "", fmt.Errorf("cannot rename key '%s' of secret '%s' to '%s': the key is empty or taken", conflict.Key, conflict.Right, resolution.NewKey)
+		}
+		if err := ValidateKey(resolution.NewKey); err != nil {
+			return "", fmt.Errorf("cannot rename key '%s' of secret '%s': %w", conflict.Key, conflict.Right, err)
+		}
+		return resolution.NewKey, nil
+	}
+	return "", fmt.Errorf("invalid resolution '%s' of key '%s'", resolution.Keep, conflict.Key)
+}
@@ -0,0 +1,75 @@
+package kube
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestPrepareMerge verifies that secrets are merged in order, resolving conflicts.
+func TestPrepareMerge(t *testing.T) {
+	secret := func(name string, labels map[string]string, data map[string]string) *corev1.Secret {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels}, Type: corev1.SecretTypeOpaque, Data: map[string][]byte{}}
+		for key, value := range data {
+			s.Data[key] = []byte(value)
+		}
+		return s
+	}
+	clientset := fake.NewSimpleClientset(
+		secret("api-db", map[string]string{"team": "api"}, map[string]string{"url": "postgres://db", "password": "a"}),
+		secret("api-cache", map[string]string{"team": "cache"}, map[string]string{"url": "redis://cache", "password": "a", "ttl": "60"}),
+	)
+
+	t.Run("should resolve conflicts and merge equal values silently", func(t *testing.T) {
+		var conflicts []MergeConflict
+		mu, err := PrepareMerge(clientset, "default", []string{"api-db", "api-cache"}, "api", func(c MergeConflict) (MergeResolution, error) {
+			conflicts = append(conflicts, c)
+			return MergeResolution{Keep: RenameKey, NewKey: "cache-url"}, nil
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if !reflect.DeepEqual(conflicts, []MergeConflict{{Key: "url", Left: "api-db", Right: "api-cache"}}) {
+			t.Errorf("Expected a single conflict on url, but got %v", conflicts)
+		}
+		expected := map[string][]byte{"url": []byte("postgres://db"), "cache-url": []byte("redis://cache"), "password": []byte("a"), "ttl": []byte("60")}
+		if mu.Before != nil || !reflect.DeepEqual(mu.After.Data, expected) {
+			t.Errorf("Expected a new secret with %v, but got %v", expected, mu.After.Data)
+		}
+		if mu.After.Labels["team"] != "api" || mu.After.Type != corev1.SecretTypeOpaque {
+			t.Errorf("Expected the labels and type of the first secret, but got %v and %s", mu.After.Labels, mu.After.Type)
+		}
+	})
+
+	t.Run("should keep the right value", func(t *testing.T) {
+		mu, err := PrepareMerge(clientset, "default", []string{"api-db", "api-cache"}, "api-db", func(MergeConflict) (MergeResolution, error) {
+			return MergeResolution{Keep: KeepRight}, nil
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if mu.Before == nil || string(mu.After.Data["url"]) != "redis://cache" {
+			t.Errorf("Expected an update of api-db with the url of api-cache, but got %v", mu.After.Data)
+		}
+	})
+
+	t.Run("should refuse to rename onto a taken key", func(t *testing.T) {
+		_, err := PrepareMerge(clientset, "default", []string{"api-db", "api-cache"}, "api", func(MergeConflict) (MergeResolution, error) {
+			return MergeResolution{Keep: RenameKey, NewKey: "password"}, nil
+		})
+		if err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+	t.Run("should refuse to rename to an invalid key", func(t *testing.T) {
+		_, err := PrepareMerge(clientset, "default", []string{"api-db", "api-cache"}, "api", func(MergeConflict) (MergeResolution, error) {
+			return MergeResolution{Keep: RenameKey, NewKey: "cache/url"}, nil
+		})
+		if err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+}
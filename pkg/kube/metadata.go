@@ -0,0 +1,107 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// The metadata fields that can be edited with 'kds label' and 'kds annotate'.
+const (
+	FieldLabels      = "labels"
+	FieldAnnotations = "annotations"
+)
+
+// MetadataChange is a single label or annotation edit: set key to value, or remove key.
+type MetadataChange struct {
+	key    string
+	value  string
+	remove bool
+}
+
+// jsonPatchOp is a single RFC 6902 JSON patch operation.
+type jsonPatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
+
+// ParseMetadataChanges parses kubectl-style "key=value" and "key-" arguments and
+// validates them for the given field.
+func ParseMetadataChanges(field string, args []string) ([]MetadataChange, error) {
+	changes := make([]MetadataChange, 0, len(args))
+	for _, arg := range args {
+		change := MetadataChange{}
+		if key, value, found := strings.Cut(arg, "="); found {
+			change.key, change.value = key, value
+		} else if strings.HasSuffix(arg, "-") {
+			change.key, change.remove = strings.TrimSuffix(arg, "-"), true
+		} else {
+			return nil, fmt.Errorf("invalid argument '%s': expected key=value or key-", arg)
+		}
+		if errs := validation.IsQualifiedName(change.key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid key '%s': %s", change.key, strings.Join(errs, "; "))
+		}
+		if field == FieldLabels && !change.remove {
+			if errs := validation.IsValidLabelValue(change.value); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid label value '%s': %s", change.value, strings.Join(errs, "; "))
+			}
+		}
+		changes = append(changes, change)
+	}
+	if len(changes) == 0 {
+		return nil, errors.New("no changes given")
+	}
+	return changes, nil
+}
+
+// buildMetadataPatch builds a JSON patch applying the changes to the current values
+// of a metadata field. Removing a key that is not set is a no-op.
+func buildMetadataPatch(field string, current map[string]string, changes []MetadataChange) ([]byte, error) {
+	var ops []jsonPatchOp
+	if current == nil {
+		// JSON patch cannot add a member to a map that does not exist yet.
+		ops = append(ops, jsonPatchOp{Op: "add", Path: "/metadata/" + field, Value: map[string]string{}})
+		current = map[string]string{}
+	}
+	for _, change := range changes {
+		path := "/metadata/" + field + "/" + escapeJSONPointer(change.key)
+		if change.remove {
+			if _, ok := current[change.key]; ok {
+				ops = append(ops, jsonPatchOp{Op: "remove", Path: path})
+			}
+			continue
+		}
+		ops = append(ops, jsonPatchOp{Op: "add", Path: path, Value: change.value})
+	}
+	return json.Marshal(ops)
+}
+
+// escapeJSONPointer escapes a map key for use as a JSON pointer segment (RFC 6901).
+func escapeJSONPointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// PatchMetadata applies label or annotation changes to a secret with a JSON patch.
+func PatchMetadata(clientset Client, ref SecretRef, field string, changes []MetadataChange) error {
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return err
+	}
+	current := secret.Labels
+	if field == FieldAnnotations {
+		current = secret.Annotations
+	}
+	patch, err := buildMetadataPatch(field, current, changes)
+	if err != nil {
+		return err
+	}
+	_, err = clientset.CoreV1().Secrets(ref.Namespace).Patch(context.TODO(), ref.Name, types.JSONPatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	return err
+}
@@ -0,0 +1,84 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestParseMetadataChanges verifies parsing and validation of "key=value" and "key-" arguments.
+func TestParseMetadataChanges(t *testing.T) {
+	t.Run("should parse additions and removals", func(t *testing.T) {
+		changes, err := ParseMetadataChanges(FieldLabels, []string{"team=payments", "legacy-"})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if len(changes) != 2 || changes[0].key != "team" || changes[0].value != "payments" || changes[0].remove {
+			t.Errorf("Expected team=payments as first change, but got %+v", changes)
+		}
+		if !changes[1].remove || changes[1].key != "legacy" {
+			t.Errorf("Expected removal of 'legacy' as second change, but got %+v", changes[1])
+		}
+	})
+	t.Run("should allow any annotation value", func(t *testing.T) {
+		if _, err := ParseMetadataChanges(FieldAnnotations, []string{"note=rotated by ops"}); err != nil {
+			t.Errorf("Expected no error, but got: %v", err)
+		}
+	})
+	for _, arg := range []string{"no-equals-or-dash", "bad key=value", "key=bad value"} {
+		t.Run("should reject "+arg, func(t *testing.T) {
+			if _, err := ParseMetadataChanges(FieldLabels, []string{arg}); err == nil {
+				t.Errorf("Expected an error for '%s', but got nil", arg)
+			}
+		})
+	}
+}
+
+// TestBuildMetadataPatch verifies the generated JSON patch operations.
+func TestBuildMetadataPatch(t *testing.T) {
+	t.Run("should create the map when it does not exist", func(t *testing.T) {
+		patch, err := buildMetadataPatch(FieldLabels, nil, []MetadataChange{{key: "team", value: "payments"}})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		expected := `[{"op":"add","path":"/metadata/labels","value":{}},{"op":"add","path":"/metadata/labels/team","value":"payments"}]`
+		if string(patch) != expected {
+			t.Errorf("Expected %s, but got %s", expected, patch)
+		}
+	})
+	t.Run("should escape keys and skip removal of missing keys", func(t *testing.T) {
+		current := map[string]string{"example.com/owner": "alice"}
+		changes := []MetadataChange{{key: "example.com/owner", remove: true}, {key: "missing", remove: true}}
+		patch, err := buildMetadataPatch(FieldAnnotations, current, changes)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		expected := `[{"op":"remove","path":"/metadata/annotations/example.com~1owner"}]`
+		if string(patch) != expected {
+			t.Errorf("Expected %s, but got %s", expected, patch)
+		}
+	})
+}
+
+// TestPatchMetadata verifies that label changes are applied to the secret.
+func TestPatchMetadata(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-db", Namespace: "default", Labels: map[string]string{"legacy": "true"}},
+	})
+	ref := SecretRef{Namespace: "default", Name: "app-db"}
+	changes := []MetadataChange{{key: "team", value: "payments"}, {key: "legacy", remove: true}}
+
+	if err := PatchMetadata(clientset, ref, FieldLabels, changes); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	secret, err := clientset.CoreV1().Secrets("default").Get(context.TODO(), "app-db", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(secret.Labels) != 1 || secret.Labels["team"] != "payments" {
+		t.Errorf("Expected labels {team: payments}, but got %v", secret.Labels)
+	}
+}
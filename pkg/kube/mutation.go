@@ -0,0 +1,332 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Values of the --dry-run flag.
+const (
+	DryRunNone   = "none"
+	DryRunClient = "client"
+	DryRunServer = "server"
+)
+
+// FieldManager is the field manager of the changes made by kds, so that the fields
+// it sets can be told apart from those of the operators managing the same secret.
+const FieldManager = "kds"
+
+// Mutation is a pending create or update of a secret.
+type Mutation struct {
+	Before *corev1.Secret // The current secret, or nil if it is to be created.
+	After  *corev1.Secret
+	Force  bool // Take over the fields that other managers applied with other values.
+}
+
+// FieldConflict is a field that a change sets to a value other than the one
+// another manager applied.
+type FieldConflict struct {
+	Field   string // e.g. ".data.password".
+	Manager string
+}
+
+// ConflictError is returned when a change conflicts with the fields applied by
+// other managers. Forcing the change takes them over.
+type ConflictError struct {
+	Conflicts []FieldConflict
+}
+
+func (e *ConflictError) Error() string {
+	fields := make([]string, len(e.Conflicts))
+	for i, conflict := range e.Conflicts {
+		fields[i] = fmt.Sprintf("%s (%s)", conflict.Field, conflict.Manager)
+	}
+	return "conflict with the fields applied by other managers: " + strings.Join(fields, ", ")
+}
+
+// Ref returns the reference of the secret being changed.
+func (mu Mutation) Ref() SecretRef {
+	return SecretRef{Namespace: mu.After.Namespace, Name: mu.After.Name}
+}
+
+// Apply sends the create or update request, optionally as a server-side dry run,
+// and returns the secret as stored (or as it would be stored) by the server.
+// Updates are sent with server-side apply, so that kds owns the fields it sets and
+// the operators managing the same secret keep theirs.
+func (mu Mutation) Apply(clientset Client, dryRun bool) (*corev1.Secret, error) {
+	var dryRunOpts []string
+	if dryRun {
+		dryRunOpts = []string{metav1.DryRunAll}
+	}
+	if mu.Before != nil {
+		result, err := mu.update(clientset, dryRunOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update secret '%s': %w", mu.Ref(), err)
+		}
+		return result, nil
+	}
+	if err := CheckSecretQuota(clientset, mu.After.Namespace, 1); err != nil {
+		return nil, err
+	}
+	secrets := clientset.CoreV1().Secrets(mu.After.Namespace)
+	result, err := secrets.Create(context.TODO(), mu.After, metav1.CreateOptions{DryRun: dryRunOpts, FieldManager: FieldManager})
+	if apierrors.IsAlreadyExists(err) {
+		return nil, fmt.Errorf("secret '%s' already exists", mu.Ref())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secret '%s': %w", mu.Ref(), err)
+	}
+	return result, nil
+}
+
+// update applies the fields the change sets, at the version the secret was read
+// at, so that kds only owns the fields it changed. The keys, labels, and
+// annotations it removes are left out of the apply, which removes those that kds
+// alone applied. Server-side apply cannot remove the others, owned by other
+// managers or set by kds outside of an apply, so a change that also removes them
+// is checked for conflicts with a dry-run apply, then sent as a single JSON patch,
+// which is applied whole or not at all.
+func (mu Mutation) update(clientset Client, dryRunOpts []string) (*corev1.Secret, error) {
+	body, err := json.Marshal(mu.applyConfiguration())
+	if err != nil {
+		return nil, err
+	}
+	applied, others := fieldsAppliedBy(mu.Before), fieldsManagedByOthers(mu.Before)
+	removed := map[string][]string{
+		"data":                 keptByApply(removedKeys(mu.Before.Data, mu.After.Data), applied.Data, others.Data),
+		"metadata/labels":      keptByApply(removedKeys(mu.Before.Labels, mu.After.Labels), applied.Metadata.Labels, others.Metadata.Labels),
+		"metadata/annotations": keptByApply(removedKeys(mu.Before.Annotations, mu.After.Annotations), applied.Metadata.Annotations, others.Metadata.Annotations),
+	}
+	removes := len(removed["data"])+len(removed["metadata/labels"])+len(removed["metadata/annotations"]) > 0
+	secrets := clientset.CoreV1().Secrets(mu.After.Namespace)
+	opts := metav1.PatchOptions{DryRun: dryRunOpts, FieldManager: FieldManager, Force: &mu.Force}
+	if removes {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	result, err := secrets.Patch(context.TODO(), mu.After.Name, types.ApplyPatchType, body, opts)
+	if err != nil {
+		return nil, conflictError(err)
+	}
+	if !removes {
+		return result, nil
+	}
+	if len(dryRunOpts) > 0 {
+		result = result.DeepCopy()
+		for _, key := range removed["data"] {
+			delete(result.Data, key)
+		}
+		for _, key := range removed["metadata/labels"] {
+			delete(result.Labels, key)
+		}
+		for _, key := range removed["metadata/annotations"] {
+			delete(result.Annotations, key)
+		}
+		return result, nil
+	}
+	patch, err := json.Marshal(mu.jsonPatch(removed))
+	if err != nil {
+		return nil, err
+	}
+	return secrets.Patch(context.TODO(), mu.After.Name, types.JSONPatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+}
+
+// applyConfiguration returns what kds applies for the change: the fields it
+// changes, and those kds applied before, which server-side apply would otherwise
+// remove for being left out.
+func (mu Mutation) applyConfiguration() *corev1.Secret {
+	owned := fieldsAppliedBy(mu.Before)
+	applied := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            mu.After.Name,
+			Namespace:       mu.After.Namespace,
+			ResourceVersion: mu.After.ResourceVersion,
+			Labels:          appliedEntries(mu.Before.Labels, mu.After.Labels, owned.Metadata.Labels),
+			Annotations:     appliedEntries(mu.Before.Annotations, mu.After.Annotations, owned.Metadata.Annotations),
+		},
+		Data: appliedEntries(mu.Before.Data, mu.After.Data, owned.Data),
+	}
+	if mu.After.Type != mu.Before.Type || owned.Type != nil {
+		applied.Type = mu.After.Type
+	}
+	if isImmutable(mu.After) != isImmutable(mu.Before) || owned.Immutable != nil {
+		applied.Immutable = mu.After.Immutable
+	}
+	return applied
+}
+
+// jsonPatch returns the change as a JSON patch: a test of the version the secret
+// was read at, then the entries it sets and those it removes.
+func (mu Mutation) jsonPatch(removed map[string][]string) []jsonPatchOp {
+	var ops []jsonPatchOp
+	if mu.Before.ResourceVersion != "" {
+		ops = append(ops, jsonPatchOp{Op: "test", Path: "/metadata/resourceVersion", Value: mu.Before.ResourceVersion})
+	}
+	ops = append(ops, setEntriesOps("data", mu.Before.Data, appliedEntries(mu.Before.Data, mu.After.Data, nil))...)
+	ops = append(ops, setEntriesOps("metadata/labels", mu.Before.Labels, appliedEntries(mu.Before.Labels, mu.After.Labels, nil))...)
+	ops = append(ops, setEntriesOps("metadata/annotations", mu.Before.Annotations, appliedEntries(mu.Before.Annotations, mu.After.Annotations, nil))...)
+	if isImmutable(mu.After) != isImmutable(mu.Before) {
+		ops = append(ops, jsonPatchOp{Op: "add", Path: "/immutable", Value: isImmutable(mu.After)})
+	}
+	for _, field := range SortedKeys(removed) {
+		for _, key := range removed[field] {
+			ops = append(ops, jsonPatchOp{Op: "remove", Path: "/" + field + "/" + escapeJSONPointer(key)})
+		}
+	}
+	return ops
+}
+
+// setEntriesOps returns the JSON patch operations setting entries of the map at
+// field, which is created if the secret has none.
+func setEntriesOps[V any](field string, current, entries map[string]V) []jsonPatchOp {
+	if len(entries) == 0 {
+		return nil
+	}
+	if current == nil {
+		// JSON patch cannot add a member to a map that does not exist yet.
+		return []jsonPatchOp{{Op: "add", Path: "/" + field, Value: entries}}
+	}
+	ops := make([]jsonPatchOp, 0, len(entries))
+	for _, key := range SortedKeys(entries) {
+		ops = append(ops, jsonPatchOp{Op: "add", Path: "/" + field + "/" + escapeJSONPointer(key), Value: entries[key]})
+	}
+	return ops
+}
+
+// appliedEntries returns the entries of after that differ from before, and those
+// whose keys are in owned, the fields of a map that kds applied before.
+func appliedEntries[V string | []byte](before, after map[string]V, owned map[string]json.RawMessage) map[string]V {
+	var entries map[string]V
+	for key, value := range after {
+		_, applied := owned["f:"+key]
+		if previous, found := before[key]; found && string(previous) == string(value) && !applied {
+			continue
+		}
+		if entries == nil {
+			entries = make(map[string]V)
+		}
+		entries[key] = value
+	}
+	return entries
+}
+
+// appliedFields are the fields kds applied to a secret before, as recorded in
+// its managed fields.
+type appliedFields struct {
+	Data      map[string]json.RawMessage `json:"f:data"`
+	Type      json.RawMessage            `json:"f:type"`
+	Immutable json.RawMessage            `json:"f:immutable"`
+	Metadata  struct {
+		Labels      map[string]json.RawMessage `json:"f:labels"`
+		Annotations map[string]json.RawMessage `json:"f:annotations"`
+	} `json:"f:metadata"`
+}
+
+// fieldsAppliedBy returns the fields kds applied to a secret before. Those it
+// cannot read are left out.
+func fieldsAppliedBy(secret *corev1.Secret) appliedFields {
+	return managedFields(secret, isAppliedByKds)
+}
+
+// fieldsManagedByOthers returns the fields of a secret that managers other than
+// the apply of kds own, including the updates made by kds. Those it cannot read
+// are left out.
+func fieldsManagedByOthers(secret *corev1.Secret) appliedFields {
+	return managedFields(secret, func(entry metav1.ManagedFieldsEntry) bool { return !isAppliedByKds(entry) })
+}
+
+// managedFields returns the fields of a secret owned by the managed fields
+// entries that match.
+func managedFields(secret *corev1.Secret, match func(metav1.ManagedFieldsEntry) bool) appliedFields {
+	var fields appliedFields
+	for _, entry := range secret.ManagedFields {
+		if match(entry) && entry.FieldsV1 != nil {
+			// Unmarshalling into the same maps adds the fields of each entry.
+			_ = json.Unmarshal(entry.FieldsV1.Raw, &fields)
+		}
+	}
+	return fields
+}
+
+// isAppliedByKds reports whether a managed fields entry is the one of the
+// server-side applies of kds.
+func isAppliedByKds(entry metav1.ManagedFieldsEntry) bool {
+	return entry.Manager == FieldManager && entry.Operation == metav1.ManagedFieldsOperationApply
+}
+
+// keptByApply returns the removed keys of a map that leaving them out of an
+// apply does not remove: those kds did not apply, or that other managers own too.
+func keptByApply(keys []string, applied, others map[string]json.RawMessage) []string {
+	var kept []string
+	for _, key := range keys {
+		_, byKds := applied["f:"+key]
+		_, byOthers := others["f:"+key]
+		if !byKds || byOthers {
+			kept = append(kept, key)
+		}
+	}
+	return kept
+}
+
+// removedKeys returns the keys of before that are no longer in after.
+func removedKeys[T any](before, after map[string]T) []string {
+	var keys []string
+	for _, key := range SortedKeys(before) {
+		if _, kept := after[key]; !kept {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// conflictError returns a ConflictError listing the fields of a failed apply that
+// other managers own, or err if it failed for another reason.
+func conflictError(err error) error {
+	var status apierrors.APIStatus
+	if !apierrors.IsConflict(err) || !errors.As(err, &status) || status.Status().Details == nil {
+		return err
+	}
+	conflictErr := &ConflictError{}
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		// The message names the manager, as in: conflict with "helm" using v1.
+		manager := cause.Message
+		if _, rest, found := strings.Cut(cause.Message, `"`); found {
+			manager, _, _ = strings.Cut(rest, `"`)
+		}
+		conflictErr.Conflicts = append(conflictErr.Conflicts, FieldConflict{Field: cause.Field, Manager: manager})
+	}
+	if len(conflictErr.Conflicts) == 0 {
+		return err
+	}
+	return conflictErr
+}
+
+// Preview runs the mutation as a server-side dry run and returns the changes the
+// server would make, including defaults and changes made by admission webhooks.
+func (mu Mutation) Preview(clientset Client) ([]DataChange, error) {
+	result, err := mu.Apply(clientset, true)
+	if err != nil {
+		return nil, err
+	}
+	return DiffSecrets(mu.Before, result), nil
+}
+
+// ValidateDryRun checks the value of the --dry-run flag.
+func ValidateDryRun(value string) error {
+	switch value {
+	case DryRunNone, DryRunClient, DryRunServer:
+		return nil
+	}
+	return fmt.Errorf("invalid --dry-run value '%s': must be none, client, or server", value)
+}
@@ -0,0 +1,150 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestMutationApplyConflicts verifies that updates are applied server-side as kds,
+// report the fields other managers applied, and take them over when forced, and
+// that only the removals server-side apply cannot make are sent as a JSON patch.
+func TestMutationApplyConflicts(t *testing.T) {
+	clientset := fake.NewClientset()
+	// The fake clientset stores dry runs; answer them with the secret as it is.
+	clientset.PrependReactor("patch", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchActionImpl)
+		if len(patch.PatchOptions.DryRun) == 0 {
+			return false, nil, nil
+		}
+		secret, err := clientset.Tracker().Get(patch.GetResource(), patch.GetNamespace(), patch.GetName())
+		return true, secret, err
+	})
+	ref := SecretRef{Namespace: "default", Name: "app-db"}
+	operator := []byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"app-db","namespace":"default"},"data":{"password":"b2xk"}}`)
+	if _, err := clientset.CoreV1().Secrets(ref.Namespace).Patch(context.TODO(), ref.Name, types.ApplyPatchType, operator, metav1.PatchOptions{FieldManager: "operator"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	before, err := GetSecret(clientset, ref)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	after := before.DeepCopy()
+	after.Data["password"] = []byte("new")
+	after.Data["user"] = []byte("admin")
+	mu := Mutation{Before: before, After: after}
+
+	t.Run("should report the fields applied by other managers", func(t *testing.T) {
+		_, err := mu.Apply(clientset, false)
+		var conflictErr *ConflictError
+		if !errors.As(err, &conflictErr) {
+			t.Fatalf("Expected a ConflictError, but got: %v", err)
+		}
+		if len(conflictErr.Conflicts) != 1 || conflictErr.Conflicts[0].Manager != "operator" || conflictErr.Conflicts[0].Field != ".data.password" {
+			t.Errorf("Expected a conflict on .data.password with operator, but got %+v", conflictErr.Conflicts)
+		}
+	})
+	t.Run("should take over the fields when forced", func(t *testing.T) {
+		mu := mu
+		mu.Force = true
+		result, err := mu.Apply(clientset, false)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if string(result.Data["password"]) != "new" || string(result.Data["user"]) != "admin" {
+			t.Errorf("Expected the changes to be applied, but got %v", result.Data)
+		}
+		managers := make(map[string]bool)
+		for _, field := range result.ManagedFields {
+			managers[field.Manager] = true
+		}
+		if !managers[FieldManager] {
+			t.Errorf("Expected the fields to be managed by %s, but got %v", FieldManager, managers)
+		}
+	})
+	jsonPatches := 0
+	clientset.PrependReactor("patch", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.PatchActionImpl).GetPatchType() == types.JSONPatchType {
+			jsonPatches++
+		}
+		return false, nil, nil
+	})
+	t.Run("should remove the keys kds applied with server-side apply", func(t *testing.T) {
+		before, _ := GetSecret(clientset, ref)
+		after := before.DeepCopy()
+		delete(after.Data, "user")
+		if _, err := (Mutation{Before: before, After: after}).Apply(clientset, false); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		secret, _ := GetSecret(clientset, ref)
+		if _, found := secret.Data["user"]; found {
+			t.Errorf("Expected user to be removed, but got %v", secret.Data)
+		}
+		if jsonPatches != 0 {
+			t.Errorf("Expected no JSON patch, but got %d", jsonPatches)
+		}
+		for _, entry := range secret.ManagedFields {
+			if entry.Manager == FieldManager && entry.Operation != metav1.ManagedFieldsOperationApply {
+				t.Errorf("Expected kds to only apply the secret, but got an entry for %s", entry.Operation)
+			}
+		}
+	})
+	t.Run("should only apply the fields it changes and keep those applied before", func(t *testing.T) {
+		operator := []byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"app-db","namespace":"default","labels":{"team":"payments"}},"data":{"token":"b3A="}}`)
+		if _, err := clientset.CoreV1().Secrets(ref.Namespace).Patch(context.TODO(), ref.Name, types.ApplyPatchType, operator, metav1.PatchOptions{FieldManager: "operator"}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		before, _ := GetSecret(clientset, ref)
+		after := before.DeepCopy()
+		after.Data["host"] = []byte("db.internal")
+		result, err := (Mutation{Before: before, After: after}).Apply(clientset, false)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if string(result.Data["password"]) != "new" || string(result.Data["host"]) != "db.internal" {
+			t.Errorf("Expected password to be kept and host to be added, but got %v", result.Data)
+		}
+		for _, entry := range result.ManagedFields {
+			if entry.Manager == FieldManager && (strings.Contains(string(entry.FieldsV1.Raw), `"f:token"`) || strings.Contains(string(entry.FieldsV1.Raw), `"f:team"`)) {
+				t.Errorf("Expected kds not to own the fields of the operator, but got %s", entry.FieldsV1.Raw)
+			}
+		}
+	})
+	t.Run("should remove the keys of other managers with a JSON patch", func(t *testing.T) {
+		before, _ := GetSecret(clientset, ref)
+		after := before.DeepCopy()
+		delete(after.Data, "token")
+		if _, err := (Mutation{Before: before, After: after}).Apply(clientset, false); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		secret, _ := GetSecret(clientset, ref)
+		if _, found := secret.Data["token"]; found || jsonPatches != 1 {
+			t.Errorf("Expected token to be removed with a JSON patch, but got %v after %d patch(es)", secret.Data, jsonPatches)
+		}
+	})
+	t.Run("should change nothing if the removals cannot be applied", func(t *testing.T) {
+		clientset.PrependReactor("patch", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.(k8stesting.PatchActionImpl).GetPatchType() != types.JSONPatchType {
+				return false, nil, nil
+			}
+			return true, nil, errors.New("the object has been modified")
+		})
+		before, _ := GetSecret(clientset, ref)
+		after := before.DeepCopy()
+		after.Data["password"] = []byte("newer")
+		delete(after.Labels, "team")
+		if _, err := (Mutation{Before: before, After: after}).Apply(clientset, false); err == nil {
+			t.Fatal("Expected an error, but got none")
+		}
+		if secret, _ := GetSecret(clientset, ref); string(secret.Data["password"]) != "new" || secret.Labels["team"] != "payments" {
+			t.Errorf("Expected the secret to be unchanged, but got %v and labels %v", secret.Data, secret.Labels)
+		}
+	})
+}
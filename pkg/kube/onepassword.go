@@ -0,0 +1,132 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// onePasswordItem holds the fields of a 1Password item, as printed by
+// 'op item get --format json' and read by 'op item create' and 'op item edit'.
+type onePasswordItem struct {
+	ID       string             `json:"id,omitempty"`
+	Title    string             `json:"title"`
+	Category string             `json:"category"`
+	Fields   []onePasswordField `json:"fields,omitempty"`
+	// Other holds the attributes kds does not change, which 'op item edit' needs
+	// back as they were.
+	Other map[string]json.RawMessage `json:"-"`
+}
+
+// onePasswordField is a field of a 1Password item.
+type onePasswordField struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type,omitempty"`
+	Purpose string          `json:"purpose,omitempty"`
+	Label   string          `json:"label,omitempty"`
+	Value   string          `json:"value,omitempty"`
+	Section json.RawMessage `json:"section,omitempty"`
+}
+
+// UnmarshalJSON keeps the attributes of an item that kds does not know.
+func (i *onePasswordItem) UnmarshalJSON(content []byte) error {
+	type plain onePasswordItem
+	if err := json.Unmarshal(content, (*plain)(i)); err != nil {
+		return err
+	}
+	var err error
+	i.Other, err = otherAttributes(content, "id", "title", "category", "fields")
+	return err
+}
+
+// MarshalJSON writes back the attributes of an item that kds does not know.
+func (i onePasswordItem) MarshalJSON() ([]byte, error) {
+	type plain onePasswordItem
+	content, err := json.Marshal(plain(i))
+	if err != nil {
+		return nil, err
+	}
+	return withOtherAttributes(content, i.Other)
+}
+
+// opArgs returns the arguments of an op command, with the vault of the store.
+func (s SecretStore) opArgs(args ...string) []string {
+	args = append([]string{"op"}, args...)
+	if s.vault != "" {
+		args = append(args, "--vault", s.vault)
+	}
+	return args
+}
+
+// getOnePasswordItem reads an item with 'op item get'. It returns nil if there is
+// no such item.
+func (s SecretStore) getOnePasswordItem(ctx context.Context, title string) (*onePasswordItem, error) {
+	out, err := s.run(ctx, s.opArgs("item", "get", title, "--format", "json"), nil, nil)
+	if err != nil && strings.Contains(err.Error(), "isn't an item") {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var item onePasswordItem
+	if err := json.Unmarshal(out, &item); err != nil {
+		return nil, fmt.Errorf("failed to parse the output of op: %w", err)
+	}
+	return &item, nil
+}
+
+// pullOnePassword reads the fields of a 1Password item, by label. The notes of the
+// item and empty fields are left out.
+func (s SecretStore) pullOnePassword(ctx context.Context, item StoreItem) (map[string][]byte, error) {
+	found, err := s.getOnePasswordItem(ctx, item.Item)
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no item '%s' in 1Password", item.Item)
+	}
+	data := make(map[string][]byte, len(found.Fields))
+	for _, field := range found.Fields {
+		if field.Label == "" || field.Value == "" || field.Purpose == "NOTES" {
+			continue
+		}
+		if _, duplicate := data[field.Label]; duplicate {
+			return nil, fmt.Errorf("item '%s' has several fields labeled '%s'", item.Item, field.Label)
+		}
+		data[field.Label] = []byte(field.Value)
+	}
+	return data, nil
+}
+
+// pushOnePassword sets the keys of a secret as concealed fields of a 1Password
+// item, by label, with 'op item edit', or creates the item as a secure note with
+// 'op item create'. The other fields of an existing item are kept. The item is
+// passed on stdin.
+func (s SecretStore) pushOnePassword(ctx context.Context, item StoreItem, data map[string][]byte) error {
+	existing, err := s.getOnePasswordItem(ctx, item.Item)
+	if err != nil {
+		return err
+	}
+	args := s.opArgs("item", "edit", item.Item)
+	if existing == nil {
+		existing = &onePasswordItem{Title: item.Item, Category: "SECURE_NOTE"}
+		args = s.opArgs("item", "create")
+	}
+	for _, key := range SortedKeys(data) {
+		i := 0
+		for i < len(existing.Fields) && existing.Fields[i].Label != key {
+			i++
+		}
+		if i == len(existing.Fields) {
+			existing.Fields = append(existing.Fields, onePasswordField{ID: key, Type: "CONCEALED", Label: key})
+		}
+		existing.Fields[i].Value = string(data[key])
+	}
+	stdin, err := json.Marshal(existing)
+	if err != nil {
+		return err
+	}
+	_, err = s.run(ctx, args, stdin, nil)
+	return err
+}
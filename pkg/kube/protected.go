@@ -0,0 +1,42 @@
+package kube
+
+import (
+	"fmt"
+	"path"
+)
+
+// ProtectedNamespaces are glob patterns, e.g. prod-*, matching the namespaces
+// whose values are only revealed, copied, or exported after an explicit
+// confirmation.
+type ProtectedNamespaces []string
+
+// CompileProtectedNamespaces validates the patterns of the configuration.
+func CompileProtectedNamespaces(patterns []string) (ProtectedNamespaces, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid protected namespace pattern '%s': %w", pattern, err)
+		}
+	}
+	return ProtectedNamespaces(patterns), nil
+}
+
+// Protects reports whether namespace matches one of the patterns.
+func (p ProtectedNamespaces) Protects(namespace string) bool {
+	for _, pattern := range p {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtectedRefs returns the referenced secrets that are in a protected namespace.
+func (p ProtectedNamespaces) ProtectedRefs(refs []SecretRef) []SecretRef {
+	var protected []SecretRef
+	for _, ref := range refs {
+		if p.Protects(ref.Namespace) {
+			protected = append(protected, ref)
+		}
+	}
+	return protected
+}
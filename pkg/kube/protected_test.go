@@ -0,0 +1,29 @@
+package kube
+
+import "testing"
+
+// TestProtectedNamespaces verifies that namespaces are matched against the glob patterns.
+func TestProtectedNamespaces(t *testing.T) {
+	protected, err := CompileProtectedNamespaces([]string{"prod-*", "kube-system"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	t.Run("should match the patterns", func(t *testing.T) {
+		for namespace, expected := range map[string]bool{"prod-eu": true, "kube-system": true, "prod": false, "dev": false} {
+			if got := protected.Protects(namespace); got != expected {
+				t.Errorf("Expected %s to be protected: %t, but got %t", namespace, expected, got)
+			}
+		}
+	})
+	t.Run("should return the protected secrets", func(t *testing.T) {
+		refs := protected.ProtectedRefs([]SecretRef{{Namespace: "dev", Name: "api"}, {Namespace: "prod-us", Name: "db"}})
+		if len(refs) != 1 || refs[0].Name != "db" {
+			t.Errorf("Expected only prod-us/db, but got %v", refs)
+		}
+	})
+	t.Run("should reject invalid patterns", func(t *testing.T) {
+		if _, err := CompileProtectedNamespaces([]string{"prod-["}); err == nil {
+			t.Error("Expected an error for an invalid pattern")
+		}
+	})
+}
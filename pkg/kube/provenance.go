@@ -0,0 +1,44 @@
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ManagedByLabel names the tool that manages a resource, e.g. Helm.
+const ManagedByLabel = "app.kubernetes.io/managed-by"
+
+// LastAppliedData returns the data of a secret as 'kubectl apply' last applied it,
+// from its last-applied-configuration annotation, with its stringData merged in.
+// It reports false if the secret was never applied with kubectl.
+func LastAppliedData(secret *corev1.Secret) (map[string][]byte, bool, error) {
+	annotation, found := secret.Annotations[corev1.LastAppliedConfigAnnotation]
+	if !found {
+		return nil, false, nil
+	}
+	var applied corev1.Secret
+	if err := json.Unmarshal([]byte(annotation), &applied); err != nil {
+		return nil, true, fmt.Errorf("invalid last-applied configuration: %w", err)
+	}
+	data := make(map[string][]byte, len(applied.Data)+len(applied.StringData))
+	for key, value := range applied.Data {
+		data[key] = value
+	}
+	for key, value := range applied.StringData {
+		data[key] = []byte(value)
+	}
+	return data, true, nil
+}
+
+// LastAppliedChanges compares the data of a secret with what 'kubectl apply' last
+// applied, so KeyAdded is a key added since. It reports false if the secret was
+// never applied with kubectl.
+func LastAppliedChanges(secret *corev1.Secret) ([]DataChange, bool, error) {
+	applied, found, err := LastAppliedData(secret)
+	if !found || err != nil {
+		return nil, found, err
+	}
+	return diffData(applied, secret.Data), true, nil
+}
@@ -0,0 +1,35 @@
+package kube
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestLastAppliedChanges verifies that the live data is compared with the data
+// last applied with kubectl.
+func TestLastAppliedChanges(t *testing.T) {
+	t.Run("should compare with the last-applied configuration", func(t *testing.T) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				corev1.LastAppliedConfigAnnotation: `{"apiVersion":"v1","kind":"Secret","data":{"password":"aHVudGVyMg=="},"stringData":{"user":"admin"}}`,
+			}},
+			Data: map[string][]byte{"password": []byte("changed"), "user": []byte("admin"), "token": []byte("x")},
+		}
+		changes, found, err := LastAppliedChanges(secret)
+		if err != nil || !found {
+			t.Fatalf("Expected the last-applied configuration to be found, but got %v, %v", found, err)
+		}
+		expected := []DataChange{{Key: "password", Kind: KeyChanged}, {Key: "token", Kind: KeyAdded}}
+		if !reflect.DeepEqual(changes, expected) {
+			t.Errorf("Expected %v, but got %v", expected, changes)
+		}
+	})
+	t.Run("should report secrets never applied with kubectl", func(t *testing.T) {
+		if _, found, err := LastAppliedChanges(&corev1.Secret{}); found || err != nil {
+			t.Errorf("Expected no last-applied configuration, but got %v, %v", found, err)
+		}
+	})
+}
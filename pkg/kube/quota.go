@@ -0,0 +1,69 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// secretCountResources are the ResourceQuota resources that limit the number of
+// secrets of a namespace.
+var secretCountResources = []corev1.ResourceName{corev1.ResourceSecrets, "count/secrets"}
+
+// SecretQuota is the limit on the number of secrets of a namespace, from the
+// ResourceQuota that leaves the least room.
+type SecretQuota struct {
+	Name string // The name of the ResourceQuota.
+	Used int64
+	Hard int64
+}
+
+// Remaining returns how many more secrets the quota allows.
+func (q *SecretQuota) Remaining() int64 {
+	return max(q.Hard-q.Used, 0)
+}
+
+// String describes the usage of the quota, e.g. "12/20 secrets".
+func (q *SecretQuota) String() string {
+	return fmt.Sprintf("%d/%d secrets", q.Used, q.Hard)
+}
+
+// GetSecretQuota returns the secret quota of a namespace, or nil if no
+// ResourceQuota limits its number of secrets.
+func GetSecretQuota(clientset Client, namespace string) (*SecretQuota, error) {
+	quotas, err := clientset.CoreV1().ResourceQuotas(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource quotas in namespace '%s': %w", namespace, err)
+	}
+	var tightest *SecretQuota
+	for _, quota := range quotas.Items {
+		for _, resource := range secretCountResources {
+			hard, ok := quota.Status.Hard[resource]
+			if !ok {
+				hard, ok = quota.Spec.Hard[resource]
+			}
+			if !ok {
+				continue
+			}
+			used := quota.Status.Used[resource]
+			candidate := &SecretQuota{Name: quota.Name, Used: used.Value(), Hard: hard.Value()}
+			if tightest == nil || candidate.Remaining() < tightest.Remaining() {
+				tightest = candidate
+			}
+		}
+	}
+	return tightest, nil
+}
+
+// CheckSecretQuota returns an error if creating count secrets in a namespace would
+// exceed its secret quota, before the API server refuses them one by one. Quotas
+// that cannot be read, e.g. for lack of RBAC permissions, are not checked.
+func CheckSecretQuota(clientset Client, namespace string, count int) error {
+	quota, err := GetSecretQuota(clientset, namespace)
+	if err != nil || quota == nil || int64(count) <= quota.Remaining() {
+		return nil
+	}
+	return fmt.Errorf("creating %d secret(s) would exceed the quota of namespace '%s': %s used (ResourceQuota '%s')", count, namespace, quota, quota.Name)
+}
@@ -0,0 +1,55 @@
+package kube
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestSecretQuota verifies that the ResourceQuota leaving the least room for
+// secrets is used, and that creates beyond it are refused early.
+func TestSecretQuota(t *testing.T) {
+	quota := func(name string, counted corev1.ResourceName, used, hard int64) *corev1.ResourceQuota {
+		return &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{counted: *resource.NewQuantity(hard, resource.DecimalSI)},
+				Used: corev1.ResourceList{counted: *resource.NewQuantity(used, resource.DecimalSI)},
+			},
+		}
+	}
+	clientset := fake.NewSimpleClientset(
+		quota("objects", corev1.ResourceSecrets, 10, 50),
+		quota("team", "count/secrets", 19, 20),
+	)
+
+	q, err := GetSecretQuota(clientset, "default")
+	if err != nil || q == nil || q.Name != "team" || q.String() != "19/20 secrets" {
+		t.Fatalf("Expected the team quota with 19/20 secrets, but got %+v (%v)", q, err)
+	}
+	if err := CheckSecretQuota(clientset, "default", 1); err != nil {
+		t.Errorf("Expected room for 1 secret, but got: %v", err)
+	}
+	if err := CheckSecretQuota(clientset, "default", 2); err == nil || !strings.Contains(err.Error(), "would exceed the quota") {
+		t.Errorf("Expected the quota to be exceeded, but got: %v", err)
+	}
+
+	t.Run("should not limit namespaces without quota", func(t *testing.T) {
+		if q, err := GetSecretQuota(clientset, "other"); q != nil || err != nil {
+			t.Errorf("Expected no quota, but got %+v (%v)", q, err)
+		}
+	})
+	t.Run("should refuse a copy beyond the quota", func(t *testing.T) {
+		full := fake.NewSimpleClientset(
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-db", Namespace: "staging"}},
+			quota("team", corev1.ResourceSecrets, 20, 20),
+		)
+		if err := CopySecret(full, SecretRef{Namespace: "staging", Name: "app-db"}, "default", false); err == nil {
+			t.Error("Expected the copy to be refused, but it succeeded")
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package kube
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RedactionMode is how the values of the keys matched by a redaction rule are shown.
+type RedactionMode string
+
+// Redaction modes, from the least to the most strict.
+const (
+	RedactNone    RedactionMode = ""
+	RedactConfirm RedactionMode = "confirm" // Masked until revealing them is confirmed.
+	RedactMask    RedactionMode = "mask"    // Always masked.
+)
+
+// RedactionRuleConfig declares a redaction rule of the configuration.
+type RedactionRuleConfig struct {
+	// Keys is a regular expression for the keys the rule applies to, e.g. _PRIVATE_KEY$.
+	Keys string `json:"keys"`
+	// Mode is mask (default) or confirm.
+	Mode RedactionMode `json:"mode,omitempty"`
+}
+
+// redactionRule is a compiled RedactionRuleConfig.
+type redactionRule struct {
+	keys *regexp.Regexp
+	mode RedactionMode
+}
+
+// RedactionPolicy masks the values of some keys regardless of how other values
+// are shown.
+type RedactionPolicy []redactionRule
+
+// CompileRedactionPolicy validates the redaction rules of the configuration.
+func CompileRedactionPolicy(configs []RedactionRuleConfig) (RedactionPolicy, error) {
+	policy := make(RedactionPolicy, 0, len(configs))
+	for _, c := range configs {
+		if c.Keys == "" {
+			return nil, errors.New("redaction rule needs keys")
+		}
+		keys, err := regexp.Compile(c.Keys)
+		if err != nil {
+			return nil, fmt.Errorf("redaction rule '%s' has invalid keys: %w", c.Keys, err)
+		}
+		rule := redactionRule{keys: keys, mode: c.Mode}
+		switch rule.mode {
+		case RedactNone:
+			rule.mode = RedactMask
+		case RedactMask, RedactConfirm:
+		default:
+			return nil, fmt.Errorf("redaction rule '%s' has invalid mode '%s': use mask or confirm", c.Keys, c.Mode)
+		}
+		policy = append(policy, rule)
+	}
+	return policy, nil
+}
+
+// ModeOf returns the strictest mode of the rules that match key.
+func (p RedactionPolicy) ModeOf(key string) RedactionMode {
+	mode := RedactNone
+	for _, rule := range p {
+		if !rule.keys.MatchString(key) {
+			continue
+		}
+		if rule.mode == RedactMask {
+			return RedactMask
+		}
+		mode = rule.mode
+	}
+	return mode
+}
+
+// SensitiveAnnotation reports whether an annotation may hold secret values: the
+// last-applied configuration of kubectl, which holds the whole secret, or the
+// previous value of a rotated key.
+func SensitiveAnnotation(key string) bool {
+	return key == lastAppliedAnnotation || strings.HasPrefix(key, PreviousValueAnnotationPrefix)
+}
+
+// WithoutSensitiveAnnotations returns a copy of annotations without the sensitive
+// ones, to be shown where values are masked.
+func WithoutSensitiveAnnotations(annotations map[string]string) map[string]string {
+	kept := make(map[string]string, len(annotations))
+	for key, value := range annotations {
+		if !SensitiveAnnotation(key) {
+			kept[key] = value
+		}
+	}
+	return kept
+}
+
+// KeysWithMode returns the sorted keys of data that the policy redacts with mode.
+func KeysWithMode[V any](p RedactionPolicy, data map[string]V, mode RedactionMode) []string {
+	var keys []string
+	for _, key := range SortedKeys(data) {
+		if p.ModeOf(key) == mode {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
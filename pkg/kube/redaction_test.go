@@ -0,0 +1,45 @@
+package kube
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestRedactionPolicy verifies that keys get the strictest mode of the rules matching them.
+func TestRedactionPolicy(t *testing.T) {
+	policy, err := CompileRedactionPolicy([]RedactionRuleConfig{
+		{Keys: "_PRIVATE_KEY$"},
+		{Keys: "^(password|API_TOKEN)$", Mode: RedactConfirm},
+		{Keys: "^API_", Mode: RedactConfirm},
+		{Keys: "^API_SIGNING_PRIVATE_KEY$", Mode: RedactConfirm},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	t.Run("should return the strictest mode", func(t *testing.T) {
+		for key, expected := range map[string]RedactionMode{
+			"TLS_PRIVATE_KEY":         RedactMask,
+			"API_SIGNING_PRIVATE_KEY": RedactMask,
+			"API_TOKEN":               RedactConfirm,
+			"password":                RedactConfirm,
+			"username":                RedactNone,
+		} {
+			if mode := policy.ModeOf(key); mode != expected {
+				t.Errorf("Expected mode '%s' for %s, but got '%s'", expected, key, mode)
+			}
+		}
+	})
+	t.Run("should list the keys with a mode", func(t *testing.T) {
+		data := map[string]string{"password": "", "username": "", "API_URL": ""}
+		if keys := KeysWithMode(policy, data, RedactConfirm); !reflect.DeepEqual(keys, []string{"API_URL", "password"}) {
+			t.Errorf("Expected API_URL and password, but got %v", keys)
+		}
+	})
+	t.Run("should reject invalid rules", func(t *testing.T) {
+		for _, c := range []RedactionRuleConfig{{Keys: ""}, {Keys: "("}, {Keys: "token", Mode: "hide"}} {
+			if _, err := CompileRedactionPolicy([]RedactionRuleConfig{c}); err == nil {
+				t.Errorf("Expected an error for %+v", c)
+			}
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// BrokenReference is a reference to a secret, or to a key of it, that does not
+// exist, and that keeps the pods of the referencing object from starting.
+type BrokenReference struct {
+	Secret string
+	Usage  SecretUsage
+	// SecretMissing is set when the whole secret is missing, not only the key.
+	SecretMissing bool
+}
+
+// String describes the reference and what is missing, e.g.
+// "Deployment/api (env: password): secret 'db' has no key 'password'".
+func (r BrokenReference) String() string {
+	if r.SecretMissing {
+		return fmt.Sprintf("%s: secret '%s' is missing", r.Usage, r.Secret)
+	}
+	return fmt.Sprintf("%s: secret '%s' has no key '%s'", r.Usage, r.Secret, r.Usage.key)
+}
+
+// FindBrokenReferences returns the references of a usage index to the secrets,
+// or the keys of them, that do not exist among the given secrets, sorted by secret
+// and referencing object. References marked optional are never broken, and neither
+// are those of ingresses and service accounts, which only fail at runtime.
+func FindBrokenReferences(usages UsageIndex, secrets []corev1.Secret) []BrokenReference {
+	existing := make(map[string]*corev1.Secret, len(secrets))
+	for i := range secrets {
+		existing[secrets[i].Name] = &secrets[i]
+	}
+	var broken []BrokenReference
+	for name, list := range usages {
+		secret := existing[name]
+		for _, usage := range list {
+			if usage.optional || usage.Kind == "Ingress" || usage.Kind == "ServiceAccount" {
+				continue
+			}
+			if secret == nil {
+				broken = append(broken, BrokenReference{Secret: name, Usage: usage, SecretMissing: true})
+				continue
+			}
+			if _, ok := secret.Data[usage.key]; usage.key != "" && !ok {
+				broken = append(broken, BrokenReference{Secret: name, Usage: usage})
+			}
+		}
+	}
+	sort.Slice(broken, func(i, j int) bool {
+		if broken[i].Secret != broken[j].Secret {
+			return broken[i].Secret < broken[j].Secret
+		}
+		return broken[i].Usage.String() < broken[j].Usage.String()
+	})
+	return broken
+}
+
+// ingressTLSKeys are the keys an ingress reads from its TLS secret.
+var ingressTLSKeys = []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey}
+
+// UnusedKeys returns the sorted keys of a secret that none of its usages consume.
+// A usage of the whole secret, such as envFrom or a volume without items, consumes
+// every key. Secrets without usages are orphans rather than secrets with unused
+// keys, and have none, as do the secrets that their owners manage.
+func UnusedKeys(secret *corev1.Secret, usages []SecretUsage) []string {
+	if len(usages) == 0 || len(secret.OwnerReferences) > 0 ||
+		secret.Type == corev1.SecretTypeServiceAccountToken || secret.Type == "helm.sh/release.v1" {
+		return nil
+	}
+	used := make(map[string]bool)
+	for _, usage := range usages {
+		switch {
+		case usage.Kind == "Ingress":
+			for _, key := range ingressTLSKeys {
+				used[key] = true
+			}
+		case usage.key == "":
+			return nil
+		default:
+			used[usage.key] = true
+		}
+	}
+	var unused []string
+	for _, key := range SortedKeys(secret.Data) {
+		if !used[key] {
+			unused = append(unused, key)
+		}
+	}
+	return unused
+}
@@ -0,0 +1,67 @@
+package kube
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestFindBrokenReferences verifies that references to missing secrets and keys
+// are reported, unless they are optional.
+func TestFindBrokenReferences(t *testing.T) {
+	optional := true
+	idx := make(UsageIndex)
+	idx.addPodSpec("Deployment", "api", &corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Env: []corev1.EnvVar{
+				{Name: "DB_PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}}},
+				{Name: "DB_USER", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "user"}}},
+				{Name: "FLAGS", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "flags"}, Key: "all", Optional: &optional}}},
+			},
+			EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "api-env"}}}},
+		}},
+	})
+	idx.add("api-tls", SecretUsage{Kind: "Ingress", Name: "web", via: "tls"})
+	secrets := []corev1.Secret{{ObjectMeta: metav1.ObjectMeta{Name: "db"}, Data: map[string][]byte{"user": []byte("admin")}}}
+
+	broken := FindBrokenReferences(idx, secrets)
+	expected := []string{
+		"Deployment/api (envFrom): secret 'api-env' is missing",
+		"Deployment/api (env: password): secret 'db' has no key 'password'",
+	}
+	if len(broken) != len(expected) {
+		t.Fatalf("Expected %d broken references, but got %v", len(expected), broken)
+	}
+	for i, ref := range broken {
+		if ref.String() != expected[i] {
+			t.Errorf("Expected %s, but got %s", expected[i], ref)
+		}
+	}
+}
+
+// TestUnusedKeys verifies that the keys no usage consumes are found.
+func TestUnusedKeys(t *testing.T) {
+	secret := &corev1.Secret{Data: map[string][]byte{"password": nil, "user": nil, "legacy-token": nil, "tls.crt": nil, "tls.key": nil}}
+	env := SecretUsage{Kind: "Deployment", Name: "api", via: "env", key: "password"}
+	volume := SecretUsage{Kind: "Deployment", Name: "api", via: "volume", key: "user"}
+	ingress := SecretUsage{Kind: "Ingress", Name: "web", via: "tls"}
+
+	t.Run("should find the keys nothing consumes", func(t *testing.T) {
+		unused := UnusedKeys(secret, []SecretUsage{env, volume, ingress})
+		if !reflect.DeepEqual(unused, []string{"legacy-token"}) {
+			t.Errorf("Expected legacy-token, but got %v", unused)
+		}
+	})
+	t.Run("should consider every key used by whole-secret usages", func(t *testing.T) {
+		if unused := UnusedKeys(secret, []SecretUsage{env, {Kind: "Deployment", Name: "worker", via: "envFrom"}}); unused != nil {
+			t.Errorf("Expected no unused keys, but got %v", unused)
+		}
+	})
+	t.Run("should leave orphans out", func(t *testing.T) {
+		if unused := UnusedKeys(secret, nil); unused != nil {
+			t.Errorf("Expected no unused keys, but got %v", unused)
+		}
+	})
+}
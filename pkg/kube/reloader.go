@@ -0,0 +1,139 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Annotations of Stakater Reloader and Wave, which restart workloads when the
+// secrets they use change.
+const (
+	reloaderAutoAnnotation       = "reloader.stakater.com/auto"
+	reloaderSecretAutoAnnotation = "secret.reloader.stakater.com/auto"
+	ReloaderSecretAnnotation     = "secret.reloader.stakater.com/reload"
+	reloaderSearchAnnotation     = "reloader.stakater.com/search"
+	reloaderMatchAnnotation      = "reloader.stakater.com/match"
+	waveAnnotation               = "wave.pusher.com/update-on-config-change"
+)
+
+// autoReloader returns the controller that restarts a workload when the named
+// secret changes, based on the annotations of the workload and of the secret, or
+// "" if the workload must be restarted by hand.
+func autoReloader(workloadAnnotations, secretAnnotations map[string]string, secretName string) string {
+	switch {
+	case workloadAnnotations[reloaderAutoAnnotation] == "true",
+		workloadAnnotations[reloaderSecretAutoAnnotation] == "true",
+		slices.Contains(splitList(workloadAnnotations[ReloaderSecretAnnotation]), secretName),
+		workloadAnnotations[reloaderSearchAnnotation] == "true" && secretAnnotations[reloaderMatchAnnotation] == "true":
+		return "Reloader"
+	case workloadAnnotations[waveAnnotation] == "true":
+		return "Wave"
+	}
+	return ""
+}
+
+// splitList splits a comma-separated annotation value.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// workloadAnnotations fetches the annotations of a workload.
+func workloadAnnotations(clientset Client, namespace string, workload WorkloadRef) (map[string]string, error) {
+	ctx, apps := context.TODO(), clientset.AppsV1()
+	var meta metav1.Object
+	var err error
+	switch workload.kind {
+	case "Deployment":
+		meta, err = apps.Deployments(namespace).Get(ctx, workload.name, metav1.GetOptions{})
+	case "StatefulSet":
+		meta, err = apps.StatefulSets(namespace).Get(ctx, workload.name, metav1.GetOptions{})
+	case "DaemonSet":
+		meta, err = apps.DaemonSets(namespace).Get(ctx, workload.name, metav1.GetOptions{})
+	default:
+		return nil, fmt.Errorf("unsupported workload kind %s", workload.kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return meta.GetAnnotations(), nil
+}
+
+// addReloaderAnnotation makes Reloader restart a workload whenever the named
+// secret changes, adding it to the workload's list of watched secrets.
+func addReloaderAnnotation(clientset Client, namespace string, workload WorkloadRef, secretName string) error {
+	annotations, err := workloadAnnotations(clientset, namespace, workload)
+	if err != nil {
+		return err
+	}
+	secrets := splitList(annotations[ReloaderSecretAnnotation])
+	if slices.Contains(secrets, secretName) {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]any{"metadata": map[string]any{"annotations": map[string]string{
+		ReloaderSecretAnnotation: strings.Join(append(secrets, secretName), ","),
+	}}})
+	if err != nil {
+		return err
+	}
+	ctx, apps := context.TODO(), clientset.AppsV1()
+	switch workload.kind {
+	case "Deployment":
+		_, err = apps.Deployments(namespace).Patch(ctx, workload.name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	case "StatefulSet":
+		_, err = apps.StatefulSets(namespace).Patch(ctx, workload.name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	case "DaemonSet":
+		_, err = apps.DaemonSets(namespace).Patch(ctx, workload.name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	}
+	return err
+}
+
+// AddReloaderAnnotations adds the Reloader annotation for a secret to every
+// workload and joins the errors.
+func AddReloaderAnnotations(clientset Client, ref SecretRef, workloads []WorkloadRef) error {
+	var errs []error
+	for _, workload := range workloads {
+		if err := addReloaderAnnotation(clientset, ref.Namespace, workload, ref.Name); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", workload, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ManualRestarts returns the workloads that are not restarted automatically.
+func ManualRestarts(workloads []WorkloadRef) []WorkloadRef {
+	var manual []WorkloadRef
+	for _, workload := range workloads {
+		if workload.autoReload == "" {
+			manual = append(manual, workload)
+		}
+	}
+	return manual
+}
+
+// AutoRestartNote describes the workloads that will restart on their own, e.g.
+// "auto-restarts Deployment/api via Reloader", or returns "" if there are none.
+func AutoRestartNote(workloads []WorkloadRef) string {
+	var names []string
+	for _, workload := range workloads {
+		if workload.autoReload != "" {
+			names = append(names, fmt.Sprintf("%s via %s", workload, workload.autoReload))
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return "auto-restarts " + strings.Join(names, ", ")
+}
@@ -0,0 +1,71 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestAutoReloader verifies the detection of Reloader and Wave annotations.
+func TestAutoReloader(t *testing.T) {
+	tests := []struct {
+		name     string
+		workload map[string]string
+		secret   map[string]string
+		expected string
+	}{
+		{"should detect reloader auto", map[string]string{reloaderAutoAnnotation: "true"}, nil, "Reloader"},
+		{"should detect secret auto", map[string]string{reloaderSecretAutoAnnotation: "true"}, nil, "Reloader"},
+		{"should detect a named secret", map[string]string{ReloaderSecretAnnotation: "other, app-db"}, nil, "Reloader"},
+		{"should ignore other named secrets", map[string]string{ReloaderSecretAnnotation: "other"}, nil, ""},
+		{"should detect search and match", map[string]string{reloaderSearchAnnotation: "true"}, map[string]string{reloaderMatchAnnotation: "true"}, "Reloader"},
+		{"should require match for search", map[string]string{reloaderSearchAnnotation: "true"}, nil, ""},
+		{"should detect wave", map[string]string{waveAnnotation: "true"}, nil, "Wave"},
+		{"should report no controller", nil, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := autoReloader(tt.workload, tt.secret, "app-db"); got != tt.expected {
+				t.Errorf("Expected '%s', but got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestAddReloaderAnnotation verifies that the secret is appended to the watched secrets.
+func TestAddReloaderAnnotation(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-db", Namespace: "default"}},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", Annotations: map[string]string{ReloaderSecretAnnotation: "other"}},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{{Name: "db", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "app-db"}}}},
+			}}},
+		},
+	)
+	ref := SecretRef{Namespace: "default", Name: "app-db"}
+	api := WorkloadRef{kind: "Deployment", name: "api"}
+
+	if err := AddReloaderAnnotations(clientset, ref, []WorkloadRef{api}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	deployment, _ := clientset.AppsV1().Deployments("default").Get(context.TODO(), "api", metav1.GetOptions{})
+	if got := deployment.Annotations[ReloaderSecretAnnotation]; got != "other,app-db" {
+		t.Errorf("Expected 'other,app-db', but got '%s'", got)
+	}
+
+	workloads, err := FindConsumers(clientset, ref)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(workloads) != 1 || workloads[0].autoReload != "Reloader" {
+		t.Errorf("Expected the deployment to be restarted by Reloader, but got %+v", workloads)
+	}
+	if len(ManualRestarts(workloads)) != 0 {
+		t.Errorf("Expected no manual restarts, but got %v", ManualRestarts(workloads))
+	}
+}
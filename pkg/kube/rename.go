@@ -0,0 +1,25 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RenameSecret recreates a secret under a new name and deletes the original.
+func RenameSecret(clientset Client, ref SecretRef, newName string) error {
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return err
+	}
+	renamed := cloneSecret(secret, ref.Namespace)
+	renamed.Name = newName
+	if _, err := clientset.CoreV1().Secrets(ref.Namespace).Create(context.TODO(), renamed, metav1.CreateOptions{FieldManager: FieldManager}); err != nil {
+		return fmt.Errorf("failed to create secret '%s/%s': %w", ref.Namespace, newName, err)
+	}
+	if err := DeleteSecret(clientset, ref); err != nil {
+		return fmt.Errorf("created '%s/%s' but failed to delete the original: %w", ref.Namespace, newName, err)
+	}
+	return nil
+}
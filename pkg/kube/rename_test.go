@@ -0,0 +1,37 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestRenameSecret verifies that a renamed secret keeps its data and metadata.
+func TestRenameSecret(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "db-creds",
+			Namespace:   "default",
+			Labels:      map[string]string{"team": "payments"},
+			Annotations: map[string]string{"owner": "alice"},
+		},
+		Data: map[string][]byte{"password": []byte("hunter2")},
+	})
+	if err := RenameSecret(clientset, SecretRef{Namespace: "default", Name: "db-creds"}, "app-db"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	renamed, err := clientset.CoreV1().Secrets("default").Get(context.TODO(), "app-db", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the renamed secret to exist, but got: %v", err)
+	}
+	if renamed.Labels["team"] != "payments" || renamed.Annotations["owner"] != "alice" || string(renamed.Data["password"]) != "hunter2" {
+		t.Errorf("Expected labels, annotations, and data to be preserved, but got %+v", renamed)
+	}
+	if _, err := clientset.CoreV1().Secrets("default").Get(context.TODO(), "db-creds", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected the original secret to be deleted, but got: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package kube
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// The supported values of the --format and --values flags of 'kds report'.
+const (
+	ReportMarkdown = "markdown"
+	ReportHTML     = "html"
+	ValuesMasked   = "masked"
+	ValuesHashed   = "hashed"
+)
+
+// reportRow is a single key of a secret as it appears in a report.
+type reportRow struct {
+	Key   string
+	Value string
+	Size  int
+}
+
+// ReportSection is a single secret as it appears in a report.
+type ReportSection struct {
+	Ref     string
+	Type    corev1.SecretType
+	Created string
+	Rows    []reportRow
+}
+
+// GetSecretsOrAll fetches the named secrets, or every secret in the namespace if no names are given.
+func GetSecretsOrAll(clientset Client, namespace string, names []string) ([]*corev1.Secret, error) {
+	if len(names) == 0 {
+		items, err := ListItems(clientset, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list secrets in namespace '%s': %w", namespace, err)
+		}
+		for _, it := range items {
+			names = append(names, it.Name)
+		}
+	}
+	secrets := make([]*corev1.Secret, 0, len(names))
+	for _, name := range names {
+		secret, err := GetSecret(clientset, SecretRef{Namespace: namespace, Name: name})
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, nil
+}
+
+// Checksum returns the hex-encoded SHA-256 digest of a raw secret value.
+func Checksum(value []byte) string {
+	sum := sha256.Sum256(value)
+	return hex.EncodeToString(sum[:])
+}
+
+// MaskValue hides a value entirely, keeping only its length.
+func MaskValue(value []byte) string {
+	return fmt.Sprintf("******** (%d bytes)", len(value))
+}
+
+// BuildReport converts secrets into report sections with masked or hashed values.
+func BuildReport(secrets []*corev1.Secret, hashed bool) []ReportSection {
+	sections := make([]ReportSection, 0, len(secrets))
+	for _, secret := range secrets {
+		keys := make([]string, 0, len(secret.Data))
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		section := ReportSection{
+			Ref:     SecretRef{Namespace: secret.Namespace, Name: secret.Name}.String(),
+			Type:    secret.Type,
+			Created: secret.CreationTimestamp.UTC().Format(time.RFC3339),
+		}
+		for _, key := range keys {
+			value := MaskValue(secret.Data[key])
+			if hashed {
+				value = "sha256:" + Checksum(secret.Data[key])
+			}
+			section.Rows = append(section.Rows, reportRow{Key: key, Value: value, Size: len(secret.Data[key])})
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// MarkdownReport renders the report as a Markdown document with one table per secret.
+func MarkdownReport(namespace string, sections []ReportSection) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Secrets report for namespace `%s`\n", namespace)
+	for _, section := range sections {
+		fmt.Fprintf(&b, "\n## `%s`\n\n", section.Ref)
+		fmt.Fprintf(&b, "Type: `%s` · Created: %s\n\n", section.Type, section.Created)
+		b.WriteString("| Key | Value | Size |\n|-----|-------|------|\n")
+		for _, row := range section.Rows {
+			fmt.Fprintf(&b, "| `%s` | `%s` | %d |\n", row.Key, row.Value, row.Size)
+		}
+	}
+	return b.String()
+}
+
+// htmlReportTemplate renders the report as a self-contained HTML page.
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Secrets report for namespace {{.Namespace}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+code { font-family: monospace; }
+</style>
+</head>
+<body>
+<h1>Secrets report for namespace <code>{{.Namespace}}</code></h1>
+{{range .Sections}}<h2><code>{{.Ref}}</code></h2>
+<p>Type: <code>{{.Type}}</code> · Created: {{.Created}}</p>
+<table>
+<tr><th>Key</th><th>Value</th><th>Size</th></tr>
+{{range .Rows}}<tr><td><code>{{.Key}}</code></td><td><code>{{.Value}}</code></td><td>{{.Size}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// WriteHTMLReport renders the report as HTML.
+func WriteHTMLReport(w io.Writer, namespace string, sections []ReportSection) error {
+	return htmlReportTemplate.Execute(w, struct {
+		Namespace string
+		Sections  []ReportSection
+	}{namespace, sections})
+}
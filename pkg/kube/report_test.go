@@ -0,0 +1,40 @@
+package kube
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestReport verifies that reports never contain plaintext values.
+func TestReport(t *testing.T) {
+	secrets := []*corev1.Secret{{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{"password": []byte("hunter2")},
+	}}
+	t.Run("should mask values in Markdown", func(t *testing.T) {
+		report := MarkdownReport("default", BuildReport(secrets, false))
+		if strings.Contains(report, "hunter2") {
+			t.Fatalf("Expected the report not to contain the plaintext value, but got:\n%s", report)
+		}
+		if !strings.Contains(report, "| `password` | `******** (7 bytes)` | 7 |") {
+			t.Errorf("Expected a masked row for 'password', but got:\n%s", report)
+		}
+	})
+	t.Run("should hash values in HTML", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteHTMLReport(&buf, "default", BuildReport(secrets, true)); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if strings.Contains(buf.String(), "hunter2") {
+			t.Fatalf("Expected the report not to contain the plaintext value, but got:\n%s", buf.String())
+		}
+		if !strings.Contains(buf.String(), "sha256:"+Checksum([]byte("hunter2"))) {
+			t.Errorf("Expected the checksum of the value, but got:\n%s", buf.String())
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// restartedAtAnnotation is the pod template annotation that `kubectl rollout restart` sets.
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// WorkloadRef identifies a workload that can be restarted.
+type WorkloadRef struct {
+	kind       string // Deployment, StatefulSet, or DaemonSet.
+	name       string
+	autoReload string // The controller that restarts it when the secret changes, if any.
+}
+
+// String returns the workload in kind/name form.
+func (w WorkloadRef) String() string { return w.kind + "/" + w.name }
+
+// FindConsumers lists the workloads whose pods read the given secret, and which
+// must be restarted to pick up changes to it, noting those that Reloader or Wave
+// restart automatically. Image pull secrets are skipped, as they are only used
+// when pulling new images.
+func FindConsumers(clientset Client, ref SecretRef) ([]WorkloadRef, error) {
+	idx, err := BuildUsageIndex(clientset, ref.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return nil, err
+	}
+	var workloads []WorkloadRef
+	seen := make(map[WorkloadRef]bool)
+	for _, usage := range idx[ref.Name] {
+		workload := WorkloadRef{kind: usage.Kind, name: usage.Name}
+		switch {
+		case usage.via == "imagePullSecrets", seen[workload]:
+			continue
+		case usage.Kind == "Deployment", usage.Kind == "StatefulSet", usage.Kind == "DaemonSet":
+			seen[workload] = true
+			annotations, err := workloadAnnotations(clientset, ref.Namespace, workload)
+			if err != nil {
+				return nil, err
+			}
+			workload.autoReload = autoReloader(annotations, secret.Annotations, ref.Name)
+			workloads = append(workloads, workload)
+		}
+	}
+	return workloads, nil
+}
+
+// restartWorkload triggers a rolling restart the way `kubectl rollout restart`
+// does, by stamping the pod template with the current time.
+func restartWorkload(clientset Client, namespace string, workload WorkloadRef, now time.Time) error {
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{"template": map[string]any{"metadata": map[string]any{
+			"annotations": map[string]string{restartedAtAnnotation: now.Format(time.RFC3339)},
+		}}},
+	})
+	if err != nil {
+		return err
+	}
+	ctx := context.TODO()
+	apps := clientset.AppsV1()
+	switch workload.kind {
+	case "Deployment":
+		_, err = apps.Deployments(namespace).Patch(ctx, workload.name, types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	case "StatefulSet":
+		_, err = apps.StatefulSets(namespace).Patch(ctx, workload.name, types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	case "DaemonSet":
+		_, err = apps.DaemonSets(namespace).Patch(ctx, workload.name, types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	default:
+		err = fmt.Errorf("cannot restart a %s", workload.kind)
+	}
+	return err
+}
+
+// RestartWorkloads restarts every workload and joins the errors.
+func RestartWorkloads(clientset Client, namespace string, workloads []WorkloadRef) error {
+	var errs []error
+	now := time.Now()
+	for _, workload := range workloads {
+		if err := restartWorkload(clientset, namespace, workload, now); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", workload, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UpdateTitle describes an update for its TUI confirmation, noting the workloads
+// that will restart on their own.
+func UpdateTitle(clientset Client, verb string, ref SecretRef) string {
+	title := verb + " " + ref.Name
+	// The note is informational; the update can be confirmed without it.
+	if workloads, err := FindConsumers(clientset, ref); err == nil {
+		if note := AutoRestartNote(workloads); note != "" {
+			title += ", which " + note
+		}
+	}
+	return title
+}
@@ -0,0 +1,59 @@
+package kube
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestRestartConsumers verifies finding and restarting the workloads that use a secret.
+func TestRestartConsumers(t *testing.T) {
+	envFrom := corev1.PodSpec{Containers: []corev1.Container{{
+		Name:    "app",
+		EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "app-db"}}}},
+		Env: []corev1.EnvVar{{Name: "PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "app-db"}, Key: "password",
+		}}}},
+	}}}
+	pullOnly := corev1.PodSpec{ImagePullSecrets: []corev1.LocalObjectReference{{Name: "app-db"}}}
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-db", Namespace: "default"}},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: envFrom}},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "default"},
+			Spec:       appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: pullOnly}},
+		},
+	)
+	ref := SecretRef{Namespace: "default", Name: "app-db"}
+
+	t.Run("should list each consuming workload once, skipping pull secrets", func(t *testing.T) {
+		workloads, err := FindConsumers(clientset, ref)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		expected := []WorkloadRef{{kind: "Deployment", name: "api"}}
+		if !reflect.DeepEqual(workloads, expected) {
+			t.Errorf("Expected %v, but got %v", expected, workloads)
+		}
+	})
+
+	t.Run("should stamp the pod template like kubectl rollout restart", func(t *testing.T) {
+		now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		if err := restartWorkload(clientset, "default", WorkloadRef{kind: "Deployment", name: "api"}, now); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		deployment, _ := clientset.AppsV1().Deployments("default").Get(context.TODO(), "api", metav1.GetOptions{})
+		if got := deployment.Spec.Template.Annotations[restartedAtAnnotation]; got != "2024-05-01T12:00:00Z" {
+			t.Errorf("Expected restartedAt '2024-05-01T12:00:00Z', but got '%s'", got)
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package kube
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Where the previous value of a rotated key is kept.
+const (
+	KeepPreviousKey        = "key"        // In the sibling key key + PreviousKeySuffix, the default.
+	KeepPreviousAnnotation = "annotation" // Base64-encoded, in PreviousValueAnnotationPrefix + key.
+	KeepPreviousNone       = "none"
+)
+
+// RotatedAtAnnotationPrefix, followed by a key, names the annotation holding when
+// the key was last rotated, in RFC 3339 format.
+const RotatedAtAnnotationPrefix = "rotated.kds.diskmanti.io/"
+
+// PreviousValueAnnotationPrefix, followed by a key, names the annotation holding
+// the base64-encoded value the key had before its last rotation. Annotations are
+// not secret: whatever shows or copies them must leave these out.
+const PreviousValueAnnotationPrefix = "previous.kds.diskmanti.io/"
+
+// PreviousKeySuffix is appended to a key to name the sibling key holding the value
+// it had before its last rotation.
+const PreviousKeySuffix = ".previous"
+
+// RotationOptions tells how to rotate a key. A zero Generator is inferred from the
+// current value by InferGenerator.
+type RotationOptions struct {
+	Generator    Generator
+	KeepPrevious string
+}
+
+// withoutRotationAnnotations returns a copy of annotations without those added by
+// rotations: the previous values, which are secret, and the times of the
+// rotations, which belong to the cluster rather than to a manifest.
+func withoutRotationAnnotations(annotations map[string]string) map[string]string {
+	var kept map[string]string
+	for key, value := range annotations {
+		if strings.HasPrefix(key, PreviousValueAnnotationPrefix) || strings.HasPrefix(key, RotatedAtAnnotationPrefix) {
+			continue
+		}
+		if kept == nil {
+			kept = make(map[string]string)
+		}
+		kept[key] = value
+	}
+	return kept
+}
+
+// ValidateKeepPrevious checks a place to keep previous values in.
+func ValidateKeepPrevious(keep string) error {
+	switch keep {
+	case KeepPreviousAnnotation, KeepPreviousKey, KeepPreviousNone:
+		return nil
+	}
+	return fmt.Errorf("invalid place '%s' to keep the previous value: must be key, annotation, or none", keep)
+}
+
+var (
+	uuidPattern       = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hexPattern        = regexp.MustCompile(`^[0-9a-f]+$`)
+	alnumPattern      = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+	passphrasePattern = regexp.MustCompile(`^[a-z]+(-[a-z]+)+$`)
+)
+
+// InferGenerator returns a generator of values shaped like value: the same kind of
+// keypair for PEM private keys, and a UUID, hex, passphrase, or alphanumeric value
+// of the same length otherwise, within the limits of the kind.
+func InferGenerator(value []byte) Generator {
+	s := strings.TrimSpace(string(value))
+	switch {
+	case uuidPattern.MatchString(s):
+		return Generator{Kind: GenerateUUID}
+	case strings.HasPrefix(s, "-----BEGIN "):
+		if block, _ := pem.Decode(value); block != nil {
+			if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+				switch k := key.(type) {
+				case ed25519.PrivateKey:
+					return Generator{Kind: GenerateEd25519}
+				case *rsa.PrivateKey:
+					return clampedGenerator(GenerateRSA, k.N.BitLen())
+				}
+			}
+			if k, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+				return clampedGenerator(GenerateRSA, k.N.BitLen())
+			}
+		}
+	case hexPattern.MatchString(s) && len(s) >= generatorLengths[GenerateHex][1]:
+		return clampedGenerator(GenerateHex, len(s))
+	case passphrasePattern.MatchString(s):
+		return clampedGenerator(GeneratePassphrase, strings.Count(s, "-")+1)
+	case alnumPattern.MatchString(s):
+		return clampedGenerator(GenerateAlphanumeric, len(s))
+	}
+	return Generator{Kind: GenerateAlphanumeric}
+}
+
+// clampedGenerator returns a generator of a kind with a length within its limits.
+func clampedGenerator(kind string, length int) Generator {
+	limits := generatorLengths[kind]
+	return Generator{Kind: kind, Length: min(max(length, limits[1]), limits[2])}
+}
+
+// PrepareRotation returns the update that sets a key of a secret to a generated
+// value, records when it was rotated, and keeps its previous value as configured.
+// For keypairs, the public key is rotated along with the private key.
+func PrepareRotation(clientset Client, ref SecretRef, key string, rotOpts RotationOptions, now time.Time) (Mutation, error) {
+	if err := ValidateKeepPrevious(rotOpts.KeepPrevious); err != nil {
+		return Mutation{}, err
+	}
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return Mutation{}, err
+	}
+	if isImmutable(secret) {
+		return Mutation{}, ErrImmutable
+	}
+	current, ok := secret.Data[key]
+	if !ok {
+		return Mutation{}, fmt.Errorf("secret '%s' has no key '%s': use one of %s", ref, key, strings.Join(SortedKeys(secret.Data), ", "))
+	}
+	g := rotOpts.Generator
+	if g.Kind == "" {
+		g = InferGenerator(current)
+	}
+	generated, err := g.Generate(key)
+	if err != nil {
+		return Mutation{}, err
+	}
+	rotated := secret.DeepCopy()
+	if rotated.Annotations == nil {
+		rotated.Annotations = make(map[string]string)
+	}
+	for _, k := range SortedKeys(generated) {
+		if errs := validation.IsQualifiedName(RotatedAtAnnotationPrefix + k); len(errs) > 0 {
+			return Mutation{}, fmt.Errorf("cannot record the rotation of key '%s' in an annotation: %s", k, strings.Join(errs, "; "))
+		}
+		previous, existed := secret.Data[k]
+		rotated.Data[k] = generated[k]
+		rotated.Annotations[RotatedAtAnnotationPrefix+k] = now.UTC().Format(time.RFC3339)
+		if !existed {
+			continue
+		}
+		switch rotOpts.KeepPrevious {
+		case KeepPreviousAnnotation:
+			rotated.Annotations[PreviousValueAnnotationPrefix+k] = base64.StdEncoding.EncodeToString(previous)
+		case KeepPreviousKey:
+			rotated.Data[k+PreviousKeySuffix] = previous
+		}
+	}
+	return Mutation{Before: secret, After: rotated}, nil
+}
@@ -0,0 +1,97 @@
+package kube
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestInferGenerator verifies that new values are shaped like the current ones.
+func TestInferGenerator(t *testing.T) {
+	keypair, err := Generator{Kind: GenerateEd25519}.Generate("key")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	tests := []struct {
+		name, value string
+		expected    Generator
+	}{
+		{"should keep UUIDs", "123e4567-e89b-12d3-a456-426614174000", Generator{Kind: GenerateUUID}},
+		{"should keep the length of hex values", "deadbeefcafe", Generator{Kind: GenerateHex, Length: 12}},
+		{"should keep the words of passphrases", "correct-horse-battery-staple", Generator{Kind: GeneratePassphrase, Length: 4}},
+		{"should keep the length of alphanumeric values", "Passw0rdPassw0rd", Generator{Kind: GenerateAlphanumeric, Length: 16}},
+		{"should raise short lengths to the minimum", "abc", Generator{Kind: GenerateAlphanumeric, Length: 8}},
+		{"should keep the kind of keypairs", string(keypair["key"]), Generator{Kind: GenerateEd25519}},
+		{"should fall back to alphanumeric values", "p@ss word!", Generator{Kind: GenerateAlphanumeric}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if g := InferGenerator([]byte(tt.value)); g != tt.expected {
+				t.Errorf("Expected %+v, but got %+v", tt.expected, g)
+			}
+		})
+	}
+}
+
+// TestPrepareRotation verifies rotating a key and keeping its previous value.
+func TestPrepareRotation(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	ref := SecretRef{Namespace: "default", Name: "db"}
+	newClientset := func() *fake.Clientset {
+		return fake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Data:       map[string][]byte{"password": []byte("0ldPassw0rd"), "user": []byte("admin")},
+		})
+	}
+	rotate := func(t *testing.T, keep string) *corev1.Secret {
+		t.Helper()
+		mu, err := PrepareRotation(newClientset(), ref, "password", RotationOptions{KeepPrevious: keep}, now)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		rotated := mu.After
+		if password := string(rotated.Data["password"]); password == "0ldPassw0rd" || len(password) != 11 {
+			t.Errorf("Expected a new 11-character password, but got %q", password)
+		}
+		if at := rotated.Annotations[RotatedAtAnnotationPrefix+"password"]; at != "2026-10-16T12:00:00Z" {
+			t.Errorf("Expected the rotation time to be recorded, but got %q", at)
+		}
+		return rotated
+	}
+
+	t.Run("should keep the previous value in an annotation", func(t *testing.T) {
+		rotated := rotate(t, KeepPreviousAnnotation)
+		expected := base64.StdEncoding.EncodeToString([]byte("0ldPassw0rd"))
+		if previous := rotated.Annotations[PreviousValueAnnotationPrefix+"password"]; previous != expected {
+			t.Errorf("Expected %q, but got %q", expected, previous)
+		}
+	})
+	t.Run("should keep the previous value in a sibling key", func(t *testing.T) {
+		rotated := rotate(t, KeepPreviousKey)
+		if previous := string(rotated.Data["password"+PreviousKeySuffix]); previous != "0ldPassw0rd" {
+			t.Errorf("Expected %q, but got %q", "0ldPassw0rd", previous)
+		}
+	})
+	t.Run("should keep nothing", func(t *testing.T) {
+		rotated := rotate(t, KeepPreviousNone)
+		if len(rotated.Data) != 2 || len(rotated.Annotations) != 1 {
+			t.Errorf("Expected only the key and the rotation time to change, but got %v and %v", rotated.Data, rotated.Annotations)
+		}
+	})
+	t.Run("should name the existing keys when the key is missing", func(t *testing.T) {
+		_, err := PrepareRotation(newClientset(), ref, "token", RotationOptions{KeepPrevious: KeepPreviousNone}, now)
+		if err == nil || !strings.Contains(err.Error(), "use one of password, user") {
+			t.Errorf("Expected a missing key error, but got %v", err)
+		}
+	})
+	t.Run("should reject an invalid place to keep the previous value", func(t *testing.T) {
+		if _, err := PrepareRotation(newClientset(), ref, "password", RotationOptions{KeepPrevious: "file"}, now); err == nil {
+			t.Error("Expected an error, but got nil")
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package kube
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// loadScanRules reads scan rules from a gitleaks-style TOML file. Each [[rules]]
+// entry needs an id and a regex or path; the path regex is matched against secret
+// keys, which become file names when a secret is mounted. The optional keywords,
+// secretGroup, entropy, and rule or global allowlists are honored, and a severity
+// may be set per rule (high by default).
+func loadScanRules(path string) ([]scanRule, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules: %w", err)
+	}
+	var doc map[string]any
+	if _, err := toml.Decode(string(content), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse rules '%s': %w", path, err)
+	}
+	global, err := parseAllowlists(doc, "allowlist", "allowlists")
+	if err != nil {
+		return nil, fmt.Errorf("invalid global allowlist: %w", err)
+	}
+	tables, ok := doc["rules"].([]map[string]any)
+	if !ok && doc["rules"] != nil {
+		return nil, fmt.Errorf("invalid rules in '%s': [[rules]] must be an array of tables", path)
+	}
+	rules := make([]scanRule, 0, len(tables))
+	for i, table := range tables {
+		rule, err := parseScanRule(table)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %d in '%s': %w", i+1, path, err)
+		}
+		rule.allow = append(rule.allow, global.allow...)
+		rule.stopwords = append(rule.stopwords, global.stopwords...)
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseScanRule converts a [[rules]] table into a scan rule.
+func parseScanRule(table map[string]any) (scanRule, error) {
+	var rule scanRule
+	var err error
+	var value, keys string
+	fields := []struct {
+		name   string
+		target *string
+	}{{"id", &rule.id}, {"description", &rule.description}, {"severity", &rule.severity}, {"regex", &value}, {"path", &keys}}
+	for _, field := range fields {
+		if *field.target, err = tomlString(table, field.name); err != nil {
+			return rule, err
+		}
+	}
+	if rule.id == "" {
+		return rule, errors.New("missing id")
+	}
+	if value == "" && keys == "" {
+		return rule, fmt.Errorf("rule '%s' needs a regex or a path", rule.id)
+	}
+	if rule.severity == "" {
+		rule.severity = SeverityHigh
+	}
+	if _, ok := severityRank[rule.severity]; !ok {
+		return rule, fmt.Errorf("rule '%s' has an invalid severity '%s': must be high, medium, or low", rule.id, rule.severity)
+	}
+	if rule.value, err = compileOptional(value); err != nil {
+		return rule, fmt.Errorf("rule '%s': %w", rule.id, err)
+	}
+	if rule.keys, err = compileOptional(keys); err != nil {
+		return rule, fmt.Errorf("rule '%s': %w", rule.id, err)
+	}
+	if rule.keywords, err = tomlStrings(table, "keywords"); err != nil {
+		return rule, err
+	}
+	for i := range rule.keywords {
+		rule.keywords[i] = strings.ToLower(rule.keywords[i])
+	}
+	if rule.secretGroup, rule.entropy, err = parseMatchOptions(table); err != nil {
+		return rule, fmt.Errorf("rule '%s': %w", rule.id, err)
+	}
+	allowlist, err := parseAllowlists(table, "allowlist", "allowlists")
+	if err != nil {
+		return rule, fmt.Errorf("rule '%s': %w", rule.id, err)
+	}
+	rule.allow, rule.stopwords = allowlist.allow, allowlist.stopwords
+	return rule, nil
+}
+
+// parseMatchOptions reads the secretGroup and entropy of a rule.
+func parseMatchOptions(table map[string]any) (group int, entropy float64, err error) {
+	if v, ok := table["secretGroup"]; ok {
+		n, isInt := v.(int64)
+		if !isInt || n < 0 {
+			return 0, 0, errors.New("secretGroup must be a non-negative integer")
+		}
+		group = int(n)
+	}
+	switch v := table["entropy"].(type) {
+	case nil:
+	case int64:
+		entropy = float64(v)
+	case float64:
+		entropy = v
+	default:
+		return 0, 0, errors.New("entropy must be a number")
+	}
+	return group, entropy, nil
+}
+
+// parseAllowlists merges the regexes and stopwords of an [allowlist] table and of
+// [[allowlists]] entries, the two spellings used by gitleaks.
+func parseAllowlists(table map[string]any, single, multiple string) (scanRule, error) {
+	var merged scanRule
+	var tables []map[string]any
+	if allowlist, ok := table[single].(map[string]any); ok {
+		tables = append(tables, allowlist)
+	}
+	if list, ok := table[multiple].([]map[string]any); ok {
+		tables = append(tables, list...)
+	}
+	for _, allowlist := range tables {
+		regexes, err := tomlStrings(allowlist, "regexes")
+		if err != nil {
+			return merged, err
+		}
+		for _, expr := range regexes {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return merged, fmt.Errorf("invalid allowlist regex: %w", err)
+			}
+			merged.allow = append(merged.allow, re)
+		}
+		stopwords, err := tomlStrings(allowlist, "stopwords")
+		if err != nil {
+			return merged, err
+		}
+		for _, word := range stopwords {
+			merged.stopwords = append(merged.stopwords, strings.ToLower(word))
+		}
+	}
+	return merged, nil
+}
+
+// compileOptional compiles a regular expression, or returns nil for an empty one.
+func compileOptional(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+// tomlString returns an optional string field of a TOML table.
+func tomlString(table map[string]any, key string) (string, error) {
+	switch v := table[key].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	}
+	return "", fmt.Errorf("'%s' must be a string", key)
+}
+
+// tomlStrings returns an optional array of strings of a TOML table.
+func tomlStrings(table map[string]any, key string) ([]string, error) {
+	values, ok := table[key].([]any)
+	if !ok {
+		if table[key] != nil {
+			return nil, fmt.Errorf("'%s' must be an array of strings", key)
+		}
+		return nil, nil
+	}
+	strs := make([]string, len(values))
+	for i, v := range values {
+		if strs[i], ok = v.(string); !ok {
+			return nil, fmt.Errorf("'%s' must be an array of strings", key)
+		}
+	}
+	return strs, nil
+}
+
+// mergeScanRules adds rules to the defaults, replacing default rules with the same id.
+func mergeScanRules(defaults, extra []scanRule) []scanRule {
+	replaced := make(map[string]bool)
+	for _, rule := range extra {
+		replaced[rule.id] = true
+	}
+	merged := make([]scanRule, 0, len(defaults)+len(extra))
+	for _, rule := range defaults {
+		if !replaced[rule.id] {
+			merged = append(merged, rule)
+		}
+	}
+	return append(merged, extra...)
+}
+
+// shannonEntropy returns the Shannon entropy of a string in bits per character.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
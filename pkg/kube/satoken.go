@@ -0,0 +1,104 @@
+package kube
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// serviceAccountSubjectPrefix starts the subject of every service account token.
+const serviceAccountSubjectPrefix = "system:serviceaccount:"
+
+// ServiceAccountToken holds the claims of a service account JWT.
+type ServiceAccountToken struct {
+	Issuer         string
+	Audience       []string
+	IssuedAt       time.Time // Zero for legacy tokens.
+	ExpiresAt      time.Time // Zero for tokens that never expire.
+	Namespace      string
+	ServiceAccount string
+	Pod            string // The pod the token is bound to, if any.
+	Secret         string // The secret the token is bound to, if any.
+	Node           string // The node the token is bound to, if any.
+	Legacy         bool   // True for tokens in the pre-TokenRequest format.
+}
+
+// jwtClaims is the payload of a service account JWT, in both the bound format of
+// the TokenRequest API and the legacy format of token secrets.
+type jwtClaims struct {
+	Issuer     string          `json:"iss"`
+	Subject    string          `json:"sub"`
+	Audience   json.RawMessage `json:"aud"`
+	IssuedAt   int64           `json:"iat"`
+	Expiry     int64           `json:"exp"`
+	Kubernetes *struct {
+		Namespace      string   `json:"namespace"`
+		ServiceAccount jwtClaim `json:"serviceaccount"`
+		Pod            jwtClaim `json:"pod"`
+		Secret         jwtClaim `json:"secret"`
+		Node           jwtClaim `json:"node"`
+	} `json:"kubernetes.io"`
+	LegacyNamespace      string `json:"kubernetes.io/serviceaccount/namespace"`
+	LegacySecret         string `json:"kubernetes.io/serviceaccount/secret.name"`
+	LegacyServiceAccount string `json:"kubernetes.io/serviceaccount/service-account.name"`
+}
+
+// jwtClaim references an object a bound token is tied to.
+type jwtClaim struct {
+	Name string `json:"name"`
+}
+
+// ParseServiceAccountToken decodes the claims of a service account JWT. The
+// signature is not verified: only the API server can do that.
+func ParseServiceAccountToken(token string) (*ServiceAccountToken, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, errors.New("not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT payload: %w", err)
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("invalid JWT payload: %w", err)
+	}
+	if !strings.HasPrefix(claims.Subject, serviceAccountSubjectPrefix) {
+		return nil, errors.New("not a service account token")
+	}
+	sa := &ServiceAccountToken{
+		Issuer:         claims.Issuer,
+		Audience:       parseAudience(claims.Audience),
+		Namespace:      claims.LegacyNamespace,
+		ServiceAccount: claims.LegacyServiceAccount,
+		Secret:         claims.LegacySecret,
+		Legacy:         claims.Kubernetes == nil,
+	}
+	if claims.IssuedAt > 0 {
+		sa.IssuedAt = time.Unix(claims.IssuedAt, 0)
+	}
+	if claims.Expiry > 0 {
+		sa.ExpiresAt = time.Unix(claims.Expiry, 0)
+	}
+	if k := claims.Kubernetes; k != nil {
+		sa.Namespace, sa.ServiceAccount = k.Namespace, k.ServiceAccount.Name
+		sa.Pod, sa.Secret, sa.Node = k.Pod.Name, k.Secret.Name, k.Node.Name
+	}
+	return sa, nil
+}
+
+// parseAudience reads the aud claim, which is either a string or a list of strings.
+func parseAudience(raw json.RawMessage) []string {
+	var audience []string
+	if err := json.Unmarshal(raw, &audience); err == nil {
+		return audience
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil && single != "" {
+		return []string{single}
+	}
+	return nil
+}
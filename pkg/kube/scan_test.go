@@ -0,0 +1,25 @@
+package kube
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestNewScannerWeakValues verifies loading additional weak values from a file.
+func TestNewScannerWeakValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weak.txt")
+	if err := os.WriteFile(path, []byte("# company defaults\nAcme2024!Winter\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewScanner(path, "", 0)
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if !s.weakValues["acme2024!winter"] || !s.weakValues["changeme"] {
+		t.Errorf("Expected custom and default weak values, but got %v", s.weakValues)
+	}
+	if s.weakValues["# company defaults"] {
+		t.Error("Expected comments to be ignored")
+	}
+}
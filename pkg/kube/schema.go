@@ -0,0 +1,159 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Value formats a key of a SecretSpec can require.
+const (
+	FormatJSON = "json"
+	FormatPEM  = "pem"
+)
+
+// SecretSpec describes the secrets a namespace must have, for 'kds validate'.
+type SecretSpec struct {
+	Secrets []SecretSchema `json:"secrets"`
+}
+
+// SecretSchema describes a secret of a SecretSpec.
+type SecretSchema struct {
+	Name string `json:"name"`
+	// Type is the type the secret must have, if set.
+	Type     corev1.SecretType `json:"type,omitempty"`
+	Optional bool              `json:"optional,omitempty"`
+	Keys     []KeySchema       `json:"keys,omitempty"`
+}
+
+// KeySchema describes a key of a SecretSchema and the constraints on its value.
+type KeySchema struct {
+	Name     string `json:"name"`
+	Optional bool   `json:"optional,omitempty"`
+	// Pattern is a regular expression the value must match.
+	Pattern   string `json:"pattern,omitempty"`
+	MinLength int    `json:"minLength,omitempty"`
+	// Format is json or pem.
+	Format string `json:"format,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// LoadSecretSpec reads and validates a spec file.
+func LoadSecretSpec(path string) (*SecretSpec, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // The path is chosen by the user.
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec: %w", err)
+	}
+	var spec SecretSpec
+	if err := yaml.UnmarshalStrict(content, &spec); err != nil {
+		return nil, fmt.Errorf("invalid spec %s: %w", path, err)
+	}
+	if err := spec.compile(); err != nil {
+		return nil, fmt.Errorf("invalid spec %s: %w", path, err)
+	}
+	return &spec, nil
+}
+
+// compile validates the spec and compiles its patterns.
+func (s *SecretSpec) compile() error {
+	for i := range s.Secrets {
+		secret := &s.Secrets[i]
+		if secret.Name == "" {
+			return errors.New("secret needs a name")
+		}
+		for j := range secret.Keys {
+			key := &secret.Keys[j]
+			if key.Name == "" {
+				return fmt.Errorf("key of secret '%s' needs a name", secret.Name)
+			}
+			switch key.Format {
+			case "", FormatJSON, FormatPEM:
+			default:
+				return fmt.Errorf("key '%s' of secret '%s' has invalid format '%s': use json or pem", key.Name, secret.Name, key.Format)
+			}
+			if key.Pattern == "" {
+				continue
+			}
+			pattern, err := regexp.Compile(key.Pattern)
+			if err != nil {
+				return fmt.Errorf("key '%s' of secret '%s' has invalid pattern: %w", key.Name, secret.Name, err)
+			}
+			key.pattern = pattern
+		}
+	}
+	return nil
+}
+
+// Validate checks the secrets of a namespace against the spec.
+func (s *SecretSpec) Validate(clientset Client, namespace string) ([]Assertion, error) {
+	var assertions []Assertion
+	for _, schema := range s.Secrets {
+		secret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), schema.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			if !schema.Optional {
+				assertions = append(assertions, Assertion{Secret: schema.Name, Reason: "secret missing"})
+			}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get secret '%s': %w", SecretRef{Namespace: namespace, Name: schema.Name}, err)
+		}
+		assertions = append(assertions, schema.validate(secret)...)
+	}
+	return assertions, nil
+}
+
+// validate checks a secret against its schema. Values are never quoted in the
+// reasons of failed assertions.
+func (schema SecretSchema) validate(secret *corev1.Secret) []Assertion {
+	assertion := Assertion{Secret: schema.Name, Passed: true}
+	if schema.Type != "" && secret.Type != schema.Type {
+		assertion = Assertion{Secret: schema.Name, Reason: fmt.Sprintf("type is %s, expected %s", secret.Type, schema.Type)}
+	}
+	assertions := []Assertion{assertion}
+	for _, key := range schema.Keys {
+		value, ok := secret.Data[key.Name]
+		if !ok {
+			if !key.Optional {
+				assertions = append(assertions, Assertion{Secret: schema.Name, Key: key.Name, Reason: "key missing"})
+			}
+			continue
+		}
+		reasons := key.check(value)
+		assertions = append(assertions, Assertion{Secret: schema.Name, Key: key.Name, Passed: len(reasons) == 0, Reason: strings.Join(reasons, "; ")})
+	}
+	return assertions
+}
+
+// check returns the constraints that a value breaks.
+func (key KeySchema) check(value []byte) []string {
+	var reasons []string
+	if len(value) < key.MinLength {
+		reasons = append(reasons, fmt.Sprintf("shorter than %d bytes", key.MinLength))
+	}
+	if key.pattern != nil && !key.pattern.Match(value) {
+		reasons = append(reasons, fmt.Sprintf("does not match %s", key.Pattern))
+	}
+	switch key.Format {
+	case FormatJSON:
+		if !json.Valid(value) {
+			reasons = append(reasons, "not valid JSON")
+		}
+	case FormatPEM:
+		if block, _ := pem.Decode(value); block == nil {
+			reasons = append(reasons, "not valid PEM")
+		}
+	}
+	return reasons
+}
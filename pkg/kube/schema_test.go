@@ -0,0 +1,79 @@
+package kube
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestSecretSpec verifies that secrets are validated against a spec file.
+func TestSecretSpec(t *testing.T) {
+	writeSpec := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "secrets.schema.yaml")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("should reject invalid specs", func(t *testing.T) {
+		for _, content := range []string{
+			"secrets:\n  - keys: [{name: a}]\n",
+			"secrets:\n  - name: db\n    keys: [{name: a, format: xml}]\n",
+			"secrets:\n  - name: db\n    keys: [{name: a, pattern: '('}]\n",
+			"secrets:\n  - name: db\n    unknown: true\n",
+		} {
+			if _, err := LoadSecretSpec(writeSpec(t, content)); err == nil {
+				t.Errorf("Expected an error for %q, but got none", content)
+			}
+		}
+	})
+
+	t.Run("should report the broken constraints", func(t *testing.T) {
+		spec, err := LoadSecretSpec(writeSpec(t, `secrets:
+  - name: db
+    keys:
+      - name: password
+        minLength: 16
+        pattern: ^[a-z]+$
+      - name: config
+        format: json
+      - name: replica
+        optional: true
+      - name: port
+  - name: app-tls
+    type: kubernetes.io/tls
+  - name: legacy
+    optional: true
+`))
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		clientset := fake.NewSimpleClientset(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"},
+				Data:       map[string][]byte{"password": []byte("Hunter2"), "config": []byte(`{"pool": 5}`)},
+			},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-tls", Namespace: "prod"}, Type: corev1.SecretTypeOpaque},
+		)
+		assertions, err := spec.Validate(clientset, "prod")
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		expected := []Assertion{
+			{Secret: "db", Passed: true},
+			{Secret: "db", Key: "password", Reason: "shorter than 16 bytes; does not match ^[a-z]+$"},
+			{Secret: "db", Key: "config", Passed: true},
+			{Secret: "db", Key: "port", Reason: "key missing"},
+			{Secret: "app-tls", Reason: "type is Opaque, expected kubernetes.io/tls"},
+		}
+		if !reflect.DeepEqual(assertions, expected) {
+			t.Errorf("Expected %v, but got %v", expected, assertions)
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package kube
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/zalando/go-keyring"
+)
+
+// sealKeySize is the size of the AES-256 keys that encrypt the files kds keeps on
+// the local disk.
+const sealKeySize = 32
+
+// sealKeyService is the service of the keys of kds in the OS keychain.
+const sealKeyService = "kds"
+
+// loadSealKey returns the key named after path in the OS keychain (the macOS
+// Keychain, the Secret Service on Linux, or the Windows Credential Manager),
+// generating it on first use. A key file at path is moved into the keychain.
+// Without a keychain, e.g. on a headless server, the key is kept in the file at
+// path instead, readable by the current user only. Any other failure of the
+// keychain, e.g. a locked one, is returned: the key may still be in there, and a
+// key file would seal the cache, the trash and the history with another key.
+func loadSealKey(path string) ([]byte, error) {
+	account := filepath.Clean(path)
+	encoded, err := keyring.Get(sealKeyService, account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		encoded, err = storeSealKey(account)
+	}
+	if noKeychain(err) {
+		slog.Debug("no keychain, keeping the key in a file", "path", path, "error", err)
+		return loadSealKeyFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s from the keychain: %w", account, err)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key %s in the keychain: %w", account, err)
+	}
+	return checkSealKey(account, key)
+}
+
+// noKeychain reports whether err means that there is no keychain at all: the
+// platform is not supported, or there is no session bus or no Secret Service to
+// talk to on Linux.
+func noKeychain(err error) bool {
+	if errors.Is(err, keyring.ErrUnsupportedPlatform) {
+		return true
+	}
+	var dbusErr dbus.Error
+	if errors.As(err, &dbusErr) {
+		return dbusErr.Name == "org.freedesktop.DBus.Error.ServiceUnknown"
+	}
+	var execErr *exec.Error
+	var exitErr *exec.ExitError
+	var netErr *net.OpError
+	return errors.As(err, &execErr) || errors.As(err, &exitErr) || errors.As(err, &netErr) ||
+		err != nil && strings.HasPrefix(err.Error(), "dbus: couldn't determine address of session bus")
+}
+
+// storeSealKey stores the key file at path in the keychain, or a new key if there
+// is none, and removes the file. It returns the key the keychain holds then, in
+// case another kds stored one in the meantime.
+func storeSealKey(path string) (string, error) {
+	key, err := os.ReadFile(path) //nolint:gosec // The path is chosen by the user.
+	existing := err == nil
+	if errors.Is(err, fs.ErrNotExist) {
+		key = make([]byte, sealKeySize)
+		_, err = rand.Read(key)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := keyring.Set(sealKeyService, path, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return "", err
+	}
+	if existing {
+		if err := os.Remove(path); err != nil {
+			return "", fmt.Errorf("failed to remove key %s, moved to the keychain: %w", path, err)
+		}
+	}
+	return keyring.Get(sealKeyService, path)
+}
+
+// loadSealKeyFile reads the key in the file at path, generating it on first use.
+func loadSealKeyFile(path string) ([]byte, error) {
+	key, err := os.ReadFile(path) //nolint:gosec // The path is chosen by the user.
+	if errors.Is(err, fs.ErrNotExist) {
+		key, err = newSealKey(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return checkSealKey(path, key)
+}
+
+// checkSealKey rejects keys of the wrong size.
+func checkSealKey(name string, key []byte) ([]byte, error) {
+	if len(key) != sealKeySize {
+		return nil, fmt.Errorf("invalid key %s: expected %d bytes, but got %d", name, sealKeySize, len(key))
+	}
+	return key, nil
+}
+
+// newSealKey generates a random key and writes it to path, readable by the
+// current user only. If another kds created the key in the meantime, that key is
+// read instead of being overwritten, so that neither loses what it encrypted.
+func newSealKey(path string) ([]byte, error) {
+	key := make([]byte, sealKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) //nolint:gosec // The path is chosen by the user.
+	if errors.Is(err, fs.ErrExist) {
+		return os.ReadFile(path) //nolint:gosec // The path is chosen by the user.
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, err = file.Write(key)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return key, err
+}
+
+// seal encrypts plaintext with AES-GCM, prefixing it with a random nonce.
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// unseal decrypts what seal encrypted with the same key.
+func unseal(key, content []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) < gcm.NonceSize() {
+		return nil, errors.New("file is truncated")
+	}
+	return gcm.Open(nil, content[:gcm.NonceSize()], content[gcm.NonceSize():], nil)
+}
+
+// newGCM returns the AES-GCM cipher of a key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// hashedPath returns the file of dir named after the hash of names, such as a
+// context and a namespace. Names are hashed, since context names may contain
+// characters that file names cannot.
+func hashedPath(dir, suffix string, names ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(names, "\x00")))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16])+suffix)
+}
+
+// writeFileAtomic writes content to path through a temporary file of dir, so that a
+// concurrent kds never reads half a file.
+func writeFileAtomic(dir, path string, content []byte) error {
+	file, err := os.CreateTemp(dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		return errors.Join(err, os.Remove(file.Name()))
+	}
+	return nil
+}
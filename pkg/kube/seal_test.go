@@ -0,0 +1,87 @@
+package kube
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+// TestMain keeps the keys of the tests in memory rather than in the keychain of
+// the machine.
+func TestMain(m *testing.M) {
+	keyring.MockInit()
+	os.Exit(m.Run())
+}
+
+// TestLoadSealKey verifies that keys are kept in the keychain, that key files are
+// moved into it, and that a key file is used without a keychain.
+func TestLoadSealKey(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("should keep a new key in the keychain", func(t *testing.T) {
+		path := filepath.Join(dir, "new.key")
+		key, err := loadSealKey(path)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected no key file, but got: %v", err)
+		}
+		again, err := loadSealKey(path)
+		if err != nil || !bytes.Equal(again, key) {
+			t.Errorf("Expected the same key from the keychain, but got %v", err)
+		}
+	})
+	t.Run("should move a key file into the keychain", func(t *testing.T) {
+		path := filepath.Join(dir, "old.key")
+		existing := bytes.Repeat([]byte{7}, sealKeySize)
+		if err := os.WriteFile(path, existing, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		key, err := loadSealKey(path)
+		if err != nil || !bytes.Equal(key, existing) {
+			t.Fatalf("Expected the key of the file, but got %v", err)
+		}
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected the key file to be removed, but got: %v", err)
+		}
+	})
+	t.Run("should keep the key in a file without a keychain", func(t *testing.T) {
+		keyring.MockInitWithError(keyring.ErrUnsupportedPlatform)
+		defer keyring.MockInit()
+		path := filepath.Join(dir, "fallback.key")
+		key, err := loadSealKey(path)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if content, err := os.ReadFile(path); err != nil || !bytes.Equal(content, key) {
+			t.Errorf("Expected the key to be written to %s, but got %v", path, err)
+		}
+	})
+	t.Run("should fail without creating a key file when the keychain fails", func(t *testing.T) {
+		keyring.MockInitWithError(errors.New("keychain locked"))
+		defer keyring.MockInit()
+		path := filepath.Join(dir, "locked.key")
+		if _, err := loadSealKey(path); err == nil {
+			t.Fatal("Expected an error, but got none")
+		}
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected no key file, but got: %v", err)
+		}
+	})
+	t.Run("should keep a key file created in the meantime", func(t *testing.T) {
+		path := filepath.Join(dir, "fallback.key")
+		existing, _ := os.ReadFile(path)
+		key, err := newSealKey(path)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if current, _ := os.ReadFile(path); !bytes.Equal(key, existing) || !bytes.Equal(current, existing) {
+			t.Error("Expected the existing key to be kept and used")
+		}
+	})
+}
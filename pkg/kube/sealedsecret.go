@@ -0,0 +1,163 @@
+package kube
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Where the Sealed Secrets controller is installed by default.
+const (
+	DefaultSealedSecretsNamespace  = "kube-system"
+	DefaultSealedSecretsController = "sealed-secrets-controller"
+)
+
+// Scopes of a SealedSecret: where the controller agrees to unseal it.
+const (
+	SealStrict        = "strict"         // Only under the same name and namespace.
+	SealNamespaceWide = "namespace-wide" // Under any name in the same namespace.
+	SealClusterWide   = "cluster-wide"   // Anywhere.
+)
+
+// sealScopeAnnotations are the annotations telling the controller the scope of a
+// SealedSecret other than strict.
+var sealScopeAnnotations = map[string]string{
+	SealNamespaceWide: "sealedsecrets.bitnami.com/namespace-wide",
+	SealClusterWide:   "sealedsecrets.bitnami.com/cluster-wide",
+}
+
+// ValidateSealScope checks the scope of a SealedSecret.
+func ValidateSealScope(scope string) error {
+	switch scope {
+	case SealStrict, SealNamespaceWide, SealClusterWide:
+		return nil
+	}
+	return fmt.Errorf("invalid scope '%s': must be strict, namespace-wide, or cluster-wide", scope)
+}
+
+// FetchSealingKey fetches the public certificate of a Sealed Secrets controller
+// through the API server proxy, as kubeseal does, and returns its key.
+func FetchSealingKey(clientset Client, namespace, controller string) (*rsa.PublicKey, error) {
+	content, err := clientset.CoreV1().Services(namespace).ProxyGet("http", controller, "", "/v1/cert.pem", nil).DoRaw(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the certificate of controller '%s/%s': %w", namespace, controller, err)
+	}
+	return ParseSealingKey(content)
+}
+
+// ParseSealingKey returns the public key of a PEM-encoded Sealed Secrets
+// certificate.
+func ParseSealingKey(content []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("invalid sealing certificate: no PEM-encoded certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sealing certificate: %w", err)
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("invalid sealing certificate: the key is not an RSA key")
+	}
+	return key, nil
+}
+
+// sealedSecret holds the fields of a SealedSecret manifest.
+type sealedSecret struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   metav1.ObjectMeta `json:"metadata"`
+	Spec       struct {
+		EncryptedData map[string]string `json:"encryptedData"`
+		Template      struct {
+			Metadata  metav1.ObjectMeta `json:"metadata"`
+			Type      corev1.SecretType `json:"type,omitempty"`
+			Immutable *bool             `json:"immutable,omitempty"`
+		} `json:"template"`
+	} `json:"spec"`
+}
+
+// SealSecret encrypts the values of a secret with the public key of a Sealed
+// Secrets controller and returns the SealedSecret manifest, as kubeseal does. Only
+// the controller can decrypt it, and only within its scope. Annotations are left
+// in the clear, so those of rotations, which may hold previous values, are left
+// out.
+func SealSecret(secret *corev1.Secret, key *rsa.PublicKey, scope string) ([]byte, error) {
+	if err := ValidateSealScope(scope); err != nil {
+		return nil, err
+	}
+	clone := cloneSecret(secret, secret.Namespace)
+	annotations := withoutRotationAnnotations(clone.Annotations)
+	if annotation, found := sealScopeAnnotations[scope]; found {
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[annotation] = "true"
+	}
+	sealed := sealedSecret{APIVersion: "bitnami.com/v1alpha1", Kind: "SealedSecret"}
+	sealed.Metadata = metav1.ObjectMeta{Name: clone.Name, Namespace: clone.Namespace, Annotations: annotations}
+	sealed.Spec.Template.Metadata = metav1.ObjectMeta{Name: clone.Name, Namespace: clone.Namespace, Labels: clone.Labels, Annotations: annotations}
+	sealed.Spec.Template.Type, sealed.Spec.Template.Immutable = clone.Type, clone.Immutable
+	sealed.Spec.EncryptedData = make(map[string]string, len(clone.Data))
+	label := sealLabel(secret, scope)
+	for k, value := range clone.Data {
+		ciphertext, err := hybridEncrypt(key, value, label)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seal key '%s': %w", k, err)
+		}
+		sealed.Spec.EncryptedData[k] = base64.StdEncoding.EncodeToString(ciphertext)
+	}
+	return yaml.Marshal(sealed)
+}
+
+// sealLabel returns the label that binds the values of a SealedSecret to its
+// scope: the controller refuses to decrypt them under another label.
+func sealLabel(secret *corev1.Secret, scope string) []byte {
+	switch scope {
+	case SealClusterWide:
+		return nil
+	case SealNamespaceWide:
+		return []byte(secret.Namespace)
+	}
+	return []byte(secret.Namespace + "/" + secret.Name)
+}
+
+// hybridEncrypt encrypts a value the way the Sealed Secrets controller expects: a
+// random AES-256-GCM session key, encrypted with RSA-OAEP (SHA-256) and the label,
+// followed by the value encrypted with it. The session key is never reused, so
+// the nonce is zero. The RSA ciphertext is prefixed with its length.
+func hybridEncrypt(key *rsa.PublicKey, plaintext, label []byte) ([]byte, error) {
+	sessionKey := make([]byte, 32)
+	if _, err := rand.Read(sessionKey); err != nil {
+		return nil, err
+	}
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key, sessionKey, label)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	out := binary.BigEndian.AppendUint16(nil, uint16(len(encryptedKey))) //nolint:gosec // RSA ciphertexts are at most a few kilobytes.
+	out = append(out, encryptedKey...)
+	return gcm.Seal(out, make([]byte, gcm.NonceSize()), plaintext, nil), nil
+}
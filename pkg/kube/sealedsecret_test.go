@@ -0,0 +1,133 @@
+package kube
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	restclient "k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+)
+
+// certResponse serves a certificate through the fake API server proxy.
+type certResponse []byte
+
+func (r certResponse) DoRaw(context.Context) ([]byte, error)         { return r, nil }
+func (r certResponse) Stream(context.Context) (io.ReadCloser, error) { return nil, nil }
+
+// TestSealSecret verifies that the values sealed with the certificate of the
+// controller can be decrypted with its key, under the label of their scope only.
+func TestSealSecret(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "sealed-secret"}, NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependProxyReactor("services", func(action k8stesting.Action) (bool, restclient.ResponseWrapper, error) {
+		proxy := action.(k8stesting.ProxyGetAction)
+		if proxy.GetName() != DefaultSealedSecretsController || proxy.GetPath() != "/v1/cert.pem" {
+			t.Errorf("Expected the certificate of the controller to be fetched, but got %s %s", proxy.GetName(), proxy.GetPath())
+		}
+		return true, certResponse(certPEM), nil
+	})
+	public, err := FetchSealingKey(clientset, DefaultSealedSecretsNamespace, DefaultSealedSecretsController)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "db", Namespace: "prod", Labels: map[string]string{"app": "db"},
+			Annotations: map[string]string{
+				"team": "payments",
+				PreviousValueAnnotationPrefix + "password": base64.StdEncoding.EncodeToString([]byte("0ld-s3cr3t")),
+				RotatedAtAnnotationPrefix + "password":     "2026-01-01T00:00:00Z",
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{"password": []byte("s3cr3t")},
+	}
+	unseal := func(t *testing.T, value string, label string) (string, error) {
+		t.Helper()
+		ciphertext, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			t.Fatalf("Expected base64, but got: %v", err)
+		}
+		n := binary.BigEndian.Uint16(ciphertext)
+		sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, ciphertext[2:2+n], []byte(label))
+		if err != nil {
+			return "", err
+		}
+		block, _ := aes.NewCipher(sessionKey)
+		gcm, _ := cipher.NewGCM(block)
+		plaintext, err := gcm.Open(nil, make([]byte, gcm.NonceSize()), ciphertext[2+n:], nil)
+		return string(plaintext), err
+	}
+	seal := func(t *testing.T, scope string) sealedSecret {
+		t.Helper()
+		manifest, err := SealSecret(secret, public, scope)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		var sealed sealedSecret
+		if err := yaml.Unmarshal(manifest, &sealed); err != nil {
+			t.Fatalf("Expected a valid manifest, but got: %v", err)
+		}
+		if strings.Contains(string(manifest), "s3cr3t") || strings.Contains(string(manifest), base64.StdEncoding.EncodeToString([]byte("0ld-s3cr3t"))) {
+			t.Errorf("Expected the value to be encrypted, but got:\n%s", manifest)
+		}
+		return sealed
+	}
+
+	t.Run("should seal values for the name and namespace of the secret", func(t *testing.T) {
+		sealed := seal(t, SealStrict)
+		if sealed.Kind != "SealedSecret" || sealed.Spec.Template.Metadata.Labels["app"] != "db" || sealed.Spec.Template.Type != corev1.SecretTypeOpaque {
+			t.Errorf("Expected a SealedSecret templating the secret, but got %+v", sealed)
+		}
+		if annotations := sealed.Spec.Template.Metadata.Annotations; len(annotations) != 1 || annotations["team"] != "payments" {
+			t.Errorf("Expected only the team annotation, without those of rotations, but got %v", annotations)
+		}
+		if value, err := unseal(t, sealed.Spec.EncryptedData["password"], "prod/db"); err != nil || value != "s3cr3t" {
+			t.Errorf("Expected s3cr3t, but got %q (error: %v)", value, err)
+		}
+		if _, err := unseal(t, sealed.Spec.EncryptedData["password"], "prod/other"); err == nil {
+			t.Error("Expected the value not to be decrypted under another name")
+		}
+	})
+	t.Run("should seal values for the whole cluster", func(t *testing.T) {
+		sealed := seal(t, SealClusterWide)
+		if sealed.Metadata.Annotations["sealedsecrets.bitnami.com/cluster-wide"] != "true" {
+			t.Errorf("Expected the cluster-wide annotation, but got %v", sealed.Metadata.Annotations)
+		}
+		if value, err := unseal(t, sealed.Spec.EncryptedData["password"], ""); err != nil || value != "s3cr3t" {
+			t.Errorf("Expected s3cr3t, but got %q (error: %v)", value, err)
+		}
+	})
+	t.Run("should reject an unknown scope", func(t *testing.T) {
+		if _, err := SealSecret(secret, public, "global"); err == nil {
+			t.Error("Expected an error for an unknown scope")
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package kube
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+)
+
+// DefaultSearchLimit is how many searches are remembered per namespace by default.
+const DefaultSearchLimit = 50
+
+// searchHistorySuffix ends the name of every file of the search history.
+const searchHistorySuffix = ".searches.enc"
+
+// SearchHistory remembers the searches typed in the TUI, per namespace of a
+// kubeconfig context, encrypted on the local disk, since they name secrets.
+type SearchHistory struct {
+	dir     string
+	context string
+	key     []byte
+	limit   int
+}
+
+// OpenSearchHistory opens the searches of a context in dir, creating it if
+// needed, remembering the last limit searches of each namespace. Its files are
+// encrypted with the key of keyPath, as loadSealKey keeps it.
+func OpenSearchHistory(dir, keyPath, context string, limit int) (*SearchHistory, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create search history directory: %w", err)
+	}
+	key, err := loadSealKey(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search history key: %w", err)
+	}
+	return &SearchHistory{dir: dir, context: context, key: key, limit: limit}, nil
+}
+
+// Load returns the searches of a namespace, or of every namespace if it is empty,
+// oldest first.
+func (h *SearchHistory) Load(namespace string) ([]string, error) {
+	content, err := os.ReadFile(h.path(namespace))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search history: %w", err)
+	}
+	plaintext, err := unseal(h.key, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt search history: %w", err)
+	}
+	var searches []string
+	if err := json.Unmarshal(plaintext, &searches); err != nil {
+		return nil, fmt.Errorf("failed to parse search history: %w", err)
+	}
+	return searches, nil
+}
+
+// Add remembers a search of a namespace as the newest one, moving it there if it
+// was already remembered, and returns the searches of the namespace.
+func (h *SearchHistory) Add(namespace, search string) ([]string, error) {
+	searches, err := h.Load(namespace)
+	if err != nil {
+		return nil, err
+	}
+	searches = AddSearch(searches, search, h.limit)
+	plaintext, err := json.Marshal(searches)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize search history: %w", err)
+	}
+	sealed, err := seal(h.key, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(h.dir, h.path(namespace), sealed); err != nil {
+		return nil, fmt.Errorf("failed to write search history: %w", err)
+	}
+	return searches, nil
+}
+
+// AddSearch appends a search to searches, oldest first, removing its previous
+// occurrence and the oldest searches beyond limit, if it is positive.
+func AddSearch(searches []string, search string, limit int) []string {
+	searches = slices.DeleteFunc(slices.Clone(searches), func(s string) bool { return s == search })
+	searches = append(searches, search)
+	if limit > 0 && len(searches) > limit {
+		searches = searches[len(searches)-limit:]
+	}
+	return searches
+}
+
+// path returns the file of the searches of a namespace.
+func (h *SearchHistory) path(namespace string) string {
+	return hashedPath(h.dir, searchHistorySuffix, h.context, namespace)
+}
@@ -0,0 +1,64 @@
+package kube
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSearchHistory verifies that searches are remembered encrypted, per context
+// and namespace, newest last and without duplicates.
+func TestSearchHistory(t *testing.T) {
+	dir := t.TempDir()
+	history, err := OpenSearchHistory(filepath.Join(dir, "searches"), filepath.Join(dir, "cache.key"), "prod", 3)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for _, search := range []string{"db", "tls", "api", "db", "redis"} {
+		if _, err := history.Add("default", search); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+
+	t.Run("should keep the newest searches once each", func(t *testing.T) {
+		searches, err := history.Load("default")
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if got := strings.Join(searches, ","); got != "api,db,redis" {
+			t.Errorf("Expected api,db,redis, but got %s", got)
+		}
+	})
+	t.Run("should encrypt the searches", func(t *testing.T) {
+		content, err := os.ReadFile(history.path("default"))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if bytes.Contains(content, []byte("redis")) {
+			t.Error("Expected the searches to be encrypted")
+		}
+	})
+	t.Run("should keep contexts and namespaces apart", func(t *testing.T) {
+		other, err := OpenSearchHistory(filepath.Join(dir, "searches"), filepath.Join(dir, "cache.key"), "staging", 3)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		for _, searches := range [][]string{mustLoadSearches(t, other, "default"), mustLoadSearches(t, history, "kube-system")} {
+			if len(searches) != 0 {
+				t.Errorf("Expected no searches, but got %v", searches)
+			}
+		}
+	})
+}
+
+// mustLoadSearches loads the searches of a namespace, failing the test on error.
+func mustLoadSearches(t *testing.T, history *SearchHistory, namespace string) []string {
+	t.Helper()
+	searches, err := history.Load(namespace)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	return searches
+}
@@ -0,0 +1,262 @@
+// Package kube reads, decodes, and changes Kubernetes secrets. It holds the logic
+// behind every kds command, without any terminal UI, so other tools can reuse it.
+package kube
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	appsv1client "k8s.io/client-go/kubernetes/typed/apps/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	networkingv1client "k8s.io/client-go/kubernetes/typed/networking/v1"
+)
+
+// Client defines the interface for the Kubernetes client.
+// This allows us to use a real clientset in production and a fake clientset
+// during testing, which is a crucial practice for writing testable code.
+// It only includes the methods our application actually needs from the client.
+type Client interface {
+	CoreV1() corev1client.CoreV1Interface
+	AppsV1() appsv1client.AppsV1Interface
+	NetworkingV1() networkingv1client.NetworkingV1Interface
+}
+
+// --- SECRETS ---
+
+// Item represents a single Kubernetes secret in our list.
+// It satisfies the `bubbles/list.Item` interface, making it usable in the list component.
+type Item struct {
+	Name       string
+	Namespace  string
+	SecretType corev1.SecretType
+	Created    time.Time
+	Immutable  bool
+	Size       int
+	Keys       int
+	Labels     map[string]string
+	// ResourceVersion is the version of the secret listed, to tell whether a
+	// secret seen later is the same.
+	ResourceVersion string
+	// CrossNamespace is set when the item is listed among the secrets of every
+	// namespace, so that its namespace is worth showing.
+	CrossNamespace bool
+}
+
+// Title returns the primary text to display in the list, marking immutable secrets.
+func (i Item) Title() string {
+	if i.Immutable {
+		return i.Name + immutableMarker
+	}
+	return i.Name
+}
+
+// Description returns the secondary text to display in the list: the number of
+// keys, the age, and the size of the secret, after its namespace in a list that
+// spans every namespace.
+func (i Item) Description() string {
+	var parts []string
+	if i.CrossNamespace {
+		parts = append(parts, "Namespace: "+i.Namespace)
+	}
+	parts = append(parts, fmt.Sprintf("%d key(s)", i.Keys))
+	if !i.Created.IsZero() {
+		parts = append(parts, duration.HumanDuration(time.Since(i.Created))+" old")
+	}
+	parts = append(parts, FormatSize(i.Size))
+	description := strings.Join(parts, " • ")
+	if warning := SizeWarning(i.Size); warning != "" {
+		description += " " + warning
+	}
+	return description
+}
+
+// FilterValue is the string that the list's fuzzy-finder will use for matching.
+func (i Item) FilterValue() string { return i.Name }
+
+// Ref returns the namespace/name reference of the secret behind this item.
+func (i Item) Ref() SecretRef { return SecretRef{Namespace: i.Namespace, Name: i.Name} }
+
+// ItemSource is a slice of items that satisfies the `fuzzy.Source` interface,
+// allowing our fuzzy-finder library to search through it.
+type ItemSource []Item
+
+// String returns the string representation of an element at a given index for the fuzzy finder.
+func (s ItemSource) String(i int) string { return s[i].Name }
+
+// Len returns the number of items in the source for the fuzzy finder.
+func (s ItemSource) Len() int { return len(s) }
+
+// ListItems fetches all secrets in a namespace and converts them into list items.
+func ListItems(clientset Client, namespace string) (ItemSource, error) {
+	secrets, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	items := make(ItemSource, len(secrets.Items))
+	for i := range secrets.Items {
+		items[i] = NewItem(&secrets.Items[i])
+	}
+	return items, nil
+}
+
+// NewItem converts a secret into a list item.
+func NewItem(secret *corev1.Secret) Item {
+	return Item{
+		Name:            secret.Name,
+		Namespace:       secret.Namespace,
+		SecretType:      secret.Type,
+		Created:         secret.CreationTimestamp.Time,
+		Immutable:       isImmutable(secret),
+		Size:            SecretSize(secret),
+		Keys:            len(secret.Data),
+		Labels:          secret.Labels,
+		ResourceVersion: secret.ResourceVersion,
+	}
+}
+
+// listWorkers bounds the namespaces whose secrets are listed at once.
+const listWorkers = 8
+
+// NamespaceItems is the outcome of listing the secrets of a namespace, or of
+// every namespace at once when Namespace is empty.
+type NamespaceItems struct {
+	Namespace string
+	Items     ItemSource
+	Err       error
+}
+
+// StreamAllItems lists the secrets of every namespace of the cluster of
+// clientset, sending them to the returned channel as they arrive, which is closed
+// once every namespace is listed. Secrets are listed cluster-wide when the
+// credentials allow it, in a single result. Otherwise, which is common under
+// strict RBAC, each namespace is listed on its own by a bounded pool of workers,
+// so that the slowest namespaces do not hold back the others. Results of
+// namespaces where listing secrets is forbidden carry a Forbidden error. The
+// second value is the number of results to expect.
+func StreamAllItems(clientset Client) (<-chan NamespaceItems, int, error) {
+	items, err := ListItems(clientset, metav1.NamespaceAll)
+	if !k8serrors.IsForbidden(err) {
+		results := make(chan NamespaceItems, 1)
+		results <- NamespaceItems{Items: markCrossNamespace(items), Err: err}
+		close(results)
+		return results, 1, nil
+	}
+	namespaces, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list namespaces after listing secrets cluster-wide was forbidden: %w", err)
+	}
+	// The channel holds every result, so that workers never wait for a reader
+	// that stopped reading.
+	results := make(chan NamespaceItems, len(namespaces.Items))
+	queue := make(chan string, len(namespaces.Items))
+	for _, ns := range namespaces.Items {
+		queue <- ns.Name
+	}
+	close(queue)
+	var wg sync.WaitGroup
+	for range min(listWorkers, len(namespaces.Items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for namespace := range queue {
+				items, err := ListItems(clientset, namespace)
+				results <- NamespaceItems{Namespace: namespace, Items: markCrossNamespace(items), Err: err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results, len(namespaces.Items), nil
+}
+
+// ListAllItems fetches the secrets of every namespace, as StreamAllItems lists
+// them, sorted by namespace. The namespaces where listing secrets is forbidden
+// are skipped and returned, so the accessible secrets are still shown.
+func ListAllItems(clientset Client) (items ItemSource, skipped []string, err error) {
+	results, total, err := StreamAllItems(clientset)
+	if err != nil {
+		return nil, nil, err
+	}
+	var collector ItemCollector
+	for result := range results {
+		_ = collector.Add(result) // Result returns the first error.
+	}
+	return collector.Result(total)
+}
+
+// ItemCollector gathers the results of StreamAllItems into the secrets of every
+// namespace.
+type ItemCollector struct {
+	items   ItemSource
+	skipped []string
+	err     error
+}
+
+// Add gathers a result. It returns the error of the result, unless listing
+// secrets was forbidden in its namespace, which is then skipped.
+func (c *ItemCollector) Add(result NamespaceItems) error {
+	var err error
+	switch {
+	case result.Err == nil:
+		c.items = append(c.items, result.Items...)
+	case result.Namespace == "":
+		err = result.Err
+	case k8serrors.IsForbidden(result.Err):
+		c.skipped = append(c.skipped, result.Namespace)
+	default:
+		err = fmt.Errorf("failed to list secrets in namespace '%s': %w", result.Namespace, result.Err)
+	}
+	c.err = cmp.Or(c.err, err)
+	return err
+}
+
+// Items returns the secrets gathered so far, sorted by namespace, then by name.
+func (c *ItemCollector) Items() ItemSource {
+	_ = SortItemsBy(c.items, "namespace") // A valid key.
+	return slices.Clone(c.items)
+}
+
+// Result returns the secrets and the skipped namespaces once the total number of
+// results announced by StreamAllItems is gathered. It fails if a result did, or
+// if listing secrets was forbidden in every namespace.
+func (c *ItemCollector) Result(total int) (ItemSource, []string, error) {
+	if c.err != nil {
+		return nil, nil, c.err
+	}
+	slices.Sort(c.skipped)
+	if len(c.skipped) == total {
+		return nil, c.skipped, errors.New("listing secrets is forbidden in every namespace")
+	}
+	return c.Items(), c.skipped, nil
+}
+
+// markCrossNamespace marks items listed across namespaces.
+func markCrossNamespace(items ItemSource) ItemSource {
+	for i := range items {
+		items[i].CrossNamespace = true
+	}
+	return items
+}
+
+// DecodeValue decodes a single secret value. Values that are not valid base64 are
+// returned unchanged, with ok set to false so callers can flag them as raw.
+func DecodeValue(value []byte) (decoded string, ok bool) {
+	decodedValue, err := base64.StdEncoding.DecodeString(string(value))
+	if err != nil {
+		return string(value), false
+	}
+	return string(decodedValue), true
+}
@@ -0,0 +1,141 @@
+package kube
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestListAllItems verifies that secrets are listed per namespace when listing
+// them cluster-wide is forbidden, skipping the namespaces without access.
+func TestListAllItems(t *testing.T) {
+	newClientset := func(forbidden ...string) *fake.Clientset {
+		clientset := fake.NewSimpleClientset(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "dev"}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"}},
+		)
+		clientset.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			for _, ns := range forbidden {
+				if action.GetNamespace() == ns {
+					return true, nil, k8serrors.NewForbidden(corev1.Resource("secrets"), "", nil)
+				}
+			}
+			return false, nil, nil
+		})
+		return clientset
+	}
+
+	t.Run("should list cluster-wide when allowed", func(t *testing.T) {
+		items, skipped, err := ListAllItems(newClientset())
+		if err != nil || len(items) != 2 || len(skipped) != 0 {
+			t.Errorf("Expected both secrets and no skipped namespace, but got %v, %v, %v", items, skipped, err)
+		}
+	})
+
+	t.Run("should skip the forbidden namespaces", func(t *testing.T) {
+		items, skipped, err := ListAllItems(newClientset(metav1.NamespaceAll, "prod"))
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if len(items) != 1 || items[0].Name != "api" {
+			t.Errorf("Expected only dev/api, but got %v", items)
+		}
+		if len(skipped) != 1 || skipped[0] != "prod" {
+			t.Errorf("Expected prod to be skipped, but got %v", skipped)
+		}
+	})
+
+	t.Run("should fail when every namespace is forbidden", func(t *testing.T) {
+		if _, _, err := ListAllItems(newClientset(metav1.NamespaceAll, "dev", "prod")); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+}
+
+// TestStreamAllItems verifies that namespaces are listed one by one when listing
+// secrets cluster-wide is forbidden, and gathered in order.
+func TestStreamAllItems(t *testing.T) {
+	var objects []runtime.Object
+	for i := range 20 {
+		namespace := fmt.Sprintf("team-%02d", i)
+		objects = append(objects,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: namespace}},
+		)
+	}
+	clientset := fake.NewSimpleClientset(objects...)
+	clientset.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == metav1.NamespaceAll || action.GetNamespace() == "team-07" {
+			return true, nil, k8serrors.NewForbidden(corev1.Resource("secrets"), "", nil)
+		}
+		return false, nil, nil
+	})
+
+	t.Run("should send a result per namespace", func(t *testing.T) {
+		results, total, err := StreamAllItems(clientset)
+		if err != nil || total != 20 {
+			t.Fatalf("Expected 20 namespaces, but got %d, %v", total, err)
+		}
+		received := 0
+		for result := range results {
+			received++
+			if result.Namespace == "team-07" && !k8serrors.IsForbidden(result.Err) {
+				t.Errorf("Expected team-07 to be forbidden, but got %v", result.Err)
+			}
+		}
+		if received != total {
+			t.Errorf("Expected %d results, but got %d", total, received)
+		}
+	})
+
+	t.Run("should gather the secrets in order", func(t *testing.T) {
+		items, skipped, err := ListAllItems(clientset)
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if len(items) != 19 || items[0].Namespace != "team-00" || items[18].Namespace != "team-19" {
+			t.Errorf("Expected 19 secrets sorted by namespace, but got %v", items)
+		}
+		if len(skipped) != 1 || skipped[0] != "team-07" {
+			t.Errorf("Expected team-07 to be skipped, but got %v", skipped)
+		}
+	})
+}
+
+// TestItemDescription verifies that list rows show the key count, age, and size of
+// a secret, and its namespace only when every namespace is listed.
+func TestItemDescription(t *testing.T) {
+	item := Item{Name: "db", Namespace: "prod", Keys: 2, Size: 2048, Created: time.Now().Add(-72 * time.Hour)}
+
+	t.Run("should show the key count, age, and size", func(t *testing.T) {
+		description := item.Description()
+		for _, want := range []string{"2 key(s)", "3d old", FormatSize(2048)} {
+			if !strings.Contains(description, want) {
+				t.Errorf("Expected %q in the description, but got %q", want, description)
+			}
+		}
+		if strings.Contains(description, "prod") {
+			t.Errorf("Expected no namespace in the description, but got %q", description)
+		}
+	})
+
+	t.Run("should show the namespace of secrets listed across namespaces", func(t *testing.T) {
+		items, _, err := ListAllItems(fake.NewSimpleClientset(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"}}))
+		if err != nil || len(items) != 1 {
+			t.Fatalf("Expected one secret, but got %v, %v", items, err)
+		}
+		if description := items[0].Description(); !strings.HasPrefix(description, "Namespace: prod") {
+			t.Errorf("Expected the namespace first, but got %q", description)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// SelectSecrets returns the secrets of a namespace matching a label selector such
+// as app=legacy, sorted by name.
+func SelectSecrets(clientset Client, namespace, selector string) ([]SecretRef, error) {
+	if _, err := labels.Parse(selector); err != nil {
+		return nil, fmt.Errorf("invalid selector '%s': %w", selector, err)
+	}
+	secrets, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets in namespace '%s': %w", namespace, err)
+	}
+	refs := make([]SecretRef, len(secrets.Items))
+	for i, secret := range secrets.Items {
+		refs[i] = SecretRef{Namespace: secret.Namespace, Name: secret.Name}
+	}
+	slices.SortFunc(refs, func(a, b SecretRef) int { return strings.Compare(a.Name, b.Name) })
+	return refs, nil
+}
@@ -0,0 +1,34 @@
+package kube
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestSelectSecrets verifies that secrets are selected by their labels.
+func TestSelectSecrets(t *testing.T) {
+	secret := func(name string, labels map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels}}
+	}
+	clientset := fake.NewSimpleClientset(
+		secret("legacy-db", map[string]string{"app": "legacy"}),
+		secret("legacy-api", map[string]string{"app": "legacy"}),
+		secret("api", map[string]string{"app": "api"}),
+	)
+
+	refs, err := SelectSecrets(clientset, "default", "app=legacy")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(refs) != 2 || refs[0].Name != "legacy-api" || refs[1].Name != "legacy-db" {
+		t.Errorf("Expected the legacy secrets sorted by name, but got %v", refs)
+	}
+	t.Run("should reject invalid selectors", func(t *testing.T) {
+		if _, err := SelectSecrets(clientset, "default", "app in (legacy"); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SecretSizeLimit is the maximum size of a secret accepted by the API server.
+const SecretSizeLimit = 1 << 20
+
+// sizeWarningRatio is the share of secretSizeLimit above which a secret is flagged.
+const sizeWarningRatio = 0.8
+
+// SecretSize approximates the size of a secret's data: its keys and raw values.
+func SecretSize(secret *corev1.Secret) int {
+	size := 0
+	for key, value := range secret.Data {
+		size += len(key) + len(value)
+	}
+	return size
+}
+
+// FormatSize formats a byte count with binary units, e.g. "12.3 KiB".
+func FormatSize(size int) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
+
+// SizeWarning returns a warning if a secret is close to the size limit, or "".
+func SizeWarning(size int) string {
+	if float64(size) < sizeWarningRatio*SecretSizeLimit {
+		return ""
+	}
+	return fmt.Sprintf("⚠ %d%% of the 1 MiB limit", size*100/SecretSizeLimit)
+}
+
+// RankBySize sorts secrets by namespace, then by size (biggest first), keeping at
+// most limit secrets per namespace.
+func RankBySize(secrets []corev1.Secret, limit int) []*corev1.Secret {
+	ranked := make([]*corev1.Secret, len(secrets))
+	for i := range secrets {
+		ranked[i] = &secrets[i]
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Namespace != ranked[j].Namespace {
+			return ranked[i].Namespace < ranked[j].Namespace
+		}
+		return SecretSize(ranked[i]) > SecretSize(ranked[j])
+	})
+	if limit <= 0 {
+		return ranked
+	}
+	kept := ranked[:0]
+	perNamespace := make(map[string]int)
+	for _, secret := range ranked {
+		if perNamespace[secret.Namespace] < limit {
+			perNamespace[secret.Namespace]++
+			kept = append(kept, secret)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,26 @@
+package kube
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFormatSize verifies the formatting of byte counts.
+func TestFormatSize(t *testing.T) {
+	cases := map[int]string{0: "0 B", 512: "512 B", 1536: "1.5 KiB", 3 << 20: "3.0 MiB"}
+	for size, expected := range cases {
+		if got := FormatSize(size); got != expected {
+			t.Errorf("Expected %d to format as '%s', but got '%s'", size, expected, got)
+		}
+	}
+}
+
+// TestSizeWarning verifies that only secrets close to the limit are flagged.
+func TestSizeWarning(t *testing.T) {
+	if got := SizeWarning(SecretSizeLimit / 2); got != "" {
+		t.Errorf("Expected no warning at half the limit, but got '%s'", got)
+	}
+	if got := SizeWarning(SecretSizeLimit); !strings.Contains(got, "100%") {
+		t.Errorf("Expected a 100%% warning, but got '%s'", got)
+	}
+}
@@ -0,0 +1,160 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Snapshot is the state of some secrets before a change, from which the change
+// can be undone.
+type Snapshot struct {
+	Secrets []*corev1.Secret // The secrets that existed, as they were.
+	Missing []SecretRef      // The secrets that did not exist yet.
+	// Versions are the resource versions the change left the secrets at, without
+	// the secrets it left missing, as recorded by RecordVersions. A secret changed
+	// again since is not restored, so that the later change is not overwritten.
+	Versions map[SecretRef]string
+}
+
+// TakeSnapshot records the current state of the referenced secrets, which may not
+// exist yet.
+func TakeSnapshot(clientset Client, refs []SecretRef) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	for _, ref := range refs {
+		secret, err := clientset.CoreV1().Secrets(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			snapshot.Missing = append(snapshot.Missing, ref)
+		case err != nil:
+			return nil, fmt.Errorf("failed to get secret '%s': %w", ref, err)
+		default:
+			snapshot.Secrets = append(snapshot.Secrets, secret)
+		}
+	}
+	return snapshot, nil
+}
+
+// RecordVersions records the resource versions the change left the secrets at,
+// once it is made.
+func (s *Snapshot) RecordVersions(clientset Client) error {
+	refs := slices.Clone(s.Missing)
+	for _, secret := range s.Secrets {
+		refs = append(refs, SecretRef{Namespace: secret.Namespace, Name: secret.Name})
+	}
+	versions := make(map[SecretRef]string, len(refs))
+	for _, ref := range refs {
+		secret, err := clientset.CoreV1().Secrets(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			return fmt.Errorf("failed to get secret '%s': %w", ref, err)
+		default:
+			versions[ref] = secret.ResourceVersion
+		}
+	}
+	s.Versions = versions
+	return nil
+}
+
+// Restore puts the secrets back in the state of the snapshot: secrets that existed
+// are restored, and secrets that the change created are deleted. The versions
+// after the change must have been recorded.
+func (s *Snapshot) Restore(clientset Client) error {
+	if s.Versions == nil {
+		return errors.New("the secrets were not recorded after the change, so it cannot be undone")
+	}
+	var errs []error
+	for _, secret := range s.Secrets {
+		version, exists := s.Versions[SecretRef{Namespace: secret.Namespace, Name: secret.Name}]
+		if err := restoreSecret(clientset, secret, version, exists); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, ref := range s.Missing {
+		version, exists := s.Versions[ref]
+		if !exists {
+			continue
+		}
+		err := clientset.CoreV1().Secrets(ref.Namespace).Delete(context.TODO(), ref.Name, metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{ResourceVersion: &version},
+		})
+		if apierrors.IsConflict(err) {
+			err = errChangedSince
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete secret '%s': %w", ref, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// errChangedSince is returned for a secret that changed after the change being
+// undone, which restoring it would overwrite.
+var errChangedSince = errors.New("it changed since, so restoring it would overwrite the newer change")
+
+// restoreSecret writes a previous version of a secret back, provided the secret is
+// still at version, the resource version the change left it at, or still missing
+// if the change did not leave it existing. It is created again if it was deleted,
+// and recreated if it became immutable or changed type, since neither can be
+// updated in place.
+func restoreSecret(clientset Client, previous *corev1.Secret, version string, exists bool) error {
+	ref := SecretRef{Namespace: previous.Namespace, Name: previous.Name}
+	restored := recreatedSecret(previous)
+	secrets := clientset.CoreV1().Secrets(ref.Namespace)
+	current, err := secrets.Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err) && !exists:
+		_, err = secrets.Create(context.TODO(), restored, metav1.CreateOptions{FieldManager: FieldManager})
+	case apierrors.IsNotFound(err):
+		err = errChangedSince
+	case err != nil:
+	case !exists || current.ResourceVersion != version:
+		err = errChangedSince
+	case isImmutable(current) || current.Type != previous.Type:
+		err = replaceSecret(clientset, current, restored)
+	default:
+		restored.ResourceVersion = current.ResourceVersion
+		_, err = secrets.Update(context.TODO(), restored, metav1.UpdateOptions{FieldManager: FieldManager})
+	}
+	if apierrors.IsConflict(err) {
+		err = errChangedSince
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore secret '%s': %w", ref, err)
+	}
+	return nil
+}
+
+// recreatedSecret returns what to create in place of a secret: a clone that,
+// unlike copies, keeps the owner references, so that the secret is still garbage
+// collected with its owner.
+func recreatedSecret(secret *corev1.Secret) *corev1.Secret {
+	recreated := cloneSecret(secret, secret.Namespace)
+	recreated.OwnerReferences = secret.OwnerReferences
+	return recreated
+}
+
+// replaceSecret deletes a secret, provided it is still the version read, and
+// creates replacement in its place. If replacement cannot be created, the secret
+// is created again as it was.
+func replaceSecret(clientset Client, current, replacement *corev1.Secret) error {
+	ref := SecretRef{Namespace: current.Namespace, Name: current.Name}
+	secrets := clientset.CoreV1().Secrets(ref.Namespace)
+	err := secrets.Delete(context.TODO(), ref.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &current.UID, ResourceVersion: &current.ResourceVersion},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete secret '%s': %w", ref, err)
+	}
+	if _, err := secrets.Create(context.TODO(), replacement, metav1.CreateOptions{FieldManager: FieldManager}); err != nil {
+		_, restoreErr := secrets.Create(context.TODO(), recreatedSecret(current), metav1.CreateOptions{FieldManager: FieldManager})
+		return errors.Join(fmt.Errorf("failed to recreate secret '%s': %w", ref, err), restoreErr)
+	}
+	return nil
+}
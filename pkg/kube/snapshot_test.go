@@ -0,0 +1,149 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestSnapshotRestore verifies that restoring a snapshot undoes edits, deletions
+// and creations, unless the secrets changed again since.
+func TestSnapshotRestore(t *testing.T) {
+	owners := []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "app", UID: "1234"}}
+	original := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-db", Namespace: "default", Labels: map[string]string{"team": "payments"}, OwnerReferences: owners},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{"password": []byte("s3cr3t")},
+	}
+	refs := []SecretRef{{Namespace: "default", Name: "app-db"}, {Namespace: "default", Name: "app-db-copy"}}
+	secrets := func(clientset Client) (*corev1.Secret, error) {
+		return clientset.CoreV1().Secrets("default").Get(context.TODO(), "app-db", metav1.GetOptions{})
+	}
+
+	t.Run("should record existing and missing secrets", func(t *testing.T) {
+		snapshot, err := TakeSnapshot(fake.NewSimpleClientset(original.DeepCopy()), refs)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if len(snapshot.Secrets) != 1 || len(snapshot.Missing) != 1 || snapshot.Missing[0] != refs[1] {
+			t.Errorf("Expected app-db to exist and app-db-copy to be missing, but got %+v", snapshot)
+		}
+	})
+
+	t.Run("should undo an edit", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(original.DeepCopy())
+		snapshot, _ := TakeSnapshot(clientset, refs[:1])
+		edited, _ := secrets(clientset)
+		edited.Data = map[string][]byte{"password": []byte("changed")}
+		edited.Labels = nil
+		if _, err := clientset.CoreV1().Secrets("default").Update(context.TODO(), edited, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("Failed to edit the secret: %v", err)
+		}
+		if err := snapshot.RecordVersions(clientset); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if err := snapshot.Restore(clientset); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		restored, _ := secrets(clientset)
+		if string(restored.Data["password"]) != "s3cr3t" || restored.Labels["team"] != "payments" {
+			t.Errorf("Expected the original data and labels, but got %v and %v", restored.Data, restored.Labels)
+		}
+	})
+
+	t.Run("should recreate a deleted secret and delete a created one", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(original.DeepCopy())
+		snapshot, _ := TakeSnapshot(clientset, refs)
+		if err := RenameSecret(clientset, refs[0], "app-db-copy"); err != nil {
+			t.Fatalf("Failed to rename the secret: %v", err)
+		}
+		if err := snapshot.RecordVersions(clientset); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if err := snapshot.Restore(clientset); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if _, err := secrets(clientset); err != nil {
+			t.Errorf("Expected app-db to be back, but got: %v", err)
+		}
+		if _, err := clientset.CoreV1().Secrets("default").Get(context.TODO(), "app-db-copy", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+			t.Errorf("Expected app-db-copy to be deleted, but got: %v", err)
+		}
+	})
+
+	t.Run("should recreate a secret that became immutable", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(original.DeepCopy())
+		snapshot, _ := TakeSnapshot(clientset, refs[:1])
+		if err := SetImmutable(clientset, refs[0], true); err != nil {
+			t.Fatalf("Failed to make the secret immutable: %v", err)
+		}
+		if err := snapshot.RecordVersions(clientset); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if err := snapshot.Restore(clientset); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		restored, _ := secrets(clientset)
+		if isImmutable(restored) {
+			t.Error("Expected the secret to be mutable again, but it is not")
+		}
+		if len(restored.OwnerReferences) != 1 || restored.OwnerReferences[0].UID != "1234" {
+			t.Errorf("Expected the owner references to be kept, but got %v", restored.OwnerReferences)
+		}
+	})
+
+	t.Run("should not overwrite a later change", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(original.DeepCopy())
+		snapshot, _ := TakeSnapshot(clientset, refs[:1])
+		edited, _ := secrets(clientset)
+		edited.Data, edited.ResourceVersion = map[string][]byte{"password": []byte("changed")}, "2"
+		if _, err := clientset.CoreV1().Secrets("default").Update(context.TODO(), edited, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("Failed to edit the secret: %v", err)
+		}
+		if err := snapshot.RecordVersions(clientset); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		edited.Data, edited.ResourceVersion = map[string][]byte{"password": []byte("changed again")}, "3"
+		if _, err := clientset.CoreV1().Secrets("default").Update(context.TODO(), edited, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("Failed to edit the secret: %v", err)
+		}
+		if err := snapshot.Restore(clientset); !errors.Is(err, errChangedSince) {
+			t.Errorf("Expected the restore to be refused, but got: %v", err)
+		}
+		if current, _ := secrets(clientset); string(current.Data["password"]) != "changed again" {
+			t.Errorf("Expected the later change to be kept, but got %v", current.Data)
+		}
+	})
+
+	t.Run("should put the secret back if it cannot be recreated", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(original.DeepCopy())
+		snapshot, _ := TakeSnapshot(clientset, refs[:1])
+		if err := SetImmutable(clientset, refs[0], true); err != nil {
+			t.Fatalf("Failed to make the secret immutable: %v", err)
+		}
+		if err := snapshot.RecordVersions(clientset); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		failed := false
+		clientset.PrependReactor("create", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+			if failed {
+				return false, nil, nil
+			}
+			failed = true
+			return true, nil, errors.New("admission webhook denied the request")
+		})
+		if err := snapshot.Restore(clientset); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+		if current, err := secrets(clientset); err != nil || !isImmutable(current) {
+			t.Errorf("Expected the immutable secret to be put back, but got %v, %v", current, err)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package kube
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Decrypter returns the decrypted content of a file encrypted with SOPS.
+type Decrypter func(path string) ([]byte, error)
+
+// Encrypter encrypts the content of a file to be written to path with SOPS.
+type Encrypter func(path string, content []byte) ([]byte, error)
+
+// DecryptSOPS decrypts a file with the sops CLI, which finds the keys the way it
+// always does: from .sops.yaml, age or PGP keys, or a cloud KMS.
+func DecryptSOPS(path string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("sops", "--decrypt", path) //nolint:gosec // The path is chosen by the user.
+	cmd.Stderr = &stderr
+	content, err := cmd.Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, fmt.Errorf("'%s' is encrypted with SOPS, but sops is not installed", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt '%s' with sops: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return content, nil
+}
+
+// EncryptSOPS encrypts YAML content with the sops CLI, with the keys of the
+// creation rule of .sops.yaml matching path, as 'sops --encrypt path' would. The
+// content is passed on stdin, so that it is never written to disk unencrypted.
+func EncryptSOPS(path string, content []byte) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("sops", "--encrypt", "--input-type", "yaml", "--output-type", "yaml", "--filename-override", path, "/dev/stdin") //nolint:gosec // The path is chosen by the user.
+	cmd.Stdin, cmd.Stderr = bytes.NewReader(content), &stderr
+	encrypted, err := cmd.Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, errors.New("sops is not installed")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt '%s' with sops: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return encrypted, nil
+}
+
+// WriteSOPSExport writes a secret to path as a Secret manifest encrypted with
+// encrypt, ready to be committed to a GitOps repository and decrypted by Flux or
+// the sops CLI. Server-populated metadata is left out, as are the annotations of
+// rotations, which may hold previous values: creation rules usually encrypt only
+// data and stringData.
+func WriteSOPSExport(clientset Client, ref SecretRef, path string, encrypt Encrypter) error {
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return err
+	}
+	manifest := cloneSecret(secret, secret.Namespace)
+	manifest.Annotations = withoutRotationAnnotations(manifest.Annotations)
+	manifest.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}
+	content, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	encrypted, err := encrypt(path, content)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, encrypted, 0o600); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", path, err)
+	}
+	return nil
+}
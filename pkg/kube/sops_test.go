@@ -0,0 +1,69 @@
+package kube
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+)
+
+// TestWriteSOPSExport verifies that a secret is exported as a clean Secret
+// manifest, written only once encrypted.
+func TestWriteSOPSExport(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "db", Namespace: "prod", ResourceVersion: "42", UID: "abc",
+			Annotations: map[string]string{
+				lastAppliedAnnotation: "{}", "team": "payments",
+				PreviousValueAnnotationPrefix + "password": "MGxkLXMzY3IzdA==",
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{"password": []byte("s3cr3t")},
+	})
+	ref := SecretRef{Namespace: "prod", Name: "db"}
+	path := filepath.Join(t.TempDir(), "db.sops.yaml")
+
+	t.Run("should write the encrypted manifest", func(t *testing.T) {
+		var plaintext []byte
+		encrypt := func(target string, content []byte) ([]byte, error) {
+			if target != path {
+				t.Errorf("Expected the rules of %s to be used, but got %s", path, target)
+			}
+			plaintext = content
+			return []byte("encrypted"), nil
+		}
+		if err := WriteSOPSExport(clientset, ref, path, encrypt); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		var manifest corev1.Secret
+		if err := yaml.Unmarshal(plaintext, &manifest); err != nil {
+			t.Fatalf("Expected a manifest, but got: %v", err)
+		}
+		if manifest.Kind != "Secret" || manifest.APIVersion != "v1" || string(manifest.Data["password"]) != "s3cr3t" || manifest.Annotations["team"] != "payments" {
+			t.Errorf("Expected the secret as a manifest, but got:\n%s", plaintext)
+		}
+		if manifest.ResourceVersion != "" || manifest.UID != "" || strings.Contains(string(plaintext), lastAppliedAnnotation) || strings.Contains(string(plaintext), PreviousValueAnnotationPrefix) {
+			t.Errorf("Expected no server-populated metadata, but got:\n%s", plaintext)
+		}
+		if content, err := os.ReadFile(path); err != nil || string(content) != "encrypted" {
+			t.Errorf("Expected the encrypted content to be written, but got %q (error: %v)", content, err)
+		}
+	})
+	t.Run("should write nothing when the encryption fails", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "db.sops.yaml")
+		encrypt := func(string, []byte) ([]byte, error) { return nil, errors.New("no matching creation rules found") }
+		if err := WriteSOPSExport(clientset, ref, path, encrypt); err == nil {
+			t.Fatal("Expected an error, but got none")
+		}
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected no file, but got: %v", err)
+		}
+	})
+}
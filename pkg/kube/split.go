@@ -0,0 +1,236 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// WorkloadPatch is a strategic merge patch of the pod template of a workload.
+type WorkloadPatch struct {
+	Workload WorkloadRef
+	Patch    []byte
+}
+
+// PrepareSplit returns the mutations that move keys of a secret into a new Opaque
+// secret: the creation of the new secret, and the update of the original one.
+func PrepareSplit(clientset Client, ref SecretRef, keys []string, into string) (create, update Mutation, err error) {
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return Mutation{}, Mutation{}, err
+	}
+	if isImmutable(secret) {
+		return Mutation{}, Mutation{}, ErrImmutable
+	}
+	if len(keys) == 0 || len(keys) == len(secret.Data) {
+		return Mutation{}, Mutation{}, fmt.Errorf("select some of the %d keys of secret '%s' to move, but not all", len(secret.Data), ref)
+	}
+	moved := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: into, Namespace: ref.Namespace, Labels: secret.Labels},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       make(map[string][]byte, len(keys)),
+	}
+	kept := secret.DeepCopy()
+	for _, key := range keys {
+		value, ok := secret.Data[key]
+		if !ok {
+			return Mutation{}, Mutation{}, fmt.Errorf("secret '%s' has no key '%s'", ref, key)
+		}
+		moved.Data[key] = value
+		delete(kept.Data, key)
+	}
+	return Mutation{After: moved}, Mutation{Before: secret, After: kept}, nil
+}
+
+// SplitPatches returns the patches that point the workloads reading moved keys of
+// a secret to the new secret, and describes the references that cannot be patched
+// and need a manual change, such as volumes that mount the whole secret.
+func SplitPatches(clientset Client, ref SecretRef, keys []string, into string) ([]WorkloadPatch, []string, error) {
+	ctx := context.TODO()
+	apps := clientset.AppsV1()
+	var patches []WorkloadPatch
+	var manual []string
+	add := func(kind, name string, original any, spec *corev1.PodSpec, modified any, dataStruct any) error {
+		changed, notes := repointPodSpec(spec, ref.Name, into, keys)
+		for _, note := range notes {
+			manual = append(manual, fmt.Sprintf("%s/%s: %s", kind, name, note))
+		}
+		if !changed {
+			return nil
+		}
+		patch, err := twoWayPatch(original, modified, dataStruct)
+		if err != nil {
+			return fmt.Errorf("failed to patch %s/%s: %w", kind, name, err)
+		}
+		patches = append(patches, WorkloadPatch{Workload: WorkloadRef{kind: kind, name: name}, Patch: patch})
+		return nil
+	}
+
+	deployments, err := apps.Deployments(ref.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		modified := d.DeepCopy()
+		if err := add("Deployment", d.Name, d, &modified.Spec.Template.Spec, modified, appsv1.Deployment{}); err != nil {
+			return nil, nil, err
+		}
+	}
+	statefulSets, err := apps.StatefulSets(ref.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulSets.Items {
+		s := &statefulSets.Items[i]
+		modified := s.DeepCopy()
+		if err := add("StatefulSet", s.Name, s, &modified.Spec.Template.Spec, modified, appsv1.StatefulSet{}); err != nil {
+			return nil, nil, err
+		}
+	}
+	daemonSets, err := apps.DaemonSets(ref.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for i := range daemonSets.Items {
+		d := &daemonSets.Items[i]
+		modified := d.DeepCopy()
+		if err := add("DaemonSet", d.Name, d, &modified.Spec.Template.Spec, modified, appsv1.DaemonSet{}); err != nil {
+			return nil, nil, err
+		}
+	}
+	return patches, manual, nil
+}
+
+// twoWayPatch computes the strategic merge patch from original to modified.
+func twoWayPatch(original, modified, dataStruct any) ([]byte, error) {
+	before, err := json.Marshal(original)
+	if err != nil {
+		return nil, err
+	}
+	after, err := json.Marshal(modified)
+	if err != nil {
+		return nil, err
+	}
+	return strategicpatch.CreateTwoWayMergePatch(before, after, dataStruct)
+}
+
+// repointPodSpec points the references of a pod spec to moved keys of secret from
+// to secret to. Env references to moved keys are repointed, envFrom and projected
+// volumes of the whole secret get a source for the new secret next to them, and
+// volumes whose items are all moved are repointed. It reports whether the spec
+// changed, and describes the references it could not repoint.
+func repointPodSpec(spec *corev1.PodSpec, from, to string, keys []string) (bool, []string) {
+	changed := false
+	var manual []string
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			c := &containers[i]
+			for j := range c.Env {
+				ref := c.Env[j].ValueFrom
+				if ref != nil && ref.SecretKeyRef != nil && ref.SecretKeyRef.Name == from && slices.Contains(keys, ref.SecretKeyRef.Key) {
+					ref.SecretKeyRef.Name = to
+					changed = true
+				}
+			}
+			for j := len(c.EnvFrom) - 1; j >= 0; j-- {
+				source := c.EnvFrom[j]
+				if source.SecretRef != nil && source.SecretRef.Name == from {
+					added := corev1.EnvFromSource{Prefix: source.Prefix, SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: to}, Optional: source.SecretRef.Optional}}
+					c.EnvFrom = slices.Insert(c.EnvFrom, j+1, added)
+					changed = true
+				}
+			}
+		}
+	}
+	for i := range spec.Volumes {
+		volume := &spec.Volumes[i]
+		if volume.Secret != nil && volume.Secret.SecretName == from {
+			if movesAllItems(volume.Secret.Items, keys) {
+				volume.Secret.SecretName = to
+				changed = true
+			} else if usesMovedItems(volume.Secret.Items, keys) {
+				manual = append(manual, fmt.Sprintf("volume '%s' mounts moved and kept keys of the secret", volume.Name))
+			}
+		}
+		if volume.Projected == nil {
+			continue
+		}
+		for j := len(volume.Projected.Sources) - 1; j >= 0; j-- {
+			source := volume.Projected.Sources[j].Secret
+			if source == nil || source.Name != from || !usesMovedItems(source.Items, keys) {
+				continue
+			}
+			added := &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: to}, Optional: source.Optional}
+			replaced := false
+			if len(source.Items) > 0 {
+				var kept []corev1.KeyToPath
+				for _, item := range source.Items {
+					if slices.Contains(keys, item.Key) {
+						added.Items = append(added.Items, item)
+					} else {
+						kept = append(kept, item)
+					}
+				}
+				source.Items, replaced = kept, len(kept) == 0
+			}
+			if replaced {
+				volume.Projected.Sources[j] = corev1.VolumeProjection{Secret: added}
+			} else {
+				volume.Projected.Sources = slices.Insert(volume.Projected.Sources, j+1, corev1.VolumeProjection{Secret: added})
+			}
+			changed = true
+		}
+	}
+	return changed, manual
+}
+
+// movesAllItems reports whether a volume mounts only moved keys.
+func movesAllItems(items []corev1.KeyToPath, keys []string) bool {
+	if len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		if !slices.Contains(keys, item.Key) {
+			return false
+		}
+	}
+	return true
+}
+
+// usesMovedItems reports whether a volume mounts any moved key, which it does if
+// it mounts every key.
+func usesMovedItems(items []corev1.KeyToPath, keys []string) bool {
+	if len(items) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(items, func(item corev1.KeyToPath) bool { return slices.Contains(keys, item.Key) })
+}
+
+// ApplyWorkloadPatches applies the patches to the workloads of a namespace.
+func ApplyWorkloadPatches(clientset Client, namespace string, patches []WorkloadPatch) error {
+	ctx := context.TODO()
+	apps := clientset.AppsV1()
+	for _, p := range patches {
+		var err error
+		switch p.Workload.kind {
+		case "Deployment":
+			_, err = apps.Deployments(namespace).Patch(ctx, p.Workload.name, types.StrategicMergePatchType, p.Patch, metav1.PatchOptions{FieldManager: FieldManager})
+		case "StatefulSet":
+			_, err = apps.StatefulSets(namespace).Patch(ctx, p.Workload.name, types.StrategicMergePatchType, p.Patch, metav1.PatchOptions{FieldManager: FieldManager})
+		case "DaemonSet":
+			_, err = apps.DaemonSets(namespace).Patch(ctx, p.Workload.name, types.StrategicMergePatchType, p.Patch, metav1.PatchOptions{FieldManager: FieldManager})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to patch %s: %w", p.Workload, err)
+		}
+	}
+	return nil
+}
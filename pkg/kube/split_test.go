@@ -0,0 +1,86 @@
+package kube
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestSplit verifies that keys move to a new secret and that workloads are repointed.
+func TestSplit(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Data:       map[string][]byte{"db-url": []byte("postgres://db"), "stripe-key": []byte("sk"), "smtp": []byte("mail")},
+	}
+	envRef := func(key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "app"}, Key: key}}
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:    "api",
+				Env:     []corev1.EnvVar{{Name: "STRIPE_KEY", ValueFrom: envRef("stripe-key")}, {Name: "DB_URL", ValueFrom: envRef("db-url")}},
+				EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "app"}}}},
+			}},
+			Volumes: []corev1.Volume{
+				{Name: "all", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "app"}}},
+				{Name: "stripe", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "app", Items: []corev1.KeyToPath{{Key: "stripe-key", Path: "key"}}}}},
+			},
+		}}},
+	}
+	clientset := fake.NewSimpleClientset(secret, deployment)
+	ref := SecretRef{Namespace: "default", Name: "app"}
+	keys := []string{"stripe-key"}
+
+	t.Run("should move the keys to a new secret", func(t *testing.T) {
+		create, update, err := PrepareSplit(clientset, ref, keys, "payments")
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if !reflect.DeepEqual(create.After.Data, map[string][]byte{"stripe-key": []byte("sk")}) || create.Before != nil {
+			t.Errorf("Expected a new secret with stripe-key, but got %v", create.After.Data)
+		}
+		if _, ok := update.After.Data["stripe-key"]; ok || len(update.After.Data) != 2 {
+			t.Errorf("Expected the original secret to keep the other keys, but got %v", update.After.Data)
+		}
+		if _, _, err := PrepareSplit(clientset, ref, []string{"db-url", "stripe-key", "smtp"}, "payments"); err == nil {
+			t.Error("Expected an error when moving every key, but got none")
+		}
+	})
+
+	t.Run("should repoint the workloads", func(t *testing.T) {
+		patches, manual, err := SplitPatches(clientset, ref, keys, "payments")
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if len(patches) != 1 || patches[0].Workload.String() != "Deployment/api" {
+			t.Fatalf("Expected a patch of Deployment/api, but got %v", patches)
+		}
+		if !reflect.DeepEqual(manual, []string{"Deployment/api: volume 'all' mounts moved and kept keys of the secret"}) {
+			t.Errorf("Expected the whole-secret volume to need a manual change, but got %v", manual)
+		}
+		if err := ApplyWorkloadPatches(clientset, "default", patches); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		patched, err := clientset.AppsV1().Deployments("default").Get(t.Context(), "api", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		spec := patched.Spec.Template.Spec
+		c := spec.Containers[0]
+		if c.Env[0].ValueFrom.SecretKeyRef.Name != "payments" || c.Env[1].ValueFrom.SecretKeyRef.Name != "app" {
+			t.Errorf("Expected only STRIPE_KEY to read payments, but got %v", c.Env)
+		}
+		if len(c.EnvFrom) != 2 || c.EnvFrom[1].SecretRef.Name != "payments" {
+			t.Errorf("Expected envFrom to read both secrets, but got %v", c.EnvFrom)
+		}
+		if spec.Volumes[0].Secret.SecretName != "app" || spec.Volumes[1].Secret.SecretName != "payments" {
+			t.Errorf("Expected only the stripe volume to read payments, but got %v", spec.Volumes)
+		}
+	})
+}
@@ -0,0 +1,245 @@
+package kube
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// openSSHMagic starts every key in the OpenSSH private key format.
+const openSSHMagic = "openssh-key-v1\x00"
+
+// SSHKeyInfo describes an SSH private key without revealing it.
+type SSHKeyInfo struct {
+	Format      string // OpenSSH, PKCS#1, PKCS#8, or SEC1.
+	Algorithm   string // e.g. ssh-ed25519 or ssh-rsa.
+	Bits        int
+	Encrypted   bool
+	Fingerprint string // SHA256 fingerprint of the public key, as printed by ssh-keygen -l.
+	Comment     string // Only readable from unencrypted OpenSSH keys.
+}
+
+// String summarizes the key, e.g. "ssh-ed25519 (256 bits, OpenSSH, unencrypted)".
+func (k SSHKeyInfo) String() string {
+	var details []string
+	if k.Bits > 0 {
+		details = append(details, fmt.Sprintf("%d bits", k.Bits))
+	}
+	details = append(details, k.Format)
+	if k.Encrypted {
+		details = append(details, "encrypted")
+	} else {
+		details = append(details, "unencrypted")
+	}
+	algorithm := k.Algorithm
+	if algorithm == "" {
+		algorithm = "unknown algorithm"
+	}
+	return fmt.Sprintf("%s (%s)", algorithm, strings.Join(details, ", "))
+}
+
+// ParseSSHPrivateKey reads the metadata of a PEM-encoded SSH private key, in the
+// OpenSSH format or one of the PKCS formats that ssh accepts.
+func ParseSSHPrivateKey(data []byte) (SSHKeyInfo, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return SSHKeyInfo{}, errors.New("no PEM-encoded private key found")
+	}
+	if block.Type == "OPENSSH PRIVATE KEY" {
+		return parseOpenSSHKey(block.Bytes)
+	}
+	info := SSHKeyInfo{Format: map[string]string{
+		"RSA PRIVATE KEY": "PKCS#1",
+		"PRIVATE KEY":     "PKCS#8",
+		"EC PRIVATE KEY":  "SEC1",
+	}[block.Type]}
+	if info.Format == "" {
+		return SSHKeyInfo{}, fmt.Errorf("unsupported key type '%s'", block.Type)
+	}
+	if strings.Contains(block.Headers["Proc-Type"], "ENCRYPTED") {
+		info.Encrypted = true
+		return info, nil
+	}
+	var key any
+	var err error
+	switch info.Format {
+	case "PKCS#1":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PKCS#8":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return SSHKeyInfo{}, err
+	}
+	blob, err := sshPublicKeyBlob(key)
+	if err != nil {
+		return SSHKeyInfo{}, err
+	}
+	return describeSSHPublicKey(info, blob)
+}
+
+// parseOpenSSHKey reads the header of an OpenSSH private key, and the comment if
+// the key is not encrypted.
+func parseOpenSSHKey(data []byte) (SSHKeyInfo, error) {
+	rest, ok := bytes.CutPrefix(data, []byte(openSSHMagic))
+	if !ok {
+		return SSHKeyInfo{}, errors.New("invalid OpenSSH private key")
+	}
+	r := &sshReader{data: rest}
+	cipher := string(r.bytes())
+	r.bytes() // KDF name
+	r.bytes() // KDF options
+	if count := r.uint32(); count != 1 {
+		return SSHKeyInfo{}, fmt.Errorf("unsupported number of keys: %d", count)
+	}
+	blob := r.bytes()
+	private := r.bytes()
+	if r.err != nil {
+		return SSHKeyInfo{}, r.err
+	}
+	info, err := describeSSHPublicKey(SSHKeyInfo{Format: "OpenSSH", Encrypted: cipher != "none"}, blob)
+	if err != nil || info.Encrypted {
+		return info, err
+	}
+	info.Comment = openSSHComment(info.Algorithm, private)
+	return info, nil
+}
+
+// openSSHComment reads the comment from the unencrypted private section of an
+// OpenSSH key, skipping the check integers and the key material.
+func openSSHComment(algorithm string, private []byte) string {
+	r := &sshReader{data: private}
+	r.uint32()
+	r.uint32()
+	r.bytes() // Key type.
+	fields := map[string]int{"ssh-ed25519": 2, "ssh-rsa": 6}[algorithm]
+	if strings.HasPrefix(algorithm, "ecdsa-") {
+		fields = 3
+	}
+	if fields == 0 {
+		return ""
+	}
+	for range fields {
+		r.bytes()
+	}
+	comment := r.bytes()
+	if r.err != nil {
+		return ""
+	}
+	return string(comment)
+}
+
+// describeSSHPublicKey fills in the algorithm, size, and fingerprint from a public
+// key in the SSH wire format.
+func describeSSHPublicKey(info SSHKeyInfo, blob []byte) (SSHKeyInfo, error) {
+	r := &sshReader{data: blob}
+	info.Algorithm = string(r.bytes())
+	switch {
+	case info.Algorithm == "ssh-ed25519":
+		info.Bits = 256
+	case info.Algorithm == "ssh-rsa":
+		r.bytes() // Exponent.
+		info.Bits = new(big.Int).SetBytes(r.bytes()).BitLen()
+	case strings.HasPrefix(info.Algorithm, "ecdsa-sha2-nistp"):
+		if bits, err := strconv.Atoi(strings.TrimPrefix(info.Algorithm, "ecdsa-sha2-nistp")); err == nil {
+			info.Bits = bits
+		}
+	}
+	if r.err != nil {
+		return SSHKeyInfo{}, r.err
+	}
+	sum := sha256.Sum256(blob)
+	info.Fingerprint = "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+	return info, nil
+}
+
+// sshPublicKeyBlob encodes the public half of a private key in the SSH wire format.
+func sshPublicKeyBlob(key any) ([]byte, error) {
+	var w sshWriter
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		w.bytes([]byte("ssh-rsa"))
+		w.mpint(big.NewInt(int64(k.E)))
+		w.mpint(k.N)
+	case *ecdsa.PrivateKey:
+		curve := fmt.Sprintf("nistp%d", k.Curve.Params().BitSize)
+		point, err := k.PublicKey.ECDH()
+		if err != nil {
+			return nil, err
+		}
+		w.bytes([]byte("ecdsa-sha2-" + curve))
+		w.bytes([]byte(curve))
+		w.bytes(point.Bytes())
+	case ed25519.PrivateKey:
+		public, ok := k.Public().(ed25519.PublicKey)
+		if !ok {
+			return nil, errors.New("invalid ed25519 key")
+		}
+		w.bytes([]byte("ssh-ed25519"))
+		w.bytes(public)
+	default:
+		return nil, fmt.Errorf("unsupported key algorithm %T", key)
+	}
+	return w.buf.Bytes(), nil
+}
+
+// sshReader reads the length-prefixed values of the SSH wire format, keeping the
+// first error.
+type sshReader struct {
+	data []byte
+	err  error
+}
+
+func (r *sshReader) uint32() uint32 {
+	if r.err != nil || len(r.data) < 4 {
+		r.err = errors.New("truncated SSH key")
+		return 0
+	}
+	v := binary.BigEndian.Uint32(r.data)
+	r.data = r.data[4:]
+	return v
+}
+
+func (r *sshReader) bytes() []byte {
+	n := r.uint32()
+	if r.err != nil || uint32(len(r.data)) < n {
+		r.err = errors.New("truncated SSH key")
+		return nil
+	}
+	v := r.data[:n]
+	r.data = r.data[n:]
+	return v
+}
+
+// sshWriter writes values in the SSH wire format.
+type sshWriter struct{ buf bytes.Buffer }
+
+// bytes writes a length-prefixed byte string.
+func (w *sshWriter) bytes(v []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(v)))
+	w.buf.Write(n[:])
+	w.buf.Write(v)
+}
+
+// mpint writes a non-negative integer, with a leading zero byte if its top bit is set.
+func (w *sshWriter) mpint(v *big.Int) {
+	b := v.Bytes()
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	w.bytes(b)
+}
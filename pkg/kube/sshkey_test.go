@@ -0,0 +1,84 @@
+package kube
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/internal/testfixtures"
+)
+
+// TestParseSSHPrivateKey verifies the metadata read from the supported key formats.
+func TestParseSSHPrivateKey(t *testing.T) {
+	t.Run("should read the comment of an OpenSSH key", func(t *testing.T) {
+		info, err := ParseSSHPrivateKey(testfixtures.NewOpenSSHKey(t, "deploy@ci"))
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if info.String() != "ssh-ed25519 (256 bits, OpenSSH, unencrypted)" {
+			t.Errorf("Expected an unencrypted ed25519 key, but got %s", info)
+		}
+		if info.Comment != "deploy@ci" {
+			t.Errorf("Expected comment 'deploy@ci', but got '%s'", info.Comment)
+		}
+		if !strings.HasPrefix(info.Fingerprint, "SHA256:") || len(info.Fingerprint) != 50 {
+			t.Errorf("Expected a SHA256 fingerprint, but got '%s'", info.Fingerprint)
+		}
+	})
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+	cases := []struct {
+		block    *pem.Block
+		expected string
+	}{
+		{&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}, "ssh-rsa (2048 bits, PKCS#1, unencrypted)"},
+		{&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}, "ecdsa-sha2-nistp384 (384 bits, SEC1, unencrypted)"},
+		{&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}, "ssh-ed25519 (256 bits, PKCS#8, unencrypted)"},
+		{&pem.Block{Type: "RSA PRIVATE KEY", Headers: map[string]string{"Proc-Type": "4,ENCRYPTED"}, Bytes: []byte("x")}, "unknown algorithm (PKCS#1, encrypted)"},
+	}
+	for _, c := range cases {
+		info, err := ParseSSHPrivateKey(pem.EncodeToMemory(c.block))
+		if err != nil {
+			t.Errorf("Expected no error for %s, but got %v", c.expected, err)
+			continue
+		}
+		if info.String() != c.expected {
+			t.Errorf("Expected %s, but got %s", c.expected, info)
+		}
+	}
+
+	t.Run("should fail on data that is not a key", func(t *testing.T) {
+		if _, err := ParseSSHPrivateKey([]byte("not a key")); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+		truncated := pem.EncodeToMemory(&pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: []byte(openSSHMagic + "\x00\x00")})
+		if _, err := ParseSSHPrivateKey(truncated); err == nil {
+			t.Error("Expected an error for a truncated key, but got none")
+		}
+	})
+}
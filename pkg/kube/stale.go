@@ -0,0 +1,114 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RotationPeriodAnnotation sets how often a secret must be rotated, e.g. "30d".
+const RotationPeriodAnnotation = "kds.diskmanti.io/rotation-period"
+
+// StaleSecret is a secret that is due for rotation.
+type StaleSecret struct {
+	Namespace      string            `json:"namespace"`
+	Name           string            `json:"name"`
+	Type           corev1.SecretType `json:"type"`
+	LastChanged    time.Time         `json:"lastChanged"`
+	AgeDays        int               `json:"ageDays"`
+	RotationPeriod string            `json:"rotationPeriod,omitempty"`
+	Reason         string            `json:"reason"`
+}
+
+// StaleOptions holds the flags of the 'kds stale' command.
+type StaleOptions struct {
+	OlderThan     string
+	SortBy        string
+	Output        string
+	AllNamespaces bool
+}
+
+// ParseAge parses a duration that may also be given in days, e.g. "90d".
+func ParseAge(value string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid number of days '%s'", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// lastChanged returns when a secret was last changed, according to its managed
+// fields, or when it was created.
+func lastChanged(secret *corev1.Secret) time.Time {
+	last := secret.CreationTimestamp.Time
+	for _, entry := range secret.ManagedFields {
+		if entry.Time != nil && entry.Time.After(last) {
+			last = entry.Time.Time
+		}
+	}
+	return last
+}
+
+// FindStaleSecrets returns the secrets that have not changed for longer than the
+// threshold or than their own rotation period.
+func FindStaleSecrets(secrets []corev1.Secret, threshold time.Duration, now time.Time) ([]StaleSecret, error) {
+	var stale []StaleSecret
+	for i := range secrets {
+		secret := &secrets[i]
+		if secret.Type == corev1.SecretTypeServiceAccountToken || secret.Type == "helm.sh/release.v1" {
+			continue
+		}
+		changed := lastChanged(secret)
+		age := now.Sub(changed)
+		entry := StaleSecret{
+			Namespace:   secret.Namespace,
+			Name:        secret.Name,
+			Type:        secret.Type,
+			LastChanged: changed.UTC(),
+			AgeDays:     int(age.Hours() / 24),
+		}
+		if period, ok := secret.Annotations[RotationPeriodAnnotation]; ok {
+			limit, err := ParseAge(period)
+			if err != nil {
+				return nil, fmt.Errorf("secret '%s/%s' has an invalid %s annotation: %w", secret.Namespace, secret.Name, RotationPeriodAnnotation, err)
+			}
+			entry.RotationPeriod = period
+			if age > limit {
+				entry.Reason = "rotation period of " + period + " exceeded"
+				stale = append(stale, entry)
+			}
+			continue
+		}
+		if threshold > 0 && age > threshold {
+			entry.Reason = fmt.Sprintf("unchanged for %d days", entry.AgeDays)
+			stale = append(stale, entry)
+		}
+	}
+	return stale, nil
+}
+
+// SortStaleSecrets sorts the report by age (oldest first), name, or namespace.
+func SortStaleSecrets(stale []StaleSecret, by string) error {
+	var less func(a, b StaleSecret) bool
+	switch by {
+	case "age":
+		less = func(a, b StaleSecret) bool { return a.LastChanged.Before(b.LastChanged) }
+	case "name":
+		less = func(a, b StaleSecret) bool { return a.Name < b.Name }
+	case "namespace":
+		less = func(a, b StaleSecret) bool {
+			return a.Namespace < b.Namespace || (a.Namespace == b.Namespace && a.Name < b.Name)
+		}
+	default:
+		return fmt.Errorf("invalid --sort '%s': must be age, name, or namespace", by)
+	}
+	sort.SliceStable(stale, func(i, j int) bool { return less(stale[i], stale[j]) })
+	return nil
+}
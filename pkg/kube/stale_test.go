@@ -0,0 +1,19 @@
+package kube
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseAge verifies parsing of durations in days or Go syntax.
+func TestParseAge(t *testing.T) {
+	if d, err := ParseAge("90d"); err != nil || d != 90*24*time.Hour {
+		t.Errorf("Expected 90 days, but got %v (%v)", d, err)
+	}
+	if d, err := ParseAge("36h"); err != nil || d != 36*time.Hour {
+		t.Errorf("Expected 36h, but got %v (%v)", d, err)
+	}
+	if _, err := ParseAge("xd"); err == nil {
+		t.Error("Expected an error for 'xd', but got nil")
+	}
+}
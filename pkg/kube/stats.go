@@ -0,0 +1,74 @@
+package kube
+
+import (
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NamespaceStats summarizes the secrets of a namespace for 'kds stats'.
+type NamespaceStats struct {
+	Namespace string                    `json:"namespace"`
+	Secrets   int                       `json:"secrets"`
+	ByType    map[corev1.SecretType]int `json:"byType"`
+	TotalSize int                       `json:"totalSize"` // Bytes of keys and raw values.
+	Oldest    *DatedSecret              `json:"oldest,omitempty"`
+	Newest    *DatedSecret              `json:"newest,omitempty"`
+	// ExpiringCertificates counts the certificates of TLS secrets that expire
+	// within the expiry window, including those that already expired.
+	ExpiringCertificates int `json:"expiringCertificates"`
+	ExpiredCertificates  int `json:"expiredCertificates"`
+	// Orphans are the secrets that no workload, ingress, or service account
+	// references, and that nothing owns. Nil if the references are unknown.
+	Orphans []string `json:"orphans"`
+}
+
+// DatedSecret is a secret and when it was created.
+type DatedSecret struct {
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+}
+
+// BuildNamespaceStats summarizes the secrets of a namespace. Without a usage
+// index, orphans are not counted.
+func BuildNamespaceStats(namespace string, secrets []corev1.Secret, usages UsageIndex, expiryWindow time.Duration, now time.Time) NamespaceStats {
+	stats := NamespaceStats{Namespace: namespace, Secrets: len(secrets), ByType: make(map[corev1.SecretType]int)}
+	if usages != nil {
+		stats.Orphans = []string{}
+	}
+	for i := range secrets {
+		secret := &secrets[i]
+		stats.ByType[secret.Type]++
+		stats.TotalSize += SecretSize(secret)
+		dated := &DatedSecret{Name: secret.Name, Created: secret.CreationTimestamp.Time}
+		if stats.Oldest == nil || dated.Created.Before(stats.Oldest.Created) {
+			stats.Oldest = dated
+		}
+		if stats.Newest == nil || dated.Created.After(stats.Newest.Created) {
+			stats.Newest = dated
+		}
+		if usages != nil && isOrphan(secret, usages) {
+			stats.Orphans = append(stats.Orphans, secret.Name)
+		}
+	}
+	for _, expiry := range CertificateExpiries(secrets) {
+		if expiry.NotAfter.Before(now) {
+			stats.ExpiredCertificates++
+		}
+		if expiry.NotAfter.Before(now.Add(expiryWindow)) {
+			stats.ExpiringCertificates++
+		}
+	}
+	slices.Sort(stats.Orphans)
+	return stats
+}
+
+// isOrphan reports whether nothing references or owns a secret. Service account
+// tokens and Helm release secrets are managed by their owners, and never orphans.
+func isOrphan(secret *corev1.Secret, usages UsageIndex) bool {
+	if secret.Type == corev1.SecretTypeServiceAccountToken || secret.Type == "helm.sh/release.v1" {
+		return false
+	}
+	return len(secret.OwnerReferences) == 0 && len(usages[secret.Name]) == 0
+}
@@ -0,0 +1,52 @@
+package kube
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diskmanti/kds/internal/testfixtures"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestBuildNamespaceStats verifies the summary of the secrets of a namespace.
+func TestBuildNamespaceStats(t *testing.T) {
+	now := time.Now()
+	secret := func(name string, secretType corev1.SecretType, created time.Time, data map[string][]byte) corev1.Secret {
+		return corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(created)},
+			Type:       secretType,
+			Data:       data,
+		}
+	}
+	expiring := testfixtures.NewCert(t, "api.example.com", now.Add(-time.Hour), now.Add(10*24*time.Hour), nil)
+	valid := testfixtures.NewCert(t, "www.example.com", now.Add(-time.Hour), now.Add(90*24*time.Hour), nil)
+	secrets := []corev1.Secret{
+		secret("app-db", corev1.SecretTypeOpaque, now.Add(-48*time.Hour), map[string][]byte{"password": []byte("s3cr3t")}),
+		secret("legacy", corev1.SecretTypeOpaque, now.Add(-720*time.Hour), nil),
+		secret("api-tls", corev1.SecretTypeTLS, now.Add(-time.Hour), map[string][]byte{corev1.TLSCertKey: expiring.CertPEM}),
+		secret("www-tls", corev1.SecretTypeTLS, now.Add(-2*time.Hour), map[string][]byte{corev1.TLSCertKey: valid.CertPEM}),
+		secret("default-token", corev1.SecretTypeServiceAccountToken, now.Add(-24*time.Hour), nil),
+	}
+	usages := UsageIndex{"app-db": {{Kind: "Deployment", Name: "api"}}, "api-tls": {{Kind: "Ingress", Name: "api"}}, "www-tls": {{Kind: "Ingress", Name: "www"}}}
+
+	stats := BuildNamespaceStats("default", secrets, usages, 30*24*time.Hour, now)
+	if stats.Secrets != 5 || stats.ByType[corev1.SecretTypeOpaque] != 2 || stats.ByType[corev1.SecretTypeTLS] != 2 {
+		t.Errorf("Expected 5 secrets, 2 of them Opaque and 2 TLS, but got %+v", stats)
+	}
+	if stats.Oldest.Name != "legacy" || stats.Newest.Name != "api-tls" {
+		t.Errorf("Expected legacy to be the oldest and api-tls the newest, but got %s and %s", stats.Oldest.Name, stats.Newest.Name)
+	}
+	if stats.ExpiringCertificates != 1 || stats.ExpiredCertificates != 0 {
+		t.Errorf("Expected 1 expiring certificate, but got %d (%d expired)", stats.ExpiringCertificates, stats.ExpiredCertificates)
+	}
+	if len(stats.Orphans) != 1 || stats.Orphans[0] != "legacy" {
+		t.Errorf("Expected legacy to be the only orphan, but got %v", stats.Orphans)
+	}
+
+	t.Run("should not count orphans without usages", func(t *testing.T) {
+		if stats := BuildNamespaceStats("default", secrets, nil, 0, now); stats.Orphans != nil {
+			t.Errorf("Expected unknown orphans, but got %v", stats.Orphans)
+		}
+	})
+}
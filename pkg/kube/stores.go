@@ -0,0 +1,359 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultStoreTimeout bounds a command of a secret store that sets no timeout of
+// its own.
+const defaultStoreTimeout = 30 * time.Second
+
+// Kinds of secret stores.
+const (
+	StoreOnePassword = "1password" // Driven through the op CLI.
+	StoreBitwarden   = "bitwarden" // Driven through the bw CLI, also for Vaultwarden.
+	StoreDoppler     = "doppler"   // Read through the doppler CLI.
+	StoreInfisical   = "infisical" // Read through the infisical CLI.
+	StoreVault       = "vault"     // Read over the HTTP API of Vault.
+	StoreExec        = "exec"      // Driven through commands declared in the configuration.
+)
+
+// StoreConfig declares an external secret store, such as a password manager, that
+// secrets can be pushed to and pulled from through its CLI.
+type StoreConfig struct {
+	Name string `json:"name"`
+	// Kind is 1password, bitwarden, doppler, infisical, vault, or exec (the
+	// default) for a store reached by the push and pull commands.
+	Kind string `json:"kind,omitempty"`
+	// Vault is the vault of the items, for the stores that have vaults, their
+	// folder for Bitwarden, their project for Doppler and Infisical, or the API
+	// path they are under for Vault, e.g. secret/data.
+	Vault string `json:"vault,omitempty"`
+	// Push receives the data of a secret on stdin as a JSON object, and Pull prints
+	// the data of an item as a JSON object. Each argument is a Go template over
+	// .Item, .Namespace, and .Secret, which are also passed as KDS_* variables.
+	Push    []string         `json:"push,omitempty"`
+	Pull    []string         `json:"pull,omitempty"`
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// StoreItem is an item of a secret store, and the secret it is pushed from or
+// pulled into.
+type StoreItem struct {
+	Item      string
+	Namespace string
+	Secret    string
+}
+
+// SecretStore is a compiled StoreConfig.
+type SecretStore struct {
+	Name    string
+	kind    string
+	vault   string
+	push    []*template.Template
+	pull    []*template.Template
+	timeout time.Duration
+	run     commandRunner
+}
+
+// commandRunner runs a command with stdin and extra environment variables, and
+// returns its stdout. Errors carry the last line of its stderr.
+type commandRunner func(ctx context.Context, args []string, stdin []byte, env []string) ([]byte, error)
+
+// CompileStores validates the secret stores of the configuration. A store named
+// after a kind that is not declared, such as 1password, is that kind with its
+// defaults.
+func CompileStores(configs []StoreConfig) ([]SecretStore, error) {
+	stores := make([]SecretStore, 0, len(configs))
+	for _, c := range configs {
+		if c.Name == "" {
+			return nil, fmt.Errorf("store of kind '%s' needs a name", c.Kind)
+		}
+		s := SecretStore{Name: c.Name, kind: c.Kind, vault: c.Vault, timeout: defaultStoreTimeout, run: runCommand}
+		switch s.kind {
+		case "", StoreExec:
+			s.kind = StoreExec
+			if len(c.Push) == 0 && len(c.Pull) == 0 {
+				return nil, fmt.Errorf("store '%s' needs a push or a pull command", c.Name)
+			}
+			var err error
+			if s.push, err = compileCommand(c.Name, c.Push); err != nil {
+				return nil, err
+			}
+			if s.pull, err = compileCommand(c.Name, c.Pull); err != nil {
+				return nil, err
+			}
+		case StoreOnePassword, StoreBitwarden, StoreDoppler, StoreInfisical, StoreVault:
+			if len(c.Push) > 0 || len(c.Pull) > 0 {
+				return nil, fmt.Errorf("store '%s' of kind %s takes no commands", c.Name, c.Kind)
+			}
+		default:
+			return nil, fmt.Errorf("store '%s' has invalid kind '%s': use %s", c.Name, c.Kind, strings.Join(StoreKinds(), ", "))
+		}
+		if c.Timeout != nil {
+			s.timeout = c.Timeout.Duration
+		}
+		stores = append(stores, s)
+	}
+	return stores, nil
+}
+
+// StoreKinds returns the kinds of secret stores.
+func StoreKinds() []string {
+	return []string{StoreExec, StoreOnePassword, StoreBitwarden, StoreDoppler, StoreInfisical, StoreVault}
+}
+
+// FindStore returns the store with a name, or the store of the kind of that name
+// with its defaults.
+func FindStore(stores []SecretStore, name string) (SecretStore, error) {
+	for _, s := range stores {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	for _, kind := range StoreKinds() {
+		if kind == name && kind != StoreExec {
+			return SecretStore{Name: name, kind: kind, timeout: defaultStoreTimeout, run: runCommand}, nil
+		}
+	}
+	names := make([]string, 0, len(stores)+5)
+	for _, s := range stores {
+		names = append(names, s.Name)
+	}
+	names = append(names, StoreOnePassword, StoreBitwarden, StoreDoppler, StoreInfisical, StoreVault)
+	return SecretStore{}, fmt.Errorf("unknown store '%s': use one of %s", name, strings.Join(names, ", "))
+}
+
+// compileCommand parses the argument templates of a command of a store.
+func compileCommand(name string, args []string) ([]*template.Template, error) {
+	command := make([]*template.Template, 0, len(args))
+	for _, arg := range args {
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("store '%s' has an invalid command: %w", name, err)
+		}
+		command = append(command, tmpl)
+	}
+	return command, nil
+}
+
+// CanPush reports whether secrets can be pushed to the store. Doppler, Infisical
+// and Vault stores are only read from, as are exec stores without a push command.
+func (s SecretStore) CanPush() bool {
+	switch s.kind {
+	case StoreDoppler, StoreInfisical, StoreVault:
+		return false
+	case StoreExec:
+		return len(s.push) > 0
+	}
+	return true
+}
+
+// CanPull reports whether secrets can be pulled from the store, which all stores
+// but exec stores without a pull command can.
+func (s SecretStore) CanPull() bool {
+	return s.kind != StoreExec || len(s.pull) > 0
+}
+
+// Push writes the data of a secret to an item of the store, creating the item if
+// needed. Values are passed on stdin, never as arguments, which other users of the
+// machine could see.
+func (s SecretStore) Push(item StoreItem, data map[string][]byte) error {
+	if !s.CanPush() {
+		return fmt.Errorf("store '%s' can only be pulled from", s.Name)
+	}
+	for key, value := range data {
+		if !utf8.Valid(value) {
+			return fmt.Errorf("key '%s' holds binary data, which cannot be stored as text", key)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	var err error
+	switch s.kind {
+	case StoreOnePassword:
+		err = s.pushOnePassword(ctx, item, data)
+	case StoreBitwarden:
+		err = s.pushBitwarden(ctx, item, data)
+	default:
+		err = s.pushExec(ctx, item, data)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to push to item '%s' of %s: %w", item.Item, s.Name, err)
+	}
+	return nil
+}
+
+// Pull reads the data of an item of the store.
+func (s SecretStore) Pull(item StoreItem) (map[string][]byte, error) {
+	if !s.CanPull() {
+		return nil, fmt.Errorf("store '%s' can only be pushed to", s.Name)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	var data map[string][]byte
+	var err error
+	switch s.kind {
+	case StoreOnePassword:
+		data, err = s.pullOnePassword(ctx, item)
+	case StoreBitwarden:
+		data, err = s.pullBitwarden(ctx, item)
+	case StoreDoppler:
+		data, err = s.pullDoppler(ctx, item)
+	case StoreInfisical:
+		data, err = s.pullInfisical(ctx, item)
+	case StoreVault:
+		data, err = s.pullVault(item)
+	default:
+		data, err = s.pullExec(ctx, item)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull item '%s' of %s: %w", item.Item, s.Name, err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("item '%s' of %s has no values", item.Item, s.Name)
+	}
+	return data, nil
+}
+
+// pushExec runs the push command of an exec store with the data as a JSON object.
+func (s SecretStore) pushExec(ctx context.Context, item StoreItem, data map[string][]byte) error {
+	stdin, err := json.Marshal(stringData(data))
+	if err != nil {
+		return err
+	}
+	_, err = s.runTemplate(ctx, s.push, item, stdin)
+	return err
+}
+
+// pullExec runs the pull command of an exec store, which prints a JSON object.
+func (s SecretStore) pullExec(ctx context.Context, item StoreItem) (map[string][]byte, error) {
+	out, err := s.runTemplate(ctx, s.pull, item, nil)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]string
+	if err := json.Unmarshal(out, &values); err != nil {
+		return nil, fmt.Errorf("the pull command did not print a JSON object of strings: %w", err)
+	}
+	return byteData(values), nil
+}
+
+// runTemplate runs a command of the configuration on an item.
+func (s SecretStore) runTemplate(ctx context.Context, command []*template.Template, item StoreItem, stdin []byte) ([]byte, error) {
+	args := make([]string, 0, len(command))
+	for _, tmpl := range command {
+		var arg strings.Builder
+		if err := tmpl.Execute(&arg, item); err != nil {
+			return nil, err
+		}
+		args = append(args, arg.String())
+	}
+	env := []string{"KDS_ITEM=" + item.Item, "KDS_NAMESPACE=" + item.Namespace, "KDS_SECRET=" + item.Secret}
+	return s.run(ctx, args, stdin, env)
+}
+
+// runCommand runs a command of a store.
+func runCommand(ctx context.Context, args []string, stdin []byte, env []string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec // Stores are declared by the user.
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		if last := lines[len(lines)-1]; last != "" {
+			return nil, fmt.Errorf("%w: %s", err, last)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// stringData converts the data of a secret to strings.
+func stringData(data map[string][]byte) map[string]string {
+	values := make(map[string]string, len(data))
+	for key, value := range data {
+		values[key] = string(value)
+	}
+	return values
+}
+
+// byteData converts strings to the data of a secret.
+func byteData(values map[string]string) map[string][]byte {
+	data := make(map[string][]byte, len(values))
+	for key, value := range values {
+		data[key] = []byte(value)
+	}
+	return data
+}
+
+// PreparePull returns the creation of an Opaque secret with the data pulled from a
+// store, or the update of the existing secret. Keys of the item are added or
+// overwrite existing ones; with prune, keys that are not in the item are removed.
+func PreparePull(clientset Client, ref SecretRef, data map[string][]byte, prune bool) (Mutation, error) {
+	secret, err := GetSecret(clientset, ref)
+	if apierrors.IsNotFound(err) {
+		return Mutation{After: NewSecret(ref.Namespace, ref.Name, corev1.SecretTypeOpaque, data)}, nil
+	}
+	if err != nil {
+		return Mutation{}, err
+	}
+	if isImmutable(secret) {
+		return Mutation{}, ErrImmutable
+	}
+	merged := secret.DeepCopy()
+	if prune {
+		merged.Data = make(map[string][]byte, len(data))
+	} else if merged.Data == nil {
+		merged.Data = make(map[string][]byte, len(data))
+	}
+	maps.Copy(merged.Data, data)
+	return Mutation{Before: secret, After: merged}, nil
+}
+
+// otherAttributes returns the attributes of a JSON object but the known ones, so
+// that items of a store can be written back without losing what kds does not read.
+func otherAttributes(content []byte, known ...string) (map[string]json.RawMessage, error) {
+	var other map[string]json.RawMessage
+	if err := json.Unmarshal(content, &other); err != nil {
+		return nil, err
+	}
+	for _, key := range known {
+		delete(other, key)
+	}
+	return other, nil
+}
+
+// withOtherAttributes adds the attributes returned by otherAttributes back to a
+// JSON object.
+func withOtherAttributes(content []byte, other map[string]json.RawMessage) ([]byte, error) {
+	if len(other) == 0 {
+		return content, nil
+	}
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(content, &merged); err != nil {
+		return nil, err
+	}
+	for key, value := range other {
+		if _, set := merged[key]; !set {
+			merged[key] = value
+		}
+	}
+	return json.Marshal(merged)
+}
@@ -0,0 +1,367 @@
+package kube
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeRun is a command of a store run by a test.
+type fakeRun struct {
+	args  []string
+	stdin string
+	env   []string
+}
+
+// fakeRunner records the commands of a store and answers them with respond.
+func fakeRunner(runs *[]fakeRun, respond func(args []string) (string, error)) commandRunner {
+	return func(_ context.Context, args []string, stdin []byte, env []string) ([]byte, error) {
+		*runs = append(*runs, fakeRun{args: args, stdin: string(stdin), env: env})
+		out, err := respond(args)
+		return []byte(out), err
+	}
+}
+
+// TestCompileStores verifies that stores are validated, and that stores of a kind
+// can be used without being declared.
+func TestCompileStores(t *testing.T) {
+	for name, configs := range map[string][]StoreConfig{
+		"no name":          {{Push: []string{"push"}}},
+		"no command":       {{Name: "vault"}},
+		"invalid kind":     {{Name: "vault", Kind: "keepass"}},
+		"invalid template": {{Name: "vault", Pull: []string{"{{.Item"}}},
+		"op with commands": {{Name: "op", Kind: StoreOnePassword, Pull: []string{"op"}}},
+		"bw with commands": {{Name: "bw", Kind: StoreBitwarden, Push: []string{"bw"}}},
+	} {
+		t.Run("should reject a store with "+name, func(t *testing.T) {
+			if _, err := CompileStores(configs); err == nil {
+				t.Error("Expected an error, but got none")
+			}
+		})
+	}
+	t.Run("should find 1password without a declaration", func(t *testing.T) {
+		s, err := FindStore(nil, StoreOnePassword)
+		if err != nil || s.kind != StoreOnePassword {
+			t.Errorf("Expected the 1password store, but got %+v (error: %v)", s, err)
+		}
+		if _, err := FindStore(nil, "keepass"); err == nil {
+			t.Error("Expected an error for an unknown store")
+		}
+	})
+}
+
+// TestExecStore verifies that exec stores get the data on stdin and print it back
+// as JSON objects.
+func TestExecStore(t *testing.T) {
+	stores, err := CompileStores([]StoreConfig{{
+		Name: "team",
+		Push: []string{"team-vault", "put", "{{.Namespace}}/{{.Item}}"},
+		Pull: []string{"team-vault", "get", "{{.Item}}"},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var runs []fakeRun
+	store := stores[0]
+	store.run = fakeRunner(&runs, func([]string) (string, error) { return `{"password":"s3cr3t"}`, nil })
+	item := StoreItem{Item: "db", Namespace: "prod", Secret: "db"}
+
+	t.Run("should push the data as a JSON object", func(t *testing.T) {
+		if err := store.Push(item, map[string][]byte{"password": []byte("s3cr3t")}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		run := runs[len(runs)-1]
+		if strings.Join(run.args, " ") != "team-vault put prod/db" || run.stdin != `{"password":"s3cr3t"}` {
+			t.Errorf("Expected the data on stdin, but got %+v", run)
+		}
+		if !strings.Contains(strings.Join(run.env, " "), "KDS_ITEM=db") {
+			t.Errorf("Expected the item in the environment, but got %v", run.env)
+		}
+	})
+	t.Run("should refuse to push binary values", func(t *testing.T) {
+		if err := store.Push(item, map[string][]byte{"keystore": {0xff, 0xfe}}); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+	t.Run("should pull the data printed as a JSON object", func(t *testing.T) {
+		data, err := store.Pull(item)
+		if err != nil || string(data["password"]) != "s3cr3t" {
+			t.Errorf("Expected the password, but got %v (error: %v)", data, err)
+		}
+	})
+}
+
+// TestOnePasswordStore verifies that secrets are pushed to and pulled from the
+// fields of 1Password items through the op CLI.
+func TestOnePasswordStore(t *testing.T) {
+	stores, err := CompileStores([]StoreConfig{{Name: "op", Kind: StoreOnePassword, Vault: "Kubernetes"}})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	existing := `{"id":"abc","title":"db","category":"LOGIN","tags":["prod"],"fields":[` +
+		`{"id":"notesPlain","purpose":"NOTES","label":"notesPlain","value":"owned by payments"},` +
+		`{"id":"password","type":"CONCEALED","purpose":"PASSWORD","label":"password","value":"old"},` +
+		`{"id":"username","type":"STRING","purpose":"USERNAME","label":"username","value":"admin"}]}`
+	item := StoreItem{Item: "db", Namespace: "prod", Secret: "db"}
+	newStore := func(runs *[]fakeRun, found bool) SecretStore {
+		store := stores[0]
+		store.run = fakeRunner(runs, func(args []string) (string, error) {
+			if args[2] == "get" && !found {
+				return "", errors.New(`exit status 1: [ERROR] "db" isn't an item in the "Kubernetes" vault`)
+			}
+			if args[2] == "get" {
+				return existing, nil
+			}
+			return "", nil
+		})
+		return store
+	}
+
+	t.Run("should pull the labeled fields but the notes", func(t *testing.T) {
+		var runs []fakeRun
+		data, err := newStore(&runs, true).Pull(item)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if len(data) != 2 || string(data["password"]) != "old" || string(data["username"]) != "admin" {
+			t.Errorf("Expected password and username, but got %v", data)
+		}
+		if got := strings.Join(runs[0].args, " "); got != "op item get db --format json --vault Kubernetes" {
+			t.Errorf("Expected the item to be read from the vault, but got %s", got)
+		}
+	})
+	t.Run("should edit an existing item, keeping its other fields", func(t *testing.T) {
+		var runs []fakeRun
+		if err := newStore(&runs, true).Push(item, map[string][]byte{"password": []byte("new"), "host": []byte("db")}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		edit := runs[len(runs)-1]
+		if got := strings.Join(edit.args, " "); got != "op item edit db --vault Kubernetes" {
+			t.Errorf("Expected the item to be edited, but got %s", got)
+		}
+		var sent map[string]any
+		if err := json.Unmarshal([]byte(edit.stdin), &sent); err != nil {
+			t.Fatalf("Expected the item on stdin, but got %q", edit.stdin)
+		}
+		for _, want := range []string{`"value":"new"`, `"label":"host"`, `"value":"admin"`, `"tags":["prod"]`, `"category":"LOGIN"`} {
+			if !strings.Contains(edit.stdin, want) {
+				t.Errorf("Expected the item to contain %s, but got %s", want, edit.stdin)
+			}
+		}
+	})
+	t.Run("should create a missing item", func(t *testing.T) {
+		var runs []fakeRun
+		if err := newStore(&runs, false).Push(item, map[string][]byte{"password": []byte("new")}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		create := runs[len(runs)-1]
+		if got := strings.Join(create.args, " "); got != "op item create --vault Kubernetes" || !strings.Contains(create.stdin, `"title":"db"`) {
+			t.Errorf("Expected the item to be created, but got %s with %s", got, create.stdin)
+		}
+		for _, arg := range create.args {
+			if strings.Contains(arg, "new") {
+				t.Errorf("Expected the values not to be passed as arguments, but got %v", create.args)
+			}
+		}
+	})
+}
+
+// TestBitwardenStore verifies that secrets are pushed to and pulled from the custom
+// fields of Bitwarden items through the bw CLI.
+func TestBitwardenStore(t *testing.T) {
+	stores, err := CompileStores([]StoreConfig{{Name: "bw", Kind: StoreBitwarden, Vault: "Kubernetes"}})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	existing := `[{"id":"abc","type":1,"name":"db","folderId":"f1","login":{"username":"admin"},"fields":[` +
+		`{"name":"password","value":"old","type":1},` +
+		`{"name":"user","value":null,"type":3,"linkedId":100}]},` +
+		`{"id":"def","type":2,"name":"db-replica","folderId":"f1","fields":[]}]`
+	item := StoreItem{Item: "db", Namespace: "prod", Secret: "db"}
+	newStore := func(runs *[]fakeRun, items string) SecretStore {
+		store := stores[0]
+		store.run = fakeRunner(runs, func(args []string) (string, error) {
+			switch strings.Join(args[2:4], " ") {
+			case "list folders":
+				return `[{"id":"f1","name":"Kubernetes"}]`, nil
+			case "list items":
+				return items, nil
+			}
+			return "", nil
+		})
+		return store
+	}
+	decode := func(t *testing.T, run fakeRun) string {
+		content, err := base64.StdEncoding.DecodeString(run.stdin)
+		if err != nil {
+			t.Fatalf("Expected the item encoded on stdin, but got %q", run.stdin)
+		}
+		return string(content)
+	}
+
+	t.Run("should pull the custom fields of the item in the folder", func(t *testing.T) {
+		var runs []fakeRun
+		data, err := newStore(&runs, existing).Pull(item)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if len(data) != 1 || string(data["password"]) != "old" {
+			t.Errorf("Expected the password only, but got %v", data)
+		}
+		if got := strings.Join(runs[0].args, " "); got != "bw --nointeraction sync" {
+			t.Errorf("Expected the vault to be synced first, but got %s", got)
+		}
+		if got := strings.Join(runs[2].args, " "); got != "bw --nointeraction list items --search db --folderid f1" {
+			t.Errorf("Expected the items of the folder to be listed, but got %s", got)
+		}
+	})
+	t.Run("should edit an existing item, keeping its other fields", func(t *testing.T) {
+		var runs []fakeRun
+		if err := newStore(&runs, existing).Push(item, map[string][]byte{"password": []byte("new"), "host": []byte("db")}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		edit := runs[len(runs)-1]
+		if got := strings.Join(edit.args, " "); got != "bw --nointeraction edit item abc" {
+			t.Errorf("Expected the item to be edited, but got %s", got)
+		}
+		sent := decode(t, edit)
+		for _, want := range []string{`"value":"new"`, `{"name":"host","value":"db","type":1}`, `"linkedId":100`, `"login":{"username":"admin"}`} {
+			if !strings.Contains(sent, want) {
+				t.Errorf("Expected the item to contain %s, but got %s", want, sent)
+			}
+		}
+	})
+	t.Run("should create a missing item as a secure note in the folder", func(t *testing.T) {
+		var runs []fakeRun
+		if err := newStore(&runs, "[]").Push(item, map[string][]byte{"password": []byte("new")}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		create := runs[len(runs)-1]
+		sent := decode(t, create)
+		if got := strings.Join(create.args, " "); got != "bw --nointeraction create item" || !strings.Contains(sent, `"folderId":"f1"`) || !strings.Contains(sent, `"secureNote":{"type":0}`) {
+			t.Errorf("Expected the item to be created, but got %s with %s", got, sent)
+		}
+	})
+	t.Run("should tell how to unlock a locked vault", func(t *testing.T) {
+		store := stores[0]
+		store.run = func(context.Context, []string, []byte, []string) ([]byte, error) {
+			return nil, errors.New("exit status 1: Vault is locked.")
+		}
+		if _, err := store.Pull(item); err == nil || !strings.Contains(err.Error(), "BW_SESSION") {
+			t.Errorf("Expected a hint to unlock the vault, but got %v", err)
+		}
+	})
+}
+
+// TestReadOnlyStores verifies that secrets are read from Doppler configs and
+// Infisical folders through their CLIs, and from Vault over its API, and cannot
+// be pushed to them.
+func TestReadOnlyStores(t *testing.T) {
+	stores, err := CompileStores([]StoreConfig{
+		{Name: "doppler", Kind: StoreDoppler, Vault: "payments"},
+		{Name: "infisical", Kind: StoreInfisical, Vault: "6f1c"},
+		{Name: "kv", Kind: StoreVault, Vault: "secret/data"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	pull := func(s SecretStore, name, out string) (map[string][]byte, string, error) {
+		var runs []fakeRun
+		s.run = fakeRunner(&runs, func([]string) (string, error) { return out, nil })
+		data, err := s.Pull(StoreItem{Item: name, Namespace: "prod", Secret: "db"})
+		if len(runs) == 0 {
+			return data, "", err
+		}
+		return data, strings.Join(runs[0].args, " "), err
+	}
+
+	t.Run("should read a Doppler config without its metadata", func(t *testing.T) {
+		data, args, err := pull(stores[0], "prd", `{"DB_PASSWORD":"s3cr3t","DOPPLER_CONFIG":"prd","DOPPLER_PROJECT":"payments","DOPPLER_ENVIRONMENT":"prd"}`)
+		if err != nil || len(data) != 1 || string(data["DB_PASSWORD"]) != "s3cr3t" {
+			t.Errorf("Expected DB_PASSWORD only, but got %v (error: %v)", data, err)
+		}
+		if args != "doppler secrets download --no-file --format json --project payments --config prd" {
+			t.Errorf("Expected the config of the project of the store, but got %s", args)
+		}
+	})
+	t.Run("should read a Doppler config of another project", func(t *testing.T) {
+		_, args, _ := pull(stores[0], "billing/stg", `{"A":"b"}`)
+		if !strings.HasSuffix(args, "--project billing --config stg") {
+			t.Errorf("Expected the config of billing, but got %s", args)
+		}
+	})
+	t.Run("should reject a Doppler config without a project", func(t *testing.T) {
+		if _, _, err := pull(SecretStore{Name: "doppler", kind: StoreDoppler, timeout: time.Second}, "prd", `{}`); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+	t.Run("should read a folder of an Infisical environment", func(t *testing.T) {
+		data, args, err := pull(stores[1], "prod/app/db", `[{"key":"DB_PASSWORD","value":"s3cr3t","type":"shared"}]`)
+		if err != nil || string(data["DB_PASSWORD"]) != "s3cr3t" {
+			t.Errorf("Expected DB_PASSWORD, but got %v (error: %v)", data, err)
+		}
+		if args != "infisical export --format json --env prod --path /app/db --projectId 6f1c" {
+			t.Errorf("Expected the folder to be exported, but got %s", args)
+		}
+	})
+	t.Run("should read a secret under the path of a Vault store", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/secret/data/prod/db" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"data":{"DB_PASSWORD":"s3cr3t"},"metadata":{"version":1}}}`))
+		}))
+		defer server.Close()
+		t.Setenv("VAULT_ADDR", server.URL)
+		t.Setenv("VAULT_TOKEN", "s.token")
+		data, err := stores[2].Pull(StoreItem{Item: "prod/db", Namespace: "prod", Secret: "db"})
+		if err != nil || string(data["DB_PASSWORD"]) != "s3cr3t" {
+			t.Errorf("Expected DB_PASSWORD, but got %v (error: %v)", data, err)
+		}
+		if s, err := FindStore(nil, StoreVault); err != nil || s.CanPush() || !s.CanPull() {
+			t.Errorf("Expected a read-only vault store by default, but got %v", err)
+		}
+	})
+	t.Run("should refuse to push", func(t *testing.T) {
+		for _, s := range stores {
+			if s.CanPush() || s.Push(StoreItem{Item: "prd"}, map[string][]byte{"a": []byte("b")}) == nil {
+				t.Errorf("Expected %s to refuse pushes", s.Name)
+			}
+		}
+	})
+}
+
+// TestPreparePull verifies that pulled data creates a secret, or is merged into the
+// existing one.
+func TestPreparePull(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"},
+		Data:       map[string][]byte{"password": []byte("old"), "port": []byte("5432")},
+	})
+	data := map[string][]byte{"password": []byte("new")}
+	t.Run("should create a missing secret", func(t *testing.T) {
+		mu, err := PreparePull(clientset, SecretRef{Namespace: "prod", Name: "api"}, data, false)
+		if err != nil || mu.Before != nil || mu.After.Type != corev1.SecretTypeOpaque {
+			t.Errorf("Expected an Opaque secret to be created, but got %+v (error: %v)", mu, err)
+		}
+	})
+	t.Run("should merge into the existing secret, or replace it with prune", func(t *testing.T) {
+		for prune, keys := range map[bool]int{false: 2, true: 1} {
+			mu, err := PreparePull(clientset, SecretRef{Namespace: "prod", Name: "db"}, data, prune)
+			if err != nil || mu.Before == nil || len(mu.After.Data) != keys || string(mu.After.Data["password"]) != "new" {
+				t.Errorf("Expected %d keys with prune %t, but got %v (error: %v)", keys, prune, mu.After.Data, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package kube
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Thresholds of the strength indicator, in bits of entropy of the whole value.
+const (
+	weakStrengthBits   = 40
+	strongStrengthBits = 70
+)
+
+// ValueStrength rates a value for the strength indicator of the detail pane.
+type ValueStrength struct {
+	Label string
+	Bars  int // Out of 3.
+	Bits  float64
+}
+
+// StrengthOf rates a value by its total Shannon entropy. Known default passwords
+// and values equal to their key are rated as placeholders, whatever their entropy.
+func StrengthOf(key, value string) ValueStrength {
+	bits := shannonEntropy(value) * float64(len([]rune(value)))
+	lower := strings.ToLower(strings.TrimSpace(value))
+	for _, weak := range defaultWeakValues {
+		if lower == weak {
+			return ValueStrength{Label: "placeholder", Bits: bits}
+		}
+	}
+	switch {
+	case lower == strings.ToLower(key):
+		return ValueStrength{Label: "placeholder", Bits: bits}
+	case bits < weakStrengthBits:
+		return ValueStrength{Label: "weak", Bars: 1, Bits: bits}
+	case bits < strongStrengthBits:
+		return ValueStrength{Label: "fair", Bars: 2, Bits: bits}
+	}
+	return ValueStrength{Label: "strong", Bars: 3, Bits: bits}
+}
+
+// CompileSecretKeys compiles the --secret-keys pattern, falling back to the default
+// heuristic that also picks the keys checked by 'kds scan'.
+func CompileSecretKeys(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return PasswordKeyPattern, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --secret-keys pattern: %w", err)
+	}
+	return re, nil
+}
@@ -0,0 +1,24 @@
+package kube
+
+import (
+	"testing"
+)
+
+// TestStrengthOf verifies the rating of placeholder, weak, and strong values.
+func TestStrengthOf(t *testing.T) {
+	cases := []struct {
+		key, value, expected string
+	}{
+		{"password", "changeme", "placeholder"},
+		{"password", "Password", "placeholder"},
+		{"api-token", "api-token", "placeholder"},
+		{"password", "hunter22", "weak"},
+		{"password", "correct-horse-staple", "fair"},
+		{"token", "q8Zr1xW4kP9mT3vB7nY2cL6s", "strong"},
+	}
+	for _, c := range cases {
+		if got := StrengthOf(c.key, c.value); got.Label != c.expected {
+			t.Errorf("Expected '%s' for %q, but got '%s' (%.1f bits)", c.expected, c.value, got.Label, got.Bits)
+		}
+	}
+}
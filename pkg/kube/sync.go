@@ -0,0 +1,52 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SyncedFromAnnotation marks a replica created by 'kds sync' with the namespace/name
+// of its source. Only secrets carrying it are ever overwritten by a sync.
+const SyncedFromAnnotation = "kds.diskmanti.io/synced-from"
+
+// WatchRetryDelay is how long 'kds sync --watch' waits before re-establishing a failed watch.
+const WatchRetryDelay = 5 * time.Second
+
+// SyncSecret creates or updates the replica of source in the target namespace.
+// Existing secrets that were not created by a sync of the same source are left untouched.
+// A replica whose data or type cannot be updated in place, because it is immutable
+// like its source, is recreated.
+func SyncSecret(clientset Client, source *corev1.Secret, toNamespace string) error {
+	origin := SecretRef{Namespace: source.Namespace, Name: source.Name}.String()
+	replica := cloneSecret(source, toNamespace)
+	if replica.Annotations == nil {
+		replica.Annotations = make(map[string]string)
+	}
+	replica.Annotations[SyncedFromAnnotation] = origin
+
+	secrets := clientset.CoreV1().Secrets(toNamespace)
+	_, err := secrets.Create(context.TODO(), replica, metav1.CreateOptions{FieldManager: FieldManager})
+	if !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	existing, err := secrets.Get(context.TODO(), replica.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if existing.Annotations[SyncedFromAnnotation] != origin {
+		return fmt.Errorf("secret '%s' already exists in namespace '%s' and is not a replica of '%s'", replica.Name, toNamespace, origin)
+	}
+	if existing.Type != replica.Type || isImmutable(existing) && !maps.EqualFunc(existing.Data, replica.Data, bytes.Equal) {
+		return replaceSecret(clientset, existing, replica)
+	}
+	replica.ResourceVersion = existing.ResourceVersion
+	_, err = secrets.Update(context.TODO(), replica, metav1.UpdateOptions{FieldManager: FieldManager})
+	return err
+}
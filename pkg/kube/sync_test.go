@@ -0,0 +1,86 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestSyncSecret verifies creating and updating replicas, immutable ones included,
+// and protecting unrelated secrets.
+func TestSyncSecret(t *testing.T) {
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "wildcard-tls", Namespace: "certs"},
+		Data:       map[string][]byte{"tls.crt": []byte("v1")},
+	}
+	unrelated := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "wildcard-tls", Namespace: "other"}}
+	clientset := fake.NewSimpleClientset(source, unrelated)
+
+	t.Run("should create a marked replica", func(t *testing.T) {
+		if err := SyncSecret(clientset, source, "web"); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		replica, err := clientset.CoreV1().Secrets("web").Get(context.TODO(), "wildcard-tls", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected the replica to exist, but got: %v", err)
+		}
+		if replica.Annotations[SyncedFromAnnotation] != "certs/wildcard-tls" {
+			t.Errorf("Expected the replica to be marked with its source, but got %v", replica.Annotations)
+		}
+	})
+	t.Run("should update an existing replica", func(t *testing.T) {
+		updated := source.DeepCopy()
+		updated.Data["tls.crt"] = []byte("v2")
+		if err := SyncSecret(clientset, updated, "web"); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		replica, err := clientset.CoreV1().Secrets("web").Get(context.TODO(), "wildcard-tls", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if string(replica.Data["tls.crt"]) != "v2" {
+			t.Errorf("Expected the replica to be updated to 'v2', but got %q", replica.Data["tls.crt"])
+		}
+	})
+	t.Run("should recreate an immutable replica to update it", func(t *testing.T) {
+		immutable := true
+		source := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "certs"},
+			Data:       map[string][]byte{"ca.crt": []byte("v1")},
+			Immutable:  &immutable,
+		}
+		clientset := fake.NewSimpleClientset(source)
+		clientset.PrependReactor("update", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			updated := action.(k8stesting.UpdateAction).GetObject().(*corev1.Secret)
+			existing, err := clientset.Tracker().Get(corev1.SchemeGroupVersion.WithResource("secrets"), updated.Namespace, updated.Name)
+			if err == nil && isImmutable(existing.(*corev1.Secret)) && !bytes.Equal(existing.(*corev1.Secret).Data["ca.crt"], updated.Data["ca.crt"]) {
+				return true, nil, errors.New("field is immutable when `immutable` is set")
+			}
+			return false, nil, nil
+		})
+		if err := SyncSecret(clientset, source, "web"); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		rotated := source.DeepCopy()
+		rotated.Data["ca.crt"] = []byte("v2")
+		if err := SyncSecret(clientset, rotated, "web"); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		replica, err := clientset.CoreV1().Secrets("web").Get(context.TODO(), "ca", metav1.GetOptions{})
+		if err != nil || string(replica.Data["ca.crt"]) != "v2" || !isImmutable(replica) {
+			t.Errorf("Expected an immutable replica at 'v2', but got %v (%v)", replica, err)
+		}
+	})
+	t.Run("should not overwrite a secret that is not a replica", func(t *testing.T) {
+		if err := SyncSecret(clientset, source, "other"); err == nil {
+			t.Fatal("Expected an error for an unrelated existing secret, but got nil")
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package kube
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// expiryWarningPeriod is how close to its expiry a certificate triggers a warning.
+const expiryWarningPeriod = 30 * 24 * time.Hour
+
+// BuildTLSSecret validates a certificate and key pair read from files and builds
+// a kubernetes.io/tls secret from it. Warnings are returned even on failure.
+func BuildTLSSecret(namespace, name, certPath, keyPath string, now time.Time) (*corev1.Secret, []string, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings, err := validateTLSPair(certPEM, keyPEM, now)
+	if err != nil {
+		return nil, warnings, fmt.Errorf("invalid certificate and key: %w", err)
+	}
+	secret := NewSecret(namespace, name, corev1.SecretTypeTLS, map[string][]byte{
+		corev1.TLSCertKey:       certPEM,
+		corev1.TLSPrivateKeyKey: keyPEM,
+	})
+	return secret, warnings, nil
+}
+
+// createTLSSecret validates a certificate and key pair read from files and stores
+// it in a new kubernetes.io/tls secret. Warnings are returned even on failure.
+func createTLSSecret(clientset Client, namespace, name, certPath, keyPath string, now time.Time) ([]string, error) {
+	secret, warnings, err := BuildTLSSecret(namespace, name, certPath, keyPath, now)
+	if err != nil {
+		return warnings, err
+	}
+	return warnings, CreateSecret(clientset, secret)
+}
+
+// validateTLSPair checks that a private key matches the leaf certificate, that the
+// chain is ordered leaf first, and that no certificate is expired or not yet valid.
+// Certificates that expire soon are reported as warnings.
+func validateTLSPair(certPEM, keyPEM []byte, now time.Time) ([]string, error) {
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return nil, err
+	}
+	chain, err := ParseCertificates(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+	for i, cert := range chain {
+		if i+1 < len(chain) {
+			if err := cert.CheckSignatureFrom(chain[i+1]); err != nil {
+				return warnings, fmt.Errorf("certificate %d (%s) is not signed by certificate %d (%s); the chain must be ordered leaf first",
+					i+1, cert.Subject.CommonName, i+2, chain[i+1].Subject.CommonName)
+			}
+		}
+		switch {
+		case now.Before(cert.NotBefore):
+			return warnings, fmt.Errorf("certificate %d (%s) is not valid before %s", i+1, cert.Subject.CommonName, cert.NotBefore.Format(time.RFC3339))
+		case now.After(cert.NotAfter):
+			return warnings, fmt.Errorf("certificate %d (%s) expired on %s", i+1, cert.Subject.CommonName, cert.NotAfter.Format(time.RFC3339))
+		case cert.NotAfter.Sub(now) < expiryWarningPeriod:
+			warnings = append(warnings, fmt.Sprintf("certificate %d (%s) expires on %s", i+1, cert.Subject.CommonName, cert.NotAfter.Format(time.RFC3339)))
+		}
+	}
+	return warnings, nil
+}
+
+// ParseCertificates decodes every CERTIFICATE block of a PEM bundle, in order.
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate found")
+	}
+	return certs, nil
+}
@@ -0,0 +1,97 @@
+package kube
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diskmanti/kds/internal/testfixtures"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestValidateTLSPair verifies the key, chain order, and expiry checks.
+func TestValidateTLSPair(t *testing.T) {
+	now := time.Now()
+	ca := testfixtures.NewCert(t, "ca", now.Add(-time.Hour), now.Add(365*24*time.Hour), nil)
+	leaf := testfixtures.NewCert(t, "leaf", now.Add(-time.Hour), now.Add(90*24*time.Hour), &ca)
+
+	t.Run("should accept a matching key and an ordered chain", func(t *testing.T) {
+		chain := append(append([]byte{}, leaf.CertPEM...), ca.CertPEM...)
+		warnings, err := validateTLSPair(chain, leaf.KeyPEM, now)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if len(warnings) != 0 {
+			t.Errorf("Expected no warnings, but got %v", warnings)
+		}
+	})
+
+	t.Run("should reject a key that does not match the certificate", func(t *testing.T) {
+		if _, err := validateTLSPair(leaf.CertPEM, ca.KeyPEM, now); err == nil {
+			t.Error("Expected an error for a mismatched key, but got nil")
+		}
+	})
+
+	t.Run("should reject a chain that is not ordered leaf first", func(t *testing.T) {
+		other := testfixtures.NewCert(t, "other-ca", now.Add(-time.Hour), now.Add(365*24*time.Hour), nil)
+		chain := append(append([]byte{}, leaf.CertPEM...), other.CertPEM...)
+		_, err := validateTLSPair(chain, leaf.KeyPEM, now)
+		if err == nil || !strings.Contains(err.Error(), "ordered leaf first") {
+			t.Errorf("Expected a chain order error, but got: %v", err)
+		}
+	})
+
+	t.Run("should reject an expired certificate", func(t *testing.T) {
+		expired := testfixtures.NewCert(t, "expired", now.Add(-48*time.Hour), now.Add(-24*time.Hour), nil)
+		_, err := validateTLSPair(expired.CertPEM, expired.KeyPEM, now)
+		if err == nil || !strings.Contains(err.Error(), "expired") {
+			t.Errorf("Expected an expiry error, but got: %v", err)
+		}
+	})
+
+	t.Run("should warn about a certificate that expires soon", func(t *testing.T) {
+		soon := testfixtures.NewCert(t, "soon", now.Add(-time.Hour), now.Add(7*24*time.Hour), nil)
+		warnings, err := validateTLSPair(soon.CertPEM, soon.KeyPEM, now)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if len(warnings) != 1 {
+			t.Errorf("Expected 1 warning, but got %v", warnings)
+		}
+	})
+}
+
+// TestCreateTLSSecret verifies that a valid pair is stored as a kubernetes.io/tls secret.
+func TestCreateTLSSecret(t *testing.T) {
+	now := time.Now()
+	cert := testfixtures.NewCert(t, "example.com", now.Add(-time.Hour), now.Add(90*24*time.Hour), nil)
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certPath, cert.CertPEM, 0o600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyPath, cert.KeyPEM, 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	clientset := fake.NewSimpleClientset()
+
+	if _, err := createTLSSecret(clientset, "default", "example-tls", certPath, keyPath, now); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	secret, err := clientset.CoreV1().Secrets("default").Get(context.TODO(), "example-tls", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the secret to be created, but got: %v", err)
+	}
+	if secret.Type != corev1.SecretTypeTLS || string(secret.Data[corev1.TLSCertKey]) != string(cert.CertPEM) {
+		t.Errorf("Expected a TLS secret with the certificate, but got type %s", secret.Type)
+	}
+
+	if _, err := createTLSSecret(clientset, "default", "example-tls", certPath, keyPath, now); err == nil {
+		t.Error("Expected an error when the secret already exists, but got nil")
+	}
+}
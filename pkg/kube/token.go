@@ -0,0 +1,31 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MinTokenDuration is the shortest token lifetime the TokenRequest API accepts.
+const MinTokenDuration = 10 * time.Minute
+
+// RequestToken mints a token for a ServiceAccount through the TokenRequest API.
+func RequestToken(clientset Client, namespace, name string, audiences []string, lifetime time.Duration) (*authenticationv1.TokenRequestStatus, error) {
+	seconds := int64(lifetime / time.Second)
+	request := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{Audiences: audiences, ExpirationSeconds: &seconds},
+	}
+	response, err := clientset.CoreV1().ServiceAccounts(namespace).CreateToken(context.TODO(), name, request, metav1.CreateOptions{FieldManager: FieldManager})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request a token for ServiceAccount '%s/%s': %w", namespace, name, err)
+	}
+	if strings.TrimSpace(response.Status.Token) == "" {
+		return nil, errors.New("the API server returned an empty token")
+	}
+	return &response.Status, nil
+}
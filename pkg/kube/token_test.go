@@ -0,0 +1,48 @@
+package kube
+
+import (
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestRequestToken verifies that tokens are requested with the audiences and
+// duration given.
+func TestRequestToken(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	var requested *authenticationv1.TokenRequest
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		create, ok := action.(k8stesting.CreateAction)
+		if !ok || action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		if requested, ok = create.GetObject().(*authenticationv1.TokenRequest); !ok {
+			return false, nil, nil
+		}
+		response := requested.DeepCopy()
+		response.Status = authenticationv1.TokenRequestStatus{Token: "eyJhbGciOiJSUzI1NiJ9.e30.sig", ExpirationTimestamp: metav1.Now()}
+		return true, response, nil
+	})
+
+	status, err := RequestToken(clientset, "ci", "builder", []string{"vault"}, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if status.Token != "eyJhbGciOiJSUzI1NiJ9.e30.sig" {
+		t.Errorf("Expected the minted token, but got '%s'", status.Token)
+	}
+	if requested == nil || *requested.Spec.ExpirationSeconds != 600 || len(requested.Spec.Audiences) != 1 || requested.Spec.Audiences[0] != "vault" {
+		t.Errorf("Expected a request for 600s with audience vault, but got %+v", requested)
+	}
+
+	t.Run("should fail on an empty token", func(t *testing.T) {
+		if _, err := RequestToken(fake.NewSimpleClientset(), "ci", "builder", nil, time.Hour); err == nil {
+			t.Error("Expected an error, but got none")
+		}
+	})
+}
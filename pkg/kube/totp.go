@@ -0,0 +1,110 @@
+package kube
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // SHA-1 is the default and most common TOTP algorithm (RFC 6238).
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// otpKeyPattern matches keys that hint at a bare base32 TOTP seed. Without such a
+// hint, only otpauth:// URIs are recognized, as any base32 text could be a seed.
+var otpKeyPattern = regexp.MustCompile(`(?i)(totp|otp|2fa|mfa)`)
+
+// TOTPConfig holds the parameters of a TOTP generator (RFC 6238).
+type TOTPConfig struct {
+	secret    []byte
+	algorithm func() hash.Hash
+	digits    int
+	period    time.Duration
+}
+
+// ParseTOTP recognizes an otpauth://totp URI, or a base32 seed under a key that
+// hints at one-time passwords, and returns its generator.
+func ParseTOTP(key, value string) (TOTPConfig, bool) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "otpauth://") {
+		cfg, err := parseOTPAuthURI(value)
+		return cfg, err == nil
+	}
+	if !otpKeyPattern.MatchString(key) {
+		return TOTPConfig{}, false
+	}
+	secret, err := decodeBase32Seed(value)
+	if err != nil || len(secret) < 10 {
+		return TOTPConfig{}, false
+	}
+	return TOTPConfig{secret: secret, algorithm: sha1.New, digits: 6, period: 30 * time.Second}, true
+}
+
+// parseOTPAuthURI parses an otpauth://totp URI as used by authenticator apps.
+func parseOTPAuthURI(uri string) (TOTPConfig, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return TOTPConfig{}, err
+	}
+	if u.Host != "totp" {
+		return TOTPConfig{}, fmt.Errorf("unsupported OTP type '%s'", u.Host)
+	}
+	query := u.Query()
+	cfg := TOTPConfig{algorithm: sha1.New, digits: 6, period: 30 * time.Second}
+	if cfg.secret, err = decodeBase32Seed(query.Get("secret")); err != nil || len(cfg.secret) == 0 {
+		return TOTPConfig{}, fmt.Errorf("invalid secret: %w", err)
+	}
+	switch strings.ToUpper(query.Get("algorithm")) {
+	case "", "SHA1":
+	case "SHA256":
+		cfg.algorithm = sha256.New
+	case "SHA512":
+		cfg.algorithm = sha512.New
+	default:
+		return TOTPConfig{}, fmt.Errorf("unsupported algorithm '%s'", query.Get("algorithm"))
+	}
+	if digits := query.Get("digits"); digits != "" {
+		if cfg.digits, err = strconv.Atoi(digits); err != nil || cfg.digits < 6 || cfg.digits > 8 {
+			return TOTPConfig{}, fmt.Errorf("invalid digits '%s'", digits)
+		}
+	}
+	if period := query.Get("period"); period != "" {
+		seconds, err := strconv.Atoi(period)
+		if err != nil || seconds <= 0 {
+			return TOTPConfig{}, fmt.Errorf("invalid period '%s'", period)
+		}
+		cfg.period = time.Duration(seconds) * time.Second
+	}
+	return cfg, nil
+}
+
+// decodeBase32Seed decodes a base32 seed, ignoring case, spaces, and padding.
+func decodeBase32Seed(seed string) ([]byte, error) {
+	seed = strings.ToUpper(strings.ReplaceAll(seed, " ", ""))
+	return base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(seed, "="))
+}
+
+// Code computes the one-time password at the given time, and how long it stays valid.
+func (c TOTPConfig) Code(now time.Time) (string, time.Duration) {
+	seconds := int64(c.period / time.Second)
+	counter := now.Unix() / seconds
+	mac := hmac.New(c.algorithm, c.secret)
+	if err := binary.Write(mac, binary.BigEndian, counter); err != nil {
+		return "", 0
+	}
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	truncated := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulo := uint32(1)
+	for range c.digits {
+		modulo *= 10
+	}
+	remaining := time.Duration(seconds-now.Unix()%seconds) * time.Second
+	return fmt.Sprintf("%0*d", c.digits, truncated%modulo), remaining
+}
@@ -0,0 +1,61 @@
+package kube
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTOTPCode verifies code generation against the test vectors of RFC 6238.
+func TestTOTPCode(t *testing.T) {
+	// The base32 encodings of the ASCII seeds of RFC 6238, appendix B.
+	seeds := map[string]string{
+		"SHA1":   "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		"SHA256": "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZA",
+		"SHA512": "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNA",
+	}
+	cases := []struct {
+		algorithm string
+		unix      int64
+		expected  string
+	}{
+		{"SHA1", 59, "94287082"},
+		{"SHA256", 59, "46119246"},
+		{"SHA512", 59, "90693936"},
+		{"SHA1", 1111111109, "07081804"},
+		{"SHA1", 20000000000, "65353130"},
+	}
+	for _, c := range cases {
+		uri := "otpauth://totp/kds:test?secret=" + seeds[c.algorithm] + "&algorithm=" + c.algorithm + "&digits=8"
+		cfg, ok := ParseTOTP("uri", uri)
+		if !ok {
+			t.Fatalf("Expected %s to be recognized", uri)
+		}
+		code, remaining := cfg.Code(time.Unix(c.unix, 0))
+		if code != c.expected {
+			t.Errorf("Expected %s code %s at %d, but got %s", c.algorithm, c.expected, c.unix, code)
+		}
+		if remaining <= 0 || remaining > 30*time.Second {
+			t.Errorf("Expected a remaining validity within the period, but got %v", remaining)
+		}
+	}
+}
+
+// TestParseTOTP verifies which values are recognized as TOTP seeds.
+func TestParseTOTP(t *testing.T) {
+	cases := []struct {
+		key, value string
+		expected   bool
+	}{
+		{"uri", "otpauth://totp/GitHub:ops?secret=JBSWY3DPEHPK3PXP&issuer=GitHub", true},
+		{"totp-seed", "jbsw y3dp ehpk 3pxp", true},
+		{"password", "JBSWY3DPEHPK3PXP", false},
+		{"uri", "otpauth://hotp/GitHub:ops?secret=JBSWY3DPEHPK3PXP&counter=1", false},
+		{"uri", "otpauth://totp/x?secret=JBSWY3DPEHPK3PXP&digits=12", false},
+		{"mfa", "not base32!", false},
+	}
+	for _, c := range cases {
+		if _, ok := ParseTOTP(c.key, c.value); ok != c.expected {
+			t.Errorf("Expected %v for %s=%q, but got %v", c.expected, c.key, c.value, ok)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package kube
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultTrashRetention is how long deleted secrets stay in the trash.
+const DefaultTrashRetention = 7 * 24 * time.Hour
+
+// trashTimeLayout prefixes the files of the trash, so that they sort by deletion time.
+const trashTimeLayout = "20060102T150405Z"
+
+// trashSuffix ends the name of every file of the trash.
+const trashSuffix = ".secret.enc"
+
+// Trash keeps encrypted copies of deleted secrets on the local disk, so that they
+// can be restored within the retention period. The secrets of every context share
+// the directory, but a trash only sees those of its context.
+type Trash struct {
+	dir       string
+	key       []byte
+	context   string
+	retention time.Duration
+}
+
+// TrashEntry is a deleted secret kept in the trash.
+type TrashEntry struct {
+	Context   string
+	Ref       SecretRef
+	DeletedAt time.Time
+	path      string
+}
+
+// OpenTrash opens the trash of a context in dir, creating it if needed. Its files
+// are encrypted with the key of keyPath, as loadSealKey keeps it. Keep the key
+// file apart from the trash, so that a copy of the trash alone reveals nothing.
+func OpenTrash(dir, keyPath, context string, retention time.Duration) (*Trash, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	key, err := loadSealKey(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash key: %w", err)
+	}
+	return &Trash{dir: dir, key: key, context: context, retention: retention}, nil
+}
+
+// contextID identifies the context of the trash in the names of its files. It is
+// hashed, since context names may contain characters that file names cannot.
+func (t *Trash) contextID() string {
+	sum := sha256.Sum256([]byte(t.context))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Put encrypts a secret into the trash, before it is deleted.
+func (t *Trash) Put(secret *corev1.Secret, now time.Time) error {
+	ref := SecretRef{Namespace: secret.Namespace, Name: secret.Name}
+	plaintext, err := json.Marshal(cloneSecret(secret, secret.Namespace))
+	if err != nil {
+		return fmt.Errorf("failed to serialize secret '%s': %w", ref, err)
+	}
+	sealed, err := seal(t.key, plaintext)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s_%s_%s_%s%s", now.UTC().Format(trashTimeLayout), t.contextID(), ref.Namespace, ref.Name, trashSuffix)
+	if err := os.WriteFile(filepath.Join(t.dir, name), sealed, 0o600); err != nil {
+		return fmt.Errorf("failed to move secret '%s' to the trash: %w", ref, err)
+	}
+	return nil
+}
+
+// List returns the secrets of the context in the trash that were deleted within
+// the retention period, the most recent first.
+func (t *Trash) List(now time.Time) ([]TrashEntry, error) {
+	files, err := os.ReadDir(t.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+	var entries []TrashEntry
+	for _, file := range files {
+		entry, contextID, ok := parseTrashEntry(file.Name())
+		if !ok || contextID != t.contextID() || now.Sub(entry.DeletedAt) > t.retention {
+			continue
+		}
+		entry.Context = t.context
+		entry.path = filepath.Join(t.dir, file.Name())
+		entries = append(entries, entry)
+	}
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+// parseTrashEntry reads the deletion time, the ID of the context and the secret
+// from the name of a file of the trash. Names of namespaces and secrets cannot
+// contain underscores.
+func parseTrashEntry(name string) (entry TrashEntry, contextID string, ok bool) {
+	parts := strings.Split(strings.TrimSuffix(name, trashSuffix), "_")
+	if !strings.HasSuffix(name, trashSuffix) || len(parts) != 4 {
+		return TrashEntry{}, "", false
+	}
+	deletedAt, err := time.Parse(trashTimeLayout, parts[0])
+	if err != nil {
+		return TrashEntry{}, "", false
+	}
+	return TrashEntry{Ref: SecretRef{Namespace: parts[2], Name: parts[3]}, DeletedAt: deletedAt}, parts[1], true
+}
+
+// Find returns the most recently deleted version of a secret of the context.
+func (t *Trash) Find(ref SecretRef, now time.Time) (TrashEntry, error) {
+	entries, err := t.List(now)
+	if err != nil {
+		return TrashEntry{}, err
+	}
+	for _, entry := range entries {
+		if entry.Ref == ref {
+			return entry, nil
+		}
+	}
+	return TrashEntry{}, fmt.Errorf("secret '%s' of context '%s' is not in the trash, or was deleted more than %s ago", ref, t.context, t.retention)
+}
+
+// Read decrypts a secret of the trash.
+func (t *Trash) Read(entry TrashEntry) (*corev1.Secret, error) {
+	content, err := os.ReadFile(entry.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+	plaintext, err := unseal(t.key, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", entry.path, err)
+	}
+	var secret corev1.Secret
+	if err := json.Unmarshal(plaintext, &secret); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", entry.path, err)
+	}
+	return &secret, nil
+}
+
+// Remove deletes a secret from the trash.
+func (t *Trash) Remove(entry TrashEntry) error {
+	return os.Remove(entry.path)
+}
+
+// Purge deletes the secrets of every context that were deleted before the
+// retention period, and returns how many were purged.
+func (t *Trash) Purge(now time.Time) (int, error) {
+	files, err := os.ReadDir(t.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read trash: %w", err)
+	}
+	purged := 0
+	var errs []error
+	for _, file := range files {
+		entry, _, ok := parseTrashEntry(file.Name())
+		if !ok || now.Sub(entry.DeletedAt) <= t.retention {
+			continue
+		}
+		if err := os.Remove(filepath.Join(t.dir, file.Name())); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		purged++
+	}
+	return purged, errors.Join(errs...)
+}
+
+// TrashAndDeleteSecret moves a secret to the trash, then deletes it. The secret is
+// not deleted if it could not be moved to the trash, or if it changed since, so
+// that the trash never misses its last version. Expired secrets of the trash are
+// purged on the way.
+func TrashAndDeleteSecret(clientset Client, trash *Trash, ref SecretRef, now time.Time) error {
+	secret, err := GetSecret(clientset, ref)
+	if err != nil {
+		return err
+	}
+	if err := trash.Put(secret, now); err != nil {
+		return err
+	}
+	err = clientset.CoreV1().Secrets(ref.Namespace).Delete(context.TODO(), ref.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{ResourceVersion: &secret.ResourceVersion},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := trash.Purge(now); err != nil {
+		return fmt.Errorf("deleted, but failed to purge the trash: %w", err)
+	}
+	return nil
+}
+
+// RestoreMutation returns the creation of a secret of the trash, which fails if a
+// secret of the same name exists. The secret stays in the trash until removed.
+func (t *Trash) RestoreMutation(entry TrashEntry) (Mutation, error) {
+	secret, err := t.Read(entry)
+	if err != nil {
+		return Mutation{}, err
+	}
+	return Mutation{After: cloneSecret(secret, entry.Ref.Namespace)}, nil
+}
+
+// RestoreDeletedSecret creates a secret of the trash again, and removes it from
+// the trash. It fails if a secret of the same name exists.
+func RestoreDeletedSecret(clientset Client, trash *Trash, entry TrashEntry) error {
+	mu, err := trash.RestoreMutation(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := mu.Apply(clientset, false); err != nil {
+		return fmt.Errorf("failed to restore secret '%s': %w", entry.Ref, err)
+	}
+	return trash.Remove(entry)
+}
@@ -0,0 +1,140 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestTrash verifies that deleted secrets are kept encrypted in the trash, can be
+// restored within the retention period, and are purged after it.
+func TestTrash(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	ref := SecretRef{Namespace: "default", Name: "app-db"}
+	newClientset := func() *fake.Clientset {
+		return fake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-db", Namespace: "default", Labels: map[string]string{"team": "payments"}},
+			Data:       map[string][]byte{"password": []byte("s3cr3t")},
+		})
+	}
+	trash, err := OpenTrash(filepath.Join(dir, "trash"), filepath.Join(dir, "trash.key"), "prod", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	clientset := newClientset()
+	if err := TrashAndDeleteSecret(clientset, trash, ref, now); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, err := clientset.CoreV1().Secrets("default").Get(context.TODO(), "app-db", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("Expected the secret to be deleted, but got: %v", err)
+	}
+
+	t.Run("should encrypt the secret", func(t *testing.T) {
+		files, _ := filepath.Glob(filepath.Join(dir, "trash", "*"))
+		if len(files) != 1 {
+			t.Fatalf("Expected 1 file in the trash, but got %v", files)
+		}
+		content, _ := os.ReadFile(files[0])
+		if bytes.Contains(content, []byte("app-db")) || bytes.Contains(content, []byte("payments")) {
+			t.Error("Expected the file to be encrypted, but it contains the secret")
+		}
+	})
+
+	t.Run("should restore the secret within the retention period", func(t *testing.T) {
+		entry, err := trash.Find(ref, now.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if err := RestoreDeletedSecret(clientset, trash, entry); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		secret, err := clientset.CoreV1().Secrets("default").Get(context.TODO(), "app-db", metav1.GetOptions{})
+		if err != nil || string(secret.Data["password"]) != "s3cr3t" || secret.Labels["team"] != "payments" {
+			t.Errorf("Expected the secret to be restored, but got %v (%v)", secret, err)
+		}
+		if entries, _ := trash.List(now); len(entries) != 0 {
+			t.Errorf("Expected the trash to be empty, but got %v", entries)
+		}
+	})
+
+	t.Run("should only restore the secrets of its context", func(t *testing.T) {
+		if err := trash.Put(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}}, now); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		dev, err := OpenTrash(filepath.Join(dir, "trash"), filepath.Join(dir, "trash.key"), "dev", 24*time.Hour)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if entries, _ := dev.List(now); len(entries) != 0 {
+			t.Errorf("Expected no secrets deleted in dev, but got %v", entries)
+		}
+		if _, err := dev.Find(SecretRef{Namespace: "default", Name: "db"}, now); err == nil {
+			t.Error("Expected the secret deleted in prod not to be found in dev")
+		}
+		entry, err := trash.Find(SecretRef{Namespace: "default", Name: "db"}, now)
+		if err != nil || entry.Context != "prod" {
+			t.Errorf("Expected the secret to be found in prod, but got %+v (%v)", entry, err)
+		}
+		if err := trash.Remove(entry); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	})
+
+	t.Run("should purge secrets deleted before the retention period", func(t *testing.T) {
+		if err := trash.Put(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "old", Namespace: "default"}}, now.Add(-48*time.Hour)); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if _, err := trash.Find(SecretRef{Namespace: "default", Name: "old"}, now); err == nil {
+			t.Error("Expected expired secrets not to be found")
+		}
+		if purged, err := trash.Purge(now); purged != 1 || err != nil {
+			t.Errorf("Expected 1 secret to be purged, but got %d (%v)", purged, err)
+		}
+	})
+
+	t.Run("should not decrypt with another key", func(t *testing.T) {
+		if err := trash.Put(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}}, now); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		other, err := OpenTrash(filepath.Join(dir, "trash"), filepath.Join(dir, "other.key"), "prod", 24*time.Hour)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		entry, _ := other.Find(SecretRef{Namespace: "default", Name: "api"}, now)
+		if _, err := other.Read(entry); err == nil {
+			t.Error("Expected decryption to fail, but it succeeded")
+		}
+	})
+
+	t.Run("should only delete the version moved to the trash", func(t *testing.T) {
+		clientset := newClientset()
+		clientset.PrependReactor("delete", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewConflict(corev1.Resource("secrets"), "app-db", errors.New("the resource version changed"))
+		})
+		if err := TrashAndDeleteSecret(clientset, trash, ref, now); !apierrors.IsConflict(err) {
+			t.Errorf("Expected a conflict, but got: %v", err)
+		}
+		var preconditions *metav1.Preconditions
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "delete" {
+				preconditions = action.(k8stesting.DeleteAction).GetDeleteOptions().Preconditions
+			}
+		}
+		if preconditions == nil || preconditions.ResourceVersion == nil {
+			t.Errorf("Expected the deletion to be conditioned on the version read, but got %+v", preconditions)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package kube
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SecretURIScheme starts the URIs that address a secret, or a key of a secret, in
+// a kubeconfig context: secret://context/namespace/name[#key].
+const SecretURIScheme = "secret://"
+
+// SecretURI addresses a secret, or one of its keys, in a kubeconfig context, so
+// that runbooks and tickets can link to it.
+type SecretURI struct {
+	Context string // The kubeconfig context, or empty for the current one.
+	Ref     SecretRef
+	Key     string // The key addressed, if any.
+}
+
+// IsSecretURI reports whether an argument is a secret URI rather than a name.
+func IsSecretURI(s string) bool {
+	return strings.HasPrefix(s, SecretURIScheme)
+}
+
+// ParseSecretURI parses a secret://context/namespace/name[#key] URI. The context
+// is everything before the last two segments, since context names such as those
+// of EKS clusters contain slashes, and is empty in secret:///namespace/name.
+// Segments may be percent-encoded.
+func ParseSecretURI(s string) (SecretURI, error) {
+	rest, ok := strings.CutPrefix(s, SecretURIScheme)
+	if !ok {
+		return SecretURI{}, fmt.Errorf("invalid secret URI '%s': it must start with %s", s, SecretURIScheme)
+	}
+	rest, key, _ := strings.Cut(rest, "#")
+	segments := strings.Split(rest, "/")
+	if len(segments) < 3 {
+		return SecretURI{}, fmt.Errorf("invalid secret URI '%s': expected %scontext/namespace/name[#key]", s, SecretURIScheme)
+	}
+	parts := []string{strings.Join(segments[:len(segments)-2], "/"), segments[len(segments)-2], segments[len(segments)-1], key}
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil {
+			return SecretURI{}, fmt.Errorf("invalid secret URI '%s': %w", s, err)
+		}
+		parts[i] = unescaped
+	}
+	uri := SecretURI{Context: parts[0], Ref: SecretRef{Namespace: parts[1], Name: parts[2]}, Key: parts[3]}
+	if uri.Ref.Namespace == "" || uri.Ref.Name == "" {
+		return SecretURI{}, fmt.Errorf("invalid secret URI '%s': the namespace and the name are required", s)
+	}
+	return uri, nil
+}
+
+// String returns the URI, percent-encoding what would not parse back.
+func (u SecretURI) String() string {
+	s := SecretURIScheme + escapeContext(u.Context) + "/" + url.PathEscape(u.Ref.Namespace) + "/" + url.PathEscape(u.Ref.Name)
+	if u.Key != "" {
+		s += "#" + url.PathEscape(u.Key)
+	}
+	return s
+}
+
+// escapeContext percent-encodes a context name, keeping its slashes readable.
+func escapeContext(context string) string {
+	segments := strings.Split(context, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,42 @@
+package kube
+
+import "testing"
+
+// TestParseSecretURI verifies that secret URIs are parsed, with contexts holding
+// slashes, and printed back.
+func TestParseSecretURI(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want SecretURI
+	}{
+		{"secret://prod/payments/db", SecretURI{Context: "prod", Ref: SecretRef{Namespace: "payments", Name: "db"}}},
+		{"secret://prod/payments/db#password", SecretURI{Context: "prod", Ref: SecretRef{Namespace: "payments", Name: "db"}, Key: "password"}},
+		{"secret:///payments/db", SecretURI{Ref: SecretRef{Namespace: "payments", Name: "db"}}},
+		{
+			"secret://arn:aws:eks:eu-west-1:123456789012:cluster/prod/payments/db#tls.crt",
+			SecretURI{Context: "arn:aws:eks:eu-west-1:123456789012:cluster/prod", Ref: SecretRef{Namespace: "payments", Name: "db"}, Key: "tls.crt"},
+		},
+		{"secret://my%20cluster/payments/db#a%23b", SecretURI{Context: "my cluster", Ref: SecretRef{Namespace: "payments", Name: "db"}, Key: "a#b"}},
+	}
+	for _, tt := range tests {
+		t.Run("should parse "+tt.uri, func(t *testing.T) {
+			got, err := ParseSecretURI(tt.uri)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %+v, but got %+v", tt.want, got)
+			}
+			if got.String() != tt.uri {
+				t.Errorf("Expected %s to be printed back, but got %s", tt.uri, got.String())
+			}
+		})
+	}
+	for _, uri := range []string{"db", "secret://prod/db", "secret://prod//db", "secret://prod/payments/%zz"} {
+		t.Run("should reject "+uri, func(t *testing.T) {
+			if _, err := ParseSecretURI(uri); err == nil {
+				t.Errorf("Expected an error for %s, but got none", uri)
+			}
+		})
+	}
+}
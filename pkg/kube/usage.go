@@ -0,0 +1,163 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretUsage records one place where a secret is referenced.
+type SecretUsage struct {
+	Kind string // The kind of the referencing object, e.g. Deployment or Ingress.
+	Name string // The name of the referencing object.
+	via  string // How the secret is referenced, e.g. env, envFrom, volume, or tls.
+	key  string // The referenced key, or empty if the whole secret is used.
+	// optional is set when the pod starts even if the secret or key is missing.
+	optional bool
+}
+
+// String describes the usage, e.g. "Deployment/api (env: password)".
+func (u SecretUsage) String() string {
+	if u.key == "" {
+		return fmt.Sprintf("%s/%s (%s)", u.Kind, u.Name, u.via)
+	}
+	return fmt.Sprintf("%s/%s (%s: %s)", u.Kind, u.Name, u.via, u.key)
+}
+
+// UsageIndex maps secret names to the places in a namespace that reference them.
+type UsageIndex map[string][]SecretUsage
+
+// add records a usage of the named secret.
+func (idx UsageIndex) add(secretName string, usage SecretUsage) {
+	idx[secretName] = append(idx[secretName], usage)
+}
+
+// BuildUsageIndex scans the workloads, standalone pods, ingresses, and service
+// accounts of a namespace for references to secrets.
+func BuildUsageIndex(clientset Client, namespace string) (UsageIndex, error) {
+	idx := make(UsageIndex)
+	ctx := context.TODO()
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, d := range deployments.Items {
+		idx.addPodSpec("Deployment", d.Name, &d.Spec.Template.Spec)
+	}
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, s := range statefulSets.Items {
+		idx.addPodSpec("StatefulSet", s.Name, &s.Spec.Template.Spec)
+	}
+	daemonSets, err := clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for _, d := range daemonSets.Items {
+		idx.addPodSpec("DaemonSet", d.Name, &d.Spec.Template.Spec)
+	}
+	if err := idx.addStandaloneObjects(ctx, clientset, namespace); err != nil {
+		return nil, err
+	}
+	for name := range idx {
+		sort.SliceStable(idx[name], func(i, j int) bool { return idx[name][i].String() < idx[name][j].String() })
+	}
+	return idx, nil
+}
+
+// addStandaloneObjects indexes the pods without a controller, ingresses, and service accounts.
+func (idx UsageIndex) addStandaloneObjects(ctx context.Context, clientset Client, namespace string) error {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	for _, p := range pods.Items {
+		// Pods owned by a controller are already covered by their workload's template.
+		if metav1.GetControllerOf(&p) == nil {
+			idx.addPodSpec("Pod", p.Name, &p.Spec)
+		}
+	}
+	ingresses, err := clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	for _, ing := range ingresses.Items {
+		for _, tls := range ing.Spec.TLS {
+			if tls.SecretName != "" {
+				idx.add(tls.SecretName, SecretUsage{Kind: "Ingress", Name: ing.Name, via: "tls"})
+			}
+		}
+	}
+	serviceAccounts, err := clientset.CoreV1().ServiceAccounts(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list serviceaccounts: %w", err)
+	}
+	for _, sa := range serviceAccounts.Items {
+		for _, ref := range sa.ImagePullSecrets {
+			idx.add(ref.Name, SecretUsage{Kind: "ServiceAccount", Name: sa.Name, via: "imagePullSecrets"})
+		}
+	}
+	return nil
+}
+
+// addPodSpec indexes every secret reference of a pod spec: volumes, projected
+// volumes, env and envFrom of all containers, and image pull secrets.
+func (idx UsageIndex) addPodSpec(kind, name string, spec *corev1.PodSpec) {
+	for _, ref := range spec.ImagePullSecrets {
+		idx.add(ref.Name, SecretUsage{Kind: kind, Name: name, via: "imagePullSecrets"})
+	}
+	for i := range spec.Volumes {
+		idx.addVolume(kind, name, &spec.Volumes[i])
+	}
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		for _, env := range c.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				ref := env.ValueFrom.SecretKeyRef
+				idx.add(ref.Name, SecretUsage{Kind: kind, Name: name, via: "env", key: ref.Key, optional: isOptional(ref.Optional)})
+			}
+		}
+		for _, envFrom := range c.EnvFrom {
+			if envFrom.SecretRef != nil {
+				idx.add(envFrom.SecretRef.Name, SecretUsage{Kind: kind, Name: name, via: "envFrom", optional: isOptional(envFrom.SecretRef.Optional)})
+			}
+		}
+	}
+}
+
+// addVolume indexes the secret references of a single volume.
+func (idx UsageIndex) addVolume(kind, name string, volume *corev1.Volume) {
+	if volume.Secret != nil {
+		idx.addKeyItems(kind, name, "volume", volume.Secret.SecretName, volume.Secret.Items, isOptional(volume.Secret.Optional))
+	}
+	if volume.Projected == nil {
+		return
+	}
+	for _, source := range volume.Projected.Sources {
+		if source.Secret != nil {
+			idx.addKeyItems(kind, name, "projected volume", source.Secret.Name, source.Secret.Items, isOptional(source.Secret.Optional))
+		}
+	}
+}
+
+// addKeyItems indexes a volume reference, recording each key if only some are mounted.
+func (idx UsageIndex) addKeyItems(kind, name, via, secretName string, items []corev1.KeyToPath, optional bool) {
+	if len(items) == 0 {
+		idx.add(secretName, SecretUsage{Kind: kind, Name: name, via: via, optional: optional})
+		return
+	}
+	for _, item := range items {
+		idx.add(secretName, SecretUsage{Kind: kind, Name: name, via: via, key: item.Key, optional: optional})
+	}
+}
+
+// isOptional dereferences the optional field of a secret reference.
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
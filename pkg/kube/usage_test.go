@@ -0,0 +1,52 @@
+package kube
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestBuildUsageIndex verifies that secret references are found across object kinds.
+func TestBuildUsageIndex(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "api",
+				Env: []corev1.EnvVar{{Name: "DB_PASSWORD", ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"},
+				}}},
+				EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "api-env"}}}},
+			}},
+			Volumes: []corev1.Volume{{Name: "certs", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "api-tls"}}}},
+		}}},
+	}
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       networkingv1.IngressSpec{TLS: []networkingv1.IngressTLS{{SecretName: "api-tls"}}},
+	}
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta:       metav1.ObjectMeta{Name: "builder", Namespace: "default"},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+	}
+	clientset := fake.NewSimpleClientset(deployment, ingress, serviceAccount)
+
+	idx, err := BuildUsageIndex(clientset, "default")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := UsageIndex{
+		"db":       {{Kind: "Deployment", Name: "api", via: "env", key: "password"}},
+		"api-env":  {{Kind: "Deployment", Name: "api", via: "envFrom"}},
+		"api-tls":  {{Kind: "Deployment", Name: "api", via: "volume"}, {Kind: "Ingress", Name: "web", via: "tls"}},
+		"registry": {{Kind: "ServiceAccount", Name: "builder", via: "imagePullSecrets"}},
+	}
+	if !reflect.DeepEqual(idx, expected) {
+		t.Errorf("Expected usage index %v, but got %v", expected, idx)
+	}
+}
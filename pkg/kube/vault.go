@@ -0,0 +1,178 @@
+package kube
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// vaultTimeout bounds every request to Vault.
+const vaultTimeout = 30 * time.Second
+
+// VaultClient reads secrets from the KV engines of a Vault server over its HTTP
+// API, with a token obtained by the Vault CLI or from the environment.
+type VaultClient struct {
+	addr      string
+	token     string
+	namespace string
+	http      *http.Client
+}
+
+// NewVaultClientFromEnv configures a client the way the Vault CLI does: the
+// server from VAULT_ADDR, the token from VAULT_TOKEN or the ~/.vault-token file
+// written by 'vault login', the Enterprise namespace from VAULT_NAMESPACE, and
+// the verification of the server's certificate from VAULT_CACERT, VAULT_CAPATH
+// and VAULT_SKIP_VERIFY.
+func NewVaultClientFromEnv() (*VaultClient, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return nil, errors.New("VAULT_ADDR is not set")
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the Vault token: %w", err)
+		}
+		content, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+		if err != nil {
+			return nil, errors.New("no Vault token: set VAULT_TOKEN or run 'vault login'")
+		}
+		token = strings.TrimSpace(string(content))
+	}
+	tlsConfig, err := vaultTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return NewVaultClient(addr, token, os.Getenv("VAULT_NAMESPACE"), &http.Client{Timeout: vaultTimeout, Transport: transport}), nil
+}
+
+// vaultTLSConfig trusts the CA certificates of the VAULT_CACERT file, or else of
+// the files of the VAULT_CAPATH directory, instead of the system's, and skips the
+// verification of the server's certificate if VAULT_SKIP_VERIFY is true.
+func vaultTLSConfig() (*tls.Config, error) {
+	config := &tls.Config{}
+	if skip := os.Getenv("VAULT_SKIP_VERIFY"); skip != "" {
+		insecure, err := strconv.ParseBool(skip)
+		if err != nil {
+			return nil, fmt.Errorf("invalid VAULT_SKIP_VERIFY '%s': %w", skip, err)
+		}
+		config.InsecureSkipVerify = insecure
+	}
+	var files []string
+	if cacert := os.Getenv("VAULT_CACERT"); cacert != "" {
+		files = []string{cacert}
+	} else if capath := os.Getenv("VAULT_CAPATH"); capath != "" {
+		entries, err := os.ReadDir(capath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read VAULT_CAPATH: %w", err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(capath, entry.Name()))
+			}
+		}
+	}
+	if len(files) == 0 {
+		return config, nil
+	}
+	config.RootCAs = x509.NewCertPool()
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the Vault CA certificate: %w", err)
+		}
+		if !config.RootCAs.AppendCertsFromPEM(content) {
+			return nil, fmt.Errorf("no PEM certificate found in '%s'", file)
+		}
+	}
+	return config, nil
+}
+
+// NewVaultClient returns a client for the Vault server at addr.
+func NewVaultClient(addr, token, namespace string, client *http.Client) *VaultClient {
+	return &VaultClient{addr: strings.TrimSuffix(addr, "/"), token: token, namespace: namespace, http: client}
+}
+
+// pullVault reads a secret of a Vault KV engine reached from the environment. The
+// item is the API path of the secret, or its path under the vault of the store.
+func (s SecretStore) pullVault(item StoreItem) (map[string][]byte, error) {
+	client, err := NewVaultClientFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	kvPath := item.Item
+	if s.vault != "" {
+		kvPath = path.Join(s.vault, kvPath)
+	}
+	return client.ReadKV(kvPath)
+}
+
+// ReadKV reads the keys and values of a secret of a KV engine, given its API path
+// such as secret/data/app/prod for version 2 engines, or secret/app/prod for
+// version 1 engines. Values that are not strings are returned as JSON.
+func (c *VaultClient) ReadKV(path string) (map[string][]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), vaultTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.addr+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vault path '%s': %w", path, err)
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+	if c.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vault path '%s': %w", path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vault path '%s': %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(body, &failure)
+		return nil, fmt.Errorf("failed to read Vault path '%s': %s %s", path, resp.Status, strings.Join(failure.Errors, "; "))
+	}
+	var secret struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("failed to parse Vault path '%s': %w", path, err)
+	}
+	values := secret.Data
+	// Version 2 engines nest the values under data, next to their metadata.
+	if nested, ok := values["data"]; ok && len(values) == 2 && values["metadata"] != nil {
+		values = nil
+		if err := json.Unmarshal(nested, &values); err != nil {
+			return nil, fmt.Errorf("failed to parse Vault path '%s': %w", path, err)
+		}
+	}
+	data := make(map[string][]byte, len(values))
+	for key, raw := range values {
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			data[key] = []byte(s)
+		} else {
+			data[key] = []byte(raw)
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,111 @@
+package kube
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestVaultReadKV verifies that the values of KV version 1 and 2 secrets are read.
+func TestVaultReadKV(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" || r.Header.Get("X-Vault-Namespace") != "team" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/app/prod":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"hunter2","port":5432},"metadata":{"version":3}}}`))
+		case "/v1/kv/app":
+			_, _ = w.Write([]byte(`{"data":{"password":"hunter2"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+	client := NewVaultClient(server.URL+"/", "s.token", "team", server.Client())
+
+	t.Run("should read version 2 secrets", func(t *testing.T) {
+		data, err := client.ReadKV("secret/data/app/prod")
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		expected := map[string][]byte{"password": []byte("hunter2"), "port": []byte("5432")}
+		if !reflect.DeepEqual(data, expected) {
+			t.Errorf("Expected %v, but got %v", expected, data)
+		}
+	})
+	t.Run("should read version 1 secrets", func(t *testing.T) {
+		data, err := client.ReadKV("/kv/app")
+		if err != nil || !reflect.DeepEqual(data, map[string][]byte{"password": []byte("hunter2")}) {
+			t.Errorf("Expected the password, but got %v, %v", data, err)
+		}
+	})
+	t.Run("should fail on missing paths and bad tokens", func(t *testing.T) {
+		if _, err := client.ReadKV("secret/data/missing"); err == nil {
+			t.Error("Expected an error for a missing path, but got none")
+		}
+		if _, err := NewVaultClient(server.URL, "bad", "team", server.Client()).ReadKV("kv/app"); err == nil {
+			t.Error("Expected an error for a bad token, but got none")
+		}
+	})
+}
+
+// TestNewVaultClientFromEnv verifies that the server's certificate is verified
+// against the CA certificates given in the environment, as the Vault CLI does.
+func TestNewVaultClientFromEnv(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"password":"hunter2"}}`))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	cacert := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(cacert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	read := func(t *testing.T, env map[string]string) error {
+		t.Setenv("VAULT_ADDR", server.URL)
+		t.Setenv("VAULT_TOKEN", "s.token")
+		for _, name := range []string{"VAULT_CACERT", "VAULT_CAPATH", "VAULT_SKIP_VERIFY"} {
+			t.Setenv(name, env[name])
+		}
+		client, err := NewVaultClientFromEnv()
+		if err != nil {
+			return err
+		}
+		_, err = client.ReadKV("kv/app")
+		return err
+	}
+
+	t.Run("should not trust an unknown CA", func(t *testing.T) {
+		if err := read(t, nil); err == nil {
+			t.Error("Expected an error for an unknown CA, but got none")
+		}
+	})
+	t.Run("should trust the CA of VAULT_CACERT", func(t *testing.T) {
+		if err := read(t, map[string]string{"VAULT_CACERT": cacert}); err != nil {
+			t.Errorf("Expected no error, but got %v", err)
+		}
+	})
+	t.Run("should trust the CAs of VAULT_CAPATH", func(t *testing.T) {
+		if err := read(t, map[string]string{"VAULT_CAPATH": dir}); err != nil {
+			t.Errorf("Expected no error, but got %v", err)
+		}
+	})
+	t.Run("should skip the verification with VAULT_SKIP_VERIFY", func(t *testing.T) {
+		if err := read(t, map[string]string{"VAULT_SKIP_VERIFY": "true"}); err != nil {
+			t.Errorf("Expected no error, but got %v", err)
+		}
+	})
+	t.Run("should reject an invalid VAULT_SKIP_VERIFY", func(t *testing.T) {
+		if err := read(t, map[string]string{"VAULT_SKIP_VERIFY": "maybe"}); err == nil {
+			t.Error("Expected an error for an invalid VAULT_SKIP_VERIFY, but got none")
+		}
+	})
+}
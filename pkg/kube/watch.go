@@ -0,0 +1,84 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// watchRetryDelay is how long to wait before watching again after the watch
+// failed.
+const watchRetryDelay = 5 * time.Second
+
+// WatchSecrets calls handle with the secrets of a namespace, or of every
+// namespace if it is empty, and then with each change to them, until ctx is done.
+// The secrets are listed as HistoryObserved, and their changes are
+// HistoryCreated, HistoryChanged, or HistoryDeleted. When the watch ends, the
+// secrets are listed and watched again, as they are after handle fails, so
+// changes made in between are seen as observed. WatchSecrets only returns early
+// if access to secrets is denied.
+func WatchSecrets(ctx context.Context, clientset Client, namespace string, handle func(secret *corev1.Secret, event string) error) error {
+	for {
+		err := watchSecretsOnce(ctx, clientset, namespace, handle)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watchRetryDelay):
+		}
+	}
+}
+
+// watchSecretsOnce lists the current secrets, then watches their changes until
+// the watch ends.
+func watchSecretsOnce(ctx context.Context, clientset Client, namespace string, handle func(*corev1.Secret, string) error) error {
+	secrets := clientset.CoreV1().Secrets(namespace)
+	list, err := secrets.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+	for i := range list.Items {
+		if err := handle(&list.Items[i], HistoryObserved); err != nil {
+			return err
+		}
+	}
+	watcher, err := secrets.Watch(ctx, metav1.ListOptions{ResourceVersion: list.ResourceVersion})
+	if err != nil {
+		return fmt.Errorf("failed to watch secrets: %w", err)
+	}
+	defer watcher.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+			if event.Type == watch.Error {
+				return apierrors.FromObject(event.Object)
+			}
+			secret, isSecret := event.Object.(*corev1.Secret)
+			if !isSecret {
+				continue
+			}
+			kind := map[watch.EventType]string{watch.Added: HistoryCreated, watch.Modified: HistoryChanged, watch.Deleted: HistoryDeleted}[event.Type]
+			if kind == "" {
+				continue
+			}
+			if err := handle(secret, kind); err != nil {
+				return err
+			}
+		}
+	}
+}
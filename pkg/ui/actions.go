@@ -0,0 +1,330 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// actionDoneMsg is sent when an action on one or more secrets has finished.
+type actionDoneMsg struct {
+	status  string         // A short, human-readable summary of what happened.
+	err     error          // Non-nil if the action failed for at least one secret.
+	refresh bool           // True if the action changed the secret list, which must be reloaded.
+	undo    *kube.Snapshot // The secrets before the action, if it can be undone.
+}
+
+// --- ACTION COMMANDS ---
+
+// runOnSecrets applies an operation to every referenced secret and summarizes the outcome.
+func runOnSecrets(refs []kube.SecretRef, verb string, op func(kube.SecretRef) error) actionDoneMsg {
+	var errs []error
+	for _, ref := range refs {
+		if err := op(ref); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ref, err))
+		}
+	}
+	if len(errs) > 0 {
+		return actionDoneMsg{status: fmt.Sprintf("%s failed for %d of %d secret(s)", verb, len(errs), len(refs)), err: errors.Join(errs...), refresh: true}
+	}
+	return actionDoneMsg{status: fmt.Sprintf("%s %d secret(s)", verb, len(refs)), refresh: true}
+}
+
+// deleteSecretsCmd deletes the referenced secrets, moving them to the trash first
+// unless it is nil.
+func deleteSecretsCmd(clientset kube.Client, trash *kube.Trash, refs []kube.SecretRef) tea.Cmd {
+	return undoable(clientset, refs, func() tea.Msg {
+		return runOnSecrets(refs, "Deleted", func(ref kube.SecretRef) error {
+			if trash == nil {
+				return kube.DeleteSecret(clientset, ref)
+			}
+			return kube.TrashAndDeleteSecret(clientset, trash, ref, time.Now())
+		})
+	})
+}
+
+// exportSecretsCmd writes the referenced secrets to a JSON file.
+func exportSecretsCmd(clientset kube.Client, refs []kube.SecretRef, path string) tea.Cmd {
+	return func() tea.Msg {
+		if err := kube.ExportSecrets(clientset, refs, path); err != nil {
+			return actionDoneMsg{status: "Export failed", err: err}
+		}
+		return actionDoneMsg{status: fmt.Sprintf("Exported %d secret(s) to %s", len(refs), path)}
+	}
+}
+
+// --- TUI WIRING ---
+
+// selectionDelegate wraps the default list delegate to mark multi-selected items
+// and changed ones, and to show the type of secrets with icons and colors when
+// they are enabled.
+type selectionDelegate struct {
+	list.DefaultDelegate
+	selected   map[string]bool
+	changed    map[string]bool // Secrets changed since they were last viewed.
+	icons      bool
+	typeColors bool
+}
+
+// markedItem is an item rendered with markers, such as the selection marker or
+// the icon of its type, in front of its title.
+type markedItem struct {
+	kube.Item
+	marker string
+}
+
+// Title returns the item's name prefixed with its markers.
+func (i markedItem) Title() string { return i.marker + i.Item.Title() }
+
+// Render draws an item after its quick-jump number, adding the selection marker if
+// it is part of the selection, and the changed marker if it changed since it was
+// last viewed.
+func (d selectionDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	if it, ok := listItem.(kube.Item); ok {
+		marker := quickJumpMarker(m, index)
+		if d.selected[it.Ref().String()] {
+			marker = "● "
+		}
+		if d.changed[it.Ref().String()] {
+			marker += changedMarker
+		}
+		if d.icons {
+			marker += typeIcon(it.SecretType) + " "
+		}
+		if color, ok := typeColors[it.SecretType]; ok && d.typeColors {
+			d.Styles.NormalTitle = d.Styles.NormalTitle.Foreground(color)
+		}
+		listItem = markedItem{it, marker}
+	}
+	d.DefaultDelegate.Render(w, m, index, listItem)
+}
+
+// actionTargets returns the secrets an action applies to: the multi-selection if
+// there is one, otherwise the highlighted secret.
+func (m Model) actionTargets() []kube.SecretRef {
+	var refs []kube.SecretRef
+	for _, it := range m.allItems {
+		if m.selected[it.Ref().String()] {
+			refs = append(refs, it.Ref())
+		}
+	}
+	if len(refs) == 0 && m.highlightedItem.Name != "" {
+		refs = append(refs, m.highlightedItem.Ref())
+	}
+	return refs
+}
+
+// handleActionKey handles the keys that trigger selection and bulk actions. It
+// reports whether the key was consumed.
+func (m Model) handleActionKey(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	if m, handled := m.handleKeysKey(msg); handled {
+		return m, nil, true
+	}
+	if m, cmd, handled := m.handleNamespaceKey(msg); handled {
+		return m, cmd, true
+	}
+	if m, cmd, handled := m.handleQuickJumpKey(msg); handled {
+		return m, cmd, true
+	}
+	if m.focus == leftPane {
+		if msg.String() != " " {
+			return m, nil, false
+		}
+		if selected, ok := m.list.SelectedItem().(kube.Item); ok {
+			key := selected.Ref().String()
+			if m.selected[key] {
+				delete(m.selected, key)
+			} else {
+				m.selected[key] = true
+			}
+		}
+		return m, nil, true
+	}
+
+	if m, handled := m.refuseLocalAction(msg); handled {
+		return m, nil, true
+	}
+	if m, cmd, handled := m.handleChordKey(msg); handled {
+		return m, cmd, true
+	}
+	if msg.String() == "c" {
+		if m.display == displayChecksums {
+			m.display = displayPlain
+		} else {
+			m.display = displayChecksums
+		}
+		return m, nil, true
+	}
+	if m, cmd, handled := m.handlePaletteKey(msg); handled {
+		return m, cmd, true
+	}
+	if msg.String() == "o" {
+		m, cmd := m.toggleTOTP()
+		return m, cmd, true
+	}
+	if msg.String() == "v" {
+		if m.locked() {
+			m.prompt = m.confirmReveal()
+			return m, nil, true
+		}
+		if keys := m.keysToConfirm(); len(keys) > 0 {
+			m.prompt = m.confirmRedactedReveal(keys)
+			return m, nil, true
+		}
+		m.reveal = !m.reveal
+		m.refreshSecretData()
+		return m, nil, true
+	}
+	if msg.String() == "n" {
+		m.prompt = m.newSecretWizard()
+		return m, nil, true
+	}
+	if msg.String() == "u" {
+		return m.confirmUndo(), nil, true
+	}
+
+	refs := m.actionTargets()
+	if len(refs) == 0 {
+		return m, nil, false
+	}
+	count := fmt.Sprintf("%d secret(s)", len(refs))
+	switch msg.String() {
+	case "x":
+		m.prompt = m.guardProtected(refs, "Export", newInputPrompt("Export "+count+" to:", kube.DefaultExportPath, func(path string) tea.Cmd {
+			return exportSecretsCmd(m.clientset, refs, path)
+		}))
+	case "w":
+		m.prompt = m.guardProtected(refs, "Write", newInputPrompt("Write keys of "+count+" as files into:", refs[0].Name, func(dir string) tea.Cmd {
+			return writeSecretFilesCmd(m.clientset, refs, dir)
+		}))
+	case "d":
+		m.prompt = newConfirmPrompt("Delete "+count+"?", func() tea.Cmd {
+			return deleteSecretsCmd(m.clientset, m.trash, refs)
+		})
+	case "l":
+		m.prompt = newInputPrompt("Labels for "+count+" (key=value, key- to remove):", "", func(input string) tea.Cmd {
+			return patchMetadataCmd(m.clientset, refs, kube.FieldLabels, input)
+		})
+	case "a":
+		m.prompt = newInputPrompt("Annotations for "+count+" (key=value, key- to remove):", "", func(input string) tea.Cmd {
+			return patchMetadataCmd(m.clientset, refs, kube.FieldAnnotations, input)
+		})
+	case "p":
+		m.prompt = m.guardProtected(refs, "Copy", newInputPrompt("Copy "+count+" to namespace:", "", func(namespace string) tea.Cmd {
+			return copySecretsCmd(m.clientset, refs, namespace, false)
+		}))
+	default:
+		return m.handleSecretActionKey(msg)
+	}
+	return m, nil, true
+}
+
+// handleSecretActionKey handles the keys of actions that apply to the highlighted
+// secret only. It reports whether the key was consumed.
+func (m Model) handleSecretActionKey(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	if m.highlightedItem.Name == "" {
+		return m, nil, false
+	}
+	switch msg.String() {
+	case "e":
+		if m.highlightedItem.Immutable {
+			m.status, m.statusErr = fmt.Sprintf("Cannot edit %s: %v", m.highlightedItem.Name, kube.ErrImmutable), true
+			break
+		}
+		m, cmd := m.guardProtectedCmd([]kube.SecretRef{m.highlightedItem.Ref()}, "Edit", prepareEditCmd(m.clientset, m.highlightedItem.Ref()))
+		return m, cmd, true
+	case "I":
+		ref := m.highlightedItem.Ref()
+		m.prompt = newInputPrompt("Import .env file into "+ref.Name+":", ".env", func(path string) tea.Cmd {
+			return prepareImportCmd(m.clientset, ref, path)
+		})
+	case "G":
+		ref := m.highlightedItem.Ref()
+		m.prompt = newInputPrompt("Generate values in "+ref.Name+" (key=kind[:length] ..., kinds: "+generatorHint+"):", "", func(input string) tea.Cmd {
+			return prepareGenerateCmd(m.clientset, ref, input)
+		})
+	case "T":
+		m.prompt = m.rotateWizard(m.highlightedItem.Ref())
+	case "L":
+		return m, checkReloaderCmd(m.clientset, m.highlightedItem.Ref()), true
+	case "r":
+		if _, failed := m.secretErrCache[m.highlightedKey()]; failed {
+			return m, m.retrySecretData(), true
+		}
+		cert, secret := m.certificates[m.highlightedKey()], m.secretObjects[m.highlightedKey()]
+		if cert == nil || secret == nil || m.dynamic == nil {
+			m.status, m.statusErr = m.highlightedItem.Name+" is not managed by a cert-manager Certificate", true
+			break
+		}
+		m.prompt = newConfirmPrompt("Renew Certificate "+cert.Name+"?", func() tea.Cmd {
+			return renewCertificateCmd(m.dynamic, secret)
+		})
+	case "K":
+		secret := m.secretObjects[m.highlightedKey()]
+		if secret == nil {
+			m.status, m.statusErr = m.highlightedItem.Name+" is not loaded yet", true
+			break
+		}
+		m, cmd := m.guardProtectedCmd([]kube.SecretRef{m.highlightedItem.Ref()}, "Write", writeKubeconfigCmd(secret))
+		return m, cmd, true
+	case "i":
+		it := m.highlightedItem
+		question := fmt.Sprintf("Mark %s as immutable? Its data can no longer be edited.", it.Name)
+		if it.Immutable {
+			question = fmt.Sprintf("Delete and recreate %s as mutable?", it.Name)
+		}
+		m.prompt = newConfirmPrompt(question, func() tea.Cmd {
+			return setImmutableCmd(m.clientset, it.Ref(), !it.Immutable)
+		})
+	case "F":
+		return m.toggleFullValues(), nil, true
+	case "h":
+		return m.toggleHistory(), nil, true
+	case "M":
+		return m.toggleMetadata(), nil, true
+	case "R":
+		ref := m.highlightedItem.Ref()
+		m.prompt = newInputPrompt("Rename "+ref.Name+" to:", ref.Name, func(newName string) tea.Cmd {
+			return checkRenameCmd(m.clientset, ref, newName)
+		})
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// retrySecretData forgets the error of the highlighted secret and fetches it again.
+func (m *Model) retrySecretData() tea.Cmd {
+	delete(m.secretErrCache, m.highlightedKey())
+	m.loadingSecret = true
+	return fetchSecretData(m.clientset, m.highlightedItem.Name, m.highlightedItem.Namespace, m.decoders)
+}
+
+// handleActionDone shows the outcome of an action and reloads the list if needed.
+func (m Model) handleActionDone(msg actionDoneMsg) (Model, tea.Cmd) {
+	m.status, m.statusErr = msg.status, msg.err != nil
+	if msg.err != nil {
+		// Joined errors span several lines; keep the help bar on a single line.
+		m.status = fmt.Sprintf("%s: %s", msg.status, strings.ReplaceAll(msg.err.Error(), "\n", "; "))
+	}
+	if msg.undo != nil {
+		m = m.pushUndo(msg.status, msg.undo)
+	}
+	if !msg.refresh {
+		return m, nil
+	}
+	clear(m.selected)
+	clear(m.secretCache)
+	clear(m.secretObjects)
+	clear(m.secretErrCache)
+	clear(m.tlsChecks)
+	clear(m.certificates)
+	m.highlightedItem = kube.Item{}
+	return m, tea.Batch(m.fetchSecretsCmd(), m.fetchQuotaCmd())
+}
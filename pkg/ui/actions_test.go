@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestBulkDelete verifies that the delete action removes every targeted secret.
+func TestBulkDelete(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-a", Namespace: "default"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-b", Namespace: "default"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "keep", Namespace: "default"}},
+	)
+	refs := []kube.SecretRef{{Namespace: "default", Name: "app-a"}, {Namespace: "default", Name: "app-b"}}
+	msg, ok := deleteSecretsCmd(clientset, nil, refs)().(actionDoneMsg)
+	if !ok {
+		t.Fatalf("Expected message of type actionDoneMsg, but got %T", msg)
+	}
+	if msg.err != nil || !msg.refresh {
+		t.Fatalf("Expected a successful, refreshing action, but got %+v", msg)
+	}
+	secrets, err := clientset.CoreV1().Secrets("default").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(secrets.Items) != 1 || secrets.Items[0].Name != "keep" {
+		t.Errorf("Expected only 'keep' to remain, but got %v", secrets.Items)
+	}
+}
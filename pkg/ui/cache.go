@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// cachedListMsg carries the list of secrets that an earlier run cached on disk.
+type cachedListMsg struct {
+	namespace string
+	items     kube.ItemSource
+	savedAt   time.Time
+}
+
+// cacheNamespace returns the namespace under which the list is cached, or an
+// empty one when the list spans every namespace.
+func (m Model) cacheNamespace() string {
+	if m.allNamespaces {
+		return ""
+	}
+	return m.namespace
+}
+
+// loadCachedListCmd reads the list cached by an earlier run, to show it while the
+// API server is asked for the current one.
+func (m Model) loadCachedListCmd() tea.Cmd {
+	if m.listCache == nil {
+		return nil
+	}
+	cache, namespace := m.listCache, m.cacheNamespace()
+	return func() tea.Msg {
+		items, savedAt, err := cache.Load(namespace)
+		if err != nil {
+			slog.Debug("failed to load the cached secret list", "namespace", namespace, "error", err)
+		}
+		return cachedListMsg{namespace: namespace, items: items, savedAt: savedAt}
+	}
+}
+
+// handleCachedList shows the cached list until the current one arrives, unless
+// it already did, or a secret is to be focused, which the cached list may miss.
+func (m Model) handleCachedList(msg cachedListMsg) (Model, tea.Cmd) {
+	if !m.loading || m.focusName != "" || len(msg.items) == 0 || msg.namespace != m.cacheNamespace() {
+		return m, nil
+	}
+	m, cmd := m.handleSecretsLoaded(msg.items)
+	m.revalidating = true
+	m.status = fmt.Sprintf("Showing the list cached %s ago, refreshing...", duration.HumanDuration(time.Since(msg.savedAt)))
+	return m, cmd
+}
+
+// handleListFetched replaces the cached list, if one was shown, and caches the
+// list fetched from the API server for the next run.
+func (m Model) handleListFetched(items kube.ItemSource) (Model, tea.Cmd) {
+	if m.revalidating {
+		m.revalidating = false
+		m.status = ""
+	}
+	if m.listCache == nil {
+		return m, nil
+	}
+	cache, namespace := m.listCache, m.cacheNamespace()
+	return m, func() tea.Msg {
+		if err := cache.Store(namespace, items, time.Now()); err != nil {
+			slog.Debug("failed to cache the secret list", "namespace", namespace, "error", err)
+		}
+		return nil
+	}
+}
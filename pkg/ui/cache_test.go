@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestListCache verifies that the cached list is shown until the current one
+// arrives, which is then cached in turn.
+func TestListCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := kube.OpenListCache(filepath.Join(dir, "lists"), filepath.Join(dir, "cache.key"), "prod")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if err := cache.Store("default", kube.ItemSource{{Name: "db", Namespace: "default"}}, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}},
+	)
+	m := NewModel(clientset, "default", Options{ListCache: cache})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 200, Height: 40})
+
+	next, _ := m.Update(m.loadCachedListCmd()())
+	m = next.(Model)
+	t.Run("should show the cached list while loading", func(t *testing.T) {
+		if m.loading || len(m.allItems) != 1 || m.allItems[0].Name != "db" {
+			t.Errorf("Expected the cached list, but got %v (loading: %v)", m.allItems, m.loading)
+		}
+		if !strings.Contains(m.status, "cached 60m ago") {
+			t.Errorf("Expected the age of the cached list, but got %q", m.status)
+		}
+	})
+
+	items := fetchSecrets(clientset, "default")().(kube.ItemSource)
+	next, _ = m.Update(items)
+	m = next.(Model)
+	t.Run("should replace it with the current list", func(t *testing.T) {
+		if len(m.allItems) != 2 || m.revalidating || m.status != "" {
+			t.Errorf("Expected the current list, but got %v (status: %q)", m.allItems, m.status)
+		}
+	})
+
+	t.Run("should cache the current list", func(t *testing.T) {
+		if _, store := m.handleListFetched(items); store != nil {
+			store()
+		}
+		if cached, _, err := cache.Load("default"); err != nil || len(cached) != 2 {
+			t.Errorf("Expected the current list to be cached, but got %v, %v", cached, err)
+		}
+	})
+
+	t.Run("should ignore the cache once the list arrived", func(t *testing.T) {
+		next, _ := m.Update(cachedListMsg{namespace: "default", items: kube.ItemSource{{Name: "old", Namespace: "default"}}})
+		if got := next.(Model).allItems; len(got) != 2 {
+			t.Errorf("Expected the current list to stay, but got %v", got)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// FormatTLSCheck renders the check with a colored status symbol.
+func FormatTLSCheck(c kube.TLSCheck) string {
+	switch {
+	case c.Problem == "":
+		return addedStyle.Render("✔ " + c.Name)
+	case c.Warning:
+		return changedStyle.Render("⚠ " + c.Name + ": " + c.Problem)
+	}
+	return removedStyle.Render("✘ " + c.Name + ": " + c.Problem)
+}
+
+// tlsCheckedMsg is sent once a TLS secret shown in the TUI has been validated.
+type tlsCheckedMsg struct {
+	key    string // The namespace/name of the secret.
+	checks []kube.TLSCheck
+	err    error
+}
+
+// checkTLSCmd validates a TLS secret for the detail pane.
+func checkTLSCmd(clientset kube.Client, secret *corev1.Secret) tea.Cmd {
+	return func() tea.Msg {
+		hosts, err := kube.IngressHosts(clientset, secret.Namespace, secret.Name)
+		if err != nil {
+			return tlsCheckedMsg{key: cacheKey(secret), err: err}
+		}
+		return tlsCheckedMsg{key: cacheKey(secret), checks: kube.CheckTLSSecret(secret, hosts, nil, time.Now())}
+	}
+}
+
+// handleTLSChecked shows the validation of a TLS secret above its data, and flags
+// failures in the status bar.
+func (m Model) handleTLSChecked(msg tlsCheckedMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status, m.statusErr = "TLS validation failed: "+msg.err.Error(), true
+		return m, nil
+	}
+	m.tlsChecks[msg.key] = msg.checks
+	if kube.TLSChecksFailed(msg.checks) {
+		m.status, m.statusErr = fmt.Sprintf("Certificate problems in %s", msg.key), true
+	}
+	if data, ok := m.secretCache[m.highlightedKey()]; ok && m.highlightedKey() == msg.key {
+		m.viewport.SetContent(m.formatSecretData(data))
+	}
+	return m, nil
+}
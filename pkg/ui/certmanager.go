@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+// CertificateLines renders the Certificate as lines for the CLI and the detail pane.
+func CertificateLines(c *kube.CertificateInfo) []string {
+	lines := []string{"Certificate/" + c.Name + " (cert-manager)"}
+	ready := "Ready: Unknown"
+	if c.Ready != nil {
+		ready = addedStyle.Render(fmt.Sprintf("Ready: %s (%s)", c.Ready.Status, c.Ready.Reason))
+		if c.Ready.Status != "True" {
+			ready = removedStyle.Render(fmt.Sprintf("Ready: %s (%s): %s", c.Ready.Status, c.Ready.Reason, c.Ready.Message))
+		}
+	}
+	lines = append(lines, ready)
+	if c.Issuing != nil && c.Issuing.Status == "True" {
+		lines = append(lines, changedStyle.Render(fmt.Sprintf("Issuing: %s (%s)", c.Issuing.Reason, c.Issuing.Message)))
+	}
+	if c.NotAfter != "" {
+		lines = append(lines, "Expires: "+c.NotAfter)
+	}
+	if c.RenewalTime != "" {
+		lines = append(lines, "Renewal: "+c.RenewalTime)
+	}
+	return lines
+}
+
+// certificateLoadedMsg is sent once the Certificate behind a TLS secret has been looked up.
+type certificateLoadedMsg struct {
+	key  string                // The namespace/name of the secret.
+	cert *kube.CertificateInfo // Nil if the secret is not managed by cert-manager.
+}
+
+// loadCertificateCmd looks up the Certificate behind a TLS secret for the detail pane.
+// Lookup failures are not reported: the pane simply shows no Certificate.
+func loadCertificateCmd(dyn dynamic.Interface, secret *corev1.Secret) tea.Cmd {
+	return func() tea.Msg {
+		cert, err := kube.FindCertificate(dyn, secret)
+		if err != nil || cert == nil {
+			return certificateLoadedMsg{key: cacheKey(secret)}
+		}
+		return certificateLoadedMsg{key: cacheKey(secret), cert: kube.DescribeCertificate(cert)}
+	}
+}
+
+// renewCertificateCmd triggers the renewal of the Certificate behind a secret.
+func renewCertificateCmd(dyn dynamic.Interface, secret *corev1.Secret) tea.Cmd {
+	return func() tea.Msg {
+		cert, err := kube.FindCertificate(dyn, secret)
+		if err == nil && cert == nil {
+			err = errors.New("not managed by a cert-manager Certificate")
+		}
+		if err == nil {
+			err = kube.RenewCertificate(dyn, cert, time.Now())
+		}
+		if err != nil {
+			return actionDoneMsg{status: "Renewal failed", err: err}
+		}
+		return actionDoneMsg{status: "Triggered the renewal of Certificate " + cert.GetName(), refresh: true}
+	}
+}
+
+// handleCertificateLoaded shows the Certificate of a TLS secret above its data.
+func (m Model) handleCertificateLoaded(msg certificateLoadedMsg) (Model, tea.Cmd) {
+	if msg.cert == nil {
+		return m, nil
+	}
+	m.certificates[msg.key] = msg.cert
+	if data, ok := m.secretCache[m.highlightedKey()]; ok && m.highlightedKey() == msg.key {
+		m.viewport.SetContent(m.formatSecretData(data))
+	}
+	return m, nil
+}
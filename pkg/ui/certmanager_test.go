@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/internal/testfixtures"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestFindCertificate verifies the lookup by annotation and by spec.secretName.
+func TestFindCertificate(t *testing.T) {
+	ready := map[string]any{"type": "Ready", "status": "True", "reason": "Ready"}
+	dyn := testfixtures.NewCertificateClient(testfixtures.NewCertificate("web", "web-tls", ready), testfixtures.NewCertificate("api-cert", "api-tls"))
+	secret := func(name string, annotations map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Annotations: annotations}}
+	}
+
+	t.Run("should find the Certificate named in the annotation", func(t *testing.T) {
+		cert, err := kube.FindCertificate(dyn, secret("api-tls", map[string]string{kube.CertificateNameAnnotation: "api-cert"}))
+		if err != nil || cert == nil || cert.GetName() != "api-cert" {
+			t.Fatalf("Expected Certificate 'api-cert', but got %v (%v)", cert, err)
+		}
+	})
+
+	t.Run("should find the Certificate by its secret name", func(t *testing.T) {
+		cert, err := kube.FindCertificate(dyn, secret("web-tls", nil))
+		if err != nil || cert == nil {
+			t.Fatalf("Expected Certificate 'web', but got %v (%v)", cert, err)
+		}
+		info := kube.DescribeCertificate(cert)
+		if info.Ready == nil || info.Ready.Status != "True" || info.RenewalTime != "2024-12-02T00:00:00Z" {
+			t.Errorf("Expected a ready Certificate with a renewal time, but got %+v", info)
+		}
+		if lines := strings.Join(CertificateLines(info), "\n"); !strings.Contains(lines, "Ready: True (Ready)") || !strings.Contains(lines, "Renewal: 2024-12-02") {
+			t.Errorf("Expected the status to be rendered, but got:\n%s", lines)
+		}
+	})
+
+	t.Run("should return nil for unmanaged secrets", func(t *testing.T) {
+		if cert, err := kube.FindCertificate(dyn, secret("db", nil)); err != nil || cert != nil {
+			t.Errorf("Expected no Certificate, but got %v (%v)", cert, err)
+		}
+	})
+}
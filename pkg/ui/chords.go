@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// chord is a key of the data pane that starts a sequence of two keys, such as g
+// for goto, whose completions are listed in a popup until the second key is
+// pressed.
+type chord struct {
+	key      string
+	name     string
+	bindings []chordBinding
+}
+
+// chordBinding is a second key of a chord, and what it does.
+type chordBinding struct {
+	key         string
+	description string
+	run         func(m Model) (Model, tea.Cmd)
+}
+
+// chords are the chords of the data pane, in the order of the help line.
+var chords = []chord{
+	{key: "g", name: "goto", bindings: []chordBinding{
+		{"g", "top of the data", func(m Model) (Model, tea.Cmd) { m.viewport.GotoTop(); return m, nil }},
+		{"e", "end of the data", func(m Model) (Model, tea.Cmd) { m.viewport.GotoBottom(); return m, nil }},
+		{"l", "list of secrets", func(m Model) (Model, tea.Cmd) { m.focus = leftPane; m.textinput.Focus(); return m, nil }},
+		{"n", "namespace", func(m Model) (Model, tea.Cmd) { return m, promptNamespaceCmd(m.clientset) }},
+	}},
+	{key: "y", name: "yank", bindings: []chordBinding{
+		{"n", "name of the secret", func(m Model) (Model, tea.Cmd) { return m.yank("name", m.highlightedItem.Name) }},
+		{"r", "namespace/name", func(m Model) (Model, tea.Cmd) { return m.yank("reference", m.highlightedKey()) }},
+		{"u", "secret:// URI", func(m Model) (Model, tea.Cmd) { return m.yank("URI", m.secretURI()) }},
+		{"k", "kubectl command", Model.yankKubectl},
+		{"v", "value of a key", Model.yankValue},
+	}},
+}
+
+// chordForKey returns the chord started by a key.
+func chordForKey(key string) (*chord, bool) {
+	for i := range chords {
+		if chords[i].key == key {
+			return &chords[i], true
+		}
+	}
+	return nil, false
+}
+
+// handleChordKey starts a chord, or completes the pending one: its second key
+// runs the binding, and any other key cancels it. It reports whether the key was
+// consumed.
+func (m Model) handleChordKey(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	if m.pendingChord == nil {
+		c, ok := chordForKey(msg.String())
+		if ok {
+			m.pendingChord = c
+		}
+		return m, nil, ok
+	}
+	c := m.pendingChord
+	m.pendingChord = nil
+	for _, b := range c.bindings {
+		if b.key == msg.String() {
+			m, cmd := b.run(m)
+			return m, cmd, true
+		}
+	}
+	if msg.String() != "esc" {
+		m.status, m.statusErr = fmt.Sprintf("%s %s is not bound", c.key, msg.String()), true
+	}
+	return m, nil, true
+}
+
+// viewChordHint renders the completions of the pending chord, which-key style.
+func (m *Model) viewChordHint() string {
+	c := m.pendingChord
+	lines := []string{TitleStyle.Render(c.name + "…")}
+	for _, b := range c.bindings {
+		lines = append(lines, fmt.Sprintf("%s %s  %s", c.key, lipgloss.NewStyle().Foreground(focusedColor).Bold(true).Render(b.key), b.description))
+	}
+	lines = append(lines, "", NoteStyle.Render("esc: cancel"))
+	return modalStyle.Render(strings.Join(lines, "\n"))
+}
+
+// yank copies a text about the highlighted secret to the clipboard.
+func (m Model) yank(what, text string) (Model, tea.Cmd) {
+	if m.highlightedItem.Name == "" {
+		m.status, m.statusErr = "No secret to yank from", true
+		return m, nil
+	}
+	return m, copyToClipboardCmd(what, text)
+}
+
+// yankValue asks for a key of the highlighted secret and copies its value to the
+// clipboard. Values that are not shown, because the namespace is protected or the
+// redaction policy masks them, are not copied either.
+func (m Model) yankValue() (Model, tea.Cmd) {
+	data, ok := m.secretCache[m.highlightedKey()]
+	if !ok || m.highlightedItem.Name == "" {
+		m.status, m.statusErr = "No secret to yank from", true
+		return m, nil
+	}
+	if m.locked() {
+		m.status, m.statusErr = fmt.Sprintf("%s is in a protected namespace; reveal it with v first", m.highlightedItem.Name), true
+		return m, nil
+	}
+	var keys []string
+	for _, key := range kube.SortedKeys(data) {
+		if m.redactionOf(key) == kube.RedactNone {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		m.status, m.statusErr = m.highlightedItem.Name+" has no values to yank", true
+		return m, nil
+	}
+	m.prompt = newChoicePrompt(fmt.Sprintf("Yank the value of (default %s):", keys[0]), "", keys, func(key string) tea.Cmd {
+		if key == "" {
+			key = keys[0]
+		}
+		value, ok := data[key]
+		if !ok || m.redactionOf(key) != kube.RedactNone {
+			return func() tea.Msg {
+				return actionDoneMsg{status: "Cannot yank", err: fmt.Errorf("no value of '%s' to yank", key)}
+			}
+		}
+		return copyToClipboardCmd("value of "+key, value)
+	})
+	return m, nil
+}
+
+// yankKubectl copies the kubectl command that prints the selected secrets, or a
+// key of the highlighted one, asked for, for those who do not use kds.
+func (m Model) yankKubectl() (Model, tea.Cmd) {
+	refs := m.actionTargets()
+	if len(refs) == 0 {
+		m.status, m.statusErr = "No secret to yank from", true
+		return m, nil
+	}
+	if len(refs) > 1 {
+		commands := make([]string, len(refs))
+		for i, ref := range refs {
+			commands[i] = kube.KubectlCommand(m.context, ref, "")
+		}
+		return m, copyToClipboardCmd(fmt.Sprintf("kubectl commands of %d secrets", len(refs)), strings.Join(commands, "\n"))
+	}
+	ref := refs[0]
+	keys := kube.SortedKeys(m.secretCache[ref.String()])
+	m.prompt = newChoicePrompt("Yank the kubectl command of the key (empty for every key):", "", keys, func(key string) tea.Cmd {
+		return copyToClipboardCmd("kubectl command", kube.KubectlCommand(m.context, ref, key))
+	})
+	return m, nil
+}
+
+// copyToClipboardCmd copies a text to the clipboard, reporting what was copied.
+func copyToClipboardCmd(what, text string) tea.Cmd {
+	return func() tea.Msg {
+		if err := clipboard.WriteAll(text); err != nil {
+			return actionDoneMsg{status: "Failed to copy the " + what, err: err}
+		}
+		return actionDoneMsg{status: "Copied the " + what + " to the clipboard"}
+	}
+}
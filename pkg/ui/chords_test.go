@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"encoding/base64"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestChords verifies that g and y list their completions until the second key,
+// and that yanking a value leaves out the keys masked by policy.
+func TestChords(t *testing.T) {
+	encode := func(s string) []byte { return []byte(base64.StdEncoding.EncodeToString([]byte(s))) }
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Data:       map[string][]byte{"API_TOKEN": encode("t0k3n"), "API_URL": encode("https://api.example.com")},
+	})
+	policy, err := kube.CompileRedactionPolicy([]kube.RedactionRuleConfig{{Keys: "_TOKEN$"}})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	m := NewModel(clientset, "default", Options{Redaction: policy})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 200, Height: 40})
+	m, _ = m.handleSecretsLoaded(kube.ItemSource{{Name: "app", Namespace: "default"}})
+	m.focus = rightPane
+	m, _ = m.handleSecretDataLoaded(fetchSecretData(clientset, "app", "default", nil)().(secretDataLoadedMsg))
+	press := func(m Model, key string) Model {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		return next.(Model)
+	}
+
+	t.Run("should list the completions of a started chord", func(t *testing.T) {
+		m := press(m, "g")
+		view := m.viewRightPane()
+		if !strings.Contains(view, "goto") || !strings.Contains(view, "top of the data") || strings.Contains(view, "api.example.com") {
+			t.Errorf("Expected the completions of g in place of the data, but got:\n%s", view)
+		}
+	})
+	t.Run("should complete a chord with its second key", func(t *testing.T) {
+		m := press(press(m, "g"), "l")
+		if m.pendingChord != nil || m.focus != leftPane {
+			t.Errorf("Expected g l to focus the list, but got focus %v", m.focus)
+		}
+	})
+	t.Run("should cancel a chord with an unbound key", func(t *testing.T) {
+		m := press(press(m, "g"), "z")
+		if m.pendingChord != nil || !m.statusErr || !strings.Contains(m.status, "g z is not bound") {
+			t.Errorf("Expected the chord to be cancelled, but got status %q", m.status)
+		}
+		if !strings.Contains(m.viewRightPane(), "api.example.com") {
+			t.Error("Expected the data to be shown again")
+		}
+	})
+	t.Run("should offer the keys for the kubectl command", func(t *testing.T) {
+		m := press(press(m, "y"), "k")
+		if m.prompt == nil {
+			t.Fatal("Expected a prompt for the key of the command")
+		}
+		if got := m.prompt.input.AvailableSuggestions(); !slices.Equal(got, []string{"API_TOKEN", "API_URL"}) {
+			t.Errorf("Expected every key to be offered, but got %v", got)
+		}
+	})
+	t.Run("should offer only the values shown to be yanked", func(t *testing.T) {
+		m := press(press(m, "y"), "v")
+		if m.prompt == nil {
+			t.Fatal("Expected a prompt for the key to yank")
+		}
+		if got := m.prompt.input.AvailableSuggestions(); !slices.Equal(got, []string{"API_URL"}) {
+			t.Errorf("Expected only API_URL to be offered, but got %v", got)
+		}
+	})
+}
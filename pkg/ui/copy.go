@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// copyConflictMsg is sent when copying from the TUI would overwrite existing secrets.
+type copyConflictMsg struct {
+	refs        []kube.SecretRef
+	toNamespace string
+}
+
+// copySecretsCmd copies the referenced secrets into another namespace. Without
+// overwrite, secrets that already exist in the target are reported back in a
+// copyConflictMsg so the user can confirm replacing them, unless other copies
+// failed, in which case they are reported as failed along with the others.
+func copySecretsCmd(clientset kube.Client, refs []kube.SecretRef, toNamespace string, overwrite bool) tea.Cmd {
+	targets := make([]kube.SecretRef, len(refs))
+	for i, ref := range refs {
+		targets[i] = kube.SecretRef{Namespace: toNamespace, Name: ref.Name}
+	}
+	return undoable(clientset, targets, func() tea.Msg {
+		var conflicts []kube.SecretRef
+		failed := 0
+		msg := runOnSecrets(refs, "Copied", func(ref kube.SecretRef) error {
+			err := kube.CopySecret(clientset, ref, toNamespace, overwrite)
+			if apierrors.IsAlreadyExists(err) {
+				conflicts = append(conflicts, ref)
+			}
+			if err != nil {
+				failed++
+			}
+			return err
+		})
+		if len(conflicts) > 0 && len(conflicts) == failed {
+			return copyConflictMsg{refs: conflicts, toNamespace: toNamespace}
+		}
+		return msg
+	})
+}
+
+// handleCopyConflict asks whether secrets that already exist in the target namespace should be overwritten.
+func (m Model) handleCopyConflict(msg copyConflictMsg) (Model, tea.Cmd) {
+	question := fmt.Sprintf("%d secret(s) already exist in '%s'. Overwrite?", len(msg.refs), msg.toNamespace)
+	m.prompt = newConfirmPrompt(question, func() tea.Cmd {
+		return copySecretsCmd(m.clientset, msg.refs, msg.toNamespace, true)
+	})
+	return m, nil
+}
@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestCopySecretsCmd verifies that secrets existing in the target namespace are
+// offered to be overwritten, and reported as failed when other copies failed.
+func TestCopySecretsCmd(t *testing.T) {
+	newClientset := func() *fake.Clientset {
+		return fake.NewSimpleClientset(
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"}},
+		)
+	}
+	api, db, missing := kube.SecretRef{Namespace: "default", Name: "api"}, kube.SecretRef{Namespace: "default", Name: "db"}, kube.SecretRef{Namespace: "default", Name: "missing"}
+
+	t.Run("should offer to overwrite the existing secrets", func(t *testing.T) {
+		msg, ok := copySecretsCmd(newClientset(), []kube.SecretRef{api, db}, "prod", false)().(copyConflictMsg)
+		if !ok || len(msg.refs) != 1 || msg.refs[0] != db {
+			t.Errorf("Expected db to conflict, but got %+v", msg)
+		}
+	})
+	t.Run("should report the conflicts along with other failures", func(t *testing.T) {
+		msg, ok := copySecretsCmd(newClientset(), []kube.SecretRef{api, db, missing}, "prod", false)().(actionDoneMsg)
+		if !ok || msg.err == nil {
+			t.Fatalf("Expected the copy to fail, but got %+v", msg)
+		}
+		if !strings.Contains(msg.status, "2 of 3") || !strings.Contains(msg.err.Error(), "default/db") || !strings.Contains(msg.err.Error(), "default/missing") {
+			t.Errorf("Expected db and missing to be reported, but got %q: %v", msg.status, msg.err)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// newSecretWizard asks for the type of a new secret in the current namespace,
+// then for the details of that type. TLS secrets are read from files, so they
+// cannot be created in remote sessions.
+func (m Model) newSecretWizard() *prompt {
+	title := "New secret type (tls, docker-registry, generic):"
+	if m.remote {
+		title = "New secret type (docker-registry, generic):"
+	}
+	return newInputPrompt(title, "", func(secretType string) tea.Cmd {
+		var next *prompt
+		switch secretType {
+		case "tls":
+			if m.remote {
+				return func() tea.Msg {
+					return actionDoneMsg{status: "Create failed", err: errors.New("tls is disabled in remote sessions: it would read files on the server")}
+				}
+			}
+			next = m.tlsSecretWizard()
+		case "docker-registry":
+			next = m.dockerRegistrySecretWizard()
+		case "generic":
+			next = m.genericSecretWizard()
+		default:
+			return func() tea.Msg {
+				return actionDoneMsg{status: "Create failed", err: fmt.Errorf("unknown secret type '%s'", secretType)}
+			}
+		}
+		return func() tea.Msg { return showPromptMsg{prompt: next} }
+	})
+}
+
+// tlsSecretWizard asks for the details of a new TLS secret.
+func (m Model) tlsSecretWizard() *prompt {
+	steps := []wizardStep{
+		{title: "New TLS secret name:"},
+		{title: "Certificate file:", initial: "tls.crt"},
+		{title: "Private key file:", initial: "tls.key"},
+	}
+	return newWizard(steps, func(answers []string) tea.Cmd {
+		return createTLSSecretCmd(m.clientset, m.namespace, answers[0], answers[1], answers[2])
+	})
+}
+
+// dockerRegistrySecretWizard asks for the details of a new docker-registry secret.
+func (m Model) dockerRegistrySecretWizard() *prompt {
+	steps := []wizardStep{
+		{title: "New docker-registry secret name:"},
+		{title: "Registry server:", initial: kube.DefaultRegistryServer},
+		{title: "Username:"},
+		{title: "Password:", hidden: true},
+		{title: "Email (optional):"},
+		{title: "Add to service account (optional):"},
+	}
+	return newWizard(steps, func(answers []string) tea.Cmd {
+		creds := kube.RegistryCredentials{Server: answers[1], Username: answers[2], Password: answers[3], Email: answers[4]}
+		return createDockerRegistrySecretCmd(m.clientset, m.namespace, answers[0], creds, answers[5])
+	})
+}
+
+// genericSecretWizard asks for the details of a new Opaque secret whose key is set
+// to a generated value.
+func (m Model) genericSecretWizard() *prompt {
+	steps := []wizardStep{
+		{title: "New generic secret name:"},
+		{title: "Key:", initial: "password"},
+		{title: "Generator (" + generatorHint + ", with an optional :length):", initial: kube.GenerateAlphanumeric},
+	}
+	return newWizard(steps, func(answers []string) tea.Cmd {
+		return createGenericSecretCmd(m.clientset, m.namespace, answers[0], answers[1], answers[2])
+	})
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxCredentialRequests is how many requests the credential refresh sends: the
+// first 401 makes client-go run the credential plugin again, and the next
+// request uses the new credentials.
+const maxCredentialRequests = 2
+
+// credentialsRefreshedMsg is sent when the TUI resumes after refreshing the credentials.
+type credentialsRefreshedMsg struct{ err error }
+
+// credentialRefresh sends requests to the API server while the TUI is suspended,
+// so that a credential plugin run by client-go can open a browser or show a
+// device code on the terminal.
+type credentialRefresh struct {
+	clientset kube.Client
+	namespace string
+	stderr    io.Writer
+}
+
+// SetStdin is part of tea.ExecCommand. The plugin reads the terminal directly.
+func (c *credentialRefresh) SetStdin(io.Reader) {}
+
+// SetStdout is part of tea.ExecCommand. The plugin writes to the terminal directly.
+func (c *credentialRefresh) SetStdout(io.Writer) {}
+
+// SetStderr is part of tea.ExecCommand, and receives the progress message.
+func (c *credentialRefresh) SetStderr(w io.Writer) { c.stderr = w }
+
+// Run sends requests until the API server accepts the credentials, or the
+// plugin failed to refresh them.
+func (c *credentialRefresh) Run() error {
+	if c.stderr != nil {
+		fmt.Fprintln(c.stderr, "kds: the API server rejected the credentials, running the credential plugin again...")
+	}
+	var err error
+	for range maxCredentialRequests {
+		_, err = c.clientset.CoreV1().Secrets(c.namespace).List(context.TODO(), metav1.ListOptions{Limit: 1})
+		if !k8serrors.IsUnauthorized(err) {
+			break
+		}
+	}
+	return err
+}
+
+// credentialsExpired reports whether err means that the credentials of a
+// credential plugin expired and can be refreshed.
+func (m Model) credentialsExpired(err error) bool {
+	return m.execPlugin && k8serrors.IsUnauthorized(err)
+}
+
+// refreshCredentials suspends the TUI while the credential plugin runs again,
+// unless it already does.
+func (m Model) refreshCredentials(err error) (Model, tea.Cmd) {
+	if m.reauthenticating {
+		return m, nil
+	}
+	slog.Debug("refreshing expired credentials", "error", err)
+	m.reauthenticating = true
+	namespace := m.namespace
+	if m.allNamespaces {
+		namespace = ""
+	}
+	refresh := &credentialRefresh{clientset: m.clientset, namespace: namespace}
+	return m, tea.Exec(refresh, func(err error) tea.Msg { return credentialsRefreshedMsg{err: err} })
+}
+
+// handleCredentialsRefreshed reloads everything with the new credentials, and
+// watches again the changes that the expired ones stopped. If they could not be
+// refreshed, the error is fatal as before.
+func (m Model) handleCredentialsRefreshed(msg credentialsRefreshedMsg) (Model, tea.Cmd) {
+	m.reauthenticating = false
+	switch {
+	case msg.err == nil:
+		m, cmd := m.handleActionDone(actionDoneMsg{status: "Refreshed the credentials", refresh: true})
+		return m, tea.Batch(cmd, m.restartWatch(), m.restartHistory())
+	case connectionLost(msg.err):
+		return m.startReconnecting(msg.err)
+	}
+	m.err = fmt.Errorf("failed to refresh the credentials: %w", msg.err)
+	return m, tea.Quit
+}
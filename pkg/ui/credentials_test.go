@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestRefreshCredentials verifies that expired credentials of a credential plugin
+// are refreshed instead of quitting.
+func TestRefreshCredentials(t *testing.T) {
+	expired := fatalErrorMsg{err: k8serrors.NewUnauthorized("token expired")}
+	newModel := func(clientset kube.Client, plugin bool) Model {
+		m := NewModel(clientset, "default", Options{CredentialPlugin: plugin})
+		next, _ := m.Update(kube.ItemSource{{Name: "api", Namespace: "default"}})
+		return next.(Model)
+	}
+
+	t.Run("should suspend the TUI with a credential plugin", func(t *testing.T) {
+		next, cmd := newModel(fake.NewSimpleClientset(), true).Update(expired)
+		m := next.(Model)
+		if m.err != nil || cmd == nil || !m.reauthenticating {
+			t.Fatalf("Expected the credentials to be refreshed, but got error %v", m.err)
+		}
+		if _, again := m.Update(expired); again != nil {
+			t.Error("Expected a single refresh at a time")
+		}
+	})
+	t.Run("should quit without a credential plugin", func(t *testing.T) {
+		next, _ := newModel(fake.NewSimpleClientset(), false).Update(expired)
+		if next.(Model).err == nil {
+			t.Error("Expected the error to be fatal")
+		}
+	})
+	t.Run("should retry once the plugin ran again", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}})
+		requests := 0
+		clientset.PrependReactor("list", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+			requests++
+			if requests == 1 {
+				return true, nil, expired.err
+			}
+			return false, nil, nil
+		})
+		if err := (&credentialRefresh{clientset: clientset, namespace: "default"}).Run(); err != nil || requests != 2 {
+			t.Errorf("Expected success on the second request, but got %v after %d request(s)", err, requests)
+		}
+	})
+	t.Run("should reload everything with the new credentials", func(t *testing.T) {
+		m := newModel(fake.NewSimpleClientset(), true)
+		m.secretErrCache["default/api"] = expired.err
+		m.reauthenticating = true
+		m, cmd := m.handleCredentialsRefreshed(credentialsRefreshedMsg{})
+		if m.reauthenticating || len(m.secretErrCache) != 0 || cmd == nil {
+			t.Errorf("Expected the errors to be cleared and the list to be reloaded, but got %v", m.secretErrCache)
+		}
+	})
+	t.Run("should refresh the credentials that stopped the watch", func(t *testing.T) {
+		m := NewModel(fake.NewSimpleClientset(), "default", Options{CredentialPlugin: true, Watch: true, History: &kube.HistoryStore{}})
+		next, cmd := m.Update(watchStoppedMsg{generation: m.watchGeneration, err: expired.err})
+		if m := next.(Model); !m.reauthenticating || cmd == nil {
+			t.Errorf("Expected the credentials to be refreshed, but got status %q", m.status)
+		}
+		next, cmd = m.Update(historyStoppedMsg{err: expired.err})
+		if m := next.(Model); !m.reauthenticating || cmd == nil {
+			t.Errorf("Expected the credentials to be refreshed, but got status %q", m.status)
+		}
+	})
+	t.Run("should watch again with the new credentials", func(t *testing.T) {
+		m := NewModel(fake.NewSimpleClientset(), "default", Options{CredentialPlugin: true, Watch: true, History: &kube.HistoryStore{}})
+		generation, historyStopped := m.watchGeneration, false
+		m.historyStop()
+		m.historyStop = func() { historyStopped = true }
+		m, _ = m.handleCredentialsRefreshed(credentialsRefreshedMsg{})
+		defer m.watchStop()
+		defer m.historyStop()
+		if m.watchGeneration != generation+1 || !historyStopped {
+			t.Errorf("Expected the watch and the history to be restarted, but got watch %d", m.watchGeneration)
+		}
+	})
+	t.Run("should quit if the plugin failed", func(t *testing.T) {
+		m, _ := newModel(fake.NewSimpleClientset(), true).handleCredentialsRefreshed(credentialsRefreshedMsg{err: expired.err})
+		if m.err == nil {
+			t.Error("Expected the error to be fatal")
+		}
+	})
+}
@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// applyDecoders replaces the decoded values of a secret with the rendering of the
+// first decoder that applies to each key. Failures are shown next to the value.
+func applyDecoders(decoders []kube.ValueDecoder, secret *corev1.Secret, data map[string]string) {
+	for key, value := range secret.Data {
+		for _, d := range decoders {
+			if !d.Matches(secret, key) {
+				continue
+			}
+			rendered, err := d.Decode(secret, key, value)
+			if err != nil {
+				data[key] += " " + errorStyle.Render(fmt.Sprintf("(decoder %s failed: %v)", d.Name, err))
+			} else {
+				data[key] = rendered + " " + NoteStyle.Render("(decoded by "+d.Name+")")
+			}
+			break
+		}
+	}
+}
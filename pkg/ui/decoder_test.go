@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestApplyDecoders verifies that values are rendered by the first decoder that
+// applies, with failures shown next to the value.
+func TestApplyDecoders(t *testing.T) {
+	decoders, err := kube.CompileDecoders([]kube.DecoderConfig{
+		{Name: "upper", Keys: `\.acme$`, Command: []string{"sh", "-c", "tr a-z A-Z"}},
+		{Name: "broken", Keys: `^broken$`, Command: []string{"sh", "-c", "echo bad format >&2; exit 1"}},
+		{Name: "location", Types: []string{"acme.io/blob"}, Command: []string{"sh", "-c", `echo "$KDS_NAMESPACE/$KDS_SECRET/$KDS_KEY"`}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	secret := &corev1.Secret{
+		Type: "acme.io/blob",
+		Data: map[string][]byte{"license.acme": []byte("abc"), "other": []byte("xyz"), "broken": []byte("?")},
+	}
+	secret.Namespace, secret.Name = "billing", "license"
+	data := map[string]string{"license.acme": "abc", "other": "xyz", "broken": "?"}
+
+	applyDecoders(decoders, secret, data)
+	if !strings.HasPrefix(data["license.acme"], "ABC (decoded by upper)") {
+		t.Errorf("Expected the value decoded by upper, but got '%s'", data["license.acme"])
+	}
+	if !strings.HasPrefix(data["other"], "billing/license/other") {
+		t.Errorf("Expected the location of the value, but got '%s'", data["other"])
+	}
+	if !strings.HasPrefix(data["broken"], "? (decoder broken failed") || !strings.Contains(data["broken"], "bad format") {
+		t.Errorf("Expected the decoder error, but got '%s'", data["broken"])
+	}
+
+	t.Run("should skip secrets of other types", func(t *testing.T) {
+		opaque := &corev1.Secret{Type: corev1.SecretTypeOpaque, Data: map[string][]byte{"other": []byte("xyz")}}
+		data := map[string]string{"other": "xyz"}
+		applyDecoders(decoders, opaque, data)
+		if data["other"] != "xyz" {
+			t.Errorf("Expected the value unchanged, but got '%s'", data["other"])
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// Styles for the kinds of change in a data diff.
+var (
+	addedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#2ECC40"))
+	changedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFDC00"))
+	removedStyle = lipgloss.NewStyle().Foreground(errorColor)
+)
+
+// RenderDataDiff renders one colored "+ key", "~ key", or "- key" line per change,
+// without revealing any values.
+func RenderDataDiff(changes []kube.DataChange) string {
+	var b strings.Builder
+	for _, change := range changes {
+		style := addedStyle
+		switch change.Kind {
+		case kube.KeyChanged:
+			style = changedStyle
+		case kube.KeyRemoved:
+			style = removedStyle
+		}
+		b.WriteString("  " + style.Render(fmt.Sprintf("%s %s", change.Kind, change.Key)) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// createDockerRegistrySecretCmd previews the docker-registry secret described in
+// the TUI wizard with a server-side dry run.
+func createDockerRegistrySecretCmd(clientset kube.Client, namespace, name string, creds kube.RegistryCredentials, serviceAccount string) tea.Cmd {
+	return func() tea.Msg {
+		if creds.Server == "" {
+			creds.Server = kube.DefaultRegistryServer
+		}
+		secret, err := kube.BuildDockerRegistrySecret(namespace, name, creds)
+		if err != nil {
+			return actionDoneMsg{status: "Create failed", err: err}
+		}
+		apply := func() tea.Msg {
+			if err := kube.CreateSecret(clientset, secret); err != nil {
+				return actionDoneMsg{status: "Create failed", err: err, refresh: true}
+			}
+			if serviceAccount != "" {
+				if err := kube.LinkServiceAccount(clientset, secret, serviceAccount); err != nil {
+					return actionDoneMsg{status: "Create incomplete", err: err, refresh: true}
+				}
+			}
+			return actionDoneMsg{status: "Created " + name, refresh: true}
+		}
+		return previewMutation(clientset, kube.Mutation{After: secret}, "Create "+name, apply)
+	}
+}
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// editReadyMsg is sent once a secret has been written to a temporary file for editing.
+type editReadyMsg struct {
+	secret *corev1.Secret
+	path   string
+	err    error
+}
+
+// editorClosedMsg is sent when the editor launched from the TUI exits.
+type editorClosedMsg struct {
+	secret *corev1.Secret
+	path   string
+	err    error
+}
+
+// editMergeMsg is sent when a secret changed on the server while it was edited
+// from the TUI, to settle the keys changed on both sides before the merged update
+// is previewed.
+type editMergeMsg struct {
+	merge   *kube.EditMerge
+	pending []string // The conflicting keys left to settle.
+	title   string
+	status  string
+}
+
+// EditorCommand builds the command that opens a file in the user's editor.
+func EditorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("KUBE_EDITOR")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	return exec.Command(args[0], append(args[1:], path)...) //nolint:gosec // The editor is chosen by the user.
+}
+
+// prepareEditCmd writes a secret to a temporary file for editing from the TUI.
+func prepareEditCmd(clientset kube.Client, ref kube.SecretRef) tea.Cmd {
+	return func() tea.Msg {
+		secret, path, err := kube.PrepareEdit(clientset, ref, nil)
+		return editReadyMsg{secret: secret, path: path, err: err}
+	}
+}
+
+// handleEditReady suspends the TUI and opens the prepared file in the editor.
+func (m Model) handleEditReady(msg editReadyMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status, m.statusErr = fmt.Sprintf("Cannot edit: %v", msg.err), true
+		return m, nil
+	}
+	return m, tea.ExecProcess(EditorCommand(msg.path), func(err error) tea.Msg {
+		return editorClosedMsg{secret: msg.secret, path: msg.path, err: err}
+	})
+}
+
+// finishEditCmd reads back the values edited in the TUI and previews the update
+// with a server-side dry run.
+func finishEditCmd(clientset kube.Client, msg editorClosedMsg) tea.Cmd {
+	return func() tea.Msg {
+		mu, changed, err := kube.ReadEdit(msg.secret, msg.path, msg.err)
+		if err != nil {
+			return actionDoneMsg{status: "Edit failed", err: err}
+		}
+		if !changed {
+			return actionDoneMsg{status: "Edit cancelled, no changes made"}
+		}
+		merge, err := kube.MergeEdit(clientset, mu)
+		if err != nil {
+			return actionDoneMsg{status: "Edit failed", err: err}
+		}
+		title := kube.UpdateTitle(clientset, "Update", mu.Ref())
+		if merge != nil {
+			return editMergeMsg{merge: merge, pending: merge.Conflicts(), title: title, status: "Edited " + msg.secret.Name}
+		}
+		return previewUpdate(clientset, mu, title, "Edited "+msg.secret.Name)
+	}
+}
+
+// handleEditMerge asks which value to keep for each key changed both in the edit
+// and on the server, then previews the merged update.
+func (m Model) handleEditMerge(msg editMergeMsg) (Model, tea.Cmd) {
+	keys := make([]string, len(msg.merge.Keys))
+	for i, k := range msg.merge.Keys {
+		keys[i] = fmt.Sprintf("%s (mine %s, theirs %s)", k.Key, changeMark(k.Mine), changeMark(k.Theirs))
+	}
+	changed := fmt.Sprintf("%s changed on the server while it was edited: %s", msg.merge.Mutation.Ref().Name, strings.Join(keys, ", "))
+	if len(msg.pending) == 0 {
+		clientset, mu := m.clientset, msg.merge.Mutation
+		m.status, m.statusErr = changed, false
+		return m, func() tea.Msg {
+			return previewUpdate(clientset, mu, msg.title+", merged with the changes on the server", msg.status)
+		}
+	}
+	key := msg.pending[0]
+	question := fmt.Sprintf("%s. Keep mine or theirs for %s?", changed, key)
+	m.prompt = newChoicePrompt(question, "mine", []string{"mine", "theirs"}, func(value string) tea.Cmd {
+		next := msg
+		switch strings.TrimSpace(value) {
+		case "mine":
+			msg.merge.Resolve(key, true)
+			next.pending = msg.pending[1:]
+		case "theirs":
+			msg.merge.Resolve(key, false)
+			next.pending = msg.pending[1:]
+		}
+		return func() tea.Msg { return next }
+	})
+	return m, nil
+}
+
+// changeMark returns the mark of a change of a key, or "=" if it is unchanged.
+func changeMark(kind kube.ChangeKind) string {
+	if kind == "" {
+		return "="
+	}
+	return string(kind)
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestHandleEditMerge verifies that the conflicts of an edit merged with changes
+// made on the server are settled one by one before the merged update is previewed.
+func TestHandleEditMerge(t *testing.T) {
+	secret := func(version, password string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", ResourceVersion: version},
+			Data:       map[string][]byte{"password": []byte(password)},
+		}
+	}
+	clientset := fake.NewSimpleClientset(secret("2", "theirs"))
+	merge, err := kube.MergeEdit(clientset, kube.Mutation{Before: secret("1", "base"), After: secret("1", "mine")})
+	if err != nil || merge == nil {
+		t.Fatalf("Expected a merge, but got error: %v", err)
+	}
+	m := NewModel(clientset, "default", Options{})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	m, _ = m.handleEditMerge(editMergeMsg{merge: merge, pending: merge.Conflicts(), title: "Update app", status: "Edited app"})
+	if m.prompt == nil || !strings.Contains(m.prompt.title, "password (mine ~, theirs ~). Keep mine or theirs for password?") {
+		t.Fatalf("Expected to be asked about password, but got %+v", m.prompt)
+	}
+
+	t.Run("should ask again for an unknown answer", func(t *testing.T) {
+		if msg := m.prompt.onSubmit("both")().(editMergeMsg); len(msg.pending) != 1 {
+			t.Errorf("Expected password to be still pending, but got %v", msg.pending)
+		}
+	})
+	t.Run("should preview the merged update once settled", func(t *testing.T) {
+		msg := m.prompt.onSubmit("theirs")().(editMergeMsg)
+		m, cmd := m.handleEditMerge(msg)
+		if cmd == nil || !strings.Contains(m.status, "changed on the server") {
+			t.Fatalf("Expected the merged update to be previewed, but got status %q", m.status)
+		}
+		if preview, ok := cmd().(mutationPreviewMsg); !ok || len(preview.changes) != 0 {
+			t.Errorf("Expected no changes once their password is kept, but got %+v", preview)
+		}
+	})
+}
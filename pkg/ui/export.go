@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// writeSecretFilesCmd writes the keys of the referenced secrets as files. A single
+// secret is written straight into dir; several are written into one subdirectory each.
+func writeSecretFilesCmd(clientset kube.Client, refs []kube.SecretRef, dir string) tea.Cmd {
+	return func() tea.Msg {
+		if len(refs) == 1 {
+			if err := kube.WriteSecretFiles(clientset, refs[0], dir); err != nil {
+				return actionDoneMsg{status: "Write failed", err: err}
+			}
+			return actionDoneMsg{status: fmt.Sprintf("Wrote the keys of %s to %s", refs[0].Name, dir)}
+		}
+		msg := runOnSecrets(refs, "Wrote files for", func(ref kube.SecretRef) error {
+			return kube.WriteSecretFiles(clientset, ref, filepath.Join(dir, ref.Name))
+		})
+		msg.refresh = false
+		return msg
+	}
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/sahilm/fuzzy"
+)
+
+// itemFilter finds the secrets matching the search pattern, as list items. The
+// secrets are converted to list items once per list rather than on every
+// keystroke, and a pattern that extends the previous one is only matched against
+// the previous matches, so that typing stays fast with tens of thousands of
+// secrets. The list itself only renders the rows of the current page.
+type itemFilter struct {
+	items   kube.ItemSource
+	boxed   []list.Item // The secrets as list items, in the order of items.
+	pattern string
+	matches []int // Indices in items of the secrets matching pattern, best first; nil without a pattern.
+}
+
+// newItemFilter prepares the filtering of a list of secrets.
+func newItemFilter(items kube.ItemSource) *itemFilter {
+	boxed := make([]list.Item, len(items))
+	for i, it := range items {
+		boxed[i] = it
+	}
+	return &itemFilter{items: items, boxed: boxed}
+}
+
+// filter returns the secrets matching pattern, best matches first, or every
+// secret without a pattern.
+func (f *itemFilter) filter(pattern string) []list.Item {
+	if pattern == "" {
+		f.pattern, f.matches = "", nil
+		return f.boxed
+	}
+	var source fuzzy.Source = f.items
+	var candidates []int
+	// Fuzzy matching is a subsequence match: what does not match a pattern does
+	// not match any pattern that extends it. The candidates keep the list order,
+	// so that equal scores rank as in a search of the whole list.
+	if f.matches != nil && strings.HasPrefix(pattern, f.pattern) {
+		candidates = slices.Sorted(slices.Values(f.matches))
+		source = candidateSource{items: f.items, indices: candidates}
+	}
+	found := fuzzy.FindFrom(pattern, source)
+	matches := make([]int, len(found))
+	items := make([]list.Item, len(found))
+	for i, match := range found {
+		index := match.Index
+		if candidates != nil {
+			index = candidates[index]
+		}
+		matches[i], items[i] = index, f.boxed[index]
+	}
+	f.pattern, f.matches = pattern, matches
+	return items
+}
+
+// candidateSource is the subset of the secrets that the fuzzy matcher searches.
+type candidateSource struct {
+	items   kube.ItemSource
+	indices []int
+}
+
+// String returns the name of the i-th candidate.
+func (s candidateSource) String(i int) string { return s.items[s.indices[i]].Name }
+
+// Len returns the number of candidates.
+func (s candidateSource) Len() int { return len(s.indices) }
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// TestItemFilter verifies that narrowing the search pattern finds the same secrets
+// as searching the whole list.
+func TestItemFilter(t *testing.T) {
+	items := make(kube.ItemSource, 50000)
+	for i := range items {
+		items[i] = kube.Item{Name: fmt.Sprintf("app-%05d-%s", i, []string{"db", "api", "tls"}[i%3]), Namespace: "default"}
+	}
+	names := func(listItems []list.Item) []string {
+		out := make([]string, len(listItems))
+		for i, it := range listItems {
+			out[i] = it.(kube.Item).Name
+		}
+		return out
+	}
+	filter := newItemFilter(items)
+
+	t.Run("should list every secret without a pattern", func(t *testing.T) {
+		if got := filter.filter(""); len(got) != len(items) {
+			t.Errorf("Expected %d secrets, but got %d", len(items), len(got))
+		}
+	})
+
+	t.Run("should narrow the previous matches as the pattern grows", func(t *testing.T) {
+		for _, pattern := range []string{"a", "ap", "api", "api9", "api99", "api"} {
+			got := names(filter.filter(pattern))
+			want := names(newItemFilter(items).filter(pattern))
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Fatalf("Expected the same matches for %q as a full search, but got %d instead of %d", pattern, len(got), len(want))
+			}
+		}
+	})
+
+	t.Run("should find nothing once nothing matches", func(t *testing.T) {
+		filter.filter("xyz")
+		if got := filter.filter("xyzw"); len(got) != 0 {
+			t.Errorf("Expected no match, but got %v", names(got))
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// generatorHint lists the generators for prompts.
+var generatorHint = strings.Join(kube.GeneratorKinds, ", ")
+
+// prepareGenerateCmd previews setting keys of a secret to generated values with a
+// server-side dry run. The keys are given as key=kind[:length], separated by spaces.
+func prepareGenerateCmd(clientset kube.Client, ref kube.SecretRef, input string) tea.Cmd {
+	return func() tea.Msg {
+		mu, err := kube.PrepareGenerate(clientset, ref, strings.Fields(input))
+		if err != nil {
+			return actionDoneMsg{status: "Generate failed", err: err}
+		}
+		title := kube.UpdateTitle(clientset, "Generate values in", ref)
+		return previewUpdate(clientset, mu, title, "Generated values in "+ref.Name)
+	}
+}
+
+// createGenericSecretCmd previews the creation of an Opaque secret with one key
+// set to a generated value.
+func createGenericSecretCmd(clientset kube.Client, namespace, name, key, spec string) tea.Cmd {
+	return func() tea.Msg {
+		g, err := kube.ParseGenerator(spec)
+		if err != nil {
+			return actionDoneMsg{status: "Create failed", err: err}
+		}
+		data, err := g.Generate(key)
+		if err != nil {
+			return actionDoneMsg{status: "Create failed", err: err}
+		}
+		mu := kube.Mutation{After: kube.NewSecret(namespace, name, corev1.SecretTypeOpaque, data)}
+		return previewMutation(clientset, mu, "Create "+name, applyMutationCmd(clientset, mu, "Created "+name))
+	}
+}
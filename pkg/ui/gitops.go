@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+// gitopsSourceLoadedMsg is sent once the repository of a GitOps manager has been
+// looked up.
+type gitopsSourceLoadedMsg struct {
+	manager string // The manager, as described by its String method.
+	source  string // Empty if it could not be found.
+}
+
+// loadGitOpsSourceCmd looks up the repository a GitOps manager applies its secrets
+// from, for the detail pane. Lookup failures are not reported: the pane simply
+// shows no repository.
+func loadGitOpsSourceCmd(dyn dynamic.Interface, manager kube.GitOpsManager) tea.Cmd {
+	return func() tea.Msg {
+		source, _ := kube.FindGitOpsSource(dyn, manager)
+		return gitopsSourceLoadedMsg{manager: manager.String(), source: source}
+	}
+}
+
+// handleGitOpsSourceLoaded shows the repository of the manager of the highlighted
+// secret above its data.
+func (m Model) handleGitOpsSourceLoaded(msg gitopsSourceLoadedMsg) Model {
+	m.gitopsSources[msg.manager] = msg.source
+	m.refreshSecretData()
+	return m
+}
+
+// formatGitOpsManager renders the GitOps object managing a secret and its
+// repository, or nothing if no tool manages it.
+func (m *Model) formatGitOpsManager(secret *corev1.Secret) string {
+	if secret == nil {
+		return ""
+	}
+	manager, managed := kube.GitOpsManagerOf(secret)
+	if !managed {
+		return ""
+	}
+	lines := []string{changedStyle.Render("⚠ Managed by " + manager.String())}
+	if source := m.gitopsSources[manager.String()]; source != "" {
+		lines = append(lines, "Source: "+source)
+	}
+	consequence := manager.DriftConsequence()
+	lines = append(lines, NoteStyle.Render(strings.ToUpper(consequence[:1])+consequence[1:]+"."))
+	return strings.Join(lines, "\n") + "\n\n"
+}
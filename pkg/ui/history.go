@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// historyTimeLayout is how the times of history entries are shown.
+const historyTimeLayout = "2006-01-02 15:04:05"
+
+// historyStoppedMsg is sent when the changes to secrets can no longer be recorded.
+type historyStoppedMsg struct {
+	err error
+}
+
+// watchHistoryCmd records the changes to the secrets of a namespace in the history
+// until ctx is done.
+func watchHistoryCmd(ctx context.Context, store *kube.HistoryStore, clientset kube.Client, namespace string) tea.Cmd {
+	return func() tea.Msg {
+		if err := store.Watch(ctx, clientset, namespace); err != nil {
+			return historyStoppedMsg{err: err}
+		}
+		return nil
+	}
+}
+
+// restartHistory stops recording the changes of the previous namespace and starts
+// recording those of the current one.
+func (m *Model) restartHistory() tea.Cmd {
+	if m.history == nil {
+		return nil
+	}
+	if m.historyStop != nil {
+		m.historyStop()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.historyStop = cancel
+	namespace := m.namespace
+	if m.allNamespaces {
+		namespace = ""
+	}
+	return watchHistoryCmd(ctx, m.history, m.clientset, namespace)
+}
+
+// toggleHistory switches the data pane between the values of the highlighted
+// secret and its recorded history.
+func (m Model) toggleHistory() Model {
+	if m.history == nil {
+		m.status, m.statusErr = "No history is recorded: enable history in the config file", true
+		return m
+	}
+	m.showHistory, m.showMetadata = !m.showHistory, false
+	m.refreshSecretData()
+	return m
+}
+
+// formatHistory renders the recorded history of the highlighted secret, newest
+// first, with the keys each entry added, changed, or removed.
+func (m *Model) formatHistory() string {
+	entries, err := m.history.Load(m.highlightedItem.Ref())
+	if err != nil {
+		return errorStyle.Render(fmt.Sprintf("Cannot read the history: %v", err)) + "\n"
+	}
+	if len(entries) == 0 {
+		return NoteStyle.Render("No changes recorded yet. Changes are recorded while kds runs.") + "\n"
+	}
+	var b strings.Builder
+	b.WriteString(NoteStyle.Render("History, newest first (h: back to values)") + "\n")
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		changes := kube.HistoryChanges(entries, i)
+		fmt.Fprintf(&b, "%s  %s  %s\n", entry.Time.Local().Format(historyTimeLayout), entry.Event, NoteStyle.Render(kube.SummarizeDataDiff(changes)))
+		b.WriteString(RenderDataDiff(changes))
+	}
+	return b.String()
+}
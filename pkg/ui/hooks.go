@@ -0,0 +1,206 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Inputs of custom actions: what they receive on stdin.
+const (
+	actionInputSecret = "secret" // The data of the secret as a JSON object of base64 values.
+	actionInputValue  = "value"  // The value of one key, asked for when the action runs.
+	actionInputNone   = "none"
+)
+
+// defaultActionTimeout bounds a custom action that sets no timeout of its own.
+const defaultActionTimeout = 30 * time.Second
+
+// ActionConfig declares a custom action on the highlighted secret, run from the
+// command palette or with its own key.
+type ActionConfig struct {
+	Name string `json:"name"`
+	// Key optionally binds the action to a key, e.g. "ctrl+o".
+	Key string `json:"key,omitempty"`
+	// Command is the program and its arguments, each a Go template over the
+	// fields of actionTarget, e.g. "{{.Namespace}}/{{.Secret}}".
+	Command []string `json:"command"`
+	// Input is secret (default), value, or none. With secret, the action gets the
+	// data of the secret as a JSON object with base64 values, like the data field
+	// of a Secret, so that binary values reach it intact. With value, it gets the
+	// raw value of the key.
+	Input   string           `json:"input,omitempty"`
+	Confirm bool             `json:"confirm,omitempty"`
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Action is a compiled ActionConfig.
+type Action struct {
+	name    string
+	key     string
+	command []*template.Template
+	input   string
+	confirm bool
+	timeout time.Duration
+}
+
+// actionTarget is what the command templates of a custom action can refer to.
+type actionTarget struct {
+	Namespace string
+	Secret    string
+	Type      string
+	Key       string // Only set for actions with the value input.
+}
+
+// CompileActions validates the custom actions of the configuration. Keys that are
+// already bound to a built-in action are rejected.
+func CompileActions(configs []ActionConfig) ([]Action, error) {
+	actions := make([]Action, 0, len(configs))
+	for _, c := range configs {
+		if c.Name == "" || len(c.Command) == 0 {
+			return nil, fmt.Errorf("action '%s' needs a name and a command", c.Name)
+		}
+		if builtin, ok := builtinActionForKey(c.Key); ok {
+			return nil, fmt.Errorf("action '%s' uses key '%s', which is bound to %s", c.Name, c.Key, builtin)
+		}
+		a := Action{name: c.Name, key: c.Key, input: c.Input, confirm: c.Confirm, timeout: defaultActionTimeout}
+		switch a.input {
+		case "":
+			a.input = actionInputSecret
+		case actionInputSecret, actionInputValue, actionInputNone:
+		default:
+			return nil, fmt.Errorf("action '%s' has invalid input '%s': use secret, value, or none", c.Name, c.Input)
+		}
+		for _, arg := range c.Command {
+			tmpl, err := template.New(c.Name).Option("missingkey=error").Parse(arg)
+			if err != nil {
+				return nil, fmt.Errorf("action '%s' has an invalid command: %w", c.Name, err)
+			}
+			a.command = append(a.command, tmpl)
+		}
+		if c.Timeout != nil {
+			a.timeout = c.Timeout.Duration
+		}
+		actions = append(actions, a)
+	}
+	return actions, nil
+}
+
+// run executes the action on a secret and returns the last line of its output.
+// The target is also passed as KDS_NAMESPACE, KDS_SECRET, KDS_TYPE, and KDS_KEY.
+func (a Action) run(secret *corev1.Secret, key string) (string, error) {
+	target := actionTarget{Namespace: secret.Namespace, Secret: secret.Name, Type: string(secret.Type), Key: key}
+	args := make([]string, 0, len(a.command))
+	for _, tmpl := range a.command {
+		var arg strings.Builder
+		if err := tmpl.Execute(&arg, target); err != nil {
+			return "", err
+		}
+		args = append(args, arg.String())
+	}
+	stdin, err := a.stdin(secret, key)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec // Actions are declared by the user.
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Env = append(os.Environ(),
+		"KDS_NAMESPACE="+target.Namespace,
+		"KDS_SECRET="+target.Secret,
+		"KDS_TYPE="+target.Type,
+		"KDS_KEY="+target.Key,
+	)
+	out, err := cmd.CombinedOutput()
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	last := lines[len(lines)-1]
+	if err != nil && last != "" {
+		return "", fmt.Errorf("%w: %s", err, last)
+	}
+	return last, err
+}
+
+// stdin returns what the action receives on stdin.
+func (a Action) stdin(secret *corev1.Secret, key string) ([]byte, error) {
+	switch a.input {
+	case actionInputSecret:
+		// Values are encoded in base64 by encoding/json, as binary ones would not
+		// survive as JSON strings.
+		return json.Marshal(secret.Data)
+	case actionInputValue:
+		value, ok := secret.Data[key]
+		if !ok {
+			return nil, fmt.Errorf("key '%s' not found in secret '%s'", key, secret.Name)
+		}
+		return value, nil
+	}
+	return nil, nil
+}
+
+// runActionCmd runs a custom action in the background and reports its outcome.
+func runActionCmd(a Action, secret *corev1.Secret, key string) tea.Cmd {
+	return func() tea.Msg {
+		out, err := a.run(secret, key)
+		if err != nil {
+			return actionDoneMsg{status: a.name + " failed", err: err}
+		}
+		status := a.name + " done"
+		if out != "" {
+			status += ": " + out
+		}
+		return actionDoneMsg{status: status}
+	}
+}
+
+// startAction runs a custom action on the highlighted secret, after asking for the
+// key when the action takes a value, and for confirmation when it is configured.
+func (m Model) startAction(a Action) (Model, tea.Cmd) {
+	secret := m.secretObjects[m.highlightedKey()]
+	if secret == nil {
+		m.status, m.statusErr = m.highlightedItem.Name+" is not loaded yet", true
+		return m, nil
+	}
+	// Actions that receive values of a protected namespace always ask first.
+	protected := a.input != actionInputNone && m.protected.Protects(secret.Namespace)
+	run := func(key string) tea.Cmd {
+		if !a.confirm && !protected {
+			return runActionCmd(a, secret, key)
+		}
+		question := fmt.Sprintf("Run %s on %s?", a.name, secret.Name)
+		if protected {
+			question = fmt.Sprintf("Run %s on %s in protected namespace %s?", a.name, secret.Name, secret.Namespace)
+		}
+		next := newConfirmPrompt(question, func() tea.Cmd {
+			return runActionCmd(a, secret, key)
+		})
+		return func() tea.Msg { return showPromptMsg{prompt: next} }
+	}
+	if a.input != actionInputValue {
+		return m, run("")
+	}
+	keys := kube.SortedKeys(secret.Data)
+	if len(keys) == 0 {
+		m.status, m.statusErr = secret.Name+" has no keys", true
+		return m, nil
+	}
+	m.prompt = newChoicePrompt(fmt.Sprintf("%s with the value of (default %s):", a.name, keys[0]), "", keys, func(key string) tea.Cmd {
+		if key == "" {
+			key = keys[0]
+		}
+		return run(key)
+	})
+	return m, nil
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestCompileActions verifies that invalid custom actions are rejected.
+func TestCompileActions(t *testing.T) {
+	invalid := []ActionConfig{
+		{Command: []string{"true"}},
+		{Name: "send"},
+		{Name: "send", Key: "e", Command: []string{"true"}},
+		{Name: "send", Input: "stdin", Command: []string{"true"}},
+		{Name: "send", Command: []string{"{{.Secret"}},
+	}
+	for _, c := range invalid {
+		if _, err := CompileActions([]ActionConfig{c}); err == nil {
+			t.Errorf("Expected an error for %+v, but got none", c)
+		}
+	}
+}
+
+// TestRunAction verifies that custom actions receive the secret through their
+// arguments, environment, and stdin.
+func TestRunAction(t *testing.T) {
+	secret := &corev1.Secret{Type: corev1.SecretTypeOpaque, Data: map[string][]byte{"token": []byte("s3cr3t")}}
+	secret.Namespace, secret.Name = "ci", "deploy"
+
+	cases := []struct {
+		config   ActionConfig
+		key      string
+		expected string
+	}{
+		{ActionConfig{Name: "args", Command: []string{"echo", "{{.Namespace}}/{{.Secret}}", "{{.Type}}"}}, "", "ci/deploy Opaque"},
+		{ActionConfig{Name: "env", Input: "none", Command: []string{"sh", "-c", `echo "$KDS_SECRET:$KDS_KEY"`}}, "token", "deploy:token"},
+		{ActionConfig{Name: "json", Command: []string{"cat"}}, "", `{"token":"czNjcjN0"}`},
+		{ActionConfig{Name: "value", Input: "value", Command: []string{"sh", "-c", "echo first; cat"}}, "token", "s3cr3t"},
+	}
+	for _, c := range cases {
+		actions, err := CompileActions([]ActionConfig{c.config})
+		if err != nil {
+			t.Fatalf("Expected no error for %s, but got %v", c.config.Name, err)
+		}
+		out, err := actions[0].run(secret, c.key)
+		if err != nil || out != c.expected {
+			t.Errorf("Expected %s to print '%s', but got '%s' (%v)", c.config.Name, c.expected, out, err)
+		}
+	}
+
+	t.Run("should pass binary values intact", func(t *testing.T) {
+		binary := &corev1.Secret{Data: map[string][]byte{"keystore": {0xfe, 0xed, 0xfe, 0xed, 0x00, 0xff}}}
+		actions, err := CompileActions([]ActionConfig{{Name: "json", Command: []string{"cat"}}})
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		stdin, err := actions[0].stdin(binary, "")
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		var data map[string][]byte
+		if err := json.Unmarshal(stdin, &data); err != nil || !bytes.Equal(data["keystore"], binary.Data["keystore"]) {
+			t.Errorf("Expected the keystore to be passed intact, but got %s (%v)", stdin, err)
+		}
+	})
+	t.Run("should report the output of failed actions", func(t *testing.T) {
+		actions, err := CompileActions([]ActionConfig{{Name: "fail", Command: []string{"sh", "-c", "echo vault sealed; exit 2"}}})
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if _, err := actions[0].run(secret, ""); err == nil || !strings.Contains(err.Error(), "vault sealed") {
+			t.Errorf("Expected the output in the error, but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// helmReleaseType is the type of the secrets in which Helm stores its releases.
+const helmReleaseType corev1.SecretType = "helm.sh/release.v1"
+
+// defaultTypeIcon is the icon of the secret types without one of their own.
+const defaultTypeIcon = "\uf15b" // nf-fa-file
+
+// typeIcons are the nerd-font icons in front of the names of the secrets of each
+// type, when Options.Icons is set.
+var typeIcons = map[corev1.SecretType]string{
+	corev1.SecretTypeOpaque:              "\uf084",     // nf-fa-key
+	corev1.SecretTypeTLS:                 "\uf023",     // nf-fa-lock
+	corev1.SecretTypeDockerConfigJson:    "\uf308",     // nf-linux-docker
+	corev1.SecretTypeDockercfg:           "\uf308",     // nf-linux-docker
+	corev1.SecretTypeServiceAccountToken: "\uf007",     // nf-fa-user
+	corev1.SecretTypeBasicAuth:           "\uf2c2",     // nf-fa-id_card
+	corev1.SecretTypeSSHAuth:             "\uf489",     // nf-oct-terminal
+	corev1.SecretTypeBootstrapToken:      "\uf1e6",     // nf-fa-plug
+	helmReleaseType:                      "\U000f0833", // nf-md-ship_wheel
+}
+
+// typeColors are the colors of the names of the secrets of each type, when
+// Options.TypeColors is set. Opaque secrets keep the default color.
+var typeColors = map[corev1.SecretType]lipgloss.Color{
+	corev1.SecretTypeTLS:                 lipgloss.Color("#2ECC40"),
+	corev1.SecretTypeDockerConfigJson:    lipgloss.Color("#39CCCC"),
+	corev1.SecretTypeDockercfg:           lipgloss.Color("#39CCCC"),
+	corev1.SecretTypeServiceAccountToken: lipgloss.Color("#FF851B"),
+	corev1.SecretTypeBasicAuth:           lipgloss.Color("#F012BE"),
+	corev1.SecretTypeSSHAuth:             lipgloss.Color("#B10DC9"),
+	corev1.SecretTypeBootstrapToken:      lipgloss.Color("#01FF70"),
+	helmReleaseType:                      lipgloss.Color("#7FDBFF"),
+}
+
+// typeIcon returns the nerd-font icon of a secret type.
+func typeIcon(secretType corev1.SecretType) string {
+	if icon, ok := typeIcons[secretType]; ok {
+		return icon
+	}
+	return defaultTypeIcon
+}
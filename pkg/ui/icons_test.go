@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestTypeIcons verifies that list rows get the icon of their secret type only
+// when icons are enabled.
+func TestTypeIcons(t *testing.T) {
+	items := kube.ItemSource{
+		{Name: "web-tls", Namespace: "default", SecretType: corev1.SecretTypeTLS},
+		{Name: "custom", Namespace: "default", SecretType: "example.com/custom"},
+	}
+	newModel := func(opts Options) Model {
+		m := NewModel(fake.NewSimpleClientset(), "default", opts)
+		m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 200, Height: 40})
+		next, _ := m.Update(items)
+		return next.(Model)
+	}
+
+	t.Run("should show the icon of each type", func(t *testing.T) {
+		view := newModel(Options{Icons: true}).list.View()
+		for _, want := range []string{typeIcons[corev1.SecretTypeTLS] + " web-tls", defaultTypeIcon + " custom"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("Expected %q in the list, but got:\n%s", want, view)
+			}
+		}
+	})
+
+	t.Run("should not show icons by default", func(t *testing.T) {
+		if view := newModel(Options{}).list.View(); strings.Contains(view, typeIcons[corev1.SecretTypeTLS]) {
+			t.Errorf("Expected no icons, but got:\n%s", view)
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// setImmutableCmd sets the immutable flag of a secret from the TUI.
+func setImmutableCmd(clientset kube.Client, ref kube.SecretRef, immutable bool) tea.Cmd {
+	return undoable(clientset, []kube.SecretRef{ref}, func() tea.Msg {
+		if err := kube.SetImmutable(clientset, ref, immutable); err != nil {
+			return actionDoneMsg{status: "Update failed", err: err, refresh: true}
+		}
+		return actionDoneMsg{status: fmt.Sprintf("%s is now %s", ref.Name, kube.Mutability(immutable)), refresh: true}
+	})
+}
@@ -0,0 +1,18 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// prepareImportCmd previews a .env import started from the TUI with a server-side dry run.
+func prepareImportCmd(clientset kube.Client, ref kube.SecretRef, path string) tea.Cmd {
+	return func() tea.Msg {
+		mu, err := kube.PrepareImport(clientset, ref, path, false)
+		if err != nil {
+			return actionDoneMsg{status: "Import failed", err: err}
+		}
+		title := kube.UpdateTitle(clientset, "Import into", ref)
+		return previewUpdate(clientset, mu, title, "Imported into "+ref.Name)
+	}
+}
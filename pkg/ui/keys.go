@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keysKey opens the list of the keys of the data pane.
+const keysKey = "?"
+
+// dataPaneKeys are the keys of the data pane, listed by keysKey since the help
+// line only has room for the most used ones.
+var dataPaneKeys = []struct{ key, description string }{
+	{"n", "new secret"},
+	{"e", "edit"},
+	{"I", "import .env"},
+	{"G", "generate"},
+	{"T", "rotate"},
+	{"h", "history"},
+	{"M", "metadata"},
+	{"i", "immutable"},
+	{"L", "reloader"},
+	{"r", "renew certificate"},
+	{"K", "write kubeconfig"},
+	{"x", "export"},
+	{"w", "write files"},
+	{"d", "delete"},
+	{"R", "rename"},
+	{"l", "label"},
+	{"a", "annotate"},
+	{"p", "copy"},
+	{"c", "checksums"},
+	{"o", "TOTP codes"},
+	{"v", "reveal"},
+	{"F", "full values"},
+	{"u", "undo"},
+	{"g", "goto…"},
+	{"y", "yank…"},
+	{"1–9", "jump"},
+	{":", "palette"},
+	{"ctrl+n", "namespace"},
+	{"tab", "switch pane"},
+	{"q", "quit"},
+}
+
+// handleKeysKey opens the list of keys with keysKey in the data pane, and closes
+// it with any key. It reports whether the key was consumed.
+func (m Model) handleKeysKey(msg tea.KeyMsg) (Model, bool) {
+	if m.showKeys {
+		m.showKeys = false
+		return m, true
+	}
+	if m.focus != rightPane || msg.String() != keysKey || m.pendingChord != nil {
+		return m, false
+	}
+	m.showKeys = true
+	return m, true
+}
+
+// viewKeys renders the keys of the data pane, which-key style.
+func (m *Model) viewKeys() string {
+	lines := []string{TitleStyle.Render("keys")}
+	for _, k := range dataPaneKeys {
+		lines = append(lines, lipgloss.NewStyle().Foreground(focusedColor).Bold(true).Render(fmt.Sprintf("%6s", k.key))+"  "+k.description)
+	}
+	lines = append(lines, "", NoteStyle.Render("any key: close"))
+	return modalStyle.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/diskmanti/kds/pkg/kube"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestKeys verifies that ? lists the keys of the data pane until any key is
+// pressed, and that the help line is wrapped to the width of the terminal.
+func TestKeys(t *testing.T) {
+	m := NewModel(fake.NewSimpleClientset(), "default", Options{})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 60, Height: 40})
+	m, _ = m.handleSecretsLoaded(kube.ItemSource{{Name: "app", Namespace: "default"}})
+	m.focus = rightPane
+	press := func(m Model, key string) Model {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		return next.(Model)
+	}
+
+	t.Run("should list the keys of the data pane", func(t *testing.T) {
+		m := press(m, "?")
+		view := m.viewRightPane()
+		if !strings.Contains(view, "write kubeconfig") || !strings.Contains(view, "TOTP codes") {
+			t.Errorf("Expected the keys of the data pane, but got:\n%s", view)
+		}
+	})
+	t.Run("should close the list with any key", func(t *testing.T) {
+		m := press(press(m, "?"), "q")
+		if m.showKeys || strings.Contains(m.viewRightPane(), "write kubeconfig") {
+			t.Errorf("Expected the list of keys to be closed, but got:\n%s", m.viewRightPane())
+		}
+	})
+	t.Run("should size the panes with the wrapped help line", func(t *testing.T) {
+		m, _ := m.handleWindowSize(tea.WindowSizeMsg{Width: 60, Height: 40})
+		help := m.viewHelp()
+		if lipgloss.Width(help) > 60 || lipgloss.Height(help) < 2 {
+			t.Fatalf("Expected the help line wrapped to 60 columns, but got:\n%s", help)
+		}
+		if want := 40 - lipgloss.Height(help) - rightPaneStyle.GetVerticalPadding(); m.viewport.Height != want {
+			t.Errorf("Expected a data pane of %d lines, but got %d", want, m.viewport.Height)
+		}
+	})
+}
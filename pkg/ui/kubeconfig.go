@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// DescribeKubeconfig lists the clusters, contexts, and users of a kubeconfig, with
+// the kind of credentials of each user but never their values.
+func DescribeKubeconfig(config *clientcmdapi.Config) []string {
+	current := config.CurrentContext
+	if current == "" {
+		current = "none"
+	}
+	lines := []string{"Kubeconfig (current context: " + current + ")"}
+	for _, name := range kube.SortedKeys(config.Clusters) {
+		cluster := config.Clusters[name]
+		line := fmt.Sprintf("  Cluster %s: %s", name, cluster.Server)
+		if cluster.InsecureSkipTLSVerify {
+			line += " " + changedStyle.Render("(TLS verification disabled)")
+		}
+		lines = append(lines, line)
+	}
+	for _, name := range kube.SortedKeys(config.Contexts) {
+		context := config.Contexts[name]
+		line := fmt.Sprintf("  Context %s: cluster %s, user %s", name, context.Cluster, context.AuthInfo)
+		if context.Namespace != "" {
+			line += ", namespace " + context.Namespace
+		}
+		lines = append(lines, line)
+	}
+	for _, name := range kube.SortedKeys(config.AuthInfos) {
+		lines = append(lines, fmt.Sprintf("  User %s: %s", name, describeCredentials(config.AuthInfos[name])))
+	}
+	return lines
+}
+
+// describeCredentials names the credentials of a kubeconfig user, masking secrets.
+func describeCredentials(user *clientcmdapi.AuthInfo) string {
+	var credentials []string
+	if user.Token != "" {
+		credentials = append(credentials, "token "+kube.MaskValue([]byte(user.Token)))
+	}
+	if user.TokenFile != "" {
+		credentials = append(credentials, "token file "+user.TokenFile)
+	}
+	if len(user.ClientCertificateData) > 0 || user.ClientCertificate != "" {
+		credentials = append(credentials, "client certificate")
+	}
+	if user.Username != "" {
+		credentials = append(credentials, fmt.Sprintf("basic auth as %s, password %s", user.Username, kube.MaskValue([]byte(user.Password))))
+	}
+	if user.Exec != nil {
+		credentials = append(credentials, "exec "+user.Exec.Command)
+	}
+	if user.AuthProvider != nil {
+		credentials = append(credentials, "auth provider "+user.AuthProvider.Name)
+	}
+	if len(credentials) == 0 {
+		return "no credentials"
+	}
+	return strings.Join(credentials, ", ")
+}
+
+// writeKubeconfigCmd writes the kubeconfig of a secret to a temporary file for the TUI.
+func writeKubeconfigCmd(secret *corev1.Secret) tea.Cmd {
+	return func() tea.Msg {
+		key, _, err := kube.FindKubeconfig(secret, "")
+		if err != nil {
+			return actionDoneMsg{status: "Writing kubeconfig failed", err: err}
+		}
+		path, err := kube.WriteKubeconfig(secret.Data[key])
+		if err != nil {
+			return actionDoneMsg{status: "Writing kubeconfig failed", err: err}
+		}
+		return actionDoneMsg{status: "export KUBECONFIG=" + path}
+	}
+}
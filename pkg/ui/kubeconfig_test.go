@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: admin@prod
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+    insecure-skip-tls-verify: true
+contexts:
+- name: admin@prod
+  context:
+    cluster: prod
+    user: admin
+    namespace: kube-system
+users:
+- name: admin
+  user:
+    token: eyJhbGciOiJSUzI1NiJ9.secret-token
+- name: ci
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: aws
+`
+
+// TestDescribeKubeconfig verifies that kubeconfigs are recognized and summarized
+// without their credentials.
+func TestDescribeKubeconfig(t *testing.T) {
+	config, ok := kube.ParseKubeconfig([]byte(testKubeconfig))
+	if !ok {
+		t.Fatal("Expected the kubeconfig to be recognized")
+	}
+	out := strings.Join(DescribeKubeconfig(config), "\n")
+	for _, expected := range []string{
+		"current context: admin@prod",
+		"Cluster prod: https://prod.example.com:6443 (TLS verification disabled)",
+		"Context admin@prod: cluster prod, user admin, namespace kube-system",
+		"User admin: token ******** (33 bytes)",
+		"User ci: exec aws",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected '%s', but got:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out, "secret-token") {
+		t.Errorf("Expected the token to be masked, but got:\n%s", out)
+	}
+
+	t.Run("should not recognize other YAML", func(t *testing.T) {
+		for _, value := range []string{"password: hunter2", "clusters: [", "kind: Config\n"} {
+			if _, ok := kube.ParseKubeconfig([]byte(value)); ok {
+				t.Errorf("Expected %q not to be recognized", value)
+			}
+		}
+	})
+}
+
+// TestFindKubeconfig verifies the lookup of the key holding a kubeconfig.
+func TestFindKubeconfig(t *testing.T) {
+	secret := &corev1.Secret{Data: map[string][]byte{"README": []byte("hello"), "value": []byte(testKubeconfig)}}
+	secret.Name = "cluster-admin"
+
+	if key, _, err := kube.FindKubeconfig(secret, ""); err != nil || key != "value" {
+		t.Errorf("Expected key 'value', but got '%s' (%v)", key, err)
+	}
+	if _, _, err := kube.FindKubeconfig(secret, "README"); err == nil {
+		t.Error("Expected an error for a key without a kubeconfig, but got none")
+	}
+	if _, _, err := kube.FindKubeconfig(&corev1.Secret{}, ""); err == nil {
+		t.Error("Expected an error for a secret without a kubeconfig, but got none")
+	}
+
+	t.Run("should write the kubeconfig to a private file", func(t *testing.T) {
+		t.Setenv("TMPDIR", t.TempDir())
+		msg, ok := writeKubeconfigCmd(secret)().(actionDoneMsg)
+		if !ok || msg.err != nil {
+			t.Fatalf("Expected the kubeconfig to be written, but got %+v", msg)
+		}
+		path := strings.TrimPrefix(msg.status, "export KUBECONFIG=")
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Expected the file to exist, but got %v", err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("Expected permissions 0600, but got %o", info.Mode().Perm())
+		}
+	})
+}
+
+// TestFormatKubeconfigValue verifies that the data pane summarizes kubeconfigs
+// until values are revealed.
+func TestFormatKubeconfigValue(t *testing.T) {
+	m := NewModel(fake.NewSimpleClientset(), "default", Options{})
+	m.viewport.Width = 200
+	m.highlightedItem = kube.Item{Name: "cluster-admin", Namespace: "default"}
+	m.secretObjects["default/cluster-admin"] = &corev1.Secret{Data: map[string][]byte{"config": []byte(testKubeconfig)}}
+	data := map[string]string{"config": testKubeconfig}
+
+	if out := m.formatSecretData(data); strings.Contains(out, "secret-token") || !strings.Contains(out, "Cluster prod") {
+		t.Errorf("Expected a summary, but got:\n%s", out)
+	}
+	m.reveal = true
+	if out := m.formatSecretData(data); !strings.Contains(out, "secret-token") {
+		t.Errorf("Expected the raw kubeconfig, but got:\n%s", out)
+	}
+}
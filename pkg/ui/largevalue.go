@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/muesli/reflow/wrap"
+)
+
+// largeValueSize is the size above which a value is summarized, until F loads
+// the values of the secret in full. Word wrapping takes seconds for values of
+// tens of kilobytes, which would freeze the UI each time the secret is highlighted.
+const largeValueSize = 16 * 1024
+
+// isLargeValue reports whether a value is too large to be word wrapped.
+func isLargeValue(raw []byte) bool {
+	return len(raw) > largeValueSize
+}
+
+// formatFullValue breaks a large value loaded with F into lines of the width of
+// the viewport, so that word wrapping does not have to.
+func (m *Model) formatFullValue(value string) string {
+	return "\n" + wrap.String(value, max(m.viewport.Width, 1))
+}
+
+// formatLargeValue summarizes a value too large to render at once by its size and
+// the type of its content.
+func formatLargeValue(raw []byte) string {
+	return NoteStyle.Render(fmt.Sprintf("(%s of %s: press F to load it)", kube.FormatSize(len(raw)), http.DetectContentType(raw)))
+}
+
+// toggleFullValues loads the large values of the highlighted secret in full, or
+// summarizes them again.
+func (m Model) toggleFullValues() Model {
+	key := m.highlightedKey()
+	if m.fullValues[key] {
+		delete(m.fullValues, key)
+	} else {
+		m.fullValues[key] = true
+	}
+	m.refreshSecretData()
+	return m
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestLargeValues verifies that large values are summarized until F loads them.
+func TestLargeValues(t *testing.T) {
+	blob := strings.Repeat("x", largeValueSize+1)
+	m := NewModel(fake.NewSimpleClientset(), "default", Options{})
+	m.highlightedItem = kube.Item{Name: "bundle", Namespace: "default"}
+	m.focus = rightPane
+	m.loading = false
+	m.viewport.Width = 200
+	m.secretObjects["default/bundle"] = &corev1.Secret{Data: map[string][]byte{"blob": []byte(blob), "host": []byte("db")}}
+	m.secretCache["default/bundle"] = map[string]string{"blob": blob, "host": "db"}
+
+	t.Run("should summarize large values", func(t *testing.T) {
+		out := m.formatSecretData(m.secretCache["default/bundle"])
+		if !strings.Contains(out, "blob: ("+kube.FormatSize(len(blob))+" of text/plain; charset=utf-8: press F to load it)") {
+			t.Errorf("Expected a summary of the large value, but got:\n%s", out)
+		}
+		if strings.Contains(out, blob[:100]) || !strings.Contains(out, "host: db") {
+			t.Errorf("Expected only the small value in full, but got:\n%s", out)
+		}
+	})
+
+	t.Run("should load them with F", func(t *testing.T) {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")})
+		m = next.(Model)
+		if out := m.formatSecretData(m.secretCache["default/bundle"]); strings.Contains(out, "press F") {
+			t.Errorf("Expected the large value in full, but got a summary:\n%.200s", out)
+		}
+	})
+}
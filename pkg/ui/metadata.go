@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// patchMetadataCmd applies space-separated label or annotation changes typed in
+// the TUI to the referenced secrets.
+func patchMetadataCmd(clientset kube.Client, refs []kube.SecretRef, field, input string) tea.Cmd {
+	return func() tea.Msg {
+		changes, err := kube.ParseMetadataChanges(field, strings.Fields(input))
+		if err != nil {
+			return actionDoneMsg{status: "Update of " + field + " failed", err: err}
+		}
+		return undoable(clientset, refs, func() tea.Msg {
+			return runOnSecrets(refs, "Updated "+field+" of", func(ref kube.SecretRef) error {
+				return kube.PatchMetadata(clientset, ref, field, changes)
+			})
+		})()
+	}
+}
+
+// toggleMetadata switches the data pane between the values of the highlighted
+// secret and its metadata.
+func (m Model) toggleMetadata() Model {
+	m.showMetadata, m.showHistory = !m.showMetadata, false
+	m.refreshSecretData()
+	return m
+}
+
+// formatMetadata renders the labels, annotations, owners, and managers of a
+// secret, and how its data differs from what 'kubectl apply' last applied. Values
+// are never shown: annotations holding previous values of rotated keys, and the
+// last-applied configuration, are summarized instead.
+func formatMetadata(secret *corev1.Secret) string {
+	if secret == nil {
+		return NoteStyle.Render("The secret is not loaded yet.") + "\n"
+	}
+	var b strings.Builder
+	b.WriteString(NoteStyle.Render("Metadata (M: back to values)") + "\n")
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			lines = []string{NoteStyle.Render("none")}
+		}
+		b.WriteString("\n" + title + ":\n  " + strings.Join(lines, "\n  ") + "\n")
+	}
+	var labels, annotations, owners, managers []string
+	for _, key := range kube.SortedKeys(secret.Labels) {
+		labels = append(labels, key+"="+secret.Labels[key])
+	}
+	for _, key := range kube.SortedKeys(secret.Annotations) {
+		value := secret.Annotations[key]
+		switch {
+		case key == corev1.LastAppliedConfigAnnotation:
+			value = NoteStyle.Render("(compared below)")
+		case strings.HasPrefix(key, kube.PreviousValueAnnotationPrefix):
+			value = NoteStyle.Render("(previous value, hidden)")
+		}
+		annotations = append(annotations, key+"="+value)
+	}
+	for _, owner := range secret.OwnerReferences {
+		line := owner.Kind + "/" + owner.Name
+		if owner.Controller != nil && *owner.Controller {
+			line += NoteStyle.Render(" (controller)")
+		}
+		owners = append(owners, line)
+	}
+	if tool := secret.Labels[kube.ManagedByLabel]; tool != "" {
+		managers = append(managers, tool+NoteStyle.Render(" ("+kube.ManagedByLabel+")"))
+	}
+	if manager, managed := kube.GitOpsManagerOf(secret); managed {
+		managers = append(managers, manager.String())
+	}
+	for _, field := range secret.ManagedFields {
+		managers = append(managers, field.Manager+NoteStyle.Render(fmt.Sprintf(" (%s, fields)", field.Operation)))
+	}
+	section("Labels", labels)
+	section("Annotations", annotations)
+	section("Owner references", owners)
+	section("Managed by", managers)
+	b.WriteString("\nLast applied with kubectl:\n")
+	switch changes, found, err := kube.LastAppliedChanges(secret); {
+	case err != nil:
+		b.WriteString("  " + errorStyle.Render(err.Error()) + "\n")
+	case !found:
+		b.WriteString("  " + NoteStyle.Render("never") + "\n")
+	case len(changes) == 0:
+		b.WriteString("  " + NoteStyle.Render("the data matches the last-applied configuration") + "\n")
+	default:
+		b.WriteString("  " + NoteStyle.Render("the data differs ("+kube.SummarizeDataDiff(changes)+"), from applied to live:") + "\n")
+		b.WriteString(RenderDataDiff(changes))
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestFormatMetadata verifies that the metadata view shows the owners and
+// managers of a secret and its drift from the last-applied configuration,
+// without revealing values.
+func TestFormatMetadata(t *testing.T) {
+	controller := true
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db",
+			Namespace: "default",
+			Labels:    map[string]string{kube.ManagedByLabel: "Helm"},
+			Annotations: map[string]string{
+				corev1.LastAppliedConfigAnnotation:              `{"apiVersion":"v1","kind":"Secret","data":{"password":"b2xk"},"stringData":{"user":"admin"}}`,
+				kube.PreviousValueAnnotationPrefix + "password": base64.StdEncoding.EncodeToString([]byte("hunter2")),
+			},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Certificate", Name: "db-tls", Controller: &controller}},
+			ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: "kubectl-client-side-apply", Operation: metav1.ManagedFieldsOperationUpdate}},
+		},
+		Data: map[string][]byte{"password": []byte("new"), "user": []byte("admin"), "host": []byte("db")},
+	}
+	got := formatMetadata(secret)
+
+	t.Run("should show the owners and managers", func(t *testing.T) {
+		for _, want := range []string{"Certificate/db-tls", "(controller)", "Helm", "kubectl-client-side-apply"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("Expected the metadata to contain %q, but got:\n%s", want, got)
+			}
+		}
+	})
+	t.Run("should show the keys changed since the last apply", func(t *testing.T) {
+		for _, want := range []string{"+1 ~1 -0", "~ password", "+ host"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("Expected the metadata to contain %q, but got:\n%s", want, got)
+			}
+		}
+		if strings.Contains(got, "  ~ user") || strings.Contains(got, "+ user") {
+			t.Errorf("Expected user to match its stringData, but got:\n%s", got)
+		}
+	})
+	t.Run("should not reveal values", func(t *testing.T) {
+		for _, value := range []string{"b2xk", "aHVudGVyMg==", "hunter2"} {
+			if strings.Contains(got, value) {
+				t.Errorf("Expected %q not to be shown, but got:\n%s", value, got)
+			}
+		}
+	})
+}
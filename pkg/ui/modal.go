@@ -0,0 +1,23 @@
+package ui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxModalWidth is the width beyond which the text of a modal wraps.
+const maxModalWidth = 72
+
+// modalStyle frames the questions that must be answered before anything else.
+var modalStyle = lipgloss.NewStyle().Padding(1, 3).BorderStyle(lipgloss.RoundedBorder()).BorderForeground(focusedColor)
+
+// viewModal renders the open confirmation prompt in a box centered over the panes,
+// so that destructive actions all ask the same way and stand out from the status
+// line. It returns false when no confirmation is open.
+func (m *Model) viewModal(width, height int) (string, bool) {
+	if m.prompt == nil || !m.prompt.confirm {
+		return "", false
+	}
+	textWidth := max(min(width-10, maxModalWidth), 20)
+	box := modalStyle.Render(lipgloss.NewStyle().Width(textWidth).Render(m.prompt.View()))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box), true
+}
@@ -0,0 +1,1070 @@
+// Package ui is the interactive secret browser of kds, a Bubble Tea model that
+// other tools can embed:
+//
+//	m := ui.NewModel(clientset, namespace, ui.Options{})
+//	_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/muesli/reflow/wordwrap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+// --- STYLES and CONSTANTS ---
+
+// This block defines the color palette and styling for the entire TUI,
+// ensuring a consistent and professional look and feel.
+var (
+	primaryColor     = lipgloss.Color("#00BFFF")
+	focusedColor     = lipgloss.Color("#AD58B4")
+	errorColor       = lipgloss.Color("#FF4136")
+	NoteStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	TitleStyle       = lipgloss.NewStyle().Foreground(primaryColor).Bold(true).MarginBottom(1)
+	errorTitleStyle  = TitleStyle.Foreground(errorColor)
+	errorStyle       = lipgloss.NewStyle().Foreground(errorColor).Bold(true)
+	paneBaseStyle    = lipgloss.NewStyle().Padding(1, 2).BorderStyle(lipgloss.RoundedBorder())
+	leftPaneStyle    = paneBaseStyle.BorderForeground(primaryColor)
+	focusedLeftPane  = leftPaneStyle.BorderForeground(focusedColor)
+	rightPaneStyle   = paneBaseStyle.BorderForeground(primaryColor)
+	focusedRightPane = rightPaneStyle.BorderForeground(focusedColor)
+)
+
+// displayMode controls how secret values are rendered in the right-hand pane.
+type displayMode int
+
+const (
+	displayPlain     displayMode = iota // Show the decoded values.
+	displayChecksums                    // Show a SHA-256 checksum of each value instead.
+)
+
+// pane identifies which of the two UI panes is currently focused by the user.
+type pane int
+
+const (
+	leftPane pane = iota
+	rightPane
+)
+
+// --- MESSAGES ---
+// Bubble Tea applications are event-driven and communicate via messages.
+
+// secretDataLoadedMsg is sent when a secret's data has been successfully fetched.
+type secretDataLoadedMsg struct {
+	key    string // The namespace/name of the secret.
+	data   map[string]string
+	secret *corev1.Secret // The full object, for views that need more than the decoded data.
+}
+
+// secretDataErrorMsg is sent when fetching a specific secret's data fails.
+// This is a non-fatal error, allowing the UI to continue running.
+type secretDataErrorMsg struct {
+	key string // The namespace/name of the secret.
+	err error
+}
+
+// fatalErrorMsg is used for unrecoverable errors (e.g., cannot connect to Kubernetes),
+// which will cause the application to display an error and quit.
+type fatalErrorMsg struct{ err error }
+
+// Model is the main state of our Bubble Tea application.
+// It holds all the UI components and the application's current state.
+type Model struct {
+	// clientset is the Kubernetes API client (can be real or fake).
+	clientset kube.Client
+	// dynamic is the client for custom resources such as cert-manager Certificates.
+	// Features that need it are disabled when it is nil.
+	dynamic dynamic.Interface
+	// namespace is the Kubernetes namespace we are currently viewing.
+	namespace string
+
+	// --- Components ---
+	list      list.Model
+	textinput textinput.Model
+	spinner   spinner.Model
+	viewport  viewport.Model // For the scrollable right-hand pane.
+
+	// --- State ---
+	allItems         kube.ItemSource                  // Holds all secrets fetched from the API.
+	filter           *itemFilter                      // Matches the search pattern against allItems.
+	highlightedItem  kube.Item                        // The secret currently selected in the list.
+	selected         map[string]bool                  // Secrets marked for bulk actions, keyed by namespace/name.
+	prompt           *prompt                          // The open input or confirmation prompt, if any.
+	status           string                           // Outcome of the last action, shown in the help bar.
+	statusErr        bool                             // True when the status reports a failure.
+	secretCache      map[string]map[string]string     // Caches secret data to avoid repeated API calls, keyed by namespace/name.
+	secretObjects    map[string]*corev1.Secret        // Caches the full secret objects behind secretCache.
+	secretErrCache   map[string]error                 // Caches errors for specific secrets to show in the UI.
+	tlsChecks        map[string][]kube.TLSCheck       // Caches the validation of TLS secrets.
+	certificates     map[string]*kube.CertificateInfo // Caches the cert-manager Certificates of TLS secrets.
+	gitopsSources    map[string]string                // Caches the repositories of GitOps managers, keyed by manager.
+	width, height    int                              // Current terminal dimensions.
+	focus            pane                             // Tracks which pane is active (left or right).
+	loading          bool                             // True when fetching the initial list of secrets.
+	loadingSecret    bool                             // True when fetching data for a single secret.
+	ready            bool                             // True once the initial layout has been calculated.
+	display          displayMode                      // How secret values are rendered in the right pane.
+	secretKeys       *regexp.Regexp                   // Keys whose values get a strength indicator.
+	showTOTP         bool                             // True when TOTP codes are computed in the right pane.
+	totpGeneration   int                              // Identifies the current chain of TOTP refresh ticks.
+	reveal           bool                             // True when typed secrets show their masked values.
+	decoders         []kube.ValueDecoder              // External commands that render values, from the config file.
+	actions          []Action                         // Custom actions from the config file.
+	stores           []kube.SecretStore               // Secret stores from the config file, listed in the command palette.
+	pickNamespace    bool                             // The namespace is picked on startup, before anything is listed.
+	searchHistory    *kube.SearchHistory              // Saves the searches for the next runs; nil when disabled.
+	searches         []string                         // Searches remembered for the namespace shown, oldest first.
+	recall           int                              // Index of the search recalled into the input, or -1.
+	pendingChord     *chord                           // The chord started in the data pane, waiting for its second key.
+	showKeys         bool                             // The keys of the data pane are listed in place of the data.
+	focusName        string                           // The secret to highlight once listed, from a secret:// URI.
+	context          string                           // The kubeconfig context, for secret:// URIs.
+	remote           bool                             // The TUI is used from another machine; local actions are disabled.
+	allNamespaces    bool                             // True when the list spans every namespace.
+	skipped          []string                         // Namespaces left out of the list for lack of access.
+	reconnectAttempt int                              // Attempts to reach the API server again, or 0 while connected.
+	reconnectErr     error                            // Why the API server is unreachable.
+	execPlugin       bool                             // True when the credentials come from an exec plugin.
+	reauthenticating bool                             // True while the TUI is suspended for the plugin.
+	protected        kube.ProtectedNamespaces         // Namespaces whose values are masked until confirmed.
+	unlocked         map[string]bool                  // Secrets whose protected or redacted values were revealed.
+	fullValues       map[string]bool                  // Secrets whose large values are rendered in full.
+	redaction        kube.RedactionPolicy             // Keys whose values are masked regardless of reveal.
+	undoStack        []undoEntry                      // Changes made from the TUI that u undoes, the last one on top.
+	trash            *kube.Trash                      // Where deleted secrets are copied, if anywhere.
+	keepPrevious     string                           // Where rotated keys keep their previous value.
+	history          *kube.HistoryStore               // Where the changes to secrets are recorded, if anywhere.
+	historyWatch     tea.Cmd                          // Records the changes of the first namespace, started by Init.
+	historyStop      context.CancelFunc               // Stops recording the changes of the current namespace.
+	showHistory      bool                             // True when the data pane shows the history of the secret.
+	showMetadata     bool                             // True when the data pane shows the metadata of the secret.
+	watch            bool                             // True when the list follows the changes to secrets.
+	bell             bool                             // True when changes ring the terminal bell.
+	liveWatch        tea.Cmd                          // Watches the first namespace, started by Init.
+	watchStop        context.CancelFunc               // Stops watching the current namespace.
+	watchGeneration  int                              // Identifies the current watch.
+	resourceVersions map[string]string                // The versions of the watched secrets, keyed by namespace/name.
+	listedVersions   map[string]string                // The versions of the listed secrets, keyed by namespace/name.
+	changed          map[string]bool                  // Secrets changed since they were last viewed, keyed by namespace/name.
+	quota            *kube.SecretQuota                // The secret quota of the namespace, if any.
+	listCache        *kube.ListCache                  // Where the secret lists are cached between runs, if anywhere.
+	revalidating     bool                             // True while a cached list is shown until the current one arrives.
+	frames           *frameStats                      // Timings of rendering and updates, for the debug log.
+	err              error                            // Stores any fatal error that occurs.
+}
+
+// Options configures the optional features of the TUI. The zero value is a
+// working configuration.
+type Options struct {
+	// Dynamic is the client for custom resources such as cert-manager Certificates.
+	// Features that need it are disabled when it is nil.
+	Dynamic dynamic.Interface
+	// SecretKeys matches the keys whose values get a strength indicator. It
+	// defaults to the heuristic of 'kds scan'.
+	SecretKeys *regexp.Regexp
+	// Decoders are external commands that render values, from the config file.
+	Decoders []kube.ValueDecoder
+	// Actions are custom actions on the highlighted secret, from the config file.
+	Actions []Action
+	// Stores are the secret stores of the config file, which the highlighted
+	// secret can be pushed to and pulled from.
+	Stores []kube.SecretStore
+	// AllNamespaces lists the secrets of every namespace instead of only the
+	// namespace passed to NewModel, which remains where new secrets are created.
+	AllNamespaces bool
+	// PickNamespace asks for the namespace in a fuzzy picker before listing the
+	// secrets, instead of using the namespace passed to NewModel.
+	PickNamespace bool
+	// CredentialPlugin tells that the credentials come from an exec plugin, such
+	// as kubelogin for OIDC. When they expire, the TUI is suspended while the
+	// plugin runs again, so that it can use the terminal.
+	CredentialPlugin bool
+	// ProtectedNamespaces mask the values of their secrets until revealing them
+	// is confirmed, and ask before values are copied or exported.
+	ProtectedNamespaces kube.ProtectedNamespaces
+	// Redaction masks the values of some keys, always or until revealing them is
+	// confirmed, on top of what reveal shows.
+	Redaction kube.RedactionPolicy
+	// Trash keeps an encrypted copy of the secrets deleted from the TUI, so that
+	// 'kds restore-deleted' can bring them back. Secrets are deleted without a
+	// copy when it is nil.
+	Trash *kube.Trash
+	// KeepPrevious is where rotated keys keep their previous value: key,
+	// annotation, or none. It defaults to key.
+	KeepPrevious string
+	// History records the changes to the secrets of the namespace while the TUI
+	// runs, and shows them with h. Nothing is recorded when it is nil.
+	History *kube.HistoryStore
+	// Watch keeps the list up to date with the changes to secrets while the TUI
+	// runs, notifying them in the help bar and marking the changed secrets until
+	// they are viewed.
+	Watch bool
+	// Bell rings the terminal bell when a watched secret changes.
+	Bell bool
+	// Icons prefixes the names of the secrets with a nerd-font icon of their
+	// type, which needs a patched font in the terminal.
+	Icons bool
+	// TypeColors colors the names of the secrets by type.
+	TypeColors bool
+	// ListCache shows the list of secrets cached by an earlier run until the API
+	// server answers, and caches the lists it fetches. Nothing is cached when it
+	// is nil.
+	ListCache *kube.ListCache
+	// SearchHistory remembers the searches of each namespace across runs, to be
+	// recalled with ↑. Searches are only remembered while the TUI runs when it is
+	// nil.
+	SearchHistory *kube.SearchHistory
+	// Focus highlights a secret of the namespace once it is listed, with the data
+	// pane focused, as when kds is started with a secret:// URI.
+	Focus string
+	// Context is the name of the kubeconfig context, used in the secret:// URIs
+	// copied with y u. The current context is meant when it is empty.
+	Context string
+	// Remote tells that the TUI is used from another machine, as in 'kds
+	// serve-ssh'. Custom actions, the editor, and the actions that read or write
+	// files or the clipboard are disabled, since they would run on this machine.
+	Remote bool
+}
+
+// NewModel is the constructor for our TUI model. It initializes all the components
+// and sets the initial state of the application. The model can be run by any
+// Bubble Tea program, which is how other tools embed the secret browser.
+func NewModel(clientset kube.Client, namespace string, opts Options) Model {
+	if opts.SecretKeys == nil {
+		opts.SecretKeys = kube.PasswordKeyPattern
+	}
+
+	ti := textinput.New()
+	ti.Placeholder = "Search for a secret..."
+	ti.Focus()
+	ti.Prompt = "🔎 "
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(primaryColor)
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(primaryColor)
+
+	selected, changed := make(map[string]bool), make(map[string]bool)
+	l := list.New(nil, selectionDelegate{DefaultDelegate: list.NewDefaultDelegate(), selected: selected, changed: changed, icons: opts.Icons, typeColors: opts.TypeColors}, 0, 0)
+	l.Title = "Kubernetes Secrets"
+	l.Styles.Title = NoteStyle
+	l.SetShowHelp(false)
+	l.SetFilteringEnabled(false) // We handle filtering manually with our fuzzy matcher.
+
+	m := Model{
+		clientset:      clientset,
+		namespace:      namespace,
+		textinput:      ti,
+		spinner:        s,
+		list:           l,
+		loading:        true,
+		focus:          leftPane,
+		selected:       selected,
+		changed:        changed,
+		secretCache:    make(map[string]map[string]string),
+		secretObjects:  make(map[string]*corev1.Secret),
+		secretErrCache: make(map[string]error),
+		tlsChecks:      make(map[string][]kube.TLSCheck),
+		certificates:   make(map[string]*kube.CertificateInfo),
+		gitopsSources:  make(map[string]string),
+		unlocked:       make(map[string]bool),
+		fullValues:     make(map[string]bool),
+		filter:         newItemFilter(nil),
+		frames:         &frameStats{},
+		dynamic:        opts.Dynamic,
+		secretKeys:     opts.SecretKeys,
+		decoders:       opts.Decoders,
+		actions:        opts.Actions,
+		stores:         opts.Stores,
+		pickNamespace:  opts.PickNamespace && !opts.AllNamespaces,
+		allNamespaces:  opts.AllNamespaces,
+		execPlugin:     opts.CredentialPlugin,
+		protected:      opts.ProtectedNamespaces,
+		redaction:      opts.Redaction,
+		trash:          opts.Trash,
+		keepPrevious:   opts.KeepPrevious,
+		history:        opts.History,
+		watch:          opts.Watch,
+		bell:           opts.Bell,
+		listCache:      opts.ListCache,
+		searchHistory:  opts.SearchHistory,
+		focusName:      opts.Focus,
+		context:        opts.Context,
+		remote:         opts.Remote,
+		recall:         -1,
+	}
+	if m.remote {
+		m.actions = nil
+	}
+	if !m.pickNamespace {
+		m.historyWatch = m.restartHistory()
+		m.liveWatch = m.restartWatch()
+	}
+	return m
+}
+
+// Init is the first command run when the Bubble Tea program starts.
+// It kicks off the initial I/O, like fetching the list of secrets.
+func (m Model) Init() tea.Cmd {
+	if m.pickNamespace {
+		return tea.Batch(m.spinner.Tick, pickNamespaceCmd(m.clientset))
+	}
+	return tea.Batch(m.spinner.Tick, m.loadCachedListCmd(), m.loadSearchesCmd(), m.fetchSecretsCmd(), m.fetchQuotaCmd(), m.historyWatch, m.liveWatch)
+}
+
+// --- COMMANDS ---
+// Commands are functions that perform I/O (like API calls) and return a message.
+
+// fetchSecrets is a command that fetches the list of all secrets in a namespace.
+// It returns an itemSource message on success or a fatalErrorMsg on failure.
+func fetchSecrets(clientset kube.Client, namespace string) tea.Cmd {
+	return func() tea.Msg {
+		items, err := kube.ListItems(clientset, namespace)
+		if err != nil {
+			return fatalErrorMsg{err}
+		}
+		return items
+	}
+}
+
+// secretsLoadedMsg is sent when the secrets of every namespace have been listed,
+// possibly without some namespaces.
+type secretsLoadedMsg struct {
+	items   kube.ItemSource
+	skipped []string // Namespaces where listing secrets is forbidden.
+}
+
+// secretsListedMsg carries the secrets of the namespaces listed so far, while the
+// others are still being listed.
+type secretsListedMsg struct {
+	items kube.ItemSource
+	next  tea.Cmd // Waits for the next namespace.
+}
+
+// fetchAllSecrets is a command that fetches the secrets of every accessible namespace.
+// It returns a secretsListedMsg each time a namespace with secrets is listed, then a
+// secretsLoadedMsg once all of them are, or a fatalErrorMsg on failure.
+func fetchAllSecrets(clientset kube.Client) tea.Cmd {
+	return func() tea.Msg {
+		results, total, err := kube.StreamAllItems(clientset)
+		if err != nil {
+			return fatalErrorMsg{err}
+		}
+		return waitNamespaceListed(results, total, &kube.ItemCollector{})()
+	}
+}
+
+// waitNamespaceListed waits for the next namespace of fetchAllSecrets.
+func waitNamespaceListed(results <-chan kube.NamespaceItems, total int, collector *kube.ItemCollector) tea.Cmd {
+	return func() tea.Msg {
+		for result := range results {
+			if err := collector.Add(result); err != nil {
+				return fatalErrorMsg{err}
+			}
+			if len(result.Items) > 0 && total > 1 {
+				return secretsListedMsg{items: collector.Items(), next: waitNamespaceListed(results, total, collector)}
+			}
+		}
+		items, skipped, err := collector.Result(total)
+		if err != nil {
+			return fatalErrorMsg{err}
+		}
+		return secretsLoadedMsg{items: items, skipped: skipped}
+	}
+}
+
+// fetchSecretsCmd fetches the secrets of the namespace, or of every namespace.
+func (m Model) fetchSecretsCmd() tea.Cmd {
+	if m.allNamespaces {
+		return fetchAllSecrets(m.clientset)
+	}
+	return fetchSecrets(m.clientset, m.namespace)
+}
+
+// fetchSecretData is a command that fetches and decodes the data for a single secret.
+// It returns a secretDataLoadedMsg on success or a secretDataErrorMsg on failure.
+func fetchSecretData(clientset kube.Client, secretName, namespace string, decoders []kube.ValueDecoder) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		secret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+		if err != nil {
+			slog.Debug("failed to fetch secret", "namespace", namespace, "secret", secretName, "error", err)
+			return secretDataErrorMsg{key: kube.SecretRef{Namespace: namespace, Name: secretName}.String(), err: err}
+		}
+		data := make(map[string]string)
+		for key, value := range secret.Data {
+			if decodedValue, ok := kube.DecodeValue(value); ok {
+				data[key] = decodedValue
+			} else {
+				data[key] = decodedValue + " " + NoteStyle.Render("(raw, base64 decoding failed)")
+			}
+		}
+		applyDecoders(decoders, secret, data)
+		slog.Debug("fetched secret", "namespace", namespace, "secret", secretName, "keys", len(data), "duration", time.Since(start))
+		return secretDataLoadedMsg{key: cacheKey(secret), data: data, secret: secret}
+	}
+}
+
+// --- UPDATE ---
+
+// Update is the main message handler for the TUI. It acts as a dispatcher,
+// routing messages to more specific handler functions to keep cognitive complexity low.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	start := time.Now()
+	defer func() { m.frames.recordUpdate(time.Since(start), len(m.allItems)) }()
+	var cmds []tea.Cmd
+	var cmd tea.Cmd
+
+	// While a prompt is open, it captures all keyboard input.
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.prompt != nil {
+		next, cmd := m.handlePromptKey(keyMsg)
+		return next, cmd
+	}
+
+	// The spinner should tick whenever we are in a loading state.
+	if m.loading || m.loadingSecret {
+		m.spinner, cmd = m.spinner.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	// Action keys are consumed here and never reach the focused pane.
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && !m.loading {
+		if next, cmd, handled := m.handleActionKey(keyMsg); handled {
+			return next, tea.Batch(append(cmds, cmd)...)
+		}
+	}
+
+	// Delegate message handling to a dedicated function.
+	m, cmd = m.handleMessages(msg)
+	cmds = append(cmds, cmd)
+
+	// If we are not loading, handle user input based on which pane is focused.
+	if !m.loading {
+		m, cmd = m.handleFocusedPaneInput(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// handleMessages handles all incoming Bubble Tea messages by type.
+func (m Model) handleMessages(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return m.handleWindowSize(msg)
+	case tea.KeyMsg:
+		return m.handleKeyMsg(msg)
+	case kube.ItemSource:
+		m, cmd := m.handleSecretsLoaded(msg)
+		m, store := m.handleListFetched(msg)
+		return m, tea.Batch(cmd, store)
+	case secretsLoadedMsg:
+		m, cmd := m.handleAllSecretsLoaded(msg)
+		m, store := m.handleListFetched(msg.items)
+		return m, tea.Batch(cmd, store)
+	case secretsListedMsg:
+		return m.handleSecretsListed(msg)
+	case cachedListMsg:
+		return m.handleCachedList(msg)
+	case namespaceSwitchedMsg:
+		return m.handleNamespaceSwitched(msg)
+	case namespacePickedMsg:
+		return m.handleNamespacePicked(msg)
+	case searchesLoadedMsg:
+		return m.handleSearchesLoaded(msg), nil
+	case secretDataLoadedMsg:
+		return m.handleSecretDataLoaded(msg)
+	case secretDataErrorMsg:
+		return m.handleSecretDataError(msg)
+	case actionDoneMsg:
+		return m.handleActionDone(msg)
+	case copyConflictMsg:
+		return m.handleCopyConflict(msg)
+	case renameCheckedMsg:
+		return m.handleRenameChecked(msg)
+	case editReadyMsg:
+		return m.handleEditReady(msg)
+	case reloaderCheckedMsg:
+		return m.handleReloaderChecked(msg)
+	case tlsCheckedMsg:
+		return m.handleTLSChecked(msg)
+	case certificateLoadedMsg:
+		return m.handleCertificateLoaded(msg)
+	case gitopsSourceLoadedMsg:
+		return m.handleGitOpsSourceLoaded(msg), nil
+	case totpTickMsg:
+		return m.handleTOTPTick(msg)
+	case historyStoppedMsg:
+		if m.credentialsExpired(msg.err) {
+			return m.refreshCredentials(msg.err)
+		}
+		m.status, m.statusErr = fmt.Sprintf("History no longer recorded: %v", msg.err), true
+		return m, nil
+	case secretEventMsg:
+		return m.handleSecretEvent(msg)
+	case watchStoppedMsg:
+		if msg.generation != m.watchGeneration {
+			return m, nil
+		}
+		if m.credentialsExpired(msg.err) {
+			return m.refreshCredentials(msg.err)
+		}
+		m.status, m.statusErr = fmt.Sprintf("Changes no longer watched: %v", msg.err), true
+		return m, nil
+	case notificationExpiredMsg:
+		return m.handleNotificationExpired(msg), nil
+	case paletteStoreMsg:
+		return m.startStoreAction(msg)
+	case paletteActionMsg:
+		return m.startAction(msg.action)
+	case restartOfferMsg:
+		return m.handleRestartOffer(msg)
+	case mutationPreviewMsg:
+		return m.handleMutationPreview(msg)
+	case showPromptMsg:
+		m.prompt = msg.prompt
+		return m, nil
+	case editorClosedMsg:
+		return m, finishEditCmd(m.clientset, msg)
+	case editMergeMsg:
+		return m.handleEditMerge(msg)
+	case reconnectTickMsg:
+		return m, m.reconnectCmd()
+	case reconnectFailedMsg:
+		return m.handleReconnectFailed(msg)
+	case reconnectedMsg:
+		return m.handleReconnected(msg)
+	case credentialsRefreshedMsg:
+		return m.handleCredentialsRefreshed(msg)
+	case revealConfirmedMsg:
+		return m.handleRevealConfirmed(msg)
+	case undoneMsg:
+		return m.handleUndone(msg)
+	case quotaLoadedMsg:
+		return m.handleQuotaLoaded(msg), nil
+	case fatalErrorMsg:
+		// Mid-session, an unreachable API server is waited for rather than fatal.
+		if !m.loading && connectionLost(msg.err) {
+			return m.startReconnecting(msg.err)
+		}
+		if !m.loading && m.credentialsExpired(msg.err) {
+			return m.refreshCredentials(msg.err)
+		}
+		m.err = msg.err
+		return m, tea.Quit
+	default:
+		return m, nil
+	}
+}
+
+// handleWindowSize updates the layout when the terminal is resized.
+func (m Model) handleWindowSize(msg tea.WindowSizeMsg) (Model, tea.Cmd) {
+	m.width, m.height = msg.Width, msg.Height
+	helpHeight := lipgloss.Height(m.viewHelp())
+	mainContentHeight := m.height - helpHeight
+	leftPaneWidth := m.width / 2
+	rightPaneWidth := m.width - leftPaneWidth
+	textInputHeight := lipgloss.Height(m.textinput.View())
+	listHeight := mainContentHeight - textInputHeight - paneBaseStyle.GetVerticalPadding()
+	m.list.SetSize(leftPaneWidth-paneBaseStyle.GetHorizontalPadding(), listHeight)
+	m.viewport.Width = rightPaneWidth - rightPaneStyle.GetHorizontalPadding()
+	m.viewport.Height = mainContentHeight - rightPaneStyle.GetVerticalPadding()
+	if !m.ready {
+		m.ready = true
+	} else if content, ok := m.secretCache[m.highlightedKey()]; ok {
+		m.viewport.SetContent(m.formatSecretData(content))
+	}
+	return m, nil
+}
+
+// handleKeyMsg handles all global keyboard input.
+func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if m.loading {
+		return m, nil
+	}
+	switch msg.String() {
+	case "ctrl+c", "q", "esc":
+		return m, tea.Quit
+	case "ctrl+x":
+		m.skipped = nil
+		return m.handleWindowSize(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	case "tab":
+		if m.focus == leftPane {
+			m.focus = rightPane
+			m.textinput.Blur()
+			return m.rememberSearch()
+		}
+		m.focus = leftPane
+		m.textinput.Focus()
+	case "enter":
+		if m.focus == leftPane {
+			return m.rememberSearch()
+		}
+	}
+	return m, nil
+}
+
+// handleSecretsLoaded handles the message received after the initial list of secrets is fetched.
+func (m Model) handleSecretsLoaded(msg kube.ItemSource) (Model, tea.Cmd) {
+	m.loading = false
+	m.setAllItems(msg)
+	cmd := m.list.SetItems(m.filteredItems())
+
+	if m.focusName != "" {
+		m = m.focusSecret()
+	}
+	if len(m.list.Items()) > 0 {
+		if selected, ok := m.list.SelectedItem().(kube.Item); ok {
+			m.highlightedItem = selected
+			m.loadingSecret = true
+			return m, tea.Batch(cmd, fetchSecretData(m.clientset, m.highlightedItem.Name, m.highlightedItem.Namespace, m.decoders))
+		}
+	}
+	m.highlightedItem = kube.Item{}
+	return m, cmd
+}
+
+// handleAllSecretsLoaded handles the secrets of every namespace, making room for
+// the banner about skipped namespaces.
+func (m Model) handleAllSecretsLoaded(msg secretsLoadedMsg) (Model, tea.Cmd) {
+	m.skipped = msg.skipped
+	m, cmd := m.handleSecretsLoaded(msg.items)
+	if m.ready {
+		m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	}
+	return m, cmd
+}
+
+// setAllItems replaces the secrets of the list, indexing their versions so that
+// the live watch can tell the secrets already listed in constant time.
+func (m *Model) setAllItems(items kube.ItemSource) {
+	m.allItems, m.filter = items, newItemFilter(items)
+	m.listedVersions = make(map[string]string, len(items))
+	for _, item := range items {
+		m.listedVersions[item.Ref().String()] = item.ResourceVersion
+	}
+}
+
+// handleSecretsListed shows the secrets of the namespaces listed so far, keeping
+// the highlighted secret, and waits for the next namespace. A cached list is kept
+// until every namespace is listed.
+func (m Model) handleSecretsListed(msg secretsListedMsg) (Model, tea.Cmd) {
+	if !m.allNamespaces || m.revalidating {
+		return m, msg.next
+	}
+	m.loading = false
+	m.setAllItems(msg.items)
+	cmds := []tea.Cmd{msg.next, m.list.SetItems(m.filteredItems())}
+	for i, item := range m.list.Items() {
+		if it, ok := item.(kube.Item); ok && it.Ref() == m.highlightedItem.Ref() {
+			m.list.Select(i)
+		}
+	}
+	if m.highlightedItem.Name == "" {
+		if selected, ok := m.list.SelectedItem().(kube.Item); ok {
+			m.highlightedItem = selected
+			m.loadingSecret = true
+			cmds = append(cmds, fetchSecretData(m.clientset, selected.Name, selected.Namespace, m.decoders))
+		}
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// handleSecretDataLoaded handles the message received after a single secret's data is fetched.
+func (m Model) handleSecretDataLoaded(msg secretDataLoadedMsg) (Model, tea.Cmd) {
+	if m.highlightedKey() == msg.key {
+		m.loadingSecret = false
+		delete(m.changed, msg.key)
+		m.secretCache[msg.key] = msg.data
+		m.secretObjects[msg.key] = msg.secret
+		delete(m.secretErrCache, msg.key)
+		m.viewport.SetContent(m.formatSecretData(msg.data))
+		m.viewport.GotoTop()
+		var cmds []tea.Cmd
+		if _, checked := m.tlsChecks[msg.key]; msg.secret.Type == corev1.SecretTypeTLS && !checked {
+			cmds = append(cmds, checkTLSCmd(m.clientset, msg.secret))
+			if m.dynamic != nil {
+				cmds = append(cmds, loadCertificateCmd(m.dynamic, msg.secret))
+			}
+		}
+		if manager, managed := kube.GitOpsManagerOf(msg.secret); managed && m.dynamic != nil {
+			if _, found := m.gitopsSources[manager.String()]; !found {
+				cmds = append(cmds, loadGitOpsSourceCmd(m.dynamic, manager))
+			}
+		}
+		return m, tea.Batch(cmds...)
+	}
+	return m, nil
+}
+
+// handleSecretDataError handles errors from fetching a single secret's data.
+func (m Model) handleSecretDataError(msg secretDataErrorMsg) (Model, tea.Cmd) {
+	if m.highlightedKey() == msg.key {
+		m.loadingSecret = false
+		m.secretErrCache[msg.key] = msg.err
+	}
+	if connectionLost(msg.err) {
+		return m.startReconnecting(msg.err)
+	}
+	if m.credentialsExpired(msg.err) {
+		return m.refreshCredentials(msg.err)
+	}
+	return m, nil
+}
+
+// handleFocusedPaneInput routes updates to the correct component based on which pane has focus.
+func (m Model) handleFocusedPaneInput(msg tea.Msg) (Model, tea.Cmd) {
+	// This function should only handle keyboard input, not other message types.
+	if _, ok := msg.(tea.KeyMsg); !ok {
+		return m, nil
+	}
+
+	var cmds []tea.Cmd
+	var cmd tea.Cmd
+
+	if m.focus == leftPane {
+		if next, cmd, handled := m.handleSearchRecallKey(msg.(tea.KeyMsg)); handled {
+			return next, cmd
+		}
+		pattern := m.textinput.Value()
+		m.textinput, cmd = m.textinput.Update(msg)
+		cmds = append(cmds, cmd)
+		// The list is only filtered again when the pattern changes, not when moving
+		// through it.
+		if m.textinput.Value() != pattern {
+			cmds = append(cmds, m.list.SetItems(m.filteredItems()))
+		}
+
+		m.list, cmd = m.list.Update(msg)
+		cmds = append(cmds, cmd, m.highlightSelected())
+	} else { // Right Pane is focused
+		m.viewport, cmd = m.viewport.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// highlightSelected shows the data of the secret selected in the list, if it
+// changed, fetching it unless it is cached.
+func (m *Model) highlightSelected() tea.Cmd {
+	selected, ok := m.list.SelectedItem().(kube.Item)
+	if !ok || m.highlightedItem.Ref() == selected.Ref() {
+		return nil
+	}
+	m.highlightedItem = selected
+	// Only fetch from the API if the data is not already in our cache.
+	if _, found := m.secretCache[selected.Ref().String()]; found {
+		slog.Debug("secret cache hit", "secret", selected.Name)
+		return nil
+	}
+	m.loadingSecret = true
+	return fetchSecretData(m.clientset, selected.Name, selected.Namespace, m.decoders)
+}
+
+// filteredItems returns the secrets matching the current search pattern, in match order.
+func (m Model) filteredItems() []list.Item {
+	return m.filter.filter(m.textinput.Value())
+}
+
+// --- VIEW ---
+// The View functions are responsible for rendering the UI based on the model's state.
+
+// formatSecretData formats the key-value data into a word-wrapped string for the viewport.
+// In checksum mode, each value is replaced by the SHA-256 checksum of its raw bytes.
+// Values are followed by their indicators, and TLS secrets start with the outcome
+// of their validation.
+func (m *Model) formatSecretData(data map[string]string) string {
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render(m.highlightedItem.Name))
+	if m.showHistory {
+		b.WriteString(m.formatHistory())
+		return wordwrap.String(b.String(), m.viewport.Width)
+	}
+	if m.showMetadata {
+		b.WriteString(formatMetadata(m.secretObjects[m.highlightedKey()]))
+		return wordwrap.String(b.String(), m.viewport.Width)
+	}
+	b.WriteString(m.formatTLSStatus(m.highlightedKey()))
+	b.WriteString(m.formatGitOpsManager(m.secretObjects[m.highlightedKey()]))
+	if m.locked() {
+		b.WriteString(m.formatLockedData(data))
+		return wordwrap.String(b.String(), m.viewport.Width)
+	}
+	secret := m.secretObjects[m.highlightedKey()]
+	// Typed secrets are rendered key by key when some of their keys are redacted.
+	if secret != nil && m.display == displayPlain && !m.redacts(data) {
+		if fields, ok := typedFields(secret, data, m.reveal); ok {
+			b.WriteString(m.formatTypedFields(secret, fields))
+			return wordwrap.String(b.String(), m.viewport.Width)
+		}
+	}
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		b.WriteString(fmt.Sprintf("%s: %s\n", key, m.formatValue(secret, key, data[key])))
+	}
+	return wordwrap.String(b.String(), m.viewport.Width)
+}
+
+// formatValue renders the decoded value of a key for the display mode, replacing
+// kubeconfigs and service account tokens with a summary unless values are revealed.
+// Keys redacted by policy are masked in every mode.
+func (m *Model) formatValue(secret *corev1.Secret, key, value string) string {
+	if mode := m.redactionOf(key); mode != kube.RedactNone {
+		raw := []byte(value)
+		if secret != nil {
+			raw = secret.Data[key]
+		}
+		return formatRedacted(raw, mode)
+	}
+	if secret == nil {
+		return value
+	}
+	switch {
+	case m.display != displayChecksums && isLargeValue(secret.Data[key]):
+		if m.fullValues[m.highlightedKey()] {
+			return m.formatFullValue(value)
+		}
+		return formatLargeValue(secret.Data[key])
+	case m.display == displayChecksums:
+		value = "sha256:" + kube.Checksum(secret.Data[key])
+	case !m.reveal:
+		if config, ok := kube.ParseKubeconfig(secret.Data[key]); ok {
+			value = "\n" + strings.Join(DescribeKubeconfig(config), "\n")
+		} else if sa, err := kube.ParseServiceAccountToken(value); err == nil {
+			value = kube.MaskValue(secret.Data[key]) + "\n" + strings.TrimRight(m.formatTypedFields(secret, tokenFields(sa, time.Now())), "\n")
+		}
+	}
+	return value + m.formatIndicators(key, secret.Data[key])
+}
+
+// formatIndicators renders what kds can tell about a raw value: the strength of
+// password-like values and the current code of TOTP seeds.
+func (m *Model) formatIndicators(key string, value []byte) string {
+	var indicators string
+	if m.secretKeys != nil && m.secretKeys.MatchString(key) {
+		indicators += "  " + formatStrength(kube.StrengthOf(key, string(value)))
+	}
+	if totp := m.formatTOTP(key, value); totp != "" {
+		indicators += "  " + totp
+	}
+	return indicators
+}
+
+// refreshSecretData re-renders the data of the highlighted secret, if it is loaded.
+func (m *Model) refreshSecretData() {
+	if data, ok := m.secretCache[m.highlightedKey()]; ok {
+		m.viewport.SetContent(m.formatSecretData(data))
+	}
+}
+
+// highlightedKey returns the cache key of the highlighted secret.
+func (m Model) highlightedKey() string { return m.highlightedItem.Ref().String() }
+
+// cacheKey returns the key of a secret in the caches of the model: its
+// namespace/name, as the list may span namespaces.
+func cacheKey(secret *corev1.Secret) string {
+	return kube.SecretRef{Namespace: secret.Namespace, Name: secret.Name}.String()
+}
+
+// formatTLSStatus renders the validation and the cert-manager Certificate of a TLS
+// secret, or nothing for other secrets.
+func (m *Model) formatTLSStatus(name string) string {
+	var lines []string
+	for _, check := range m.tlsChecks[name] {
+		lines = append(lines, FormatTLSCheck(check))
+	}
+	if cert, ok := m.certificates[name]; ok {
+		lines = append(lines, CertificateLines(cert)...)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n\n"
+}
+
+// viewHelp renders the help text at the bottom of the screen, or the open prompt,
+// below the banners about skipped namespaces and the connection.
+func (m *Model) viewHelp() string {
+	return m.viewSkippedBanner() + m.viewReconnectBanner() + m.viewHelpLine()
+}
+
+// viewHelpLine renders the key bindings and the status, or the open prompt.
+func (m *Model) viewHelpLine() string {
+	if m.prompt != nil && m.prompt.confirm {
+		return NoteStyle.Render("  y: confirm | any other key: cancel")
+	}
+	if m.prompt != nil {
+		return "  " + m.prompt.View()
+	}
+	help := "  ↑/↓: navigate | alt+1–9: jump | space: select | ctrl+n: namespace | tab: switch pane | q: quit"
+	if m.focus == rightPane {
+		help = "  e: edit | x: export | v: reveal | g: goto… | y: yank… | :: palette | ?: all keys | tab: switch pane | q: quit"
+	}
+	line := NoteStyle.Render(help)
+	if quota := m.viewQuota(); quota != "" {
+		line += NoteStyle.Render("  •  ") + quota
+	}
+	if m.watch {
+		line += NoteStyle.Render("  •  watching")
+	}
+	if m.status != "" {
+		status := NoteStyle.Render(m.status)
+		if m.statusErr {
+			status = errorStyle.Render(m.status)
+		}
+		line += NoteStyle.Render("  •  ") + status
+	}
+	// Wrapped to the width, so that the panes are sized with the lines it takes.
+	if m.width > 0 {
+		line = lipgloss.NewStyle().Width(m.width).Render(line)
+	}
+	return line
+}
+
+// maxSkippedShown is how many skipped namespaces the banner names.
+const maxSkippedShown = 5
+
+// viewSkippedBanner warns about the namespaces left out of the list, on a line
+// above the help, until it is dismissed.
+func (m *Model) viewSkippedBanner() string {
+	if len(m.skipped) == 0 {
+		return ""
+	}
+	names := strings.Join(m.skipped, ", ")
+	if len(m.skipped) > maxSkippedShown {
+		names = fmt.Sprintf("%s and %d more", strings.Join(m.skipped[:maxSkippedShown], ", "), len(m.skipped)-maxSkippedShown)
+	}
+	banner := fmt.Sprintf("⚠ Skipped %d namespace(s) where listing secrets is forbidden: %s", len(m.skipped), names)
+	return "  " + changedStyle.Render(banner) + NoteStyle.Render("  •  ctrl+x: dismiss") + "\n"
+}
+
+// viewLeftPane renders the content for the left-hand pane (search bar and list).
+func (m *Model) viewLeftPane() string {
+	return lipgloss.JoinVertical(lipgloss.Left, m.textinput.View(), m.list.View())
+}
+
+// viewRightPane renders the content for the right-hand pane (secret data or status).
+func (m *Model) viewRightPane() string {
+	if m.pendingChord != nil {
+		return m.viewChordHint()
+	}
+	if m.showKeys {
+		return m.viewKeys()
+	}
+	if err, found := m.secretErrCache[m.highlightedKey()]; found {
+		return wordwrap.String(m.viewSecretError(err), m.viewport.Width)
+	}
+	if data, found := m.secretCache[m.highlightedKey()]; found {
+		m.viewport.SetContent(m.formatSecretData(data))
+		return m.viewport.View()
+	}
+	if m.loadingSecret {
+		return fmt.Sprintf("\n%s Loading secret data...", m.spinner.View())
+	}
+	if len(m.allItems) == 0 {
+		return wordwrap.String(m.viewEmptyHint(), m.viewport.Width)
+	}
+	return NoteStyle.Render("Select a secret to view its data.")
+}
+
+// viewSecretError renders why the highlighted secret could not be fetched, and
+// how to retry.
+func (m *Model) viewSecretError(err error) string {
+	kind := kube.ClassifyFetchError(err)
+	var b strings.Builder
+	b.WriteString(errorTitleStyle.Render(strings.ToUpper(kind.String()[:1]) + kind.String()[1:]))
+	b.WriteString(fmt.Sprintf("Failed to fetch secret '%s':\n\n", m.highlightedItem.Name))
+	b.WriteString(errorStyle.Render(err.Error()))
+	b.WriteString("\n\n" + fetchErrorHint(kind))
+	retry := "r: retry"
+	if m.focus == leftPane {
+		retry = "tab, then r: retry"
+	}
+	b.WriteString("\n\n" + NoteStyle.Render(retry))
+	return b.String()
+}
+
+// fetchErrorHint explains what a kind of fetch error means for the user.
+func fetchErrorHint(kind kube.FetchErrorKind) string {
+	switch kind {
+	case kube.FetchErrorPermission:
+		return "Your credentials are not allowed to read this secret. Retry once your RBAC permissions are fixed."
+	case kube.FetchErrorNotFound:
+		return "The secret was deleted or renamed since the list was loaded."
+	case kube.FetchErrorTransient:
+		return "The API server could not be reached or is overloaded. Retrying usually helps."
+	}
+	return "The request failed."
+}
+
+// View is the main render function for the entire TUI.
+func (m Model) View() string {
+	start := time.Now()
+	defer func() { m.frames.recordRender(time.Since(start), len(m.allItems)) }()
+
+	// If a fatal error has occurred, show only the error message.
+	if m.err != nil {
+		return fmt.Sprintf("\n%s: %v\n\n", errorStyle.Render("Fatal Error"), m.err)
+	}
+	// Do not render anything until the initial layout is calculated.
+	if !m.ready {
+		return "Initializing..."
+	}
+	// Show the namespace picker, or a loading message while fetching the initial
+	// secret list.
+	if m.loading && m.prompt != nil {
+		return fmt.Sprintf("\n  %s\n\n", m.prompt.View())
+	}
+	if m.loading {
+		if m.allNamespaces {
+			return fmt.Sprintf("\n  %s Searching for secrets in all namespaces...\n\n", m.spinner.View())
+		}
+		return fmt.Sprintf("\n  %s Searching for secrets in namespace '%s'...\n\n", m.spinner.View(), m.namespace)
+	}
+
+	// Determine which pane style to use based on focus.
+	var currentLeftPaneStyle, currentRightPaneStyle lipgloss.Style
+	if m.focus == leftPane {
+		currentLeftPaneStyle = focusedLeftPane
+		currentRightPaneStyle = rightPaneStyle
+	} else {
+		currentLeftPaneStyle = leftPaneStyle
+		currentRightPaneStyle = focusedRightPane
+	}
+
+	// Calculate dimensions and join the panes together.
+	helpHeight := lipgloss.Height(m.viewHelp())
+	mainContentHeight := m.height - helpHeight
+	leftPaneWidth := m.width / 2
+	rightPaneWidth := m.width - leftPaneWidth
+	mainPanes, ok := m.viewModal(m.width, mainContentHeight)
+	if !ok {
+		mainPanes = lipgloss.JoinHorizontal(lipgloss.Top,
+			currentLeftPaneStyle.Width(leftPaneWidth).Height(mainContentHeight).Render(m.viewLeftPane()),
+			currentRightPaneStyle.Width(rightPaneWidth).Height(mainContentHeight).Render(m.viewRightPane()),
+		)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, mainPanes, m.viewHelp())
+}
@@ -0,0 +1,175 @@
+package ui
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	"github.com/zalando/go-keyring"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestMain keeps the keys of the tests in memory rather than in the keychain of
+// the machine.
+func TestMain(m *testing.M) {
+	keyring.MockInit()
+	os.Exit(m.Run())
+}
+
+// TestFetchSecrets verifies that the command to fetch all secrets works correctly.
+func TestFetchSecrets(t *testing.T) {
+	testNamespace := "default"
+	t.Run("should fetch a list of secrets successfully", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret-a", Namespace: testNamespace}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret-b", Namespace: testNamespace}},
+		)
+		expectedItems := kube.ItemSource{
+			{Name: "secret-a", Namespace: testNamespace},
+			{Name: "secret-b", Namespace: testNamespace},
+		}
+		msg := fetchSecrets(clientset, testNamespace)()
+		items, ok := msg.(kube.ItemSource)
+		if !ok {
+			t.Fatalf("Expected message of type itemSource, but got %T", msg)
+		}
+		if !reflect.DeepEqual(items, expectedItems) {
+			t.Errorf("Expected secrets %v, but got %v", expectedItems, items)
+		}
+	})
+	t.Run("should return an empty list if no secrets are found", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		msg := fetchSecrets(clientset, testNamespace)()
+		if items, ok := msg.(kube.ItemSource); !ok || len(items) != 0 {
+			t.Fatalf("Expected an empty itemSource, but got %#v", msg)
+		}
+	})
+}
+
+// TestFetchSecretData verifies that fetching and decoding a single secret works.
+func TestFetchSecretData(t *testing.T) {
+	testNamespace := "default"
+	secretName := "my-secret"
+	secretKey := "token"
+	decodedValue := "hello-world"
+	encodedValue := base64.StdEncoding.EncodeToString([]byte(decodedValue))
+	t.Run("should fetch and decode a secret's data successfully", func(t *testing.T) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: testNamespace},
+			Data:       map[string][]byte{secretKey: []byte(encodedValue)},
+		}
+		clientset := fake.NewSimpleClientset(secret)
+		msg := fetchSecretData(clientset, secretName, testNamespace, nil)()
+		dataMsg, ok := msg.(secretDataLoadedMsg)
+		if !ok {
+			t.Fatalf("Expected message of type secretDataLoadedMsg, but got %T", msg)
+		}
+		if dataMsg.data[secretKey] != decodedValue {
+			t.Errorf("Expected decoded value '%s', but got '%s'", decodedValue, dataMsg.data[secretKey])
+		}
+	})
+	t.Run("should return an error if the secret does not exist", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		msg := fetchSecretData(clientset, "non-existent-secret", testNamespace, nil)()
+		if _, ok := msg.(secretDataErrorMsg); !ok {
+			t.Fatalf("Expected message of type secretDataErrorMsg, but got %T", msg)
+		}
+	})
+}
+
+// TestRetrySecretData verifies that an errored secret explains its error and can
+// be fetched again once the cause is fixed.
+func TestRetrySecretData(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	m := NewModel(clientset, "default", Options{})
+	m.focus = rightPane
+	m.highlightedItem = kube.Item{Name: "db", Namespace: "default"}
+	next, _ := m.handleSecretDataError(fetchSecretData(clientset, "db", "default", nil)().(secretDataErrorMsg))
+
+	if view := next.viewRightPane(); !strings.Contains(view, "Not found") || !strings.Contains(view, "r: retry") {
+		t.Errorf("Expected a not-found error with the retry key, but got:\n%s", view)
+	}
+
+	if _, err := clientset.CoreV1().Secrets("default").Create(context.TODO(), &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}, Data: map[string][]byte{"password": []byte(base64.StdEncoding.EncodeToString([]byte("s3cr3t")))}}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	next, cmd, handled := next.handleActionKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if !handled || cmd == nil || !next.loadingSecret {
+		t.Fatal("Expected r to fetch the secret again")
+	}
+	if _, failed := next.secretErrCache["default/db"]; failed {
+		t.Error("Expected the cached error to be cleared")
+	}
+	if msg, ok := cmd().(secretDataLoadedMsg); !ok || msg.data["password"] != "s3cr3t" {
+		t.Errorf("Expected the secret to load, but got %#v", msg)
+	}
+}
+
+// TestSkippedNamespacesBanner verifies that namespaces skipped while listing every
+// namespace are reported in a banner until it is dismissed.
+func TestSkippedNamespacesBanner(t *testing.T) {
+	m := NewModel(fake.NewSimpleClientset(), "default", Options{AllNamespaces: true})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 120, Height: 40})
+	next, _ := m.Update(secretsLoadedMsg{items: kube.ItemSource{{Name: "api", Namespace: "dev"}}, skipped: []string{"prod", "kube-system"}})
+	m = next.(Model)
+
+	if help := m.viewHelp(); !strings.Contains(help, "Skipped 2 namespace(s) where listing secrets is forbidden: prod, kube-system") {
+		t.Errorf("Expected a banner naming the skipped namespaces, but got:\n%s", help)
+	}
+	if len(m.list.Items()) != 1 {
+		t.Errorf("Expected the accessible secret to be listed, but got %v", m.list.Items())
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	m = next.(Model)
+	if help := m.viewHelp(); strings.Contains(help, "Skipped") {
+		t.Errorf("Expected the banner to be dismissed, but got:\n%s", help)
+	}
+}
+
+// TestStreamedNamespaces verifies that, when every namespace is listed on its own,
+// the list fills up as the namespaces arrive.
+func TestStreamedNamespaces(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "dev"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"}},
+	)
+	clientset.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == metav1.NamespaceAll {
+			return true, nil, k8serrors.NewForbidden(corev1.Resource("secrets"), "", nil)
+		}
+		return false, nil, nil
+	})
+	m := NewModel(clientset, "default", Options{AllNamespaces: true})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	msg := fetchAllSecrets(clientset)()
+	listed, ok := msg.(secretsListedMsg)
+	if !ok {
+		t.Fatalf("Expected the secrets of the first namespace, but got %#v", msg)
+	}
+	next, _ := m.Update(listed)
+	m = next.(Model)
+	if m.loading || len(m.list.Items()) != 1 {
+		t.Errorf("Expected the first namespace to be shown, but got %v (loading: %v)", m.list.Items(), m.loading)
+	}
+
+	msg = listed.next()
+	if listed, ok = msg.(secretsListedMsg); ok {
+		msg = listed.next()
+	}
+	if loaded, ok := msg.(secretsLoadedMsg); !ok || len(loaded.items) != 2 {
+		t.Fatalf("Expected both secrets once every namespace is listed, but got %#v", msg)
+	}
+}
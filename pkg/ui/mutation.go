@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// mutationPreviewMsg carries the server-side dry run of a change started from the
+// TUI, so it can be confirmed before the real request is sent.
+type mutationPreviewMsg struct {
+	title   string            // What is about to happen, e.g. "Update app-db".
+	changes []kube.DataChange // The field-level changes reported by the dry run.
+	err     error
+	warning string  // Why the change may not last, e.g. the GitOps object managing the secret.
+	apply   tea.Cmd // Sends the real request.
+	force   tea.Cmd // Previews the change again, taking over the fields other managers applied.
+}
+
+// previewMutation runs a server-side dry run of a change started from the TUI. The
+// change can be undone once applied. Changes to secrets managed by a GitOps tool
+// are confirmed with a warning that they will be reverted.
+func previewMutation(clientset kube.Client, mu kube.Mutation, title string, apply tea.Cmd) mutationPreviewMsg {
+	changes, err := mu.Preview(clientset)
+	msg := mutationPreviewMsg{title: title, changes: changes, err: err, apply: undoable(clientset, []kube.SecretRef{mu.Ref()}, apply)}
+	if mu.Before != nil {
+		if manager, managed := kube.GitOpsManagerOf(mu.Before); managed {
+			msg.warning = manager.DriftWarning()
+		}
+	}
+	return msg
+}
+
+// handleMutationPreview asks for confirmation of the changes found by a dry run.
+func (m Model) handleMutationPreview(msg mutationPreviewMsg) (Model, tea.Cmd) {
+	var conflictErr *kube.ConflictError
+	if errors.As(msg.err, &conflictErr) && msg.force != nil {
+		m.prompt = newConfirmPrompt(fmt.Sprintf("%s: %v. Take them over?", msg.title, conflictErr), func() tea.Cmd { return msg.force })
+		return m, nil
+	}
+	if msg.err != nil {
+		m.status, m.statusErr = fmt.Sprintf("%s failed: %v", msg.title, msg.err), true
+		return m, nil
+	}
+	if len(msg.changes) == 0 {
+		m.status, m.statusErr = msg.title+": no changes", false
+		return m, nil
+	}
+	fields := make([]string, len(msg.changes))
+	for i, change := range msg.changes {
+		fields[i] = string(change.Kind) + change.Key
+	}
+	question := fmt.Sprintf("%s (%s: %s)?", msg.title, kube.SummarizeDataDiff(msg.changes), strings.Join(fields, " "))
+	if msg.warning != "" {
+		question = "⚠ Secret " + msg.warning + ". " + question
+	}
+	m.prompt = newConfirmPrompt(question, func() tea.Cmd { return msg.apply })
+	return m, nil
+}
+
+// previewUpdate runs a server-side dry run of an update started from the TUI, which
+// can be forced if it conflicts with the fields applied by other managers.
+func previewUpdate(clientset kube.Client, mu kube.Mutation, title, status string) mutationPreviewMsg {
+	msg := previewMutation(clientset, mu, title, applyUpdateCmd(clientset, mu, status))
+	if !mu.Force {
+		msg.force = func() tea.Msg {
+			mu.Force = true
+			return previewUpdate(clientset, mu, title, status)
+		}
+	}
+	return msg
+}
+
+// applyMutationCmd sends the real request of a change confirmed in the TUI.
+func applyMutationCmd(clientset kube.Client, mu kube.Mutation, status string) tea.Cmd {
+	return func() tea.Msg {
+		if _, err := mu.Apply(clientset, false); err != nil {
+			return actionDoneMsg{status: "Update failed", err: err, refresh: true}
+		}
+		return actionDoneMsg{status: status, refresh: true}
+	}
+}
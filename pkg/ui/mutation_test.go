@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestPreviewUpdateConflicts verifies that an update conflicting with the fields of
+// another manager is confirmed before they are taken over.
+func TestPreviewUpdateConflicts(t *testing.T) {
+	clientset := fake.NewClientset()
+	ref := kube.SecretRef{Namespace: "default", Name: "app"}
+	operator := []byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"app","namespace":"default"},"data":{"token":"b2xk"}}`)
+	if _, err := clientset.CoreV1().Secrets(ref.Namespace).Patch(context.TODO(), ref.Name, types.ApplyPatchType, operator, metav1.PatchOptions{FieldManager: "operator"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	before, err := kube.GetSecret(clientset, ref)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	after := before.DeepCopy()
+	after.Data["token"] = []byte("new")
+	m := NewModel(clientset, "default", Options{})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	m, _ = m.handleMutationPreview(previewUpdate(clientset, kube.Mutation{Before: before, After: after}, "Update app", "Updated app"))
+	if m.prompt == nil || !strings.Contains(m.prompt.title, ".data.token (operator). Take them over?") {
+		t.Fatalf("Expected the conflict to be confirmed, but got %+v", m.prompt)
+	}
+
+	t.Run("should preview the forced update once confirmed", func(t *testing.T) {
+		msg, ok := m.prompt.onSubmit("y")().(mutationPreviewMsg)
+		if !ok || msg.err != nil || len(msg.changes) != 1 || msg.force != nil {
+			t.Fatalf("Expected a preview of the forced update, but got %+v", msg)
+		}
+	})
+}
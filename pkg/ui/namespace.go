@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// namespaceSwitchedMsg is sent once the secrets of another namespace have been listed.
+type namespaceSwitchedMsg struct {
+	namespace string
+	items     kube.ItemSource
+}
+
+// namespacePickedMsg starts listing the secrets of the namespace picked on startup.
+type namespacePickedMsg struct{ namespace string }
+
+// promptNamespaceCmd asks for the namespace to switch to, offering the namespaces
+// of the cluster as fuzzy choices if they can be listed.
+func promptNamespaceCmd(clientset kube.Client) tea.Cmd {
+	return func() tea.Msg {
+		names, _ := kube.ListNamespaceNames(clientset)
+		return showPromptMsg{prompt: newFuzzyPrompt("Switch to namespace:", names, func(namespace string) tea.Cmd {
+			namespace = strings.TrimSpace(namespace)
+			if namespace == "" {
+				return nil
+			}
+			return switchNamespaceCmd(clientset, namespace)
+		})}
+	}
+}
+
+// pickNamespaceCmd asks for the namespace to browse on startup, before anything is
+// listed. Dismissing the picker quits.
+func pickNamespaceCmd(clientset kube.Client) tea.Cmd {
+	return func() tea.Msg {
+		names, _ := kube.ListNamespaceNames(clientset)
+		p := newFuzzyPrompt("Namespace:", names, func(namespace string) tea.Cmd {
+			namespace = strings.TrimSpace(namespace)
+			if namespace == "" {
+				return pickNamespaceCmd(clientset)
+			}
+			return func() tea.Msg { return namespacePickedMsg{namespace: namespace} }
+		})
+		p.onCancel = tea.Quit
+		return showPromptMsg{prompt: p}
+	}
+}
+
+// handleNamespacePicked starts browsing the namespace picked on startup, as if it
+// had been given with --namespace.
+func (m Model) handleNamespacePicked(msg namespacePickedMsg) (Model, tea.Cmd) {
+	m.namespace, m.pickNamespace = msg.namespace, false
+	return m, tea.Batch(m.loadCachedListCmd(), m.loadSearchesCmd(), m.fetchSecretsCmd(), m.fetchQuotaCmd(), m.restartHistory(), m.restartWatch())
+}
+
+// switchNamespaceCmd lists the secrets of another namespace. On failure, the
+// current namespace is kept.
+func switchNamespaceCmd(clientset kube.Client, namespace string) tea.Cmd {
+	return func() tea.Msg {
+		items, err := kube.ListItems(clientset, namespace)
+		if err != nil {
+			return actionDoneMsg{status: "Failed to switch to namespace " + namespace, err: err}
+		}
+		return namespaceSwitchedMsg{namespace: namespace, items: items}
+	}
+}
+
+// handleNamespaceKey handles ctrl+n, which switches namespace, and r in an empty
+// list, which lists the secrets again. It reports whether the key was consumed.
+func (m Model) handleNamespaceKey(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	switch {
+	case msg.String() == "ctrl+n":
+		return m, promptNamespaceCmd(m.clientset), true
+	case msg.String() == "r" && len(m.allItems) == 0 && m.highlightedItem.Name == "":
+		return m, m.fetchSecretsCmd(), true
+	}
+	return m, nil, false
+}
+
+// handleNamespaceSwitched forgets everything about the previous namespace and
+// shows the secrets of the new one.
+func (m Model) handleNamespaceSwitched(msg namespaceSwitchedMsg) (Model, tea.Cmd) {
+	m.namespace, m.allNamespaces, m.skipped, m.quota = msg.namespace, false, nil, nil
+	m.status, m.statusErr = "Switched to namespace "+msg.namespace, false
+	clear(m.selected)
+	clear(m.changed)
+	clear(m.secretCache)
+	clear(m.secretObjects)
+	clear(m.secretErrCache)
+	clear(m.tlsChecks)
+	clear(m.certificates)
+	m.highlightedItem = kube.Item{}
+	m.textinput.SetValue("")
+	m, cmd := m.handleSecretsLoaded(msg.items)
+	if m.ready {
+		m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	}
+	m.searches, m.recall = nil, -1
+	return m, tea.Batch(cmd, m.loadSearchesCmd(), m.fetchQuotaCmd(), m.restartHistory(), m.restartWatch())
+}
+
+// viewEmptyHint explains that there are no secrets to show, and what to do about it.
+func (m *Model) viewEmptyHint() string {
+	where := fmt.Sprintf("namespace '%s'", m.namespace)
+	if m.allNamespaces {
+		where = "any accessible namespace"
+	}
+	return NoteStyle.Render(fmt.Sprintf("No secrets in %s — press ctrl+n to switch namespace, r to refresh.", where))
+}
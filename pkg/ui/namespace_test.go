@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestEmptyNamespace verifies that an empty namespace shows a hint instead of
+// quitting, and can be refreshed or switched away from.
+func TestEmptyNamespace(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"}},
+	)
+	m := NewModel(clientset, "default", Options{})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 200, Height: 40})
+	next, _ := m.Update(fetchSecrets(clientset, "default")())
+	m = next.(Model)
+
+	t.Run("should show a hint", func(t *testing.T) {
+		if m.err != nil {
+			t.Fatalf("Expected no fatal error, but got %v", m.err)
+		}
+		if view := m.viewRightPane(); !strings.Contains(view, "No secrets in namespace 'default'") || !strings.Contains(view, "ctrl+n") {
+			t.Errorf("Expected a hint about the empty namespace, but got:\n%s", view)
+		}
+	})
+	t.Run("should refresh with r", func(t *testing.T) {
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+		if cmd == nil {
+			t.Fatal("Expected r to list the secrets again")
+		}
+		if _, ok := cmd().(kube.ItemSource); !ok {
+			t.Errorf("Expected the secrets to be listed again, but got %T", cmd())
+		}
+	})
+	t.Run("should switch namespace with ctrl+n", func(t *testing.T) {
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+		shown, ok := cmd().(showPromptMsg)
+		if !ok {
+			t.Fatalf("Expected a namespace prompt, but got %T", cmd())
+		}
+		if choices := shown.prompt.input.AvailableSuggestions(); len(choices) != 1 || choices[0] != "prod" {
+			t.Errorf("Expected the namespaces as choices, but got %v", choices)
+		}
+		next, _ := m.Update(shown.prompt.onSubmit("prod")())
+		switched := next.(Model)
+		if switched.namespace != "prod" || len(switched.list.Items()) != 1 {
+			t.Errorf("Expected the secrets of namespace prod, but got %v in %s", switched.list.Items(), switched.namespace)
+		}
+	})
+	t.Run("should keep the namespace if listing fails", func(t *testing.T) {
+		clientset.PrependReactor("list", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, k8serrors.NewForbidden(corev1.Resource("secrets"), "", nil)
+		})
+		next, _ := m.Update(switchNamespaceCmd(clientset, "kube-system")())
+		if kept := next.(Model); kept.namespace != "default" || !kept.statusErr {
+			t.Errorf("Expected an error and namespace default, but got namespace %s", kept.namespace)
+		}
+	})
+}
+
+// TestPickNamespace verifies that the namespace can be picked fuzzily on startup,
+// before any secret is listed.
+func TestPickNamespace(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments-prod"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments-staging"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "payments-staging"}},
+	)
+	m := NewModel(clientset, "default", Options{PickNamespace: true})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 200, Height: 40})
+	// Each subtest opens its own picker, since prompts are shared by the copies of
+	// the model.
+	open := func() Model {
+		next, _ := m.Update(pickNamespaceCmd(clientset)())
+		return next.(Model)
+	}
+	typeText := func(m Model, text string) Model {
+		for _, r := range text {
+			next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			m = next.(Model)
+		}
+		return m
+	}
+
+	t.Run("should show the picker instead of listing secrets", func(t *testing.T) {
+		if view := open().View(); !strings.Contains(view, "Namespace:") || !strings.Contains(view, "payments-staging") {
+			t.Errorf("Expected the namespaces to pick from, but got:\n%s", view)
+		}
+	})
+	t.Run("should list the secrets of the best fuzzy match", func(t *testing.T) {
+		m := typeText(open(), "pstag")
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		picked, ok := cmd().(namespacePickedMsg)
+		if !ok || picked.namespace != "payments-staging" {
+			t.Fatalf("Expected payments-staging to be picked, but got %+v", cmd())
+		}
+		m, _ = next.(Model).handleNamespacePicked(picked)
+		if m.namespace != "payments-staging" || m.pickNamespace {
+			t.Fatalf("Expected namespace payments-staging, but got %s", m.namespace)
+		}
+		m, _ = m.handleSecretsLoaded(fetchSecrets(clientset, m.namespace)().(kube.ItemSource))
+		if len(m.list.Items()) != 1 {
+			t.Errorf("Expected the secret of payments-staging, but got %v", m.list.Items())
+		}
+	})
+	t.Run("should pick another match with the arrow keys", func(t *testing.T) {
+		m := typeText(open(), "pay")
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		_, cmd := next.(Model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if picked, ok := cmd().(namespacePickedMsg); !ok || picked.namespace != "payments-staging" {
+			t.Errorf("Expected the second match to be picked, but got %+v", cmd())
+		}
+	})
+	t.Run("should quit when dismissed", func(t *testing.T) {
+		_, cmd := open().Update(tea.KeyMsg{Type: tea.KeyEsc})
+		if cmd == nil {
+			t.Fatal("Expected to quit, but got nothing")
+		}
+		if _, ok := cmd().(tea.QuitMsg); !ok {
+			t.Errorf("Expected to quit, but got %T", cmd())
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paletteKey opens the command palette in the data pane.
+const paletteKey = ":"
+
+// builtinAction is an action of the data pane, listed in the command palette.
+type builtinAction struct {
+	name string
+	key  string
+}
+
+// builtinActions are the actions bound to keys in the data pane, in the order of
+// the help bar.
+var builtinActions = []builtinAction{
+	{"new", "n"}, {"edit", "e"}, {"import .env", "I"}, {"generate", "G"}, {"rotate", "T"}, {"history", "h"}, {"metadata", "M"}, {"immutable", "i"}, {"reloader", "L"},
+	{"renew cert", "r"}, {"write kubeconfig", "K"}, {"export", "x"}, {"write files", "w"},
+	{"delete", "d"}, {"rename", "R"}, {"label", "l"}, {"annotate", "a"}, {"copy", "p"},
+	{"checksums", "c"}, {"TOTP codes", "o"}, {"reveal", "v"}, {"full values", "F"}, {"undo", "u"},
+	{"goto…", "g"}, {"yank…", "y"},
+}
+
+// builtinActionForKey returns the name of the built-in action bound to a key.
+func builtinActionForKey(key string) (string, bool) {
+	switch key {
+	case "":
+		return "", false
+	case " ", "tab", "q", "ctrl+c", "up", "down", "k", "j", paletteKey:
+		return "navigation", true
+	}
+	for _, a := range builtinActions {
+		if a.key == key {
+			return a.name, true
+		}
+	}
+	return "", false
+}
+
+// handlePaletteKey opens the command palette, or runs the custom action bound to
+// the key. It reports whether the key was consumed.
+func (m Model) handlePaletteKey(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	if msg.String() == paletteKey {
+		m.prompt = m.newPalette()
+		return m, nil, true
+	}
+	for _, a := range m.actions {
+		if a.key != "" && a.key == msg.String() {
+			m, cmd := m.startAction(a)
+			return m, cmd, true
+		}
+	}
+	return m, nil, false
+}
+
+// newPalette creates the command palette, listing the custom actions and those of
+// the secret stores, followed by the built-in ones. Built-in actions run as if their
+// key had been pressed.
+func (m Model) newPalette() *prompt {
+	names := make([]string, 0, len(m.actions)+3*len(m.stores)+len(builtinActions))
+	for _, a := range m.actions {
+		names = append(names, a.name)
+	}
+	for _, s := range m.stores {
+		for _, action := range storeActions(s) {
+			names = append(names, action+s.Name)
+		}
+	}
+	for _, a := range builtinActions {
+		names = append(names, a.name)
+	}
+	return newChoicePrompt("Action:", "", names, func(name string) tea.Cmd {
+		name = strings.TrimSpace(name)
+		for _, a := range m.actions {
+			if strings.EqualFold(a.name, name) {
+				return func() tea.Msg { return paletteActionMsg{action: a} }
+			}
+		}
+		for _, s := range m.stores {
+			for _, action := range storeActions(s) {
+				if strings.EqualFold(action+s.Name, name) {
+					return func() tea.Msg { return paletteStoreMsg{store: s, action: action} }
+				}
+			}
+		}
+		for _, a := range builtinActions {
+			if strings.EqualFold(a.name, name) {
+				return func() tea.Msg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(a.key)} }
+			}
+		}
+		return func() tea.Msg {
+			return actionDoneMsg{status: "Cannot run action", err: fmt.Errorf("unknown action '%s'", name)}
+		}
+	})
+}
+
+// paletteActionMsg runs a custom action chosen in the command palette.
+type paletteActionMsg struct{ action Action }
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestCommandPalette verifies that the palette runs built-in and custom actions,
+// and that custom actions can be bound to keys.
+func TestCommandPalette(t *testing.T) {
+	actions, err := CompileActions([]ActionConfig{{Name: "Send to vault", Key: "ctrl+o", Command: []string{"true"}, Confirm: true}})
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	m := NewModel(fake.NewSimpleClientset(), "default", Options{})
+	m.actions = actions
+	m.focus = rightPane
+	m.highlightedItem = kube.Item{Name: "deploy", Namespace: "default"}
+	m.secretObjects["default/deploy"] = &corev1.Secret{Data: map[string][]byte{"token": []byte("s3cr3t")}}
+
+	submit := func(name string) tea.Msg {
+		next, _, handled := m.handleActionKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(":")})
+		if !handled || next.prompt == nil {
+			t.Fatal("Expected the palette to open")
+		}
+		return next.prompt.onSubmit(name)()
+	}
+
+	if msg, ok := submit("edit").(tea.KeyMsg); !ok || msg.String() != "e" {
+		t.Errorf("Expected the key of the edit action, but got %#v", msg)
+	}
+	msg, ok := submit("send to VAULT").(paletteActionMsg)
+	if !ok || msg.action.name != "Send to vault" {
+		t.Fatalf("Expected the custom action, but got %#v", msg)
+	}
+	if done, ok := submit("nope").(actionDoneMsg); !ok || done.err == nil {
+		t.Errorf("Expected an error for an unknown action, but got %#v", done)
+	}
+
+	t.Run("should ask for confirmation before running a bound action", func(t *testing.T) {
+		_, cmd, handled := m.handleActionKey(tea.KeyMsg{Type: tea.KeyCtrlO})
+		if !handled || cmd == nil {
+			t.Fatal("Expected the key to start the action")
+		}
+		if shown, ok := cmd().(showPromptMsg); !ok || !shown.prompt.confirm {
+			t.Errorf("Expected a confirmation prompt, but got %#v", shown)
+		}
+	})
+}
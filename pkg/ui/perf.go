@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// frameStatsInterval is how often the timings of frames are logged.
+const frameStatsInterval = 5 * time.Second
+
+// frameStats aggregates how long rendering the view and handling messages take,
+// so that the debug log shows the latency of the UI with large lists without a
+// line per frame.
+type frameStats struct {
+	mu      sync.Mutex
+	since   time.Time
+	renders []time.Duration
+	updates []time.Duration
+}
+
+// recordRender adds the time taken to render a frame.
+func (s *frameStats) recordRender(took time.Duration, items int) {
+	s.record(func() { s.renders = append(s.renders, took) }, items)
+}
+
+// recordUpdate adds the time taken to handle a message.
+func (s *frameStats) recordUpdate(took time.Duration, items int) {
+	s.record(func() { s.updates = append(s.updates, took) }, items)
+}
+
+// record adds a timing, and logs the timings of the interval once it is over.
+// Nothing is recorded unless debug messages are logged.
+func (s *frameStats) record(add func(), items int) {
+	if s == nil || !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if s.since.IsZero() {
+		s.since = now
+	}
+	add()
+	if now.Sub(s.since) < frameStatsInterval {
+		return
+	}
+	render50, render95, renderMax := percentiles(s.renders)
+	update50, update95, updateMax := percentiles(s.updates)
+	slog.Debug("frame timings", "items", items, "interval", now.Sub(s.since).Round(time.Millisecond),
+		"renders", len(s.renders), "renderP50", render50, "renderP95", render95, "renderMax", renderMax,
+		"updates", len(s.updates), "updateP50", update50, "updateP95", update95, "updateMax", updateMax)
+	s.since, s.renders, s.updates = now, s.renders[:0], s.updates[:0]
+}
+
+// percentiles returns the median, the 95th percentile, and the maximum of
+// timings, sorting them.
+func percentiles(timings []time.Duration) (p50, p95, maximum time.Duration) {
+	if len(timings) == 0 {
+		return 0, 0, 0
+	}
+	slices.Sort(timings)
+	return timings[len(timings)/2], timings[len(timings)*95/100], timings[len(timings)-1]
+}
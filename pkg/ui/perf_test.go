@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFrameStats verifies that frame timings are logged once per interval, and
+// only at debug level.
+func TestFrameStats(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	var out bytes.Buffer
+	newStats := func() *frameStats {
+		return &frameStats{since: time.Now().Add(-time.Minute), renders: []time.Duration{3 * time.Millisecond}}
+	}
+
+	t.Run("should log the timings of the interval", func(t *testing.T) {
+		slog.SetDefault(slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})))
+		stats := newStats()
+		stats.recordRender(time.Millisecond, 50000)
+		if log := out.String(); !strings.Contains(log, "frame timings") || !strings.Contains(log, "items=50000") || !strings.Contains(log, "renders=2") || !strings.Contains(log, "renderMax=3ms") {
+			t.Errorf("Expected the timings of two renders, but got %q", log)
+		}
+		if len(stats.renders) != 0 {
+			t.Errorf("Expected the timings to be reset, but got %v", stats.renders)
+		}
+	})
+
+	t.Run("should not record without debug logs", func(t *testing.T) {
+		out.Reset()
+		slog.SetDefault(slog.New(slog.NewTextHandler(&out, nil)))
+		stats := newStats()
+		stats.recordUpdate(time.Millisecond, 10)
+		if out.Len() != 0 || len(stats.updates) != 0 {
+			t.Errorf("Expected nothing recorded, but got %q and %v", out.String(), stats.updates)
+		}
+	})
+}
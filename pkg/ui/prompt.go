@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// prompt is a single-line input shown in place of the help bar. It collects the
+// argument of an action (a label, a namespace, a path), or a yes/no answer when
+// confirm is set, and hands the result to onSubmit.
+type prompt struct {
+	title    string
+	input    textinput.Model
+	confirm  bool
+	onSubmit func(value string) tea.Cmd
+	// fuzzy matches the choices with the input fuzzily, best first, and submits
+	// the highlighted match, moved with the arrow keys and tab.
+	fuzzy  bool
+	cursor int
+	// onCancel runs when the prompt is dismissed with esc or ctrl+c.
+	onCancel tea.Cmd
+}
+
+// maxFuzzyMatches is how many matches a fuzzy prompt lists.
+const maxFuzzyMatches = 10
+
+// newInputPrompt creates a prompt that asks for a free-form value.
+func newInputPrompt(title, initial string, onSubmit func(string) tea.Cmd) *prompt {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.SetValue(initial)
+	ti.Focus()
+	return &prompt{title: title, input: ti, onSubmit: onSubmit}
+}
+
+// newChoicePrompt creates a prompt that offers choices, listed next to the input and
+// completed with tab. Values that are not a choice are submitted as typed.
+func newChoicePrompt(title, initial string, choices []string, onSubmit func(string) tea.Cmd) *prompt {
+	p := newInputPrompt(title, initial, onSubmit)
+	p.input.ShowSuggestions = true
+	p.input.SetSuggestions(choices)
+	return p
+}
+
+// newFuzzyPrompt creates a prompt that offers choices matched fuzzily, like the
+// secrets of the list. Values that match no choice are submitted as typed.
+func newFuzzyPrompt(title string, choices []string, onSubmit func(string) tea.Cmd) *prompt {
+	p := newInputPrompt(title, "", onSubmit)
+	p.input.SetSuggestions(choices)
+	p.fuzzy = true
+	return p
+}
+
+// fuzzyMatches returns the choices of a fuzzy prompt matching its input, best
+// first, or all of them while the input is empty.
+func (p *prompt) fuzzyMatches() []string {
+	choices := p.input.AvailableSuggestions()
+	if p.input.Value() == "" {
+		return choices
+	}
+	found := fuzzy.Find(p.input.Value(), choices)
+	matches := make([]string, len(found))
+	for i, match := range found {
+		matches[i] = match.Str
+	}
+	return matches
+}
+
+// newConfirmPrompt creates a prompt that runs onConfirm only if the user answers "y".
+func newConfirmPrompt(title string, onConfirm func() tea.Cmd) *prompt {
+	return &prompt{
+		title:    title,
+		confirm:  true,
+		onSubmit: func(string) tea.Cmd { return onConfirm() },
+	}
+}
+
+// View renders the prompt's title and input field.
+func (p *prompt) View() string {
+	title := lipgloss.NewStyle().Foreground(focusedColor).Bold(true).Render(p.title)
+	if p.confirm {
+		return title + NoteStyle.Render(" (y/N)")
+	}
+	if p.fuzzy {
+		return title + " " + p.input.View() + "  " + p.viewFuzzyMatches()
+	}
+	if !p.input.ShowSuggestions {
+		return title + " " + p.input.View()
+	}
+	choices := p.input.MatchedSuggestions()
+	if p.input.Value() == "" {
+		choices = p.input.AvailableSuggestions()
+	}
+	return title + " " + p.input.View() + NoteStyle.Render("  "+strings.Join(choices, " | "))
+}
+
+// viewFuzzyMatches renders the first matches of a fuzzy prompt, highlighting the
+// one enter submits.
+func (p *prompt) viewFuzzyMatches() string {
+	matches := p.fuzzyMatches()
+	parts := make([]string, 0, min(len(matches), maxFuzzyMatches)+1)
+	for i, match := range matches {
+		if i == maxFuzzyMatches {
+			parts = append(parts, fmt.Sprintf("+%d more", len(matches)-maxFuzzyMatches))
+			break
+		}
+		if i == p.cursor {
+			match = lipgloss.NewStyle().Foreground(focusedColor).Bold(true).Render(match)
+		} else {
+			match = NoteStyle.Render(match)
+		}
+		parts = append(parts, match)
+	}
+	return strings.Join(parts, NoteStyle.Render(" | "))
+}
+
+// handlePromptKey feeds a key press to the open prompt, submitting or dismissing it as needed.
+func (m Model) handlePromptKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	p := m.prompt
+	if p.confirm {
+		m.prompt = nil
+		if msg.String() == "y" || msg.String() == "Y" {
+			return m, p.onSubmit("")
+		}
+		return m, nil
+	}
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.prompt = nil
+		return m, p.onCancel
+	case "enter":
+		m.prompt = nil
+		value := p.input.Value()
+		// The typed value wins over the best match if it is a choice itself.
+		if matches := p.fuzzyMatches(); p.fuzzy && len(matches) > 0 && (p.cursor > 0 || !slices.Contains(matches, value)) {
+			value = matches[min(p.cursor, len(matches)-1)]
+		}
+		return m, p.onSubmit(value)
+	}
+	if p.fuzzy {
+		if shown := min(len(p.fuzzyMatches()), maxFuzzyMatches); shown > 0 {
+			switch msg.String() {
+			case "down", "tab":
+				p.cursor = (p.cursor + 1) % shown
+				return m, nil
+			case "up", "shift+tab":
+				p.cursor = (p.cursor + shown - 1) % shown
+				return m, nil
+			}
+		}
+		p.cursor = 0
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return m, cmd
+}
+
+// showPromptMsg opens another prompt, e.g. the next step of a wizard.
+type showPromptMsg struct{ prompt *prompt }
+
+// wizardStep is a single question asked by a wizard.
+type wizardStep struct {
+	title   string
+	initial string
+	hidden  bool // Masks the answer, e.g. for passwords.
+}
+
+// newWizard chains one input prompt per step and hands all answers to onDone once
+// the last step is submitted. Cancelling any step cancels the whole wizard.
+func newWizard(steps []wizardStep, onDone func(answers []string) tea.Cmd) *prompt {
+	return wizardPrompt(steps, nil, onDone)
+}
+
+// wizardPrompt creates the prompt for the first step that has not been answered yet.
+func wizardPrompt(steps []wizardStep, answers []string, onDone func([]string) tea.Cmd) *prompt {
+	step := steps[len(answers)]
+	p := newInputPrompt(step.title, step.initial, func(value string) tea.Cmd {
+		answers := append(slices.Clone(answers), value)
+		if len(answers) == len(steps) {
+			return onDone(answers)
+		}
+		next := wizardPrompt(steps, answers, onDone)
+		return func() tea.Msg { return showPromptMsg{prompt: next} }
+	})
+	if step.hidden {
+		p.input.EchoMode = textinput.EchoPassword
+	}
+	return p
+}
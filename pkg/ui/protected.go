@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// revealConfirmedMsg is sent once revealing the values of a secret in a
+// protected namespace, or its keys redacted by policy, has been confirmed.
+type revealConfirmedMsg struct{ key string }
+
+// locked reports whether the values of the highlighted secret are masked until
+// revealing them is confirmed.
+func (m *Model) locked() bool {
+	return m.protected.Protects(m.highlightedItem.Namespace) && !m.unlocked[m.highlightedKey()]
+}
+
+// confirmReveal asks before revealing the values of the highlighted secret.
+func (m Model) confirmReveal() *prompt {
+	key := m.highlightedKey()
+	question := fmt.Sprintf("%s is in protected namespace %s. Reveal its values?", m.highlightedItem.Name, m.highlightedItem.Namespace)
+	return newConfirmPrompt(question, func() tea.Cmd {
+		return func() tea.Msg { return revealConfirmedMsg{key: key} }
+	})
+}
+
+// handleRevealConfirmed shows the values of a secret of a protected namespace,
+// or its keys redacted by policy, until kds exits.
+func (m Model) handleRevealConfirmed(msg revealConfirmedMsg) (Model, tea.Cmd) {
+	m.unlocked[msg.key] = true
+	m.refreshSecretData()
+	return m, nil
+}
+
+// guardProtected returns a prompt asking for confirmation before an action
+// reveals, copies, or exports values of secrets in protected namespaces, and
+// then opens next. Without such secrets, it returns next.
+func (m Model) guardProtected(refs []kube.SecretRef, verb string, next *prompt) *prompt {
+	protected := m.protected.ProtectedRefs(refs)
+	if len(protected) == 0 {
+		return next
+	}
+	return newConfirmPrompt(protectedQuestion(verb, protected), func() tea.Cmd {
+		return func() tea.Msg { return showPromptMsg{prompt: next} }
+	})
+}
+
+// guardProtectedCmd is guardProtected for actions that run without a prompt.
+func (m Model) guardProtectedCmd(refs []kube.SecretRef, verb string, cmd tea.Cmd) (Model, tea.Cmd) {
+	protected := m.protected.ProtectedRefs(refs)
+	if len(protected) == 0 {
+		return m, cmd
+	}
+	m.prompt = newConfirmPrompt(protectedQuestion(verb, protected), func() tea.Cmd { return cmd })
+	return m, nil
+}
+
+// protectedQuestion asks whether to go on with an action on secrets of protected namespaces.
+func protectedQuestion(verb string, protected []kube.SecretRef) string {
+	names := make([]string, len(protected))
+	for i, ref := range protected {
+		names[i] = ref.String()
+	}
+	return fmt.Sprintf("%s values of protected %s?", verb, strings.Join(names, ", "))
+}
+
+// formatLockedData renders the keys of a secret in a protected namespace with
+// masked values.
+func (m *Model) formatLockedData(data map[string]string) string {
+	var b strings.Builder
+	b.WriteString(changedStyle.Render(fmt.Sprintf("🔒 Protected namespace %s: press v to reveal the values", m.highlightedItem.Namespace)) + "\n\n")
+	for _, key := range kube.SortedKeys(data) {
+		b.WriteString(fmt.Sprintf("%s: %s\n", key, kube.MaskValue([]byte(data[key]))))
+	}
+	return b.String()
+}
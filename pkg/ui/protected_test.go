@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestProtectedNamespaces verifies that the values of protected namespaces are
+// masked until revealing them is confirmed, and that exporting them asks first.
+func TestProtectedNamespaces(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod-eu"},
+		Data:       map[string][]byte{"password": []byte(base64.StdEncoding.EncodeToString([]byte("s3cr3t")))},
+	})
+	m := NewModel(clientset, "prod-eu", Options{ProtectedNamespaces: kube.ProtectedNamespaces{"prod-*"}})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 200, Height: 40})
+	m, _ = m.handleSecretsLoaded(kube.ItemSource{{Name: "db", Namespace: "prod-eu"}})
+	m.focus = rightPane
+	m, _ = m.handleSecretDataLoaded(fetchSecretData(clientset, "db", "prod-eu", nil)().(secretDataLoadedMsg))
+
+	press := func(m Model, key string) (Model, tea.Cmd) {
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		return next.(Model), cmd
+	}
+
+	t.Run("should mask the values", func(t *testing.T) {
+		if view := m.viewRightPane(); strings.Contains(view, "s3cr3t") || !strings.Contains(view, "Protected namespace prod-eu") {
+			t.Errorf("Expected masked values, but got:\n%s", view)
+		}
+	})
+	t.Run("should reveal the values once confirmed", func(t *testing.T) {
+		m, _ := press(m, "v")
+		if m.prompt == nil || !m.prompt.confirm {
+			t.Fatal("Expected v to ask for confirmation")
+		}
+		m, cmd := press(m, "y")
+		next, _ := m.Update(cmd())
+		m = next.(Model)
+		if view := m.viewRightPane(); !strings.Contains(view, "s3cr3t") {
+			t.Errorf("Expected the revealed values, but got:\n%s", view)
+		}
+	})
+	t.Run("should ask before exporting", func(t *testing.T) {
+		m, _ := press(m, "x")
+		if m.prompt == nil || !strings.Contains(m.prompt.title, "Export values of protected prod-eu/db?") {
+			t.Fatalf("Expected a confirmation, but got %+v", m.prompt)
+		}
+		_, cmd := press(m, "y")
+		if shown, ok := cmd().(showPromptMsg); !ok || shown.prompt.confirm {
+			t.Errorf("Expected the export prompt after the confirmation, but got %#v", cmd())
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxQuickJump is how many rows of a page of the list are numbered for quick
+// jumps.
+const maxQuickJump = 9
+
+// quickJumpNumber returns the number shown in front of a row of the list, from 1
+// at the top of the page, or 0 if the row is not numbered.
+func quickJumpNumber(l list.Model, index int) int {
+	n := index - l.Paginator.Page*l.Paginator.PerPage + 1
+	if n < 1 || n > maxQuickJump {
+		return 0
+	}
+	return n
+}
+
+// quickJumpMarker renders the number of a row of the list.
+func quickJumpMarker(l list.Model, index int) string {
+	if n := quickJumpNumber(l, index); n > 0 {
+		return fmt.Sprintf("%d ", n)
+	}
+	return "  "
+}
+
+// handleQuickJumpKey highlights a numbered row of the list: 1–9 in the data pane,
+// and alt+1–9 in the list pane, where digits are typed into the search. It
+// reports whether the key was consumed.
+func (m Model) handleQuickJumpKey(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	key := msg.String()
+	if m.pendingChord != nil {
+		return m, nil, false
+	}
+	if m.focus == leftPane {
+		var alt bool
+		if key, alt = strings.CutPrefix(key, "alt+"); !alt {
+			return m, nil, false
+		}
+	}
+	if len(key) != 1 || key[0] < '1' || key[0] > '0'+maxQuickJump {
+		return m, nil, false
+	}
+	index := m.list.Paginator.Page*m.list.Paginator.PerPage + int(key[0]-'1')
+	if index >= len(m.list.VisibleItems()) {
+		m.status, m.statusErr = fmt.Sprintf("No row %s in the list", key), true
+		return m, nil, true
+	}
+	m.list.Select(index)
+	return m, m.highlightSelected(), true
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestQuickJump verifies that the rows of the list are numbered, and that their
+// number highlights them from either pane.
+func TestQuickJump(t *testing.T) {
+	m := NewModel(fake.NewSimpleClientset(), "default", Options{})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = m.handleSecretsLoaded(kube.ItemSource{{Name: "api", Namespace: "default"}, {Name: "cache", Namespace: "default"}, {Name: "db", Namespace: "default"}})
+
+	t.Run("should number the rows shown", func(t *testing.T) {
+		view := m.viewLeftPane()
+		if !strings.Contains(view, "1 api") || !strings.Contains(view, "3 db") {
+			t.Errorf("Expected numbered rows, but got:\n%s", view)
+		}
+	})
+	t.Run("should jump with alt and a number in the list pane", func(t *testing.T) {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3"), Alt: true})
+		m := next.(Model)
+		if m.highlightedItem.Name != "db" || m.textinput.Value() != "" {
+			t.Errorf("Expected db to be highlighted without searching, but got %q and search %q", m.highlightedItem.Name, m.textinput.Value())
+		}
+	})
+	t.Run("should type the number into the search without alt", func(t *testing.T) {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+		if m := next.(Model); m.textinput.Value() != "2" {
+			t.Errorf("Expected 2 to be searched, but got %q", m.textinput.Value())
+		}
+	})
+	t.Run("should jump with a number in the data pane", func(t *testing.T) {
+		m := m
+		m.focus = rightPane
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+		if m := next.(Model); m.highlightedItem.Name != "cache" {
+			t.Errorf("Expected cache to be highlighted, but got %q", m.highlightedItem.Name)
+		}
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("7")})
+		if m := next.(Model); !m.statusErr {
+			t.Errorf("Expected an error for a row not shown, but got status %q", m.status)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// quotaLoadedMsg carries the secret quota of a namespace, or nil if it has none.
+type quotaLoadedMsg struct {
+	namespace string
+	quota     *kube.SecretQuota
+}
+
+// fetchQuotaCmd reads the secret quota of the current namespace. Without access to
+// ResourceQuotas, the quota is simply not shown.
+func (m Model) fetchQuotaCmd() tea.Cmd {
+	if m.allNamespaces {
+		return nil
+	}
+	clientset, namespace := m.clientset, m.namespace
+	return func() tea.Msg {
+		quota, err := kube.GetSecretQuota(clientset, namespace)
+		if err != nil {
+			slog.Debug("failed to read the secret quota", "namespace", namespace, "error", err)
+		}
+		return quotaLoadedMsg{namespace: namespace, quota: quota}
+	}
+}
+
+// handleQuotaLoaded keeps the quota of the namespace that is still shown.
+func (m Model) handleQuotaLoaded(msg quotaLoadedMsg) Model {
+	if msg.namespace == m.namespace && !m.allNamespaces {
+		m.quota = msg.quota
+	}
+	return m
+}
+
+// viewQuota renders the secret quota usage for the help bar, highlighted once less
+// than a tenth of it is left.
+func (m *Model) viewQuota() string {
+	if m.quota == nil {
+		return ""
+	}
+	usage := "quota: " + m.quota.String()
+	if m.quota.Remaining()*10 < m.quota.Hard {
+		return changedStyle.Render(usage)
+	}
+	return NoteStyle.Render(usage)
+}
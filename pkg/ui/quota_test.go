@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestQuota verifies that the secret quota of the namespace is shown in the help bar.
+func TestQuota(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "default"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourceSecrets: resource.MustParse("20")},
+			Used: corev1.ResourceList{corev1.ResourceSecrets: resource.MustParse("19")},
+		},
+	})
+	m := NewModel(clientset, "default", Options{})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 400, Height: 30})
+	m, _ = m.handleSecretsLoaded(kube.ItemSource{{Name: "db", Namespace: "default"}})
+
+	next, _ := m.Update(m.fetchQuotaCmd()())
+	m = next.(Model)
+	if help := m.viewHelp(); !strings.Contains(help, "quota: 19/20 secrets") {
+		t.Errorf("Expected the quota in the help bar, but got:\n%s", help)
+	}
+
+	t.Run("should ignore the quota of another namespace", func(t *testing.T) {
+		m := m.handleQuotaLoaded(quotaLoadedMsg{namespace: "other"})
+		if m.quota == nil {
+			t.Error("Expected the quota of the current namespace to be kept")
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// Delays between attempts to reach the API server again, doubling up to the maximum.
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// reconnectTickMsg starts the next attempt to reach the API server.
+type reconnectTickMsg struct{}
+
+// reconnectFailedMsg is sent when the API server is still unreachable.
+type reconnectFailedMsg struct{ err error }
+
+// reconnectedMsg is sent when the API server answers again, with the fresh list
+// of secrets.
+type reconnectedMsg struct{ loaded tea.Msg }
+
+// reconnectDelay returns how long to wait before the given attempt.
+func reconnectDelay(attempt int) time.Duration {
+	delay := minReconnectDelay
+	for i := 1; i < attempt && delay < maxReconnectDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxReconnectDelay)
+}
+
+// connectionLost reports whether err means that the API server cannot be
+// reached, e.g. after a VPN drop, rather than that the request was refused.
+func connectionLost(err error) bool {
+	return kube.ClassifyFetchError(err) == kube.FetchErrorTransient
+}
+
+// startReconnecting shows the reconnecting banner and schedules the first attempt
+// to reach the API server again, unless one is already scheduled.
+func (m Model) startReconnecting(err error) (Model, tea.Cmd) {
+	m.reconnectErr = err
+	if m.reconnectAttempt > 0 {
+		return m, nil
+	}
+	slog.Debug("lost the connection to the API server", "error", err)
+	m.reconnectAttempt = 1
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	return m, tea.Tick(reconnectDelay(m.reconnectAttempt), func(time.Time) tea.Msg { return reconnectTickMsg{} })
+}
+
+// reconnectCmd lists the secrets again to find out whether the API server is back.
+func (m Model) reconnectCmd() tea.Cmd {
+	fetch := m.fetchSecretsCmd()
+	return func() tea.Msg {
+		msg := fetch()
+		if failed, ok := msg.(fatalErrorMsg); ok && connectionLost(failed.err) {
+			return reconnectFailedMsg{err: failed.err}
+		}
+		return reconnectedMsg{loaded: msg}
+	}
+}
+
+// handleReconnectFailed schedules the next attempt, waiting longer each time.
+func (m Model) handleReconnectFailed(msg reconnectFailedMsg) (Model, tea.Cmd) {
+	m.reconnectErr = msg.err
+	m.reconnectAttempt++
+	slog.Debug("the API server is still unreachable", "attempt", m.reconnectAttempt, "error", msg.err)
+	return m, tea.Tick(reconnectDelay(m.reconnectAttempt), func(time.Time) tea.Msg { return reconnectTickMsg{} })
+}
+
+// handleReconnected hides the banner and resumes with the fresh list of secrets,
+// fetching again the secrets whose data failed to load meanwhile, and watching
+// the changes again.
+func (m Model) handleReconnected(msg reconnectedMsg) (Model, tea.Cmd) {
+	slog.Debug("reconnected to the API server", "attempts", m.reconnectAttempt)
+	m.reconnectAttempt, m.reconnectErr = 0, nil
+	clear(m.secretErrCache)
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	m, cmd := m.handleMessages(msg.loaded)
+	return m, tea.Batch(cmd, m.restartWatch(), m.restartHistory())
+}
+
+// viewReconnectBanner tells that the API server is unreachable, on a line above
+// the help, while kds tries to reconnect.
+func (m *Model) viewReconnectBanner() string {
+	if m.reconnectAttempt == 0 {
+		return ""
+	}
+	banner := fmt.Sprintf("⟳ Lost the connection to the API server, reconnecting… (attempt %d)", m.reconnectAttempt)
+	line := "  " + changedStyle.Render(banner) + NoteStyle.Render("  •  "+m.reconnectErr.Error())
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(line) + "\n"
+}
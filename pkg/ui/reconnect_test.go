@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestReconnect verifies that losing the API server mid-session shows a banner
+// and retries, instead of quitting.
+func TestReconnect(t *testing.T) {
+	newModel := func() Model {
+		m := NewModel(fake.NewSimpleClientset(), "default", Options{})
+		m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 200, Height: 40})
+		next, _ := m.Update(kube.ItemSource{{Name: "api", Namespace: "default"}})
+		return next.(Model)
+	}
+	lost := fatalErrorMsg{err: k8serrors.NewServiceUnavailable("connection refused")}
+
+	t.Run("should show a banner instead of quitting", func(t *testing.T) {
+		next, cmd := newModel().Update(lost)
+		m := next.(Model)
+		if m.err != nil || cmd == nil {
+			t.Fatalf("Expected a reconnect attempt to be scheduled, but got error %v", m.err)
+		}
+		if help := m.viewHelp(); !strings.Contains(help, "reconnecting… (attempt 1)") {
+			t.Errorf("Expected a reconnecting banner, but got:\n%s", help)
+		}
+	})
+	t.Run("should still quit on other errors", func(t *testing.T) {
+		next, _ := newModel().Update(fatalErrorMsg{err: errors.New("boom")})
+		if next.(Model).err == nil {
+			t.Error("Expected the error to be fatal")
+		}
+	})
+	t.Run("should count the failed attempts", func(t *testing.T) {
+		next, _ := newModel().Update(lost)
+		next, _ = next.Update(reconnectFailedMsg{err: lost.err})
+		m := next.(Model)
+		if help := m.viewHelp(); !strings.Contains(help, "(attempt 2)") {
+			t.Errorf("Expected the second attempt in the banner, but got:\n%s", help)
+		}
+	})
+	t.Run("should resume once the API server answers", func(t *testing.T) {
+		next, _ := newModel().Update(lost)
+		m := next.(Model)
+		m.secretErrCache["default/api"] = lost.err
+		next, _ = m.Update(reconnectedMsg{loaded: kube.ItemSource{{Name: "api", Namespace: "default"}, {Name: "db", Namespace: "default"}}})
+		m = next.(Model)
+		if help := m.viewHelp(); strings.Contains(help, "reconnecting") {
+			t.Errorf("Expected the banner to be hidden, but got:\n%s", help)
+		}
+		if len(m.secretErrCache) != 0 {
+			t.Errorf("Expected the errors to be cleared, but got %v", m.secretErrCache)
+		}
+		if len(m.list.Items()) != 2 {
+			t.Errorf("Expected the fresh list, but got %v", m.list.Items())
+		}
+	})
+	t.Run("should watch the changes again", func(t *testing.T) {
+		m := NewModel(fake.NewSimpleClientset(), "default", Options{Watch: true})
+		next, _ := m.Update(lost)
+		m = next.(Model)
+		generation := m.watchGeneration
+		next, _ = m.Update(reconnectedMsg{loaded: kube.ItemSource{{Name: "api", Namespace: "default"}}})
+		m = next.(Model)
+		defer m.watchStop()
+		if m.watchGeneration != generation+1 {
+			t.Errorf("Expected the watch to be restarted, but got watch %d", m.watchGeneration)
+		}
+	})
+}
+
+// TestReconnectDelay verifies that the delay between attempts doubles up to the maximum.
+func TestReconnectDelay(t *testing.T) {
+	for attempt, expected := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 10: maxReconnectDelay} {
+		if delay := reconnectDelay(attempt); delay != expected {
+			t.Errorf("Expected a delay of %s before attempt %d, but got %s", expected, attempt, delay)
+		}
+	}
+}
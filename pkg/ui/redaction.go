@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// redactionOf returns how the redaction policy shows a key of the highlighted
+// secret. Keys to confirm are shown once revealing them was confirmed.
+func (m *Model) redactionOf(key string) kube.RedactionMode {
+	mode := m.redaction.ModeOf(key)
+	if mode == kube.RedactConfirm && m.unlocked[m.highlightedKey()] {
+		return kube.RedactNone
+	}
+	return mode
+}
+
+// redacts reports whether the redaction policy masks a key of data.
+func (m *Model) redacts(data map[string]string) bool {
+	for key := range data {
+		if m.redactionOf(key) != kube.RedactNone {
+			return true
+		}
+	}
+	return false
+}
+
+// formatRedacted renders a value masked by the redaction policy.
+func formatRedacted(value []byte, mode kube.RedactionMode) string {
+	note := "(v to reveal)"
+	if mode == kube.RedactMask {
+		note = "(masked by policy)"
+	}
+	return kube.MaskValue(value) + " " + NoteStyle.Render(note)
+}
+
+// keysToConfirm returns the keys of the highlighted secret that stay masked until
+// revealing them is confirmed.
+func (m Model) keysToConfirm() []string {
+	if m.unlocked[m.highlightedKey()] {
+		return nil
+	}
+	return kube.KeysWithMode(m.redaction, m.secretCache[m.highlightedKey()], kube.RedactConfirm)
+}
+
+// confirmRedactedReveal asks before revealing keys of the highlighted secret
+// redacted by policy.
+func (m Model) confirmRedactedReveal(keys []string) *prompt {
+	key := m.highlightedKey()
+	question := fmt.Sprintf("Reveal %s of %s, redacted by policy?", strings.Join(keys, ", "), m.highlightedItem.Name)
+	return newConfirmPrompt(question, func() tea.Cmd {
+		return func() tea.Msg { return revealConfirmedMsg{key: key} }
+	})
+}
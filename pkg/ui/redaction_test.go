@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestRedaction verifies that keys redacted by policy are masked, and that keys
+// to confirm are revealed with v once confirmed.
+func TestRedaction(t *testing.T) {
+	encode := func(s string) []byte { return []byte(base64.StdEncoding.EncodeToString([]byte(s))) }
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Data: map[string][]byte{
+			"SIGNING_PRIVATE_KEY": encode("-----BEGIN KEY-----"),
+			"API_TOKEN":           encode("t0k3n"),
+			"API_URL":             encode("https://api.example.com"),
+		},
+	})
+	policy, err := kube.CompileRedactionPolicy([]kube.RedactionRuleConfig{
+		{Keys: "_PRIVATE_KEY$"},
+		{Keys: "_TOKEN$", Mode: kube.RedactConfirm},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	m := NewModel(clientset, "default", Options{Redaction: policy})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 200, Height: 40})
+	m, _ = m.handleSecretsLoaded(kube.ItemSource{{Name: "app", Namespace: "default"}})
+	m.focus = rightPane
+	m, _ = m.handleSecretDataLoaded(fetchSecretData(clientset, "app", "default", nil)().(secretDataLoadedMsg))
+
+	t.Run("should mask the redacted keys", func(t *testing.T) {
+		view := m.viewRightPane()
+		if strings.Contains(view, "BEGIN KEY") || strings.Contains(view, "t0k3n") || !strings.Contains(view, "https://api.example.com") {
+			t.Errorf("Expected only API_URL in clear, but got:\n%s", view)
+		}
+	})
+	t.Run("should reveal the keys to confirm once confirmed", func(t *testing.T) {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+		m := next.(Model)
+		if m.prompt == nil || !strings.Contains(m.prompt.title, "Reveal API_TOKEN of app") {
+			t.Fatalf("Expected a confirmation for API_TOKEN, but got %+v", m.prompt)
+		}
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+		next, _ = next.Update(cmd())
+		m = next.(Model)
+		view := m.viewRightPane()
+		if !strings.Contains(view, "t0k3n") || strings.Contains(view, "BEGIN KEY") {
+			t.Errorf("Expected API_TOKEN revealed and the private key still masked, but got:\n%s", view)
+		}
+	})
+}
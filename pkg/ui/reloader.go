@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// reloaderCheckedMsg is sent once the workloads that could get a Reloader
+// annotation for a secret are known.
+type reloaderCheckedMsg struct {
+	ref       kube.SecretRef
+	workloads []kube.WorkloadRef
+	err       error
+}
+
+// checkReloaderCmd looks up the workloads of a secret that could get a Reloader annotation.
+func checkReloaderCmd(clientset kube.Client, ref kube.SecretRef) tea.Cmd {
+	return func() tea.Msg {
+		workloads, err := kube.FindConsumers(clientset, ref)
+		return reloaderCheckedMsg{ref: ref, workloads: kube.ManualRestarts(workloads), err: err}
+	}
+}
+
+// handleReloaderChecked asks for confirmation before annotating workloads for Reloader.
+func (m Model) handleReloaderChecked(msg reloaderCheckedMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status, m.statusErr = fmt.Sprintf("Could not find the workloads using %s: %v", msg.ref.Name, msg.err), true
+		return m, nil
+	}
+	if len(msg.workloads) == 0 {
+		m.status, m.statusErr = "No workload using "+msg.ref.Name+" needs a Reloader annotation", false
+		return m, nil
+	}
+	names := make([]string, len(msg.workloads))
+	for i, workload := range msg.workloads {
+		names[i] = workload.String()
+	}
+	m.prompt = newConfirmPrompt(fmt.Sprintf("Add Reloader annotation for %s to %s?", msg.ref.Name, strings.Join(names, ", ")), func() tea.Cmd {
+		return func() tea.Msg {
+			if err := kube.AddReloaderAnnotations(m.clientset, msg.ref, msg.workloads); err != nil {
+				return actionDoneMsg{status: "Annotation failed", err: err}
+			}
+			return actionDoneMsg{status: fmt.Sprintf("Annotated %d workload(s) for Reloader", len(msg.workloads))}
+		}
+	})
+	return m, nil
+}
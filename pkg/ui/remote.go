@@ -0,0 +1,26 @@
+package ui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// localActions are the actions of the data pane that run on the machine of kds
+// rather than in the cluster: they open an editor, read or write files, or use
+// the clipboard. They are disabled in remote sessions.
+var localActions = map[string]string{
+	"e": "edit",
+	"I": "import .env",
+	"K": "write kubeconfig",
+	"x": "export",
+	"w": "write files",
+	"y": "yank",
+}
+
+// refuseLocalAction refuses the local actions in remote sessions, reporting
+// whether the key was consumed.
+func (m Model) refuseLocalAction(msg tea.KeyMsg) (Model, bool) {
+	name, ok := localActions[msg.String()]
+	if !m.remote || !ok || m.pendingChord != nil {
+		return m, false
+	}
+	m.status, m.statusErr = name+" is disabled in remote sessions: it would run on the server", true
+	return m, true
+}
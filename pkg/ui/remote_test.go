@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestRemote verifies that remote sessions refuse the actions that would run on
+// the server, including custom ones.
+func TestRemote(t *testing.T) {
+	actions, err := CompileActions([]ActionConfig{{Name: "open", Key: "ctrl+o", Command: []string{"xdg-open", "{{.Secret}}"}}})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	m := NewModel(fake.NewSimpleClientset(), "default", Options{Actions: actions, Remote: true})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = m.handleSecretsLoaded(kube.ItemSource{{Name: "db", Namespace: "default"}})
+	m.focus = rightPane
+
+	if len(m.actions) != 0 {
+		t.Errorf("Expected no custom actions, but got %d", len(m.actions))
+	}
+	for _, key := range []string{"e", "K", "x", "w", "I", "y"} {
+		t.Run("should refuse "+key, func(t *testing.T) {
+			next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+			m := next.(Model)
+			if cmd != nil || m.prompt != nil || m.pendingChord != nil || !m.statusErr || !strings.Contains(m.status, "disabled in remote sessions") {
+				t.Errorf("Expected %s to be refused, but got status %q", key, m.status)
+			}
+		})
+	}
+	t.Run("should refuse new TLS secrets read from files", func(t *testing.T) {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+		m := next.(Model)
+		if m.prompt == nil {
+			t.Fatal("Expected n to ask for the type of the secret")
+		}
+		msg, ok := m.prompt.onSubmit("tls")().(actionDoneMsg)
+		if !ok || msg.err == nil || !strings.Contains(msg.err.Error(), "disabled in remote sessions") {
+			t.Errorf("Expected tls to be refused, but got %+v", msg)
+		}
+	})
+	t.Run("should keep the actions on the cluster", func(t *testing.T) {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+		if m := next.(Model); m.prompt == nil {
+			t.Error("Expected l to ask for labels")
+		}
+	})
+}
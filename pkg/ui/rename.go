@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// renameCheckedMsg is sent once the usages of a secret about to be renamed are known.
+type renameCheckedMsg struct {
+	ref     kube.SecretRef
+	newName string
+	usages  []kube.SecretUsage
+	err     error
+}
+
+// checkRenameCmd looks up the usages of a secret before it is renamed from the TUI.
+func checkRenameCmd(clientset kube.Client, ref kube.SecretRef, newName string) tea.Cmd {
+	return func() tea.Msg {
+		idx, err := kube.BuildUsageIndex(clientset, ref.Namespace)
+		if err != nil {
+			return renameCheckedMsg{ref: ref, newName: newName, err: err}
+		}
+		return renameCheckedMsg{ref: ref, newName: newName, usages: idx[ref.Name]}
+	}
+}
+
+// renameSecretCmd renames a secret from the TUI.
+func renameSecretCmd(clientset kube.Client, ref kube.SecretRef, newName string) tea.Cmd {
+	renamed := kube.SecretRef{Namespace: ref.Namespace, Name: newName}
+	return undoable(clientset, []kube.SecretRef{ref, renamed}, func() tea.Msg {
+		if err := kube.RenameSecret(clientset, ref, newName); err != nil {
+			return actionDoneMsg{status: "Rename failed", err: err, refresh: true}
+		}
+		return actionDoneMsg{status: fmt.Sprintf("Renamed %s to %s", ref.Name, newName), refresh: true}
+	})
+}
+
+// handleRenameChecked asks for confirmation of a rename, warning about references to the old name.
+func (m Model) handleRenameChecked(msg renameCheckedMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status, m.statusErr = fmt.Sprintf("Rename failed: could not check usages: %v", msg.err), true
+		return m, nil
+	}
+	question := fmt.Sprintf("Rename %s to %s?", msg.ref.Name, msg.newName)
+	if len(msg.usages) > 0 {
+		kinds := make([]string, 0, len(msg.usages))
+		for _, usage := range msg.usages {
+			kinds = append(kinds, usage.Kind+"/"+usage.Name)
+		}
+		question = fmt.Sprintf("Still used by %s. %s", strings.Join(kinds, ", "), question)
+	}
+	m.prompt = newConfirmPrompt(question, func() tea.Cmd {
+		return renameSecretCmd(m.clientset, msg.ref, msg.newName)
+	})
+	return m, nil
+}
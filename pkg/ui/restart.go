@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// restartOfferMsg is sent after a secret was changed from the TUI and workloads
+// consuming it were found, so the user can restart them.
+type restartOfferMsg struct {
+	done      actionDoneMsg
+	namespace string
+	workloads []kube.WorkloadRef
+}
+
+// applyUpdateCmd sends an update confirmed in the TUI, then looks for workloads
+// that need a restart to pick it up.
+func applyUpdateCmd(clientset kube.Client, mu kube.Mutation, status string) tea.Cmd {
+	return func() tea.Msg {
+		if _, err := mu.Apply(clientset, false); err != nil {
+			return actionDoneMsg{status: "Update failed", err: err, refresh: true}
+		}
+		done := actionDoneMsg{status: status, refresh: true}
+		workloads, err := kube.FindConsumers(clientset, mu.Ref())
+		if err != nil {
+			done.status += fmt.Sprintf(" (could not look for workloads to restart: %v)", err)
+			return done
+		}
+		if note := kube.AutoRestartNote(workloads); note != "" {
+			done.status += " (" + note + ")"
+		}
+		if workloads = kube.ManualRestarts(workloads); len(workloads) == 0 {
+			return done
+		}
+		return restartOfferMsg{done: done, namespace: mu.Ref().Namespace, workloads: workloads}
+	}
+}
+
+// handleRestartOffer shows the outcome of an update and offers to restart the
+// workloads that consume the changed secret.
+func (m Model) handleRestartOffer(msg restartOfferMsg) (Model, tea.Cmd) {
+	m, cmd := m.handleActionDone(msg.done)
+	names := make([]string, len(msg.workloads))
+	for i, workload := range msg.workloads {
+		names[i] = workload.String()
+	}
+	m.prompt = newConfirmPrompt(fmt.Sprintf("Restart %s?", strings.Join(names, ", ")), func() tea.Cmd {
+		return func() tea.Msg {
+			if err := kube.RestartWorkloads(m.clientset, msg.namespace, msg.workloads); err != nil {
+				return actionDoneMsg{status: "Restart failed", err: err}
+			}
+			return actionDoneMsg{status: fmt.Sprintf("Restarted %d workload(s)", len(msg.workloads))}
+		}
+	})
+	return m, cmd
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// rotateWizard asks for the key of a secret to rotate and the generator of its new
+// value.
+func (m Model) rotateWizard(ref kube.SecretRef) *prompt {
+	steps := []wizardStep{
+		{title: "Rotate key of " + ref.Name + ":"},
+		{title: "Generator (" + generatorHint + ", with an optional :length; empty to match the current value):"},
+	}
+	return newWizard(steps, func(answers []string) tea.Cmd {
+		return prepareRotationCmd(m.clientset, ref, answers[0], answers[1], m.keepPrevious)
+	})
+}
+
+// prepareRotationCmd previews the rotation of a key with a server-side dry run.
+// Once applied, the workloads using the secret can be restarted.
+func prepareRotationCmd(clientset kube.Client, ref kube.SecretRef, key, spec, keepPrevious string) tea.Cmd {
+	return func() tea.Msg {
+		rotOpts := kube.RotationOptions{KeepPrevious: keepPrevious}
+		if rotOpts.KeepPrevious == "" {
+			rotOpts.KeepPrevious = kube.KeepPreviousKey
+		}
+		if spec != "" {
+			g, err := kube.ParseGenerator(spec)
+			if err != nil {
+				return actionDoneMsg{status: "Rotate failed", err: err}
+			}
+			rotOpts.Generator = g
+		}
+		mu, err := kube.PrepareRotation(clientset, ref, key, rotOpts, time.Now())
+		if err != nil {
+			return actionDoneMsg{status: "Rotate failed", err: err}
+		}
+		title := kube.UpdateTitle(clientset, "Rotate "+key+" of", ref)
+		return previewUpdate(clientset, mu, title, "Rotated "+key+" of "+ref.Name)
+	}
+}
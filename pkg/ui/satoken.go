@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// tokenFields renders the claims for the detail pane, with a warning for tokens that
+// never expire or have expired.
+func tokenFields(t *kube.ServiceAccountToken, now time.Time) []typedField {
+	fields := []typedField{
+		{label: "Service account", value: t.Namespace + "/" + t.ServiceAccount},
+		{label: "Issuer", value: t.Issuer},
+	}
+	if len(t.Audience) > 0 {
+		fields = append(fields, typedField{label: "Audience", value: strings.Join(t.Audience, ", ")})
+	}
+	if !t.IssuedAt.IsZero() {
+		fields = append(fields, typedField{label: "Issued", value: t.IssuedAt.UTC().Format(time.RFC3339)})
+	}
+	var bound []string
+	for _, claim := range [][2]string{{"pod", t.Pod}, {"secret", t.Secret}, {"node", t.Node}} {
+		if claim[1] != "" {
+			bound = append(bound, claim[0]+" "+claim[1])
+		}
+	}
+	if len(bound) > 0 {
+		fields = append(fields, typedField{label: "Bound to", value: strings.Join(bound, ", ")})
+	}
+	switch {
+	case t.ExpiresAt.IsZero() && t.Legacy:
+		fields = append(fields, typedField{label: "Expires", value: changedStyle.Render(
+			"⚠ never: legacy long-lived token, migrate to short-lived tokens from the TokenRequest API (kds token)")})
+	case t.ExpiresAt.IsZero():
+		fields = append(fields, typedField{label: "Expires", value: changedStyle.Render("⚠ never")})
+	case !now.Before(t.ExpiresAt):
+		fields = append(fields, typedField{label: "Expires", value: removedStyle.Render(
+			fmt.Sprintf("✘ expired %s ago (%s)", duration.HumanDuration(now.Sub(t.ExpiresAt)), t.ExpiresAt.UTC().Format(time.RFC3339)))})
+	default:
+		fields = append(fields, typedField{label: "Expires", value: fmt.Sprintf("in %s (%s)",
+			duration.HumanDuration(t.ExpiresAt.Sub(now)), t.ExpiresAt.UTC().Format(time.RFC3339))})
+	}
+	return fields
+}
+
+// renderServiceAccountToken shows the claims of the token instead of the token itself.
+func renderServiceAccountToken(secret *corev1.Secret, reveal bool) []typedField {
+	data := string(secret.Data[corev1.ServiceAccountTokenKey])
+	if data == "" {
+		return []typedField{{label: "Token", value: NoteStyle.Render("(not populated yet)")}}
+	}
+	token := kube.MaskValue([]byte(data)) + " " + NoteStyle.Render("(v to reveal)")
+	if reveal {
+		token = data
+	}
+	fields := []typedField{{label: "Token", value: token}}
+	sa, err := kube.ParseServiceAccountToken(data)
+	if err != nil {
+		return append(fields, typedField{label: "Claims", value: errorStyle.Render(fmt.Sprintf("unreadable: %v", err))})
+	}
+	return append(fields, tokenFields(sa, time.Now())...)
+}
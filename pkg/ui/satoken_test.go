@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestJWT builds an unsigned JWT with the given JSON payload.
+func newTestJWT(payload string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"RS256"}`)) + "." + encode([]byte(payload)) + ".c2lnbmF0dXJl"
+}
+
+// TestParseServiceAccountToken verifies that the claims of bound and legacy tokens are read.
+func TestParseServiceAccountToken(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("should read the claims of a bound token", func(t *testing.T) {
+		sa, err := kube.ParseServiceAccountToken(newTestJWT(`{"aud":["https://kubernetes.default.svc"],"exp":1700003600,"iat":1700000000,` +
+			`"iss":"https://kubernetes.default.svc.cluster.local","sub":"system:serviceaccount:ci:builder",` +
+			`"kubernetes.io":{"namespace":"ci","serviceaccount":{"name":"builder"},"pod":{"name":"build-1"}}}`))
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if sa.Legacy || sa.Namespace != "ci" || sa.ServiceAccount != "builder" || sa.Pod != "build-1" {
+			t.Errorf("Expected a bound token of ci/builder for pod build-1, but got %+v", sa)
+		}
+		out := fieldsText(tokenFields(sa, now))
+		for _, expected := range []string{"Audience: https://kubernetes.default.svc", "Bound to: pod build-1", "Expires: in 60m"} {
+			if !strings.Contains(out, expected) {
+				t.Errorf("Expected '%s', but got:\n%s", expected, out)
+			}
+		}
+	})
+
+	t.Run("should warn about legacy tokens", func(t *testing.T) {
+		sa, err := kube.ParseServiceAccountToken(newTestJWT(`{"iss":"kubernetes/serviceaccount","sub":"system:serviceaccount:default:deployer",` +
+			`"kubernetes.io/serviceaccount/namespace":"default","kubernetes.io/serviceaccount/secret.name":"deployer-token",` +
+			`"kubernetes.io/serviceaccount/service-account.name":"deployer"}`))
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		out := fieldsText(tokenFields(sa, now))
+		for _, expected := range []string{"Service account: default/deployer", "Bound to: secret deployer-token", "legacy long-lived token"} {
+			if !strings.Contains(out, expected) {
+				t.Errorf("Expected '%s', but got:\n%s", expected, out)
+			}
+		}
+	})
+
+	t.Run("should flag expired tokens", func(t *testing.T) {
+		sa, err := kube.ParseServiceAccountToken(newTestJWT(`{"aud":"api","exp":1699996400,"sub":"system:serviceaccount:a:b","kubernetes.io":{}}`))
+		if err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		if out := fieldsText(tokenFields(sa, now)); !strings.Contains(out, "expired 60m ago") || !strings.Contains(out, "Audience: api") {
+			t.Errorf("Expected an expired token for audience api, but got:\n%s", out)
+		}
+	})
+
+	t.Run("should reject other tokens", func(t *testing.T) {
+		for _, token := range []string{"not-a-jwt", newTestJWT(`{"sub":"alice"}`), "a.!!!.c"} {
+			if _, err := kube.ParseServiceAccountToken(token); err == nil {
+				t.Errorf("Expected an error for %q, but got none", token)
+			}
+		}
+	})
+}
+
+// TestFormatServiceAccountToken verifies that token secrets show their claims
+// instead of the token.
+func TestFormatServiceAccountToken(t *testing.T) {
+	token := newTestJWT(`{"iss":"kubernetes/serviceaccount","sub":"system:serviceaccount:default:deployer"}`)
+	m := NewModel(fake.NewSimpleClientset(), "default", Options{})
+	m.viewport.Width = 200
+	m.highlightedItem = kube.Item{Name: "deployer-token", Namespace: "default"}
+	m.secretObjects["default/deployer-token"] = &corev1.Secret{
+		Type: corev1.SecretTypeServiceAccountToken,
+		Data: map[string][]byte{"token": []byte(token), "namespace": []byte("default")},
+	}
+	out := m.formatSecretData(map[string]string{"token": token, "namespace": "default"})
+	for _, expected := range []string{"Token: ********", "Issuer: kubernetes/serviceaccount", "namespace: default"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected '%s', but got:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out, token) {
+		t.Errorf("Expected the token to be masked, but got:\n%s", out)
+	}
+}
+
+// fieldsText joins typed fields the way the detail pane shows them.
+func fieldsText(fields []typedField) string {
+	var b strings.Builder
+	for _, field := range fields {
+		b.WriteString(field.label + ": " + field.value + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// searchesLoadedMsg carries the searches remembered for a namespace.
+type searchesLoadedMsg struct {
+	namespace string
+	searches  []string
+}
+
+// loadSearchesCmd reads the searches remembered for the namespace shown, kept
+// under the same namespace as its cached list.
+func (m Model) loadSearchesCmd() tea.Cmd {
+	if m.searchHistory == nil {
+		return nil
+	}
+	history, namespace := m.searchHistory, m.cacheNamespace()
+	return func() tea.Msg {
+		searches, err := history.Load(namespace)
+		if err != nil {
+			slog.Debug("failed to load the search history", "namespace", namespace, "error", err)
+		}
+		return searchesLoadedMsg{namespace: namespace, searches: searches}
+	}
+}
+
+// handleSearchesLoaded keeps the searches of the namespace shown for recall.
+func (m Model) handleSearchesLoaded(msg searchesLoadedMsg) Model {
+	if msg.namespace == m.cacheNamespace() {
+		m.searches, m.recall = msg.searches, -1
+	}
+	return m
+}
+
+// rememberSearch remembers the search typed in the list pane, as the list is left
+// for the data pane or enter is pressed, and saves it for the next runs.
+func (m Model) rememberSearch() (Model, tea.Cmd) {
+	search := m.textinput.Value()
+	m.recall = -1
+	if search == "" {
+		return m, nil
+	}
+	m.searches = kube.AddSearch(m.searches, search, kube.DefaultSearchLimit)
+	if m.searchHistory == nil {
+		return m, nil
+	}
+	history, namespace := m.searchHistory, m.cacheNamespace()
+	return m, func() tea.Msg {
+		if _, err := history.Add(namespace, search); err != nil {
+			slog.Debug("failed to save the search history", "namespace", namespace, "error", err)
+		}
+		return nil
+	}
+}
+
+// handleSearchRecallKey recalls the remembered searches into the search input: ↑
+// at the top of the list with an empty search shows the newest one, then ↑ and ↓
+// move through them, ↓ past the newest clearing the search again. Enter, or any
+// other key, keeps the search shown, so that the arrows move through the list
+// again. It reports whether the key was consumed.
+func (m Model) handleSearchRecallKey(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	switch {
+	case msg.String() == "up" && m.recall > 0:
+		m.recall--
+	case msg.String() == "up" && m.recall == 0:
+		return m, nil, true
+	case msg.String() == "up" && m.textinput.Value() == "" && m.list.Index() == 0 && len(m.searches) > 0:
+		m.recall = len(m.searches) - 1
+	case msg.String() == "down" && m.recall >= 0:
+		m.recall++
+	default:
+		m.recall = -1
+		return m, nil, false
+	}
+	search := ""
+	if m.recall < len(m.searches) {
+		search = m.searches[m.recall]
+	} else {
+		m.recall = -1
+	}
+	m.textinput.SetValue(search)
+	m.textinput.CursorEnd()
+	return m, m.list.SetItems(m.filteredItems()), true
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestSearchRecall verifies that searches are remembered per namespace, and
+// recalled with the arrow keys while the search is empty.
+func TestSearchRecall(t *testing.T) {
+	dir := t.TempDir()
+	history, err := kube.OpenSearchHistory(filepath.Join(dir, "searches"), filepath.Join(dir, "cache.key"), "prod", kube.DefaultSearchLimit)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	items := kube.ItemSource{{Name: "api-tls", Namespace: "default"}, {Name: "db", Namespace: "default"}, {Name: "redis", Namespace: "default"}}
+	m := NewModel(fake.NewSimpleClientset(), "default", Options{SearchHistory: history})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = m.handleSecretsLoaded(items)
+	press := func(m Model, keys ...tea.KeyMsg) Model {
+		for _, key := range keys {
+			next, cmd := m.Update(key)
+			m = next.(Model)
+			if cmd != nil {
+				cmd()
+			}
+		}
+		return m
+	}
+	search := func(m Model, text string) Model {
+		m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+		return press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	}
+	up, down := tea.KeyMsg{Type: tea.KeyUp}, tea.KeyMsg{Type: tea.KeyDown}
+	clearSearch := func(m Model) Model {
+		m.textinput.SetValue("")
+		m.list.SetItems(m.filteredItems())
+		return m
+	}
+	m = clearSearch(search(clearSearch(search(m, "tls")), "db"))
+
+	t.Run("should save the searches for the next runs", func(t *testing.T) {
+		next := NewModel(fake.NewSimpleClientset(), "default", Options{SearchHistory: history})
+		next = next.handleSearchesLoaded(next.loadSearchesCmd()().(searchesLoadedMsg))
+		if got := strings.Join(next.searches, ","); got != "tls,db" {
+			t.Errorf("Expected tls,db, but got %s", got)
+		}
+	})
+	t.Run("should recall the searches, newest first", func(t *testing.T) {
+		m := press(m, up)
+		if m.textinput.Value() != "db" || len(m.list.Items()) != 1 {
+			t.Fatalf("Expected db to be recalled and filter the list, but got %q", m.textinput.Value())
+		}
+		if m = press(m, up); m.textinput.Value() != "tls" {
+			t.Errorf("Expected tls to be recalled, but got %q", m.textinput.Value())
+		}
+		if m = press(m, down, down); m.textinput.Value() != "" || len(m.list.Items()) != 3 {
+			t.Errorf("Expected the search to be cleared, but got %q", m.textinput.Value())
+		}
+	})
+	t.Run("should move through the list again once a recalled search is kept", func(t *testing.T) {
+		m := press(m, up, up, tea.KeyMsg{Type: tea.KeyEnter}, down)
+		if m.textinput.Value() != "tls" {
+			t.Errorf("Expected the search to be kept, but got %q", m.textinput.Value())
+		}
+		if got := strings.Join(m.searches, ","); got != "db,tls" {
+			t.Errorf("Expected tls to be the newest search, but got %s", got)
+		}
+	})
+	t.Run("should move through the list when not at its top", func(t *testing.T) {
+		m := press(m, down, up)
+		if m.textinput.Value() != "" || m.list.Index() != 0 {
+			t.Errorf("Expected to move through the list, but got search %q at %d", m.textinput.Value(), m.list.Index())
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Actions on a secret store, which prefix its name in the command palette.
+const (
+	storePushPrefix    = "push to "
+	storePullPrefix    = "pull from "
+	storeComparePrefix = "compare with "
+)
+
+// paletteStoreMsg pushes the highlighted secret to a secret store, pulls it from
+// the store, or compares it with an item of the store, as chosen in the command
+// palette.
+type paletteStoreMsg struct {
+	store  kube.SecretStore
+	action string // One of the store action prefixes.
+}
+
+// storeActions returns the actions a store supports.
+func storeActions(s kube.SecretStore) []string {
+	var actions []string
+	if s.CanPush() {
+		actions = append(actions, storePushPrefix)
+	}
+	if s.CanPull() {
+		actions = append(actions, storePullPrefix, storeComparePrefix)
+	}
+	return actions
+}
+
+// startStoreAction asks for the item of a store to push the highlighted secret to,
+// pull it from, or compare it with, named after the secret by default. Pushes are
+// confirmed, since the values leave the cluster; pulls are previewed like edits.
+func (m Model) startStoreAction(msg paletteStoreMsg) (Model, tea.Cmd) {
+	secret := m.secretObjects[m.highlightedKey()]
+	if secret == nil {
+		m.status, m.statusErr = m.highlightedItem.Name+" is not loaded yet", true
+		return m, nil
+	}
+	clientset, s := m.clientset, msg.store
+	ref := kube.SecretRef{Namespace: secret.Namespace, Name: secret.Name}
+	switch msg.action {
+	case storeComparePrefix:
+		m.prompt = newInputPrompt(fmt.Sprintf("Compare %s with item of %s:", secret.Name, s.Name), ref.String(), func(item string) tea.Cmd {
+			return compareStoreCmd(s, secret, item)
+		})
+		return m, nil
+	case storePullPrefix:
+		m.prompt = newInputPrompt(fmt.Sprintf("Pull %s from item of %s:", secret.Name, s.Name), ref.String(), func(item string) tea.Cmd {
+			return pullStoreCmd(clientset, s, ref, item)
+		})
+		return m, nil
+	}
+	m.prompt = newInputPrompt(fmt.Sprintf("Push %s to item of %s:", secret.Name, s.Name), ref.String(), func(item string) tea.Cmd {
+		next := newConfirmPrompt(fmt.Sprintf("Push the values of %s to item '%s' of %s?", secret.Name, item, s.Name), func() tea.Cmd {
+			return pushStoreCmd(s, secret, item)
+		})
+		return func() tea.Msg { return showPromptMsg{prompt: next} }
+	})
+	return m, nil
+}
+
+// pushStoreCmd pushes the data of a secret to an item of a store.
+func pushStoreCmd(s kube.SecretStore, secret *corev1.Secret, item string) tea.Cmd {
+	return func() tea.Msg {
+		if err := s.Push(kube.StoreItem{Item: item, Namespace: secret.Namespace, Secret: secret.Name}, secret.Data); err != nil {
+			return actionDoneMsg{status: "Push failed", err: err}
+		}
+		return actionDoneMsg{status: fmt.Sprintf("Pushed %s to item '%s' of %s", secret.Name, item, s.Name)}
+	}
+}
+
+// pullStoreCmd pulls an item of a store and previews the update of a secret with
+// its keys.
+func pullStoreCmd(clientset kube.Client, s kube.SecretStore, ref kube.SecretRef, item string) tea.Cmd {
+	return func() tea.Msg {
+		data, err := s.Pull(kube.StoreItem{Item: item, Namespace: ref.Namespace, Secret: ref.Name})
+		if err != nil {
+			return actionDoneMsg{status: "Pull failed", err: err}
+		}
+		mu, err := kube.PreparePull(clientset, ref, data, false)
+		if err != nil {
+			return actionDoneMsg{status: "Pull failed", err: err}
+		}
+		title := kube.UpdateTitle(clientset, fmt.Sprintf("Pull item '%s' of %s into", item, s.Name), ref)
+		return previewUpdate(clientset, mu, title, "Pulled "+item+" into "+ref.Name)
+	}
+}
+
+// compareStoreCmd compares the data of a secret with an item of a store, and tells
+// how they differ in the status bar.
+func compareStoreCmd(s kube.SecretStore, secret *corev1.Secret, item string) tea.Cmd {
+	return func() tea.Msg {
+		data, err := s.Pull(kube.StoreItem{Item: item, Namespace: secret.Namespace, Secret: secret.Name})
+		if err != nil {
+			return actionDoneMsg{status: "Compare failed", err: err}
+		}
+		changes := kube.CompareData(secret.Data, data)
+		if len(changes) == 0 {
+			return actionDoneMsg{status: fmt.Sprintf("%s matches item '%s' of %s", secret.Name, item, s.Name)}
+		}
+		keys := make([]string, 0, len(changes))
+		for _, change := range changes {
+			keys = append(keys, string(change.Kind)+change.Key)
+		}
+		return actionDoneMsg{status: fmt.Sprintf("%s and item '%s' of %s differ: %s", secret.Name, item, s.Name, strings.Join(keys, " "))}
+	}
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestStoreActions verifies that the highlighted secret can be pushed to and
+// pulled from the secret stores of the configuration from the command palette.
+func TestStoreActions(t *testing.T) {
+	stores, err := kube.CompileStores([]kube.StoreConfig{{
+		Name: "team",
+		Push: []string{"true"},
+		Pull: []string{"echo", `{"password":"new"}`},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("old")},
+	})
+	m := NewModel(clientset, "default", Options{Stores: stores})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = m.handleSecretsLoaded(kube.ItemSource{{Name: "db", Namespace: "default"}})
+	m, _ = m.handleSecretDataLoaded(fetchSecretData(clientset, "db", "default", nil)().(secretDataLoadedMsg))
+
+	t.Run("should list the actions of the stores in the palette", func(t *testing.T) {
+		msg, ok := m.newPalette().onSubmit("pull from team")().(paletteStoreMsg)
+		if !ok || msg.action != storePullPrefix || msg.store.Name != "team" {
+			t.Errorf("Expected the pull from team, but got %+v", msg)
+		}
+	})
+	t.Run("should confirm a push", func(t *testing.T) {
+		m, _ := m.startStoreAction(paletteStoreMsg{store: stores[0], action: storePushPrefix})
+		next := m.prompt.onSubmit("default/db")().(showPromptMsg).prompt
+		if !next.confirm || !strings.Contains(next.title, "Push the values of db to item 'default/db' of team?") {
+			t.Fatalf("Expected a confirmation, but got %+v", next)
+		}
+		if done := next.onSubmit("y")().(actionDoneMsg); done.err != nil {
+			t.Errorf("Expected the push to succeed, but got: %v", done.err)
+		}
+	})
+	t.Run("should preview a pull", func(t *testing.T) {
+		m, _ := m.startStoreAction(paletteStoreMsg{store: stores[0], action: storePullPrefix})
+		preview, ok := m.prompt.onSubmit("default/db")().(mutationPreviewMsg)
+		if !ok || preview.err != nil || len(preview.changes) != 1 || preview.changes[0].Key != "data.password" {
+			t.Errorf("Expected password to be changed, but got %+v", preview)
+		}
+	})
+	t.Run("should tell how a secret differs from an item", func(t *testing.T) {
+		m, _ := m.startStoreAction(paletteStoreMsg{store: stores[0], action: storeComparePrefix})
+		done := m.prompt.onSubmit("default/db")().(actionDoneMsg)
+		if done.err != nil || done.status != "db and item 'default/db' of team differ: ~password" {
+			t.Errorf("Expected password to differ, but got %q (error: %v)", done.status, done.err)
+		}
+	})
+	t.Run("should only list the actions a store supports", func(t *testing.T) {
+		readOnly, err := kube.CompileStores([]kube.StoreConfig{{Name: "prod", Kind: kube.StoreDoppler}})
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if got := strings.Join(storeActions(readOnly[0]), "|"); got != storePullPrefix+"|"+storeComparePrefix {
+			t.Errorf("Expected pull and compare only, but got %s", got)
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// formatStrength renders the rating as a colored indicator, e.g. "▰▰▱ fair (52 bits)".
+func formatStrength(s kube.ValueStrength) string {
+	style := removedStyle
+	switch s.Bars {
+	case 2:
+		style = changedStyle
+	case 3:
+		style = addedStyle
+	}
+	bars := strings.Repeat("▰", s.Bars) + strings.Repeat("▱", 3-s.Bars)
+	return style.Render(fmt.Sprintf("%s %s (%.0f bits)", bars, s.Label, s.Bits))
+}
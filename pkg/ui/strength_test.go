@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestFormatSecretDataStrength verifies that only password-like keys get an indicator.
+func TestFormatSecretDataStrength(t *testing.T) {
+	m := NewModel(fake.NewSimpleClientset(), "default", Options{})
+	m.highlightedItem = kube.Item{Name: "db", Namespace: "default"}
+	m.viewport.Width = 200
+	m.secretObjects["default/db"] = &corev1.Secret{Data: map[string][]byte{"password": []byte("changeme"), "host": []byte("db")}}
+	data := map[string]string{"password": "changeme", "host": "db"}
+
+	t.Run("should rate password-like keys", func(t *testing.T) {
+		out := m.formatSecretData(data)
+		if !strings.Contains(out, "password: changeme  ▱▱▱ placeholder") {
+			t.Errorf("Expected a placeholder indicator, but got:\n%s", out)
+		}
+		if strings.Contains(out, "host: db  ") {
+			t.Errorf("Expected no indicator for 'host', but got:\n%s", out)
+		}
+	})
+
+	t.Run("should use the configured key heuristics", func(t *testing.T) {
+		var err error
+		if m.secretKeys, err = kube.CompileSecretKeys("^host$"); err != nil {
+			t.Fatalf("Expected no error, but got %v", err)
+		}
+		out := m.formatSecretData(data)
+		if !strings.Contains(out, "host: db  ▰▱▱ weak") || strings.Contains(out, "placeholder") {
+			t.Errorf("Expected an indicator for 'host' only, but got:\n%s", out)
+		}
+	})
+
+	t.Run("should reject invalid patterns", func(t *testing.T) {
+		if _, err := kube.CompileSecretKeys("("); err == nil {
+			t.Error("Expected an error, but got nil")
+		}
+	})
+}
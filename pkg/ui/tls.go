@@ -0,0 +1,26 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// createTLSSecretCmd previews the TLS secret described in the TUI wizard with a
+// server-side dry run.
+func createTLSSecretCmd(clientset kube.Client, namespace, name, certPath, keyPath string) tea.Cmd {
+	return func() tea.Msg {
+		secret, warnings, err := kube.BuildTLSSecret(namespace, name, certPath, keyPath, time.Now())
+		if err != nil {
+			return actionDoneMsg{status: "Create failed", err: err}
+		}
+		title := "Create " + name
+		if len(warnings) > 0 {
+			title += " (warning: " + strings.Join(warnings, "; ") + ")"
+		}
+		mu := kube.Mutation{After: secret}
+		return previewMutation(clientset, mu, title, applyMutationCmd(clientset, mu, "Created "+name))
+	}
+}
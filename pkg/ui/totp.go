@@ -0,0 +1,54 @@
+package ui
+
+import (
+
+	//nolint:gosec // SHA-1 is the default and most common TOTP algorithm (RFC 6238).
+
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// totpTickMsg refreshes the TOTP codes shown in the detail pane every second.
+type totpTickMsg struct{ generation int }
+
+// formatTOTP renders the current code of a TOTP value, or a hint to show it.
+func (m *Model) formatTOTP(key string, value []byte) string {
+	cfg, ok := kube.ParseTOTP(key, string(value))
+	if !ok {
+		return ""
+	}
+	if !m.showTOTP {
+		return NoteStyle.Render("(TOTP seed, press o for the code)")
+	}
+	code, remaining := cfg.Code(time.Now())
+	return addedStyle.Render(fmt.Sprintf("🔑 %s (%ds)", code, int(remaining.Seconds())))
+}
+
+// tickTOTP schedules the next refresh of the TOTP codes.
+func tickTOTP(generation int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return totpTickMsg{generation: generation} })
+}
+
+// toggleTOTP shows or hides the TOTP codes. Each time the codes are shown, a new
+// generation of ticks starts, so that earlier ticks stop.
+func (m Model) toggleTOTP() (Model, tea.Cmd) {
+	m.showTOTP = !m.showTOTP
+	m.refreshSecretData()
+	if !m.showTOTP {
+		return m, nil
+	}
+	m.totpGeneration++
+	return m, tickTOTP(m.totpGeneration)
+}
+
+// handleTOTPTick refreshes the countdown of the TOTP codes while they are shown.
+func (m Model) handleTOTPTick(msg totpTickMsg) (Model, tea.Cmd) {
+	if !m.showTOTP || msg.generation != m.totpGeneration {
+		return m, nil
+	}
+	m.refreshSecretData()
+	return m, tickTOTP(msg.generation)
+}
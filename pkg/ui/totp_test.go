@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestToggleTOTP verifies that codes are shown on demand and refreshed by ticks.
+func TestToggleTOTP(t *testing.T) {
+	m := NewModel(fake.NewSimpleClientset(), "default", Options{})
+	m.highlightedItem = kube.Item{Name: "github", Namespace: "default"}
+	m.viewport.Width = 200
+	m.secretObjects["default/github"] = &corev1.Secret{Data: map[string][]byte{"otp": []byte("JBSWY3DPEHPK3PXP")}}
+	m.secretCache["default/github"] = map[string]string{"otp": "JBSWY3DPEHPK3PXP"}
+
+	if out := m.formatSecretData(m.secretCache["default/github"]); !strings.Contains(out, "press o for the code") {
+		t.Errorf("Expected a hint, but got:\n%s", out)
+	}
+
+	m, cmd := m.toggleTOTP()
+	if cmd == nil || !m.showTOTP {
+		t.Fatal("Expected the codes to be shown with a refresh tick")
+	}
+	if out := m.formatSecretData(m.secretCache["default/github"]); !strings.Contains(out, "🔑 ") {
+		t.Errorf("Expected a code, but got:\n%s", out)
+	}
+
+	t.Run("should stop ticks of earlier generations", func(t *testing.T) {
+		stale := totpTickMsg{generation: m.totpGeneration - 1}
+		if _, cmd := m.handleTOTPTick(stale); cmd != nil {
+			t.Error("Expected a stale tick to stop")
+		}
+		var next tea.Cmd
+		if _, next = m.handleTOTPTick(totpTickMsg{generation: m.totpGeneration}); next == nil {
+			t.Error("Expected the current tick to schedule the next one")
+		}
+	})
+
+	m, _ = m.toggleTOTP()
+	if _, cmd := m.handleTOTPTick(totpTickMsg{generation: m.totpGeneration}); cmd != nil || m.showTOTP {
+		t.Error("Expected ticks to stop once the codes are hidden")
+	}
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// typedField is one line of the type-specific rendering of a secret.
+type typedField struct {
+	label string
+	key   string // The key behind the field, for value indicators, or empty.
+	value string
+}
+
+// typedRenderers render secrets of well-known types as fields instead of the
+// generic key/value dump. The keys they consume are listed so the remaining keys
+// can still be shown.
+var typedRenderers = map[corev1.SecretType]struct {
+	keys   []string
+	render func(secret *corev1.Secret, reveal bool) []typedField
+}{
+	corev1.SecretTypeBasicAuth: {
+		keys:   []string{corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey},
+		render: renderBasicAuth,
+	},
+	corev1.SecretTypeSSHAuth: {
+		keys:   []string{corev1.SSHAuthPrivateKey},
+		render: renderSSHAuth,
+	},
+	corev1.SecretTypeServiceAccountToken: {
+		keys:   []string{corev1.ServiceAccountTokenKey},
+		render: renderServiceAccountToken,
+	},
+}
+
+// typedFields renders a secret with the renderer for its type, followed by any
+// other keys as generic fields. It returns false for types without a renderer.
+func typedFields(secret *corev1.Secret, data map[string]string, reveal bool) ([]typedField, bool) {
+	renderer, ok := typedRenderers[secret.Type]
+	if !ok {
+		return nil, false
+	}
+	fields := renderer.render(secret, reveal)
+	consumed := make(map[string]bool, len(renderer.keys))
+	for _, key := range renderer.keys {
+		consumed[key] = true
+	}
+	var others []string
+	for key := range data {
+		if !consumed[key] {
+			others = append(others, key)
+		}
+	}
+	sort.Strings(others)
+	for _, key := range others {
+		fields = append(fields, typedField{label: key, key: key, value: data[key]})
+	}
+	return fields, true
+}
+
+// renderBasicAuth shows the username and the password, masked unless revealed.
+func renderBasicAuth(secret *corev1.Secret, reveal bool) []typedField {
+	username := string(secret.Data[corev1.BasicAuthUsernameKey])
+	if username == "" {
+		username = NoteStyle.Render("(not set)")
+	}
+	password := secret.Data[corev1.BasicAuthPasswordKey]
+	shown := kube.MaskValue(password) + " " + NoteStyle.Render("(v to reveal)")
+	if reveal {
+		shown = string(password)
+	}
+	return []typedField{
+		{label: "Username", value: username},
+		{label: "Password", key: corev1.BasicAuthPasswordKey, value: shown},
+	}
+}
+
+// renderSSHAuth shows the metadata of the private key instead of the key itself.
+func renderSSHAuth(secret *corev1.Secret, reveal bool) []typedField {
+	data := secret.Data[corev1.SSHAuthPrivateKey]
+	if reveal {
+		return []typedField{{label: "Private key", value: "\n" + strings.TrimRight(string(data), "\n")}}
+	}
+	info, err := kube.ParseSSHPrivateKey(data)
+	if err != nil {
+		return []typedField{{label: "Private key", value: errorStyle.Render(fmt.Sprintf("unreadable: %v", err))}}
+	}
+	fields := []typedField{{label: "Private key", value: info.String() + " " + NoteStyle.Render("(v to reveal)")}}
+	if info.Fingerprint != "" {
+		fields = append(fields, typedField{label: "Fingerprint", value: info.Fingerprint})
+	}
+	if info.Comment != "" {
+		fields = append(fields, typedField{label: "Comment", value: info.Comment})
+	}
+	return fields
+}
+
+// formatTypedFields renders the fields of a typed secret for the detail pane.
+func (m *Model) formatTypedFields(secret *corev1.Secret, fields []typedField) string {
+	var b strings.Builder
+	for _, field := range fields {
+		value := field.value
+		if field.key != "" {
+			value += m.formatIndicators(field.key, secret.Data[field.key])
+		}
+		fmt.Fprintf(&b, "%s: %s\n", field.label, value)
+	}
+	return b.String()
+}
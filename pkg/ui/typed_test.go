@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diskmanti/kds/internal/testfixtures"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestFormatTypedSecrets verifies that basic-auth and ssh-auth secrets are rendered
+// by type, with their sensitive values hidden until revealed.
+func TestFormatTypedSecrets(t *testing.T) {
+	m := NewModel(fake.NewSimpleClientset(), "default", Options{})
+	m.viewport.Width = 200
+	show := func(secret *corev1.Secret) string {
+		data := make(map[string]string, len(secret.Data))
+		for key, value := range secret.Data {
+			data[key] = string(value)
+		}
+		m.highlightedItem = kube.Item{Name: secret.Name, Namespace: "default"}
+		m.secretObjects["default/"+secret.Name] = secret
+		return m.formatSecretData(data)
+	}
+
+	basicAuth := &corev1.Secret{
+		Type: corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{"username": []byte("admin"), "password": []byte("s3cr3t-Passw0rd"), "url": []byte("https://example.com")},
+	}
+	basicAuth.Name = "registry"
+
+	t.Run("should mask the password of basic-auth secrets", func(t *testing.T) {
+		out := show(basicAuth)
+		for _, expected := range []string{"Username: admin", "Password: ********", "url: https://example.com"} {
+			if !strings.Contains(out, expected) {
+				t.Errorf("Expected '%s', but got:\n%s", expected, out)
+			}
+		}
+		if strings.Contains(out, "s3cr3t-Passw0rd") {
+			t.Errorf("Expected the password to be masked, but got:\n%s", out)
+		}
+	})
+
+	t.Run("should reveal the password on demand", func(t *testing.T) {
+		m.reveal = true
+		defer func() { m.reveal = false }()
+		if out := show(basicAuth); !strings.Contains(out, "Password: s3cr3t-Passw0rd") {
+			t.Errorf("Expected the password, but got:\n%s", out)
+		}
+	})
+
+	t.Run("should show the metadata of ssh-auth keys", func(t *testing.T) {
+		secret := &corev1.Secret{Type: corev1.SecretTypeSSHAuth, Data: map[string][]byte{"ssh-privatekey": testfixtures.NewOpenSSHKey(t, "deploy@ci")}}
+		secret.Name = "git"
+		out := show(secret)
+		for _, expected := range []string{"Private key: ssh-ed25519 (256 bits, OpenSSH, unencrypted)", "Fingerprint: SHA256:", "Comment: deploy@ci"} {
+			if !strings.Contains(out, expected) {
+				t.Errorf("Expected '%s', but got:\n%s", expected, out)
+			}
+		}
+		if strings.Contains(out, "BEGIN OPENSSH") {
+			t.Errorf("Expected the key to be hidden, but got:\n%s", out)
+		}
+	})
+
+	t.Run("should report unreadable ssh-auth keys", func(t *testing.T) {
+		secret := &corev1.Secret{Type: corev1.SecretTypeSSHAuth, Data: map[string][]byte{"ssh-privatekey": []byte("garbage")}}
+		secret.Name = "broken"
+		if out := show(secret); !strings.Contains(out, "unreadable") {
+			t.Errorf("Expected an unreadable key, but got:\n%s", out)
+		}
+	})
+
+	t.Run("should keep the generic view for other types", func(t *testing.T) {
+		secret := &corev1.Secret{Type: corev1.SecretTypeOpaque, Data: map[string][]byte{"password": []byte("s3cr3t-Passw0rd")}}
+		secret.Name = "opaque"
+		if out := show(secret); !strings.Contains(out, "password: s3cr3t-Passw0rd") {
+			t.Errorf("Expected the plain value, but got:\n%s", out)
+		}
+	})
+}
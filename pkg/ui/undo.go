@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// maxUndo is how many changes the undo stack remembers.
+const maxUndo = 20
+
+// undoEntry is a change made from the TUI that can be undone with u.
+type undoEntry struct {
+	status   string         // The status reported by the change, e.g. "Deleted 2 secret(s)".
+	snapshot *kube.Snapshot // The secrets as they were before the change, kept in memory only.
+}
+
+// undoneMsg is sent once the last change has been undone.
+type undoneMsg struct {
+	entry undoEntry
+	err   error
+}
+
+// undoable saves the referenced secrets before running a change, so that the
+// actionDoneMsg of the change can be pushed on the undo stack, and records their
+// versions after it, so that undoing it does not overwrite later changes. The
+// change is not run if the secrets cannot be saved.
+func undoable(clientset kube.Client, refs []kube.SecretRef, cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		snapshot, err := kube.TakeSnapshot(clientset, refs)
+		if err != nil {
+			return actionDoneMsg{status: "Could not save the secrets to undo the change, so nothing was changed", err: err}
+		}
+		msg := cmd()
+		done, ok := msg.(actionDoneMsg)
+		if !ok {
+			return msg
+		}
+		if err := snapshot.RecordVersions(clientset); err != nil {
+			slog.Debug("failed to record the secrets after the change, which cannot be undone", "change", done.status, "error", err)
+			return done
+		}
+		done.undo = snapshot
+		return done
+	}
+}
+
+// pushUndo remembers a change, forgetting the oldest one beyond maxUndo.
+func (m Model) pushUndo(status string, snapshot *kube.Snapshot) Model {
+	m.undoStack = append(m.undoStack, undoEntry{status: status, snapshot: snapshot})
+	if len(m.undoStack) > maxUndo {
+		m.undoStack = m.undoStack[len(m.undoStack)-maxUndo:]
+	}
+	return m
+}
+
+// confirmUndo asks whether the last change should be undone.
+func (m Model) confirmUndo() Model {
+	if len(m.undoStack) == 0 {
+		m.status, m.statusErr = "Nothing to undo", false
+		return m
+	}
+	entry := m.undoStack[len(m.undoStack)-1]
+	m.prompt = newConfirmPrompt(fmt.Sprintf("Undo %q?", entry.status), func() tea.Cmd {
+		return undoCmd(m.clientset, entry)
+	})
+	return m
+}
+
+// undoCmd restores the secrets saved before a change.
+func undoCmd(clientset kube.Client, entry undoEntry) tea.Cmd {
+	return func() tea.Msg {
+		return undoneMsg{entry: entry, err: entry.snapshot.Restore(clientset)}
+	}
+}
+
+// handleUndone pops the change that was undone, and reloads the secrets. A change
+// that could not be fully undone stays on the stack, so it can be tried again.
+func (m Model) handleUndone(msg undoneMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		slog.Debug("failed to undo", "change", msg.entry.status, "error", msg.err)
+		return m.handleActionDone(actionDoneMsg{status: fmt.Sprintf("Undo of %q failed", msg.entry.status), err: msg.err, refresh: true})
+	}
+	if n := len(m.undoStack); n > 0 && m.undoStack[n-1].snapshot == msg.entry.snapshot {
+		m.undoStack = m.undoStack[:n-1]
+	}
+	return m.handleActionDone(actionDoneMsg{status: fmt.Sprintf("Undid %q", msg.entry.status), refresh: true})
+}
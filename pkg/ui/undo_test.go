@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestUndo verifies that a deletion made from the TUI can be undone with u, after
+// confirming it in a modal.
+func TestUndo(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("s3cr3t")},
+	})
+	m := NewModel(clientset, "default", Options{})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m, _ = m.handleSecretsLoaded(kube.ItemSource{{Name: "db", Namespace: "default"}})
+	m.focus = rightPane
+
+	press := func(m Model, key string) (Model, tea.Cmd) {
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		return next.(Model), cmd
+	}
+
+	t.Run("should report that there is nothing to undo", func(t *testing.T) {
+		if m, _ := press(m, "u"); m.prompt != nil || m.status != "Nothing to undo" {
+			t.Errorf("Expected nothing to undo, but got status %q", m.status)
+		}
+	})
+
+	m, _ = press(m, "d")
+	if m.prompt == nil || !m.prompt.confirm {
+		t.Fatal("Expected d to ask for confirmation")
+	}
+	if view := m.View(); !strings.Contains(view, "Delete 1 secret(s)?") || !strings.Contains(view, "y: confirm") {
+		t.Errorf("Expected the confirmation in a modal, but got:\n%s", view)
+	}
+	m, cmd := press(m, "y")
+	m, _ = m.handleActionDone(cmd().(actionDoneMsg))
+	if len(m.undoStack) != 1 {
+		t.Fatalf("Expected the deletion on the undo stack, but got %d change(s)", len(m.undoStack))
+	}
+
+	m, _ = press(m, "u")
+	if m.prompt == nil || !strings.Contains(m.prompt.title, `Undo "Deleted 1 secret(s)"?`) {
+		t.Fatalf("Expected a confirmation, but got %+v", m.prompt)
+	}
+	m, cmd = press(m, "y")
+	next, _ := m.Update(cmd())
+	m = next.(Model)
+	if len(m.undoStack) != 0 || m.statusErr {
+		t.Errorf("Expected the change to be undone, but got status %q", m.status)
+	}
+	secret, err := clientset.CoreV1().Secrets("default").Get(context.TODO(), "db", metav1.GetOptions{})
+	if err != nil || string(secret.Data["password"]) != "s3cr3t" {
+		t.Errorf("Expected the secret to be back, but got %v (%v)", secret, err)
+	}
+}
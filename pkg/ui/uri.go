@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/diskmanti/kds/pkg/kube"
+)
+
+// focusSecret selects the secret to focus in the list, once, with the data pane
+// focused, or reports that it is not listed.
+func (m Model) focusSecret() Model {
+	name := m.focusName
+	m.focusName = ""
+	for i, item := range m.list.Items() {
+		if it, ok := item.(kube.Item); ok && it.Name == name {
+			m.list.Select(i)
+			m.focus = rightPane
+			m.textinput.Blur()
+			return m
+		}
+	}
+	m.status, m.statusErr = fmt.Sprintf("Secret '%s' not found in namespace '%s'", name, m.namespace), true
+	return m
+}
+
+// secretURI returns the secret:// URI of the highlighted secret.
+func (m Model) secretURI() string {
+	return kube.SecretURI{Context: m.context, Ref: m.highlightedItem.Ref()}.String()
+}
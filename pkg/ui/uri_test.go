@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestFocusSecret verifies that the secret of a secret:// URI is highlighted once
+// listed, with the data pane focused.
+func TestFocusSecret(t *testing.T) {
+	items := kube.ItemSource{{Name: "api", Namespace: "default"}, {Name: "db", Namespace: "default"}}
+	load := func(focus string) Model {
+		m := NewModel(fake.NewSimpleClientset(), "default", Options{Focus: focus, Context: "prod"})
+		m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 120, Height: 40})
+		m, _ = m.handleSecretsLoaded(items)
+		return m
+	}
+
+	t.Run("should highlight the secret and focus the data pane", func(t *testing.T) {
+		m := load("db")
+		if m.highlightedItem.Name != "db" || m.focus != rightPane {
+			t.Errorf("Expected db to be focused, but got %q", m.highlightedItem.Name)
+		}
+		if got := m.secretURI(); got != "secret://prod/default/db" {
+			t.Errorf("Expected the URI of db, but got %s", got)
+		}
+	})
+	t.Run("should report a secret that is not listed", func(t *testing.T) {
+		m := load("cache")
+		if m.highlightedItem.Name != "api" || m.focus != leftPane || !m.statusErr {
+			t.Errorf("Expected an error and api highlighted, but got %q and status %q", m.highlightedItem.Name, m.status)
+		}
+	})
+}
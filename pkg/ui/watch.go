@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// notificationDuration is how long a change notification stays in the help bar.
+const notificationDuration = 5 * time.Second
+
+// changedMarker is put in front of the secrets that changed since they were last
+// viewed.
+const changedMarker = "✱ "
+
+// secretEventMsg carries a change to a secret seen by the live watch.
+type secretEventMsg struct {
+	secret     *corev1.Secret
+	event      string  // One of the events of kube.WatchSecrets.
+	generation int     // The watch that saw it, as changes of a previous namespace are ignored.
+	next       tea.Cmd // Waits for the next change.
+}
+
+// watchStoppedMsg is sent when the live watch ends for good.
+type watchStoppedMsg struct {
+	generation int
+	err        error
+}
+
+// notificationExpiredMsg clears a change notification unless another status
+// replaced it.
+type notificationExpiredMsg struct{ status string }
+
+// watchSecretsCmd watches the secrets of a namespace until ctx is done, sending a
+// secretEventMsg for each of them and each of their changes.
+func watchSecretsCmd(ctx context.Context, clientset kube.Client, namespace string, generation int) tea.Cmd {
+	return func() tea.Msg {
+		events := make(chan secretEventMsg)
+		done := make(chan error, 1)
+		go func() {
+			done <- kube.WatchSecrets(ctx, clientset, namespace, func(secret *corev1.Secret, event string) error {
+				select {
+				case events <- secretEventMsg{secret: secret, event: event, generation: generation}:
+				case <-ctx.Done():
+				}
+				return nil
+			})
+		}()
+		return waitSecretEvent(events, done, generation)()
+	}
+}
+
+// waitSecretEvent waits for the next change seen by watchSecretsCmd.
+func waitSecretEvent(events <-chan secretEventMsg, done <-chan error, generation int) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case msg := <-events:
+			msg.next = waitSecretEvent(events, done, generation)
+			return msg
+		case err := <-done:
+			if err != nil {
+				return watchStoppedMsg{generation: generation, err: err}
+			}
+			return nil
+		}
+	}
+}
+
+// restartWatch stops watching the secrets of the previous namespace and starts
+// watching those of the current one.
+func (m *Model) restartWatch() tea.Cmd {
+	if !m.watch {
+		return nil
+	}
+	if m.watchStop != nil {
+		m.watchStop()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.watchStop = cancel
+	m.watchGeneration++
+	m.resourceVersions = make(map[string]string)
+	namespace := m.namespace
+	if m.allNamespaces {
+		namespace = ""
+	}
+	return watchSecretsCmd(ctx, m.clientset, namespace, m.watchGeneration)
+}
+
+// handleSecretEvent updates the list with a change seen by the live watch. Changes
+// to the secrets, other than those listed when the watch started, are notified in
+// the help bar, and the changed secrets are marked until they are viewed. The
+// highlighted secret is loaded again instead.
+func (m Model) handleSecretEvent(msg secretEventMsg) (Model, tea.Cmd) {
+	if msg.generation != m.watchGeneration {
+		return m, nil
+	}
+	key := cacheKey(msg.secret)
+	previous, known := m.resourceVersions[key]
+	m.resourceVersions[key] = msg.secret.ResourceVersion
+	if msg.event == kube.HistoryDeleted {
+		delete(m.resourceVersions, key)
+	}
+	if m.loading {
+		return m, msg.next
+	}
+	cmds := []tea.Cmd{msg.next}
+	// The listing that starts each watch is observed secret by secret, so those
+	// already listed are left alone rather than rebuilding the list every time.
+	if listed, ok := m.listedVersions[key]; !ok || listed != msg.secret.ResourceVersion || msg.event != kube.HistoryObserved {
+		var cmd tea.Cmd
+		m, cmd = m.updateListedSecret(msg.secret, msg.event == kube.HistoryDeleted)
+		cmds = append(cmds, cmd)
+	}
+	event := msg.event
+	if event == kube.HistoryObserved {
+		if !known || previous == msg.secret.ResourceVersion {
+			return m, tea.Batch(cmds...)
+		}
+		event = kube.HistoryChanged
+	}
+	delete(m.secretCache, key)
+	delete(m.secretObjects, key)
+	delete(m.secretErrCache, key)
+	delete(m.tlsChecks, key)
+	switch {
+	case event == kube.HistoryDeleted:
+		delete(m.changed, key)
+		delete(m.selected, key)
+	case key == m.highlightedKey():
+		m.loadingSecret = true
+		cmds = append(cmds, fetchSecretData(m.clientset, msg.secret.Name, msg.secret.Namespace, m.decoders))
+	default:
+		m.changed[key] = true
+	}
+	m.status, m.statusErr = fmt.Sprintf("Secret %s was %s", key, event), false
+	status := m.status
+	cmds = append(cmds, tea.Tick(notificationDuration, func(time.Time) tea.Msg { return notificationExpiredMsg{status: status} }))
+	if m.bell {
+		cmds = append(cmds, ringBell)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// updateListedSecret adds, replaces, or removes a secret in the list, keeping the
+// highlighted secret selected. If the highlighted secret is removed, the one
+// taking its place is loaded.
+func (m Model) updateListedSecret(secret *corev1.Secret, deleted bool) (Model, tea.Cmd) {
+	key := cacheKey(secret)
+	items := slices.Clone(m.allItems)
+	i := slices.IndexFunc(items, func(it kube.Item) bool { return it.Ref().String() == key })
+	item := kube.NewItem(secret)
+	item.CrossNamespace = m.allNamespaces
+	switch {
+	case deleted && i < 0:
+		return m, nil
+	case deleted:
+		items = slices.Delete(items, i, i+1)
+	case i >= 0:
+		items[i] = item
+	default:
+		at := slices.IndexFunc(items, func(it kube.Item) bool { return it.Ref().String() > key })
+		if at < 0 {
+			at = len(items)
+		}
+		items = slices.Insert(items, at, item)
+	}
+	m.setAllItems(items)
+	cmds := []tea.Cmd{m.list.SetItems(m.filteredItems())}
+	for i, listed := range m.list.Items() {
+		if it, ok := listed.(kube.Item); ok && it.Ref() == m.highlightedItem.Ref() {
+			m.list.Select(i)
+		}
+	}
+	if selected, ok := m.list.SelectedItem().(kube.Item); ok && selected.Ref() != m.highlightedItem.Ref() {
+		m.highlightedItem = selected
+		if _, found := m.secretCache[selected.Ref().String()]; !found {
+			m.loadingSecret = true
+			cmds = append(cmds, fetchSecretData(m.clientset, selected.Name, selected.Namespace, m.decoders))
+		}
+	} else if !ok {
+		m.highlightedItem = kube.Item{}
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// handleNotificationExpired clears a change notification that is still shown.
+func (m Model) handleNotificationExpired(msg notificationExpiredMsg) Model {
+	if m.status == msg.status {
+		m.status, m.statusErr = "", false
+	}
+	return m
+}
+
+// ringBell rings the terminal bell. It is written to stderr, which is the same
+// terminal, so that it does not interleave with the frames of the TUI.
+func ringBell() tea.Msg {
+	fmt.Fprint(os.Stderr, "\a")
+	return nil
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/diskmanti/kds/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestHandleSecretEvent verifies that changes seen by the live watch update the
+// list, are notified, and mark the changed secrets until they are viewed.
+func TestHandleSecretEvent(t *testing.T) {
+	secret := func(name, version string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", ResourceVersion: version}}
+	}
+	clientset := fake.NewSimpleClientset(secret("api", "1"), secret("db", "1"))
+	m := NewModel(clientset, "default", Options{Watch: true})
+	m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = m.handleSecretsLoaded(kube.ItemSource{{Name: "api", Namespace: "default"}, {Name: "db", Namespace: "default"}})
+	event := func(m Model, s *corev1.Secret, kind string) Model {
+		m, _ = m.handleSecretEvent(secretEventMsg{secret: s, event: kind, generation: m.watchGeneration})
+		return m
+	}
+	m = event(m, secret("api", "1"), kube.HistoryObserved)
+	m = event(m, secret("db", "1"), kube.HistoryObserved)
+
+	t.Run("should not notify the secrets listed when the watch starts", func(t *testing.T) {
+		if m.status != "" || len(m.changed) != 0 {
+			t.Errorf("Expected no notification, but got status %q and changed %v", m.status, m.changed)
+		}
+	})
+	t.Run("should notify and mark a changed secret", func(t *testing.T) {
+		m := event(m, secret("db", "2"), kube.HistoryChanged)
+		if !strings.Contains(m.status, "default/db was changed") {
+			t.Errorf("Expected a notification of the change, but got %q", m.status)
+		}
+		if !m.changed["default/db"] {
+			t.Errorf("Expected db to be marked as changed, but got %v", m.changed)
+		}
+		m.highlightedItem = kube.Item{Name: "db", Namespace: "default"}
+		m, _ = m.handleSecretDataLoaded(secretDataLoadedMsg{key: "default/db", data: map[string]string{}, secret: secret("db", "2")})
+		if m.changed["default/db"] {
+			t.Error("Expected db to be unmarked once viewed")
+		}
+	})
+	t.Run("should load the highlighted secret again instead of marking it", func(t *testing.T) {
+		m := event(m, secret("api", "2"), kube.HistoryChanged)
+		if m.changed["default/api"] || !m.loadingSecret {
+			t.Errorf("Expected api to be loaded again, but got changed %v", m.changed)
+		}
+	})
+	t.Run("should add created secrets and remove deleted ones", func(t *testing.T) {
+		m := event(m, secret("cache", "3"), kube.HistoryCreated)
+		m = event(m, secret("db", "1"), kube.HistoryDeleted)
+		var names []string
+		for _, it := range m.allItems {
+			names = append(names, it.Name)
+		}
+		if got := strings.Join(names, ","); got != "api,cache" {
+			t.Errorf("Expected api,cache to be listed, but got %s", got)
+		}
+	})
+	t.Run("should leave the list alone for the secrets already listed", func(t *testing.T) {
+		m := NewModel(clientset, "default", Options{Watch: true})
+		m, _ = m.handleWindowSize(tea.WindowSizeMsg{Width: 120, Height: 40})
+		m, _ = m.handleSecretsLoaded(kube.ItemSource{kube.NewItem(secret("api", "1")), kube.NewItem(secret("db", "1"))})
+		listed := &m.allItems[0]
+		m = event(m, secret("api", "1"), kube.HistoryObserved)
+		m = event(m, secret("db", "1"), kube.HistoryObserved)
+		if &m.allItems[0] != listed {
+			t.Error("Expected the list not to be rebuilt for secrets already listed")
+		}
+		m = event(m, secret("db", "2"), kube.HistoryObserved)
+		if &m.allItems[0] == listed || m.allItems[1].ResourceVersion != "2" {
+			t.Errorf("Expected the list to be updated with a newer version, but got %+v", m.allItems)
+		}
+	})
+	t.Run("should ignore the changes seen by a previous watch", func(t *testing.T) {
+		next, _ := m.handleSecretEvent(secretEventMsg{secret: secret("db", "5"), event: kube.HistoryChanged, generation: m.watchGeneration - 1})
+		if next.status != "" || next.changed["default/db"] {
+			t.Errorf("Expected the change to be ignored, but got status %q", next.status)
+		}
+	})
+	t.Run("should clear the notification once expired", func(t *testing.T) {
+		m := event(m, secret("db", "2"), kube.HistoryChanged)
+		if m = m.handleNotificationExpired(notificationExpiredMsg{status: m.status}); m.status != "" {
+			t.Errorf("Expected the notification to be cleared, but got %q", m.status)
+		}
+	})
+}